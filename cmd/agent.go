@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"website-analyzer/internal/agent"
+	"website-analyzer/internal/config"
+	"website-analyzer/internal/redact"
+)
+
+// runAgent implements `--agent`: the binary checks links for the server at
+// AGENT_SERVER from this network location, registering with it as
+// AGENT_REGION and serving the checks on the usual listener at AGENT_URL
+func runAgent(cfg *config.Config) int {
+	slog.SetDefault(slog.New(redact.New(cfg.RedactParams).LogHandler(slog.NewJSONHandler(os.Stdout, nil))))
+
+	if cfg.AgentToken == "" || cfg.AgentServer == "" || cfg.AgentURL == "" || cfg.AgentRegion == "" {
+		slog.Error("agent mode needs AGENT_TOKEN, AGENT_SERVER, AGENT_URL and AGENT_REGION")
+		return exitUsage
+	}
+	a, err := newAnalyzer(cfg, nil)
+	if err != nil {
+		slog.Error("failed to create analyzer", "error", err)
+		return exitError
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(agent.CheckPath, agent.Handler(a, cfg.AgentRegion, cfg.AgentToken))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ln, err := listen(cfg)
+	if err != nil {
+		slog.Error("failed to listen", "error", err)
+		return exitError
+	}
+	server := &http.Server{
+		Handler:     mux,
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			slog.Error("shutdown error", "error", err)
+		}
+	}()
+	go agent.Heartbeat(ctx, cfg.AgentServer, cfg.AgentToken, agent.Registration{Region: cfg.AgentRegion, URL: cfg.AgentURL})
+
+	slog.Info("agent starting", "addr", ln.Addr().String(), "region", cfg.AgentRegion, "server", cfg.AgentServer)
+	if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("agent server error", "error", err)
+		return exitError
+	}
+	slog.Info("agent stopped")
+	return exitOK
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"website-analyzer/internal/bundle"
+	"website-analyzer/internal/config"
+	"website-analyzer/internal/storage"
+)
+
+// runConfig implements `config export [--output file]` and
+// `config import [--profiles-out file] <file>`, which move monitors,
+// projects with their webhooks, the denylist and profiles between
+// instances as a YAML bundle
+func runConfig(cfg *config.Config, args []string, stdout, stderr io.Writer) int {
+	usage := func() {
+		fmt.Fprintln(stderr, "Usage: webpage-analyzer config export [--output file]")
+		fmt.Fprintln(stderr, "       webpage-analyzer config import [--profiles-out file] <file>")
+	}
+	if len(args) == 0 || (args[0] != "export" && args[0] != "import") {
+		usage()
+		return exitUsage
+	}
+	if cfg.HistoryDBPath == "" {
+		fmt.Fprintln(stderr, "config commands need HISTORY_DB_PATH")
+		return exitUsage
+	}
+
+	fs := flag.NewFlagSet("config "+args[0], flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = usage
+	output := fs.String("output", "", "write the bundle to this file instead of stdout")
+	profilesOut := fs.String("profiles-out", "", "write the bundled profiles to this file for PROFILES_FILE")
+	if err := fs.Parse(args[1:]); err != nil {
+		return exitUsage
+	}
+
+	a, err := newAnalyzer(cfg, nil)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	store, err := storage.NewSQLiteStore(cfg.HistoryDBPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to open history database: %v\n", err)
+		return exitError
+	}
+	defer store.Close()
+
+	if args[0] == "export" {
+		if fs.NArg() > 0 {
+			usage()
+			return exitUsage
+		}
+		b, err := bundle.Export(store, a.Profiles())
+		var data []byte
+		if err == nil {
+			data, err = b.Marshal()
+		}
+		if err == nil {
+			if *output != "" {
+				err = os.WriteFile(*output, data, 0o600)
+			} else {
+				_, err = stdout.Write(data)
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "export failed: %v\n", err)
+			return exitError
+		}
+		auditCLI(store, storage.AuditConfigExport, "bundle",
+			fmt.Sprintf("projects=%d monitors=%d denylist=%d", len(b.Projects), len(b.Monitors), len(b.Denylist)))
+		return exitOK
+	}
+
+	if fs.NArg() != 1 {
+		usage()
+		return exitUsage
+	}
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	b, err := bundle.Parse(data)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	result, err := bundle.Import(store, b, a.Profiles())
+	if err != nil {
+		fmt.Fprintf(stderr, "import failed: %v\n", err)
+		return exitError
+	}
+	auditCLI(store, storage.AuditConfigImport, "bundle",
+		fmt.Sprintf("projects=%d monitors=%d monitors_skipped=%d denylist=%d",
+			result.Projects, result.Monitors, result.MonitorsSkipped, result.Denylist))
+
+	fmt.Fprintf(stdout, "Imported %d project(s), %d monitor(s) (%d already present) and %d denylist entr(ies)\n",
+		result.Projects, result.Monitors, result.MonitorsSkipped, result.Denylist)
+	if *profilesOut != "" {
+		data, err := json.MarshalIndent(b.Profiles, "", "  ")
+		if err == nil {
+			err = os.WriteFile(*profilesOut, append(data, '\n'), 0o644)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "failed to write profiles: %v\n", err)
+			return exitError
+		}
+		fmt.Fprintf(stdout, "Wrote %d profile(s) to %s; point PROFILES_FILE at it and restart to apply them\n", len(b.Profiles), *profilesOut)
+	} else if len(result.ProfilesNotApplied) > 0 {
+		fmt.Fprintf(stdout, "Profiles missing or different here: %s; rerun with --profiles-out to write them for PROFILES_FILE\n",
+			strings.Join(result.ProfilesNotApplied, ", "))
+	}
+	return exitOK
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/signal"
+	"os/user"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"website-analyzer/internal/analyzer"
+	"website-analyzer/internal/config"
+	"website-analyzer/internal/models"
+	"website-analyzer/internal/redact"
+	"website-analyzer/internal/storage"
+)
+
+// Exit codes of the analyze subcommand
+const (
+	exitOK         = 0
+	exitError      = 1
+	exitUsage      = 2
+	exitGateFailed = 3
+)
+
+// runAnalyze implements `analyze <url> [--format text|json] [--profile name]
+// [--keyword phrase] [--baseline] [--gate [--tolerance n]] [--project name]
+// [--tags a,b] [--include-links] [--flags list] [--fetcher name]`. Flags may come before or after the URL.
+func runAnalyze(cfg *config.Config, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "text", "output format: text or json")
+	profile := fs.String("profile", "", "analysis profile (default from DEFAULT_PROFILE)")
+	keyword := fs.String("keyword", "", "target keyword to audit")
+	baseline := fs.Bool("baseline", false, "store the result as the baseline for the URL")
+	gate := fs.Bool("gate", false, "compare against the stored baseline and exit 3 on regression")
+	tolerance := fs.Int("tolerance", cfg.GateTolerance, "score points allowed below the baseline with --gate")
+	project := fs.String("project", "", "project to file the stored result under")
+	tags := fs.String("tags", "", "comma-separated tags for the stored result")
+	includeLinks := fs.Bool("include-links", false, "list every link with its text, rel and target attributes and status")
+	flags := fs.String("flags", "", "feature flags to switch on, or off with a leading -, e.g. simhash,-rendered")
+	fetcher := fs.String("fetcher", "", "how the page is obtained: http, browser, cached or har (default from FETCHER)")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: webpage-analyzer analyze <url> [flags]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	targetURL := fs.Arg(0)
+	if err := fs.Parse(fs.Args()[1:]); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "unexpected arguments: %v\n", fs.Args())
+		return exitUsage
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stderr, "unknown format %q\n", *format)
+		return exitUsage
+	}
+	if *tolerance < 0 {
+		fmt.Fprintln(stderr, "tolerance must not be negative")
+		return exitUsage
+	}
+	if (*baseline || *gate) && cfg.HistoryDBPath == "" {
+		fmt.Fprintln(stderr, "--baseline and --gate need HISTORY_DB_PATH")
+		return exitUsage
+	}
+
+	// Logs go to stderr so stdout stays machine-readable
+	logHandler := slog.NewTextHandler(stderr, &slog.HandlerOptions{Level: slog.LevelWarn})
+	slog.SetDefault(slog.New(redact.New(cfg.RedactParams).LogHandler(logHandler)))
+
+	a, err := newAnalyzer(cfg, nil)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	result, err := a.AnalyzeWithOptions(ctx, targetURL, analyzer.AnalyzeOptions{
+		Profile:      *profile,
+		Keyword:      *keyword,
+		IncludeLinks: *includeLinks,
+		Flags:        *flags,
+		Fetcher:      *fetcher,
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "analysis failed: %v\n", err)
+		return exitError
+	}
+
+	var verdict *models.GateVerdict
+	if *baseline || *gate {
+		labels := storage.Labels{Project: *project, Tags: storage.ParseTags(*tags)}
+		// result.URL is redacted and is what the history is keyed by
+		verdict, err = storeAndGate(cfg, result.URL, result, labels, *baseline, *gate, *tolerance)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		var out any = result
+		if verdict != nil {
+			out = struct {
+				Result  *models.AnalysisResult `json:"result"`
+				Verdict *models.GateVerdict    `json:"verdict"`
+			}{result, verdict}
+		}
+		if err := encoder.Encode(out); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+	} else {
+		printResult(stdout, result)
+		if verdict != nil {
+			printVerdict(stdout, verdict)
+		}
+	}
+
+	if verdict != nil && !verdict.Pass {
+		return exitGateFailed
+	}
+	return exitOK
+}
+
+// storeAndGate saves the result to the history database of cfg under labels,
+// optionally marks it as the baseline and, when gate is set, checks it
+// against the previous baseline. Acknowledged findings are suppressed on both sides.
+func storeAndGate(cfg *config.Config, targetURL string, result *models.AnalysisResult, labels storage.Labels, baseline, gate bool, tolerance int) (*models.GateVerdict, error) {
+	store, err := storage.NewSQLiteStore(cfg.HistoryDBPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+	defer store.Close()
+	store.SetRespectNoArchive(cfg.RespectNoArchive)
+
+	// Load the previous baseline before this run can replace it
+	var previous *storage.Record
+	if gate {
+		previous, err = store.Baseline(targetURL)
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, fmt.Errorf("no baseline set for %s; run with --baseline first", targetURL)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	record, err := store.Save(targetURL, result, labels)
+	if err != nil {
+		return nil, err
+	}
+	auditCLI(store, storage.AuditAnalysisRun, targetURL, "id="+record.ID)
+	if baseline {
+		if err := store.SetBaseline(record.ID); err != nil {
+			return nil, err
+		}
+		auditCLI(store, storage.AuditBaselineSet, targetURL, "id="+record.ID)
+	}
+	if !gate {
+		return nil, nil
+	}
+
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return nil, err
+	}
+	acks, err := store.Acknowledgements(u.Host)
+	if err != nil {
+		return nil, err
+	}
+	analyzer.ApplyAcknowledgements(previous.Result, acks)
+	analyzer.ApplyAcknowledgements(result, acks)
+
+	return analyzer.EvaluateGate(previous.Result, result, tolerance), nil
+}
+
+// auditCLI records a command line action under the OS user's name
+func auditCLI(store storage.Store, action, target, detail string) {
+	actor := "cli"
+	if u, err := user.Current(); err == nil {
+		actor += ":" + u.Username
+	}
+	entry := &storage.AuditEntry{Actor: actor, Action: action, Target: target, Detail: detail}
+	if err := store.RecordAudit(entry); err != nil {
+		slog.Warn("failed to record audit entry", "action", action, "error", err)
+	}
+}
+
+// printVerdict writes the gate outcome after the result summary
+func printVerdict(w io.Writer, verdict *models.GateVerdict) {
+	if verdict.Pass {
+		fmt.Fprintf(w, "Gate:           PASS (tolerance %d)\n", verdict.Tolerance)
+		return
+	}
+	fmt.Fprintf(w, "Gate:           FAIL (tolerance %d)\n", verdict.Tolerance)
+	for _, reason := range verdict.Reasons {
+		fmt.Fprintf(w, "  %s\n", reason)
+	}
+	for _, link := range verdict.NewBrokenLinks {
+		fmt.Fprintf(w, "  new broken: %s (%s)\n", link.URL, link.Error)
+	}
+}
+
+// printResult writes a short human-readable summary
+func printResult(w io.Writer, result *models.AnalysisResult) {
+	fmt.Fprintf(w, "URL:            %s\n", result.URL)
+	fmt.Fprintf(w, "Profile:        %s\n", result.Profile)
+	fmt.Fprintf(w, "Title:          %s\n", result.Title)
+	fmt.Fprintf(w, "HTML version:   %s\n", result.HTMLVersion)
+	fmt.Fprintf(w, "Words:          %d\n", result.WordCount)
+	fmt.Fprintf(w, "HTML size:      %d bytes\n", result.HTMLSize)
+	fmt.Fprintf(w, "Headings:       h1=%d h2=%d h3=%d h4=%d h5=%d h6=%d\n",
+		result.Headings["h1"], result.Headings["h2"], result.Headings["h3"],
+		result.Headings["h4"], result.Headings["h5"], result.Headings["h6"])
+	fmt.Fprintf(w, "Links:          %d internal, %d external, %d inaccessible\n",
+		result.InternalLinks, result.ExternalLinks, len(result.InaccessibleLinks))
+	fmt.Fprintf(w, "Login form:     %t\n", result.HasLoginForm)
+
+	if u := result.Usage; u != nil {
+		fmt.Fprintf(w, "Resources:      %d ms, %d requests, %d bytes, peak %d goroutines\n", u.WallTimeMs, u.Requests, u.BytesDownloaded, u.PeakGoroutines)
+	}
+	if s := result.Scores; s != nil {
+		fmt.Fprintf(w, "Scores:         overall=%d seo=%d accessibility=%d links=%d\n", s.Overall, s.SEO, s.Accessibility, s.Links)
+	}
+
+	for _, link := range result.InaccessibleLinks {
+		if link.Attempts > 1 {
+			fmt.Fprintf(w, "  broken: %s [%s] (%s, %d attempts)\n", link.URL, link.ErrorType, link.Error, link.Attempts)
+			continue
+		}
+		fmt.Fprintf(w, "  broken: %s [%s] (%s)\n", link.URL, link.ErrorType, link.Error)
+	}
+	for _, link := range result.RobotsSkipped {
+		fmt.Fprintf(w, "  skipped by robots.txt: %s\n", link)
+	}
+	for _, link := range result.LinkDetails {
+		status := "not checked"
+		switch {
+		case link.Error != "":
+			status = link.Error
+		case link.Checked:
+			status = strconv.Itoa(link.StatusCode)
+		}
+		fmt.Fprintf(w, "  link: %s %q rel=%s target=%s [%s]\n", link.URL, link.Text, strings.Join(link.Rel, ","), link.Target, status)
+	}
+	if result.Readiness != nil {
+		for _, issue := range result.Readiness.Issues {
+			fmt.Fprintf(w, "  readiness: %s\n", issue.Message)
+		}
+	}
+}
@@ -1,52 +1,430 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
 
+	"website-analyzer/internal/agent"
 	"website-analyzer/internal/analyzer"
 	"website-analyzer/internal/config"
 	"website-analyzer/internal/handler"
+	"website-analyzer/internal/jobs"
+	"website-analyzer/internal/monitor"
+	"website-analyzer/internal/portfolio"
+	"website-analyzer/internal/redact"
+	"website-analyzer/internal/rediscache"
+	"website-analyzer/internal/redisqueue"
+	"website-analyzer/internal/sheets"
+	"website-analyzer/internal/storage"
+	"website-analyzer/internal/validator"
+	"website-analyzer/internal/webhook"
 )
 
 func main() {
-	// Configure logging
-	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
-
 	// Configuration
 	cfg := config.LoadConfig()
+	if err := validator.SetBlocklist(cfg.BlockedHosts, cfg.BlockedCIDRs); err != nil {
+		log.Fatalf("BLOCKED_CIDRS: %v", err)
+	}
+	if err := validator.SetAccessLists(cfg.AllowedHosts, cfg.DeniedHosts); err != nil {
+		log.Fatalf("ALLOWED_HOSTS/DENIED_HOSTS: %v", err)
+	}
 
-	// Analyzer config
-	analyzerCfg := &analyzer.Config{
-		RequestTimeout:  cfg.RequestTimeout,
-		LinkTimeout:     cfg.LinkTimeout,
-		MaxWorkers:      cfg.MaxWorkers,
-		MaxResponseSize: cfg.MaxResponseSize,
-		MaxURLLength:    cfg.MaxURLLength,
-		MaxRedirects:    cfg.MaxRedirects,
+	// One-off CLI mode: analyze and print, without starting the server
+	if len(os.Args) > 1 && os.Args[1] == "analyze" {
+		os.Exit(runAnalyze(cfg, os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfig(cfg, os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "rescore" {
+		os.Exit(runRescore(cfg, os.Args[2:], os.Stdout, os.Stderr))
+	}
+	// Agent mode: link checks for the main server from another location
+	if len(os.Args) > 1 && os.Args[1] == "--agent" {
+		os.Exit(runAgent(cfg))
+	}
+
+	// Configure logging
+	slog.SetDefault(slog.New(redact.New(cfg.RedactParams).LogHandler(slog.NewJSONHandler(os.Stdout, nil))))
+
+	// Create analyzer; with AGENT_TOKEN set, links are also checked from
+	// the regions of registered agents
+	var agents *agent.Registry
+	var regions analyzer.RegionChecker
+	if cfg.AgentToken != "" {
+		agents = agent.NewRegistry(cfg.AgentToken, cfg.RequestTimeout)
+		regions = agents
+	}
+	analyzer, err := newAnalyzer(cfg, regions)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	// Create analyzer
-	analyzer := analyzer.NewAnalyzer(analyzerCfg)
+	// Create history store (disabled when HISTORY_DB_PATH is empty)
+	var store storage.Store
+	if cfg.HistoryDBPath != "" {
+		sqliteStore, err := storage.NewSQLiteStore(cfg.HistoryDBPath)
+		if err != nil {
+			log.Fatal("Failed to open history database:", err)
+		}
+		defer sqliteStore.Close()
+		sqliteStore.SetRespectNoArchive(cfg.RespectNoArchive)
+		store = sqliteStore
+	}
 
 	// Create handler
-	h, err := handler.NewHandler(analyzer, "web/templates")
+	h, err := handler.NewHandler(analyzer, store, "web/templates")
 	if err != nil {
 		log.Fatal("Failed to load templates:", err)
 	}
+	quotas, err := newQuotas(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	h.SetQuotas(quotas)
+	h.SetAdminToken(cfg.AdminToken)
+	h.SetBatchLimit(cfg.BatchMaxURLs)
+	h.SetBotContact(cfg.BotContact)
+	assets, err := handler.NewStaticAssets("web/static", cfg.StaticMaxAge)
+	if err != nil {
+		log.Fatal(err)
+	}
+	h.SetAssets(assets)
+	if !webhook.ValidFormat(cfg.WebhookFormat) {
+		log.Fatalf("WEBHOOK_FORMAT must be one of %v", webhook.Formats)
+	}
+	var notifier *webhook.Notifier
+	if store != nil {
+		notifier = webhook.NewNotifier(store, analyzer.Redactor())
+		notifier.SetSecret(cfg.WebhookSecret)
+		notifier.SetGlobal(cfg.WebhookURL, cfg.WebhookFormat)
+		h.AddSavedHook("webhooks", notifier.Notify)
+	} else if cfg.WebhookURL != "" {
+		slog.Warn("WEBHOOK_URL needs HISTORY_DB_PATH; webhooks are disabled")
+	}
+	if cfg.SheetsID != "" {
+		appender, err := sheets.New(cfg.SheetsCredentials, cfg.SheetsID, cfg.SheetsName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		h.AddScheduledHook("google-sheets", func(ctx context.Context, record *storage.Record) error {
+			return appender.Append(ctx, sheets.SummaryRow(record))
+		})
+	}
 
 	// Routes
-	http.HandleFunc("/", h.IndexHandler)
-	http.HandleFunc("/analyze", h.AnalyzeHandler)
-	http.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("web/static"))))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", h.IndexHandler)
+	mux.HandleFunc("/analyze", h.AnalyzeHandler)
+	mux.HandleFunc("/crawl", h.CrawlHandler)
+	mux.HandleFunc("/compare", h.CompareHandler)
+	mux.HandleFunc("/history", h.HistoryHandler)
+	mux.HandleFunc("/history/{id}", h.HistoryResultHandler)
+	mux.HandleFunc("/diff", h.DiffPageHandler)
+	mux.HandleFunc("/acknowledge", h.AcknowledgeHandler)
+	mux.HandleFunc("/acknowledge/{id}/delete", h.UnacknowledgeHandler)
+	mux.HandleFunc("/api/baseline", h.BaselineHandler)
+	mux.HandleFunc("/api/gate", h.GateHandler)
+	mux.HandleFunc("/api/usage", h.UsageHandler)
+	mux.HandleFunc("/api/quota", h.QuotaHandler)
+	mux.HandleFunc("/api/v1/analyze/batch", h.BatchAnalyzeHandler)
+	mux.HandleFunc("/api/v1/analyses/{id}/export", h.ExportHandler)
+	mux.HandleFunc("/api/v1/analyses/{id}/replay", h.ReplayHandler)
+	mux.HandleFunc("/api/v1/diff", h.DiffHandler)
+	mux.HandleFunc(agent.RegisterPath, h.RegisterAgentHandler)
+	mux.HandleFunc("/api/v1/jobs", h.JobsHandler)
+	mux.HandleFunc("/api/v1/jobs/{id}", h.JobHandler)
+	mux.HandleFunc("/api/v1/jobs/{id}/events", h.JobEventsHandler)
+	mux.HandleFunc("/jobs/{id}", h.JobPageHandler)
+	mux.HandleFunc("/api/grafana/{$}", h.GrafanaTestHandler)
+	mux.HandleFunc("/api/grafana/search", h.GrafanaSearchHandler)
+	mux.HandleFunc("/api/grafana/metrics", h.GrafanaMetricsHandler)
+	mux.HandleFunc("/api/grafana/query", h.GrafanaQueryHandler)
+	mux.HandleFunc("/projects", h.ProjectsHandler)
+	mux.HandleFunc("/projects/{name}/report", h.ProjectReportHandler)
+	mux.HandleFunc("/linkrot", h.LinkRotHandler)
+	mux.HandleFunc("/monitors", h.MonitorsHandler)
+	mux.HandleFunc("/monitors/{id}/pause", h.PauseMonitorHandler)
+	mux.HandleFunc("/monitors/{id}/resume", h.ResumeMonitorHandler)
+	mux.HandleFunc("/monitors/{id}/delete", h.DeleteMonitorHandler)
+	mux.HandleFunc("/maintenance", h.MaintenanceHandler)
+	mux.HandleFunc("/maintenance/{id}/delete", h.DeleteMaintenanceHandler)
+	mux.Handle("/static/", assets)
+	mux.HandleFunc(handler.BotInfoPath, h.BotInfoHandler)
+	mux.HandleFunc("/version", h.VersionHandler)
+
+	// Operational endpoints move to their own listener when ADMIN_ADDR is
+	// set; the profiler is only served there
+	adminMux := mux
+	if cfg.AdminAddr != "" {
+		adminMux = http.NewServeMux()
+		registerDebug(adminMux)
+	}
+	adminMux.HandleFunc("/metrics", h.MetricsHandler)
+	adminMux.HandleFunc("/admin/audit", h.AuditHandler)
+	adminMux.HandleFunc("/admin/audit/export", h.AuditExportHandler)
+	adminMux.HandleFunc("/admin/circuit-breaker", h.CircuitBreakerHandler)
+	adminMux.HandleFunc("/admin/erasure", h.ErasureHandler)
+	adminMux.HandleFunc("/admin/erasure/{token}/confirm", h.ConfirmErasureHandler)
+	adminMux.HandleFunc("/admin/denylist", h.DenylistHandler)
+	adminMux.HandleFunc("/admin/denylist/{domain}/delete", h.RemoveDenylistHandler)
+	adminMux.HandleFunc("/admin/config/export", h.ConfigExportHandler)
+	adminMux.HandleFunc("/admin/config/import", h.ConfigImportHandler)
+	adminMux.HandleFunc("/admin/rescore", h.RescoreHandler)
+	adminMux.HandleFunc("/admin/agents", h.AgentsHandler)
+	adminMux.HandleFunc("/admin/projects/{name}/keys", h.ProjectKeyHandler)
+	adminMux.HandleFunc("/admin/projects/{name}/keys/{id}/revoke", h.RevokeKeyHandler)
+
+	// Cancelled on SIGINT/SIGTERM; request contexts derive from it so
+	// in-flight analyses abort on shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Background crawls and batches end with the server; scheduled runs
+	// share their workers
+	jobManager := jobs.NewManager(ctx, cfg.JobWorkers)
+	jobManager.SetTimeout(cfg.JobTimeout)
+	h.SetJobs(jobManager)
+	// Replicas sharing Redis take each other's queued jobs
+	if cfg.RedisURL != "" {
+		queue, err := redisqueue.New(ctx, cfg.RedisURL)
+		if err != nil {
+			log.Fatal(err)
+		}
+		jobManager.SetQueue(queue)
+	}
+	h.SetAgents(agents)
+
+	// Scheduled re-analysis of monitored pages
+	if store != nil && cfg.MonitorInterval > 0 {
+		runner := monitor.NewRunner(store, analyzer, cfg.MonitorInterval)
+		runner.OnSaved(h.RunScheduledHooks)
+		runner.SetNotifier(notifier)
+		runner.SetQueue(jobManager)
+		go runner.Run(ctx)
+
+		// Portfolio reports are delivered on the same cadence
+		go portfolio.NewRunner(store, notifier, cfg.MonitorInterval).Run(ctx)
+	}
 
 	// Start server
-	addr := ":" + cfg.Port
-	slog.Info("server starting", "addr", addr, "env", cfg.Env)
+	ln, err := listen(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	server := &http.Server{
+		Handler:     mux,
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	servers := []*http.Server{server}
+
+	if cfg.AdminAddr != "" {
+		adminLn, err := net.Listen("tcp", cfg.AdminAddr)
+		if err != nil {
+			log.Fatal("Failed to open admin listener:", err)
+		}
+		adminServer := &http.Server{
+			Handler:     adminMux,
+			BaseContext: func(net.Listener) context.Context { return ctx },
+		}
+		servers = append(servers, adminServer)
+
+		slog.Info("admin server starting", "addr", adminLn.Addr().String())
+		go func() {
+			if err := adminServer.Serve(adminLn); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("admin server error", "error", err)
+			}
+		}()
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		for _, s := range servers {
+			if err := s.Shutdown(shutdownCtx); err != nil {
+				slog.Error("shutdown error", "error", err)
+			}
+		}
+	}()
+
+	slog.Info("server starting", "addr", ln.Addr().String(), "network", ln.Addr().Network(), "env", cfg.Env)
 
-	if err := http.ListenAndServe(addr, nil); err != nil {
+	if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatal(err)
 	}
+	slog.Info("server stopped")
+}
+
+// newAnalyzer builds the analyzer from the environment configuration;
+// regions may be nil to check links from here only
+func newAnalyzer(cfg *config.Config, regions analyzer.RegionChecker) (*analyzer.Analyzer, error) {
+	analyzerCfg := &analyzer.Config{
+		RequestTimeout:    cfg.RequestTimeout,
+		LinkTimeout:       cfg.LinkTimeout,
+		LinkMaxAttempts:   cfg.LinkMaxAttempts,
+		LinkRetryBackoff:  cfg.LinkRetryBackoff,
+		LinkRetryJitter:   cfg.LinkRetryJitter,
+		LinkCheckGetOnly:  cfg.LinkCheckGetOnly,
+		RespectRobots:     cfg.RespectRobots,
+		RedactParams:      cfg.RedactParams,
+		LinkHostRate:      cfg.LinkHostRate,
+		LinkHostInFlight:  cfg.LinkHostInFlight,
+		CircuitBreakerTTL: cfg.CircuitBreakerTTL,
+		RenderMode:        cfg.RenderMode,
+		BrowserPath:       cfg.BrowserPath,
+		BotInfoURL:        cfg.BotInfoURL,
+		OptOutDomains:     cfg.OptOutDomains,
+		CacheTTL:          cfg.CacheTTL,
+		MaxWorkers:        cfg.MaxWorkers,
+		MaxResponseSize:   cfg.MaxResponseSize,
+		MaxURLLength:      cfg.MaxURLLength,
+		MaxRedirects:      cfg.MaxRedirects,
+		RedirectChainMax:  cfg.RedirectChainMax,
+		DeepAnalysis:      cfg.DeepAnalysis,
+		LargeDocumentSize: cfg.LargeDocumentSize,
+		SitemapAnalysis:   cfg.SitemapAnalysis,
+		CrawlMaxDepth:     cfg.CrawlMaxDepth,
+		CrawlMaxPages:     cfg.CrawlMaxPages,
+		CrawlMemoryURLs:   cfg.CrawlMemoryURLs,
+		CrawlSpillDir:     cfg.CrawlSpillDir,
+		CrawlBloomFPRate:  cfg.CrawlBloomFPRate,
+		DefaultProfile:    cfg.DefaultProfile,
+		GateTolerance:     cfg.GateTolerance,
+		LinkScope:         cfg.LinkScope,
+		SlowestLinks:      cfg.SlowestLinks,
+		KeepSnapshots:     cfg.KeepSnapshots,
+		Regions:           regions,
+		Region:            cfg.AgentRegion,
+	}
+
+	if cfg.RenderMode != analyzer.RenderHTTP && cfg.RenderMode != analyzer.RenderBrowser {
+		return nil, fmt.Errorf("RENDER_MODE must be %q or %q", analyzer.RenderHTTP, analyzer.RenderBrowser)
+	}
+	if _, err := analyzer.ParseLinkScope(cfg.LinkScope); err != nil {
+		return nil, fmt.Errorf("LINK_SCOPE: %w", err)
+	}
+	if err := validateTimeouts(cfg); err != nil {
+		return nil, err
+	}
+	flags, err := analyzer.ParseFlags(cfg.FeatureFlags, analyzer.DefaultFlags(analyzerCfg))
+	if err != nil {
+		return nil, fmt.Errorf("FEATURE_FLAGS: %w", err)
+	}
+	for _, flag := range cfg.RequestFlags {
+		if !slices.Contains(analyzer.AllFlags, flag) {
+			return nil, fmt.Errorf("REQUEST_FEATURE_FLAGS: unknown feature flag %q", flag)
+		}
+	}
+	analyzerCfg.Flags, analyzerCfg.RequestFlags = flags, cfg.RequestFlags
+	if analyzerCfg.WeightBudget, err = analyzer.ParseBudget(cfg.WeightBudget); err != nil {
+		return nil, fmt.Errorf("PAGE_WEIGHT_BUDGET: %w", err)
+	}
+	analyzerCfg.DefaultFetcher = cfg.Fetcher
+	analyzerCfg.Fetchers = make(map[string]analyzer.Fetcher)
+	if cfg.HARFile != "" {
+		har, err := analyzer.LoadHAR(cfg.HARFile)
+		if err != nil {
+			return nil, fmt.Errorf("HAR_FILE: %w", err)
+		}
+		analyzerCfg.Fetchers[analyzer.FetcherHAR] = har
+	}
+	if cfg.WARCFile != "" {
+		warc, err := analyzer.LoadWARC(cfg.WARCFile)
+		if err != nil {
+			return nil, fmt.Errorf("WARC_FILE: %w", err)
+		}
+		analyzerCfg.Fetchers[analyzer.FetcherWARC] = warc
+	}
+
+	// Replicas share cached results through Redis; the in-memory cache is
+	// the default
+	if cfg.RedisURL != "" && cfg.CacheTTL > 0 {
+		cache, err := rediscache.New(context.Background(), cfg.RedisURL, cfg.CacheTTL)
+		if err != nil {
+			return nil, err
+		}
+		analyzerCfg.ResultCache = cache
+	}
+
+	// Analysis profiles: built-in defaults, optionally overridden from a file
+	analyzerCfg.Profiles = analyzer.DefaultProfiles(analyzerCfg)
+	if cfg.ProfilesFile != "" {
+		profiles, err := analyzer.LoadProfiles(cfg.ProfilesFile, analyzerCfg.Profiles)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load profiles: %w", err)
+		}
+		analyzerCfg.Profiles = profiles
+	}
+
+	// Technology rules: built-in, extended or overridden from a file
+	analyzerCfg.TechRules = analyzer.DefaultTechRules()
+	if cfg.TechRulesFile != "" {
+		rules, err := analyzer.LoadTechRules(cfg.TechRulesFile, analyzerCfg.TechRules)
+		if err != nil {
+			return nil, fmt.Errorf("TECH_RULES_FILE: %w", err)
+		}
+		analyzerCfg.TechRules = rules
+	}
+	analyzerCfg.TrackerRules = analyzer.DefaultTrackerRules()
+	if cfg.TrackerRulesFile != "" {
+		rules, err := analyzer.LoadTechRules(cfg.TrackerRulesFile, analyzerCfg.TrackerRules)
+		if err != nil {
+			return nil, fmt.Errorf("TRACKER_RULES_FILE: %w", err)
+		}
+		analyzerCfg.TrackerRules = rules
+	}
+
+	a := analyzer.NewAnalyzer(analyzerCfg)
+	if _, err := a.Fetcher("", analyzerCfg.Flags); err != nil {
+		return nil, fmt.Errorf("FETCHER: %w", err)
+	}
+	return a, nil
+}
+
+// validateTimeouts checks that each outbound timeout fits within the one
+// enclosing it: a link check within a page fetch, and a page fetch within a
+// background job. Otherwise links fail before they could have answered or
+// jobs are cut off in the middle of a page.
+func validateTimeouts(cfg *config.Config) error {
+	if cfg.RequestTimeout <= 0 {
+		return fmt.Errorf("REQUEST_TIMEOUT must be positive, got %s", cfg.RequestTimeout)
+	}
+	if cfg.LinkTimeout <= 0 {
+		return fmt.Errorf("LINK_CHECK_TIMEOUT must be positive, got %s", cfg.LinkTimeout)
+	}
+	if cfg.LinkTimeout > cfg.RequestTimeout {
+		return fmt.Errorf("LINK_CHECK_TIMEOUT (%s) must not exceed REQUEST_TIMEOUT (%s)", cfg.LinkTimeout, cfg.RequestTimeout)
+	}
+	if cfg.JobTimeout < 0 {
+		return fmt.Errorf("JOB_TIMEOUT must not be negative, got %s", cfg.JobTimeout)
+	}
+	if cfg.JobTimeout > 0 && cfg.RequestTimeout > cfg.JobTimeout {
+		return fmt.Errorf("REQUEST_TIMEOUT (%s) must not exceed JOB_TIMEOUT (%s)", cfg.RequestTimeout, cfg.JobTimeout)
+	}
+	return nil
+}
+
+// newQuotas parses the API key quotas from the environment configuration
+func newQuotas(cfg *config.Config) (handler.Quotas, error) {
+	daily, err := storage.ParseQuota(cfg.QuotaDaily)
+	if err != nil {
+		return handler.Quotas{}, fmt.Errorf("API_QUOTA_DAILY: %w", err)
+	}
+	monthly, err := storage.ParseQuota(cfg.QuotaMonthly)
+	if err != nil {
+		return handler.Quotas{}, fmt.Errorf("API_QUOTA_MONTHLY: %w", err)
+	}
+	return handler.Quotas{Daily: daily, Monthly: monthly}, nil
 }
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"website-analyzer/internal/config"
+)
+
+func TestValidateTimeouts(t *testing.T) {
+	tests := []struct {
+		name    string
+		request time.Duration
+		link    time.Duration
+		job     time.Duration
+		wantErr string
+	}{
+		{name: "nested", request: 10 * time.Second, link: 5 * time.Second, job: time.Minute},
+		{name: "equal", request: 10 * time.Second, link: 10 * time.Second, job: 10 * time.Second},
+		{name: "no job deadline", request: time.Minute, link: 5 * time.Second},
+		{name: "zero request", link: 5 * time.Second, wantErr: "REQUEST_TIMEOUT must be positive"},
+		{name: "negative request", request: -time.Second, link: 5 * time.Second, wantErr: "REQUEST_TIMEOUT must be positive"},
+		{name: "negative link", request: 10 * time.Second, link: -time.Second, wantErr: "LINK_CHECK_TIMEOUT must be positive"},
+		{name: "negative job", request: 10 * time.Second, link: 5 * time.Second, job: -time.Second, wantErr: "JOB_TIMEOUT must not be negative"},
+		{name: "link beyond request", request: 5 * time.Second, link: 10 * time.Second, wantErr: "LINK_CHECK_TIMEOUT (10s) must not exceed REQUEST_TIMEOUT (5s)"},
+		{name: "request beyond job", request: time.Minute, link: 5 * time.Second, job: 30 * time.Second, wantErr: "REQUEST_TIMEOUT (1m0s) must not exceed JOB_TIMEOUT (30s)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{RequestTimeout: tt.request, LinkTimeout: tt.link, JobTimeout: tt.job}
+			err := validateTimeouts(cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected a valid configuration, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
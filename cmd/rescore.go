@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"website-analyzer/internal/config"
+	"website-analyzer/internal/rescore"
+	"website-analyzer/internal/storage"
+)
+
+// runRescore implements `rescore [--format text|json]`, which re-scores
+// every stored snapshot with the current checks after they change,
+// keeping the original findings next to the new ones
+func runRescore(cfg *config.Config, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("rescore", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "text", "output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() > 0 || (*format != "text" && *format != "json") {
+		fmt.Fprintln(stderr, "Usage: webpage-analyzer rescore [--format text|json]")
+		return exitUsage
+	}
+	if cfg.HistoryDBPath == "" {
+		fmt.Fprintln(stderr, "rescore needs HISTORY_DB_PATH")
+		return exitUsage
+	}
+
+	a, err := newAnalyzer(cfg, nil)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	store, err := storage.NewSQLiteStore(cfg.HistoryDBPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to open history database: %v\n", err)
+		return exitError
+	}
+	defer store.Close()
+	store.SetRespectNoArchive(cfg.RespectNoArchive)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	summary, err := rescore.Run(ctx, store, a)
+	if summary != nil {
+		auditCLI(store, storage.AuditRescore, "snapshots",
+			fmt.Sprintf("snapshots=%d rescored=%d changed=%d failed=%d",
+				summary.Snapshots, summary.Rescored, len(summary.Changes), summary.Failed))
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "rescore failed: %v\n", err)
+		return exitError
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(summary); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+	} else {
+		fmt.Fprintf(stdout, "Rescored %d of %d snapshot(s), %d failed\n", summary.Rescored, summary.Snapshots, summary.Failed)
+		for _, change := range summary.Changes {
+			fmt.Fprintf(stdout, "  %s %s: %d -> %d\n", change.ID, change.URL, change.Before, change.After)
+		}
+	}
+	if summary.Failed > 0 {
+		return exitError
+	}
+	return exitOK
+}
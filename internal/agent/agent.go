@@ -0,0 +1,135 @@
+// Package agent runs link checks from other network locations. An agent is
+// the same binary started with --agent: it registers with the main server
+// and checks the URLs the server sends it, so results can show links that
+// are geo-blocked or fail in some regions only.
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"website-analyzer/internal/analyzer"
+	"website-analyzer/internal/models"
+)
+
+// CheckPath is where agents accept link checks from the server
+const CheckPath = "/agent/check"
+
+// RegisterPath is where the server accepts agent registrations
+const RegisterPath = "/api/v1/agents"
+
+// HeartbeatInterval is how often agents register again. The server forgets
+// agents that missed three heartbeats.
+const HeartbeatInterval = 30 * time.Second
+
+// maxCheckURLs bounds the URLs of one check request
+const maxCheckURLs = 1000
+
+// maxRequestSize bounds the bodies of check and registration requests
+const maxRequestSize = 4 << 20
+
+// Registration announces an agent to the server
+type Registration struct {
+	Region string `json:"region"`
+	// URL is where the server reaches the agent
+	URL string `json:"url"`
+}
+
+// checkRequest asks an agent to check URLs
+type checkRequest struct {
+	URLs []string `json:"urls"`
+}
+
+// checkResponse is an agent's answer to a checkRequest
+type checkResponse struct {
+	Region   string              `json:"region"`
+	Statuses []models.LinkStatus `json:"statuses"`
+}
+
+// Handler serves CheckPath for an agent in region, checking the URLs that
+// requests bearing token send with a
+func Handler(a *analyzer.Analyzer, region, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !authorized(r, token) {
+			http.Error(w, "A valid agent token is required", http.StatusUnauthorized)
+			return
+		}
+
+		var req checkRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+			http.Error(w, "Invalid check request", http.StatusBadRequest)
+			return
+		}
+		if len(req.URLs) > maxCheckURLs {
+			http.Error(w, fmt.Sprintf("At most %d URLs can be checked at once", maxCheckURLs), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		statuses := a.CheckURLs(r.Context(), req.URLs)
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(checkResponse{Region: region, Statuses: statuses}); err != nil {
+			slog.Error("failed to write check response", "error", err)
+		}
+	})
+}
+
+// Heartbeat registers the agent with the server at serverURL now and every
+// HeartbeatInterval until ctx is cancelled; failures are logged and
+// retried on the next beat
+func Heartbeat(ctx context.Context, serverURL, token string, registration Registration) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	ticker := time.NewTicker(HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		if err := Register(ctx, client, serverURL, token, registration); err != nil && ctx.Err() == nil {
+			slog.Warn("failed to register with server", "server", serverURL, "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Register announces the agent to the server at serverURL once
+func Register(ctx context.Context, client *http.Client, serverURL, token string, registration Registration) error {
+	body, err := json.Marshal(registration)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(serverURL, "/")+RegisterPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("server answered %s", resp.Status)
+	}
+	return nil
+}
+
+// authorized reports whether r bears token
+func authorized(r *http.Request, token string) bool {
+	secret, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return found && token != "" && subtle.ConstantTimeCompare([]byte(strings.TrimSpace(secret)), []byte(token)) == 1
+}
@@ -0,0 +1,106 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"website-analyzer/internal/analyzer"
+)
+
+func TestRegistryChecksThroughAgents(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/blocked" {
+			w.WriteHeader(http.StatusUnavailableForLegalReasons)
+		}
+	}))
+	defer target.Close()
+
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	a := analyzer.NewAnalyzer(&analyzer.Config{RequestTimeout: 5 * time.Second, LinkTimeout: 2 * time.Second, MaxWorkers: 2})
+	remote := httptest.NewServer(Handler(a, "eu-west", "s3cret"))
+	defer remote.Close()
+
+	registry := NewRegistry("s3cret", 5*time.Second)
+	if checks := registry.CheckRegions(context.Background(), []string{target.URL}); checks != nil {
+		t.Errorf("Expected no checks without agents, got %+v", checks)
+	}
+	for _, registration := range []Registration{
+		{Region: "", URL: remote.URL},
+		{Region: "eu-west", URL: "ftp://agent.example"},
+	} {
+		if _, err := registry.Register(registration); err == nil {
+			t.Errorf("Expected %+v to be rejected", registration)
+		}
+	}
+	if added, err := registry.Register(Registration{Region: "eu-west", URL: remote.URL + "/"}); err != nil || !added {
+		t.Fatalf("Expected a new agent, got %v, %v", added, err)
+	}
+	if added, _ := registry.Register(Registration{Region: "eu-west", URL: remote.URL}); added {
+		t.Error("Expected a heartbeat of a known agent not to add it again")
+	}
+	// A second agent in a region is only a standby
+	registry.now = func() time.Time { return time.Now().Add(-time.Minute) }
+	registry.Register(Registration{Region: "eu-west", URL: "http://127.0.0.1:1"})
+	registry.now = time.Now
+	if agents := registry.Agents(); len(agents) != 2 || agents[0].URL != remote.URL {
+		t.Fatalf("Expected the most recently seen agent first, got %+v", agents)
+	}
+
+	checks := registry.CheckRegions(context.Background(), []string{target.URL + "/", target.URL + "/blocked"})
+	if len(checks) != 1 || checks[0].Region != "eu-west" || checks[0].Err != nil || len(checks[0].Statuses) != 2 {
+		t.Fatalf("Expected the agent's checks, got %+v", checks)
+	}
+	for _, status := range checks[0].Statuses {
+		if (status.StatusCode == http.StatusUnavailableForLegalReasons) != (status.URL == target.URL+"/blocked") {
+			t.Errorf("Unexpected status %+v", status)
+		}
+	}
+
+	// Agents expire when they stop registering
+	registry.now = func() time.Time { return time.Now().Add(expireAfter + time.Second) }
+	if agents := registry.Agents(); len(agents) != 0 {
+		t.Errorf("Expected agents to expire, got %+v", agents)
+	}
+
+	wrong := NewRegistry("wrong", 5*time.Second)
+	wrong.Register(Registration{Region: "eu-west", URL: remote.URL})
+	if checks := wrong.CheckRegions(context.Background(), []string{target.URL}); len(checks) != 1 || checks[0].Err == nil {
+		t.Errorf("Expected an agent to refuse a wrong token, got %+v", checks)
+	}
+	if !registry.Authorized("s3cret") || registry.Authorized("wrong") || NewRegistry("", time.Second).Authorized("") {
+		t.Error("Unexpected token check")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	var got Registration
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != RegisterPath {
+			http.NotFound(w, r)
+			return
+		}
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	registration := Registration{Region: "ap-south", URL: "http://agent.example:8080"}
+	if err := Register(context.Background(), server.Client(), server.URL+"/", "s3cret", registration); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if got != registration || auth != "Bearer s3cret" {
+		t.Errorf("Unexpected registration %+v with %q", got, auth)
+	}
+	if err := Register(context.Background(), server.Client(), server.URL+"/prefix", "s3cret", registration); err == nil {
+		t.Error("Expected a rejected registration to fail")
+	}
+}
@@ -0,0 +1,164 @@
+package agent
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"website-analyzer/internal/analyzer"
+	"website-analyzer/internal/models"
+)
+
+// expireAfter is how long the server keeps an agent after its last
+// registration
+const expireAfter = 3 * HeartbeatInterval
+
+// ErrInvalidRegistration is returned for registrations without a region or
+// an http(s) URL
+var ErrInvalidRegistration = errors.New("invalid agent registration")
+
+// Agent is an agent registered with the server
+type Agent struct {
+	Region   string    `json:"region"`
+	URL      string    `json:"url"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// Registry tracks the agents registered with the server and checks URLs
+// from their regions, from the most recently seen agent of each. It is an
+// analyzer.RegionChecker. Agents hold the shared token, so their URLs are
+// trusted and not held to the private address checks of analyzed pages.
+type Registry struct {
+	token  string
+	client *http.Client
+	now    func() time.Time
+
+	mu     sync.Mutex
+	agents map[string]Agent // by URL
+}
+
+// NewRegistry returns a registry for agents bearing token, whose checks
+// are given up after timeout
+func NewRegistry(token string, timeout time.Duration) *Registry {
+	return &Registry{
+		token:  token,
+		client: &http.Client{Timeout: timeout},
+		now:    time.Now,
+		agents: make(map[string]Agent),
+	}
+}
+
+// Authorized reports whether secret is the agents' token
+func (r *Registry) Authorized(secret string) bool {
+	return r.token != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(r.token)) == 1
+}
+
+// Register records an agent's heartbeat, reporting whether it is new
+func (r *Registry) Register(registration Registration) (bool, error) {
+	region := strings.TrimSpace(registration.Region)
+	u, err := url.Parse(registration.URL)
+	if region == "" || err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return false, ErrInvalidRegistration
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expire()
+	key := strings.TrimSuffix(u.String(), "/")
+	_, known := r.agents[key]
+	r.agents[key] = Agent{Region: region, URL: key, LastSeen: r.now().UTC()}
+	return !known, nil
+}
+
+// Agents lists the live agents by region
+func (r *Registry) Agents() []Agent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expire()
+	agents := make([]Agent, 0, len(r.agents))
+	for _, agent := range r.agents {
+		agents = append(agents, agent)
+	}
+	slices.SortFunc(agents, func(a, b Agent) int {
+		return cmp.Or(strings.Compare(a.Region, b.Region), b.LastSeen.Compare(a.LastSeen), strings.Compare(a.URL, b.URL))
+	})
+	return agents
+}
+
+// expire forgets agents that stopped registering; r.mu must be held
+func (r *Registry) expire() {
+	cutoff := r.now().Add(-expireAfter)
+	for key, agent := range r.agents {
+		if agent.LastSeen.Before(cutoff) {
+			delete(r.agents, key)
+		}
+	}
+}
+
+// CheckRegions checks urls from one agent of each region concurrently.
+// URLs beyond what an agent accepts at once are left out.
+func (r *Registry) CheckRegions(ctx context.Context, urls []string) []analyzer.RegionCheck {
+	var agents []Agent
+	for _, agent := range r.Agents() {
+		// Agents are sorted most recently seen first within a region
+		if len(agents) == 0 || agents[len(agents)-1].Region != agent.Region {
+			agents = append(agents, agent)
+		}
+	}
+	if len(agents) == 0 {
+		return nil
+	}
+	urls = urls[:min(len(urls), maxCheckURLs)]
+
+	checks := make([]analyzer.RegionCheck, len(agents))
+	var wg sync.WaitGroup
+	for i, agent := range agents {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checks[i] = analyzer.RegionCheck{Region: agent.Region}
+			checks[i].Statuses, checks[i].Err = r.check(ctx, agent, urls)
+		}()
+	}
+	wg.Wait()
+	return checks
+}
+
+// check sends urls to one agent
+func (r *Registry) check(ctx context.Context, agent Agent, urls []string) ([]models.LinkStatus, error) {
+	body, err := json.Marshal(checkRequest{URLs: urls})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, agent.URL+CheckPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+r.token)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("agent unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("agent answered %s", resp.Status)
+	}
+	var answer checkResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxRequestSize)).Decode(&answer); err != nil {
+		return nil, fmt.Errorf("invalid agent response: %w", err)
+	}
+	return answer.Statuses, nil
+}
@@ -0,0 +1,292 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"website-analyzer/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Accessibility rule identifiers
+const (
+	RuleInvalidRole      = "aria-invalid-role"
+	RuleBrokenReference  = "aria-broken-reference"
+	RuleRedundantRole    = "aria-redundant-role"
+	RuleMissingName      = "aria-missing-accessible-name"
+	maxElementDescLength = 80
+)
+
+// validRoles lists the WAI-ARIA 1.2 roles authors may use
+var validRoles = toSet(
+	"alert", "alertdialog", "application", "article", "banner", "blockquote",
+	"button", "caption", "cell", "checkbox", "code", "columnheader", "combobox",
+	"complementary", "contentinfo", "definition", "deletion", "dialog",
+	"directory", "document", "emphasis", "feed", "figure", "form", "generic",
+	"grid", "gridcell", "group", "heading", "img", "insertion", "link", "list",
+	"listbox", "listitem", "log", "main", "marquee", "math", "menu", "menubar",
+	"menuitem", "menuitemcheckbox", "menuitemradio", "meter", "navigation",
+	"none", "note", "option", "paragraph", "presentation", "progressbar",
+	"radio", "radiogroup", "region", "row", "rowgroup", "rowheader",
+	"scrollbar", "search", "searchbox", "separator", "slider", "spinbutton",
+	"status", "strong", "subscript", "superscript", "switch", "tab", "table",
+	"tablist", "tabpanel", "term", "textbox", "time", "timer", "toolbar",
+	"tooltip", "tree", "treegrid", "treeitem",
+)
+
+// implicitRoles maps elements to the role they already expose natively
+var implicitRoles = map[string]string{
+	"article":  "article",
+	"aside":    "complementary",
+	"button":   "button",
+	"dialog":   "dialog",
+	"form":     "form",
+	"h1":       "heading",
+	"h2":       "heading",
+	"h3":       "heading",
+	"h4":       "heading",
+	"h5":       "heading",
+	"h6":       "heading",
+	"img":      "img",
+	"li":       "listitem",
+	"main":     "main",
+	"nav":      "navigation",
+	"ol":       "list",
+	"select":   "combobox",
+	"table":    "table",
+	"textarea": "textbox",
+	"ul":       "list",
+}
+
+// interactiveRoles need an accessible name to be announced meaningfully
+var interactiveRoles = toSet(
+	"button", "checkbox", "combobox", "link", "menuitem", "menuitemcheckbox",
+	"menuitemradio", "option", "radio", "searchbox", "slider", "spinbutton",
+	"switch", "tab", "textbox", "treeitem",
+)
+
+// idReferenceAttrs hold space-separated lists of element IDs
+var idReferenceAttrs = []string{"aria-labelledby", "aria-describedby", "aria-controls", "aria-owns"}
+
+// AnalyzeAccessibility runs the accessibility checks on the document
+func AnalyzeAccessibility(doc *goquery.Document) *models.AccessibilityReport {
+	report := &models.AccessibilityReport{}
+	report.Issues = append(report.Issues, checkARIA(doc)...)
+
+	landmarks, issues := checkLandmarks(doc)
+	report.Landmarks = landmarks
+	report.Issues = append(report.Issues, issues...)
+
+	positive, issues := checkTabOrder(doc)
+	report.PositiveTabIndex = positive
+	report.Issues = append(report.Issues, issues...)
+
+	skipLink, issues := checkSkipLink(doc)
+	report.HasSkipLink = skipLink
+	report.Issues = append(report.Issues, issues...)
+	report.Issues = append(report.Issues, checkFocusOutline(doc)...)
+
+	lang, issues := checkSemantics(doc)
+	report.Lang = lang
+	report.Issues = append(report.Issues, issues...)
+
+	return report
+}
+
+// checkARIA validates roles, ID references, redundant roles and accessible
+// names of interactive elements
+func checkARIA(doc *goquery.Document) []models.AccessibilityIssue {
+	var issues []models.AccessibilityIssue
+
+	ids := make(map[string]bool)
+	doc.Find("[id]").Each(func(i int, s *goquery.Selection) {
+		id, _ := s.Attr("id")
+		ids[id] = true
+	})
+
+	doc.Find("[role]").Each(func(i int, s *goquery.Selection) {
+		role, _ := s.Attr("role")
+		// The first recognized token wins, the rest are fallbacks
+		primary := strings.ToLower(firstField(role))
+
+		if primary == "" || !validRoles[primary] {
+			issues = append(issues, newIssue(RuleInvalidRole, s,
+				fmt.Sprintf("role %q is not a valid ARIA role", role)))
+			return
+		}
+
+		if implicitRole(s) == primary {
+			issues = append(issues, newIssue(RuleRedundantRole, s,
+				fmt.Sprintf("role %q duplicates the element's native semantics", primary)))
+		}
+	})
+
+	for _, attr := range idReferenceAttrs {
+		doc.Find("[" + attr + "]").Each(func(i int, s *goquery.Selection) {
+			value, _ := s.Attr(attr)
+			for _, ref := range strings.Fields(value) {
+				if !ids[ref] {
+					issues = append(issues, newIssue(RuleBrokenReference, s,
+						fmt.Sprintf("%s references missing id %q", attr, ref)))
+				}
+			}
+		})
+	}
+
+	doc.Find("button, a[href], [role]").Each(func(i int, s *goquery.Selection) {
+		role := effectiveRole(s)
+		if !interactiveRoles[role] || isHiddenFromAT(s) {
+			return
+		}
+
+		if accessibleName(doc, s) == "" {
+			issues = append(issues, newIssue(RuleMissingName, s,
+				fmt.Sprintf("interactive element with role %q has no accessible name", role)))
+		}
+	})
+
+	return issues
+}
+
+// implicitRole returns the native role of an element, if any
+func implicitRole(s *goquery.Selection) string {
+	tag := goquery.NodeName(s)
+	if tag == "a" {
+		if _, ok := s.Attr("href"); ok {
+			return "link"
+		}
+		return ""
+	}
+	if tag == "input" {
+		inputType, _ := s.Attr("type")
+		switch strings.ToLower(inputType) {
+		case "checkbox":
+			return "checkbox"
+		case "radio":
+			return "radio"
+		case "button", "submit", "reset", "image":
+			return "button"
+		case "range":
+			return "slider"
+		case "", "text", "email", "tel", "url":
+			return "textbox"
+		}
+		return ""
+	}
+	return implicitRoles[tag]
+}
+
+// effectiveRole returns the explicit role or falls back to the native one
+func effectiveRole(s *goquery.Selection) string {
+	if role, ok := s.Attr("role"); ok {
+		if primary := strings.ToLower(firstField(role)); primary != "" {
+			return primary
+		}
+	}
+	return implicitRole(s)
+}
+
+func isHiddenFromAT(s *goquery.Selection) bool {
+	if hidden, _ := s.Attr("aria-hidden"); hidden == "true" {
+		return true
+	}
+	_, hidden := s.Attr("hidden")
+	return hidden
+}
+
+// accessibleName approximates the accessible name computation: labelledby,
+// aria-label, alt text of contained images, text content, then title
+func accessibleName(doc *goquery.Document, s *goquery.Selection) string {
+	if labelledBy, ok := s.Attr("aria-labelledby"); ok {
+		var parts []string
+		for _, ref := range strings.Fields(labelledBy) {
+			if text := strings.TrimSpace(doc.Find("#" + escapeID(ref)).Text()); text != "" {
+				parts = append(parts, text)
+			}
+		}
+		if len(parts) > 0 {
+			return strings.Join(parts, " ")
+		}
+	}
+
+	if label, _ := s.Attr("aria-label"); strings.TrimSpace(label) != "" {
+		return strings.TrimSpace(label)
+	}
+
+	if text := strings.TrimSpace(s.Text()); text != "" {
+		return text
+	}
+
+	var alt string
+	s.Find("img[alt]").EachWithBreak(func(i int, img *goquery.Selection) bool {
+		alt, _ = img.Attr("alt")
+		alt = strings.TrimSpace(alt)
+		return alt == ""
+	})
+	if alt != "" {
+		return alt
+	}
+
+	if goquery.NodeName(s) == "input" {
+		if value, _ := s.Attr("value"); strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+
+	title, _ := s.Attr("title")
+	return strings.TrimSpace(title)
+}
+
+func newIssue(rule string, s *goquery.Selection, message string) models.AccessibilityIssue {
+	return models.AccessibilityIssue{
+		Rule:    rule,
+		Element: describeElement(s),
+		Message: message,
+	}
+}
+
+// describeElement renders a short opening-tag sample used to locate findings
+func describeElement(s *goquery.Selection) string {
+	var b strings.Builder
+	b.WriteString("<" + goquery.NodeName(s))
+	for _, attr := range []string{"id", "class", "role", "href", "src", "name", "type"} {
+		if value, ok := s.Attr(attr); ok {
+			fmt.Fprintf(&b, " %s=%q", attr, value)
+		}
+	}
+	b.WriteString(">")
+
+	desc := b.String()
+	if len(desc) > maxElementDescLength {
+		desc = desc[:maxElementDescLength-4] + "...>"
+	}
+	return desc
+}
+
+// escapeID makes an ID safe to use in a CSS selector
+func escapeID(id string) string {
+	var b strings.Builder
+	for _, r := range id {
+		if !(r == '-' || r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r > 127) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func firstField(s string) string {
+	if fields := strings.Fields(s); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+func toSet(values ...string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
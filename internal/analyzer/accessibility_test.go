@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func countRule(t *testing.T, html, rule string) int {
+	t.Helper()
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	count := 0
+	for _, issue := range AnalyzeAccessibility(doc).Issues {
+		if issue.Rule == rule {
+			count++
+		}
+	}
+	return count
+}
+
+func TestCheckARIA(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		rule     string
+		expected int
+	}{
+		{
+			name:     "Invalid role",
+			html:     `<div role="buton">Click</div><div role="button">Ok</div>`,
+			rule:     RuleInvalidRole,
+			expected: 1,
+		},
+		{
+			name:     "Broken labelledby reference",
+			html:     `<span id="lbl">Name</span><input aria-labelledby="lbl missing" aria-describedby="gone">`,
+			rule:     RuleBrokenReference,
+			expected: 2,
+		},
+		{
+			name:     "Redundant roles",
+			html:     `<nav role="navigation"></nav><button role="button">Go</button><ul role="menu"></ul>`,
+			rule:     RuleRedundantRole,
+			expected: 2,
+		},
+		{
+			name: "Missing accessible names",
+			html: `
+				<button></button>
+				<button aria-label="Close"></button>
+				<a href="/home"><img src="/logo.png"></a>
+				<a href="/home"><img src="/logo.png" alt="Home"></a>
+				<span id="t">Title</span><div role="tab" aria-labelledby="t"></div>
+				<div role="switch"></div>
+				<button aria-hidden="true"></button>
+			`,
+			rule:     RuleMissingName,
+			expected: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countRule(t, tt.html, tt.rule); got != tt.expected {
+				t.Errorf("Expected %d %s issues, got %d", tt.expected, tt.rule, got)
+			}
+		})
+	}
+}
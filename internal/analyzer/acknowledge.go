@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"website-analyzer/internal/models"
+)
+
+// AccessibilityTarget is the acknowledgement target for an accessibility
+// issue, which is identified by its rule and element
+func AccessibilityTarget(issue models.AccessibilityIssue) string {
+	return issue.Rule + "|" + issue.Element
+}
+
+// ApplyAcknowledgements moves findings matched by acks out of the report and
+// into result.Acknowledged, then rescores the result so suppressed findings
+// no longer count against it. Acknowledgements for other hosts are ignored.
+func ApplyAcknowledgements(result *models.AnalysisResult, acks []models.Acknowledgement) {
+	if result == nil || len(acks) == 0 {
+		return
+	}
+
+	host := getDomain(result.URL)
+	byKey := make(map[[2]string]models.Acknowledgement, len(acks))
+	for _, ack := range acks {
+		if ack.Host == host {
+			byKey[[2]string{ack.Kind, ack.Target}] = ack
+		}
+	}
+	if len(byKey) == 0 {
+		return
+	}
+
+	match := func(kind, target, detail string) bool {
+		ack, ok := byKey[[2]string{kind, target}]
+		if ok {
+			result.Acknowledged = append(result.Acknowledged, models.AcknowledgedFinding{Acknowledgement: ack, Detail: detail})
+		}
+		return ok
+	}
+
+	var links []models.LinkError
+	for _, link := range result.InaccessibleLinks {
+		if !match(models.FindingLink, link.URL, link.Error) {
+			links = append(links, link)
+		}
+	}
+	result.InaccessibleLinks = links
+
+	if result.Readiness != nil {
+		var issues []models.ReadinessIssue
+		for _, issue := range result.Readiness.Issues {
+			if !match(models.FindingReadiness, issue.Rule, issue.Message) {
+				issues = append(issues, issue)
+			}
+		}
+		result.Readiness.Issues = issues
+	}
+
+	if result.Accessibility != nil {
+		var issues []models.AccessibilityIssue
+		for _, issue := range result.Accessibility.Issues {
+			if !match(models.FindingAccessibility, AccessibilityTarget(issue), issue.Message) {
+				issues = append(issues, issue)
+			}
+		}
+		result.Accessibility.Issues = issues
+	}
+
+	result.Scores = ScoreResult(result)
+}
@@ -0,0 +1,70 @@
+package analyzer
+
+import (
+	"testing"
+
+	"website-analyzer/internal/models"
+)
+
+func TestApplyAcknowledgements(t *testing.T) {
+	altIssue := models.AccessibilityIssue{Rule: "img-alt", Element: `<img src="logo.png">`, Message: "Image is missing alt text"}
+	result := &models.AnalysisResult{
+		URL:           "https://example.com/",
+		InternalLinks: 2,
+		ExternalLinks: 2,
+		InaccessibleLinks: []models.LinkError{
+			{URL: "https://partner.com/promo", StatusCode: 404, Error: "HTTP 404"},
+			{URL: "https://example.com/gone", StatusCode: 404, Error: "HTTP 404"},
+		},
+		Readiness: &models.ReadinessReport{Issues: []models.ReadinessIssue{
+			{Rule: RuleMetaNoindex, Message: "Page is noindex"},
+		}},
+		Accessibility: &models.AccessibilityReport{Issues: []models.AccessibilityIssue{
+			altIssue,
+			{Rule: "img-alt", Element: `<img src="hero.png">`},
+		}},
+	}
+	before := ScoreResult(result)
+
+	ApplyAcknowledgements(result, []models.Acknowledgement{
+		{Host: "example.com", Kind: models.FindingLink, Target: "https://partner.com/promo", Note: "known partner outage"},
+		{Host: "example.com", Kind: models.FindingReadiness, Target: RuleMetaNoindex},
+		{Host: "example.com", Kind: models.FindingAccessibility, Target: AccessibilityTarget(altIssue)},
+		// Other hosts never match
+		{Host: "other.com", Kind: models.FindingLink, Target: "https://example.com/gone"},
+	})
+
+	if len(result.InaccessibleLinks) != 1 || result.InaccessibleLinks[0].URL != "https://example.com/gone" {
+		t.Errorf("Expected only the unacknowledged link to remain, got %+v", result.InaccessibleLinks)
+	}
+	if len(result.Readiness.Issues) != 0 {
+		t.Errorf("Expected readiness issue to be suppressed, got %+v", result.Readiness.Issues)
+	}
+	if len(result.Accessibility.Issues) != 1 || result.Accessibility.Issues[0].Element != `<img src="hero.png">` {
+		t.Errorf("Expected one accessibility issue to remain, got %+v", result.Accessibility.Issues)
+	}
+
+	if len(result.Acknowledged) != 3 {
+		t.Fatalf("Expected 3 acknowledged findings, got %+v", result.Acknowledged)
+	}
+	if got := result.Acknowledged[0]; got.Note != "known partner outage" || got.Detail != "HTTP 404" {
+		t.Errorf("Unexpected acknowledged link: %+v", got)
+	}
+
+	if result.Scores == nil || result.Scores.Links <= before.Links || result.Scores.SEO <= before.SEO {
+		t.Errorf("Expected scores to improve after suppression, before %+v after %+v", before, result.Scores)
+	}
+}
+
+func TestApplyAcknowledgementsNone(t *testing.T) {
+	result := &models.AnalysisResult{
+		URL:               "https://example.com/",
+		InaccessibleLinks: []models.LinkError{{URL: "https://example.com/gone"}},
+	}
+
+	ApplyAcknowledgements(result, nil)
+
+	if len(result.InaccessibleLinks) != 1 || result.Acknowledged != nil || result.Scores != nil {
+		t.Errorf("Expected result to be untouched, got %+v", result)
+	}
+}
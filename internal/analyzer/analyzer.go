@@ -1,13 +1,17 @@
 package analyzer
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"fmt"
-	"io"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"website-analyzer/internal/models"
+	"website-analyzer/internal/redact"
 	"website-analyzer/internal/validator"
 
 	"github.com/PuerkitoBio/goquery"
@@ -20,43 +24,351 @@ type Config struct {
 	MaxResponseSize int64
 	MaxURLLength    int
 	MaxRedirects    int
+	// RedirectChainMax is how many redirects the analyzed URL may go
+	// through before its chain is flagged; zero allows three
+	RedirectChainMax int
+	DeepAnalysis     bool // Fetch referenced resources for size/format checks
+	// LargeDocumentSize flags linked documents above this size whose anchor
+	// text carries no size hint
+	LargeDocumentSize int64
+	// SitemapAnalysis enables fetching robots.txt and the XML sitemap
+	SitemapAnalysis bool
+	// Crawl defaults used when CrawlOptions leaves them unset
+	CrawlMaxDepth int
+	CrawlMaxPages int
+	// CrawlMemoryURLs bounds the pages a crawl keeps in memory, seen and
+	// queued each; the rest spill to a temporary file in CrawlSpillDir,
+	// the system temporary directory when empty. Zero keeps all in memory.
+	CrawlMemoryURLs int
+	CrawlSpillDir   string
+	// CrawlBloomFPRate, between 0 and 1, tracks the pages a crawl has
+	// seen in a Bloom filter of far less memory, at the cost of skipping
+	// up to that share of new pages as seen; zero tracks them exactly
+	CrawlBloomFPRate float64
+	// Profiles available to requests; nil uses DefaultProfiles
+	Profiles       map[string]Profile
+	DefaultProfile string
+	// GateTolerance is how many points a score may fall below the baseline
+	// before a regression gate fails
+	GateTolerance int
+	// Link check retries for transient failures; see CheckLinksConfig
+	LinkMaxAttempts  int
+	LinkRetryBackoff time.Duration
+	LinkRetryJitter  float64
+	// LinkCheckGetOnly checks links with GET only, for servers that
+	// mishandle HEAD
+	LinkCheckGetOnly bool
+	// RespectRobots skips internal links and crawl pages that the target's
+	// robots.txt disallows for our user agent
+	RespectRobots bool
+	// RedactParams names query parameters whose values are masked in
+	// results and errors; empty disables redaction
+	RedactParams []string
+	// Per-host link check limits shared by all pages of a run; see
+	// CheckLinksConfig
+	LinkHostRate     float64
+	LinkHostInFlight int
+	// CircuitBreakerTTL is how long a failing domain is remembered across
+	// analyses after its last failure
+	CircuitBreakerTTL time.Duration
+	// RenderMode "browser" analyzes the DOM rendered by headless Chrome,
+	// found on the PATH or at BrowserPath, instead of the raw HTML
+	RenderMode  string
+	BrowserPath string
+	// BotInfoURL is the public address of the page describing the bot,
+	// linked from the User-Agent header
+	BotInfoURL string
+	// OptOutDomains are never contacted, neither as targets nor by link
+	// checks; subdomains are included
+	OptOutDomains []string
+	// CacheTTL is how long analysis results are reused for repeated
+	// requests; zero disables the cache
+	CacheTTL time.Duration
+	// ResultCache replaces the in-memory cache, e.g. with one shared by
+	// several replicas
+	ResultCache ResultCache
+	// LinkScope decides which links count as internal, see ParseLinkScope;
+	// profiles may override it
+	LinkScope string
+	// KeepSnapshots keeps the analyzed HTML with each result so stored
+	// analyses can be replayed with later checks, see Replay
+	KeepSnapshots bool
+	// WeightBudget flags pages that load too much
+	WeightBudget Budget
+	// SlowestLinks is how many of the slowest links results list; zero
+	// lists ten
+	SlowestLinks int
+	// Flags are the feature flags switched on for every analysis;
+	// RequestFlags lists those requests may change. Nil Flags are the
+	// DefaultFlags.
+	Flags        Flags
+	RequestFlags []string
+	// Fetchers adds named fetchers requests may select, or replaces the
+	// built-in ones; DefaultFetcher names the one used when a request
+	// selects none, see Analyzer.Fetcher
+	Fetchers       map[string]Fetcher
+	DefaultFetcher string
+	// TechRules recognize the technologies pages use; nil uses
+	// DefaultTechRules
+	TechRules []TechRule
+	// TrackerRules recognize the analytics, advertising and session
+	// recording scripts pages load; nil uses DefaultTrackerRules
+	TrackerRules []TechRule
+	// Regions checks the page and its external links from other network
+	// locations; nil checks them from here only. Region names this
+	// server's location in the results, "local" when empty.
+	Regions RegionChecker
+	Region  string
 }
 
 type Analyzer struct {
-	config     *Config
-	httpClient *http.Client
+	config         *Config
+	httpClient     *http.Client
+	resourceClient *http.Client
+	redactor       *redact.Redactor
+	// breaker is shared by every analysis so domains that keep failing
+	// stay skipped between requests
+	breaker  *circuitBreaker
+	optOut   domainList
+	cache    ResultCache
+	fetchers map[string]Fetcher
 }
 
 func NewAnalyzer(config *Config) *Analyzer {
-	return &Analyzer{
+	if config.Profiles == nil {
+		config.Profiles = DefaultProfiles(config)
+	}
+	if config.Flags == nil {
+		config.Flags = DefaultFlags(config)
+	}
+	if config.TechRules == nil {
+		config.TechRules = DefaultTechRules()
+	}
+	if config.TrackerRules == nil {
+		config.TrackerRules = DefaultTrackerRules()
+	}
+
+	optOut := newDomainList(config.OptOutDomains)
+	outbound := outboundTransport{userAgent: UserAgent(config.BotInfoURL), optOut: optOut}
+	a := &Analyzer{
 		config: config,
 		httpClient: &http.Client{
-			Timeout: config.RequestTimeout,
+			Timeout:       config.RequestTimeout,
+			Transport:     meteredTransport{base: outbound},
+			CheckRedirect: checkRedirect(cmp.Or(config.MaxRedirects, defaultMaxRedirects)),
+		},
+		resourceClient: &http.Client{
+			Timeout:       config.LinkTimeout,
+			Transport:     meteredTransport{base: outbound},
+			CheckRedirect: checkRedirect(cmp.Or(config.MaxRedirects, defaultMaxRedirects)),
 		},
+		redactor: redact.New(config.RedactParams),
+		breaker:  newSharedCircuitBreaker(config.CircuitBreakerTTL),
+		optOut:   optOut,
+		cache:    newResultCache(config),
 	}
+	a.fetchers = a.newFetchers()
+	return a
+}
+
+// UserAgent is the User-Agent header the analyzer identifies itself with
+func (a *Analyzer) UserAgent() string {
+	return UserAgent(a.config.BotInfoURL)
 }
 
-func (a *Analyzer) Analyze(targetURL string) (*models.AnalysisResult, error) {
-	// Validate URL
-	if err := validator.ValidateURL(targetURL, a.config.MaxURLLength); err != nil {
-		return nil, fmt.Errorf("invalid URL: %w", err)
+// RespectsRobots reports whether robots.txt rules are followed
+func (a *Analyzer) RespectsRobots() bool {
+	return a.config.RespectRobots
+}
+
+// newSharedCircuitBreaker returns the analyzer-wide breaker. Without a TTL
+// failures would be remembered forever, so a zero TTL falls back to ten
+// minutes.
+func newSharedCircuitBreaker(ttl time.Duration) *circuitBreaker {
+	if ttl <= 0 {
+		ttl = 10 * time.Minute
 	}
+	cb := newCircuitBreaker(5)
+	cb.ttl = ttl
+	return cb
+}
 
-	// Fetch HTML
-	doc, err := a.fetchHTML(targetURL)
+// CircuitBreakerState lists the domains the shared circuit breaker has seen
+// failing recently
+func (a *Analyzer) CircuitBreakerState() []models.CircuitState {
+	return a.breaker.snapshot()
+}
+
+// Redactor masks the configured sensitive query parameters; it is nil when
+// redaction is disabled
+func (a *Analyzer) Redactor() *redact.Redactor {
+	return a.redactor
+}
+
+// AnalyzeOptions are per-request settings supplied by the user
+type AnalyzeOptions struct {
+	// Keyword enables the target phrase audit when non-empty
+	Keyword string
+	// Profile names the set of checks to run; empty uses the default
+	Profile string
+	// Force analyzes the page even when a cached result is available
+	Force bool
+	// IncludeLinks lists every link with its anchor text, rel and target
+	// attributes and check outcome in LinkDetails
+	IncludeLinks bool
+	// Flags switches feature flags on ("simhash") or off ("-simhash") for
+	// this analysis, see ParseFlags
+	Flags string
+	// Fetcher names how the page is obtained ("http", "browser",
+	// "cached", ...); empty uses the default, see Analyzer.Fetcher
+	Fetcher string
+}
+
+// Analyze fetches and analyzes a single page. Cancelling ctx aborts the
+// fetch and any link checks still in flight.
+func (a *Analyzer) Analyze(ctx context.Context, targetURL string) (*models.AnalysisResult, error) {
+	return a.AnalyzeWithOptions(ctx, targetURL, AnalyzeOptions{})
+}
+
+// AnalyzeWithOptions is Analyze with per-request options
+func (a *Analyzer) AnalyzeWithOptions(ctx context.Context, targetURL string, opts AnalyzeOptions) (*models.AnalysisResult, error) {
+	profile, err := a.profile(opts.Profile)
+	if err != nil {
+		return nil, err
+	}
+	flags, err := a.flags(opts.Flags)
+	if err != nil {
+		return nil, err
+	}
+	fetcher, err := a.Fetcher(opts.Fetcher, flags)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, meter := withUsage(ctx)
+	key := resultCacheKey(targetURL, profile, opts, flags)
+	if !opts.Force {
+		// A cached result costs no pages or requests
+		if result, analyzed, ok := a.cachedResult(ctx, key); ok {
+			result.CachedAt = analyzed
+			result.Usage = meter.usage(0)
+			return result, nil
+		}
+	}
+
+	result, _, err := a.analyzePage(ctx, targetURL, &pageContext{
+		opts: opts, profile: profile, flags: flags, fetcher: fetcher, keepSnapshot: a.config.KeepSnapshots,
+	})
+	if err != nil {
+		return nil, a.redactor.Error(err)
+	}
+	result.Usage = meter.usage(1)
+	a.redactor.Walk(result)
+	a.cacheResult(ctx, key, result)
+	return result, nil
+}
+
+// pageContext carries state shared by the pages of one analysis run
+type pageContext struct {
+	opts    AnalyzeOptions
+	profile Profile
+	flags   Flags
+	fetcher Fetcher
+	// replay is the stored result of a replayed snapshot; checks that
+	// need the network are skipped and their findings taken from it
+	replay *models.AnalysisResult
+	// keepSnapshot keeps the page's HTML with its result
+	keepSnapshot bool
+	siteOnce     sync.Once
+	site         *siteFiles
+	// checked caches link check outcomes across pages; nil disables sharing
+	checked *linkStatusCache
+
+	robotsOnce sync.Once
+	robots     *robotsTxt
+	robotsErr  error
+
+	limiterOnce sync.Once
+	limiter     *hostLimiter
+}
+
+// hostLimiter returns the per-host link check limiter of the run
+func (pc *pageContext) hostLimiter(a *Analyzer) *hostLimiter {
+	pc.limiterOnce.Do(func() {
+		pc.limiter = newHostLimiter(a.config.LinkHostRate, a.config.LinkHostInFlight)
+	})
+	return pc.limiter
+}
+
+// robotsFile loads robots.txt once per run
+func (pc *pageContext) robotsFile(ctx context.Context, a *Analyzer, targetURL string) (*robotsTxt, error) {
+	pc.robotsOnce.Do(func() {
+		pc.robots, pc.robotsErr = fetchRobots(ctx, a.resourceClient, targetURL)
+	})
+	return pc.robots, pc.robotsErr
+}
+
+// siteFiles loads robots.txt and sitemaps once per run
+func (pc *pageContext) siteFiles(ctx context.Context, a *Analyzer, targetURL string) *siteFiles {
+	pc.siteOnce.Do(func() {
+		robots, err := pc.robotsFile(ctx, a, targetURL)
+		pc.site = a.loadSiteFiles(ctx, targetURL, robots, err)
+	})
+	return pc.site
+}
+
+// disallowedByRobots reports whether RespectRobots is on and the target's
+// robots.txt disallows link. A robots.txt that can't be fetched allows
+// everything.
+func (pc *pageContext) disallowedByRobots(ctx context.Context, a *Analyzer, targetURL string, link models.Link) bool {
+	if !a.config.RespectRobots || link.Type != models.LinkTypeInternal {
+		return false
+	}
+	robots, err := pc.robotsFile(ctx, a, targetURL)
+	if err != nil {
+		return false
+	}
+	return !robots.allowed(robotsAgent, link.URL)
+}
+
+// analyzePage runs every check on a single page and also returns the
+// extracted links so callers such as the crawler can follow them
+func (a *Analyzer) analyzePage(ctx context.Context, targetURL string, pc *pageContext) (*models.AnalysisResult, []models.Link, error) {
+	// Validate URL; recordings are replayed without resolving the host
+	validate := validator.ValidateTarget
+	if offline(pc.fetcher) {
+		validate = validator.ValidateSyntax
+	}
+	if err := validate(targetURL, a.config.MaxURLLength); err != nil {
+		return nil, nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if a.optOut.containsURL(targetURL) {
+		return nil, nil, ErrOptedOut
+	}
+
+	// Fetch HTML
+	clock := &stageClock{}
+	fetchStart := time.Now()
+	doc, page, err := a.fetchHTML(ctx, targetURL, pc.fetcher, clock)
+	if err != nil {
+		return nil, nil, err
+	}
+	responseTime := time.Since(fetchStart)
+
 	// Extract links
-	links, err := ExtractLinks(doc, targetURL)
+	stop := clock.start(StageExtractLinks)
+	links, err := ExtractLinks(doc, targetURL, a.linkScope(pc.profile))
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract links: %w", err)
+		return nil, nil, fmt.Errorf("failed to extract links: %w", err)
 	}
+	stop()
 
-	// Count internal/external
+	// Count internal/external; the URLs are kept so later runs can be
+	// diffed against this one
 	var internal, external int
+	linkURLs := make([]string, 0, len(links))
 	for _, link := range links {
+		linkURLs = append(linkURLs, link.URL)
 		if link.Type == models.LinkTypeInternal {
 			internal++
 		}
@@ -66,57 +378,339 @@ func (a *Analyzer) Analyze(targetURL string) (*models.AnalysisResult, error) {
 		}
 	}
 
+	prof := pc.profile
+	online := pc.replay == nil
+	maxWorkers := a.config.MaxWorkers
+	if prof.MaxWorkers > 0 {
+		maxWorkers = prof.MaxWorkers
+	}
+
 	// Check link accessibility
-	checkConfig := CheckLinksConfig{
-		Timeout:      a.config.LinkTimeout,
-		MaxWorkers:   a.config.MaxWorkers,
-		MaxRedirects: a.config.MaxRedirects,
+	checkConfig := a.checkLinksConfig(prof, maxWorkers, pc.hostLimiter(a))
+	var statuses []models.LinkStatus
+	var robotsSkipped, optedOut, skipped []string
+	if prof.Enabled(LinksCheck) && online {
+		stop := clock.start(string(LinksCheck))
+		var checked []models.Link
+		for _, link := range links {
+			if a.optOut.containsURL(link.URL) {
+				optedOut = append(optedOut, link.URL)
+				continue
+			}
+			if outOfScope(link.URL) {
+				skipped = append(skipped, link.URL)
+				continue
+			}
+			if pc.disallowedByRobots(ctx, a, targetURL, link) {
+				robotsSkipped = append(robotsSkipped, link.URL)
+				continue
+			}
+			checked = append(checked, link)
+		}
+		if prof.MaxLinks > 0 && len(checked) > prof.MaxLinks {
+			checked = checked[:prof.MaxLinks]
+		}
+		statuses = pc.checked.check(ctx, checked, checkConfig)
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		stop()
+	}
+	restricted, remaining := SplitRestricted(statuses)
+
+	// Links checked here are checked again from the other regions
+	var regions *models.RegionReport
+	if prof.Enabled(LinksCheck) && online && a.config.Regions != nil {
+		stop := clock.start(StageRegions)
+		regions = a.checkRegions(ctx, targetURL, statuses)
+		stop()
 	}
-	inaccessible := CheckLinks(links, checkConfig)
 
 	// Build result
+	info := a.info(prof, pc.flags)
 	result := &models.AnalysisResult{
 		URL:               targetURL,
+		Profile:           prof.Name,
 		HTMLVersion:       DetectHTMLVersion(doc),
 		Title:             ExtractTitle(doc),
+		HTMLSize:          page.Size,
+		ResponseTimeMs:    responseTime.Milliseconds(),
+		Timing:            page.Timing,
+		Protocol:          DetectProtocol(page),
+		Redirects:         RedirectReport(page.Redirects, a.config.RedirectChainMax),
+		WordCount:         len(strings.Fields(visibleText(doc))),
 		Headings:          CountHeadings(doc),
 		InternalLinks:     internal,
 		ExternalLinks:     external,
-		InaccessibleLinks: inaccessible,
+		Links:             linkURLs,
+		InaccessibleLinks: InaccessibleLinks(remaining),
+		LinkDetails:       linkDetails(links, statuses, pc.opts.IncludeLinks),
+		Restricted:        restricted,
+		RobotsSkipped:     robotsSkipped,
+		OptedOut:          optedOut,
+		OutOfScope:        skipped,
+		InlineLinks:       ExtractInlineLinks(doc),
+		Analyzer:          &info,
 		HasLoginForm:      HasLoginForm(doc),
+		ExternalDomains:   SummarizeDomains(statuses),
+		LinkLatency:       SummarizeLatency(statuses, a.config.SlowestLinks),
+		Regions:           regions,
+		ArchiveDirectives: ArchiveDirectives(doc, page.Header),
+	}
+	if pc.keepSnapshot {
+		result.Snapshot = snapshotOf(targetURL, page)
 	}
 
-	return result, nil
-}
+	// Metadata is always extracted; other checks read the canonical URL
+	stop = clock.start(string(SEOCheck))
+	seo := ExtractSEO(doc, targetURL)
+	seo.Preview = BuildSERPPreview(result.Title, seo.Description, targetURL)
+	if prof.Enabled(SEOCheck) {
+		result.SEO = seo
+	}
+	stop()
+	if prof.Enabled(LazyLoadCheck) {
+		stop := clock.start(string(LazyLoadCheck))
+		result.LazyLoading = AuditLazyLoading(doc)
+		stop()
+	}
+	if prof.Enabled(DataURICheck) {
+		stop := clock.start(string(DataURICheck))
+		result.DataURIs = AuditDataURIs(doc)
+		stop()
+	}
+	if prof.Enabled(AccessibilityCheck) {
+		stop := clock.start(string(AccessibilityCheck))
+		result.Accessibility = AnalyzeAccessibility(doc)
+		stop()
+	}
+	if prof.Enabled(DocumentsCheck) && online {
+		stop := clock.start(string(DocumentsCheck))
+		result.Documents = InventoryDocuments(ctx, doc, targetURL, a.resourceClient, maxWorkers, a.config.LargeDocumentSize)
+		stop()
+	}
+	if prof.Enabled(ResourcesCheck) {
+		// Resources are listed always and checked like links in deep mode
+		var resourceCheck *CheckLinksConfig
+		if prof.DeepAnalysis && pc.flags.Enabled(FlagDeepResources) && online {
+			resourceCheck = &checkConfig
+		}
+		stop := clock.start(string(ResourcesCheck))
+		result.Resources = a.auditResources(ctx, pc, ExtractResources(doc, targetURL, a.linkScope(prof)), resourceCheck)
+		stop()
+	}
+	if pc.flags.Enabled(FlagSimHash) {
+		stop := clock.start(StageSimHash)
+		result.SimHash = SimHash(visibleText(doc))
+		stop()
+	}
+	if prof.Enabled(WeightCheck) {
+		// Resource sizes are measured in deep mode
+		var weightClient *http.Client
+		if prof.DeepAnalysis && pc.flags.Enabled(FlagDeepResources) && online {
+			weightClient = a.resourceClient
+		}
+		stop := clock.start(string(WeightCheck))
+		result.Weight = MeasurePageWeight(ctx, doc, targetURL, page.Size, a.config.WeightBudget, weightClient, maxWorkers)
+		stop()
+	}
+	if prof.Enabled(CachingCheck) {
+		// Asset lifetimes and brotli support are probed in deep mode
+		var cachingClient *http.Client
+		if prof.DeepAnalysis && pc.flags.Enabled(FlagDeepResources) && online {
+			cachingClient = a.resourceClient
+		}
+		stop := clock.start(string(CachingCheck))
+		result.Caching = AuditCaching(ctx, doc, targetURL, page.Header, cachingClient, maxWorkers)
+		stop()
+	}
+	if prof.Enabled(FragmentsCheck) {
+		stop := clock.start(string(FragmentsCheck))
+		result.Fragments = CheckFragments(doc)
+		stop()
+	}
+	if prof.Enabled(TechnologiesCheck) {
+		stop := clock.start(string(TechnologiesCheck))
+		result.Technologies = DetectTechnologies(doc, targetURL, page.Header, a.config.TechRules)
+		stop()
+	}
+	if prof.Enabled(TrackersCheck) {
+		stop := clock.start(string(TrackersCheck))
+		result.Trackers = DetectTechnologies(doc, targetURL, page.Header, a.config.TrackerRules)
+		stop()
+	}
+	if prof.Enabled(IconsCheck) {
+		// Icons are checked, and manifest icons found, unless replaying
+		var iconClient *http.Client
+		if online {
+			iconClient = a.resourceClient
+		}
+		stop := clock.start(string(IconsCheck))
+		result.Icons = AuditIcons(ctx, doc, targetURL, iconClient, maxWorkers)
+		stop()
+	}
+	if prof.Enabled(ThirdPartiesCheck) {
+		stop := clock.start(string(ThirdPartiesCheck))
+		result.ThirdParties = InventoryThirdParties(doc, targetURL, links, a.linkScope(prof))
+		stop()
+	}
+	if prof.Enabled(RelCheck) {
+		stop := clock.start(string(RelCheck))
+		result.RelCompliance = AuditRelAttributes(links)
+		stop()
+	}
+	if prof.Enabled(InsecureCheck) && online {
+		stop := clock.start(string(InsecureCheck))
+		result.InsecureLinks = AuditInsecureLinks(ctx, links, a.resourceClient, maxWorkers)
+		stop()
+	}
+	if prof.Enabled(StructuredDataCheck) {
+		stop := clock.start(string(StructuredDataCheck))
+		result.StructuredData = AnalyzeStructuredData(doc)
+		stop()
+	}
+	if prof.Enabled(FeedsCheck) && online {
+		stop := clock.start(string(FeedsCheck))
+		result.Feeds = CheckFeeds(ctx, doc, targetURL, a.resourceClient, maxWorkers)
+		stop()
+	}
 
-func (a *Analyzer) fetchHTML(url string) (*goquery.Document, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), a.config.RequestTimeout)
-	defer cancel()
+	// Hreflang from link tags, merged with sitemap alternates when enabled
+	var fromSitemap []models.HreflangAlternate
+	var robots *robotsTxt
+	if prof.SitemapAnalysis && online {
+		stop := clock.start(StageSitemaps)
+		site := pc.siteFiles(ctx, a, targetURL)
+		robots = site.robots
+		fromSitemap = sitemapHreflang(site.sitemaps, targetURL)
+		if prof.Enabled(SiteCheck) {
+			result.Site = buildSiteReport(site, targetURL, seo.Canonical)
+		}
+		stop()
+	}
+	if prof.Enabled(HreflangCheck) {
+		stop := clock.start(string(HreflangCheck))
+		result.Hreflang = mergeHreflang(ExtractHreflang(doc, targetURL), fromSitemap, prof.SitemapAnalysis && online)
+		stop()
+	}
+	if prof.Enabled(ReadinessCheck) {
+		stop := clock.start(string(ReadinessCheck))
+		result.Readiness = CheckProductionReadiness(doc, robots)
+		stop()
+	}
+	if pc.opts.Keyword != "" {
+		stop := clock.start(StageKeyword)
+		result.Keyword = AuditKeyword(doc, targetURL, pc.opts.Keyword)
+		stop()
+	}
+	if prof.Enabled(SocialCheck) && online {
+		stop := clock.start(string(SocialCheck))
+		result.Social = BuildSocialPreviews(ctx, seo, result.Title, targetURL, a.resourceClient)
+		stop()
+	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, err
+	// Deep mode checks fetch referenced resources
+	if prof.DeepAnalysis && pc.flags.Enabled(FlagDeepResources) {
+		if prof.Enabled(ImagesCheck) && online {
+			stop := clock.start(string(ImagesCheck))
+			result.ImageFormats = AuditImageFormats(ctx, doc, targetURL, a.resourceClient, maxWorkers)
+			stop()
+		}
+		if prof.Enabled(ContrastCheck) && result.Accessibility != nil {
+			stop := clock.start(string(ContrastCheck))
+			result.Accessibility.Contrast = CheckContrast(doc)
+			stop()
+		}
 	}
 
-	req.Header.Set("User-Agent", "WebPageAnalyzer/1.0")
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
 
-	resp, err := a.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch URL: %w", err)
+	if !online {
+		carryOver(result, pc.replay)
+	}
+	stop = clock.start(StageScore)
+	result.Scores = ScoreResult(result)
+	stop()
+	result.Stages = clock.stages
+
+	return result, links, nil
+}
+
+// checkLinksConfig returns the link check settings of prof, with the
+// analyzer's retries, identity and shared circuit breaker
+func (a *Analyzer) checkLinksConfig(prof Profile, maxWorkers int, limiter *hostLimiter) CheckLinksConfig {
+	return CheckLinksConfig{
+		Timeout:      a.linkTimeout(prof),
+		MaxWorkers:   maxWorkers,
+		MaxRedirects: a.config.MaxRedirects,
+		MaxAttempts:  a.config.LinkMaxAttempts,
+		RetryBackoff: a.config.LinkRetryBackoff,
+		RetryJitter:  a.config.LinkRetryJitter,
+		GetOnly:      a.config.LinkCheckGetOnly,
+		limiter:      limiter,
+		breaker:      a.breaker,
+		userAgent:    a.UserAgent(),
+		optOut:       a.optOut,
 	}
-	defer resp.Body.Close()
+}
+
+// siteFiles holds the origin-level files fetched for site analysis
+type siteFiles struct {
+	robots      *robotsTxt
+	robotsErr   error
+	sitemaps    []*sitemapFile
+	sitemapErrs []error
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+// loadSiteFiles fetches the sitemaps declared by robots.txt
+func (a *Analyzer) loadSiteFiles(ctx context.Context, targetURL string, robots *robotsTxt, robotsErr error) *siteFiles {
+	site := &siteFiles{robots: robots, robotsErr: robotsErr}
+	site.sitemaps, site.sitemapErrs = loadSitemaps(ctx, a.resourceClient, discoverSitemaps(site.robots, targetURL))
+	return site
+}
+
+// buildSiteReport turns the fetched origin files into hygiene findings
+func buildSiteReport(site *siteFiles, targetURL, canonical string) *models.SiteReport {
+	report := &models.SiteReport{}
+
+	if site.robotsErr != nil {
+		report.RobotsWarnings = append(report.RobotsWarnings, fmt.Sprintf("robots.txt could not be fetched: %v", site.robotsErr))
+	} else {
+		report.RobotsFound = true
+		report.RobotsWarnings = lintRobots(site.robots.Raw, getDomain(targetURL))
 	}
 
-	// Limit response size
-	limitedReader := io.LimitReader(resp.Body, a.config.MaxResponseSize)
+	for _, err := range site.sitemapErrs {
+		report.SitemapIssues = append(report.SitemapIssues, err.Error())
+	}
+	sitemaps, issues := validateSitemaps(site.sitemaps, targetURL, canonical)
+	report.Sitemaps = sitemaps
+	report.SitemapIssues = append(report.SitemapIssues, issues...)
+
+	return report
+}
+
+// fetchHTML obtains the page through fetcher and parses it, timing both on
+// clock, also returning the fetched page for its size, headers and timing
+func (a *Analyzer) fetchHTML(ctx context.Context, url string, fetcher Fetcher, clock *stageClock) (*goquery.Document, *FetchedPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.config.RequestTimeout)
+	defer cancel()
+
+	stop := clock.start(StageFetch)
+	page, err := fetcher.Fetch(ctx, url)
+	if err != nil {
+		return nil, nil, err
+	}
+	stop()
 
-	doc, err := goquery.NewDocumentFromReader(limitedReader)
+	stop = clock.start(StageParse)
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(page.Body))
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
+	stop()
 
-	return doc, nil
+	return doc, page, nil
 }
@@ -1,6 +1,8 @@
 package analyzer
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -42,7 +44,7 @@ func TestAnalyzer_Analyze(t *testing.T) {
 
 	a := NewAnalyzer(config)
 
-	result, err := a.Analyze(ts.URL)
+	result, err := a.Analyze(context.Background(), ts.URL)
 	if err != nil {
 		t.Fatalf("Analyze failed: %v", err)
 	}
@@ -67,3 +69,127 @@ func TestAnalyzer_Analyze(t *testing.T) {
 		t.Error("Expected login form to be detected")
 	}
 }
+
+func TestAnalyzer_AnalyzeCancelled(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			// The client goes away while link checks are in flight
+			cancel()
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body><a href="/slow">Slow</a></body></html>`))
+	}))
+	defer ts.Close()
+
+	a := NewAnalyzer(&Config{
+		RequestTimeout:  5 * time.Second,
+		LinkTimeout:     5 * time.Second,
+		MaxWorkers:      2,
+		MaxResponseSize: 1024 * 1024,
+		MaxURLLength:    2048,
+		MaxRedirects:    10,
+	})
+
+	start := time.Now()
+	_, err := a.Analyze(ctx, ts.URL)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected cancellation to abort link checks, took %v", elapsed)
+	}
+}
+
+func TestAnalyzer_AnalyzeRedacts(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Query().Get("token") != "s3cret" {
+			t.Errorf("Expected the real token to be sent, got %q", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>Account</title></head><body><a href="/gone?session=abc">Gone</a></body></html>`))
+	}))
+	defer ts.Close()
+
+	a := NewAnalyzer(&Config{
+		RequestTimeout:  2 * time.Second,
+		LinkTimeout:     time.Second,
+		MaxWorkers:      2,
+		MaxResponseSize: 1024 * 1024,
+		MaxURLLength:    2048,
+		MaxRedirects:    10,
+		RedactParams:    []string{"token", "session"},
+	})
+
+	result, err := a.Analyze(context.Background(), ts.URL+"/?token=s3cret")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	if result.URL != ts.URL+"/?token=REDACTED" {
+		t.Errorf("Expected result URL to be redacted, got %q", result.URL)
+	}
+	if len(result.InaccessibleLinks) != 1 || result.InaccessibleLinks[0].URL != ts.URL+"/gone?session=REDACTED" {
+		t.Errorf("Expected broken link URL to be redacted, got %+v", result.InaccessibleLinks)
+	}
+}
+
+func TestAnalyzer_AnalyzeUsage(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	page := `<html><body><a href="/a">A</a><a href="/b">B</a><a href="/c">C</a></body></html>`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(page))
+	}))
+	defer ts.Close()
+
+	a := NewAnalyzer(&Config{
+		RequestTimeout:  2 * time.Second,
+		LinkTimeout:     time.Second,
+		MaxWorkers:      3,
+		MaxResponseSize: 1024 * 1024,
+		MaxURLLength:    2048,
+		MaxRedirects:    10,
+	})
+
+	result, err := a.Analyze(context.Background(), ts.URL)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	usage := result.Usage
+	if usage == nil {
+		t.Fatal("Expected resource usage to be recorded")
+	}
+	// The page itself and a check for each of the three links, plus any
+	// resources the default profile fetches
+	if usage.Requests < 4 {
+		t.Errorf("Expected at least 4 requests, got %d", usage.Requests)
+	}
+	if usage.BytesDownloaded < int64(len(page)) {
+		t.Errorf("Expected at least the page to be counted, got %d bytes", usage.BytesDownloaded)
+	}
+	if usage.PeakGoroutines < 2 || usage.PeakGoroutines > 4 {
+		t.Errorf("Expected the caller plus up to 3 workers, got peak %d", usage.PeakGoroutines)
+	}
+}
@@ -0,0 +1,45 @@
+package analyzer
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// archiveRestrictions are the robots directives asking search engines not
+// to keep a copy of the page or show excerpts of it
+var archiveRestrictions = []string{"noarchive", "nosnippet"}
+
+// ArchiveDirectives returns the noarchive and nosnippet directives declared
+// by the page's robots meta tags, for all crawlers or a named one, and its
+// X-Robots-Tag headers, whose directives may be prefixed with a user agent
+// ("googlebot: noarchive")
+func ArchiveDirectives(doc *goquery.Document, header http.Header) []string {
+	var found []string
+	add := func(content string) {
+		for _, directive := range strings.Split(content, ",") {
+			directive = strings.ToLower(strings.TrimSpace(directive))
+			if _, rest, ok := strings.Cut(directive, ":"); ok {
+				directive = strings.TrimSpace(rest)
+			}
+			if slices.Contains(archiveRestrictions, directive) && !slices.Contains(found, directive) {
+				found = append(found, directive)
+			}
+		}
+	}
+
+	doc.Find("meta[name][content]").Each(func(i int, s *goquery.Selection) {
+		name := strings.ToLower(strings.TrimSpace(s.AttrOr("name", "")))
+		if name == "robots" || strings.HasSuffix(name, "bot") {
+			add(s.AttrOr("content", ""))
+		}
+	})
+	for _, value := range header.Values("X-Robots-Tag") {
+		add(value)
+	}
+
+	slices.Sort(found)
+	return found
+}
@@ -0,0 +1,36 @@
+package analyzer
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestArchiveDirectives(t *testing.T) {
+	tests := []struct {
+		name   string
+		html   string
+		header http.Header
+		want   []string
+	}{
+		{"none", `<meta name="robots" content="index, follow">`, nil, nil},
+		{"robots meta", `<meta name="robots" content="NOARCHIVE, nofollow">`, nil, []string{"noarchive"}},
+		{"crawler meta", `<meta name="googlebot" content="nosnippet"><meta name="description" content="noarchive">`, nil, []string{"nosnippet"}},
+		{"header with agent", "", http.Header{"X-Robots-Tag": {"googlebot: noarchive", "nosnippet, noarchive"}}, []string{"noarchive", "nosnippet"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><head>" + tt.html + "</head></html>"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ArchiveDirectives(doc, tt.header); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
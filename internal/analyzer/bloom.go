@@ -0,0 +1,94 @@
+package analyzer
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+)
+
+// bloomInitialCapacity is how many keys the first filter of a bloomSet is
+// sized for; each filter added after it holds twice as many as the last
+const bloomInitialCapacity = 1 << 16
+
+// bloomSet is an approximate set of strings in a fraction of the memory of
+// a map: it may claim to hold a key it doesn't, at about the configured
+// false-positive rate, but never misses one it holds. It grows without a
+// size given up front by adding filters, each with half the false-positive
+// rate of the last, so the combined rate stays under the configured one.
+type bloomSet struct {
+	filters []*bloomFilter
+	// rate is the false-positive rate of the next filter added
+	rate float64
+}
+
+// newBloomSet returns a set whose false-positive rate, 0 < rate < 1, stays
+// below falsePositive however many keys are added
+func newBloomSet(falsePositive float64) *bloomSet {
+	return &bloomSet{rate: falsePositive / 2}
+}
+
+// add adds key, reporting whether it was (probably) not in the set before
+func (s *bloomSet) add(key string) bool {
+	h1, h2 := bloomHash(key)
+	for _, f := range s.filters {
+		if f.contains(h1, h2) {
+			return false
+		}
+	}
+	last := len(s.filters) - 1
+	if last < 0 || s.filters[last].count >= s.filters[last].capacity {
+		capacity := bloomInitialCapacity
+		if last >= 0 {
+			capacity = s.filters[last].capacity * 2
+		}
+		s.filters = append(s.filters, newBloomFilter(capacity, s.rate))
+		s.rate /= 2
+		last++
+	}
+	s.filters[last].add(h1, h2)
+	return true
+}
+
+// bloomFilter is a fixed-size Bloom filter sized for capacity keys
+type bloomFilter struct {
+	bits     []uint64
+	m        uint64 // number of bits
+	k        uint64 // hashes per key
+	count    int
+	capacity int
+}
+
+func newBloomFilter(capacity int, rate float64) *bloomFilter {
+	m := math.Ceil(-float64(capacity) * math.Log(rate) / (math.Ln2 * math.Ln2))
+	k := max(math.Round(m/float64(capacity)*math.Ln2), 1)
+	words := (uint64(m) + 63) / 64
+	return &bloomFilter{bits: make([]uint64, words), m: words * 64, k: uint64(k), capacity: capacity}
+}
+
+func (f *bloomFilter) add(h1, h2 uint64) {
+	for i := range f.k {
+		bit := (h1 + i*h2) % f.m
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+	f.count++
+}
+
+func (f *bloomFilter) contains(h1, h2 uint64) bool {
+	for i := range f.k {
+		bit := (h1 + i*h2) % f.m
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// bloomHash returns two independent hashes of key, combined to derive a
+// filter's k bit positions
+func bloomHash(key string) (uint64, uint64) {
+	h := fnv.New128a()
+	h.Write([]byte(key))
+	sum := h.Sum(nil)
+	// A zero step would put all k positions on the same bit
+	return binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:]) | 1
+}
@@ -0,0 +1,48 @@
+package analyzer
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBloomSet(t *testing.T) {
+	set := newBloomSet(0.01)
+	const n = 3 * bloomInitialCapacity
+	for i := range n {
+		set.add(fmt.Sprintf("https://example.com/page/%d", i))
+	}
+	if len(set.filters) < 2 {
+		t.Errorf("Expected the set to grow past its first filter, got %d filters", len(set.filters))
+	}
+	for i := range n {
+		if set.add(fmt.Sprintf("https://example.com/page/%d", i)) {
+			t.Fatalf("Expected page %d to be in the set", i)
+		}
+	}
+
+	falsePositives := 0
+	for i := range n {
+		if !set.add(fmt.Sprintf("https://example.com/other/%d", i)) {
+			falsePositives++
+		}
+	}
+	if rate := float64(falsePositives) / n; rate > 0.01 {
+		t.Errorf("Expected a false-positive rate under 1%%, got %.4f", rate)
+	}
+}
+
+func TestCrawlStateBloom(t *testing.T) {
+	state := newCrawlState(1, t.TempDir(), 0.001)
+	defer state.close()
+	for _, key := range []string{"https://example.com/a", "https://example.com/b"} {
+		if unseen, err := state.visit(key); err != nil || !unseen {
+			t.Errorf("Expected %s to be unseen, got %v, %v", key, unseen, err)
+		}
+		if unseen, _ := state.visit(key); unseen {
+			t.Errorf("Expected %s to be seen", key)
+		}
+	}
+	if state.db != nil {
+		t.Error("Expected seen pages tracked by the bloom set not to spill")
+	}
+}
@@ -0,0 +1,187 @@
+package analyzer
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+// memoryCacheSize bounds how many analyses the in-memory cache keeps
+const memoryCacheSize = 256
+
+// ResultCache stores encoded analysis results so repeated requests for a
+// page can reuse them. Implementations expire entries after their own TTL,
+// treat failures as misses and must be safe for concurrent use.
+type ResultCache interface {
+	Get(ctx context.Context, key string) ([]byte, bool)
+	Set(ctx context.Context, key string, value []byte)
+	// Delete drops the entries whose keys matches accepts
+	Delete(ctx context.Context, matches func(key string) bool)
+}
+
+// cachedResult is the encoded form of a cached analysis
+type cachedResult struct {
+	AnalyzedAt time.Time              `json:"analyzed_at"`
+	Result     *models.AnalysisResult `json:"result"`
+}
+
+// newResultCache returns config's cache, an in-memory one when only
+// CacheTTL is set, or nil to disable caching
+func newResultCache(config *Config) ResultCache {
+	if config.ResultCache != nil {
+		return config.ResultCache
+	}
+	if config.CacheTTL <= 0 {
+		return nil
+	}
+	return newMemoryCache(config.CacheTTL)
+}
+
+// resultCacheKey identifies an analysis by its normalized URL and the
+// options that change its result
+func resultCacheKey(targetURL string, profile Profile, opts AnalyzeOptions, flags Flags) string {
+	key := normalizeURL(targetURL) + "\x00" + profile.Name + "\x00" + opts.Keyword
+	if opts.IncludeLinks {
+		key += "\x00links"
+	}
+	if opts.Flags != "" {
+		key += "\x00flags:" + strings.Join(flags.names(), ",")
+	}
+	if opts.Fetcher != "" {
+		key += "\x00fetcher:" + opts.Fetcher
+	}
+	return key
+}
+
+// NormalizeURL is the form of a page's URL its cached results and pages
+// are keyed by
+func NormalizeURL(raw string) string {
+	return normalizeURL(raw)
+}
+
+// PurgeCache drops the cached results and pages of every page matches
+// accepts, given its normalized URL, so erased data isn't served again
+func (a *Analyzer) PurgeCache(ctx context.Context, matches func(pageURL string) bool) {
+	// Keys start with the page's normalized URL
+	keyMatches := func(key string) bool {
+		pageURL, _, _ := strings.Cut(key, "\x00")
+		return matches(pageURL)
+	}
+	if a.cache != nil {
+		a.cache.Delete(ctx, keyMatches)
+	}
+	for _, f := range a.fetchers {
+		if cached, ok := f.(*cachedFetcher); ok {
+			cached.cache.Delete(ctx, keyMatches)
+		}
+	}
+}
+
+// cachedResult returns a copy of the result cached for key and when it
+// was analyzed
+func (a *Analyzer) cachedResult(ctx context.Context, key string) (*models.AnalysisResult, time.Time, bool) {
+	if a.cache == nil {
+		return nil, time.Time{}, false
+	}
+	encoded, ok := a.cache.Get(ctx, key)
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	var cached cachedResult
+	if err := json.Unmarshal(encoded, &cached); err != nil || cached.Result == nil {
+		return nil, time.Time{}, false
+	}
+	return cached.Result, cached.AnalyzedAt, true
+}
+
+// cacheResult stores result under key
+func (a *Analyzer) cacheResult(ctx context.Context, key string, result *models.AnalysisResult) {
+	if a.cache == nil {
+		return
+	}
+	encoded, err := json.Marshal(cachedResult{AnalyzedAt: time.Now().UTC(), Result: result})
+	if err != nil {
+		return
+	}
+	a.cache.Set(ctx, key, encoded)
+}
+
+// memoryCache is the default ResultCache, an LRU cache local to the
+// process
+type memoryCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	order   *list.List // front is most recently used
+	entries map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	created time.Time
+}
+
+func newMemoryCache(ttl time.Duration) *memoryCache {
+	return &memoryCache{ttl: ttl, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (c *memoryCache) Get(_ context.Context, key string) ([]byte, bool) {
+	return c.get(key, time.Now())
+}
+
+func (c *memoryCache) Set(_ context.Context, key string, value []byte) {
+	c.set(key, value, time.Now())
+}
+
+func (c *memoryCache) Delete(_ context.Context, matches func(key string) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, elem := range c.entries {
+		if matches(key) {
+			c.order.Remove(elem)
+			delete(c.entries, key)
+		}
+	}
+}
+
+// get returns the value cached for key, dropping it once expired
+func (c *memoryCache) get(key string, now time.Time) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*memoryEntry)
+	if now.Sub(entry.created) >= c.ttl {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+// set caches value under key, evicting the least recently used entry when
+// the cache is full
+func (c *memoryCache) set(key string, value []byte, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = &memoryEntry{key: key, value: value, created: now}
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&memoryEntry{key: key, value: value, created: now})
+	if c.order.Len() > memoryCacheSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryEntry).key)
+	}
+}
@@ -0,0 +1,181 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryCache(t *testing.T) {
+	if newResultCache(&Config{}) != nil {
+		t.Error("Expected a zero TTL to disable the cache")
+	}
+
+	now := time.Now()
+	c := newMemoryCache(time.Minute)
+	c.set("a", []byte("A"), now)
+
+	if got, ok := c.get("a", now.Add(30*time.Second)); !ok || string(got) != "A" {
+		t.Fatalf("get = %q, %v", got, ok)
+	}
+	if _, ok := c.get("a", now.Add(time.Minute)); ok {
+		t.Error("Expected the entry to expire after the TTL")
+	}
+
+	// Filling the cache evicts the least recently used entry
+	for i := 0; i < memoryCacheSize; i++ {
+		c.set(fmt.Sprint(i), nil, now)
+	}
+	c.get("0", now)
+	c.set("new", nil, now)
+	if _, ok := c.get("1", now); ok {
+		t.Error("Expected the least recently used entry to be evicted")
+	}
+	if _, ok := c.get("0", now); !ok {
+		t.Error("Expected a recently used entry to be kept")
+	}
+
+	c.Delete(context.Background(), func(key string) bool { return key == "0" })
+	if _, ok := c.get("0", now); ok {
+		t.Error("Expected the matching entry to be deleted")
+	}
+	if _, ok := c.get("2", now); !ok {
+		t.Error("Expected other entries to be kept")
+	}
+}
+
+// mapCache is a ResultCache standing in for a shared backend
+type mapCache map[string][]byte
+
+func (m mapCache) Get(_ context.Context, key string) ([]byte, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+func (m mapCache) Set(_ context.Context, key string, value []byte) {
+	m[key] = value
+}
+
+func (m mapCache) Delete(_ context.Context, matches func(key string) bool) {
+	for key := range m {
+		if matches(key) {
+			delete(m, key)
+		}
+	}
+}
+
+func TestAnalyzer_ResultCache(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	var hits atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>Cached</title></head><body></body></html>`))
+	}))
+	defer ts.Close()
+
+	a := NewAnalyzer(&Config{
+		RequestTimeout:  2 * time.Second,
+		LinkTimeout:     time.Second,
+		MaxWorkers:      5,
+		MaxResponseSize: 1024 * 1024,
+		MaxURLLength:    2048,
+		MaxRedirects:    5,
+		CacheTTL:        time.Minute,
+	})
+	ctx := context.Background()
+
+	first, err := a.Analyze(ctx, ts.URL+"/")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if !first.CachedAt.IsZero() {
+		t.Error("Expected a fresh result not to be marked cached")
+	}
+	requests := hits.Load()
+
+	// The normalized URL matches without the trailing slash
+	cached, err := a.Analyze(ctx, ts.URL)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if hits.Load() != requests || cached.CachedAt.IsZero() || cached.Title != "Cached" {
+		t.Errorf("Expected a cached result without requests, got %d requests and %+v", hits.Load()-requests, cached)
+	}
+	if cached.Usage == nil || cached.Usage.Pages != 0 {
+		t.Errorf("Expected a cache hit to cost no pages, got %+v", cached.Usage)
+	}
+
+	forced, err := a.AnalyzeWithOptions(ctx, ts.URL+"/", AnalyzeOptions{Force: true})
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if hits.Load() == requests || !forced.CachedAt.IsZero() {
+		t.Error("Expected Force to analyze the page again")
+	}
+
+	if _, err := a.AnalyzeWithOptions(ctx, ts.URL+"/", AnalyzeOptions{Keyword: "cache"}); err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if result, err := a.AnalyzeWithOptions(ctx, ts.URL+"/", AnalyzeOptions{Keyword: "other"}); err != nil || !result.CachedAt.IsZero() {
+		t.Error("Expected a different keyword not to share the cached result")
+	}
+
+	// Hits are copies callers may modify
+	cached.Title = "changed"
+	if again, err := a.Analyze(ctx, ts.URL); err != nil || again.Title != "Cached" {
+		t.Errorf("Expected an unmodified cached result, got %+v, %v", again, err)
+	}
+}
+
+func TestAnalyzer_SharedResultCache(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	var hits atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>Shared</title></head><body></body></html>`))
+	}))
+	defer ts.Close()
+
+	// Two replicas sharing one cache backend
+	shared := mapCache{}
+	newReplica := func() *Analyzer {
+		return NewAnalyzer(&Config{
+			RequestTimeout:  2 * time.Second,
+			LinkTimeout:     time.Second,
+			MaxWorkers:      5,
+			MaxResponseSize: 1024 * 1024,
+			MaxURLLength:    2048,
+			MaxRedirects:    5,
+			ResultCache:     shared,
+		})
+	}
+
+	if _, err := newReplica().Analyze(context.Background(), ts.URL); err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	requests := hits.Load()
+	result, err := newReplica().Analyze(context.Background(), ts.URL)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if hits.Load() != requests || result.CachedAt.IsZero() || result.Title != "Shared" {
+		t.Errorf("Expected the second replica to reuse the cached result, got %+v", result)
+	}
+
+	// Purging a page on one replica drops it for all of them
+	newReplica().PurgeCache(context.Background(), func(pageURL string) bool { return pageURL == NormalizeURL(ts.URL) })
+	if len(shared) != 0 {
+		t.Errorf("Expected the purge to empty the shared cache, left %d entries", len(shared))
+	}
+}
@@ -0,0 +1,156 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"website-analyzer/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// longLivedCache is the shortest freshness lifetime that counts as a
+// long-lived cache for a static asset
+const longLivedCache = 30 * 24 * time.Hour
+
+// AuditCaching reports how the page is compressed and cached from the
+// headers it was served with and, with a client, probes whether it is
+// offered with brotli and checks the cache lifetime of the scripts,
+// stylesheets, images and fonts it references with HEAD requests
+func AuditCaching(ctx context.Context, doc *goquery.Document, baseURL string, header http.Header, client *http.Client, maxWorkers int) *models.CachingReport {
+	report := &models.CachingReport{
+		Compression:  contentEncoding(header),
+		CacheControl: header.Get("Cache-Control"),
+		ETag:         header.Get("ETag") != "",
+		LastModified: header.Get("Last-Modified") != "",
+	}
+
+	byKind := weightResources(doc, baseURL)
+	type asset struct{ url, kind string }
+	var assets []asset
+	for _, kind := range []string{"scripts", "stylesheets", "images", "fonts"} {
+		for _, assetURL := range byKind[kind] {
+			assets = append(assets, asset{assetURL, kind})
+		}
+	}
+	report.Assets = len(assets)
+
+	if client != nil {
+		if encoding, err := probeEncoding(ctx, client, baseURL); err == nil {
+			report.Compression = encoding
+		}
+
+		var mu sync.Mutex
+		runLimited(ctx, len(assets), maxWorkers, func(i int) {
+			resp, err := headResource(ctx, client, assets[i].url)
+			if err != nil {
+				mu.Lock()
+				report.Unchecked++
+				mu.Unlock()
+				return
+			}
+			resp.Body.Close()
+
+			mu.Lock()
+			defer mu.Unlock()
+			if resp.StatusCode >= 400 {
+				report.Unchecked++
+				return
+			}
+			lifetime, cached := freshness(resp.Header)
+			if cached && lifetime >= longLivedCache {
+				report.LongLived++
+				return
+			}
+			report.ShortLived = append(report.ShortLived, models.AssetCache{
+				URL:          assets[i].url,
+				Kind:         assets[i].kind,
+				CacheControl: resp.Header.Get("Cache-Control"),
+				MaxAge:       int64(lifetime.Seconds()),
+			})
+		})
+		slices.SortFunc(report.ShortLived, func(a, b models.AssetCache) int {
+			return strings.Compare(a.URL, b.URL)
+		})
+		report.AssetsChecked = true
+	}
+
+	if report.Compression == "" {
+		report.Issues = append(report.Issues, "The page is served uncompressed; enable gzip or brotli")
+	}
+	if report.CacheControl == "" {
+		report.Issues = append(report.Issues, "The page has no Cache-Control header")
+	}
+	if !report.ETag && !report.LastModified {
+		report.Issues = append(report.Issues, "The page has neither an ETag nor a Last-Modified header to revalidate against")
+	}
+	return report
+}
+
+// contentEncoding returns the compression named by a Content-Encoding
+// header, ignoring "identity"
+func contentEncoding(header http.Header) string {
+	encoding := strings.ToLower(strings.TrimSpace(header.Get("Content-Encoding")))
+	if encoding == "identity" {
+		return ""
+	}
+	return encoding
+}
+
+// probeEncoding asks for the page with brotli or gzip and returns the
+// encoding the server picks; the body is not read
+func probeEncoding(ctx context.Context, client *http.Client, pageURL string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, client.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return "", err
+	}
+	// Setting the header stops the transport from decompressing gzip
+	// itself, which would hide the Content-Encoding
+	req.Header.Set("Accept-Encoding", "br, gzip")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	return contentEncoding(resp.Header), nil
+}
+
+// freshness returns how long a response may be cached from its
+// Cache-Control max-age or, failing that, its Expires and Date headers.
+// cached is false when the response must not be stored or reused without
+// revalidation, or declares no lifetime.
+func freshness(header http.Header) (lifetime time.Duration, cached bool) {
+	maxAge := -1
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store", "no-cache":
+			return 0, false
+		case "max-age":
+			if n, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil {
+				maxAge = n
+			}
+		}
+	}
+	if maxAge >= 0 {
+		return time.Duration(maxAge) * time.Second, true
+	}
+
+	expires, err := http.ParseTime(header.Get("Expires"))
+	if err != nil {
+		return 0, false
+	}
+	date, err := http.ParseTime(header.Get("Date"))
+	if err != nil {
+		date = time.Now()
+	}
+	return max(expires.Sub(date), 0), true
+}
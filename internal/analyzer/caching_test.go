@@ -0,0 +1,92 @@
+package analyzer
+
+import (
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestAuditCaching(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			if strings.Contains(r.Header.Get("Accept-Encoding"), "br") {
+				w.Header().Set("Content-Encoding", "br")
+			}
+		case "/app.js":
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		case "/site.css":
+			w.Header().Set("Cache-Control", "max-age=3600")
+		case "/logo.png":
+			w.Header().Set("Date", "Mon, 02 Jan 2026 00:00:00 GMT")
+			w.Header().Set("Expires", "Mon, 02 Mar 2026 00:00:00 GMT")
+		case "/font.woff2":
+			w.Header().Set("Cache-Control", "no-cache, max-age=31536000")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<html><head>
+		<link rel="stylesheet" href="/site.css">
+		<link rel="preload" href="/font.woff2" as="font">
+		<script src="/app.js"></script>
+	</head><body><img src="/logo.png"><img src="/missing.png"></body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header := http.Header{"Cache-Control": {"no-cache"}, "Etag": {`"abc"`}}
+	report := AuditCaching(context.Background(), doc, server.URL+"/", header, nil, 2)
+	if report.Compression != "" || report.CacheControl != "no-cache" || !report.ETag || report.LastModified {
+		t.Errorf("Unexpected page headers %+v", report)
+	}
+	if report.Assets != 5 || report.AssetsChecked {
+		t.Errorf("Expected 5 unchecked assets without a client, got %+v", report)
+	}
+	if len(report.Issues) != 1 || !strings.Contains(report.Issues[0], "uncompressed") {
+		t.Errorf("Expected only the missing compression flagged, got %v", report.Issues)
+	}
+
+	report = AuditCaching(context.Background(), doc, server.URL+"/", http.Header{"Content-Encoding": {"gzip"}}, &http.Client{Timeout: 5 * time.Second}, 2)
+	if report.Compression != "br" {
+		t.Errorf("Expected the brotli probe to win, got %q", report.Compression)
+	}
+	if !report.AssetsChecked || report.LongLived != 2 || report.Unchecked != 1 || len(report.ShortLived) != 2 {
+		t.Fatalf("Expected 2 long-lived, 2 short-lived and 1 unchecked asset, got %+v", report)
+	}
+	if !strings.HasSuffix(report.ShortLived[0].URL, "/font.woff2") || report.ShortLived[0].MaxAge != 0 ||
+		!strings.HasSuffix(report.ShortLived[1].URL, "/site.css") || report.ShortLived[1].MaxAge != 3600 || report.ShortLived[1].Kind != "stylesheets" {
+		t.Errorf("Unexpected short-lived assets %+v", report.ShortLived)
+	}
+	if len(report.Issues) != 2 {
+		t.Errorf("Expected missing Cache-Control and validators flagged, got %v", report.Issues)
+	}
+}
+
+func TestHTTPFetcherKeepsContentEncoding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		zw.Write([]byte("<html><title>Zipped</title></html>"))
+		zw.Close()
+	}))
+	defer server.Close()
+
+	f := httpFetcher{client: &http.Client{Timeout: 5 * time.Second}, maxSize: 1024}
+	page, err := f.Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(page.Body) != "<html><title>Zipped</title></html>" || page.Header.Get("Content-Encoding") != "gzip" {
+		t.Errorf("Expected the decompressed page with its encoding, got %q %v", page.Body, page.Header)
+	}
+}
@@ -2,32 +2,114 @@ package analyzer
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
 	"net/http"
 	"net/url"
 	"sync"
+	"syscall"
 	"time"
 
 	"website-analyzer/internal/models"
+	"website-analyzer/internal/validator"
 )
 
+// errTooManyRedirects stops a link check after MaxRedirects hops
+var errTooManyRedirects = errors.New("Too many redirects")
+
+// defaultMaxRedirects limits page fetches when MaxRedirects is unset, like
+// net/http's default
+const defaultMaxRedirects = 10
+
+// checkRedirect stops after maxRedirects hops and validates every hop's
+// target like a submitted URL
+func checkRedirect(maxRedirects int) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return errTooManyRedirects
+		}
+		return validator.ValidateRedirect(req)
+	}
+}
+
 // CheckLinksConfig holds configuration for link checking
 type CheckLinksConfig struct {
 	Timeout      time.Duration
 	MaxWorkers   int
 	MaxRedirects int
 	Transport    http.RoundTripper // Optional custom transport for testing
+	// MaxAttempts bounds how often a link is tried; values below 2 disable
+	// retries. Only network errors, 5xx and 429 responses are retried.
+	MaxAttempts int
+	// RetryBackoff is the delay before the first retry, doubled after each
+	RetryBackoff time.Duration
+	// RetryJitter adds up to this fraction of the delay at random so
+	// retries against one host spread out
+	RetryJitter float64
+	// GetOnly checks links with ranged GET requests instead of HEAD
+	GetOnly bool
+	// HostRate limits requests per second to any one host and
+	// HostConcurrency the requests in flight to it; zero disables either.
+	// Retries count against both.
+	HostRate        float64
+	HostConcurrency int
+
+	// limiter shares the per-host limits across calls, e.g. the pages of a
+	// crawl; CheckAllLinks creates one from the limits when nil
+	limiter *hostLimiter
+	// breaker carries domain health across calls; CheckAllLinks uses a
+	// fresh breaker when nil
+	breaker *circuitBreaker
+	// userAgent identifies link checks; empty sends the bare product token
+	userAgent string
+	// optOut lists domains whose links fail without being requested
+	optOut domainList
 }
 
 // checkResult is used internally for worker communication
 type checkResult struct {
+	link       models.Link
 	url        string
 	statusCode int
-	err        error
+	// authenticate is the WWW-Authenticate challenge of a 401 response
+	authenticate string
+	latency      time.Duration
+	blocked      bool
+	attempts     int
+	err          error
 }
 
 // CheckLinks verifies accessibility of links concurrently
-func CheckLinks(links []models.Link, config CheckLinksConfig) []models.LinkError {
+func CheckLinks(ctx context.Context, links []models.Link, config CheckLinksConfig) []models.LinkError {
+	return InaccessibleLinks(CheckAllLinks(ctx, links, config))
+}
+
+// InaccessibleLinks picks the failed checks out of a full status list.
+// Links skipped by the circuit breaker are not reported as inaccessible.
+func InaccessibleLinks(statuses []models.LinkStatus) []models.LinkError {
+	var errors []models.LinkError
+	for _, status := range statuses {
+		if status.Error != "" && !status.Blocked {
+			errors = append(errors, models.LinkError{
+				URL:        status.URL,
+				StatusCode: status.StatusCode,
+				Error:      status.Error,
+				ErrorType:  status.ErrorType,
+				Attempts:   status.Attempts,
+			})
+		}
+	}
+	return errors
+}
+
+// CheckAllLinks checks links concurrently and returns the outcome of every
+// link, including latency and whether the circuit breaker skipped it
+func CheckAllLinks(ctx context.Context, links []models.Link, config CheckLinksConfig) []models.LinkStatus {
 	if len(links) == 0 {
 		return nil
 	}
@@ -41,10 +123,23 @@ func CheckLinks(links []models.Link, config CheckLinksConfig) []models.LinkError
 	wg.Add(config.MaxWorkers)
 
 	// Circuit breaker
-	cb := newCircuitBreaker(5)
+	cb := config.breaker
+	if cb == nil {
+		cb = newCircuitBreaker(5)
+	}
+
+	if config.limiter == nil {
+		config.limiter = newHostLimiter(config.HostRate, config.HostConcurrency)
+	}
+	if config.limiter != nil {
+		links = interleaveByHost(links)
+	}
+
+	progress := progressFrom(ctx)
+	progress.AddLinks(len(links))
 
 	for w := 0; w < config.MaxWorkers; w++ {
-		go worker(jobs, results, config, cb, &wg)
+		go worker(ctx, jobs, results, config, cb, &wg)
 	}
 
 	// Send jobs
@@ -59,49 +154,71 @@ func CheckLinks(links []models.Link, config CheckLinksConfig) []models.LinkError
 		close(results)
 	}()
 
-	// Collect errors
-	var errors []models.LinkError
+	// Collect results
+	var statuses []models.LinkStatus
 	for result := range results {
+		progress.LinkChecked(result.latency)
+		status := models.LinkStatus{
+			URL:          result.url,
+			Type:         result.link.Type,
+			StatusCode:   result.statusCode,
+			Authenticate: result.authenticate,
+			LatencyMs:    result.latency.Milliseconds(),
+			Blocked:      result.blocked,
+			Attempts:     result.attempts,
+		}
 		if result.err != nil {
-			errors = append(errors, models.LinkError{
-				URL:        result.url,
-				StatusCode: result.statusCode,
-				Error:      result.err.Error(),
-			})
+			status.Error = result.err.Error()
+			status.ErrorType = classifyError(result.statusCode, result.err)
 		}
+		statuses = append(statuses, status)
 	}
 
-	return errors
+	return statuses
 }
 
 // worker processes link checking jobs
-func worker(jobs <-chan models.Link, results chan<- checkResult, config CheckLinksConfig, cb *circuitBreaker, wg *sync.WaitGroup) {
+func worker(ctx context.Context, jobs <-chan models.Link, results chan<- checkResult, config CheckLinksConfig, cb *circuitBreaker, wg *sync.WaitGroup) {
 	defer wg.Done()
+	m := meterFrom(ctx)
+	m.enter()
+	defer m.leave()
 
 	client := &http.Client{
-		Timeout:   config.Timeout,
-		Transport: config.Transport,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			if len(via) >= config.MaxRedirects {
-				return fmt.Errorf("Too many redirects")
-			}
-			return nil
-		},
+		Timeout:       config.Timeout,
+		Transport:     meteredTransport{base: outboundTransport{base: config.Transport, userAgent: config.userAgent, optOut: config.optOut}},
+		CheckRedirect: checkRedirect(config.MaxRedirects),
 	}
 
 	for link := range jobs {
+		// Drain remaining jobs without touching the network once cancelled
+		if err := ctx.Err(); err != nil {
+			results <- checkResult{link: link, url: link.URL, err: err}
+			continue
+		}
+
 		domain := getDomain(link.URL)
 
 		// Check circuit breaker
 		if domain != "" && !cb.allow(domain) {
+			results <- checkResult{
+				link:    link,
+				url:     link.URL,
+				blocked: true,
+				err:     fmt.Errorf("skipped: circuit breaker open for %s", domain),
+			}
 			continue
 		}
 
-		result := checkLink(client, link.URL)
+		start := time.Now()
+		result := checkWithRetry(ctx, client, link.URL, config)
+		result.link = link
+		result.latency = time.Since(start)
 
-		// Update circuit breaker based on result
-		if domain != "" {
-			if result.err != nil {
+		// Update circuit breaker based on result. A domain that answers,
+		// even with 404, is healthy; only its broken links are reported.
+		if domain != "" && ctx.Err() == nil {
+			if domainFailed(result) {
 				cb.recordFailure(domain)
 			} else {
 				cb.recordSuccess(domain)
@@ -120,12 +237,150 @@ func getDomain(linkURL string) string {
 	return u.Host
 }
 
-// checkLink performs a single link check
-func checkLink(client *http.Client, url string) checkResult {
-	ctx, cancel := context.WithTimeout(context.Background(), client.Timeout)
+// checkWithRetry runs checkLink, retrying transient failures with
+// exponential backoff and jitter
+func checkWithRetry(ctx context.Context, client *http.Client, url string, config CheckLinksConfig) checkResult {
+	delay := config.RetryBackoff
+	host := getDomain(url)
+	for attempt := 1; ; attempt++ {
+		release, err := config.limiter.acquire(ctx, host)
+		if err != nil {
+			return checkResult{url: url, attempts: attempt - 1, err: err}
+		}
+		result := checkLink(ctx, client, url, config.GetOnly)
+		release()
+		result.attempts = attempt
+		if attempt >= config.MaxAttempts || !isTransient(ctx, result) {
+			return result
+		}
+
+		wait := delay
+		if config.RetryJitter > 0 {
+			wait += time.Duration(rand.Float64() * config.RetryJitter * float64(delay))
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// domainFailed reports whether a check failed because the domain itself
+// is unhealthy: no response, a server error or rate limiting
+func domainFailed(result checkResult) bool {
+	if result.err == nil || errors.Is(result.err, errTooManyRedirects) || errors.Is(result.err, ErrOptedOut) || validator.Refused(result.err) {
+		return false
+	}
+	return result.statusCode == 0 || result.statusCode == http.StatusTooManyRequests || result.statusCode >= 500
+}
+
+// isTransient reports whether a failed check may succeed when repeated
+func isTransient(ctx context.Context, result checkResult) bool {
+	if result.err == nil || ctx.Err() != nil || validator.Refused(result.err) {
+		return false
+	}
+	if result.statusCode == 0 {
+		return isNetworkError(result.err)
+	}
+	return result.statusCode == http.StatusTooManyRequests || result.statusCode >= 500
+}
+
+// classifyError tells apart failures such as a domain that no longer
+// resolves and a server that was merely slow
+func classifyError(statusCode int, err error) models.LinkErrorType {
+	switch {
+	case statusCode >= 500:
+		return models.LinkErrorHTTP5xx
+	case statusCode >= 400:
+		return models.LinkErrorHTTP4xx
+	case errors.Is(err, errTooManyRedirects):
+		return models.LinkErrorTooManyRedirects
+	}
+
+	var (
+		dnsErr       *net.DNSError
+		certErr      *tls.CertificateVerificationError
+		unknownAuth  x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidCert  x509.CertificateInvalidError
+		recordHeader tls.RecordHeaderError
+		alert        tls.AlertError
+		urlErr       *url.Error
+	)
+	switch {
+	case errors.As(err, &dnsErr):
+		if dnsErr.IsTimeout {
+			return models.LinkErrorTimeout
+		}
+		return models.LinkErrorDNS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return models.LinkErrorConnectionRefused
+	case errors.As(err, &certErr), errors.As(err, &unknownAuth), errors.As(err, &hostnameErr),
+		errors.As(err, &invalidCert), errors.As(err, &recordHeader), errors.As(err, &alert):
+		return models.LinkErrorTLS
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &urlErr) && urlErr.Timeout():
+		return models.LinkErrorTimeout
+	}
+	return models.LinkErrorOther
+}
+
+// isNetworkError reports whether a request failed on the network (timeout,
+// refused or reset connection, temporary DNS failure) rather than on an
+// invalid URL or a redirect loop
+func isNetworkError(err error) bool {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return false
+	}
+	if urlErr.Timeout() {
+		return true
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(urlErr.Err, &dnsErr) {
+		return dnsErr.IsTemporary || dnsErr.IsTimeout
+	}
+	var opErr *net.OpError
+	return errors.As(urlErr.Err, &opErr) || errors.Is(urlErr.Err, io.EOF) || errors.Is(urlErr.Err, io.ErrUnexpectedEOF)
+}
+
+// checkLink performs a single link check. It sends HEAD and falls back to a
+// ranged GET when the server rejects HEAD; getOnly skips the HEAD request.
+func checkLink(ctx context.Context, client *http.Client, url string, getOnly bool) checkResult {
+	if !getOnly {
+		result := requestLink(ctx, client, http.MethodHead, url)
+		if !rejectsHead(result.statusCode) {
+			return result
+		}
+	}
+	return requestLink(ctx, client, http.MethodGet, url)
+}
+
+// rejectsHead reports whether a HEAD status may only mean that the server
+// does not support HEAD
+func rejectsHead(statusCode int) bool {
+	switch statusCode {
+	case http.StatusForbidden, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+// maxDrainSize bounds how much of a GET body is read so the connection can
+// be reused; servers ignoring Range would otherwise send the whole resource
+const maxDrainSize = 4 * 1024
+
+// requestLink checks url with a single request. GET asks for the first byte
+// only and discards the body.
+func requestLink(ctx context.Context, client *http.Client, method, url string) checkResult {
+	ctx, cancel := context.WithTimeout(ctx, client.Timeout)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, "HEAD", url, nil)
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
 	if err != nil {
 		return checkResult{
 			url:        url,
@@ -134,7 +389,9 @@ func checkLink(client *http.Client, url string) checkResult {
 		}
 	}
 
-	req.Header.Set("User-Agent", "WebPageAnalyzer/1.0")
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -145,13 +402,22 @@ func checkLink(client *http.Client, url string) checkResult {
 		}
 	}
 	defer resp.Body.Close()
+	if method == http.MethodGet {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainSize))
+	}
+
+	// An unsatisfiable range still means the resource exists (e.g. empty)
+	if method == http.MethodGet && resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		return checkResult{url: url, statusCode: resp.StatusCode}
+	}
 
 	// Consider 2xx and 3xx as success
 	if resp.StatusCode >= 400 {
 		return checkResult{
-			url:        url,
-			statusCode: resp.StatusCode,
-			err:        fmt.Errorf("HTTP %d: %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
+			url:          url,
+			statusCode:   resp.StatusCode,
+			authenticate: resp.Header.Get("WWW-Authenticate"),
+			err:          fmt.Errorf("HTTP %d: %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
 		}
 	}
 
@@ -1,9 +1,17 @@
 package analyzer
 
 import (
+	"context"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
 	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -11,6 +19,9 @@ import (
 )
 
 func TestCheckLinks(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
 	// Create test servers
 	server200 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -32,7 +43,7 @@ func TestCheckLinks(t *testing.T) {
 		MaxWorkers: 2,
 	}
 
-	errors := CheckLinks(links, config)
+	errors := CheckLinks(context.Background(), links, config)
 
 	// Should have 1 error (404)
 	if len(errors) != 1 {
@@ -61,7 +72,7 @@ func TestCheckLinksTimeout(t *testing.T) {
 		MaxWorkers: 1,
 	}
 
-	errors := CheckLinks(links, config)
+	errors := CheckLinks(context.Background(), links, config)
 
 	// Should timeout
 	if len(errors) != 1 {
@@ -70,6 +81,9 @@ func TestCheckLinksTimeout(t *testing.T) {
 }
 
 func TestCheckLinksMultipleStatuses(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
 	// Create servers with different status codes
 	server200 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -97,7 +111,7 @@ func TestCheckLinksMultipleStatuses(t *testing.T) {
 		MaxWorkers: 3,
 	}
 
-	errors := CheckLinks(links, config)
+	errors := CheckLinks(context.Background(), links, config)
 
 	// Should have 1 error (500)
 	if len(errors) != 1 {
@@ -117,7 +131,7 @@ func TestCheckLinksEmpty(t *testing.T) {
 		MaxWorkers: 2,
 	}
 
-	errors := CheckLinks(links, config)
+	errors := CheckLinks(context.Background(), links, config)
 
 	if errors != nil {
 		t.Errorf("Expected nil for empty links, got %v", errors)
@@ -139,7 +153,7 @@ func TestCheckLinksGoroutineLeak(t *testing.T) {
 
 	// Run multiple times to see if leaks accumulate
 	for i := 0; i < 10; i++ {
-		_ = CheckLinks(links, config)
+		_ = CheckLinks(context.Background(), links, config)
 	}
 
 	// Small buffer for any runtime-background goroutines that might have started
@@ -168,9 +182,351 @@ func TestCheckLinksDefaultWorkers(t *testing.T) {
 		MaxWorkers: 0,
 	}
 
-	errors := CheckLinks(links, config)
+	errors := CheckLinks(context.Background(), links, config)
 
 	if len(errors) != 0 {
 		t.Errorf("Expected 0 errors, got %d", len(errors))
 	}
 }
+
+func TestCheckLinksRetry(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	var flakyHits, missingHits, busyHits atomic.Int32
+
+	// Fails twice with 503, then recovers
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if flakyHits.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer flaky.Close()
+
+	missing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		missingHits.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer missing.Close()
+
+	busy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		busyHits.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer busy.Close()
+
+	// Nothing listens here once closed
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	links := []models.Link{
+		{URL: flaky.URL, Type: models.LinkTypeExternal},
+		{URL: missing.URL, Type: models.LinkTypeExternal},
+		{URL: busy.URL, Type: models.LinkTypeExternal},
+		{URL: down.URL, Type: models.LinkTypeExternal},
+	}
+
+	config := CheckLinksConfig{
+		Timeout:      time.Second,
+		MaxWorkers:   4,
+		MaxAttempts:  3,
+		RetryBackoff: 10 * time.Millisecond,
+		RetryJitter:  0.5,
+	}
+
+	attempts := make(map[string]int)
+	for _, status := range CheckAllLinks(context.Background(), links, config) {
+		attempts[status.URL] = status.Attempts
+	}
+
+	if got := attempts[flaky.URL]; got != 3 {
+		t.Errorf("Expected flaky link to succeed on attempt 3, got %d", got)
+	}
+	if got := missingHits.Load(); got != 1 {
+		t.Errorf("Expected 404 not to be retried, got %d requests", got)
+	}
+	if got := busyHits.Load(); got != 3 {
+		t.Errorf("Expected 429 to be retried up to 3 attempts, got %d requests", got)
+	}
+	if got := attempts[down.URL]; got != 3 {
+		t.Errorf("Expected connection errors to be retried, got %d attempts", got)
+	}
+
+	errors := InaccessibleLinks(CheckAllLinks(context.Background(), links[3:], config))
+	if len(errors) != 1 || errors[0].Attempts != 3 {
+		t.Errorf("Expected attempt count in LinkError, got %+v", errors)
+	}
+}
+
+func TestCheckLinksNoRetryByDefault(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	config := CheckLinksConfig{Timeout: time.Second, MaxWorkers: 1}
+	errors := CheckLinks(context.Background(), []models.Link{{URL: server.URL}}, config)
+
+	if len(errors) != 1 || errors[0].Attempts != 1 || hits.Load() != 1 {
+		t.Errorf("Expected a single attempt, got %+v after %d requests", errors, hits.Load())
+	}
+}
+
+func TestCheckLinksHeadFallback(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	var mu sync.Mutex
+	methods := make(map[string][]string)
+	record := func(r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		methods[r.Host] = append(methods[r.Host], r.Method+" "+r.Header.Get("Range"))
+	}
+
+	// Rejects HEAD but serves GET
+	noHead := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		record(r)
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write([]byte("x"))
+	}))
+	defer noHead.Close()
+
+	// Forbidden regardless of method
+	forbidden := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		record(r)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer forbidden.Close()
+
+	// Empty resource: any range is unsatisfiable
+	empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		record(r)
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+		w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+	}))
+	defer empty.Close()
+
+	links := []models.Link{
+		{URL: noHead.URL, Type: models.LinkTypeExternal},
+		{URL: forbidden.URL, Type: models.LinkTypeExternal},
+		{URL: empty.URL, Type: models.LinkTypeExternal},
+	}
+
+	errors := CheckLinks(context.Background(), links, CheckLinksConfig{Timeout: time.Second, MaxWorkers: 3})
+
+	if len(errors) != 1 || errors[0].URL != forbidden.URL || errors[0].StatusCode != http.StatusForbidden {
+		t.Errorf("Expected only the forbidden link to fail, got %+v", errors)
+	}
+	host := strings.TrimPrefix(noHead.URL, "http://")
+	if got := methods[host]; len(got) != 2 || got[0] != "HEAD " || got[1] != "GET bytes=0-0" {
+		t.Errorf("Expected HEAD then ranged GET, got %v", got)
+	}
+}
+
+func TestCheckLinksGetOnly(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	var heads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			heads.Add(1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := CheckLinksConfig{Timeout: time.Second, MaxWorkers: 1, GetOnly: true}
+	errors := CheckLinks(context.Background(), []models.Link{{URL: server.URL}}, config)
+
+	if len(errors) != 0 {
+		t.Errorf("Expected no errors, got %+v", errors)
+	}
+	if heads.Load() != 0 {
+		t.Errorf("Expected no HEAD requests in GET-only mode, got %d", heads.Load())
+	}
+}
+
+func TestCheckLinksErrorTypes(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+
+	var loop *httptest.Server
+	loop = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, loop.URL+"/again", http.StatusFound)
+	}))
+	defer loop.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer slow.Close()
+
+	// Self-signed certificate not trusted by the checker
+	untrusted := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer untrusted.Close()
+
+	refused := httptest.NewServer(http.NotFoundHandler())
+	refused.Close()
+
+	want := map[string]models.LinkErrorType{
+		notFound.URL:  models.LinkErrorHTTP4xx,
+		failing.URL:   models.LinkErrorHTTP5xx,
+		loop.URL:      models.LinkErrorTooManyRedirects,
+		slow.URL:      models.LinkErrorTimeout,
+		untrusted.URL: models.LinkErrorTLS,
+		refused.URL:   models.LinkErrorConnectionRefused,
+	}
+
+	var links []models.Link
+	for u := range want {
+		links = append(links, models.Link{URL: u, Type: models.LinkTypeExternal})
+	}
+
+	config := CheckLinksConfig{Timeout: 200 * time.Millisecond, MaxWorkers: len(links), MaxRedirects: 3}
+	errors := CheckLinks(context.Background(), links, config)
+
+	if len(errors) != len(want) {
+		t.Fatalf("Expected %d errors, got %+v", len(want), errors)
+	}
+	for _, linkErr := range errors {
+		if linkErr.ErrorType != want[linkErr.URL] {
+			t.Errorf("%s: expected error type %v, got %v (%s)", linkErr.URL, want[linkErr.URL], linkErr.ErrorType, linkErr.Error)
+		}
+	}
+}
+
+func TestClassifyErrorDNS(t *testing.T) {
+	err := &url.Error{Op: "Head", URL: "https://gone.example", Err: &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "gone.example", IsNotFound: true}}}
+	if got := classifyError(0, err); got != models.LinkErrorDNS {
+		t.Errorf("Expected DNS failure, got %v", got)
+	}
+
+	err = &url.Error{Op: "Head", URL: "https://slow.example", Err: &net.DNSError{Err: "i/o timeout", Name: "slow.example", IsTimeout: true}}
+	if got := classifyError(0, err); got != models.LinkErrorTimeout {
+		t.Errorf("Expected DNS timeout to count as timeout, got %v", got)
+	}
+}
+
+func TestCheckLinksHostLimits(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	var inFlight, peak atomic.Int32
+	var mu sync.Mutex
+	var starts []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		mu.Lock()
+		starts = append(starts, time.Now())
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var links []models.Link
+	for i := 0; i < 6; i++ {
+		links = append(links, models.Link{URL: server.URL + "/page/" + strconv.Itoa(i)})
+	}
+
+	config := CheckLinksConfig{Timeout: time.Second, MaxWorkers: 6, HostConcurrency: 2, HostRate: 50}
+	errors := CheckLinks(context.Background(), links, config)
+
+	if len(errors) != 0 {
+		t.Errorf("Expected no errors, got %+v", errors)
+	}
+	if peak.Load() > 2 {
+		t.Errorf("Expected at most 2 requests in flight to the host, got %d", peak.Load())
+	}
+	// Six requests at 50/s need at least five 20ms gaps
+	if len(starts) == 6 {
+		if elapsed := starts[5].Sub(starts[0]); elapsed < 90*time.Millisecond {
+			t.Errorf("Expected requests to be spaced by the host rate, took %v", elapsed)
+		}
+	}
+}
+
+func TestCheckLinksBlocksPrivateAddresses(t *testing.T) {
+	var hits atomic.Int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer ts.Close()
+
+	// localhost passes no URL check here; the dial itself is refused
+	link := strings.Replace(ts.URL, "127.0.0.1", "localhost", 1)
+	cb := newCircuitBreaker(1)
+	statuses := CheckAllLinks(context.Background(), []models.Link{{URL: link}, {URL: ts.URL + "/other"}}, CheckLinksConfig{
+		Timeout:     time.Second,
+		MaxWorkers:  1,
+		MaxAttempts: 3,
+		breaker:     cb,
+	})
+
+	if hits.Load() != 0 {
+		t.Fatalf("Expected no request to reach the private server, got %d", hits.Load())
+	}
+	for _, status := range statuses {
+		if !strings.Contains(status.Error, "private IP") || status.Attempts != 1 || status.Blocked {
+			t.Errorf("Expected a single refused attempt, got %+v", status)
+		}
+	}
+	if !cb.allow(getDomain(ts.URL)) {
+		t.Error("Expected refused links not to trip the circuit breaker")
+	}
+}
+
+func TestCheckRedirect(t *testing.T) {
+	check := checkRedirect(2)
+	hop := func(rawURL string) *http.Request {
+		u, _ := url.Parse(rawURL)
+		return &http.Request{URL: u}
+	}
+
+	if err := check(hop("http://93.184.215.14/next"), nil); err != nil {
+		t.Errorf("Expected a public hop to be followed, got %v", err)
+	}
+	if err := check(hop("http://127.0.0.1/admin"), nil); err == nil || !strings.Contains(err.Error(), "redirect to 127.0.0.1 blocked") {
+		t.Errorf("Expected a private hop to be blocked, got %v", err)
+	}
+	if err := check(hop("http://[::1]/"), nil); err == nil {
+		t.Error("Expected an IPv6 loopback hop to be blocked")
+	}
+	if err := check(hop("file:///etc/passwd"), nil); err == nil {
+		t.Error("Expected a non-http hop to be blocked")
+	}
+	if err := check(hop("http://93.184.215.14/"), make([]*http.Request, 2)); err != errTooManyRedirects {
+		t.Errorf("Expected the hop limit to apply first, got %v", err)
+	}
+}
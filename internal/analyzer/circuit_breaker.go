@@ -1,8 +1,11 @@
 package analyzer
 
 import (
+	"sort"
 	"sync"
 	"time"
+
+	"website-analyzer/internal/models"
 )
 
 // circuitBreaker manages failure counts per domain with half-open state support
@@ -14,6 +17,10 @@ type circuitBreaker struct {
 	maxFailures      int
 	successThreshold int
 	retryDelay       time.Duration
+	// ttl forgets a domain's failures this long after the last one, so a
+	// breaker shared across analyses closes again; zero keeps them
+	ttl       time.Duration
+	lastPrune time.Time
 }
 
 func newCircuitBreaker(maxFailures int) *circuitBreaker {
@@ -34,7 +41,7 @@ func (cb *circuitBreaker) allow(domain string) bool {
 	failCount := cb.failures[domain]
 
 	// If not in open state, allow
-	if failCount < cb.maxFailures {
+	if failCount < cb.maxFailures || cb.expired(domain) {
 		return true
 	}
 
@@ -52,6 +59,10 @@ func (cb *circuitBreaker) allow(domain string) bool {
 func (cb *circuitBreaker) recordFailure(domain string) {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
+	cb.prune()
+	if cb.expired(domain) {
+		cb.failures[domain] = 0
+	}
 	cb.failures[domain]++
 	cb.successes[domain] = 0 // Reset success count
 	cb.lastAttempt[domain] = time.Now()
@@ -74,4 +85,60 @@ func (cb *circuitBreaker) recordSuccess(domain string) {
 			delete(cb.lastAttempt, domain)
 		}
 	}
-}
\ No newline at end of file
+}
+
+// expired reports whether the domain's last failure is older than the TTL.
+// Callers hold cb.mu.
+func (cb *circuitBreaker) expired(domain string) bool {
+	lastAttempt, exists := cb.lastAttempt[domain]
+	return cb.ttl > 0 && exists && time.Since(lastAttempt) >= cb.ttl
+}
+
+// prune drops expired domains at most once per TTL so a long-lived breaker
+// doesn't grow without bound. Callers hold cb.mu for writing.
+func (cb *circuitBreaker) prune() {
+	if cb.ttl <= 0 || time.Since(cb.lastPrune) < cb.ttl {
+		return
+	}
+	cb.lastPrune = time.Now()
+	for domain := range cb.lastAttempt {
+		if cb.expired(domain) {
+			delete(cb.failures, domain)
+			delete(cb.successes, domain)
+			delete(cb.lastAttempt, domain)
+		}
+	}
+}
+
+// snapshot lists the domains with recorded failures, sorted by domain
+func (cb *circuitBreaker) snapshot() []models.CircuitState {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	var states []models.CircuitState
+	for domain, failures := range cb.failures {
+		if failures == 0 || cb.expired(domain) {
+			continue
+		}
+		state := models.CircuitState{
+			Domain:      domain,
+			State:       models.CircuitClosed,
+			Failures:    failures,
+			Successes:   cb.successes[domain],
+			LastFailure: cb.lastAttempt[domain],
+		}
+		if failures >= cb.maxFailures {
+			state.State = models.CircuitOpen
+			if time.Since(state.LastFailure) >= cb.retryDelay {
+				state.State = models.CircuitHalfOpen
+			}
+		}
+		if cb.ttl > 0 {
+			state.ExpiresAt = state.LastFailure.Add(cb.ttl)
+		}
+		states = append(states, state)
+	}
+
+	sort.Slice(states, func(i, j int) bool { return states[i].Domain < states[j].Domain })
+	return states
+}
@@ -1,6 +1,7 @@
 package analyzer
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"sync"
@@ -58,7 +59,7 @@ func TestCheckLinks_CircuitBreaker_Recovery(t *testing.T) {
 	}
 
 	// First batch - should hit circuit breaker after 5 failures
-	errors := CheckLinks(links, config)
+	errors := CheckLinks(context.Background(), links, config)
 
 	mock.mu.Lock()
 	firstBatchCalls := mock.calls["recovering.com"]
@@ -80,7 +81,7 @@ func TestCheckLinks_CircuitBreaker_Recovery(t *testing.T) {
 	links2 := []models.Link{
 		{URL: "http://recovering.com/probe1"},
 	}
-	errors2 := CheckLinks(links2, config)
+	errors2 := CheckLinks(context.Background(), links2, config)
 
 	mock.mu.Lock()
 	secondBatchCalls := mock.calls["recovering.com"]
@@ -104,7 +105,7 @@ func TestCheckLinks_CircuitBreaker_Recovery(t *testing.T) {
 		{URL: "http://recovering.com/success2"},
 		{URL: "http://recovering.com/success3"},
 	}
-	errors3 := CheckLinks(links3, config)
+	errors3 := CheckLinks(context.Background(), links3, config)
 
 	mock.mu.Lock()
 	thirdBatchCalls := mock.calls["recovering.com"]
@@ -126,7 +127,7 @@ func TestCheckLinks_CircuitBreaker_Recovery(t *testing.T) {
 		{URL: "http://recovering.com/after-recovery1"},
 		{URL: "http://recovering.com/after-recovery2"},
 	}
-	errors4 := CheckLinks(links4, config)
+	errors4 := CheckLinks(context.Background(), links4, config)
 
 	mock.mu.Lock()
 	finalCalls := mock.calls["recovering.com"]
@@ -163,7 +164,7 @@ func TestCheckLinks_CircuitBreaker_HalfOpenFailure(t *testing.T) {
 	}
 
 	// First batch - trip the circuit breaker
-	_ = CheckLinks(links, config)
+	_ = CheckLinks(context.Background(), links, config)
 
 	mock.mu.Lock()
 	firstCalls := mock.calls["always-failing.com"]
@@ -180,7 +181,7 @@ func TestCheckLinks_CircuitBreaker_HalfOpenFailure(t *testing.T) {
 	links2 := []models.Link{
 		{URL: "http://always-failing.com/probe"},
 	}
-	_ = CheckLinks(links2, config)
+	_ = CheckLinks(context.Background(), links2, config)
 
 	mock.mu.Lock()
 	secondCalls := mock.calls["always-failing.com"]
@@ -200,7 +201,7 @@ func TestCheckLinks_CircuitBreaker_HalfOpenFailure(t *testing.T) {
 		{URL: "http://always-failing.com/blocked1"},
 		{URL: "http://always-failing.com/blocked2"},
 	}
-	_ = CheckLinks(links3, config)
+	_ = CheckLinks(context.Background(), links3, config)
 
 	mock.mu.Lock()
 	thirdCalls := mock.calls["always-failing.com"]
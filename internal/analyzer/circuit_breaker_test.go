@@ -1,9 +1,11 @@
 package analyzer
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"sync"
 	"testing"
 	"time"
@@ -53,7 +55,7 @@ func TestCheckLinks_CircuitBreaker_Parallel(t *testing.T) {
 		Transport:    mock,
 	}
 
-	_ = CheckLinks(links, config)
+	_ = CheckLinks(context.Background(), links, config)
 
 	mock.mu.Lock()
 	badCalls := mock.calls["bad.com"]
@@ -73,3 +75,108 @@ func TestCheckLinks_CircuitBreaker_Parallel(t *testing.T) {
 		t.Errorf("Expected 10 calls to good.com, got %d", goodCalls)
 	}
 }
+
+func TestCheckLinks_CircuitBreaker_SharedAcrossChecks(t *testing.T) {
+	mock := &mockTransport{
+		calls: make(map[string]int),
+	}
+
+	var links []models.Link
+	for i := 0; i < 10; i++ {
+		links = append(links, models.Link{URL: "http://bad.com/" + fmt.Sprintf("%d", i)})
+	}
+
+	config := CheckLinksConfig{
+		Timeout:    100 * time.Millisecond,
+		MaxWorkers: 1,
+		Transport:  mock,
+		breaker:    newSharedCircuitBreaker(time.Minute),
+	}
+
+	_ = CheckLinks(context.Background(), links, config)
+
+	mock.mu.Lock()
+	firstCalls := mock.calls["bad.com"]
+	mock.mu.Unlock()
+
+	results := CheckLinks(context.Background(), links, config)
+
+	mock.mu.Lock()
+	secondCalls := mock.calls["bad.com"] - firstCalls
+	mock.mu.Unlock()
+
+	if firstCalls != 5 {
+		t.Errorf("Expected 5 calls before the breaker opened, got %d", firstCalls)
+	}
+	if secondCalls != 0 {
+		t.Errorf("Expected the open breaker to carry over to the next check, got %d calls", secondCalls)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected blocked links not to be reported as inaccessible, got %d", len(results))
+	}
+
+	states := config.breaker.snapshot()
+	if len(states) != 1 || states[0].Domain != "bad.com" || states[0].State != models.CircuitOpen {
+		t.Errorf("Expected bad.com to be open, got %+v", states)
+	}
+}
+
+func TestCircuitBreaker_TTL(t *testing.T) {
+	cb := newCircuitBreaker(2)
+	cb.ttl = 20 * time.Millisecond
+	cb.retryDelay = time.Hour
+
+	cb.recordFailure("bad.com")
+	cb.recordFailure("bad.com")
+	if cb.allow("bad.com") {
+		t.Fatal("Expected the breaker to be open")
+	}
+	if states := cb.snapshot(); len(states) != 1 || !states[0].ExpiresAt.After(states[0].LastFailure) {
+		t.Errorf("Expected one open domain with an expiry, got %+v", states)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	if !cb.allow("bad.com") {
+		t.Error("Expected the breaker to close once the failures expired")
+	}
+	if states := cb.snapshot(); len(states) != 0 {
+		t.Errorf("Expected expired domains to be hidden, got %+v", states)
+	}
+
+	// A failure after expiry starts counting again and prunes stale entries
+	cb.recordFailure("bad.com")
+	if !cb.allow("bad.com") {
+		t.Error("Expected one fresh failure to leave the breaker closed")
+	}
+	if failures := cb.failures["bad.com"]; failures != 1 {
+		t.Errorf("Expected the failure count to restart, got %d", failures)
+	}
+}
+
+func TestCheckLinks_CircuitBreaker_IgnoresClientErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	var links []models.Link
+	for i := 0; i < 8; i++ {
+		links = append(links, models.Link{URL: server.URL + "/missing/" + fmt.Sprintf("%d", i)})
+	}
+
+	config := CheckLinksConfig{
+		Timeout:    time.Second,
+		MaxWorkers: 2,
+		breaker:    newSharedCircuitBreaker(time.Minute),
+	}
+
+	results := CheckLinks(context.Background(), links, config)
+
+	if len(results) != len(links) {
+		t.Errorf("Expected every 404 to be reported, got %d of %d", len(results), len(links))
+	}
+	if states := config.breaker.snapshot(); len(states) != 0 {
+		t.Errorf("Expected 404s not to trip the breaker, got %+v", states)
+	}
+}
@@ -0,0 +1,118 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"website-analyzer/internal/models"
+)
+
+// MaxCompetitors bounds how many competitor pages a comparison accepts
+const MaxCompetitors = 3
+
+// comparisonMetric extracts one comparable number from a page; lower
+// marks metrics where smaller is better (page weight)
+type comparisonMetric struct {
+	name  string
+	value func(models.ComparisonPage) int64
+	lower bool
+}
+
+var comparisonMetrics = []comparisonMetric{
+	{name: "word_count", value: func(p models.ComparisonPage) int64 { return int64(p.WordCount) }},
+	{name: "headings", value: func(p models.ComparisonPage) int64 { return int64(p.Headings) }},
+	{name: "page_weight", value: func(p models.ComparisonPage) int64 { return p.PageWeight }, lower: true},
+	{name: "overall_score", value: func(p models.ComparisonPage) int64 { return int64(p.Scores.Overall) }},
+	{name: "seo_score", value: func(p models.ComparisonPage) int64 { return int64(p.Scores.SEO) }},
+	{name: "accessibility_score", value: func(p models.ComparisonPage) int64 { return int64(p.Scores.Accessibility) }},
+	{name: "links_score", value: func(p models.ComparisonPage) int64 { return int64(p.Scores.Links) }},
+	{name: "structured_types", value: func(p models.ComparisonPage) int64 { return int64(len(p.StructuredTypes)) }},
+}
+
+// Compare analyzes the primary page and up to MaxCompetitors competitor
+// pages concurrently and reports where the primary page lags behind.
+// Competitor failures are recorded per page; a primary failure is an error.
+func (a *Analyzer) Compare(ctx context.Context, primaryURL string, competitorURLs []string) (*models.ComparisonReport, error) {
+	if len(competitorURLs) == 0 {
+		return nil, fmt.Errorf("at least one competitor URL is required")
+	}
+	if len(competitorURLs) > MaxCompetitors {
+		return nil, fmt.Errorf("at most %d competitor URLs are allowed", MaxCompetitors)
+	}
+
+	urls := append([]string{primaryURL}, competitorURLs...)
+	pages := make([]models.ComparisonPage, len(urls))
+	errs := make([]error, len(urls))
+
+	runLimited(ctx, len(urls), len(urls), func(i int) {
+		result, err := a.Analyze(ctx, urls[i])
+		errs[i] = err
+		pages[i] = comparisonPage(urls[i], result, err)
+	})
+	pages[0].Primary = true
+
+	if errs[0] != nil {
+		return nil, errs[0]
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	report := &models.ComparisonReport{Pages: pages, Lagging: make(map[string]bool)}
+	for _, metric := range comparisonMetrics {
+		primary := metric.value(pages[0])
+		best, bestURL := primary, ""
+		for _, page := range pages[1:] {
+			if page.Error != "" {
+				continue
+			}
+			v := metric.value(page)
+			if (metric.lower && v < best) || (!metric.lower && v > best) {
+				best, bestURL = v, page.URL
+			}
+		}
+		if bestURL != "" {
+			report.Lags = append(report.Lags, models.ComparisonLag{
+				Metric:  metric.name,
+				Primary: primary,
+				Best:    best,
+				BestURL: bestURL,
+			})
+			report.Lagging[metric.name] = true
+		}
+	}
+
+	a.redactor.Walk(report)
+	return report, nil
+}
+
+func comparisonPage(pageURL string, result *models.AnalysisResult, err error) models.ComparisonPage {
+	page := models.ComparisonPage{URL: pageURL}
+	if err != nil {
+		page.Error = err.Error()
+		return page
+	}
+
+	page.Title = result.Title
+	page.WordCount = result.WordCount
+	// The page's weight includes its resources when they were measured
+	page.PageWeight = result.HTMLSize
+	if result.Weight != nil {
+		page.PageWeight = result.Weight.TotalSize
+	}
+	page.Scores = result.Scores
+	page.H1 = result.Headings["h1"]
+	for _, count := range result.Headings {
+		page.Headings += count
+	}
+	if result.StructuredData != nil {
+		for _, entity := range result.StructuredData.Entities {
+			if !slices.Contains(page.StructuredTypes, entity.Type) {
+				page.StructuredTypes = append(page.StructuredTypes, entity.Type)
+			}
+		}
+	}
+
+	return page
+}
@@ -0,0 +1,119 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompare(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/primary":
+			_, _ = w.Write([]byte(`<html><head><title>Primary page title</title></head><body><h1>Short</h1><p>Few words here.</p></body></html>`))
+		case "/rival":
+			_, _ = w.Write([]byte(`<html><head><title>Rival page title</title>
+				<meta name="description" content="A description">
+				<link rel="canonical" href="/rival">
+				<script type="application/ld+json">{"@context":"https://schema.org","@type":"Organization","name":"Rival","url":"https://rival.example"}</script>
+				</head><body><h1>Rival</h1><h2>More</h2><p>` + strings.Repeat("word ", 200) + `</p></body></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	a := NewAnalyzer(&Config{
+		RequestTimeout:  2 * time.Second,
+		LinkTimeout:     time.Second,
+		MaxWorkers:      2,
+		MaxResponseSize: 1024 * 1024,
+		MaxURLLength:    2048,
+		MaxRedirects:    5,
+	})
+
+	report, err := a.Compare(context.Background(), ts.URL+"/primary", []string{ts.URL + "/rival", ts.URL + "/missing"})
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+
+	if len(report.Pages) != 3 || !report.Pages[0].Primary {
+		t.Fatalf("Expected primary first plus two competitors, got %+v", report.Pages)
+	}
+	if report.Pages[2].Error == "" {
+		t.Error("Expected failing competitor to record its error")
+	}
+
+	for _, metric := range []string{"word_count", "headings", "seo_score", "structured_types"} {
+		if !report.Lagging[metric] {
+			t.Errorf("Expected primary to lag on %s, lags: %+v", metric, report.Lags)
+		}
+	}
+	if report.Lagging["page_weight"] {
+		t.Error("Primary page is smaller and should not lag on page weight")
+	}
+
+	if _, err := a.Compare(context.Background(), ts.URL+"/primary", []string{"a", "b", "c", "d"}); err == nil {
+		t.Error("Expected an error for too many competitors")
+	}
+	if _, err := a.Compare(context.Background(), ts.URL+"/missing", []string{ts.URL + "/rival"}); err == nil {
+		t.Error("Expected an error when the primary page fails")
+	}
+}
+
+// TestCompareMeasuredPageWeight compares a page with little HTML but a
+// heavy image against one with more HTML and no resources
+func TestCompareMeasuredPageWeight(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/primary":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<html><head><title>Primary</title></head><body><img src="/hero.png" alt="Hero"></body></html>`))
+		case "/rival":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<html><head><title>Rival</title></head><body><p>` + strings.Repeat("word ", 200) + `</p></body></html>`))
+		case "/hero.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Header().Set("Content-Length", "50000")
+			if r.Method != http.MethodHead {
+				_, _ = w.Write(make([]byte, 50000))
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	a := NewAnalyzer(&Config{
+		RequestTimeout:  2 * time.Second,
+		LinkTimeout:     time.Second,
+		MaxWorkers:      2,
+		MaxResponseSize: 1024 * 1024,
+		MaxURLLength:    2048,
+		MaxRedirects:    5,
+		DeepAnalysis:    true,
+	})
+
+	report, err := a.Compare(context.Background(), ts.URL+"/primary", []string{ts.URL + "/rival"})
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+	primary, rival := report.Pages[0], report.Pages[1]
+	if primary.PageWeight <= rival.PageWeight {
+		t.Fatalf("Expected the image to make the primary page heavier, got %d and %d bytes", primary.PageWeight, rival.PageWeight)
+	}
+	if !report.Lagging["page_weight"] {
+		t.Errorf("Expected the primary page to lag on page weight despite its smaller HTML, lags: %+v", report.Lags)
+	}
+}
@@ -0,0 +1,408 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"website-analyzer/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+)
+
+const (
+	// WCAG 2.x AA minimum contrast ratios
+	contrastAANormal = 4.5
+	contrastAALarge  = 3.0
+	// maxContrastSamples caps the failing elements included in the report
+	maxContrastSamples = 10
+)
+
+// rgba is a color with 0-255 channels and 0-1 alpha
+type rgba struct {
+	r, g, b float64
+	a       float64
+}
+
+func (c rgba) hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", int(c.r), int(c.g), int(c.b))
+}
+
+var namedColors = map[string]rgba{
+	"black":   {0, 0, 0, 1},
+	"white":   {255, 255, 255, 1},
+	"red":     {255, 0, 0, 1},
+	"green":   {0, 128, 0, 1},
+	"blue":    {0, 0, 255, 1},
+	"yellow":  {255, 255, 0, 1},
+	"orange":  {255, 165, 0, 1},
+	"purple":  {128, 0, 128, 1},
+	"gray":    {128, 128, 128, 1},
+	"grey":    {128, 128, 128, 1},
+	"silver":  {192, 192, 192, 1},
+	"maroon":  {128, 0, 0, 1},
+	"navy":    {0, 0, 128, 1},
+	"teal":    {0, 128, 128, 1},
+	"olive":   {128, 128, 0, 1},
+	"lime":    {0, 255, 0, 1},
+	"aqua":    {0, 255, 255, 1},
+	"cyan":    {0, 255, 255, 1},
+	"fuchsia": {255, 0, 255, 1},
+	"magenta": {255, 0, 255, 1},
+	"pink":    {255, 192, 203, 1},
+	"brown":   {165, 42, 42, 1},
+}
+
+// elementStyle holds the declared properties relevant to contrast
+type elementStyle struct {
+	props       map[string]string
+	specificity map[string]cascadia.Specificity
+}
+
+// CheckContrast estimates text contrast from inline styles and <style>
+// blocks. Only elements whose colors can be determined from the markup are
+// evaluated; background images and translucent backgrounds are skipped.
+func CheckContrast(doc *goquery.Document) *models.ContrastReport {
+	styles := computeStyles(doc)
+	report := &models.ContrastReport{}
+
+	doc.Find("body *").Each(func(i int, s *goquery.Selection) {
+		node := s.Get(0)
+		text := directText(node)
+		if text == "" {
+			return
+		}
+
+		fg, bg, declared, ok := resolveColors(node, styles)
+		if !ok || !declared {
+			return
+		}
+
+		report.Checked++
+		ratio := contrastRatio(fg, bg)
+		required := contrastAANormal
+		if isLargeText(node, styles) {
+			required = contrastAALarge
+		}
+
+		if ratio >= required {
+			return
+		}
+
+		report.Failures++
+		if len(report.Samples) < maxContrastSamples {
+			report.Samples = append(report.Samples, models.ContrastSample{
+				Element:    describeElement(s),
+				Text:       truncate(text, 60),
+				Foreground: fg.hex(),
+				Background: bg.hex(),
+				Ratio:      math.Round(ratio*100) / 100,
+				Required:   required,
+			})
+		}
+	})
+
+	return report
+}
+
+// computeStyles applies stylesheet rules by specificity and then inline
+// styles, which always win
+func computeStyles(doc *goquery.Document) map[*html.Node]*elementStyle {
+	styles := make(map[*html.Node]*elementStyle)
+	get := func(n *html.Node) *elementStyle {
+		if styles[n] == nil {
+			styles[n] = &elementStyle{
+				props:       make(map[string]string),
+				specificity: make(map[string]cascadia.Specificity),
+			}
+		}
+		return styles[n]
+	}
+
+	apply := func(n *html.Node, decls []cssDeclaration, spec cascadia.Specificity) {
+		style := get(n)
+		for _, d := range decls {
+			prop, value := normalizeColorProperty(d.property, d.value)
+			if prop == "" {
+				continue
+			}
+			if current, ok := style.specificity[prop]; ok && spec.Less(current) {
+				continue
+			}
+			style.props[prop] = value
+			style.specificity[prop] = spec
+		}
+	}
+
+	doc.Find("style").Each(func(i int, s *goquery.Selection) {
+		for _, rule := range parseCSS(s.Text()) {
+			group, err := cascadia.ParseGroup(rule.selector)
+			if err != nil {
+				continue
+			}
+			for _, sel := range group {
+				spec := sel.Specificity()
+				for _, n := range cascadia.QueryAll(doc.Get(0), sel) {
+					apply(n, rule.declarations, spec)
+				}
+			}
+		}
+	})
+
+	inline := cascadia.Specificity{1 << 20, 0, 0}
+	doc.Find("[style]").Each(func(i int, s *goquery.Selection) {
+		style, _ := s.Attr("style")
+		apply(s.Get(0), parseDeclarations(style), inline)
+	})
+
+	return styles
+}
+
+// normalizeColorProperty keeps only the properties used by the contrast
+// check, mapping the background shorthand to background-color
+func normalizeColorProperty(property, value string) (string, string) {
+	switch property {
+	case "color", "background-color", "font-size", "font-weight":
+		return property, value
+	case "background":
+		if strings.Contains(value, "url(") || strings.Contains(value, "gradient(") {
+			return "background-image", value
+		}
+		return "background-color", firstField(value)
+	case "background-image":
+		if value != "none" {
+			return property, value
+		}
+	}
+	return "", ""
+}
+
+// resolveColors walks up the tree to find the inherited text color and the
+// nearest opaque background. declared reports whether any color in the
+// chain came from the page rather than browser defaults.
+func resolveColors(node *html.Node, styles map[*html.Node]*elementStyle) (fg, bg rgba, declared, ok bool) {
+	fg = rgba{0, 0, 0, 1}
+	bg = rgba{255, 255, 255, 1}
+	fgFound, bgFound := false, false
+
+	for n := node; n != nil && n.Type == html.ElementNode; n = n.Parent {
+		style := styles[n]
+		if style == nil {
+			continue
+		}
+
+		if !bgFound {
+			if _, hasImage := style.props["background-image"]; hasImage {
+				return fg, bg, false, false
+			}
+		}
+
+		if value, exists := style.props["color"]; exists && !fgFound {
+			c, parsed := parseColor(value)
+			if !parsed {
+				return fg, bg, false, false
+			}
+			fg, fgFound = c, true
+		}
+
+		if value, exists := style.props["background-color"]; exists && !bgFound {
+			c, parsed := parseColor(value)
+			if !parsed {
+				return fg, bg, false, false
+			}
+			if c.a == 0 {
+				continue
+			}
+			if c.a < 1 {
+				return fg, bg, false, false
+			}
+			bg, bgFound = c, true
+		}
+	}
+
+	if fg.a < 1 {
+		fg = blend(fg, bg)
+	}
+
+	return fg, bg, fgFound || bgFound, true
+}
+
+// isLargeText applies the WCAG definition: 18pt (24px) or 14pt (~18.66px) bold
+func isLargeText(node *html.Node, styles map[*html.Node]*elementStyle) bool {
+	size := 0.0
+	bold := false
+
+	switch node.Data {
+	case "h1", "h2":
+		size = 24
+		bold = true
+	case "h3", "b", "strong", "th":
+		bold = true
+	}
+
+	for n := node; n != nil && n.Type == html.ElementNode; n = n.Parent {
+		style := styles[n]
+		if style == nil {
+			continue
+		}
+		if value, ok := style.props["font-size"]; ok && size == 0 {
+			size = parsePixels(value)
+		}
+		if value, ok := style.props["font-weight"]; ok {
+			weight, err := strconv.Atoi(value)
+			bold = value == "bold" || value == "bolder" || err == nil && weight >= 700
+			break
+		}
+	}
+
+	return size >= 24 || bold && size >= 18.66
+}
+
+func parsePixels(value string) float64 {
+	value = strings.TrimSpace(strings.ToLower(value))
+	var factor float64
+	switch {
+	case strings.HasSuffix(value, "px"):
+		factor, value = 1, strings.TrimSuffix(value, "px")
+	case strings.HasSuffix(value, "pt"):
+		factor, value = 4.0/3.0, strings.TrimSuffix(value, "pt")
+	case strings.HasSuffix(value, "rem"):
+		factor, value = 16, strings.TrimSuffix(value, "rem")
+	case strings.HasSuffix(value, "em"):
+		factor, value = 16, strings.TrimSuffix(value, "em")
+	default:
+		return 0
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	return n * factor
+}
+
+// parseColor understands hex, rgb()/rgba() and basic named colors
+func parseColor(value string) (rgba, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	if value == "transparent" {
+		return rgba{0, 0, 0, 0}, true
+	}
+	if c, ok := namedColors[value]; ok {
+		return c, true
+	}
+
+	if strings.HasPrefix(value, "#") {
+		hex := value[1:]
+		if len(hex) == 3 || len(hex) == 4 {
+			var expanded strings.Builder
+			for _, r := range hex {
+				expanded.WriteRune(r)
+				expanded.WriteRune(r)
+			}
+			hex = expanded.String()
+		}
+		if len(hex) != 6 && len(hex) != 8 {
+			return rgba{}, false
+		}
+		n, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return rgba{}, false
+		}
+		if len(hex) == 6 {
+			return rgba{float64(n >> 16 & 0xff), float64(n >> 8 & 0xff), float64(n & 0xff), 1}, true
+		}
+		return rgba{float64(n >> 24 & 0xff), float64(n >> 16 & 0xff), float64(n >> 8 & 0xff), float64(n&0xff) / 255}, true
+	}
+
+	if strings.HasPrefix(value, "rgb") {
+		open := strings.Index(value, "(")
+		close := strings.LastIndex(value, ")")
+		if open < 0 || close < open {
+			return rgba{}, false
+		}
+		parts := strings.FieldsFunc(value[open+1:close], func(r rune) bool {
+			return r == ',' || r == ' ' || r == '/'
+		})
+		if len(parts) < 3 {
+			return rgba{}, false
+		}
+
+		var channels [4]float64
+		channels[3] = 1
+		for i := 0; i < len(parts) && i < 4; i++ {
+			part := parts[i]
+			percent := strings.HasSuffix(part, "%")
+			n, err := strconv.ParseFloat(strings.TrimSuffix(part, "%"), 64)
+			if err != nil {
+				return rgba{}, false
+			}
+			switch {
+			case percent && i < 3:
+				n = n * 255 / 100
+			case percent:
+				n = n / 100
+			}
+			channels[i] = n
+		}
+		return rgba{channels[0], channels[1], channels[2], channels[3]}, true
+	}
+
+	return rgba{}, false
+}
+
+// blend composites a translucent color over an opaque background
+func blend(c, bg rgba) rgba {
+	return rgba{
+		r: c.r*c.a + bg.r*(1-c.a),
+		g: c.g*c.a + bg.g*(1-c.a),
+		b: c.b*c.a + bg.b*(1-c.a),
+		a: 1,
+	}
+}
+
+// contrastRatio computes the WCAG contrast ratio between two colors
+func contrastRatio(a, b rgba) float64 {
+	la, lb := relativeLuminance(a), relativeLuminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+func relativeLuminance(c rgba) float64 {
+	channel := func(v float64) float64 {
+		v /= 255
+		if v <= 0.03928 {
+			return v / 12.92
+		}
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(c.r) + 0.7152*channel(c.g) + 0.0722*channel(c.b)
+}
+
+// directText returns the element's own text, excluding child elements
+func directText(node *html.Node) string {
+	if node.Data == "script" || node.Data == "style" {
+		return ""
+	}
+
+	var b strings.Builder
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
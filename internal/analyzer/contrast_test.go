@@ -0,0 +1,66 @@
+package analyzer
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestContrastRatio(t *testing.T) {
+	black, _ := parseColor("#000")
+	white, _ := parseColor("white")
+	gray, _ := parseColor("rgb(119, 119, 119)")
+
+	if ratio := contrastRatio(black, white); math.Abs(ratio-21) > 0.01 {
+		t.Errorf("Expected 21:1 for black on white, got %.2f", ratio)
+	}
+
+	if ratio := contrastRatio(gray, white); ratio < 4.4 || ratio > 4.5 {
+		t.Errorf("Expected ~4.48:1 for #777 on white, got %.2f", ratio)
+	}
+}
+
+func TestCheckContrast(t *testing.T) {
+	html := `
+		<html><head>
+			<style>
+				.muted { color: #aaaaaa; }
+				.dark { background: #222; }
+				.dark p { color: #333333; }
+				@media (min-width: 600px) { .big { font-size: 32px; color: #949494; } }
+			</style>
+		</head><body>
+			<p>Default colors are not evaluated</p>
+			<p class="muted">Low contrast text</p>
+			<p style="color: #111">Good contrast</p>
+			<div class="dark"><p>Dark on dark</p></div>
+			<p class="big">Large text passes at 3:1</p>
+			<div style="background: url(/bg.png)"><p style="color: #eee">Unknown background</p></div>
+		</body></html>
+	`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	report := CheckContrast(doc)
+
+	if report.Checked != 4 {
+		t.Errorf("Expected 4 checked elements, got %d", report.Checked)
+	}
+
+	if report.Failures != 2 {
+		t.Fatalf("Expected 2 failures, got %d: %+v", report.Failures, report.Samples)
+	}
+
+	if report.Samples[0].Foreground != "#aaaaaa" || report.Samples[0].Background != "#ffffff" {
+		t.Errorf("Unexpected first sample: %+v", report.Samples[0])
+	}
+
+	if report.Samples[1].Background != "#222222" {
+		t.Errorf("Expected inherited dark background, got %+v", report.Samples[1])
+	}
+}
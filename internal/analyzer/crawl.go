@@ -0,0 +1,266 @@
+package analyzer
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+// defaultCrawlConcurrency is how many pages are analyzed in parallel
+const defaultCrawlConcurrency = 3
+
+// crawlBatchSize is how many queued pages are taken to analyze at a time
+const crawlBatchSize = 100
+
+// CrawlOptions limits a multi-page crawl. Zero values fall back to the
+// analyzer configuration.
+type CrawlOptions struct {
+	MaxDepth    int
+	MaxPages    int
+	Concurrency int
+	// Profile names the checks run on every page; empty uses the default
+	Profile string
+}
+
+// linkStatusCache shares link check outcomes between crawled pages so a
+// navigation link present on every page is only checked once
+type linkStatusCache struct {
+	mu       sync.Mutex
+	statuses map[string]models.LinkStatus
+}
+
+func newLinkStatusCache() *linkStatusCache {
+	return &linkStatusCache{statuses: make(map[string]models.LinkStatus)}
+}
+
+// check returns statuses for all links, only checking links not seen
+// before. A nil cache checks everything.
+func (c *linkStatusCache) check(ctx context.Context, links []models.Link, config CheckLinksConfig) []models.LinkStatus {
+	if c == nil {
+		return CheckAllLinks(ctx, links, config)
+	}
+
+	var statuses []models.LinkStatus
+	var unchecked []models.Link
+
+	c.mu.Lock()
+	for _, link := range links {
+		if status, ok := c.statuses[link.URL]; ok {
+			status.Type = link.Type
+			statuses = append(statuses, status)
+		} else {
+			unchecked = append(unchecked, link)
+		}
+	}
+	c.mu.Unlock()
+
+	fresh := CheckAllLinks(ctx, unchecked, config)
+
+	c.mu.Lock()
+	for _, status := range fresh {
+		c.statuses[status.URL] = status
+	}
+	c.mu.Unlock()
+
+	return append(statuses, fresh...)
+}
+
+// Crawl analyzes targetURL and follows internal links breadth-first up to
+// the configured depth and page limit, returning every page's result plus
+// a site-wide summary. The pages seen and queued beyond the configured
+// memory limit are kept on disk; see Config.CrawlMemoryURLs.
+func (a *Analyzer) Crawl(ctx context.Context, targetURL string, opts CrawlOptions) (*models.CrawlResult, error) {
+	opts = a.crawlDefaults(opts)
+
+	profile, err := a.profile(opts.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	fetcher, err := a.Fetcher("", a.config.Flags)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, meter := withUsage(ctx)
+	progress := progressFrom(ctx)
+	pc := &pageContext{checked: newLinkStatusCache(), profile: profile, flags: a.config.Flags, fetcher: fetcher}
+	crawl := &models.CrawlResult{StartURL: targetURL}
+
+	state := newCrawlState(a.config.CrawlMemoryURLs, a.config.CrawlSpillDir, a.config.CrawlBloomFPRate)
+	defer state.close()
+	if _, err := state.visit(crawlKey(targetURL)); err != nil {
+		return nil, err
+	}
+	if err := state.push(crawlEntry{url: targetURL}); err != nil {
+		return nil, err
+	}
+
+	// The queue holds pages in the order found, so depths are taken in turn
+	for len(crawl.Pages) < opts.MaxPages {
+		batch, err := state.pop(min(crawlBatchSize, opts.MaxPages-len(crawl.Pages)))
+		if err != nil {
+			return nil, err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		progress.AddPages(len(batch))
+
+		pages := make([]models.CrawlPage, len(batch))
+		discovered := make([][]models.Link, len(batch))
+		errs := make([]error, len(batch))
+
+		runLimited(ctx, len(batch), opts.Concurrency, func(i int) {
+			start := time.Now()
+			defer func() { progress.PageDone(time.Since(start)) }()
+			result, links, err := a.analyzePage(ctx, batch[i].url, pc)
+			pages[i] = models.CrawlPage{URL: batch[i].url, Depth: batch[i].depth, Result: result}
+			if err != nil {
+				pages[i].Error = err.Error()
+			}
+			discovered[i] = links
+			errs[i] = err
+		})
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// The start page failing means there is nothing to crawl
+		if len(crawl.Pages) == 0 && errs[0] != nil {
+			return nil, a.redactor.Error(errs[0])
+		}
+
+		crawl.Pages = append(crawl.Pages, pages...)
+		if len(crawl.Pages) >= opts.MaxPages {
+			break
+		}
+
+		for i, links := range discovered {
+			for _, link := range links {
+				if link.Type != models.LinkTypeInternal || !isCrawlable(link.URL) {
+					continue
+				}
+				unseen, err := state.visit(crawlKey(link.URL))
+				if err != nil {
+					return nil, err
+				}
+				if !unseen || outOfScope(link.URL) {
+					continue
+				}
+				if pc.disallowedByRobots(ctx, a, targetURL, link) {
+					crawl.RobotsSkipped = append(crawl.RobotsSkipped, stripFragment(link.URL))
+					continue
+				}
+				if batch[i].depth >= opts.MaxDepth {
+					continue
+				}
+				if err := state.push(crawlEntry{url: stripFragment(link.URL), depth: batch[i].depth + 1}); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	crawl.Summary = summarizeCrawl(crawl.Pages)
+	crawl.Usage = meter.usage(len(crawl.Pages))
+	a.redactor.Walk(crawl)
+	return crawl, nil
+}
+
+func (a *Analyzer) crawlDefaults(opts CrawlOptions) CrawlOptions {
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = a.config.CrawlMaxDepth
+	}
+	if opts.MaxPages <= 0 {
+		opts.MaxPages = a.config.CrawlMaxPages
+	}
+	if opts.MaxPages <= 0 {
+		opts.MaxPages = 1
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultCrawlConcurrency
+	}
+	return opts
+}
+
+// summarizeCrawl aggregates per-page results into site-wide numbers
+func summarizeCrawl(pages []models.CrawlPage) models.CrawlSummary {
+	summary := models.CrawlSummary{}
+	broken := make(map[string]bool)
+	titles := make(map[string]int)
+
+	for _, page := range pages {
+		if page.Result == nil {
+			summary.PagesFailed++
+			continue
+		}
+		summary.PagesCrawled++
+
+		result := page.Result
+		summary.InternalLinks += result.InternalLinks
+		summary.ExternalLinks += result.ExternalLinks
+		for _, link := range result.InaccessibleLinks {
+			broken[link.URL] = true
+		}
+		if result.HasLoginForm {
+			summary.PagesWithLoginForm++
+		}
+		if result.Title == "No title" {
+			summary.PagesMissingTitle++
+		} else {
+			titles[result.Title]++
+		}
+		if result.Headings["h1"] == 0 {
+			summary.PagesMissingH1++
+		}
+	}
+
+	summary.UniqueBrokenLinks = len(broken)
+	for _, count := range titles {
+		if count > 1 {
+			summary.DuplicateTitles += count
+		}
+	}
+
+	return summary
+}
+
+// isCrawlable skips links to documents and media the crawler can't analyze
+func isCrawlable(link string) bool {
+	if documentType(link) != "" {
+		return false
+	}
+	switch formatFromPath(link) {
+	case "jpeg", "png", "gif", "webp", "avif", "svg":
+		return false
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(path.Ext(u.Path)) {
+	case ".css", ".js", ".json", ".xml", ".txt", ".mp4", ".mp3", ".woff", ".woff2", ".ico":
+		return false
+	}
+	return true
+}
+
+// crawlKey identifies a page regardless of fragment and trailing slash
+func crawlKey(link string) string {
+	return normalizeURL(link)
+}
+
+func stripFragment(link string) string {
+	if idx := strings.Index(link, "#"); idx >= 0 {
+		return link[:idx]
+	}
+	return link
+}
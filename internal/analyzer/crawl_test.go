@@ -0,0 +1,159 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAnalyzer_Crawl(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	var mu sync.Mutex
+	hits := map[string]int{}
+
+	pages := map[string]string{
+		"/":         `<html><head><title>Home</title></head><body><h1>Home</h1><a href="/a">A</a><a href="/b#top">B</a><a href="/file.pdf">PDF</a></body></html>`,
+		"/a":        `<html><head><title>Shared</title></head><body><a href="/">Home</a><a href="/a/deep">Deep</a></body></html>`,
+		"/b":        `<html><head><title>Shared</title></head><body><h1>B</h1><a href="/missing">Missing</a></body></html>`,
+		"/a/deep":   `<html><head><title>Deep</title></head><body><a href="/a/deeper">Deeper</a></body></html>`,
+		"/file.pdf": "%PDF-1.4",
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		if r.Method == http.MethodGet {
+			hits[r.URL.Path]++
+		}
+		mu.Unlock()
+
+		body, ok := pages[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	a := NewAnalyzer(&Config{
+		RequestTimeout:  2 * time.Second,
+		LinkTimeout:     time.Second,
+		MaxWorkers:      5,
+		MaxResponseSize: 1024 * 1024,
+		MaxURLLength:    2048,
+		MaxRedirects:    5,
+	})
+
+	crawl, err := a.Crawl(context.Background(), ts.URL+"/", CrawlOptions{MaxDepth: 1, MaxPages: 10})
+	if err != nil {
+		t.Fatalf("Crawl failed: %v", err)
+	}
+
+	if len(crawl.Pages) != 3 {
+		t.Fatalf("Expected 3 pages within depth 1, got %d", len(crawl.Pages))
+	}
+
+	if hits["/a/deep"] != 0 || hits["/file.pdf"] > 1 {
+		t.Errorf("Crawl went past depth limit or crawled documents: %v", hits)
+	}
+
+	summary := crawl.Summary
+	if summary.PagesCrawled != 3 || summary.UniqueBrokenLinks != 1 || summary.DuplicateTitles != 2 || summary.PagesMissingH1 != 1 {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+
+	limited, err := a.Crawl(context.Background(), ts.URL+"/", CrawlOptions{MaxDepth: 3, MaxPages: 2})
+	if err != nil {
+		t.Fatalf("Crawl failed: %v", err)
+	}
+	if len(limited.Pages) != 2 {
+		t.Errorf("Expected page limit of 2, got %d", len(limited.Pages))
+	}
+
+	// Spilling to disk crawls the same pages in the same order
+	a.config.CrawlMemoryURLs = 1
+	a.config.CrawlSpillDir = t.TempDir()
+	spilled, err := a.Crawl(context.Background(), ts.URL+"/", CrawlOptions{MaxDepth: 1, MaxPages: 10})
+	if err != nil {
+		t.Fatalf("Crawl failed: %v", err)
+	}
+	if len(spilled.Pages) != len(crawl.Pages) {
+		t.Fatalf("Expected %d pages, got %d", len(crawl.Pages), len(spilled.Pages))
+	}
+	for i, page := range spilled.Pages {
+		if page.URL != crawl.Pages[i].URL || page.Depth != crawl.Pages[i].Depth {
+			t.Errorf("Expected page %d to be %s, got %s", i, crawl.Pages[i].URL, page.URL)
+		}
+	}
+}
+
+func TestAnalyzer_CrawlRespectsRobots(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	var mu sync.Mutex
+	hits := map[string]int{}
+
+	pages := map[string]string{
+		"/":           `<html><head><title>Home</title></head><body><a href="/open">Open</a><a href="/private/a">Private</a></body></html>`,
+		"/open":       `<html><head><title>Open</title></head><body><a href="/private/b">Private</a></body></html>`,
+		"/private/a":  `<html><head><title>Private</title></head><body></body></html>`,
+		"/private/b":  `<html><head><title>Private</title></head><body></body></html>`,
+		"/robots.txt": "User-agent: *\nDisallow: /private\n",
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path]++
+		mu.Unlock()
+
+		body, ok := pages[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	a := NewAnalyzer(&Config{
+		RequestTimeout:  2 * time.Second,
+		LinkTimeout:     time.Second,
+		MaxWorkers:      5,
+		MaxResponseSize: 1024 * 1024,
+		MaxURLLength:    2048,
+		MaxRedirects:    5,
+		RespectRobots:   true,
+	})
+
+	crawl, err := a.Crawl(context.Background(), ts.URL+"/", CrawlOptions{MaxDepth: 2, MaxPages: 10})
+	if err != nil {
+		t.Fatalf("Crawl failed: %v", err)
+	}
+
+	if len(crawl.Pages) != 2 {
+		t.Errorf("Expected only the allowed pages to be crawled, got %d", len(crawl.Pages))
+	}
+	if hits["/private/a"] != 0 || hits["/private/b"] != 0 {
+		t.Errorf("Disallowed paths were requested: %v", hits)
+	}
+	if hits["/robots.txt"] != 1 {
+		t.Errorf("Expected robots.txt to be fetched once, got %d", hits["/robots.txt"])
+	}
+	if len(crawl.RobotsSkipped) != 2 {
+		t.Errorf("Expected both private pages to be reported, got %v", crawl.RobotsSkipped)
+	}
+
+	home := crawl.Pages[0].Result
+	if len(home.RobotsSkipped) != 1 || home.RobotsSkipped[0] != ts.URL+"/private/a" {
+		t.Errorf("Expected the private link to be skipped on the home page, got %v", home.RobotsSkipped)
+	}
+}
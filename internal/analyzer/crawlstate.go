@@ -0,0 +1,194 @@
+package analyzer
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	_ "modernc.org/sqlite"
+)
+
+// crawlEntry is a page waiting to be analyzed
+type crawlEntry struct {
+	url   string
+	depth int
+}
+
+// crawlState is the set of pages a crawl has seen and the queue of those it
+// has yet to analyze. Up to memoryURLs entries of each are held in memory;
+// the rest spill to a SQLite file in spillDir, created on first use and
+// removed by close, so crawls of large sites don't run out of memory. A
+// limit of zero or less keeps everything in memory. With a bloom set the
+// seen pages are tracked approximately in memory instead, never spilling.
+type crawlState struct {
+	memoryURLs int
+	spillDir   string
+
+	visited map[string]bool
+	bloom   *bloomSet
+	// queue is the head of the queue; the spilled entries on disk follow
+	// it in order
+	queue   []crawlEntry
+	spilled int
+
+	db   *sql.DB
+	path string
+}
+
+// newCrawlState returns an empty state. A falsePositive rate between 0
+// and 1 tracks seen pages in a bloom set, which wrongly takes about that
+// share of unseen pages for seen ones, so they aren't crawled.
+func newCrawlState(memoryURLs int, spillDir string, falsePositive float64) *crawlState {
+	s := &crawlState{memoryURLs: memoryURLs, spillDir: spillDir, visited: make(map[string]bool)}
+	if falsePositive > 0 && falsePositive < 1 {
+		s.bloom = newBloomSet(falsePositive)
+	}
+	return s
+}
+
+// full reports whether n entries use up the memory limit
+func (s *crawlState) full(n int) bool {
+	return s.memoryURLs > 0 && n >= s.memoryURLs
+}
+
+// visit marks key seen, reporting whether it was unseen before
+func (s *crawlState) visit(key string) (bool, error) {
+	if s.bloom != nil {
+		return s.bloom.add(key), nil
+	}
+	if s.visited[key] {
+		return false, nil
+	}
+	if !s.full(len(s.visited)) {
+		// Keys spill only once the map is full, which it stays
+		s.visited[key] = true
+		return true, nil
+	}
+	if err := s.spill(); err != nil {
+		return false, err
+	}
+	res, err := s.db.Exec(`INSERT OR IGNORE INTO visited (key) VALUES (?)`, key)
+	if err != nil {
+		return false, fmt.Errorf("crawl spill: %w", err)
+	}
+	added, err := res.RowsAffected()
+	return added > 0, err
+}
+
+// push queues a page behind every page queued before it
+func (s *crawlState) push(entry crawlEntry) error {
+	if s.spilled == 0 && !s.full(len(s.queue)) {
+		s.queue = append(s.queue, entry)
+		return nil
+	}
+	if err := s.spill(); err != nil {
+		return err
+	}
+	if _, err := s.db.Exec(`INSERT INTO queue (url, depth) VALUES (?, ?)`, entry.url, entry.depth); err != nil {
+		return fmt.Errorf("crawl spill: %w", err)
+	}
+	s.spilled++
+	return nil
+}
+
+// pop takes up to n pages off the front of the queue
+func (s *crawlState) pop(n int) ([]crawlEntry, error) {
+	var entries []crawlEntry
+	for len(entries) < n {
+		if len(s.queue) == 0 {
+			if err := s.refill(); err != nil {
+				return nil, err
+			}
+			if len(s.queue) == 0 {
+				break
+			}
+		}
+		take := min(n-len(entries), len(s.queue))
+		entries = append(entries, s.queue[:take]...)
+		s.queue = s.queue[take:]
+	}
+	return entries, nil
+}
+
+// refill moves the oldest spilled entries back into memory
+func (s *crawlState) refill() error {
+	if s.spilled == 0 {
+		return nil
+	}
+	limit := s.spilled
+	if s.memoryURLs > 0 {
+		limit = min(limit, s.memoryURLs)
+	}
+
+	rows, err := s.db.Query(`SELECT seq, url, depth FROM queue ORDER BY seq LIMIT ?`, limit)
+	if err != nil {
+		return fmt.Errorf("crawl spill: %w", err)
+	}
+	defer rows.Close()
+	var last int64
+	queue := make([]crawlEntry, 0, limit)
+	for rows.Next() {
+		var entry crawlEntry
+		if err := rows.Scan(&last, &entry.url, &entry.depth); err != nil {
+			return fmt.Errorf("crawl spill: %w", err)
+		}
+		queue = append(queue, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("crawl spill: %w", err)
+	}
+	rows.Close()
+
+	if _, err := s.db.Exec(`DELETE FROM queue WHERE seq <= ?`, last); err != nil {
+		return fmt.Errorf("crawl spill: %w", err)
+	}
+	s.queue = queue
+	s.spilled -= len(queue)
+	return nil
+}
+
+// spill opens the spill file unless it is open already
+func (s *crawlState) spill() error {
+	if s.db != nil {
+		return nil
+	}
+	f, err := os.CreateTemp(s.spillDir, "crawl-*.db")
+	if err != nil {
+		return fmt.Errorf("crawl spill: %w", err)
+	}
+	f.Close()
+
+	db, err := sql.Open("sqlite", f.Name())
+	if err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("crawl spill: %w", err)
+	}
+	// The file is thrown away with the crawl, so durability doesn't matter
+	db.SetMaxOpenConns(1)
+	_, err = db.Exec(`
+		PRAGMA journal_mode = OFF;
+		PRAGMA synchronous = OFF;
+		CREATE TABLE visited (key TEXT PRIMARY KEY) WITHOUT ROWID;
+		CREATE TABLE queue (seq INTEGER PRIMARY KEY AUTOINCREMENT, url TEXT NOT NULL, depth INTEGER NOT NULL);
+	`)
+	if err != nil {
+		db.Close()
+		os.Remove(f.Name())
+		return fmt.Errorf("crawl spill: %w", err)
+	}
+	s.db, s.path = db, f.Name()
+	return nil
+}
+
+// close removes the spill file, if any
+func (s *crawlState) close() error {
+	if s.db == nil {
+		return nil
+	}
+	err := s.db.Close()
+	if rmErr := os.Remove(s.path); err == nil {
+		err = rmErr
+	}
+	s.db = nil
+	return err
+}
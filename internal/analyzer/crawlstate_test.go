@@ -0,0 +1,66 @@
+package analyzer
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestCrawlStateSpill(t *testing.T) {
+	dir := t.TempDir()
+	state := newCrawlState(2, dir, 0)
+
+	for i := range 5 {
+		key := fmt.Sprintf("https://example.com/%d", i)
+		if unseen, err := state.visit(key); err != nil || !unseen {
+			t.Fatalf("Expected %s to be unseen, got %v, %v", key, unseen, err)
+		}
+		if err := state.push(crawlEntry{url: key, depth: i / 2}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Seen keys are found whether held in memory or on disk
+	for _, key := range []string{"https://example.com/0", "https://example.com/4"} {
+		if unseen, err := state.visit(key); err != nil || unseen {
+			t.Errorf("Expected %s to be seen, got %v, %v", key, unseen, err)
+		}
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Errorf("Expected a spill file, got %v", files)
+	}
+
+	var order []string
+	batch, err := state.pop(3)
+	if err != nil || len(batch) != 3 {
+		t.Fatalf("Expected 3 entries, got %v, %v", batch, err)
+	}
+	for _, entry := range batch {
+		order = append(order, entry.url)
+	}
+	// Pushed while spilled entries wait, so queued behind them
+	if err := state.push(crawlEntry{url: "https://example.com/5", depth: 3}); err != nil {
+		t.Fatal(err)
+	}
+	rest, err := state.pop(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range rest {
+		order = append(order, entry.url)
+	}
+	for i, got := range order {
+		if want := fmt.Sprintf("https://example.com/%d", i); got != want {
+			t.Errorf("Expected entry %d to be %s, got %s", i, want, got)
+		}
+	}
+	if len(order) != 6 || rest[len(rest)-1].depth != 3 {
+		t.Errorf("Expected 6 entries in order, got %v", order)
+	}
+
+	if err := state.close(); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("Expected the spill file removed, got %v", files)
+	}
+}
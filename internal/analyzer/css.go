@@ -0,0 +1,114 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// cssRule is a qualified rule from a stylesheet
+type cssRule struct {
+	selector     string
+	declarations []cssDeclaration
+}
+
+// cssDeclaration is a single property: value pair
+type cssDeclaration struct {
+	property  string
+	value     string
+	important bool
+}
+
+var cssCommentPattern = regexp.MustCompile(`(?s)/\*.*?\*/`)
+
+// parseCSS does a lightweight parse of a stylesheet into flat rules.
+// Rules nested in @media/@supports are included; other at-rules
+// (@font-face, @keyframes, @import, ...) are skipped.
+func parseCSS(text string) []cssRule {
+	text = cssCommentPattern.ReplaceAllString(text, "")
+
+	var rules []cssRule
+	for len(text) > 0 {
+		open := strings.IndexAny(text, "{;")
+		if open < 0 {
+			break
+		}
+
+		prelude := strings.TrimSpace(text[:open])
+		if text[open] == ';' {
+			// Statement at-rule such as @import or @charset
+			text = text[open+1:]
+			continue
+		}
+
+		close := matchingBrace(text, open)
+		if close < 0 {
+			break
+		}
+		block := text[open+1 : close]
+		text = text[close+1:]
+
+		if strings.HasPrefix(prelude, "@") {
+			name := strings.ToLower(firstField(prelude))
+			if name == "@media" || name == "@supports" {
+				rules = append(rules, parseCSS(block)...)
+			}
+			continue
+		}
+
+		if prelude != "" {
+			rules = append(rules, cssRule{
+				selector:     prelude,
+				declarations: parseDeclarations(block),
+			})
+		}
+	}
+
+	return rules
+}
+
+// parseDeclarations parses the body of a rule or a style attribute
+func parseDeclarations(block string) []cssDeclaration {
+	var declarations []cssDeclaration
+
+	for _, part := range strings.Split(block, ";") {
+		property, value, found := strings.Cut(part, ":")
+		if !found {
+			continue
+		}
+
+		property = strings.ToLower(strings.TrimSpace(property))
+		value = strings.TrimSpace(value)
+		important := false
+		if idx := strings.Index(strings.ToLower(value), "!important"); idx >= 0 {
+			important = true
+			value = strings.TrimSpace(value[:idx])
+		}
+
+		if property != "" {
+			declarations = append(declarations, cssDeclaration{
+				property:  property,
+				value:     value,
+				important: important,
+			})
+		}
+	}
+
+	return declarations
+}
+
+// matchingBrace returns the index of the brace closing the one at open
+func matchingBrace(text string, open int) int {
+	depth := 0
+	for i := open; i < len(text); i++ {
+		switch text[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
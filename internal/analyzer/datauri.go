@@ -0,0 +1,149 @@
+package analyzer
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"website-analyzer/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// largeDataURIBytes is the decoded size from which an inline data URI is
+// flagged; larger payloads are better served as cacheable files
+const largeDataURIBytes = 4 * 1024
+
+var cssDataURIPattern = regexp.MustCompile(`url\(\s*['"]?(data:[^'")]+)`)
+
+// AuditDataURIs finds data: URIs embedded in element attributes (src,
+// href, poster, each candidate of a srcset, ...) and inline CSS and
+// reports their decoded sizes
+func AuditDataURIs(doc *goquery.Document) *models.DataURIReport {
+	report := &models.DataURIReport{}
+
+	add := func(location, uri string) {
+		entry := parseDataURI(uri)
+		entry.Location = location
+		entry.Flagged = entry.DecodedSize >= largeDataURIBytes
+
+		report.URIs = append(report.URIs, entry)
+		report.TotalDecodedSize += entry.DecodedSize
+		if entry.Flagged {
+			report.Flagged++
+		}
+	}
+
+	doc.Find("*").Each(func(i int, s *goquery.Selection) {
+		node := s.Get(0)
+		for _, attr := range node.Attr {
+			value := strings.TrimSpace(attr.Val)
+			location := node.Data + "[" + attr.Key + "]"
+
+			switch attr.Key {
+			case "style":
+				for _, match := range cssDataURIPattern.FindAllStringSubmatch(value, -1) {
+					add(location, match[1])
+				}
+			case "srcset", "imagesrcset":
+				for _, uri := range srcsetDataURIs(value) {
+					add(location, uri)
+				}
+			default:
+				if isDataURI(value) {
+					add(location, value)
+				}
+			}
+		}
+
+		if node.Data == "style" && node.Type == html.ElementNode {
+			for _, match := range cssDataURIPattern.FindAllStringSubmatch(s.Text(), -1) {
+				add("style", match[1])
+			}
+		}
+	})
+
+	return report
+}
+
+// srcsetDataURIs returns the data: URIs among the image candidates of a
+// srcset
+func srcsetDataURIs(srcset string) []string {
+	var uris []string
+	for _, uri := range srcsetURLs(srcset) {
+		if isDataURI(uri) {
+			uris = append(uris, uri)
+		}
+	}
+	return uris
+}
+
+// srcsetURLs returns the URLs of the image candidates of a srcset, split
+// as browsers do: a candidate's URL runs to the next whitespace, so the
+// comma inside a data URI doesn't end it, and its descriptors run to the
+// next comma.
+func srcsetURLs(srcset string) []string {
+	var urls []string
+	rest := srcset
+	for {
+		rest = strings.TrimLeft(rest, ", \t\n\f\r")
+		if rest == "" {
+			return urls
+		}
+		end := strings.IndexAny(rest, " \t\n\f\r")
+		if end < 0 {
+			end = len(rest)
+		}
+		uri, descriptors := rest[:end], rest[end:]
+		if trimmed := strings.TrimRight(uri, ","); trimmed != uri {
+			// A URL ending in commas has no descriptors
+			uri, descriptors = trimmed, ""
+		}
+		urls = append(urls, uri)
+		rest = rest[end:]
+		if descriptors != "" {
+			if comma := strings.IndexByte(descriptors, ','); comma >= 0 {
+				rest = descriptors[comma:]
+			} else {
+				rest = ""
+			}
+		}
+	}
+}
+
+func isDataURI(value string) bool {
+	return strings.HasPrefix(strings.ToLower(value), "data:")
+}
+
+// parseDataURI extracts the media type and computes the decoded payload size
+// without actually decoding it
+func parseDataURI(uri string) models.DataURI {
+	entry := models.DataURI{EncodedSize: len(uri)}
+
+	header, payload, found := strings.Cut(strings.TrimPrefix(uri, uri[:5]), ",")
+	if !found {
+		return entry
+	}
+
+	params := strings.Split(header, ";")
+	entry.MediaType = params[0]
+	if entry.MediaType == "" {
+		entry.MediaType = "text/plain"
+	}
+
+	isBase64 := strings.EqualFold(params[len(params)-1], "base64")
+	if isBase64 {
+		payload = strings.TrimRight(strings.Join(strings.Fields(payload), ""), "=")
+		entry.DecodedSize = len(payload) * 3 / 4
+		return entry
+	}
+
+	if decoded, err := url.PathUnescape(payload); err == nil {
+		entry.DecodedSize = len(decoded)
+	} else {
+		entry.DecodedSize = len(payload)
+	}
+
+	return entry
+}
@@ -0,0 +1,89 @@
+package analyzer
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestAuditDataURIs(t *testing.T) {
+	large := base64.StdEncoding.EncodeToString(make([]byte, 6000))
+	small := base64.StdEncoding.EncodeToString(make([]byte, 30))
+
+	html := `
+		<html><head>
+			<style>.bg { background: url('data:image/png;base64,` + large + `'); }</style>
+		</head><body>
+			<img src="data:image/gif;base64,` + small + `">
+			<div style="background-image: url(data:image/svg+xml,%3Csvg%3E%3C/svg%3E)"></div>
+			<a href="/page">Normal link</a>
+		</body></html>
+	`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	report := AuditDataURIs(doc)
+
+	if len(report.URIs) != 3 {
+		t.Fatalf("Expected 3 data URIs, got %d", len(report.URIs))
+	}
+
+	if report.Flagged != 1 {
+		t.Errorf("Expected 1 flagged data URI, got %d", report.Flagged)
+	}
+
+	sizes := map[string]int{}
+	for _, uri := range report.URIs {
+		sizes[uri.MediaType] = uri.DecodedSize
+	}
+
+	if sizes["image/png"] != 6000 {
+		t.Errorf("Expected decoded PNG size 6000, got %d", sizes["image/png"])
+	}
+	if sizes["image/gif"] != 30 {
+		t.Errorf("Expected decoded GIF size 30, got %d", sizes["image/gif"])
+	}
+	if sizes["image/svg+xml"] != len("<svg></svg>") {
+		t.Errorf("Expected decoded SVG size %d, got %d", len("<svg></svg>"), sizes["image/svg+xml"])
+	}
+}
+
+func TestAuditDataURIsInAttributes(t *testing.T) {
+	gif := "data:image/gif;base64," + base64.StdEncoding.EncodeToString(make([]byte, 30))
+	tests := []struct {
+		name     string
+		html     string
+		location string
+		uris     int
+	}{
+		{"src", `<img src="` + gif + `">`, "img[src]", 1},
+		{"srcset", `<img srcset="/small.png 1x, ` + gif + ` 2x,` + gif + `">`, "img[srcset]", 2},
+		{"href", `<link rel="icon" href="` + gif + `">`, "link[href]", 1},
+		{"poster", `<video poster="` + gif + `"></video>`, "video[poster]", 1},
+		{"ordinary URLs", `<img src="/a.gif" srcset="/a.gif 1x, /b.gif 2x"><a href="/data:page">x</a>`, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><body>" + tt.html + "</body></html>"))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+
+			report := AuditDataURIs(doc)
+			if len(report.URIs) != tt.uris {
+				t.Fatalf("Expected %d data URIs, got %+v", tt.uris, report.URIs)
+			}
+			for _, uri := range report.URIs {
+				if uri.Location != tt.location || uri.MediaType != "image/gif" || uri.DecodedSize != 30 {
+					t.Errorf("Unexpected data URI %+v", uri)
+				}
+			}
+		})
+	}
+}
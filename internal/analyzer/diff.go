@@ -0,0 +1,140 @@
+package analyzer
+
+import (
+	"slices"
+	"strings"
+
+	"website-analyzer/internal/models"
+)
+
+// diffCounts lists the numbers compared between two results
+var diffCounts = []struct {
+	name  string
+	value func(*models.AnalysisResult) int64
+}{
+	{name: "internal_links", value: func(r *models.AnalysisResult) int64 { return int64(r.InternalLinks) }},
+	{name: "external_links", value: func(r *models.AnalysisResult) int64 { return int64(r.ExternalLinks) }},
+	{name: "broken_links", value: func(r *models.AnalysisResult) int64 { return int64(len(r.InaccessibleLinks)) }},
+	{name: "word_count", value: func(r *models.AnalysisResult) int64 { return int64(r.WordCount) }},
+	{name: "html_size", value: func(r *models.AnalysisResult) int64 { return r.HTMLSize }},
+}
+
+// DiffResults compares two analyses: links added and removed, links that
+// broke or were fixed, heading counts, link and page size counts, scores,
+// and the title and meta tags. The sides of the returned diff are left for
+// the caller to fill in.
+func DiffResults(from, to *models.AnalysisResult) *models.AnalysisDiff {
+	diff := &models.AnalysisDiff{
+		LinksAdded:   []string{},
+		LinksRemoved: []string{},
+		NewlyBroken:  []models.LinkError{},
+		Fixed:        []string{},
+		Headings:     []models.CountChange{},
+		Counts:       []models.CountChange{},
+		Fields:       []models.FieldChange{},
+	}
+
+	// Results stored before link lists were kept can't be compared link
+	// by link
+	if (from.Links == nil && from.InternalLinks+from.ExternalLinks > 0) ||
+		(to.Links == nil && to.InternalLinks+to.ExternalLinks > 0) {
+		diff.LinksUnavailable = true
+	} else {
+		diff.LinksAdded = missingFrom(to.Links, from.Links)
+		diff.LinksRemoved = missingFrom(from.Links, to.Links)
+	}
+
+	wasBroken := make(map[string]bool, len(from.InaccessibleLinks))
+	for _, link := range from.InaccessibleLinks {
+		wasBroken[link.URL] = true
+	}
+	isBroken := make(map[string]bool, len(to.InaccessibleLinks))
+	for _, link := range to.InaccessibleLinks {
+		isBroken[link.URL] = true
+		if !wasBroken[link.URL] {
+			diff.NewlyBroken = append(diff.NewlyBroken, link)
+		}
+	}
+	for _, link := range from.InaccessibleLinks {
+		if !isBroken[link.URL] {
+			diff.Fixed = append(diff.Fixed, link.URL)
+		}
+	}
+
+	for _, level := range unionKeys(from.Headings, to.Headings) {
+		if was, now := from.Headings[level], to.Headings[level]; was != now {
+			diff.Headings = append(diff.Headings, models.CountChange{Name: level, From: int64(was), To: int64(now)})
+		}
+	}
+
+	for _, count := range diffCounts {
+		if was, now := count.value(from), count.value(to); was != now {
+			diff.Counts = append(diff.Counts, models.CountChange{Name: count.name, From: was, To: now})
+		}
+	}
+	if from.Scores != nil && to.Scores != nil {
+		for _, score := range gateScores {
+			if was, now := score.value(from.Scores), score.value(to.Scores); was != now {
+				diff.Counts = append(diff.Counts, models.CountChange{Name: score.name + "_score", From: int64(was), To: int64(now)})
+			}
+		}
+	}
+
+	addField := func(field, was, now string) {
+		if was != now {
+			diff.Fields = append(diff.Fields, models.FieldChange{Field: field, From: was, To: now})
+		}
+	}
+	addField("title", from.Title, to.Title)
+	addField("html_version", from.HTMLVersion, to.HTMLVersion)
+	wasSEO, nowSEO := seoOrEmpty(from), seoOrEmpty(to)
+	addField("meta_description", wasSEO.Description, nowSEO.Description)
+	addField("meta_keywords", strings.Join(wasSEO.Keywords, ", "), strings.Join(nowSEO.Keywords, ", "))
+	addField("canonical", wasSEO.Canonical, nowSEO.Canonical)
+	addField("meta_robots", strings.Join(wasSEO.Robots, ", "), strings.Join(nowSEO.Robots, ", "))
+	for _, property := range unionKeys(wasSEO.OpenGraph, nowSEO.OpenGraph) {
+		addField("og:"+property, wasSEO.OpenGraph[property], nowSEO.OpenGraph[property])
+	}
+	for _, name := range unionKeys(wasSEO.TwitterCard, nowSEO.TwitterCard) {
+		addField("twitter:"+name, wasSEO.TwitterCard[name], nowSEO.TwitterCard[name])
+	}
+
+	return diff
+}
+
+// missingFrom returns the entries of list that other lacks, in list order
+func missingFrom(list, other []string) []string {
+	known := make(map[string]bool, len(other))
+	for _, s := range other {
+		known[s] = true
+	}
+	missing := []string{}
+	for _, s := range list {
+		if !known[s] {
+			missing = append(missing, s)
+		}
+	}
+	return missing
+}
+
+// unionKeys returns the keys of a and b, sorted
+func unionKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func seoOrEmpty(result *models.AnalysisResult) *models.SEOReport {
+	if result.SEO == nil {
+		return &models.SEOReport{}
+	}
+	return result.SEO
+}
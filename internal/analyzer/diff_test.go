@@ -0,0 +1,92 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"website-analyzer/internal/models"
+)
+
+func TestDiffResults(t *testing.T) {
+	from := &models.AnalysisResult{
+		Title:             "Shop",
+		HTMLVersion:       "HTML5",
+		WordCount:         300,
+		Headings:          map[string]int{"h1": 1, "h2": 4},
+		InternalLinks:     2,
+		ExternalLinks:     1,
+		Links:             []string{"https://shop.example/a", "https://shop.example/b", "https://partner.example/"},
+		InaccessibleLinks: []models.LinkError{{URL: "https://shop.example/b"}},
+		SEO: &models.SEOReport{
+			Description: "Buy things",
+			OpenGraph:   map[string]string{"title": "Shop", "image": "https://shop.example/og.png"},
+		},
+		Scores: &models.Scores{Overall: 80, SEO: 90, Accessibility: 70, Links: 80},
+	}
+	to := &models.AnalysisResult{
+		Title:             "Shop - Sale",
+		HTMLVersion:       "HTML5",
+		WordCount:         300,
+		Headings:          map[string]int{"h1": 2, "h2": 4, "h3": 1},
+		InternalLinks:     2,
+		ExternalLinks:     1,
+		Links:             []string{"https://shop.example/a", "https://shop.example/sale", "https://partner.example/"},
+		InaccessibleLinks: []models.LinkError{{URL: "https://partner.example/", StatusCode: 500}},
+		SEO: &models.SEOReport{
+			Description: "Buy things",
+			Canonical:   "https://shop.example/",
+			OpenGraph:   map[string]string{"title": "Shop - Sale"},
+		},
+		Scores: &models.Scores{Overall: 78, SEO: 90, Accessibility: 70, Links: 74},
+	}
+
+	diff := DiffResults(from, to)
+	if !reflect.DeepEqual(diff.LinksAdded, []string{"https://shop.example/sale"}) ||
+		!reflect.DeepEqual(diff.LinksRemoved, []string{"https://shop.example/b"}) {
+		t.Errorf("Unexpected link changes: added %v, removed %v", diff.LinksAdded, diff.LinksRemoved)
+	}
+	if len(diff.NewlyBroken) != 1 || diff.NewlyBroken[0].StatusCode != 500 || !reflect.DeepEqual(diff.Fixed, []string{"https://shop.example/b"}) {
+		t.Errorf("Unexpected broken link changes: %+v, fixed %v", diff.NewlyBroken, diff.Fixed)
+	}
+
+	wantHeadings := []models.CountChange{{Name: "h1", From: 1, To: 2}, {Name: "h3", From: 0, To: 1}}
+	if !reflect.DeepEqual(diff.Headings, wantHeadings) {
+		t.Errorf("Headings = %+v, want %+v", diff.Headings, wantHeadings)
+	}
+	wantCounts := []models.CountChange{{Name: "overall_score", From: 80, To: 78}, {Name: "links_score", From: 80, To: 74}}
+	if !reflect.DeepEqual(diff.Counts, wantCounts) {
+		t.Errorf("Counts = %+v, want %+v", diff.Counts, wantCounts)
+	}
+	wantFields := []models.FieldChange{
+		{Field: "title", From: "Shop", To: "Shop - Sale"},
+		{Field: "canonical", From: "", To: "https://shop.example/"},
+		{Field: "og:image", From: "https://shop.example/og.png", To: ""},
+		{Field: "og:title", From: "Shop", To: "Shop - Sale"},
+	}
+	if !reflect.DeepEqual(diff.Fields, wantFields) {
+		t.Errorf("Fields = %+v, want %+v", diff.Fields, wantFields)
+	}
+	if diff.Empty() {
+		t.Error("Expected the diff to report changes")
+	}
+
+	if same := DiffResults(from, from); !same.Empty() {
+		t.Errorf("Expected no changes against itself, got %+v", same)
+	}
+}
+
+func TestDiffResultsWithoutLinkLists(t *testing.T) {
+	// Results stored before link lists were kept
+	old := &models.AnalysisResult{InternalLinks: 3}
+	current := &models.AnalysisResult{InternalLinks: 3, Links: []string{"https://example.com/a"}}
+
+	diff := DiffResults(old, current)
+	if !diff.LinksUnavailable || len(diff.LinksAdded) != 0 || diff.LinksAdded == nil {
+		t.Errorf("Expected link changes to be unavailable, got %+v", diff)
+	}
+
+	// A page without links has nothing to compare
+	if diff := DiffResults(&models.AnalysisResult{}, current); diff.LinksUnavailable || len(diff.LinksAdded) != 1 {
+		t.Errorf("Expected the link to be added, got %+v", diff)
+	}
+}
@@ -0,0 +1,146 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"website-analyzer/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// documentTypes maps file extensions to the document kind reported
+var documentTypes = map[string]string{
+	".pdf":  "pdf",
+	".doc":  "doc",
+	".docx": "docx",
+	".xls":  "xls",
+	".xlsx": "xlsx",
+	".ppt":  "ppt",
+	".pptx": "pptx",
+	".odt":  "odt",
+	".ods":  "ods",
+	".csv":  "csv",
+	".zip":  "zip",
+	".rar":  "rar",
+	".7z":   "7z",
+	".gz":   "gz",
+}
+
+// sizeHintPattern matches size hints such as "(2.4 MB)" in anchor text
+var sizeHintPattern = regexp.MustCompile(`(?i)\d+([.,]\d+)?\s*(bytes|[kmg]i?b)\b`)
+
+// InventoryDocuments collects links to downloadable documents, verifies them
+// with single-byte range requests and flags large files whose anchor text
+// does not warn about their size
+func InventoryDocuments(ctx context.Context, doc *goquery.Document, baseURL string, client *http.Client, maxWorkers int, largeSize int64) *models.DocumentReport {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil
+	}
+	base = documentBase(doc, base)
+
+	report := &models.DocumentReport{ByType: make(map[string]int)}
+	seen := make(map[string]bool)
+
+	doc.Find("a[href]").Each(func(i int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		resolved, err := resolveURL(base, href)
+		if err != nil || resolved == "" || seen[resolved] {
+			return
+		}
+
+		docType := documentType(resolved)
+		if docType == "" {
+			return
+		}
+		seen[resolved] = true
+
+		report.ByType[docType]++
+		report.Documents = append(report.Documents, models.DocumentLink{
+			URL:  resolved,
+			Type: docType,
+			Text: strings.Join(strings.Fields(s.Text()), " "),
+		})
+	})
+
+	runLimited(ctx, len(report.Documents), maxWorkers, func(i int) {
+		document := &report.Documents[i]
+
+		size, status, err := probeSize(ctx, client, document.URL)
+		document.Size = size
+		document.StatusCode = status
+		if err != nil {
+			document.Error = err.Error()
+			return
+		}
+
+		if largeSize > 0 && size >= largeSize && !sizeHintPattern.MatchString(document.Text) {
+			document.MissingSizeHint = true
+		}
+	})
+
+	for _, document := range report.Documents {
+		report.TotalSize += document.Size
+		if document.MissingSizeHint {
+			report.Flagged++
+		}
+	}
+
+	return report
+}
+
+func documentType(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return documentTypes[strings.ToLower(path.Ext(u.Path))]
+}
+
+// probeSize requests the first byte of a resource and reads the total size
+// from Content-Range, falling back to Content-Length for servers that
+// ignore ranges
+func probeSize(ctx context.Context, client *http.Client, resourceURL string) (int64, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, client.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", resourceURL, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	req.Header.Set("Range", "bytes=0-0")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return 0, resp.StatusCode, fmt.Errorf("HTTP %d: %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	if resp.StatusCode == http.StatusPartialContent {
+		// Content-Range: bytes 0-0/12345
+		if _, total, found := strings.Cut(resp.Header.Get("Content-Range"), "/"); found {
+			if size, err := strconv.ParseInt(strings.TrimSpace(total), 10, 64); err == nil {
+				return size, resp.StatusCode, nil
+			}
+		}
+		return 0, resp.StatusCode, nil
+	}
+
+	// Full response: the body is not read, the size is in the header
+	if resp.ContentLength < 0 {
+		return 0, resp.StatusCode, nil
+	}
+	return resp.ContentLength, resp.StatusCode, nil
+}
@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestInventoryDocuments(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/report.pdf", "/hinted.pdf":
+			if r.Header.Get("Range") != "bytes=0-0" {
+				t.Errorf("Expected range request, got %q", r.Header.Get("Range"))
+			}
+			w.Header().Set("Content-Range", "bytes 0-0/8000000")
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write([]byte("%"))
+		case "/data.xlsx":
+			w.Header().Set("Content-Length", "1200")
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	html := `
+		<html><body>
+			<a href="/report.pdf">Annual report</a>
+			<a href="/hinted.pdf">Brochure (7.6 MB)</a>
+			<a href="/data.xlsx">Data</a>
+			<a href="/missing.zip">Archive</a>
+			<a href="/page.html">Page</a>
+		</body></html>
+	`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	report := InventoryDocuments(context.Background(), doc, ts.URL, &http.Client{Timeout: time.Second}, 2, 5*1024*1024)
+
+	if len(report.Documents) != 4 {
+		t.Fatalf("Expected 4 documents, got %d", len(report.Documents))
+	}
+
+	if report.ByType["pdf"] != 2 || report.ByType["xlsx"] != 1 || report.ByType["zip"] != 1 {
+		t.Errorf("Unexpected type counts: %v", report.ByType)
+	}
+
+	if report.Flagged != 1 || !report.Documents[0].MissingSizeHint {
+		t.Errorf("Expected only the unhinted PDF to be flagged, got %+v", report.Documents)
+	}
+
+	if report.Documents[2].Size != 1200 {
+		t.Errorf("Expected Content-Length fallback size 1200, got %d", report.Documents[2].Size)
+	}
+
+	if report.Documents[3].StatusCode != 404 || report.Documents[3].Error == "" {
+		t.Errorf("Expected missing archive to report 404, got %+v", report.Documents[3])
+	}
+}
@@ -0,0 +1,67 @@
+package analyzer
+
+import (
+	"sort"
+
+	"website-analyzer/internal/models"
+)
+
+// SummarizeDomains aggregates external link results per destination domain.
+// Domains with the most broken links come first so editors can see which
+// references need re-sourcing.
+func SummarizeDomains(statuses []models.LinkStatus) []models.DomainHealth {
+	byDomain := make(map[string]*models.DomainHealth)
+	latencyTotals := make(map[string]int64)
+	measured := make(map[string]int64)
+
+	for _, status := range statuses {
+		if status.Type != models.LinkTypeExternal {
+			continue
+		}
+
+		domain := getDomain(status.URL)
+		if domain == "" {
+			continue
+		}
+
+		health, ok := byDomain[domain]
+		if !ok {
+			health = &models.DomainHealth{Domain: domain}
+			byDomain[domain] = health
+		}
+
+		health.Total++
+		switch {
+		case status.Blocked:
+			health.Blocked++
+		case status.Error != "":
+			health.Broken++
+		}
+
+		if !status.Blocked {
+			latencyTotals[domain] += status.LatencyMs
+			measured[domain]++
+		}
+	}
+
+	summary := make([]models.DomainHealth, 0, len(byDomain))
+	for domain, health := range byDomain {
+		if measured[domain] > 0 {
+			health.AvgLatencyMs = latencyTotals[domain] / measured[domain]
+		}
+		summary = append(summary, *health)
+	}
+
+	sort.Slice(summary, func(i, j int) bool {
+		a, b := summary[i], summary[j]
+		if a.Broken+a.Blocked != b.Broken+b.Blocked {
+			return a.Broken+a.Blocked > b.Broken+b.Blocked
+		}
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return a.Domain < b.Domain
+	})
+
+	return summary
+}
@@ -0,0 +1,33 @@
+package analyzer
+
+import (
+	"testing"
+
+	"website-analyzer/internal/models"
+)
+
+func TestSummarizeDomains(t *testing.T) {
+	statuses := []models.LinkStatus{
+		{URL: "https://ok.com/a", Type: models.LinkTypeExternal, StatusCode: 200, LatencyMs: 100},
+		{URL: "https://ok.com/b", Type: models.LinkTypeExternal, StatusCode: 200, LatencyMs: 300},
+		{URL: "https://bad.com/a", Type: models.LinkTypeExternal, StatusCode: 404, Error: "HTTP 404", LatencyMs: 50},
+		{URL: "https://bad.com/b", Type: models.LinkTypeExternal, Error: "skipped", Blocked: true},
+		{URL: "https://self.com/a", Type: models.LinkTypeInternal, StatusCode: 500, Error: "HTTP 500"},
+	}
+
+	summary := SummarizeDomains(statuses)
+
+	if len(summary) != 2 {
+		t.Fatalf("Expected 2 external domains, got %d", len(summary))
+	}
+
+	bad := summary[0]
+	if bad.Domain != "bad.com" || bad.Total != 2 || bad.Broken != 1 || bad.Blocked != 1 || bad.AvgLatencyMs != 50 {
+		t.Errorf("Unexpected summary for bad.com: %+v", bad)
+	}
+
+	ok := summary[1]
+	if ok.Domain != "ok.com" || ok.Total != 2 || ok.Broken != 0 || ok.AvgLatencyMs != 200 {
+		t.Errorf("Unexpected summary for ok.com: %+v", ok)
+	}
+}
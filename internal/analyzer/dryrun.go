@@ -0,0 +1,224 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"website-analyzer/internal/models"
+	"website-analyzer/internal/validator"
+)
+
+const (
+	// estimatedLinksPerPage stands in for the links of a page that was
+	// never analyzed
+	estimatedLinksPerPage = 60
+	// estimatedPageTime is the wall time assumed for such a page
+	estimatedPageTime = 3 * time.Second
+)
+
+// PlanOptions supplies what a dry run knows without making requests
+type PlanOptions struct {
+	// Previous returns the latest stored analysis of a URL, or nil
+	Previous func(url string) *models.AnalysisResult
+	// Denied returns why a URL may not be analyzed, or ""
+	Denied func(url string) string
+}
+
+// PlanBatch is a dry run of analyzing urls with opts, concurrency at a
+// time: it validates and estimates each URL without contacting any host
+func (a *Analyzer) PlanBatch(ctx context.Context, urls []string, opts AnalyzeOptions, concurrency int, plan PlanOptions) (*models.ScopePlan, error) {
+	profile, err := a.profile(opts.Profile)
+	if err != nil {
+		return nil, err
+	}
+	flags, err := a.flags(opts.Flags)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := a.Fetcher(opts.Fetcher, flags); err != nil {
+		return nil, err
+	}
+
+	result := &models.ScopePlan{Concurrency: concurrency}
+	for _, targetURL := range urls {
+		page := a.planPage(targetURL, profile, plan)
+		if page.Skipped == "" && !opts.Force {
+			// A cached result costs no requests
+			if _, _, ok := a.cachedResult(ctx, resultCacheKey(targetURL, profile, opts, flags)); ok {
+				page.Cached, page.Requests, page.DurationMs = true, 0, 0
+			}
+		}
+		result.Pages = append(result.Pages, page)
+	}
+
+	a.totalPlan(result, profile)
+	return result, nil
+}
+
+// PlanCrawl is a dry run of Crawl. Pages are discovered from the links of
+// their stored analyses; pages whose links aren't known stand in for up to
+// the page limit of unknown pages. robots.txt is not fetched, so pages it
+// disallows are still counted.
+func (a *Analyzer) PlanCrawl(targetURL string, opts CrawlOptions, plan PlanOptions) (*models.ScopePlan, error) {
+	opts = a.crawlDefaults(opts)
+	profile, err := a.profile(opts.Profile)
+	if err != nil {
+		return nil, err
+	}
+	result := &models.ScopePlan{Concurrency: opts.Concurrency}
+	visited := map[string]bool{crawlKey(targetURL): true}
+	frontier := []string{targetURL}
+	unexpanded := false
+
+	for depth := 0; depth <= opts.MaxDepth && len(frontier) > 0; depth++ {
+		if remaining := opts.MaxPages - len(result.Pages); len(frontier) > remaining {
+			frontier = frontier[:remaining]
+		}
+
+		var next []string
+		for _, pageURL := range frontier {
+			page := a.planPage(pageURL, profile, plan)
+			page.Depth = depth
+			result.Pages = append(result.Pages, page)
+			if page.Skipped != "" || depth == opts.MaxDepth {
+				continue
+			}
+
+			previous := plan.previous(pageURL)
+			base, err := url.Parse(pageURL)
+			if previous == nil || previous.Links == nil || err != nil {
+				unexpanded = true
+				continue
+			}
+			// Links are internal to the page they were found on, as when
+			// they were extracted
+			for _, link := range previous.Links {
+				if classifyLink(link, base, a.linkScope(profile)) != models.LinkTypeInternal || !isCrawlable(link) {
+					continue
+				}
+				key := crawlKey(link)
+				if visited[key] {
+					continue
+				}
+				visited[key] = true
+				next = append(next, stripFragment(link))
+			}
+		}
+
+		// The crawl fails when its start page can't be analyzed
+		if depth == 0 && result.Pages[0].Skipped != "" {
+			break
+		}
+		frontier = next
+	}
+
+	if unexpanded && len(result.Pages) < opts.MaxPages {
+		result.UnknownPages = opts.MaxPages - len(result.Pages)
+		result.Notes = append(result.Notes, fmt.Sprintf(
+			"%d page(s) can only be found by fetching pages never analyzed before; estimates assume the crawl reaches its limit of %d pages",
+			result.UnknownPages, opts.MaxPages))
+	}
+	if a.config.RespectRobots {
+		result.Notes = append(result.Notes, "robots.txt is not fetched in a dry run, so pages it disallows are still counted")
+	}
+
+	a.totalPlan(result, profile)
+	return result, nil
+}
+
+// planPage checks targetURL the way an analysis would, short of resolving
+// its host, and estimates what analyzing it costs
+func (a *Analyzer) planPage(targetURL string, profile Profile, plan PlanOptions) models.PlannedPage {
+	page := models.PlannedPage{URL: a.redactor.Text(targetURL)}
+	if err := validator.ValidateSyntax(targetURL, a.config.MaxURLLength); err != nil {
+		page.Skipped = "invalid URL: " + err.Error()
+		return page
+	}
+	if a.optOut.containsURL(targetURL) {
+		page.Skipped = ErrOptedOut.Error()
+		return page
+	}
+	if outOfScope(targetURL) {
+		page.Skipped = validator.ErrOutOfScope.Error()
+		return page
+	}
+	if plan.Denied != nil {
+		if reason := plan.Denied(targetURL); reason != "" {
+			page.Skipped = reason
+			return page
+		}
+	}
+
+	// A stored analysis measured what the page cost last time
+	previous := plan.previous(targetURL)
+	if previous != nil && previous.Usage != nil && previous.Usage.Requests > 0 {
+		page.Requests = previous.Usage.Requests
+		page.DurationMs = previous.Usage.WallTimeMs
+		page.FromHistory = true
+		return page
+	}
+
+	links := estimatedLinksPerPage
+	if previous != nil {
+		links = previous.InternalLinks + previous.ExternalLinks
+		page.FromHistory = true
+	}
+	page.Requests, page.DurationMs = a.estimatePage(links, profile)
+	return page
+}
+
+// estimatePage estimates the requests and wall time of a page with links
+// links: the page itself plus its link checks
+func (a *Analyzer) estimatePage(links int, profile Profile) (requests, durationMs int64) {
+	requests = 1
+	if profile.Enabled(LinksCheck) {
+		if profile.MaxLinks > 0 {
+			links = min(links, profile.MaxLinks)
+		}
+		requests += int64(links)
+	}
+	return requests, estimatedPageTime.Milliseconds()
+}
+
+// totalPlan adds up the pages of result, including its unknown pages, and
+// estimates the wall time with result.Concurrency pages at once
+func (a *Analyzer) totalPlan(result *models.ScopePlan, profile Profile) {
+	var durations []int64
+	for _, page := range result.Pages {
+		if page.Skipped != "" || page.Cached {
+			continue
+		}
+		result.FetchPages++
+		result.Requests += page.Requests
+		durations = append(durations, page.DurationMs)
+	}
+	requests, durationMs := a.estimatePage(estimatedLinksPerPage, profile)
+	for range result.UnknownPages {
+		result.FetchPages++
+		result.Requests += requests
+		durations = append(durations, durationMs)
+	}
+	result.DurationMs = scheduleDuration(durations, result.Concurrency)
+}
+
+// scheduleDuration is how long durations take on workers running in
+// parallel, each taking the next job as it frees up
+func scheduleDuration(durations []int64, workers int) int64 {
+	workers = max(workers, 1)
+	busy := make([]int64, min(workers, max(len(durations), 1)))
+	for _, d := range durations {
+		i := slices.Index(busy, slices.Min(busy))
+		busy[i] += d
+	}
+	return slices.Max(busy)
+}
+
+func (p PlanOptions) previous(url string) *models.AnalysisResult {
+	if p.Previous == nil {
+		return nil
+	}
+	return p.Previous(url)
+}
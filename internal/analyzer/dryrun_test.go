@@ -0,0 +1,162 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+func TestPlanBatch(t *testing.T) {
+	var hits atomic.Int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer ts.Close()
+
+	a := NewAnalyzer(&Config{
+		RequestTimeout: 2 * time.Second,
+		MaxURLLength:   2048,
+		OptOutDomains:  []string{"optout.example"},
+	})
+	known := ts.URL + "/known"
+	plan := PlanOptions{
+		Previous: func(url string) *models.AnalysisResult {
+			if url == known {
+				return &models.AnalysisResult{Usage: &models.ResourceUsage{Requests: 12, WallTimeMs: 800}}
+			}
+			return nil
+		},
+		Denied: func(url string) string {
+			if url == "https://denied.example/" {
+				return "denied.example is on this service's do-not-analyze list"
+			}
+			return ""
+		},
+	}
+
+	urls := []string{known, ts.URL + "/new", "ftp://example.com/", "https://optout.example/", "https://denied.example/"}
+	result, err := a.PlanBatch(context.Background(), urls, AnalyzeOptions{Profile: "quick"}, 4, plan)
+	if err != nil {
+		t.Fatalf("PlanBatch failed: %v", err)
+	}
+	if hits.Load() != 0 {
+		t.Fatalf("Expected no requests, server saw %d", hits.Load())
+	}
+	if len(result.Pages) != len(urls) {
+		t.Fatalf("Expected every URL in the plan, got %+v", result.Pages)
+	}
+
+	if page := result.Pages[0]; !page.FromHistory || page.Requests != 12 || page.DurationMs != 800 {
+		t.Errorf("Expected the stored usage as the estimate, got %+v", page)
+	}
+	// The quick profile checks at most 25 links
+	if page := result.Pages[1]; page.FromHistory || page.Requests != 26 || page.DurationMs != estimatedPageTime.Milliseconds() {
+		t.Errorf("Expected the default estimate, got %+v", page)
+	}
+	for _, page := range result.Pages[2:] {
+		if page.Skipped == "" || page.Requests != 0 {
+			t.Errorf("Expected %s to be skipped, got %+v", page.URL, page)
+		}
+	}
+	if result.FetchPages != 2 || result.Requests != 38 || result.DurationMs != estimatedPageTime.Milliseconds() {
+		t.Errorf("Unexpected totals %+v", result)
+	}
+
+	if _, err := a.PlanBatch(context.Background(), urls, AnalyzeOptions{Profile: "missing"}, 4, plan); err == nil {
+		t.Error("Expected an unknown profile to be rejected")
+	}
+}
+
+func TestPlanBatchCached(t *testing.T) {
+	a := NewAnalyzer(&Config{MaxURLLength: 2048, CacheTTL: time.Hour})
+	profile, _ := a.profile("")
+	a.cacheResult(context.Background(), resultCacheKey("https://example.com/", profile, AnalyzeOptions{}, nil), &models.AnalysisResult{Title: "Cached"})
+
+	result, err := a.PlanBatch(context.Background(), []string{"https://example.com/"}, AnalyzeOptions{}, 4, PlanOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Pages[0].Cached || result.FetchPages != 0 || result.Requests != 0 {
+		t.Errorf("Expected the cached page to cost nothing, got %+v", result)
+	}
+
+	result, _ = a.PlanBatch(context.Background(), []string{"https://example.com/"}, AnalyzeOptions{Force: true}, 4, PlanOptions{})
+	if result.Pages[0].Cached || result.FetchPages != 1 {
+		t.Errorf("Expected a forced analysis to bypass the cache, got %+v", result)
+	}
+}
+
+func TestPlanCrawl(t *testing.T) {
+	a := NewAnalyzer(&Config{MaxURLLength: 2048})
+	history := map[string]*models.AnalysisResult{
+		"https://example.com/": {
+			Links: []string{"https://example.com/a", "https://example.com/b#top", "https://other.example/", "https://example.com/report.pdf"},
+			Usage: &models.ResourceUsage{Requests: 5, WallTimeMs: 1000},
+		},
+		"https://example.com/a": {Links: []string{"https://example.com/", "https://example.com/c"}, InternalLinks: 2},
+	}
+	plan := PlanOptions{Previous: func(url string) *models.AnalysisResult { return history[url] }}
+
+	result, err := a.PlanCrawl("https://example.com/", CrawlOptions{MaxDepth: 2, MaxPages: 10, Concurrency: 2}, plan)
+	if err != nil {
+		t.Fatalf("PlanCrawl failed: %v", err)
+	}
+	var urls []string
+	for _, page := range result.Pages {
+		urls = append(urls, page.URL)
+	}
+	want := []string{"https://example.com/", "https://example.com/a", "https://example.com/b", "https://example.com/c"}
+	if len(urls) != len(want) {
+		t.Fatalf("Pages = %v, want %v", urls, want)
+	}
+	for i := range want {
+		if urls[i] != want[i] {
+			t.Errorf("Pages = %v, want %v", urls, want)
+			break
+		}
+	}
+	if result.Pages[3].Depth != 2 {
+		t.Errorf("Expected /c at depth 2, got %+v", result.Pages[3])
+	}
+	// /b was never analyzed, so its links are unknown
+	if result.UnknownPages != 6 || result.FetchPages != 10 || len(result.Notes) == 0 {
+		t.Errorf("Expected the remaining page budget to be estimated, got %+v", result)
+	}
+
+	// Fully known scopes need no unknown pages
+	history["https://example.com/b"] = &models.AnalysisResult{Links: []string{}}
+	history["https://example.com/c"] = &models.AnalysisResult{Links: []string{}}
+	result, _ = a.PlanCrawl("https://example.com/", CrawlOptions{MaxDepth: 2, MaxPages: 10}, plan)
+	if result.UnknownPages != 0 || result.FetchPages != 4 {
+		t.Errorf("Expected a fully known crawl of four pages, got %+v", result)
+	}
+
+	result, _ = a.PlanCrawl("ftp://example.com/", CrawlOptions{}, plan)
+	if len(result.Pages) != 1 || result.Pages[0].Skipped == "" || result.FetchPages != 0 {
+		t.Errorf("Expected an invalid start page to end the plan, got %+v", result)
+	}
+}
+
+func TestScheduleDuration(t *testing.T) {
+	tests := []struct {
+		durations []int64
+		workers   int
+		want      int64
+	}{
+		{nil, 4, 0},
+		{[]int64{5, 5, 5, 5}, 4, 5},
+		{[]int64{5, 5, 5, 5}, 2, 10},
+		{[]int64{9, 1, 1, 1}, 2, 9},
+		{[]int64{3, 3}, 0, 6},
+	}
+	for _, tt := range tests {
+		if got := scheduleDuration(tt.durations, tt.workers); got != tt.want {
+			t.Errorf("scheduleDuration(%v, %d) = %d, want %d", tt.durations, tt.workers, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"website-analyzer/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Feed types
+const (
+	FeedTypeRSS        = "rss"
+	FeedTypeAtom       = "atom"
+	FeedTypeJSON       = "json"
+	FeedTypeOpenSearch = "opensearch"
+)
+
+const (
+	// staleFeedAge flags feeds whose newest entry is older than this
+	staleFeedAge = 180 * 24 * time.Hour
+	maxFeedSize  = 5 * 1024 * 1024
+)
+
+// feedLinkTypes maps advertised MIME types to feed types
+var feedLinkTypes = map[string]string{
+	"application/rss+xml":                   FeedTypeRSS,
+	"application/atom+xml":                  FeedTypeAtom,
+	"application/feed+json":                 FeedTypeJSON,
+	"application/opensearchdescription+xml": FeedTypeOpenSearch,
+}
+
+// feedDateLayouts covers RFC 822/1123 variants seen in RSS plus RFC 3339
+var feedDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC822Z,
+	time.RFC822,
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02",
+}
+
+type rssDocument struct {
+	Channel struct {
+		Title   string `xml:"title"`
+		LastBld string `xml:"lastBuildDate"`
+		Items   []struct {
+			PubDate string `xml:"pubDate"`
+			Date    string `xml:"http://purl.org/dc/elements/1.1/ date"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+type atomDocument struct {
+	Title   string `xml:"title"`
+	Updated string `xml:"updated"`
+	Entries []struct {
+		Updated   string `xml:"updated"`
+		Published string `xml:"published"`
+	} `xml:"entry"`
+}
+
+type jsonFeedDocument struct {
+	Version string `json:"version"`
+	Title   string `json:"title"`
+	Items   []struct {
+		DatePublished string `json:"date_published"`
+		DateModified  string `json:"date_modified"`
+	} `json:"items"`
+}
+
+type openSearchDocument struct {
+	ShortName string `xml:"ShortName"`
+	URLs      []struct {
+		Type     string `xml:"type,attr"`
+		Template string `xml:"template,attr"`
+	} `xml:"Url"`
+}
+
+// CheckFeeds finds advertised RSS/Atom/JSON feeds and OpenSearch
+// descriptions, fetches them and reports validity, entry counts and
+// staleness. It returns nil when the page advertises none.
+func CheckFeeds(ctx context.Context, doc *goquery.Document, baseURL string, client *http.Client, maxWorkers int) *models.FeedReport {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil
+	}
+	base = documentBase(doc, base)
+
+	var feeds []models.Feed
+	seen := make(map[string]bool)
+
+	doc.Find(`link[rel~="alternate"][type], link[rel~="search"][type]`).Each(func(i int, s *goquery.Selection) {
+		linkType, _ := s.Attr("type")
+		feedType, ok := feedLinkTypes[strings.ToLower(strings.TrimSpace(linkType))]
+		if !ok {
+			return
+		}
+
+		href, _ := s.Attr("href")
+		resolved, err := resolveURL(base, href)
+		if err != nil || resolved == "" || seen[resolved] {
+			return
+		}
+		seen[resolved] = true
+
+		title, _ := s.Attr("title")
+		feeds = append(feeds, models.Feed{URL: resolved, Type: feedType, Title: title})
+	})
+
+	if len(feeds) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	runLimited(ctx, len(feeds), maxWorkers, func(i int) {
+		feed := &feeds[i]
+
+		body, _, err := fetchBody(ctx, client, feed.URL, maxFeedSize)
+		if err != nil {
+			feed.Error = err.Error()
+			return
+		}
+
+		if err := parseFeed(feed, body); err != nil {
+			feed.Error = err.Error()
+			return
+		}
+		feed.Valid = true
+
+		if feed.LastPublished != nil && now.Sub(*feed.LastPublished) > staleFeedAge {
+			feed.Stale = true
+		}
+	})
+
+	return &models.FeedReport{Feeds: feeds}
+}
+
+// parseFeed fills in entry count and last publication date for the feed
+func parseFeed(feed *models.Feed, body []byte) error {
+	switch feed.Type {
+	case FeedTypeJSON:
+		var doc jsonFeedDocument
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return fmt.Errorf("invalid JSON feed: %w", err)
+		}
+		if !strings.Contains(doc.Version, "jsonfeed.org") {
+			return fmt.Errorf("missing JSON Feed version")
+		}
+		feed.EntryCount = len(doc.Items)
+		for _, item := range doc.Items {
+			feed.LastPublished = latest(feed.LastPublished, item.DatePublished, item.DateModified)
+		}
+		return nil
+	case FeedTypeOpenSearch:
+		var doc openSearchDocument
+		if err := xml.Unmarshal(body, &doc); err != nil {
+			return fmt.Errorf("invalid XML: %w", err)
+		}
+		if doc.ShortName == "" {
+			return fmt.Errorf("OpenSearch description has no ShortName")
+		}
+		for _, u := range doc.URLs {
+			if strings.Contains(u.Template, "{searchTerms}") {
+				return nil
+			}
+		}
+		return fmt.Errorf("OpenSearch description has no Url template with {searchTerms}")
+	}
+
+	// RSS and Atom are told apart by their root element since sites often
+	// advertise the wrong MIME type
+	root, err := rootElement(body)
+	if err != nil {
+		return err
+	}
+
+	switch root {
+	case "rss", "RDF":
+		var doc rssDocument
+		if err := xml.Unmarshal(body, &doc); err != nil {
+			return fmt.Errorf("invalid XML: %w", err)
+		}
+		feed.Type = FeedTypeRSS
+		feed.EntryCount = len(doc.Channel.Items)
+		for _, item := range doc.Channel.Items {
+			feed.LastPublished = latest(feed.LastPublished, item.PubDate, item.Date)
+		}
+	case "feed":
+		var doc atomDocument
+		if err := xml.Unmarshal(body, &doc); err != nil {
+			return fmt.Errorf("invalid XML: %w", err)
+		}
+		feed.Type = FeedTypeAtom
+		feed.EntryCount = len(doc.Entries)
+		for _, entry := range doc.Entries {
+			feed.LastPublished = latest(feed.LastPublished, entry.Published, entry.Updated)
+		}
+	default:
+		return fmt.Errorf("unexpected feed root element <%s>", root)
+	}
+
+	return nil
+}
+
+// latest returns the most recent of current and the parsable dates
+func latest(current *time.Time, dates ...string) *time.Time {
+	for _, value := range dates {
+		t, ok := parseFeedDate(value)
+		if ok && (current == nil || t.After(*current)) {
+			current = &t
+		}
+	}
+	return current
+}
+
+func parseFeedDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range feedDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
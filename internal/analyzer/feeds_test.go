@@ -0,0 +1,80 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestCheckFeeds(t *testing.T) {
+	recent := time.Now().Add(-48 * time.Hour)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rss.xml":
+			_, _ = w.Write([]byte(`<?xml version="1.0"?><rss version="2.0"><channel><title>News</title>
+				<item><pubDate>` + recent.Format(time.RFC1123Z) + `</pubDate></item>
+				<item><pubDate>Mon, 02 Jan 2006 15:04:05 -0700</pubDate></item>
+			</channel></rss>`))
+		case "/atom.xml":
+			_, _ = w.Write([]byte(`<feed xmlns="http://www.w3.org/2005/Atom"><title>Old</title>
+				<entry><updated>2019-05-01T10:00:00Z</updated></entry>
+			</feed>`))
+		case "/opensearch.xml":
+			_, _ = w.Write([]byte(`<OpenSearchDescription xmlns="http://a9.com/-/spec/opensearch/1.1/">
+				<ShortName>Site</ShortName>
+				<Url type="text/html" template="https://example.com/search?q={searchTerms}"/>
+			</OpenSearchDescription>`))
+		case "/broken.xml":
+			_, _ = w.Write([]byte(`<rss><channel><item>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	html := `
+		<html><head>
+			<link rel="alternate" type="application/rss+xml" title="News" href="/rss.xml">
+			<link rel="alternate" type="application/rss+xml" href="/atom.xml">
+			<link rel="search" type="application/opensearchdescription+xml" href="/opensearch.xml">
+			<link rel="alternate" type="application/rss+xml" href="/broken.xml">
+			<link rel="alternate" type="application/atom+xml" href="/missing.xml">
+		</head></html>
+	`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	report := CheckFeeds(context.Background(), doc, ts.URL, &http.Client{Timeout: time.Second}, 3)
+	if report == nil || len(report.Feeds) != 5 {
+		t.Fatalf("Expected 5 feeds, got %+v", report)
+	}
+
+	rss := report.Feeds[0]
+	if !rss.Valid || rss.EntryCount != 2 || rss.Stale || rss.LastPublished == nil {
+		t.Errorf("Unexpected RSS result: %+v", rss)
+	}
+
+	atom := report.Feeds[1]
+	if !atom.Valid || atom.Type != FeedTypeAtom || !atom.Stale {
+		t.Errorf("Expected stale Atom feed detected by root element, got %+v", atom)
+	}
+
+	if !report.Feeds[2].Valid {
+		t.Errorf("Expected valid OpenSearch description, got %+v", report.Feeds[2])
+	}
+
+	for _, feed := range report.Feeds[3:] {
+		if feed.Valid || feed.Error == "" {
+			t.Errorf("Expected %s to be reported broken, got %+v", feed.URL, feed)
+		}
+	}
+}
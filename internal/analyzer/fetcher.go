@@ -0,0 +1,242 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+// Fetcher names select how the analyzed page is obtained
+const (
+	FetcherHTTP    = "http"
+	FetcherBrowser = "browser"
+	FetcherCached  = "cached"
+	FetcherHAR     = "har"
+	FetcherWARC    = "warc"
+)
+
+// defaultFetchCacheTTL is how long the cached fetcher keeps pages when no
+// CacheTTL is configured
+const defaultFetchCacheTTL = 5 * time.Minute
+
+// ErrInvalidFetcher is returned for requests naming an unknown fetcher or
+// one the deployment's feature flags don't allow
+var ErrInvalidFetcher = errors.New("invalid fetcher")
+
+// Fetcher obtains the HTML of the page being analyzed. Link and resource
+// checks still go over the network.
+type Fetcher interface {
+	Fetch(ctx context.Context, pageURL string) (*FetchedPage, error)
+}
+
+// OfflineFetcher is implemented by fetchers that may answer without
+// contacting the page's host, such as recordings; when Offline reports
+// true the page's address isn't resolved for the SSRF checks
+type OfflineFetcher interface {
+	Offline() bool
+}
+
+// FetchedPage is a page as returned by a Fetcher
+type FetchedPage struct {
+	// Body is the HTML the checks see
+	Body []byte `json:"body"`
+	// Size is the number of bytes downloaded, which differs from Body for
+	// rendered pages
+	Size   int64       `json:"size"`
+	Header http.Header `json:"header,omitempty"`
+	// Timing breaks down a fetch over the network; nil when the page
+	// didn't come from one
+	Timing *models.ResponseTiming `json:"timing,omitempty"`
+	// Proto is the protocol the page was served over, such as "HTTP/1.1"
+	// or "HTTP/2.0"; empty when unknown
+	Proto string `json:"proto,omitempty"`
+	// TLS reports that the page was served over TLS, where HTTP/2 is
+	// offered during the handshake
+	TLS bool `json:"tls,omitempty"`
+	// Redirects are the responses from the requested URL to the page,
+	// ending with the page's own; nil when it wasn't redirected
+	Redirects []models.RedirectHop `json:"redirects,omitempty"`
+}
+
+// Fetcher returns the fetcher called name, or the default one for flags
+// when name is empty: DefaultFetcher, else the browser when the rendered
+// flag is on and plain HTTP otherwise. The browser needs the rendered
+// flag however it is selected.
+func (a *Analyzer) Fetcher(name string, flags Flags) (Fetcher, error) {
+	if name == "" {
+		name = a.config.DefaultFetcher
+	}
+	if name == "" {
+		name = FetcherHTTP
+		if flags.Enabled(FlagRendered) {
+			name = FetcherBrowser
+		}
+	}
+	if name == FetcherBrowser && !flags.Enabled(FlagRendered) {
+		return nil, fmt.Errorf("%w: %q needs the %s feature flag", ErrInvalidFetcher, name, FlagRendered)
+	}
+	f, ok := a.fetchers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown fetcher %q", ErrInvalidFetcher, name)
+	}
+	return f, nil
+}
+
+// Fetchers lists the names requests may select a fetcher by
+func (a *Analyzer) Fetchers() []string {
+	return slices.Sorted(maps.Keys(a.fetchers))
+}
+
+// newFetchers returns the built-in fetchers with the configured ones added
+// or replacing them
+func (a *Analyzer) newFetchers() map[string]Fetcher {
+	plain := httpFetcher{client: a.httpClient, maxSize: a.config.MaxResponseSize}
+	ttl := a.config.CacheTTL
+	if ttl <= 0 {
+		ttl = defaultFetchCacheTTL
+	}
+	fetchers := map[string]Fetcher{
+		FetcherHTTP:    plain,
+		FetcherBrowser: browserFetcher{page: plain, render: a.renderBrowser},
+		FetcherCached:  &cachedFetcher{next: plain, cache: newMemoryCache(ttl)},
+	}
+	maps.Copy(fetchers, a.config.Fetchers)
+	return fetchers
+}
+
+// offline reports whether f answers without contacting the page's host
+func offline(f Fetcher) bool {
+	o, ok := f.(OfflineFetcher)
+	return ok && o.Offline()
+}
+
+// httpFetcher GETs the page, requiring a 200 response
+type httpFetcher struct {
+	client  *http.Client
+	maxSize int64
+}
+
+func (f httpFetcher) Fetch(ctx context.Context, pageURL string) (*FetchedPage, error) {
+	ctx, trace := withFetchTrace(ctx)
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each hop of a redirect chain is recorded as it is followed
+	var hops []models.RedirectHop
+	hopStart := time.Now()
+	client := *f.client
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		now := time.Now()
+		hops = append(hops, models.RedirectHop{
+			URL:        via[len(via)-1].URL.String(),
+			StatusCode: req.Response.StatusCode,
+			LatencyMs:  now.Sub(hopStart).Milliseconds(),
+		})
+		hopStart = now
+		if err := redirectLoop(hops, req.URL.String()); err != nil {
+			return err
+		}
+		if f.client.CheckRedirect != nil {
+			return f.client.CheckRedirect(req, via)
+		}
+		return nil
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch URL: %w", err)
+	}
+	defer resp.Body.Close()
+	if hops != nil {
+		hops = append(hops, models.RedirectHop{
+			URL:        resp.Request.URL.String(),
+			StatusCode: resp.StatusCode,
+			LatencyMs:  time.Since(hopStart).Milliseconds(),
+		})
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	// Limit response size
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.maxSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	// The transport decompresses gzip itself and drops the header; keep
+	// it so the headers describe what the server sent
+	header := resp.Header
+	if resp.Uncompressed {
+		header = header.Clone()
+		header.Set("Content-Encoding", "gzip")
+	}
+	return &FetchedPage{
+		Body:      body,
+		Size:      int64(len(body)),
+		Header:    header,
+		Timing:    trace.timing(),
+		Proto:     resp.Proto,
+		TLS:       resp.TLS != nil,
+		Redirects: hops,
+	}, nil
+}
+
+// browserFetcher fetches the page over HTTP, which still supplies the
+// status and document size, and replaces its body with the DOM rendered
+// after scripts have run
+type browserFetcher struct {
+	page   Fetcher
+	render func(ctx context.Context, pageURL string) (string, error)
+}
+
+func (f browserFetcher) Fetch(ctx context.Context, pageURL string) (*FetchedPage, error) {
+	page, err := f.page.Fetch(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := f.render(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+	page.Body = []byte(rendered)
+	return page, nil
+}
+
+// cachedFetcher reuses pages fetched within the cache's TTL, so repeated
+// analyses with different profiles or options download a page once
+type cachedFetcher struct {
+	next  Fetcher
+	cache *memoryCache
+}
+
+func (f *cachedFetcher) Fetch(ctx context.Context, pageURL string) (*FetchedPage, error) {
+	key := normalizeURL(pageURL)
+	if encoded, ok := f.cache.Get(ctx, key); ok {
+		var page FetchedPage
+		if err := json.Unmarshal(encoded, &page); err == nil {
+			// The original fetch's timing says nothing about this one
+			page.Timing = nil
+			return &page, nil
+		}
+	}
+
+	page, err := f.next.Fetch(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+	if encoded, err := json.Marshal(page); err == nil {
+		f.cache.Set(ctx, key, encoded)
+	}
+	return page, nil
+}
@@ -0,0 +1,100 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// recordedFetcher answers every URL with the same page without any
+// network access
+type recordedFetcher struct {
+	body  string
+	calls atomic.Int32
+}
+
+func (f *recordedFetcher) Fetch(ctx context.Context, pageURL string) (*FetchedPage, error) {
+	f.calls.Add(1)
+	header := http.Header{"Content-Type": {"text/html"}}
+	return &FetchedPage{Body: []byte(f.body), Size: int64(len(f.body)), Header: header}, nil
+}
+
+func (f *recordedFetcher) Offline() bool {
+	return true
+}
+
+func TestAnalyzeWithInjectedFetcher(t *testing.T) {
+	recorded := &recordedFetcher{body: `<html><head><title>Recorded</title></head><body><h1>Hi</h1><form><input type="password"></form></body></html>`}
+	a := NewAnalyzer(&Config{
+		RequestTimeout:  5 * time.Second,
+		LinkTimeout:     time.Second,
+		MaxWorkers:      2,
+		MaxResponseSize: 1024 * 1024,
+		MaxURLLength:    2048,
+		Fetchers:        map[string]Fetcher{"recorded": recorded},
+		DefaultFetcher:  "recorded",
+	})
+
+	// The host doesn't resolve; an offline fetcher skips the lookup
+	result, err := a.Analyze(context.Background(), "https://recorded.invalid/page")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if result.Title != "Recorded" || !result.HasLoginForm {
+		t.Errorf("Expected the recorded page, got title %q", result.Title)
+	}
+	if recorded.calls.Load() != 1 {
+		t.Errorf("Expected one fetch, got %d", recorded.calls.Load())
+	}
+}
+
+func TestFetcherSelection(t *testing.T) {
+	a := NewAnalyzer(&Config{RequestTimeout: time.Second, MaxResponseSize: 1024})
+	if got := a.Fetchers(); strings.Join(got, ",") != "browser,cached,http" {
+		t.Errorf("Fetchers() = %v", got)
+	}
+
+	f, err := a.Fetcher("", a.config.Flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := f.(httpFetcher); !ok {
+		t.Errorf("Expected plain HTTP by default, got %T", f)
+	}
+	if _, err := a.Fetcher(FetcherBrowser, a.config.Flags); !errors.Is(err, ErrInvalidFetcher) {
+		t.Errorf("Expected the browser to need the rendered flag, got %v", err)
+	}
+	if _, err := a.Fetcher("ftp", a.config.Flags); !errors.Is(err, ErrInvalidFetcher) {
+		t.Errorf("Expected an unknown fetcher to be rejected, got %v", err)
+	}
+
+	f, err = a.Fetcher("", Flags{FlagRendered: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := f.(browserFetcher); !ok {
+		t.Errorf("Expected the browser with the rendered flag, got %T", f)
+	}
+}
+
+func TestCachedFetcher(t *testing.T) {
+	recorded := &recordedFetcher{body: "<html></html>"}
+	f := &cachedFetcher{next: recorded, cache: newMemoryCache(time.Minute)}
+
+	for _, pageURL := range []string{"https://example.com/a", "https://EXAMPLE.com/a/", "https://example.com/b"} {
+		page, err := f.Fetch(context.Background(), pageURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(page.Body) != "<html></html>" || page.Header.Get("Content-Type") != "text/html" {
+			t.Errorf("Unexpected page %+v", page)
+		}
+	}
+	if got := recorded.calls.Load(); got != 2 {
+		t.Errorf("Expected 2 fetches, got %d", got)
+	}
+}
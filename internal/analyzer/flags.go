@@ -0,0 +1,92 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Feature flags gating experimental analyzers, so they can ship dark and
+// be enabled per deployment or per request
+const (
+	// FlagRendered analyzes the DOM rendered by headless Chrome
+	FlagRendered = "rendered"
+	// FlagDeepResources lets deep mode profiles fetch images and other
+	// referenced resources
+	FlagDeepResources = "deep_resources"
+	// FlagSimHash fingerprints the page text to spot near-duplicates
+	FlagSimHash = "simhash"
+)
+
+// AllFlags lists every feature flag
+var AllFlags = []string{FlagRendered, FlagDeepResources, FlagSimHash}
+
+// ErrInvalidFlags is returned for requests naming unknown feature flags or
+// flags the deployment doesn't let requests change
+var ErrInvalidFlags = errors.New("invalid feature flags")
+
+// Flags are the feature flags that are switched on
+type Flags map[string]bool
+
+// DefaultFlags returns the flags of a deployment that configures none:
+// deep resource fetches are on and rendering follows RenderMode
+func DefaultFlags(cfg *Config) Flags {
+	return Flags{FlagRendered: cfg.RenderMode == RenderBrowser, FlagDeepResources: true}
+}
+
+// Enabled reports whether flag is switched on
+func (f Flags) Enabled(flag string) bool {
+	return f[flag]
+}
+
+// ParseFlags applies a comma-separated list of flags to base: "simhash"
+// switches a flag on, "-simhash" off. base is left unchanged.
+func ParseFlags(spec string, base Flags) (Flags, error) {
+	flags := make(Flags, len(AllFlags))
+	for flag, on := range base {
+		flags[flag] = on
+	}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		flag, off := strings.CutPrefix(item, "-")
+		if !slices.Contains(AllFlags, flag) {
+			return nil, fmt.Errorf("unknown feature flag %q, expected one of %s", flag, strings.Join(AllFlags, ", "))
+		}
+		flags[flag] = !off
+	}
+	return flags, nil
+}
+
+// flags returns the deployment's flags with a request's overrides applied.
+// Requests may only change the flags listed in Config.RequestFlags.
+func (a *Analyzer) flags(spec string) (Flags, error) {
+	if strings.TrimSpace(spec) == "" {
+		return a.config.Flags, nil
+	}
+	flags, err := ParseFlags(spec, a.config.Flags)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFlags, err)
+	}
+	for _, flag := range AllFlags {
+		if flags[flag] != a.config.Flags[flag] && !slices.Contains(a.config.RequestFlags, flag) {
+			return nil, fmt.Errorf("%w: feature flag %q can't be changed per request", ErrInvalidFlags, flag)
+		}
+	}
+	return flags, nil
+}
+
+// names lists the flags that are switched on, sorted
+func (f Flags) names() []string {
+	var names []string
+	for flag, on := range f {
+		if on {
+			names = append(names, flag)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
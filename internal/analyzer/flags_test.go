@@ -0,0 +1,76 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParseFlags(t *testing.T) {
+	base := Flags{FlagDeepResources: true}
+
+	flags, err := ParseFlags(" SimHash, -deep_resources ", base)
+	if err != nil {
+		t.Fatalf("ParseFlags failed: %v", err)
+	}
+	if !flags.Enabled(FlagSimHash) || flags.Enabled(FlagDeepResources) || flags.Enabled(FlagRendered) {
+		t.Errorf("Unexpected flags %v", flags)
+	}
+	if !base.Enabled(FlagDeepResources) {
+		t.Error("Expected the base flags to be left unchanged")
+	}
+
+	if _, err := ParseFlags("telepathy", nil); err == nil {
+		t.Error("Expected an unknown flag to be rejected")
+	}
+}
+
+func TestAnalyzeRequestFlags(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>Flags</title></head><body><p>Some words to fingerprint here</p></body></html>`))
+	}))
+	defer ts.Close()
+
+	a := NewAnalyzer(&Config{
+		RequestTimeout:  2 * time.Second,
+		LinkTimeout:     time.Second,
+		MaxResponseSize: 1024 * 1024,
+		MaxURLLength:    2048,
+		MaxRedirects:    5,
+		Profiles:        map[string]Profile{"seo": {Name: "seo", Checks: []Check{SEOCheck}}},
+		DefaultProfile:  "seo",
+		RequestFlags:    []string{FlagSimHash},
+	})
+
+	dark, err := a.AnalyzeWithOptions(context.Background(), ts.URL, AnalyzeOptions{})
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if dark.SimHash != "" {
+		t.Errorf("Expected the simhash to stay dark by default, got %q", dark.SimHash)
+	}
+
+	enabled, err := a.AnalyzeWithOptions(context.Background(), ts.URL, AnalyzeOptions{Flags: "simhash"})
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if enabled.SimHash == "" {
+		t.Error("Expected the request to switch the simhash on")
+	}
+
+	if _, err := a.AnalyzeWithOptions(context.Background(), ts.URL, AnalyzeOptions{Flags: "rendered"}); !errors.Is(err, ErrInvalidFlags) {
+		t.Errorf("Expected a flag requests may not change to be rejected, got %v", err)
+	}
+	// Repeating the deployment's setting changes nothing
+	if _, err := a.AnalyzeWithOptions(context.Background(), ts.URL, AnalyzeOptions{Flags: "deep_resources"}); err != nil {
+		t.Errorf("Expected an unchanged flag to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package analyzer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"website-analyzer/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Keyboard navigation rule identifiers
+const (
+	RuleMissingSkipLink    = "skip-link-missing"
+	RuleBrokenSkipLink     = "skip-link-broken-target"
+	RuleFocusOutlineRemove = "focus-outline-removed"
+	focusableSelector      = "a[href], area[href], button, input, select, textarea, iframe, [tabindex], [contenteditable]"
+)
+
+// focusReplacementProps are properties that can provide an alternative focus
+// indicator when the outline is removed
+var focusReplacementProps = toSet("box-shadow", "border", "border-color", "border-bottom", "background", "background-color", "text-decoration")
+
+// checkSkipLink verifies that the first focusable element is an in-page link
+// to existing content
+func checkSkipLink(doc *goquery.Document) (bool, []models.AccessibilityIssue) {
+	first := firstFocusable(doc)
+	if first == nil {
+		return false, nil
+	}
+
+	href, _ := first.Attr("href")
+	if goquery.NodeName(first) != "a" || !strings.HasPrefix(href, "#") || len(href) < 2 {
+		return false, []models.AccessibilityIssue{newIssue(RuleMissingSkipLink, first,
+			"the first focusable element is not a skip-to-content link")}
+	}
+
+	target := href[1:]
+	if doc.Find("#"+escapeID(target)).Length() == 0 && doc.Find(`a[name="`+target+`"]`).Length() == 0 {
+		return true, []models.AccessibilityIssue{newIssue(RuleBrokenSkipLink, first,
+			fmt.Sprintf("skip link points to missing target %q", href))}
+	}
+
+	return true, nil
+}
+
+func firstFocusable(doc *goquery.Document) *goquery.Selection {
+	var first *goquery.Selection
+	doc.Find("body").Find(focusableSelector).EachWithBreak(func(i int, s *goquery.Selection) bool {
+		if isFocusable(s) {
+			first = s
+			return false
+		}
+		return true
+	})
+	return first
+}
+
+func isFocusable(s *goquery.Selection) bool {
+	if _, disabled := s.Attr("disabled"); disabled || isHiddenFromAT(s) {
+		return false
+	}
+	if inputType, _ := s.Attr("type"); goquery.NodeName(s) == "input" && strings.EqualFold(inputType, "hidden") {
+		return false
+	}
+	if value, ok := s.Attr("tabindex"); ok {
+		if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && n < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// checkFocusOutline flags CSS rules that remove the focus outline without
+// providing another visible indicator
+func checkFocusOutline(doc *goquery.Document) []models.AccessibilityIssue {
+	var rules []cssRule
+	doc.Find("style").Each(func(i int, s *goquery.Selection) {
+		rules = append(rules, parseCSS(s.Text())...)
+	})
+
+	// A :focus-visible rule with a visible indicator makes removing the
+	// outline for mouse focus acceptable
+	hasFocusVisibleStyle := false
+	for _, rule := range rules {
+		if strings.Contains(rule.selector, ":focus-visible") && !strings.Contains(rule.selector, ":not(") &&
+			!removesOutline(rule.declarations) && providesIndicator(rule.declarations) {
+			hasFocusVisibleStyle = true
+		}
+	}
+
+	var issues []models.AccessibilityIssue
+	for _, rule := range rules {
+		if !removesOutline(rule.declarations) || !affectsFocus(rule.selector) {
+			continue
+		}
+		if hasReplacement(rule.declarations) || hasFocusVisibleStyle {
+			continue
+		}
+
+		issues = append(issues, models.AccessibilityIssue{
+			Rule:    RuleFocusOutlineRemove,
+			Element: truncate(rule.selector, maxElementDescLength),
+			Message: "focus outline is removed without a replacement indicator",
+		})
+	}
+
+	return issues
+}
+
+// affectsFocus reports whether a selector applies in the focused state
+func affectsFocus(selector string) bool {
+	for _, part := range strings.Split(selector, ",") {
+		part = strings.TrimSpace(part)
+		if strings.Contains(part, ":focus") && !strings.Contains(part, ":not(:focus-visible)") {
+			return true
+		}
+		if part == "*" || part == "a" || part == "button" || part == "input" {
+			return true
+		}
+	}
+	return false
+}
+
+func removesOutline(decls []cssDeclaration) bool {
+	for _, d := range decls {
+		value := strings.ToLower(d.value)
+		switch d.property {
+		case "outline":
+			if value == "none" || value == "0" || strings.HasPrefix(value, "0 ") || strings.Contains(value, "none") {
+				return true
+			}
+		case "outline-style":
+			if value == "none" {
+				return true
+			}
+		case "outline-width":
+			if value == "0" || value == "0px" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasReplacement(decls []cssDeclaration) bool {
+	for _, d := range decls {
+		if focusReplacementProps[d.property] && !strings.EqualFold(d.value, "none") {
+			return true
+		}
+	}
+	return false
+}
+
+func providesIndicator(decls []cssDeclaration) bool {
+	for _, d := range decls {
+		if strings.HasPrefix(d.property, "outline") || hasReplacement([]cssDeclaration{d}) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestCheckSkipLink(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected bool
+		issues   int
+	}{
+		{
+			name:     "Skip link first",
+			html:     `<body><a href="#main" class="skip">Skip to content</a><nav><a href="/">Home</a></nav><main id="main"></main></body>`,
+			expected: true,
+		},
+		{
+			name:     "Skip link with missing target",
+			html:     `<body><a href="#content">Skip</a><main id="main"></main></body>`,
+			expected: true,
+			issues:   1,
+		},
+		{
+			name:   "Navigation first",
+			html:   `<body><input type="hidden" name="csrf"><nav><a href="/">Home</a></nav><a href="#main">Skip</a></body>`,
+			issues: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+
+			found, issues := checkSkipLink(doc)
+			if found != tt.expected || len(issues) != tt.issues {
+				t.Errorf("Expected skip link %v with %d issues, got %v with %v", tt.expected, tt.issues, found, issues)
+			}
+		})
+	}
+}
+
+func TestCheckFocusOutline(t *testing.T) {
+	tests := []struct {
+		name   string
+		css    string
+		issues int
+	}{
+		{"Outline removed on focus", `a:focus { outline: none; }`, 1},
+		{"Global outline reset", `* { outline: 0 }`, 1},
+		{"Replaced with box-shadow", `button:focus { outline: none; box-shadow: 0 0 0 3px blue; }`, 0},
+		{"Focus-visible fallback", `:focus:not(:focus-visible) { outline: none } :focus-visible { outline: 2px solid blue }`, 0},
+		{"Unrelated outline", `.card { outline: none }`, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<html><head><style>` + tt.css + `</style></head></html>`))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+
+			if issues := checkFocusOutline(doc); len(issues) != tt.issues {
+				t.Errorf("Expected %d issues, got %v", tt.issues, issues)
+			}
+		})
+	}
+}
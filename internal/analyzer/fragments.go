@@ -0,0 +1,47 @@
+package analyzer
+
+import (
+	"net/url"
+	"strings"
+
+	"website-analyzer/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// CheckFragments verifies that the distinct in-page anchors such as href="#pricing"
+// lead to an element with that id, or an <a> with that name. "#" and
+// "#top", which browsers scroll to the top of the page, always resolve.
+// It returns nil when the page has no in-page anchors.
+func CheckFragments(doc *goquery.Document) *models.FragmentReport {
+	targets := make(map[string]bool)
+	doc.Find("[id]").Each(func(i int, s *goquery.Selection) {
+		targets[s.AttrOr("id", "")] = true
+	})
+	doc.Find("a[name]").Each(func(i int, s *goquery.Selection) {
+		targets[s.AttrOr("name", "")] = true
+	})
+
+	report := &models.FragmentReport{}
+	seen := make(map[string]bool)
+	doc.Find(`a[href^="#"]`).Each(func(i int, s *goquery.Selection) {
+		fragment := strings.TrimPrefix(strings.TrimSpace(s.AttrOr("href", "")), "#")
+		if fragment == "" || strings.EqualFold(fragment, "top") || seen[fragment] {
+			return
+		}
+		seen[fragment] = true
+		report.Total++
+		if targets[fragment] {
+			return
+		}
+		if decoded, err := url.PathUnescape(fragment); err == nil && targets[decoded] {
+			return
+		}
+		report.Broken = append(report.Broken, models.BrokenFragment{Fragment: "#" + fragment, Text: anchorText(s)})
+	})
+
+	if report.Total == 0 {
+		return nil
+	}
+	return report
+}
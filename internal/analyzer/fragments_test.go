@@ -0,0 +1,50 @@
+package analyzer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"website-analyzer/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestCheckFragments(t *testing.T) {
+	html := `<html><body>
+		<nav>
+			<a href="#pricing">Pricing</a>
+			<a href="#faq">FAQ</a>
+			<a href="#legacy">Legacy</a>
+			<a href="#caf%C3%A9">Café</a>
+			<a href="#">Menu</a>
+			<a href="#top">Back to top</a>
+			<a href="#missing">Missing</a>
+			<a href="#missing">Missing again</a>
+			<a href="/other#nowhere">Elsewhere</a>
+		</nav>
+		<section id="pricing"></section>
+		<div id="faq"></div>
+		<a name="legacy"></a>
+		<h2 id="café">Café</h2>
+		<p name="missing">Only anchors may be targeted by name</p>
+	</body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := CheckFragments(doc)
+	if report == nil || report.Total != 5 {
+		t.Fatalf("Expected 5 distinct in-page anchors, got %+v", report)
+	}
+	want := []models.BrokenFragment{{Fragment: "#missing", Text: "Missing"}}
+	if !reflect.DeepEqual(report.Broken, want) {
+		t.Errorf("Expected %+v, got %+v", want, report.Broken)
+	}
+
+	doc, _ = goquery.NewDocumentFromReader(strings.NewReader(`<a href="/page">Page</a><a href="#">Top</a>`))
+	if report := CheckFragments(doc); report != nil {
+		t.Errorf("Expected no report without in-page anchors, got %+v", report)
+	}
+}
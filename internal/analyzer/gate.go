@@ -0,0 +1,56 @@
+package analyzer
+
+import (
+	"fmt"
+
+	"website-analyzer/internal/models"
+)
+
+// gateScores lists the scores compared against the baseline
+var gateScores = []struct {
+	name  string
+	value func(*models.Scores) int
+}{
+	{name: "overall", value: func(s *models.Scores) int { return s.Overall }},
+	{name: "seo", value: func(s *models.Scores) int { return s.SEO }},
+	{name: "accessibility", value: func(s *models.Scores) int { return s.Accessibility }},
+	{name: "links", value: func(s *models.Scores) int { return s.Links }},
+}
+
+// EvaluateGate checks current against baseline. The gate fails when current
+// has broken links the baseline did not, or when any score dropped by more
+// than tolerance points.
+func EvaluateGate(baseline, current *models.AnalysisResult, tolerance int) *models.GateVerdict {
+	verdict := &models.GateVerdict{Tolerance: tolerance}
+
+	known := make(map[string]bool, len(baseline.InaccessibleLinks))
+	for _, link := range baseline.InaccessibleLinks {
+		known[link.URL] = true
+	}
+	for _, link := range current.InaccessibleLinks {
+		if !known[link.URL] {
+			verdict.NewBrokenLinks = append(verdict.NewBrokenLinks, link)
+		}
+	}
+	if n := len(verdict.NewBrokenLinks); n > 0 {
+		verdict.Reasons = append(verdict.Reasons, fmt.Sprintf("%d new broken link(s)", n))
+	}
+
+	if baseline.Scores != nil && current.Scores != nil {
+		for _, score := range gateScores {
+			was, now := score.value(baseline.Scores), score.value(current.Scores)
+			if was-now > tolerance {
+				verdict.ScoreDrops = append(verdict.ScoreDrops, models.ScoreDrop{Metric: score.name, Baseline: was, Current: now})
+				verdict.Reasons = append(verdict.Reasons, fmt.Sprintf("%s score dropped from %d to %d", score.name, was, now))
+			}
+		}
+	}
+
+	verdict.Pass = len(verdict.Reasons) == 0
+	return verdict
+}
+
+// GateTolerance returns the configured score tolerance for gating
+func (a *Analyzer) GateTolerance() int {
+	return a.config.GateTolerance
+}
@@ -0,0 +1,77 @@
+package analyzer
+
+import (
+	"testing"
+
+	"website-analyzer/internal/models"
+)
+
+func TestEvaluateGate(t *testing.T) {
+	baseline := &models.AnalysisResult{
+		InaccessibleLinks: []models.LinkError{{URL: "https://partner.com/old"}},
+		Scores:            &models.Scores{Overall: 80, SEO: 90, Accessibility: 70, Links: 80},
+	}
+
+	tests := []struct {
+		name      string
+		current   *models.AnalysisResult
+		pass      bool
+		newBroken int
+		drops     []string
+	}{
+		{
+			name: "unchanged",
+			current: &models.AnalysisResult{
+				InaccessibleLinks: []models.LinkError{{URL: "https://partner.com/old"}},
+				Scores:            &models.Scores{Overall: 80, SEO: 90, Accessibility: 70, Links: 80},
+			},
+			pass: true,
+		},
+		{
+			name: "within tolerance",
+			current: &models.AnalysisResult{
+				Scores: &models.Scores{Overall: 76, SEO: 85, Accessibility: 70, Links: 100},
+			},
+			pass: true,
+		},
+		{
+			name: "new broken link",
+			current: &models.AnalysisResult{
+				InaccessibleLinks: []models.LinkError{{URL: "https://partner.com/old"}, {URL: "https://example.com/new"}},
+				Scores:            &models.Scores{Overall: 80, SEO: 90, Accessibility: 70, Links: 80},
+			},
+			newBroken: 1,
+		},
+		{
+			name: "score regression",
+			current: &models.AnalysisResult{
+				Scores: &models.Scores{Overall: 74, SEO: 60, Accessibility: 70, Links: 100},
+			},
+			drops: []string{"overall", "seo"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict := EvaluateGate(baseline, tt.current, 5)
+
+			if verdict.Pass != tt.pass {
+				t.Errorf("Expected pass=%v, got %+v", tt.pass, verdict)
+			}
+			if len(verdict.NewBrokenLinks) != tt.newBroken {
+				t.Errorf("Expected %d new broken links, got %+v", tt.newBroken, verdict.NewBrokenLinks)
+			}
+			if len(verdict.ScoreDrops) != len(tt.drops) {
+				t.Fatalf("Expected drops %v, got %+v", tt.drops, verdict.ScoreDrops)
+			}
+			for i, metric := range tt.drops {
+				if verdict.ScoreDrops[i].Metric != metric {
+					t.Errorf("Expected drop in %s, got %+v", metric, verdict.ScoreDrops[i])
+				}
+			}
+			if !verdict.Pass && len(verdict.Reasons) == 0 {
+				t.Error("Expected reasons for a failing verdict")
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"website-analyzer/internal/models"
+)
+
+// harFile is the subset of the HAR 1.2 format the replay fetcher reads
+type harFile struct {
+	Log struct {
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harEntry struct {
+	Request struct {
+		Method string `json:"method"`
+		URL    string `json:"url"`
+	} `json:"request"`
+	Response struct {
+		Status      int    `json:"status"`
+		HTTPVersion string `json:"httpVersion"`
+		Headers     []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"headers"`
+		Content struct {
+			Text     string `json:"text"`
+			Encoding string `json:"encoding"`
+		} `json:"content"`
+		RedirectURL string `json:"redirectURL"`
+	} `json:"response"`
+	// Timings are in milliseconds, -1 for phases that didn't apply
+	Timings struct {
+		DNS     float64 `json:"dns"`
+		Connect float64 `json:"connect"`
+		SSL     float64 `json:"ssl"`
+		Send    float64 `json:"send"`
+		Wait    float64 `json:"wait"`
+		Receive float64 `json:"receive"`
+	} `json:"timings"`
+}
+
+// timing converts the recorded timings; HAR counts the TLS handshake as
+// part of connecting
+func (e harEntry) timing() *models.ResponseTiming {
+	ms := func(v float64) int64 { return int64(max(v, 0)) }
+	t := e.Timings
+	timing := &models.ResponseTiming{
+		DNSMs:      ms(t.DNS),
+		ConnectMs:  ms(t.Connect - max(t.SSL, 0)),
+		TLSMs:      ms(t.SSL),
+		TTFBMs:     ms(t.DNS) + ms(t.Connect) + ms(t.Send) + ms(t.Wait),
+		DownloadMs: ms(t.Receive),
+	}
+	timing.TotalMs = timing.TTFBMs + timing.DownloadMs
+	return timing
+}
+
+// HARFetcher replays pages recorded in a HAR file, e.g. one saved from a
+// browser's developer tools, without contacting their hosts. Recorded
+// redirects are followed; the first GET of each URL is used. Pages carry
+// the timings and HTTP version recorded with them.
+type HARFetcher struct {
+	responses recording
+}
+
+// LoadHAR reads the HAR file at path
+func LoadHAR(path string) (*HARFetcher, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseHAR(f)
+}
+
+// ParseHAR reads a HAR recording
+func ParseHAR(r io.Reader) (*HARFetcher, error) {
+	var har harFile
+	if err := json.NewDecoder(r).Decode(&har); err != nil {
+		return nil, fmt.Errorf("invalid HAR: %w", err)
+	}
+
+	responses := make(recording)
+	for _, entry := range har.Log.Entries {
+		if entry.Request.Method != http.MethodGet {
+			continue
+		}
+		key := normalizeURL(entry.Request.URL)
+		if _, ok := responses[key]; ok {
+			continue
+		}
+
+		body := []byte(entry.Response.Content.Text)
+		if entry.Response.Content.Encoding == "base64" {
+			decoded, err := base64.StdEncoding.DecodeString(entry.Response.Content.Text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid HAR: body of %s: %w", entry.Request.URL, err)
+			}
+			body = decoded
+		}
+		header := make(http.Header)
+		for _, h := range entry.Response.Headers {
+			header.Add(h.Name, h.Value)
+		}
+		redirect := entry.Response.RedirectURL
+		if redirect == "" {
+			redirect = header.Get("Location")
+		}
+		responses[key] = recordedResponse{
+			status:   entry.Response.Status,
+			proto:    normalizeProto(entry.Response.HTTPVersion),
+			header:   header,
+			body:     body,
+			redirect: redirect,
+			timing:   entry.timing(),
+		}
+	}
+	return &HARFetcher{responses: responses}, nil
+}
+
+// Offline reports that replayed pages never contact their hosts
+func (f *HARFetcher) Offline() bool {
+	return true
+}
+
+func (f *HARFetcher) Fetch(ctx context.Context, pageURL string) (*FetchedPage, error) {
+	return f.responses.fetch(ctx, pageURL)
+}
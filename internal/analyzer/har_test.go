@@ -0,0 +1,65 @@
+package analyzer
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"website-analyzer/internal/models"
+)
+
+const testHAR = `{"log": {"version": "1.2", "entries": [
+	{"request": {"method": "GET", "url": "http://example.com/"},
+	 "response": {"status": 301, "headers": [{"name": "Location", "value": "https://example.com/home"}], "content": {"text": ""}, "redirectURL": ""}},
+	{"request": {"method": "GET", "url": "https://example.com/home"},
+	 "response": {"status": 200, "httpVersion": "h2", "headers": [{"name": "Content-Type", "value": "text/html"}],
+	  "content": {"text": "PGh0bWw+PHRpdGxlPkhvbWU8L3RpdGxlPjwvaHRtbD4=", "encoding": "base64"}},
+	 "timings": {"blocked": 1, "dns": 12, "connect": 30, "ssl": 18, "send": 1, "wait": 80, "receive": 7}},
+	{"request": {"method": "POST", "url": "https://example.com/missing"},
+	 "response": {"status": 200, "headers": [], "content": {"text": "posted"}}},
+	{"request": {"method": "GET", "url": "https://example.com/gone"},
+	 "response": {"status": 404, "headers": [], "content": {"text": "not found"}}}
+]}}`
+
+func TestHARFetcher(t *testing.T) {
+	har, err := ParseHAR(strings.NewReader(testHAR))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !offline(har) {
+		t.Error("Expected HAR replay to be offline")
+	}
+
+	page, err := har.Fetch(context.Background(), "http://example.com")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if string(page.Body) != "<html><title>Home</title></html>" || page.Size != int64(len(page.Body)) {
+		t.Errorf("Unexpected body %q", page.Body)
+	}
+	if page.Header.Get("Content-Type") != "text/html" {
+		t.Errorf("Expected recorded headers, got %v", page.Header)
+	}
+	if page.Proto != "HTTP/2.0" || !page.TLS {
+		t.Errorf("Expected the recorded HTTP/2 over TLS, got %q, %v", page.Proto, page.TLS)
+	}
+	want := models.ResponseTiming{DNSMs: 12, ConnectMs: 12, TLSMs: 18, TTFBMs: 123, DownloadMs: 7, TotalMs: 130}
+	if page.Timing == nil || *page.Timing != want {
+		t.Errorf("Timing = %+v, want %+v", page.Timing, want)
+	}
+	hops := []models.RedirectHop{{URL: "http://example.com", StatusCode: 301}, {URL: "https://example.com/home", StatusCode: 200, LatencyMs: 130}}
+	if !slices.Equal(page.Redirects, hops) {
+		t.Errorf("Redirects = %+v, want %+v", page.Redirects, hops)
+	}
+
+	for _, pageURL := range []string{"https://example.com/missing", "https://example.com/gone"} {
+		if _, err := har.Fetch(context.Background(), pageURL); err == nil {
+			t.Errorf("Expected %s to fail", pageURL)
+		}
+	}
+
+	if _, err := ParseHAR(strings.NewReader("not json")); err == nil {
+		t.Error("Expected invalid HAR to be rejected")
+	}
+}
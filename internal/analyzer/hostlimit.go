@@ -0,0 +1,120 @@
+package analyzer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+// hostLimiter keeps link checks polite towards each host: at most
+// concurrency requests in flight and requests spaced by interval. A nil
+// limiter allows everything.
+type hostLimiter struct {
+	interval    time.Duration
+	concurrency int
+
+	mu    sync.Mutex
+	hosts map[string]*hostSlot
+}
+
+type hostSlot struct {
+	inFlight chan struct{}
+	next     time.Time
+}
+
+// newHostLimiter returns nil when both limits are disabled
+func newHostLimiter(rate float64, concurrency int) *hostLimiter {
+	if rate <= 0 && concurrency <= 0 {
+		return nil
+	}
+	l := &hostLimiter{concurrency: concurrency, hosts: make(map[string]*hostSlot)}
+	if rate > 0 {
+		l.interval = time.Duration(float64(time.Second) / rate)
+	}
+	return l
+}
+
+func (l *hostLimiter) slot(host string) *hostSlot {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	slot, ok := l.hosts[host]
+	if !ok {
+		slot = &hostSlot{}
+		if l.concurrency > 0 {
+			slot.inFlight = make(chan struct{}, l.concurrency)
+		}
+		l.hosts[host] = slot
+	}
+	return slot
+}
+
+// acquire waits until host may be sent another request. The returned
+// release must be called once the request is done.
+func (l *hostLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	if l == nil || host == "" {
+		return func() {}, nil
+	}
+
+	slot := l.slot(host)
+	release := func() {}
+	if slot.inFlight != nil {
+		select {
+		case slot.inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		release = func() { <-slot.inFlight }
+	}
+
+	if l.interval > 0 {
+		// Reserve the next free start time for this host
+		l.mu.Lock()
+		start := time.Now()
+		if slot.next.After(start) {
+			start = slot.next
+		}
+		slot.next = start.Add(l.interval)
+		l.mu.Unlock()
+
+		if wait := time.Until(start); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				release()
+				return nil, ctx.Err()
+			case <-timer.C:
+			}
+		}
+	}
+
+	return release, nil
+}
+
+// interleaveByHost orders links round-robin across hosts so workers
+// waiting on one rate-limited host don't hold up the rest
+func interleaveByHost(links []models.Link) []models.Link {
+	var hosts []string
+	byHost := make(map[string][]models.Link)
+	for _, link := range links {
+		host := getDomain(link.URL)
+		if _, ok := byHost[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		byHost[host] = append(byHost[host], link)
+	}
+
+	ordered := make([]models.Link, 0, len(links))
+	for len(ordered) < len(links) {
+		for _, host := range hosts {
+			if queue := byHost[host]; len(queue) > 0 {
+				ordered = append(ordered, queue[0])
+				byHost[host] = queue[1:]
+			}
+		}
+	}
+	return ordered
+}
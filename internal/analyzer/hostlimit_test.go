@@ -0,0 +1,86 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+func TestHostLimiterDisabled(t *testing.T) {
+	l := newHostLimiter(0, 0)
+	if l != nil {
+		t.Fatal("Expected no limiter when both limits are off")
+	}
+	release, err := l.acquire(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("Expected a nil limiter to allow everything, got %v", err)
+	}
+	release()
+}
+
+func TestHostLimiterConcurrency(t *testing.T) {
+	l := newHostLimiter(0, 1)
+	ctx := context.Background()
+
+	release, err := l.acquire(ctx, "a.com")
+	if err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+
+	// Other hosts are independent
+	other, err := l.acquire(ctx, "b.com")
+	if err != nil {
+		t.Fatalf("Expected another host to be free, got %v", err)
+	}
+	other()
+
+	// The same host waits until the slot is released or ctx ends
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.acquire(short, "a.com"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected acquire to wait for the busy host, got %v", err)
+	}
+
+	release()
+	again, err := l.acquire(ctx, "a.com")
+	if err != nil {
+		t.Fatalf("Expected the released slot to be reusable, got %v", err)
+	}
+	again()
+}
+
+func TestHostLimiterRate(t *testing.T) {
+	l := newHostLimiter(20, 0)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		release, err := l.acquire(ctx, "a.com")
+		if err != nil {
+			t.Fatalf("acquire failed: %v", err)
+		}
+		release()
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Expected 3 requests at 20/s to take at least 100ms, took %v", elapsed)
+	}
+}
+
+func TestInterleaveByHost(t *testing.T) {
+	links := []models.Link{
+		{URL: "https://a.com/1"}, {URL: "https://a.com/2"}, {URL: "https://a.com/3"},
+		{URL: "https://b.com/1"}, {URL: "https://c.com/1"}, {URL: "https://b.com/2"},
+	}
+
+	got := interleaveByHost(links)
+
+	want := []string{"https://a.com/1", "https://b.com/1", "https://c.com/1", "https://a.com/2", "https://b.com/2", "https://a.com/3"}
+	for i, link := range got {
+		if link.URL != want[i] {
+			t.Fatalf("Expected order %v, got %v", want, got)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"website-analyzer/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Hreflang declaration sources
+const (
+	HreflangSourceLink    = "link"
+	HreflangSourceSitemap = "sitemap"
+)
+
+// ExtractHreflang returns alternates declared with <link rel="alternate" hreflang>
+func ExtractHreflang(doc *goquery.Document, baseURL string) []models.HreflangAlternate {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil
+	}
+	base = documentBase(doc, base)
+
+	var alternates []models.HreflangAlternate
+	doc.Find(`link[rel="alternate"][hreflang]`).Each(func(i int, s *goquery.Selection) {
+		lang, _ := s.Attr("hreflang")
+		href, _ := s.Attr("href")
+
+		parsed, err := url.Parse(strings.TrimSpace(href))
+		if err != nil || href == "" {
+			return
+		}
+
+		alternates = append(alternates, models.HreflangAlternate{
+			Lang:    strings.ToLower(strings.TrimSpace(lang)),
+			URL:     base.ResolveReference(parsed).String(),
+			Sources: []string{HreflangSourceLink},
+		})
+	})
+
+	return alternates
+}
+
+// sitemapHreflang returns the xhtml:link alternates listed for the target
+// URL in the given sitemaps
+func sitemapHreflang(files []*sitemapFile, targetURL string) []models.HreflangAlternate {
+	var alternates []models.HreflangAlternate
+	for _, file := range files {
+		for _, entry := range file.URLs {
+			if !sameURL(entry.Loc, targetURL) {
+				continue
+			}
+			for _, link := range entry.Alternates {
+				if link.Rel != "alternate" || link.Hreflang == "" {
+					continue
+				}
+				alternates = append(alternates, models.HreflangAlternate{
+					Lang:    strings.ToLower(strings.TrimSpace(link.Hreflang)),
+					URL:     strings.TrimSpace(link.Href),
+					Sources: []string{HreflangSourceSitemap},
+				})
+			}
+		}
+	}
+	return alternates
+}
+
+// mergeHreflang combines both declaration sources and reports conflicting
+// URLs and languages declared in only one of them
+func mergeHreflang(fromLinks, fromSitemap []models.HreflangAlternate, sitemapChecked bool) *models.HreflangReport {
+	report := &models.HreflangReport{}
+
+	linkURLs := make(map[string]string)
+	for _, alt := range fromLinks {
+		linkURLs[alt.Lang] = alt.URL
+		report.Alternates = append(report.Alternates, alt)
+	}
+
+	sitemapURLs := make(map[string]string)
+	for _, alt := range fromSitemap {
+		sitemapURLs[alt.Lang] = alt.URL
+
+		linkURL, inLinks := linkURLs[alt.Lang]
+		switch {
+		case !inLinks:
+			report.Alternates = append(report.Alternates, alt)
+		case sameURL(linkURL, alt.URL):
+			for i := range report.Alternates {
+				if report.Alternates[i].Lang == alt.Lang {
+					report.Alternates[i].Sources = append(report.Alternates[i].Sources, HreflangSourceSitemap)
+				}
+			}
+		default:
+			report.Alternates = append(report.Alternates, alt)
+			report.Conflicts = append(report.Conflicts, fmt.Sprintf(
+				"hreflang %q points to %s in link tags but %s in the sitemap", alt.Lang, linkURL, alt.URL))
+		}
+	}
+
+	// Missing declarations only matter when both sources are in use
+	if sitemapChecked && len(fromLinks) > 0 && len(fromSitemap) > 0 {
+		for lang := range linkURLs {
+			if _, ok := sitemapURLs[lang]; !ok {
+				report.Conflicts = append(report.Conflicts, fmt.Sprintf("hreflang %q is declared in link tags but not in the sitemap", lang))
+			}
+		}
+		for lang := range sitemapURLs {
+			if _, ok := linkURLs[lang]; !ok {
+				report.Conflicts = append(report.Conflicts, fmt.Sprintf("hreflang %q is declared in the sitemap but not in link tags", lang))
+			}
+		}
+	}
+
+	sort.SliceStable(report.Alternates, func(i, j int) bool {
+		return report.Alternates[i].Lang < report.Alternates[j].Lang
+	})
+	sort.Strings(report.Conflicts)
+
+	return report
+}
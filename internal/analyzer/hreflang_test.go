@@ -0,0 +1,81 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestHreflangMerge(t *testing.T) {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			_, _ = w.Write([]byte("User-agent: *\nDisallow:\nSitemap: " + ts.URL + "/sitemap-index.xml\n"))
+		case "/sitemap-index.xml":
+			_, _ = w.Write([]byte(`<?xml version="1.0"?>
+				<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+					<sitemap><loc>` + ts.URL + `/sitemap-pages.xml</loc></sitemap>
+				</sitemapindex>`))
+		case "/sitemap-pages.xml":
+			_, _ = w.Write([]byte(`<?xml version="1.0"?>
+				<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9" xmlns:xhtml="http://www.w3.org/1999/xhtml">
+					<url>
+						<loc>` + ts.URL + `/en/</loc>
+						<xhtml:link rel="alternate" hreflang="en" href="` + ts.URL + `/en/"/>
+						<xhtml:link rel="alternate" hreflang="de" href="` + ts.URL + `/de-de/"/>
+						<xhtml:link rel="alternate" hreflang="fr" href="` + ts.URL + `/fr/"/>
+					</url>
+				</urlset>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	html := `
+		<html><head>
+			<link rel="alternate" hreflang="en" href="/en">
+			<link rel="alternate" hreflang="de" href="/de/">
+			<link rel="alternate" hreflang="x-default" href="/">
+		</head></html>
+	`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	client := &http.Client{Timeout: time.Second}
+	robots, err := fetchRobots(context.Background(), client, ts.URL+"/en/")
+	if err != nil {
+		t.Fatalf("Failed to fetch robots.txt: %v", err)
+	}
+
+	files, errs := loadSitemaps(context.Background(), client, discoverSitemaps(robots, ts.URL))
+	if len(errs) != 0 || len(files) != 2 {
+		t.Fatalf("Expected index and child sitemap, got %d files, errors %v", len(files), errs)
+	}
+
+	report := mergeHreflang(ExtractHreflang(doc, ts.URL+"/en/"), sitemapHreflang(files, ts.URL+"/en/"), true)
+
+	if len(report.Alternates) != 5 {
+		t.Errorf("Expected 5 merged alternates, got %+v", report.Alternates)
+	}
+
+	for _, alt := range report.Alternates {
+		if alt.Lang == "en" && len(alt.Sources) != 2 {
+			t.Errorf("Expected en to be declared in both sources, got %v", alt.Sources)
+		}
+	}
+
+	// de points to different URLs, fr is sitemap-only, x-default is link-only
+	if len(report.Conflicts) != 3 {
+		t.Errorf("Expected 3 conflicts, got %v", report.Conflicts)
+	}
+}
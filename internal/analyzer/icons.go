@@ -0,0 +1,181 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"website-analyzer/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// maxManifestSize bounds the web app manifests read for their icons
+const maxManifestSize = 1024 * 1024
+
+// webManifest is the part of a web app manifest listing its icons
+type webManifest struct {
+	Icons []struct {
+		Src   string `json:"src"`
+		Sizes string `json:"sizes"`
+	} `json:"icons"`
+}
+
+// AuditIcons lists the favicons and touch icons the page declares and the
+// /favicon.ico of its origin. With a client, the icons of its web app
+// manifest are added and every icon is checked for a 200 response with an
+// image content type.
+func AuditIcons(ctx context.Context, doc *goquery.Document, baseURL string, client *http.Client, maxWorkers int) *models.IconReport {
+	page, err := url.Parse(baseURL)
+	if err != nil {
+		return nil
+	}
+	base := documentBase(doc, page)
+
+	report := &models.IconReport{}
+	seen := make(map[string]bool)
+	add := func(icon models.Icon) {
+		if icon.URL == "" || seen[icon.URL] {
+			return
+		}
+		seen[icon.URL] = true
+		report.Icons = append(report.Icons, icon)
+	}
+
+	doc.Find("link[rel][href]").Each(func(_ int, s *goquery.Selection) {
+		var source string
+		for _, rel := range strings.Fields(strings.ToLower(s.AttrOr("rel", ""))) {
+			switch rel {
+			case "icon":
+				source = models.IconSourceLink
+			case "apple-touch-icon", "apple-touch-icon-precomposed":
+				source = models.IconSourceTouch
+			case "manifest":
+				if report.Manifest == "" {
+					report.Manifest, _ = resolveURL(base, s.AttrOr("href", ""))
+				}
+			}
+		}
+		if source == "" {
+			return
+		}
+		resolved, err := resolveURL(base, s.AttrOr("href", ""))
+		if err != nil {
+			return
+		}
+		add(models.Icon{URL: resolved, Source: source, Sizes: s.AttrOr("sizes", "")})
+	})
+
+	if client != nil && report.Manifest != "" {
+		icons, err := manifestIcons(ctx, client, report.Manifest)
+		if err != nil {
+			report.ManifestError = err.Error()
+		}
+		for _, icon := range icons {
+			add(icon)
+		}
+	}
+	add(models.Icon{URL: page.Scheme + "://" + page.Host + "/favicon.ico", Source: models.IconSourceFallback})
+
+	if client != nil {
+		runLimited(ctx, len(report.Icons), maxWorkers, func(i int) {
+			checkIcon(ctx, client, &report.Icons[i])
+		})
+	}
+
+	report.Issues = iconIssues(report, client != nil)
+	return report
+}
+
+// manifestIcons reads the icons a web app manifest lists, resolved
+// against the manifest's URL
+func manifestIcons(ctx context.Context, client *http.Client, manifestURL string) ([]models.Icon, error) {
+	body, _, err := fetchBody(ctx, client, manifestURL, maxManifestSize)
+	if err != nil {
+		return nil, err
+	}
+	var manifest webManifest
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	base, err := url.Parse(manifestURL)
+	if err != nil {
+		return nil, err
+	}
+	var icons []models.Icon
+	for _, icon := range manifest.Icons {
+		resolved, err := resolveURL(base, icon.Src)
+		if err != nil || resolved == "" {
+			continue
+		}
+		icons = append(icons, models.Icon{URL: resolved, Source: models.IconSourceManifest, Sizes: icon.Sizes})
+	}
+	return icons, nil
+}
+
+// checkIcon requests icon, without reading its body, and records whether
+// it is available
+func checkIcon(ctx context.Context, client *http.Client, icon *models.Icon) {
+	ctx, cancel := context.WithTimeout(ctx, client.Timeout)
+	defer cancel()
+
+	icon.Checked = true
+	req, err := http.NewRequestWithContext(ctx, "GET", icon.URL, nil)
+	if err != nil {
+		icon.Error = err.Error()
+		return
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		icon.Error = err.Error()
+		return
+	}
+	resp.Body.Close()
+
+	icon.StatusCode = resp.StatusCode
+	icon.ContentType, _, _ = mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch {
+	case resp.StatusCode != http.StatusOK:
+		icon.Error = fmt.Sprintf("HTTP %d: %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	case !strings.HasPrefix(icon.ContentType, "image/"):
+		icon.Error = fmt.Sprintf("served as %q, not an image", icon.ContentType)
+	default:
+		icon.Available = true
+	}
+}
+
+// iconIssues lists missing icon kinds and, when checked, broken icons
+func iconIssues(report *models.IconReport, checked bool) []string {
+	var issues []string
+	declared := make(map[string]bool)
+	available := make(map[string]bool)
+	for _, icon := range report.Icons {
+		declared[icon.Source] = true
+		if icon.Available {
+			available[icon.Source] = true
+		}
+		if checked && !icon.Available && icon.Source != models.IconSourceFallback {
+			issues = append(issues, fmt.Sprintf("The %s %s is unavailable: %s", icon.Source, icon.URL, icon.Error))
+		}
+	}
+
+	if !declared[models.IconSourceLink] && !declared[models.IconSourceManifest] {
+		if checked && !available[models.IconSourceFallback] {
+			issues = append(issues, "No favicon is declared and /favicon.ico is unavailable")
+		} else if !checked {
+			issues = append(issues, "No favicon is declared; browsers fall back to /favicon.ico")
+		}
+	}
+	if !declared[models.IconSourceTouch] {
+		issues = append(issues, "No apple-touch-icon is declared for iOS home screens")
+	}
+	if report.ManifestError != "" {
+		issues = append(issues, "The web app manifest couldn't be read: "+report.ManifestError)
+	}
+	return issues
+}
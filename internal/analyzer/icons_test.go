@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"website-analyzer/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestAuditIcons(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/icon.png", "/static/icon-192.png":
+			w.Header().Set("Content-Type", "image/png")
+		case "/favicon.ico":
+			w.Header().Set("Content-Type", "image/x-icon")
+		case "/static/site.webmanifest":
+			w.Header().Set("Content-Type", "application/manifest+json")
+			w.Write([]byte(`{"icons": [{"src": "icon-192.png", "sizes": "192x192"}, {"src": "icon-512.png", "sizes": "512x512"}]}`))
+		case "/touch.png":
+			// Soft 404s answer with a page
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	html := `<html><head>
+		<link rel="shortcut icon" href="/icon.png" sizes="32x32">
+		<link rel="apple-touch-icon" href="/touch.png">
+		<link rel="manifest" href="/static/site.webmanifest">
+		<link rel="stylesheet" href="/site.css">
+	</head></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := AuditIcons(context.Background(), doc, ts.URL+"/page", &http.Client{Timeout: time.Second}, 3)
+	if report.Manifest != ts.URL+"/static/site.webmanifest" || report.ManifestError != "" {
+		t.Errorf("Unexpected manifest %q: %s", report.Manifest, report.ManifestError)
+	}
+
+	expected := []models.Icon{
+		{URL: ts.URL + "/icon.png", Source: models.IconSourceLink, Sizes: "32x32", Checked: true, Available: true, StatusCode: 200, ContentType: "image/png"},
+		{URL: ts.URL + "/touch.png", Source: models.IconSourceTouch, Checked: true, StatusCode: 200, ContentType: "text/html", Error: `served as "text/html", not an image`},
+		{URL: ts.URL + "/static/icon-192.png", Source: models.IconSourceManifest, Sizes: "192x192", Checked: true, Available: true, StatusCode: 200, ContentType: "image/png"},
+		{URL: ts.URL + "/static/icon-512.png", Source: models.IconSourceManifest, Sizes: "512x512", Checked: true, StatusCode: 404, Error: "HTTP 404: Not Found"},
+		{URL: ts.URL + "/favicon.ico", Source: models.IconSourceFallback, Checked: true, Available: true, StatusCode: 200, ContentType: "image/x-icon"},
+	}
+	if !slices.Equal(report.Icons, expected) {
+		t.Errorf("Expected icons\n%+v\ngot\n%+v", expected, report.Icons)
+	}
+	if len(report.Issues) != 2 || !strings.Contains(report.Issues[0], "touch.png") || !strings.Contains(report.Issues[1], "icon-512.png") {
+		t.Errorf("Expected the broken touch and manifest icons as issues, got %v", report.Issues)
+	}
+
+	// Without a client nothing is checked and the fallback is only noted
+	bare, _ := goquery.NewDocumentFromReader(strings.NewReader(`<html><head></head></html>`))
+	offline := AuditIcons(context.Background(), bare, "https://example.com/a/b", nil, 3)
+	if len(offline.Icons) != 1 || offline.Icons[0].URL != "https://example.com/favicon.ico" || offline.Icons[0].Checked {
+		t.Errorf("Expected only the unchecked fallback, got %+v", offline.Icons)
+	}
+	if len(offline.Issues) != 2 {
+		t.Errorf("Expected missing favicon and touch icon issues, got %v", offline.Issues)
+	}
+}
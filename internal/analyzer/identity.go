@@ -0,0 +1,123 @@
+package analyzer
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"website-analyzer/internal/validator"
+)
+
+// botProduct is the product token and version every request carries
+const botProduct = "WebPageAnalyzer/1.0"
+
+// ErrOptedOut is returned for pages on domains the operator has promised
+// never to contact
+var ErrOptedOut = errors.New("domain has opted out of analysis")
+
+// UserAgent returns the User-Agent header sent with every request. When
+// infoURL is set it points site owners to the page describing the bot.
+func UserAgent(infoURL string) string {
+	if infoURL == "" {
+		return botProduct
+	}
+	return botProduct + " (+" + infoURL + ")"
+}
+
+// RobotsToken is the user agent name robots.txt groups can address
+func RobotsToken() string {
+	return robotsAgent
+}
+
+// domainList matches hosts against a set of domains and their subdomains
+type domainList map[string]bool
+
+func newDomainList(domains []string) domainList {
+	list := make(domainList, len(domains))
+	for _, d := range domains {
+		d = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(d)), ".")
+		if d != "" {
+			list[d] = true
+		}
+	}
+	return list
+}
+
+// contains reports whether host is a listed domain or a subdomain of one
+func (l domainList) contains(host string) bool {
+	if len(l) == 0 {
+		return false
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	// Addresses have no parent domains
+	if net.ParseIP(host) != nil {
+		return l[host]
+	}
+	for host != "" {
+		if l[host] {
+			return true
+		}
+		_, parent, ok := strings.Cut(host, ".")
+		if !ok {
+			break
+		}
+		host = parent
+	}
+	return false
+}
+
+// containsURL reports whether rawURL's host is listed; unparseable URLs
+// are left to fail elsewhere
+func (l domainList) containsURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return l.contains(u.Hostname())
+}
+
+// outOfScope reports whether rawURL's host is outside the hosts this
+// deployment may analyze, as far as its name tells
+func outOfScope(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return validator.CheckAccess(u.Hostname()) != nil
+}
+
+// safeTransport carries outbound requests unless a test supplies its own;
+// it refuses to connect to private addresses and to hosts outside the
+// access lists whatever a host resolves to
+var safeTransport = validator.NewTargetTransport()
+
+// outboundTransport identifies requests with the bot's User-Agent and
+// refuses to contact opted-out domains, which also covers redirects and
+// resources fetched on the page's behalf
+type outboundTransport struct {
+	base      http.RoundTripper
+	userAgent string
+	optOut    domainList
+}
+
+func (t outboundTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.optOut.contains(req.URL.Hostname()) {
+		return nil, ErrOptedOut
+	}
+
+	base := t.base
+	if base == nil {
+		base = safeTransport
+	}
+
+	userAgent := t.userAgent
+	if userAgent == "" {
+		userAgent = botProduct
+	}
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", userAgent)
+	return base.RoundTrip(req)
+}
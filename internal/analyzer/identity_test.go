@@ -0,0 +1,154 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"website-analyzer/internal/validator"
+)
+
+func TestUserAgent(t *testing.T) {
+	if got := UserAgent(""); got != "WebPageAnalyzer/1.0" {
+		t.Errorf("UserAgent without info URL = %q", got)
+	}
+	want := "WebPageAnalyzer/1.0 (+https://analyzer.example.com/.well-known/bot)"
+	if got := UserAgent("https://analyzer.example.com/.well-known/bot"); got != want {
+		t.Errorf("UserAgent = %q, want %q", got, want)
+	}
+}
+
+func TestDomainList(t *testing.T) {
+	list := newDomainList([]string{" Example.com ", "10.0.0.1", ""})
+
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"example.com", true},
+		{"www.example.com", true},
+		{"EXAMPLE.COM.", true},
+		{"notexample.com", false},
+		{"example.org", false},
+		{"10.0.0.1", true},
+		{"110.0.0.1", false},
+		{"0.1", false},
+	}
+	for _, tt := range tests {
+		if got := list.contains(tt.host); got != tt.want {
+			t.Errorf("contains(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+
+	if newDomainList(nil).contains("example.com") {
+		t.Error("Empty list should contain nothing")
+	}
+}
+
+func TestAnalyzer_OptOut(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	var userAgent string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			userAgent = r.UserAgent()
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body>
+			<a href="/about">About</a>
+			<a href="http://opted-out.test/page">Opted out</a>
+			<a href="http://www.opted-out.test/other">Subdomain</a>
+		</body></html>`))
+	}))
+	defer ts.Close()
+
+	config := &Config{
+		RequestTimeout:  2 * time.Second,
+		LinkTimeout:     time.Second,
+		MaxWorkers:      5,
+		MaxResponseSize: 1024 * 1024,
+		MaxURLLength:    2048,
+		MaxRedirects:    5,
+		BotInfoURL:      "https://analyzer.example.com/.well-known/bot",
+		OptOutDomains:   []string{"opted-out.test"},
+	}
+	a := NewAnalyzer(config)
+
+	result, err := a.Analyze(context.Background(), ts.URL+"/")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if userAgent != UserAgent(config.BotInfoURL) {
+		t.Errorf("Expected the bot User-Agent, got %q", userAgent)
+	}
+	if len(result.OptedOut) != 2 {
+		t.Errorf("Expected both opted-out links to be listed, got %v", result.OptedOut)
+	}
+	if len(result.InaccessibleLinks) != 0 {
+		t.Errorf("Opted-out links should not be reported inaccessible, got %v", result.InaccessibleLinks)
+	}
+
+	config.OptOutDomains = []string{"127.0.0.1"}
+	_, err = NewAnalyzer(config).Analyze(context.Background(), ts.URL+"/")
+	if !errors.Is(err, ErrOptedOut) {
+		t.Errorf("Expected ErrOptedOut for an opted-out target, got %v", err)
+	}
+}
+
+func TestAnalyzer_AccessLists(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+	defer validator.SetAccessLists(nil, nil)
+
+	// The same server under another name is outside the allowlist
+	var elsewhere atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/elsewhere" {
+			elsewhere.Add(1)
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>Home</title></head><body>
+			<a href="/about">About</a>
+			<a href="http://partner.test/page">Partner</a>
+			<a href="http://localhost:` + r.Host[strings.LastIndex(r.Host, ":")+1:] + `/elsewhere">Other host</a>
+		</body></html>`))
+	}))
+	defer ts.Close()
+
+	if err := validator.SetAccessLists([]string{"127.0.0.1"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	a := NewAnalyzer(&Config{
+		RequestTimeout:  2 * time.Second,
+		LinkTimeout:     time.Second,
+		MaxWorkers:      5,
+		MaxResponseSize: 1024 * 1024,
+		MaxURLLength:    2048,
+		MaxRedirects:    5,
+	})
+
+	result, err := a.Analyze(context.Background(), ts.URL+"/")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(result.OutOfScope) != 2 || len(result.InaccessibleLinks) != 0 {
+		t.Errorf("Expected both links outside the allowlist to be skipped, got %v and %v", result.OutOfScope, result.InaccessibleLinks)
+	}
+	if elsewhere.Load() != 0 {
+		t.Errorf("Expected no request outside the allowlist, got %d", elsewhere.Load())
+	}
+
+	if err := validator.SetAccessLists(nil, []string{"127.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Analyze(context.Background(), ts.URL+"/?denied"); !errors.Is(err, validator.ErrOutOfScope) {
+		t.Errorf("Expected ErrOutOfScope for a denied target, got %v", err)
+	}
+}
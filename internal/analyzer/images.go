@@ -0,0 +1,190 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"website-analyzer/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// largeImageBytes is the size from which a JPEG/PNG without a modern
+// alternative is flagged
+const largeImageBytes = 100 * 1024
+
+// webpSavings holds typical size reductions when converting to WebP
+// (lossy for JPEG, lossless for PNG)
+var webpSavings = map[string]float64{
+	"jpeg": 0.30,
+	"png":  0.26,
+}
+
+// AuditImageFormats fetches metadata for every raster image on the page and
+// flags large JPEG/PNG files that are not offered as WebP/AVIF
+func AuditImageFormats(ctx context.Context, doc *goquery.Document, baseURL string, client *http.Client, maxWorkers int) *models.ImageFormatReport {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil
+	}
+	base = documentBase(doc, base)
+
+	var images []models.ImageInfo
+	seen := make(map[string]bool)
+
+	doc.Find("img").Each(func(i int, s *goquery.Selection) {
+		src := imageSource(s)
+		if src == "" || strings.HasPrefix(src, "data:") {
+			return
+		}
+
+		resolved, err := resolveURL(base, src)
+		if err != nil || resolved == "" || seen[resolved] {
+			return
+		}
+		seen[resolved] = true
+
+		format := formatFromPath(resolved)
+		if format == "svg" {
+			return
+		}
+
+		images = append(images, models.ImageInfo{
+			URL:                  resolved,
+			Format:               format,
+			HasModernAlternative: hasModernAlternative(s),
+		})
+	})
+
+	fetchImageInfo(ctx, images, client, maxWorkers)
+
+	report := &models.ImageFormatReport{Images: images}
+	for i := range report.Images {
+		img := &report.Images[i]
+		ratio, convertible := webpSavings[img.Format]
+		if !convertible || img.HasModernAlternative || img.Size < largeImageBytes {
+			continue
+		}
+
+		img.Flagged = true
+		img.EstimatedSavings = int64(float64(img.Size) * ratio)
+		report.TotalEstimatedSavings += img.EstimatedSavings
+	}
+
+	return report
+}
+
+// fetchImageInfo issues HEAD requests to fill in size and content type
+func fetchImageInfo(ctx context.Context, images []models.ImageInfo, client *http.Client, maxWorkers int) {
+	runLimited(ctx, len(images), maxWorkers, func(i int) {
+		img := &images[i]
+
+		resp, err := headResource(ctx, client, img.URL)
+		if err != nil {
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= 400 {
+			return
+		}
+
+		img.Size = resp.ContentLength
+		if format := formatFromContentType(resp.Header.Get("Content-Type")); format != "" {
+			img.Format = format
+		}
+	})
+}
+
+func headResource(ctx context.Context, client *http.Client, resourceURL string) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, client.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "HEAD", resourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Do(req)
+}
+
+// hasModernAlternative checks for WebP/AVIF sources in a wrapping <picture>
+// or among the candidates of the image's own srcset
+func hasModernAlternative(s *goquery.Selection) bool {
+	srcset, _ := s.Attr("srcset")
+	if hasModernCandidate(srcset) {
+		return true
+	}
+
+	found := false
+	s.Closest("picture").Find("source").Each(func(i int, source *goquery.Selection) {
+		sourceType, _ := source.Attr("type")
+		sourceSet, _ := source.Attr("srcset")
+		if isModernFormat(formatFromContentType(sourceType)) || hasModernCandidate(sourceSet) {
+			found = true
+		}
+	})
+
+	return found
+}
+
+// hasModernCandidate reports whether any candidate of srcset is WebP/AVIF
+func hasModernCandidate(srcset string) bool {
+	for _, candidate := range srcsetURLs(srcset) {
+		if isModernFormat(formatFromPath(candidate)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isModernFormat(format string) bool {
+	return format == "webp" || format == "avif"
+}
+
+func formatFromPath(rawURL string) string {
+	// srcset candidates carry a descriptor after the URL
+	if fields := strings.Fields(rawURL); len(fields) > 0 {
+		rawURL = fields[0]
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		rawURL = u.Path
+	}
+
+	switch strings.ToLower(path.Ext(rawURL)) {
+	case ".jpg", ".jpeg":
+		return "jpeg"
+	case ".png":
+		return "png"
+	case ".gif":
+		return "gif"
+	case ".webp":
+		return "webp"
+	case ".avif":
+		return "avif"
+	case ".svg":
+		return "svg"
+	default:
+		return "unknown"
+	}
+}
+
+func formatFromContentType(contentType string) string {
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	if !strings.HasPrefix(contentType, "image/") {
+		return ""
+	}
+
+	format := strings.TrimPrefix(contentType, "image/")
+	if idx := strings.IndexAny(format, ";+"); idx >= 0 {
+		format = format[:idx]
+	}
+	if format == "jpg" {
+		format = "jpeg"
+	}
+
+	return format
+}
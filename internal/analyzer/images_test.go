@@ -0,0 +1,112 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestAuditImageFormats(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/large.jpg", "/picture.jpg":
+			w.Header().Set("Content-Type", "image/jpeg")
+			w.Header().Set("Content-Length", "200000")
+		case "/small.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Header().Set("Content-Length", "2000")
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	html := `
+		<html><body>
+			<img src="/large.jpg">
+			<img src="/small.png">
+			<picture>
+				<source type="image/avif" srcset="/picture.avif">
+				<img src="/picture.jpg">
+			</picture>
+			<img src="/logo.svg">
+		</body></html>
+	`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	report := AuditImageFormats(context.Background(), doc, ts.URL, &http.Client{Timeout: time.Second}, 2)
+
+	if len(report.Images) != 3 {
+		t.Fatalf("Expected 3 raster images, got %d", len(report.Images))
+	}
+
+	flagged := 0
+	for _, img := range report.Images {
+		if img.Flagged {
+			flagged++
+			if !strings.HasSuffix(img.URL, "/large.jpg") {
+				t.Errorf("Unexpected flagged image %s", img.URL)
+			}
+		}
+	}
+
+	if flagged != 1 {
+		t.Errorf("Expected 1 flagged image, got %d", flagged)
+	}
+
+	if report.TotalEstimatedSavings != 60000 {
+		t.Errorf("Expected 60000 bytes of savings, got %d", report.TotalEstimatedSavings)
+	}
+}
+
+func TestHasModernAlternative(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want bool
+	}{
+		{"modern later candidate", `<img src="/a.jpg" srcset="/a.jpg 1x, /a.webp 2x">`, true},
+		{"no modern candidate", `<img src="/a.jpg" srcset="/a.jpg 1x, /a.png 2x">`, false},
+		{"after a data URI", `<img src="/a.jpg" srcset="data:image/png;base64,AAAA 1x, /a.avif 2x">`, true},
+		{"untyped source", `<picture><source srcset="/p.jpg 480w, /p.avif 800w"><img src="/p.jpg"></picture>`, true},
+		{"legacy source", `<picture><source srcset="/p.jpg 480w, /p.png 800w"><img src="/p.jpg"></picture>`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hasModernAlternative(doc.Find("img")); got != tt.want {
+				t.Errorf("hasModernAlternative = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"/img/photo.JPG", "jpeg"},
+		{"https://cdn.example.com/a.png?v=2", "png"},
+		{"/hero.webp 1200w", "webp"},
+		{"/image", "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := formatFromPath(tt.input); got != tt.expected {
+			t.Errorf("formatFromPath(%q) = %s, want %s", tt.input, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"website-analyzer/internal/models"
+)
+
+// AuditInsecureLinks lists links pointing at http:// destinations and checks
+// whether the https:// equivalent responds, producing an upgrade list
+func AuditInsecureLinks(ctx context.Context, links []models.Link, client *http.Client, maxWorkers int) *models.InsecureLinkReport {
+	report := &models.InsecureLinkReport{}
+
+	for _, link := range links {
+		if strings.HasPrefix(link.URL, "http://") {
+			report.Links = append(report.Links, models.InsecureLink{
+				URL:  link.URL,
+				Type: link.Type,
+			})
+		}
+	}
+
+	runLimited(ctx, len(report.Links), maxWorkers, func(i int) {
+		insecure := &report.Links[i]
+		insecure.HTTPSURL = "https://" + strings.TrimPrefix(insecure.URL, "http://")
+
+		result := checkLink(ctx, client, insecure.HTTPSURL, false)
+		if result.err != nil {
+			insecure.UpgradeError = result.err.Error()
+			return
+		}
+		insecure.Upgradeable = true
+	})
+
+	for _, insecure := range report.Links {
+		if insecure.Upgradeable {
+			report.Upgradeable++
+		}
+	}
+
+	return report
+}
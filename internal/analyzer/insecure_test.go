@@ -0,0 +1,53 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+type upgradeTransport struct {
+	secureHosts map[string]bool
+}
+
+func (u *upgradeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "https" || !u.secureHosts[req.URL.Host] {
+		return nil, fmt.Errorf("connection refused")
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+}
+
+func TestAuditInsecureLinks(t *testing.T) {
+	links := []models.Link{
+		{URL: "https://secure.com/page", Type: models.LinkTypeExternal},
+		{URL: "http://upgradeable.com/page", Type: models.LinkTypeExternal},
+		{URL: "http://legacy.com/page", Type: models.LinkTypeInternal},
+	}
+
+	client := &http.Client{
+		Timeout:   time.Second,
+		Transport: &upgradeTransport{secureHosts: map[string]bool{"upgradeable.com": true}},
+	}
+
+	report := AuditInsecureLinks(context.Background(), links, client, 2)
+
+	if len(report.Links) != 2 {
+		t.Fatalf("Expected 2 insecure links, got %d", len(report.Links))
+	}
+
+	if report.Upgradeable != 1 {
+		t.Errorf("Expected 1 upgradeable link, got %d", report.Upgradeable)
+	}
+
+	if !report.Links[0].Upgradeable || report.Links[0].HTTPSURL != "https://upgradeable.com/page" {
+		t.Errorf("Unexpected upgrade result: %+v", report.Links[0])
+	}
+
+	if report.Links[1].Upgradeable || report.Links[1].UpgradeError == "" {
+		t.Errorf("Expected legacy.com upgrade to fail, got %+v", report.Links[1])
+	}
+}
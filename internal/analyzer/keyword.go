@@ -0,0 +1,125 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"website-analyzer/internal/models"
+)
+
+// Keyword audit locations
+const (
+	KeywordTitle          = "title"
+	KeywordTitleStart     = "title-start"
+	KeywordH1             = "h1"
+	KeywordDescription    = "meta-description"
+	KeywordFirstParagraph = "first-paragraph"
+	KeywordURLSlug        = "url-slug"
+	KeywordImageAlt       = "image-alt"
+)
+
+// keywordEarlyWords is how close to the start of the title the phrase
+// should appear to count as front-loaded
+const keywordEarlyWords = 3
+
+// AuditKeyword reports where a target phrase appears on the page and turns
+// it into a simple optimization checklist
+func AuditKeyword(doc *goquery.Document, pageURL, keyword string) *models.KeywordReport {
+	phrase := keywordWords(keyword)
+	if len(phrase) == 0 {
+		return nil
+	}
+	report := &models.KeywordReport{Keyword: strings.TrimSpace(keyword)}
+
+	add := func(location string, position int, detail string) {
+		report.Checks = append(report.Checks, models.KeywordCheck{
+			Location: location,
+			Found:    position > 0,
+			Position: position,
+			Detail:   detail,
+		})
+	}
+
+	title := strings.TrimSpace(doc.Find("title").First().Text())
+	titlePos := phrasePosition(keywordWords(title), phrase)
+	add(KeywordTitle, titlePos, title)
+	if titlePos > 0 && titlePos <= keywordEarlyWords {
+		add(KeywordTitleStart, titlePos, fmt.Sprintf("starts at word %d", titlePos))
+	} else {
+		add(KeywordTitleStart, 0, fmt.Sprintf("should start within the first %d words", keywordEarlyWords))
+	}
+
+	h1 := strings.Join(strings.Fields(doc.Find("h1").First().Text()), " ")
+	add(KeywordH1, phrasePosition(keywordWords(h1), phrase), h1)
+
+	description := strings.TrimSpace(doc.Find(`meta[name="description" i]`).First().AttrOr("content", ""))
+	add(KeywordDescription, phrasePosition(keywordWords(description), phrase), description)
+
+	paragraph := ""
+	doc.Find("body p").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		paragraph = strings.Join(strings.Fields(s.Text()), " ")
+		return paragraph == ""
+	})
+	add(KeywordFirstParagraph, phrasePosition(keywordWords(paragraph), phrase), truncate(paragraph, 120))
+
+	slug := ""
+	if u, err := url.Parse(pageURL); err == nil {
+		slug = path.Base(strings.TrimSuffix(u.Path, "/"))
+		if slug == "." || slug == "/" {
+			slug = ""
+		}
+	}
+	add(KeywordURLSlug, phrasePosition(keywordWords(slug), phrase), slug)
+
+	images, matching, firstMatch := 0, 0, 0
+	doc.Find("img[alt]").Each(func(i int, s *goquery.Selection) {
+		images++
+		if phrasePosition(keywordWords(s.AttrOr("alt", "")), phrase) > 0 {
+			matching++
+			if firstMatch == 0 {
+				firstMatch = images
+			}
+		}
+	})
+	add(KeywordImageAlt, firstMatch, fmt.Sprintf("%d of %d images with alt text", matching, images))
+
+	for _, check := range report.Checks {
+		if check.Found {
+			report.Passed++
+		}
+	}
+	report.Total = len(report.Checks)
+
+	return report
+}
+
+// keywordWords lowercases text and splits it into words, treating
+// punctuation, hyphens and underscores (as in URL slugs) as separators
+func keywordWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// phrasePosition returns the 1-based word index where phrase starts in
+// words, or 0 when it does not appear
+func phrasePosition(words, phrase []string) int {
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		match := true
+		for j, word := range phrase {
+			if words[i+j] != word {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i + 1
+		}
+	}
+	return 0
+}
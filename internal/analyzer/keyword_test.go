@@ -0,0 +1,59 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestAuditKeyword(t *testing.T) {
+	html := `<html><head>
+		<title>Best Running Shoes for 2024 | Shop</title>
+		<meta name="description" content="Compare trail and road shoes.">
+	</head><body>
+		<h1>Our guide to running-shoes</h1>
+		<p></p>
+		<p>Finding the best running shoes starts with your gait.</p>
+		<img src="a.jpg" alt="Blue running shoes">
+		<img src="b.jpg" alt="Store front">
+		<img src="c.jpg">
+	</body></html>`
+
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+	report := AuditKeyword(doc, "https://example.com/guides/running_shoes/", "Running Shoes")
+
+	expected := map[string]struct {
+		found    bool
+		position int
+	}{
+		KeywordTitle:          {true, 2},
+		KeywordTitleStart:     {true, 2},
+		KeywordH1:             {true, 4},
+		KeywordDescription:    {false, 0},
+		KeywordFirstParagraph: {true, 4},
+		KeywordURLSlug:        {true, 1},
+		KeywordImageAlt:       {true, 1},
+	}
+
+	if report.Total != len(expected) || report.Passed != 6 {
+		t.Errorf("Expected 6/%d passed, got %d/%d", len(expected), report.Passed, report.Total)
+	}
+
+	for _, check := range report.Checks {
+		want := expected[check.Location]
+		if check.Found != want.found || check.Position != want.position {
+			t.Errorf("%s: expected found=%v position=%d, got %+v", check.Location, want.found, want.position, check)
+		}
+		if check.Location == KeywordImageAlt && check.Detail != "1 of 2 images with alt text" {
+			t.Errorf("Unexpected alt detail %q", check.Detail)
+		}
+	}
+}
+
+func TestAuditKeywordEmpty(t *testing.T) {
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader("<html></html>"))
+	if report := AuditKeyword(doc, "https://example.com/", "  "); report != nil {
+		t.Errorf("Expected no report for a blank keyword, got %+v", report)
+	}
+}
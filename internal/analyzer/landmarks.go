@@ -0,0 +1,162 @@
+package analyzer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"website-analyzer/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Landmark and tab order rule identifiers
+const (
+	RuleMissingMain       = "landmark-missing-main"
+	RuleDuplicateLandmark = "landmark-duplicate"
+	RuleUnlabeledLandmark = "landmark-unlabeled-duplicate"
+	RulePositiveTabindex  = "tabindex-positive"
+	// sectioningSelector matches elements that scope header/footer
+	sectioningSelector = "article, aside, main, nav, section"
+)
+
+// landmarkRoles are the ARIA landmarks included in the structure report
+var landmarkRoles = []string{"banner", "navigation", "main", "complementary", "contentinfo", "search", "form", "region"}
+
+// uniqueLandmarks should appear at most once per page
+var uniqueLandmarks = []string{"main", "banner", "contentinfo"}
+
+// checkLandmarks counts landmarks exposed by HTML5 sectioning elements and
+// explicit roles, and reports missing or ambiguous ones
+func checkLandmarks(doc *goquery.Document) (map[string]int, []models.AccessibilityIssue) {
+	var issues []models.AccessibilityIssue
+	counts := make(map[string]int)
+	elements := make(map[string][]*goquery.Selection)
+
+	doc.Find("*").Each(func(i int, s *goquery.Selection) {
+		role := landmarkRole(s)
+		if role == "" || isHiddenFromAT(s) {
+			return
+		}
+		counts[role]++
+		elements[role] = append(elements[role], s)
+	})
+
+	for _, role := range landmarkRoles {
+		if _, ok := counts[role]; !ok {
+			counts[role] = 0
+		}
+	}
+
+	if counts["main"] == 0 {
+		issues = append(issues, models.AccessibilityIssue{
+			Rule:    RuleMissingMain,
+			Element: "<body>",
+			Message: "page has no main landmark (<main> or role=\"main\")",
+		})
+	}
+
+	for _, role := range uniqueLandmarks {
+		if counts[role] > 1 {
+			issues = append(issues, newIssue(RuleDuplicateLandmark, elements[role][1],
+				fmt.Sprintf("%d %s landmarks found; at most one is expected", counts[role], role)))
+		}
+	}
+
+	// Repeated landmarks need distinct labels so screen reader users can
+	// tell them apart
+	for role, list := range elements {
+		if len(list) < 2 || role == "main" || role == "banner" || role == "contentinfo" {
+			continue
+		}
+		for _, s := range list {
+			if landmarkLabel(doc, s) == "" {
+				issues = append(issues, newIssue(RuleUnlabeledLandmark, s,
+					fmt.Sprintf("one of %d %s landmarks has no label", len(list), role)))
+			}
+		}
+	}
+
+	return counts, issues
+}
+
+// landmarkRole returns the landmark an element exposes, if any
+func landmarkRole(s *goquery.Selection) string {
+	if role, ok := s.Attr("role"); ok {
+		primary := strings.ToLower(firstField(role))
+		for _, landmark := range landmarkRoles {
+			if primary == landmark {
+				return primary
+			}
+		}
+		return ""
+	}
+
+	switch goquery.NodeName(s) {
+	case "main":
+		return "main"
+	case "nav":
+		return "navigation"
+	case "aside":
+		return "complementary"
+	case "search":
+		return "search"
+	case "header":
+		// Only page-level headers and footers map to landmarks
+		if s.ParentsFiltered(sectioningSelector).Length() == 0 {
+			return "banner"
+		}
+	case "footer":
+		if s.ParentsFiltered(sectioningSelector).Length() == 0 {
+			return "contentinfo"
+		}
+	case "section":
+		// Sections and forms are landmarks only when named
+		if hasLabelAttr(s) {
+			return "region"
+		}
+	case "form":
+		if hasLabelAttr(s) {
+			return "form"
+		}
+	}
+
+	return ""
+}
+
+func hasLabelAttr(s *goquery.Selection) bool {
+	_, label := s.Attr("aria-label")
+	_, labelledBy := s.Attr("aria-labelledby")
+	return label || labelledBy
+}
+
+func landmarkLabel(doc *goquery.Document, s *goquery.Selection) string {
+	if label, _ := s.Attr("aria-label"); strings.TrimSpace(label) != "" {
+		return label
+	}
+	if ref, ok := s.Attr("aria-labelledby"); ok {
+		return strings.TrimSpace(doc.Find("#" + escapeID(firstField(ref))).Text())
+	}
+	return ""
+}
+
+// checkTabOrder flags positive tabindex values, which override the natural
+// document order
+func checkTabOrder(doc *goquery.Document) (int, []models.AccessibilityIssue) {
+	var issues []models.AccessibilityIssue
+	count := 0
+
+	doc.Find("[tabindex]").Each(func(i int, s *goquery.Selection) {
+		value, _ := s.Attr("tabindex")
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || n <= 0 {
+			return
+		}
+
+		count++
+		issues = append(issues, newIssue(RulePositiveTabindex, s,
+			fmt.Sprintf("tabindex=%d breaks the natural tab order", n)))
+	})
+
+	return count, issues
+}
@@ -0,0 +1,66 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestCheckLandmarks(t *testing.T) {
+	html := `
+		<html><body>
+			<header>Site header</header>
+			<nav aria-label="Primary"></nav>
+			<nav></nav>
+			<main>
+				<article><header>Article header</header></article>
+			</main>
+			<div role="main"></div>
+			<footer>Site footer</footer>
+		</body></html>
+	`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	counts, issues := checkLandmarks(doc)
+
+	expected := map[string]int{"banner": 1, "navigation": 2, "main": 2, "contentinfo": 1, "search": 0}
+	for role, want := range expected {
+		if counts[role] != want {
+			t.Errorf("Expected %d %s landmarks, got %d", want, role, counts[role])
+		}
+	}
+
+	rules := map[string]int{}
+	for _, issue := range issues {
+		rules[issue.Rule]++
+	}
+
+	if rules[RuleDuplicateLandmark] != 1 {
+		t.Errorf("Expected duplicate main to be flagged, got %v", rules)
+	}
+	if rules[RuleUnlabeledLandmark] != 1 {
+		t.Errorf("Expected one unlabeled nav, got %v", rules)
+	}
+	if rules[RuleMissingMain] != 0 {
+		t.Errorf("Main landmark is present but was reported missing")
+	}
+}
+
+func TestCheckTabOrder(t *testing.T) {
+	html := `<a href="/" tabindex="3">A</a><button tabindex="0">B</button><div tabindex="-1"></div><input tabindex="1">`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	count, issues := checkTabOrder(doc)
+	if count != 2 || len(issues) != 2 {
+		t.Errorf("Expected 2 positive tabindex values, got %d (%d issues)", count, len(issues))
+	}
+}
@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"slices"
+
+	"website-analyzer/internal/models"
+)
+
+// defaultSlowestLinks is how many links the slowest-links list keeps when
+// no limit is configured
+const defaultSlowestLinks = 10
+
+// SummarizeLatency reports p50/p95 link check latencies and the top
+// slowest links. Only links that answered count: failures such as
+// timeouts would report the timeout rather than the link's speed, and
+// blocked links were never contacted.
+func SummarizeLatency(statuses []models.LinkStatus, top int) *models.LinkLatencyReport {
+	var working []models.LinkStatus
+	for _, status := range statuses {
+		if !status.Blocked && status.Error == "" {
+			working = append(working, status)
+		}
+	}
+	if len(working) == 0 {
+		return nil
+	}
+
+	slices.SortStableFunc(working, func(a, b models.LinkStatus) int {
+		return int(b.LatencyMs - a.LatencyMs)
+	})
+	latencies := make([]int64, len(working))
+	for i, status := range working {
+		latencies[len(working)-1-i] = status.LatencyMs
+	}
+
+	report := &models.LinkLatencyReport{
+		Measured: len(working),
+		P50Ms:    percentile(latencies, 50),
+		P95Ms:    percentile(latencies, 95),
+		MaxMs:    latencies[len(latencies)-1],
+	}
+	if top <= 0 {
+		top = defaultSlowestLinks
+	}
+	for _, status := range working[:min(top, len(working))] {
+		report.Slowest = append(report.Slowest, models.SlowLink{
+			URL:        status.URL,
+			Type:       status.Type,
+			StatusCode: status.StatusCode,
+			LatencyMs:  status.LatencyMs,
+		})
+	}
+	return report
+}
+
+// percentile returns the nearest-rank p-th percentile of ascending values
+func percentile(sorted []int64, p int) int64 {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
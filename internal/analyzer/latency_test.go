@@ -0,0 +1,45 @@
+package analyzer
+
+import (
+	"fmt"
+	"testing"
+
+	"website-analyzer/internal/models"
+)
+
+func TestSummarizeLatency(t *testing.T) {
+	var statuses []models.LinkStatus
+	for i := 1; i <= 20; i++ {
+		statuses = append(statuses, models.LinkStatus{
+			URL:        fmt.Sprintf("https://example.com/%d", i),
+			Type:       models.LinkTypeInternal,
+			StatusCode: 200,
+			LatencyMs:  int64(i * 10),
+		})
+	}
+	statuses = append(statuses,
+		models.LinkStatus{URL: "https://slow.example.org/timeout", Type: models.LinkTypeExternal, Error: "timeout", LatencyMs: 10000},
+		models.LinkStatus{URL: "https://skipped.example.org/", Type: models.LinkTypeExternal, Error: "skipped", Blocked: true},
+	)
+
+	report := SummarizeLatency(statuses, 3)
+	if report == nil {
+		t.Fatal("Expected a latency report")
+	}
+	if report.Measured != 20 || report.P50Ms != 100 || report.P95Ms != 190 || report.MaxMs != 200 {
+		t.Errorf("Unexpected percentiles: %+v", report)
+	}
+	if len(report.Slowest) != 3 {
+		t.Fatalf("Expected the top 3 slowest links, got %d", len(report.Slowest))
+	}
+	if report.Slowest[0].URL != "https://example.com/20" || report.Slowest[0].LatencyMs != 200 || report.Slowest[2].LatencyMs != 180 {
+		t.Errorf("Unexpected slowest links: %+v", report.Slowest)
+	}
+
+	if got := SummarizeLatency(statuses[20:], 3); got != nil {
+		t.Errorf("Expected no report without working links, got %+v", got)
+	}
+	if got := SummarizeLatency(statuses[:1], 0); got.P50Ms != 10 || got.P95Ms != 10 || len(got.Slowest) != 1 {
+		t.Errorf("Unexpected single-link report: %+v", got)
+	}
+}
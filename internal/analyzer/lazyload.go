@@ -0,0 +1,112 @@
+package analyzer
+
+import (
+	"strconv"
+	"strings"
+
+	"website-analyzer/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	// aboveFoldImages is how many images at the top of the document are
+	// treated as likely above the fold (hero, logo, banner)
+	aboveFoldImages = 2
+	// heavyImageArea is the declared pixel area from which an image is
+	// considered heavy enough to deserve lazy loading
+	heavyImageArea = 400 * 300
+)
+
+// AuditLazyLoading reports how images and iframes use the loading attribute,
+// flagging heavy below-the-fold images loaded eagerly and likely hero images
+// that are lazy-loaded
+func AuditLazyLoading(doc *goquery.Document) *models.LazyLoadReport {
+	report := &models.LazyLoadReport{}
+
+	doc.Find("img").Each(func(i int, s *goquery.Selection) {
+		src := imageSource(s)
+		lazy := isLazy(s)
+		countLoading(&report.Images, lazy)
+
+		if i < aboveFoldImages || isHeroImage(s) {
+			if lazy {
+				report.LazyAboveFold = append(report.LazyAboveFold, src)
+			}
+			return
+		}
+
+		if !lazy && isHeavyImage(s) {
+			report.EagerBelowFold = append(report.EagerBelowFold, src)
+		}
+	})
+
+	doc.Find("iframe").Each(func(i int, s *goquery.Selection) {
+		countLoading(&report.Iframes, isLazy(s))
+	})
+
+	return report
+}
+
+func countLoading(stats *models.LoadingStats, lazy bool) {
+	stats.Total++
+	if lazy {
+		stats.Lazy++
+	} else {
+		stats.Eager++
+	}
+}
+
+// isLazy reports whether the element opts into native lazy loading.
+// A missing loading attribute means eager.
+func isLazy(s *goquery.Selection) bool {
+	loading, _ := s.Attr("loading")
+	return strings.EqualFold(strings.TrimSpace(loading), "lazy")
+}
+
+// isHeroImage uses markup hints to spot images meant to render immediately
+func isHeroImage(s *goquery.Selection) bool {
+	if priority, _ := s.Attr("fetchpriority"); strings.EqualFold(priority, "high") {
+		return true
+	}
+
+	class, _ := s.Attr("class")
+	class = strings.ToLower(class)
+	if strings.Contains(class, "hero") || strings.Contains(class, "banner") {
+		return true
+	}
+
+	return s.ParentsFiltered("header").Length() > 0
+}
+
+// isHeavyImage checks declared dimensions; images without dimensions but
+// with a srcset are assumed to be large responsive images
+func isHeavyImage(s *goquery.Selection) bool {
+	width := intAttr(s, "width")
+	height := intAttr(s, "height")
+	if width > 0 && height > 0 {
+		return width*height >= heavyImageArea
+	}
+
+	_, hasSrcset := s.Attr("srcset")
+	return hasSrcset
+}
+
+func imageSource(s *goquery.Selection) string {
+	if src, ok := s.Attr("src"); ok && src != "" {
+		return src
+	}
+	if src, ok := s.Attr("data-src"); ok {
+		return src
+	}
+	return ""
+}
+
+func intAttr(s *goquery.Selection, name string) int {
+	value, _ := s.Attr(name)
+	n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(value), "px"))
+	if err != nil {
+		return 0
+	}
+	return n
+}
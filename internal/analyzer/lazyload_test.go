@@ -0,0 +1,48 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestAuditLazyLoading(t *testing.T) {
+	html := `
+		<html><body>
+			<header><img src="/logo.png" loading="lazy"></header>
+			<img src="/hero.jpg" class="hero" width="1200" height="600">
+			<p>Content</p>
+			<img src="/big.jpg" width="800" height="600">
+			<img src="/icon.png" width="16" height="16">
+			<img src="/lazy.jpg" width="800" height="600" loading="lazy">
+			<img src="/responsive.jpg" srcset="/r-480.jpg 480w, /r-960.jpg 960w">
+			<iframe src="https://video.example.com" loading="lazy"></iframe>
+			<iframe src="https://map.example.com"></iframe>
+		</body></html>
+	`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	report := AuditLazyLoading(doc)
+
+	if report.Images.Total != 6 || report.Images.Lazy != 2 || report.Images.Eager != 4 {
+		t.Errorf("Unexpected image stats: %+v", report.Images)
+	}
+
+	if report.Iframes.Total != 2 || report.Iframes.Lazy != 1 {
+		t.Errorf("Unexpected iframe stats: %+v", report.Iframes)
+	}
+
+	expectedEager := []string{"/big.jpg", "/responsive.jpg"}
+	if strings.Join(report.EagerBelowFold, ",") != strings.Join(expectedEager, ",") {
+		t.Errorf("Expected eager below fold %v, got %v", expectedEager, report.EagerBelowFold)
+	}
+
+	if len(report.LazyAboveFold) != 1 || report.LazyAboveFold[0] != "/logo.png" {
+		t.Errorf("Expected lazy above fold [/logo.png], got %v", report.LazyAboveFold)
+	}
+}
@@ -10,12 +10,15 @@ import (
 	"github.com/PuerkitoBio/goquery"
 )
 
-// ExtractLinks finds all <a href> tags and returns their URLs
-func ExtractLinks(doc *goquery.Document, baseURL string) ([]models.Link, error) {
-	base, err := url.Parse(baseURL)
+// ExtractLinks finds all <a href> tags and returns their URLs, resolved
+// against the document's base URL and classified as internal or external
+// to baseURL by scope
+func ExtractLinks(doc *goquery.Document, baseURL string, scope LinkScope) ([]models.Link, error) {
+	page, err := url.Parse(baseURL)
 	if err != nil {
 		return nil, fmt.Errorf("invalid base URL: %w", err)
 	}
+	base := documentBase(doc, page)
 
 	var links []models.Link
 	seen := make(map[string]bool) // Deduplicate
@@ -39,17 +42,80 @@ func ExtractLinks(doc *goquery.Document, baseURL string) ([]models.Link, error)
 		seen[resolved] = true
 
 		// Classify link
-		linkType := classifyLink(resolved, base)
+		linkType := classifyLink(resolved, page, scope)
+
+		rel, _ := s.Attr("rel")
+		target, _ := s.Attr("target")
 
 		links = append(links, models.Link{
-			URL:  resolved,
-			Type: linkType,
+			URL:    resolved,
+			Type:   linkType,
+			Rel:    strings.Fields(strings.ToLower(rel)),
+			Text:   anchorText(s),
+			Target: strings.TrimSpace(target),
 		})
 	})
 
 	return links, nil
 }
 
+// ExtractInlineLinks finds the anchors whose href is a data: or blob: URL,
+// which ExtractLinks leaves out since there is nothing to fetch
+func ExtractInlineLinks(doc *goquery.Document) []models.InlineLink {
+	var inline []models.InlineLink
+	doc.Find("a[href]").Each(func(i int, s *goquery.Selection) {
+		href := strings.TrimSpace(s.AttrOr("href", ""))
+		scheme, _, ok := strings.Cut(href, ":")
+		if !ok {
+			return
+		}
+		link := models.InlineLink{Scheme: strings.ToLower(scheme), URL: href, Text: anchorText(s)}
+		switch link.Scheme {
+		case "data":
+			parsed := parseDataURI(href)
+			link.URL = "data:" + parsed.MediaType
+			link.Size = parsed.DecodedSize
+		case "blob":
+		default:
+			return
+		}
+		inline = append(inline, link)
+	})
+	return inline
+}
+
+// anchorText is a link's visible text with whitespace collapsed, or for a
+// link without any, its aria-label or the alt text of its image
+func anchorText(s *goquery.Selection) string {
+	if text := strings.Join(strings.Fields(s.Text()), " "); text != "" {
+		return text
+	}
+	if label, ok := s.Attr("aria-label"); ok && strings.TrimSpace(label) != "" {
+		return strings.TrimSpace(label)
+	}
+	alt, _ := s.Find("img[alt]").First().Attr("alt")
+	return strings.TrimSpace(alt)
+}
+
+// documentBase returns the URL relative links of the page at pageURL
+// resolve against: the href of its first <base> element, itself resolved
+// against pageURL, or pageURL when there is none or it isn't http(s)
+func documentBase(doc *goquery.Document, pageURL *url.URL) *url.URL {
+	href, ok := doc.Find("base[href]").First().Attr("href")
+	if !ok {
+		return pageURL
+	}
+	parsed, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return pageURL
+	}
+	base := pageURL.ResolveReference(parsed)
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return pageURL
+	}
+	return base
+}
+
 // resolveURL converts relative URLs to absolute
 func resolveURL(base *url.URL, href string) (string, error) {
 	href = strings.TrimSpace(href)
@@ -81,16 +147,38 @@ func resolveURL(base *url.URL, href string) (string, error) {
 }
 
 // classifyLink determines if a link is internal or external
-func classifyLink(link string, base *url.URL) models.LinkType {
+func classifyLink(link string, base *url.URL, scope LinkScope) models.LinkType {
 	parsed, err := url.Parse(link)
 	if err != nil {
 		return models.LinkTypeInvalid
 	}
 
-	// Same host (including subdomains) = internal
-	if parsed.Host == base.Host {
+	if scope.internal(parsed, base) {
 		return models.LinkTypeInternal
 	}
 
 	return models.LinkTypeExternal
 }
+
+// linkDetails pairs each link with its check outcome when include is set
+func linkDetails(links []models.Link, statuses []models.LinkStatus, include bool) []models.LinkDetail {
+	if !include {
+		return nil
+	}
+	checked := make(map[string]models.LinkStatus, len(statuses))
+	for _, status := range statuses {
+		checked[status.URL] = status
+	}
+
+	details := make([]models.LinkDetail, len(links))
+	for i, link := range links {
+		details[i].Link = link
+		if status, ok := checked[link.URL]; ok {
+			details[i].Checked = true
+			details[i].StatusCode = status.StatusCode
+			details[i].Error = status.Error
+			details[i].LatencyMs = status.LatencyMs
+		}
+	}
+	return details
+}
@@ -2,6 +2,7 @@ package analyzer
 
 import (
 	"net/url"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -67,7 +68,7 @@ func TestExtractLinks(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			doc, _ := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
-			links, err := ExtractLinks(doc, tt.baseURL)
+			links, err := ExtractLinks(doc, tt.baseURL, LinkScope{})
 
 			if err != nil {
 				t.Fatalf("ExtractLinks failed: %v", err)
@@ -97,6 +98,117 @@ func TestExtractLinks(t *testing.T) {
 	}
 }
 
+func TestExtractLinkAttributes(t *testing.T) {
+	html := `<html><body>
+		<a href="/about" target="_blank" rel="noopener NoFollow">  About
+			us </a>
+		<a href="https://ads.example.net" rel="sponsored" aria-label="Partner offer"></a>
+		<a href="/home"><img src="/logo.png" alt="Home"></a>
+	</body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	links, err := ExtractLinks(doc, "https://example.com", LinkScope{})
+	if err != nil || len(links) != 3 {
+		t.Fatalf("Expected three links, got %+v (%v)", links, err)
+	}
+
+	about := links[0]
+	if about.Text != "About us" || about.Target != "_blank" || strings.Join(about.Rel, " ") != "noopener nofollow" {
+		t.Errorf("Unexpected attributes %+v", about)
+	}
+	if links[1].Text != "Partner offer" || links[1].Rel[0] != "sponsored" {
+		t.Errorf("Expected the aria-label as text, got %+v", links[1])
+	}
+	if links[2].Text != "Home" {
+		t.Errorf("Expected the image alt as text, got %+v", links[2])
+	}
+
+	statuses := []models.LinkStatus{
+		{URL: about.URL, StatusCode: 200},
+		{URL: links[1].URL, Error: "timeout"},
+	}
+	if details := linkDetails(links, statuses, false); details != nil {
+		t.Errorf("Expected no details unless requested, got %+v", details)
+	}
+	details := linkDetails(links, statuses, true)
+	if len(details) != 3 || details[0].StatusCode != 200 || details[0].Text != "About us" || !details[1].Checked || details[1].Error != "timeout" {
+		t.Errorf("Expected the check outcomes on the details, got %+v", details)
+	}
+	if details[2].Checked {
+		t.Errorf("Expected the unchecked link to say so, got %+v", details[2])
+	}
+}
+
+func TestExtractInlineLinks(t *testing.T) {
+	html := `<html><body>
+		<a href="/page">Page</a>
+		<a href="data:text/csv;base64,YSxiCjEsMgo=" download="table.csv">Download CSV</a>
+		<a href="DATA:,hello">Hello</a>
+		<a href="blob:https://example.com/550e8400-e29b-41d4-a716-446655440000">Export</a>
+		<a href="javascript:void(0)">Menu</a>
+	</body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []models.InlineLink{
+		{Scheme: "data", URL: "data:text/csv", Size: 8, Text: "Download CSV"},
+		{Scheme: "data", URL: "data:text/plain", Size: 5, Text: "Hello"},
+		{Scheme: "blob", URL: "blob:https://example.com/550e8400-e29b-41d4-a716-446655440000", Text: "Export"},
+	}
+	got := ExtractInlineLinks(doc)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+
+	links, _ := ExtractLinks(doc, "https://example.com/", LinkScope{})
+	if len(links) != 1 {
+		t.Errorf("Expected inline links to stay out of the extracted links, got %+v", links)
+	}
+}
+
+func TestExtractLinksBaseHref(t *testing.T) {
+	tests := []struct {
+		name string
+		head string
+		want []string
+	}{
+		{"no base", "", []string{"https://example.com/docs/guide/intro", "https://example.com/top"}},
+		{"absolute base", `<base href="https://cdn.example.net/v2/">`, []string{"https://cdn.example.net/v2/intro", "https://example.com/top"}},
+		{"relative base", `<base href="/manual/"><base href="/ignored/">`, []string{"https://example.com/manual/intro", "https://example.com/top"}},
+		{"target only", `<base target="_blank">`, []string{"https://example.com/docs/guide/intro", "https://example.com/top"}},
+		{"unsupported scheme", `<base href="ftp://files.example.com/">`, []string{"https://example.com/docs/guide/intro", "https://example.com/top"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html := `<html><head>` + tt.head + `</head><body><a href="intro">Intro</a><a href="https://example.com/top">Top</a></body></html>`
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+			if err != nil {
+				t.Fatal(err)
+			}
+			links, err := ExtractLinks(doc, "https://example.com/docs/guide/", LinkScope{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, link := range links {
+				got = append(got, link.URL)
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+			// Links are classified against the page, not the base
+			if links[1].Type != models.LinkTypeInternal {
+				t.Errorf("Expected %s to be internal, got %s", links[1].URL, links[1].Type)
+			}
+		})
+	}
+}
+
 func TestResolveURL(t *testing.T) {
 	baseURL := mustParseURL("https://example.com/path/page.html")
 
@@ -150,7 +262,7 @@ func TestClassifyLink(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := classifyLink(tt.link, baseURL)
+			result := classifyLink(tt.link, baseURL, LinkScope{})
 			if result != tt.expected {
 				t.Errorf("Expected %s, got %s", tt.expected, result)
 			}
@@ -158,6 +270,53 @@ func TestClassifyLink(t *testing.T) {
 	}
 }
 
+func TestClassifyLinkScopes(t *testing.T) {
+	baseURL := mustParseURL("https://www.example.co.uk")
+
+	tests := []struct {
+		scope    string
+		link     string
+		expected models.LinkType
+	}{
+		{ScopeExactHost, "https://www.example.co.uk/about", models.LinkTypeInternal},
+		{ScopeExactHost, "https://blog.example.co.uk/", models.LinkTypeExternal},
+		{ScopeRegistrableDomain, "https://blog.example.co.uk/", models.LinkTypeInternal},
+		{ScopeRegistrableDomain, "https://example.co.uk/", models.LinkTypeInternal},
+		{ScopeRegistrableDomain, "https://other.co.uk/", models.LinkTypeExternal},
+		{ScopeRegistrableDomain, "https://www.example.com/", models.LinkTypeExternal},
+		{"*.example.co.uk, cdn.example.net", "https://shop.example.co.uk/", models.LinkTypeInternal},
+		{"*.example.co.uk, cdn.example.net", "https://CDN.example.net/app.js", models.LinkTypeInternal},
+		{"*.example.co.uk, cdn.example.net", "https://example.net/", models.LinkTypeExternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scope+" "+tt.link, func(t *testing.T) {
+			scope, err := ParseLinkScope(tt.scope)
+			if err != nil {
+				t.Fatalf("ParseLinkScope failed: %v", err)
+			}
+			if result := classifyLink(tt.link, baseURL, scope); result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+
+	// Public suffixes are domains of their own
+	scope, _ := ParseLinkScope(ScopeRegistrableDomain)
+	if result := classifyLink("https://other.github.io/", mustParseURL("https://me.github.io/"), scope); result != models.LinkTypeExternal {
+		t.Errorf("Expected sites on a shared public suffix to be external, got %s", result)
+	}
+	if result := classifyLink("http://127.0.0.1:8081/", mustParseURL("http://127.0.0.1:8080/"), scope); result != models.LinkTypeExternal {
+		t.Errorf("Expected another port of an address to be external, got %s", result)
+	}
+
+	for _, spec := range []string{"https://example.com", "example.com:8080", "*."} {
+		if _, err := ParseLinkScope(spec); err == nil {
+			t.Errorf("Expected link scope %q to be rejected", spec)
+		}
+	}
+}
+
 // Helper
 func mustParseURL(s string) *url.URL {
 	u, _ := url.Parse(s)
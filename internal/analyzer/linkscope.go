@@ -0,0 +1,97 @@
+package analyzer
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// Link scope policies deciding which links count as internal
+const (
+	// ScopeExactHost treats only links to the page's own host and port as
+	// internal, so blog.example.com is external to example.com
+	ScopeExactHost = "exact-host"
+	// ScopeRegistrableDomain treats links within the page's registrable
+	// domain (per the public suffix list) as internal, so
+	// blog.example.com is internal to www.example.com but
+	// other.github.io is external to me.github.io
+	ScopeRegistrableDomain = "same-registrable-domain"
+)
+
+// LinkScope decides which links of a page are internal. The zero value is
+// the exact-host policy.
+type LinkScope struct {
+	policy string
+	// Custom scopes treat the page's own host and these as internal
+	hosts     map[string]bool
+	wildcards []string // ".example.com" for "*.example.com"
+}
+
+// ParseLinkScope reads a policy name, or a comma-separated list of host
+// patterns: exact hosts, or "*.example.com" for its subdomains. An empty
+// spec is the exact-host policy.
+func ParseLinkScope(spec string) (LinkScope, error) {
+	spec = strings.TrimSpace(spec)
+	switch spec {
+	case "", ScopeExactHost:
+		return LinkScope{policy: ScopeExactHost}, nil
+	case ScopeRegistrableDomain:
+		return LinkScope{policy: ScopeRegistrableDomain}, nil
+	}
+
+	scope := LinkScope{policy: "custom", hosts: make(map[string]bool)}
+	for _, pattern := range strings.Split(spec, ",") {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		host, wildcard := strings.CutPrefix(pattern, "*.")
+		if host == "" || strings.ContainsAny(host, "/:*@ ") {
+			return LinkScope{}, fmt.Errorf("link scope %q must be %s, %s or host patterns such as *.example.com", pattern, ScopeExactHost, ScopeRegistrableDomain)
+		}
+		if wildcard {
+			scope.wildcards = append(scope.wildcards, "."+host)
+		} else {
+			scope.hosts[host] = true
+		}
+	}
+	return scope, nil
+}
+
+// internal reports whether link is internal to a page at base. A link to
+// the page's own host and port always is.
+func (s LinkScope) internal(link, base *url.URL) bool {
+	if link.Host == base.Host {
+		return true
+	}
+	host := strings.ToLower(link.Hostname())
+	switch s.policy {
+	case ScopeRegistrableDomain:
+		linkDomain, ok := registrableDomain(host)
+		baseDomain, baseOK := registrableDomain(strings.ToLower(base.Hostname()))
+		return ok && baseOK && linkDomain == baseDomain
+	case "custom":
+		if s.hosts[host] {
+			return true
+		}
+		for _, suffix := range s.wildcards {
+			if strings.HasSuffix(host, suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// registrableDomain returns the public suffix of host plus one label.
+// Addresses and single-label hosts such as localhost have none.
+func registrableDomain(host string) (string, bool) {
+	if net.ParseIP(host) != nil {
+		return "", false
+	}
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	return domain, err == nil
+}
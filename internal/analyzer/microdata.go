@@ -0,0 +1,104 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// microdataItems extracts the top-level itemscope items of the page as
+// JSON-LD style entities, with itemtype as @type and nested items as
+// objects, so they can be validated the same way. It also reports itemprop
+// attributes that belong to no item.
+func microdataItems(doc *goquery.Document) ([]map[string]any, []string) {
+	var (
+		items  []map[string]any
+		errors []string
+	)
+
+	doc.Find("[itemscope]").Each(func(i int, s *goquery.Selection) {
+		// Items that are a property of another item are parsed with it
+		if _, isProp := s.Attr("itemprop"); isProp && s.Parent().Closest("[itemscope]").Length() > 0 {
+			return
+		}
+		item := microdataItem(s)
+		if _, typed := item["@type"]; !typed {
+			errors = append(errors, fmt.Sprintf("Microdata item %d has no itemtype", len(items)+1))
+		}
+		items = append(items, item)
+	})
+
+	doc.Find("[itemprop]").Each(func(i int, s *goquery.Selection) {
+		if s.Parent().Closest("[itemscope]").Length() == 0 {
+			errors = append(errors, fmt.Sprintf("Microdata property %q is outside any itemscope", s.AttrOr("itemprop", "")))
+		}
+	})
+
+	return items, errors
+}
+
+// microdataItem collects the properties whose nearest enclosing item is s
+func microdataItem(s *goquery.Selection) map[string]any {
+	item := make(map[string]any)
+	if types := strings.Fields(s.AttrOr("itemtype", "")); len(types) == 1 {
+		item["@type"] = types[0]
+	} else if len(types) > 1 {
+		values := make([]any, len(types))
+		for i, t := range types {
+			values[i] = t
+		}
+		item["@type"] = values
+	}
+
+	scope := s.Get(0)
+	s.Find("[itemprop]").Each(func(i int, prop *goquery.Selection) {
+		if owner := prop.Parent().Closest("[itemscope]"); owner.Length() == 0 || owner.Get(0) != scope {
+			return
+		}
+		value := microdataValue(prop)
+		for _, name := range strings.Fields(prop.AttrOr("itemprop", "")) {
+			switch existing := item[name].(type) {
+			case nil:
+				item[name] = value
+			case []any:
+				item[name] = append(existing, value)
+			default:
+				item[name] = []any{existing, value}
+			}
+		}
+	})
+
+	return item
+}
+
+// microdataValue returns a property's value following the HTML microdata
+// rules: nested items, URL attributes for embedding and linking elements,
+// content, value and datetime attributes, and text otherwise
+func microdataValue(prop *goquery.Selection) any {
+	if _, nested := prop.Attr("itemscope"); nested {
+		return microdataItem(prop)
+	}
+
+	var attr string
+	switch goquery.NodeName(prop) {
+	case "meta":
+		attr = "content"
+	case "audio", "embed", "iframe", "img", "source", "track", "video":
+		attr = "src"
+	case "a", "area", "link":
+		attr = "href"
+	case "object":
+		attr = "data"
+	case "data", "meter":
+		attr = "value"
+	case "time":
+		if value, ok := prop.Attr("datetime"); ok {
+			return value
+		}
+	}
+	if attr != "" {
+		return prop.AttrOr(attr, "")
+	}
+	return strings.TrimSpace(prop.Text())
+}
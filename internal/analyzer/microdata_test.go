@@ -0,0 +1,104 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestAnalyzeStructuredDataMicrodata(t *testing.T) {
+	html := `
+		<html><head>
+			<script type="application/ld+json">{"@context": "https://schema.org", "@type": "Organization", "name": "Acme", "url": "https://acme.test", "logo": "https://acme.test/logo.png"}</script>
+		</head><body>
+			<div itemscope itemtype="https://schema.org/Product">
+				<h1 itemprop="name">Widget</h1>
+				<img itemprop="image" src="/widget.png">
+				<div itemprop="offers" itemscope itemtype="https://schema.org/Offer">
+					<meta itemprop="priceCurrency" content="EUR">
+					<span itemprop="price">9.99</span>
+				</div>
+				<div itemprop="brand" itemscope itemtype="https://schema.org/Brand">
+					<span itemprop="name">Acme</span>
+				</div>
+			</div>
+			<div itemscope itemtype="https://schema.org/Event">
+				<span itemprop="name">Launch</span>
+				<time itemprop="startDate" datetime="2026-11-01">November 1st</time>
+			</div>
+			<div itemscope><span itemprop="name">Untyped</span></div>
+			<span itemprop="orphan">Stray</span>
+		</body></html>
+	`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	report := AnalyzeStructuredData(doc)
+
+	if len(report.Entities) != 4 {
+		t.Fatalf("Expected 1 JSON-LD and 3 microdata entities, got %+v", report.Entities)
+	}
+	if got := strings.Join(report.Types, ","); got != "Event,Organization,Product" {
+		t.Errorf("Expected sorted distinct types, got %q", got)
+	}
+
+	product := report.Entities[1]
+	if product.Type != "Product" || product.Format != FormatMicrodata || !product.Validated {
+		t.Fatalf("Expected a validated microdata product, got %+v", product)
+	}
+	if len(product.MissingRequired) != 0 {
+		t.Errorf("Expected nested offer to satisfy the product, got %+v", product.MissingRequired)
+	}
+	if got := strings.Join(product.MissingRecommended, ","); got != "description,sku" {
+		t.Errorf("Expected only description and sku to be missing, got %q", got)
+	}
+
+	event := report.Entities[2]
+	if got := strings.Join(event.MissingRequired, ","); got != "location" {
+		t.Errorf("Expected the event to miss its location, got %+v", event)
+	}
+
+	if report.Entities[3].Type != "unknown" {
+		t.Errorf("Expected the untyped item to be unknown, got %+v", report.Entities[3])
+	}
+	if len(report.Errors) != 2 {
+		t.Errorf("Expected missing itemtype and orphan property errors, got %v", report.Errors)
+	}
+}
+
+func TestMicrodataValue(t *testing.T) {
+	html := `
+		<div itemscope>
+			<a itemprop="url" href="https://example.com/">Example</a>
+			<meta itemprop="sku" content="W-1">
+			<data itemprop="gtin" value="0123">GTIN</data>
+			<time itemprop="date">2026-10-17</time>
+			<span itemprop="tag">one</span>
+			<span itemprop="tag keyword">two</span>
+		</div>
+	`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	items, _ := microdataItems(doc)
+	if len(items) != 1 {
+		t.Fatalf("Expected one item, got %d", len(items))
+	}
+
+	item := items[0]
+	for name, want := range map[string]string{"url": "https://example.com/", "sku": "W-1", "gtin": "0123", "date": "2026-10-17", "keyword": "two"} {
+		if item[name] != want {
+			t.Errorf("Expected %s to be %q, got %v", name, want, item[name])
+		}
+	}
+	if tags, ok := item["tag"].([]any); !ok || len(tags) != 2 {
+		t.Errorf("Expected repeated properties to collect into a list, got %v", item["tag"])
+	}
+}
@@ -0,0 +1,34 @@
+package analyzer
+
+import (
+	"context"
+	"sync"
+)
+
+// runLimited calls fn for every index in [0, n) using at most maxWorkers
+// goroutines and waits for all of them to finish. The goroutines count
+// towards the usage of the run metered in ctx.
+func runLimited(ctx context.Context, n, maxWorkers int, fn func(i int)) {
+	if maxWorkers < 1 {
+		maxWorkers = 1
+	}
+
+	sem := make(chan struct{}, maxWorkers)
+	var wg sync.WaitGroup
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			m := meterFrom(ctx)
+			m.enter()
+			defer m.leave()
+			fn(i)
+		}(i)
+	}
+
+	wg.Wait()
+}
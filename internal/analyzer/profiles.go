@@ -0,0 +1,248 @@
+package analyzer
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Check names a group of analyses that a profile can enable
+type Check string
+
+const (
+	LinksCheck          Check = "links"
+	AccessibilityCheck  Check = "accessibility"
+	ContrastCheck       Check = "contrast"
+	LazyLoadCheck       Check = "lazyload"
+	DataURICheck        Check = "datauri"
+	ImagesCheck         Check = "images"
+	DocumentsCheck      Check = "documents"
+	RelCheck            Check = "rel"
+	InsecureCheck       Check = "insecure"
+	StructuredDataCheck Check = "structured_data"
+	FeedsCheck          Check = "feeds"
+	SEOCheck            Check = "seo"
+	SocialCheck         Check = "social"
+	ReadinessCheck      Check = "readiness"
+	HreflangCheck       Check = "hreflang"
+	SiteCheck           Check = "site"
+	ResourcesCheck      Check = "resources"
+	FragmentsCheck      Check = "fragments"
+	WeightCheck         Check = "weight"
+	CachingCheck        Check = "caching"
+	TechnologiesCheck   Check = "technologies"
+	ThirdPartiesCheck   Check = "third_parties"
+	TrackersCheck       Check = "trackers"
+	IconsCheck          Check = "icons"
+)
+
+// AllChecks lists every check a profile may name
+var AllChecks = []Check{
+	LinksCheck, AccessibilityCheck, ContrastCheck, LazyLoadCheck, DataURICheck,
+	ImagesCheck, DocumentsCheck, RelCheck, InsecureCheck, StructuredDataCheck,
+	FeedsCheck, SEOCheck, SocialCheck, ReadinessCheck, HreflangCheck, SiteCheck,
+	ResourcesCheck, FragmentsCheck, WeightCheck, CachingCheck, TechnologiesCheck,
+	ThirdPartiesCheck, TrackersCheck, IconsCheck,
+}
+
+// DefaultProfileName is used when a request does not name a profile
+const DefaultProfileName = "standard"
+
+// Duration is a time.Duration written as a string ("3s") in profile files
+// and configuration bundles
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalYAML() (any, error) {
+	return time.Duration(d).String(), nil
+}
+
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	var s string
+	if err := node.Decode(&s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Profile bundles the checks and limits of one kind of analysis. Zero
+// limits fall back to the analyzer configuration; no checks means all.
+type Profile struct {
+	Name            string   `json:"name" yaml:"name"`
+	Description     string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Checks          []Check  `json:"checks,omitempty" yaml:"checks,omitempty"`
+	DeepAnalysis    bool     `json:"deep_analysis" yaml:"deep_analysis"`
+	SitemapAnalysis bool     `json:"sitemap_analysis" yaml:"sitemap_analysis"`
+	MaxLinks        int      `json:"max_links,omitempty" yaml:"max_links,omitempty"`
+	MaxWorkers      int      `json:"max_workers,omitempty" yaml:"max_workers,omitempty"`
+	LinkTimeout     Duration `json:"link_timeout,omitempty" yaml:"link_timeout,omitempty"`
+	// LinkScope overrides the server's link scope, see ParseLinkScope
+	LinkScope string `json:"link_scope,omitempty" yaml:"link_scope,omitempty"`
+}
+
+// Enabled reports whether the profile runs the given check
+func (p Profile) Enabled(check Check) bool {
+	return len(p.Checks) == 0 || slices.Contains(p.Checks, check)
+}
+
+// DefaultProfiles returns the built-in profiles. The standard profile
+// follows the deep and sitemap settings of cfg.
+func DefaultProfiles(cfg *Config) map[string]Profile {
+	profiles := []Profile{
+		{
+			Name:        "quick",
+			Description: "Core page checks with a small, fast link sample",
+			Checks:      []Check{LinksCheck, SEOCheck, ReadinessCheck, AccessibilityCheck},
+			MaxLinks:    25,
+			LinkTimeout: Duration(3 * time.Second),
+		},
+		{
+			Name:            DefaultProfileName,
+			Description:     "All checks using the server defaults",
+			DeepAnalysis:    cfg.DeepAnalysis,
+			SitemapAnalysis: cfg.SitemapAnalysis,
+		},
+		{
+			Name:            "deep",
+			Description:     "All checks including resource fetching and sitemaps",
+			DeepAnalysis:    true,
+			SitemapAnalysis: true,
+		},
+		{
+			Name:            "seo-only",
+			Description:     "Search metadata, structured data and sitemaps without link checks",
+			Checks:          []Check{SEOCheck, SocialCheck, StructuredDataCheck, HreflangCheck, ReadinessCheck, SiteCheck},
+			SitemapAnalysis: true,
+		},
+		{
+			Name:        "security-only",
+			Description: "Broken, insecure and unsafe links plus leaked errors",
+			Checks:      []Check{LinksCheck, InsecureCheck, RelCheck, ReadinessCheck},
+		},
+	}
+
+	byName := make(map[string]Profile, len(profiles))
+	for _, p := range profiles {
+		byName[p.Name] = p
+	}
+	return byName
+}
+
+// LoadProfiles reads a JSON array of profiles from path and merges them
+// over base, replacing profiles with the same name
+func LoadProfiles(path string, base map[string]Profile) (map[string]Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profiles: %w", err)
+	}
+
+	var loaded []Profile
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return nil, fmt.Errorf("invalid profiles file: %w", err)
+	}
+
+	merged := make(map[string]Profile, len(base)+len(loaded))
+	for name, p := range base {
+		merged[name] = p
+	}
+	for _, p := range loaded {
+		if p.Name == "" {
+			return nil, fmt.Errorf("invalid profiles file: profile without a name")
+		}
+		for _, check := range p.Checks {
+			if !slices.Contains(AllChecks, check) {
+				return nil, fmt.Errorf("invalid profiles file: profile %q has unknown check %q", p.Name, check)
+			}
+		}
+		if p.LinkTimeout < 0 {
+			return nil, fmt.Errorf("invalid profiles file: profile %q has a negative link_timeout", p.Name)
+		}
+		if _, err := ParseLinkScope(p.LinkScope); err != nil {
+			return nil, fmt.Errorf("invalid profiles file: profile %q: %w", p.Name, err)
+		}
+		merged[p.Name] = p
+	}
+
+	return merged, nil
+}
+
+// Profiles returns the configured profiles sorted by name
+func (a *Analyzer) Profiles() []Profile {
+	profiles := make([]Profile, 0, len(a.config.Profiles))
+	for _, p := range a.config.Profiles {
+		profiles = append(profiles, p)
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+	return profiles
+}
+
+// DefaultProfile returns the name of the profile used when none is given
+func (a *Analyzer) DefaultProfile() string {
+	if a.config.DefaultProfile != "" {
+		return a.config.DefaultProfile
+	}
+	return DefaultProfileName
+}
+
+// linkTimeout returns the link check timeout of profile, falling back to
+// the server's. A link check never waits longer than the page fetch it
+// belongs to, so a longer profile timeout is clamped to RequestTimeout.
+func (a *Analyzer) linkTimeout(p Profile) time.Duration {
+	timeout := a.config.LinkTimeout
+	if p.LinkTimeout > 0 {
+		timeout = time.Duration(p.LinkTimeout)
+	}
+	if a.config.RequestTimeout > 0 {
+		timeout = min(timeout, a.config.RequestTimeout)
+	}
+	return timeout
+}
+
+// linkScope returns the link scope of profile, falling back to the
+// server's. Both are validated when loaded, so a scope that doesn't parse
+// is the exact-host default.
+func (a *Analyzer) linkScope(p Profile) LinkScope {
+	scope, _ := ParseLinkScope(cmp.Or(p.LinkScope, a.config.LinkScope))
+	return scope
+}
+
+// profile resolves a profile name, using the default for an empty name
+func (a *Analyzer) profile(name string) (Profile, error) {
+	if name == "" {
+		name = a.DefaultProfile()
+	}
+	p, ok := a.config.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown profile %q", name)
+	}
+	return p, nil
+}
@@ -0,0 +1,151 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoadProfiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "profiles.json")
+	content := `[
+		{"name": "quick", "checks": ["links"], "max_links": 5, "link_timeout": "2s"},
+		{"name": "custom", "checks": ["seo", "readiness"], "deep_analysis": true}
+	]`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	profiles, err := LoadProfiles(path, DefaultProfiles(&Config{}))
+	if err != nil {
+		t.Fatalf("LoadProfiles failed: %v", err)
+	}
+
+	quick := profiles["quick"]
+	if quick.MaxLinks != 5 || time.Duration(quick.LinkTimeout) != 2*time.Second || !quick.Enabled(LinksCheck) || quick.Enabled(SEOCheck) {
+		t.Errorf("Expected quick profile to be overridden, got %+v", quick)
+	}
+	if custom, ok := profiles["custom"]; !ok || !custom.DeepAnalysis {
+		t.Errorf("Expected custom profile to be added, got %+v", custom)
+	}
+	if _, ok := profiles["security-only"]; !ok {
+		t.Error("Expected built-in profiles to be kept")
+	}
+
+	if err := os.WriteFile(path, []byte(`[{"name": "bad", "checks": ["telepathy"]}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadProfiles(path, nil); err == nil || !strings.Contains(err.Error(), "telepathy") {
+		t.Errorf("Expected unknown check error, got %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(`[{"name": "site", "link_scope": "same-registrable-domain"}, {"name": "bad", "link_scope": "https://example.com"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadProfiles(path, nil); err == nil || !strings.Contains(err.Error(), `"bad"`) {
+		t.Errorf("Expected invalid link scope error, got %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(`[{"name": "bad", "link_timeout": "-1s"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadProfiles(path, nil); err == nil || !strings.Contains(err.Error(), "link_timeout") {
+		t.Errorf("Expected negative link timeout error, got %v", err)
+	}
+}
+
+func TestLinkTimeoutClamped(t *testing.T) {
+	a := NewAnalyzer(&Config{RequestTimeout: 10 * time.Second, LinkTimeout: 5 * time.Second})
+
+	if got := a.linkTimeout(Profile{}); got != 5*time.Second {
+		t.Errorf("Expected the server's link timeout, got %s", got)
+	}
+	if got := a.linkTimeout(Profile{LinkTimeout: Duration(2 * time.Second)}); got != 2*time.Second {
+		t.Errorf("Expected the profile's link timeout, got %s", got)
+	}
+	if got := a.linkTimeout(Profile{LinkTimeout: Duration(time.Minute)}); got != 10*time.Second {
+		t.Errorf("Expected the profile's link timeout to be clamped to the request timeout, got %s", got)
+	}
+}
+
+func TestAnalyzeWithProfile(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	var linkChecks atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			linkChecks.Add(1)
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>Profiled</title><meta name="description" content="d"></head>
+			<body><h1>Hi</h1><a href="/a">A</a><a href="/b">B</a></body></html>`))
+	}))
+	defer ts.Close()
+
+	a := NewAnalyzer(&Config{
+		RequestTimeout:  2 * time.Second,
+		LinkTimeout:     time.Second,
+		MaxWorkers:      1,
+		MaxResponseSize: 1024 * 1024,
+		MaxURLLength:    2048,
+		MaxRedirects:    5,
+	})
+
+	result, err := a.AnalyzeWithOptions(context.Background(), ts.URL, AnalyzeOptions{Profile: "seo-only"})
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if result.Profile != "seo-only" || result.SEO == nil || result.Accessibility != nil || result.InsecureLinks != nil {
+		t.Errorf("Unexpected seo-only result: profile=%q seo=%v a11y=%v", result.Profile, result.SEO != nil, result.Accessibility != nil)
+	}
+	if n := linkChecks.Load(); n != 0 {
+		t.Errorf("Expected no link checks for seo-only, got %d", n)
+	}
+
+	if _, err := a.AnalyzeWithOptions(context.Background(), ts.URL, AnalyzeOptions{Profile: "missing"}); err == nil {
+		t.Error("Expected an error for an unknown profile")
+	}
+}
+
+func TestAnalyzeProfileLinkScope(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>Scoped</title></head>
+			<body><a href="/a">A</a><a href="https://blog.example.com/">Blog</a><a href="https://example.org/">Other</a></body></html>`))
+	}))
+	defer ts.Close()
+
+	a := NewAnalyzer(&Config{
+		RequestTimeout:  2 * time.Second,
+		LinkTimeout:     time.Second,
+		MaxWorkers:      1,
+		MaxResponseSize: 1024 * 1024,
+		MaxURLLength:    2048,
+		MaxRedirects:    5,
+		Profiles: map[string]Profile{
+			"server": {Name: "server", Checks: []Check{SEOCheck}},
+			"blog":   {Name: "blog", Checks: []Check{SEOCheck}, LinkScope: "*.example.com"},
+		},
+	})
+
+	for profile, internal := range map[string]int{"server": 1, "blog": 2} {
+		result, err := a.AnalyzeWithOptions(context.Background(), ts.URL, AnalyzeOptions{Profile: profile})
+		if err != nil {
+			t.Fatalf("Analyze failed: %v", err)
+		}
+		if result.InternalLinks != internal || result.ExternalLinks != 3-internal {
+			t.Errorf("Expected %d internal links with the %s profile, got %d internal and %d external", internal, profile, result.InternalLinks, result.ExternalLinks)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package analyzer
+
+import (
+	"context"
+	"time"
+)
+
+// ProgressReporter follows the work of a run as it happens. Pages and
+// links are added as they become known, so a crawl's totals grow while it
+// discovers pages.
+type ProgressReporter interface {
+	// AddPages announces n more pages to analyze
+	AddPages(n int)
+	// PageDone reports a page analyzed, successfully or not
+	PageDone(elapsed time.Duration)
+	// AddLinks announces n more links to check
+	AddLinks(n int)
+	// LinkChecked reports a link checked, successfully or not
+	LinkChecked(elapsed time.Duration)
+}
+
+type progressKey struct{}
+
+// WithProgress reports the progress of runs using ctx to p. Crawls report
+// their pages; callers analyzing single pages report those themselves.
+func WithProgress(ctx context.Context, p ProgressReporter) context.Context {
+	return context.WithValue(ctx, progressKey{}, p)
+}
+
+// progressFrom returns the run's reporter, which ignores everything when
+// nobody is following the run
+func progressFrom(ctx context.Context) ProgressReporter {
+	if p, ok := ctx.Value(progressKey{}).(ProgressReporter); ok {
+		return p
+	}
+	return noProgress{}
+}
+
+type noProgress struct{}
+
+func (noProgress) AddPages(int)              {}
+func (noProgress) PageDone(time.Duration)    {}
+func (noProgress) AddLinks(int)              {}
+func (noProgress) LinkChecked(time.Duration) {}
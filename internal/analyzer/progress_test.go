@@ -0,0 +1,90 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingProgress tallies what a run reports
+type countingProgress struct {
+	mu                                           sync.Mutex
+	pagesAdded, pagesDone, linksAdded, linksDone int
+}
+
+func (p *countingProgress) AddPages(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pagesAdded += n
+}
+
+func (p *countingProgress) PageDone(time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pagesDone++
+}
+
+func (p *countingProgress) AddLinks(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.linksAdded += n
+}
+
+func (p *countingProgress) LinkChecked(time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.linksDone++
+}
+
+func TestCrawlReportsProgress(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	pages := map[string]string{
+		"/":  `<html><body><a href="/a">A</a><a href="/b">B</a><a href="https://example.invalid/">Out</a></body></html>`,
+		"/a": `<html><body><a href="/">Home</a></body></html>`,
+		"/b": `<html><body><a href="/a">A</a></body></html>`,
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := pages[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	a := NewAnalyzer(&Config{
+		RequestTimeout:  2 * time.Second,
+		LinkTimeout:     time.Second,
+		MaxWorkers:      5,
+		MaxResponseSize: 1024 * 1024,
+		MaxURLLength:    2048,
+		MaxRedirects:    5,
+	})
+
+	progress := &countingProgress{}
+	crawl, err := a.Crawl(WithProgress(context.Background(), progress), ts.URL+"/", CrawlOptions{MaxDepth: 2, MaxPages: 10})
+	if err != nil {
+		t.Fatalf("Crawl failed: %v", err)
+	}
+
+	if progress.pagesAdded != len(crawl.Pages) || progress.pagesDone != len(crawl.Pages) {
+		t.Errorf("Expected %d pages added and done, got %+v", len(crawl.Pages), progress)
+	}
+	// Links shared between pages are only checked once
+	if progress.linksAdded == 0 || progress.linksDone != progress.linksAdded {
+		t.Errorf("Expected every added link to be checked, got %+v", progress)
+	}
+
+	// Runs nobody follows report nowhere
+	if _, err := a.Crawl(context.Background(), ts.URL+"/", CrawlOptions{MaxDepth: 1, MaxPages: 10}); err != nil {
+		t.Fatalf("Crawl failed: %v", err)
+	}
+}
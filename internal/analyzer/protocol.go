@@ -0,0 +1,57 @@
+package analyzer
+
+import (
+	"strings"
+
+	"website-analyzer/internal/models"
+)
+
+// DetectProtocol reports the protocol page was served over, whether its
+// server speaks HTTP/2, and whether it advertises HTTP/3 in Alt-Svc. HTTP/2
+// support is only known for pages served over TLS: in cleartext only
+// HTTP/1.1 is attempted. It returns nil when the fetcher didn't record the
+// protocol.
+func DetectProtocol(page *FetchedPage) *models.ProtocolReport {
+	if page.Proto == "" {
+		return nil
+	}
+	report := &models.ProtocolReport{
+		Negotiated: page.Proto,
+		AltSvc:     page.Header.Get("Alt-Svc"),
+	}
+	major := strings.TrimPrefix(page.Proto, "HTTP/")
+	report.HTTP2 = strings.HasPrefix(major, "2") || strings.HasPrefix(major, "3")
+	report.HTTP2Known = page.TLS || report.HTTP2
+	report.HTTP3 = strings.HasPrefix(major, "3") || advertisesHTTP3(report.AltSvc)
+	return report
+}
+
+// advertisesHTTP3 reports whether an Alt-Svc value offers HTTP/3, final
+// ("h3") or a draft ("h3-29")
+func advertisesHTTP3(altSvc string) bool {
+	for _, service := range strings.Split(altSvc, ",") {
+		protocol, _, _ := strings.Cut(strings.TrimSpace(service), "=")
+		if protocol == "h3" || strings.HasPrefix(protocol, "h3-") {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeProto turns the HTTP versions recorded in HAR files ("h2",
+// "http/2.0", "HTTP/1.1") into the form of http.Response.Proto
+func normalizeProto(version string) string {
+	switch strings.ToLower(strings.TrimSpace(version)) {
+	case "":
+		return ""
+	case "h2", "http/2", "http/2.0":
+		return "HTTP/2.0"
+	case "h3", "http/3", "http/3.0":
+		return "HTTP/3.0"
+	case "http/1.0":
+		return "HTTP/1.0"
+	case "http/1.1":
+		return "HTTP/1.1"
+	}
+	return version
+}
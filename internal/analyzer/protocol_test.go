@@ -0,0 +1,49 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDetectProtocol(t *testing.T) {
+	h2 := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Alt-Svc", `h3=":443"; ma=86400, h3-29=":443"`)
+		w.Write([]byte("<html></html>"))
+	}))
+	h2.EnableHTTP2 = true
+	h2.StartTLS()
+	defer h2.Close()
+
+	page, err := httpFetcher{client: h2.Client(), maxSize: 1024}.Fetch(context.Background(), h2.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := DetectProtocol(page)
+	if report == nil || report.Negotiated != "HTTP/2.0" || !report.HTTP2 || !report.HTTP2Known || !report.HTTP3 {
+		t.Errorf("Expected HTTP/2 with HTTP/3 advertised, got %+v", report)
+	}
+
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html></html>"))
+	}))
+	defer plain.Close()
+	page, err = httpFetcher{client: plain.Client(), maxSize: 1024}.Fetch(context.Background(), plain.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report = DetectProtocol(page)
+	if report == nil || report.Negotiated != "HTTP/1.1" || report.HTTP2 || report.HTTP2Known || report.HTTP3 {
+		t.Errorf("Expected HTTP/1.1 with HTTP/2 untested, got %+v", report)
+	}
+
+	if report := DetectProtocol(&FetchedPage{}); report != nil {
+		t.Errorf("Expected no report without a recorded protocol, got %+v", report)
+	}
+	for version, want := range map[string]string{"h2": "HTTP/2.0", "http/2.0": "HTTP/2.0", "h3": "HTTP/3.0", "HTTP/1.1": "HTTP/1.1", "": ""} {
+		if got := normalizeProto(version); got != want {
+			t.Errorf("normalizeProto(%q) = %q, want %q", version, got, want)
+		}
+	}
+}
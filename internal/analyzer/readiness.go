@@ -0,0 +1,142 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"website-analyzer/internal/models"
+)
+
+// Production readiness rules
+const (
+	RuleMetaNoindex     = "meta-noindex"
+	RuleRobotsDisallow  = "robots-disallow-all"
+	RulePlaceholderText = "placeholder-text"
+	RuleDefaultTitle    = "default-title"
+	RuleVisibleTrace    = "visible-stack-trace"
+)
+
+// defaultTitles are titles left behind by framework starters and server defaults
+var defaultTitles = toSet(
+	"react app",
+	"vite app",
+	"vite + react",
+	"vite + react + ts",
+	"vite + vue",
+	"create next app",
+	"welcome to nginx!",
+	"apache2 ubuntu default page: it works",
+	"it works!",
+	"iis windows server",
+	"document",
+	"untitled",
+	"untitled document",
+	"my wordpress blog",
+	"angular",
+	"svelte app",
+)
+
+var placeholderPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)lorem ipsum`),
+	regexp.MustCompile(`\b(?:TODO|FIXME|TBD)\b`),
+}
+
+// stackTracePatterns match error output from common server runtimes
+var stackTracePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`Traceback \(most recent call last\)`),
+	regexp.MustCompile(`Exception in thread "[^"]+"`),
+	regexp.MustCompile(`\bat (?:java|javax|org|com)\.[\w.$]+\([\w]+\.java:\d+\)`),
+	regexp.MustCompile(`goroutine \d+ \[running\]`),
+	regexp.MustCompile(`(?:Fatal error|Warning|Parse error|Notice): .{1,200} in /\S+\.php on line \d+`),
+	regexp.MustCompile(`Stack trace:\s*#0`),
+	regexp.MustCompile(`System\.\w+Exception: `),
+	regexp.MustCompile(`\bat \w+ \(/[^)]+\.js:\d+:\d+\)`),
+}
+
+// CheckProductionReadiness flags launch leftovers: noindex, blanket robots
+// blocks, placeholder copy, starter titles and leaked stack traces. robots
+// may be nil when robots.txt was not fetched.
+func CheckProductionReadiness(doc *goquery.Document, robots *robotsTxt) *models.ReadinessReport {
+	report := &models.ReadinessReport{}
+	add := func(rule, message, evidence string) {
+		report.Issues = append(report.Issues, models.ReadinessIssue{Rule: rule, Evidence: evidence, Message: message})
+	}
+
+	doc.Find(`meta[name][content]`).Each(func(i int, s *goquery.Selection) {
+		name := strings.ToLower(s.AttrOr("name", ""))
+		if name != "robots" && name != "googlebot" {
+			return
+		}
+		for _, directive := range strings.Split(strings.ToLower(s.AttrOr("content", "")), ",") {
+			directive = strings.TrimSpace(directive)
+			if directive == "noindex" || directive == "none" {
+				add(RuleMetaNoindex, "Page tells search engines not to index it", `<meta name="`+name+`" content="`+s.AttrOr("content", "")+`">`)
+				return
+			}
+		}
+	})
+
+	if robots != nil && blocksAllCrawlers(robots) {
+		add(RuleRobotsDisallow, "robots.txt blocks all crawlers from the entire site", "User-agent: * / Disallow: /")
+	}
+
+	title := strings.TrimSpace(doc.Find("title").First().Text())
+	if defaultTitles[strings.ToLower(title)] {
+		add(RuleDefaultTitle, "Page still uses a framework or server default title", title)
+	}
+
+	text := visibleText(doc)
+	for _, pattern := range placeholderPatterns {
+		if match := pattern.FindString(text); match != "" {
+			add(RulePlaceholderText, "Placeholder text is visible on the page", excerpt(text, match))
+		}
+	}
+	for _, pattern := range stackTracePatterns {
+		if match := pattern.FindString(text); match != "" {
+			add(RuleVisibleTrace, "A server error or stack trace is visible on the page", excerpt(text, match))
+			break
+		}
+	}
+
+	return report
+}
+
+// blocksAllCrawlers reports whether the wildcard group disallows the whole site
+func blocksAllCrawlers(robots *robotsTxt) bool {
+	for _, group := range robots.Groups {
+		if !isWildcardGroup(group) {
+			continue
+		}
+		allowRoot, disallowRoot := false, false
+		for _, rule := range group.Rules {
+			if rule.Path == "/" {
+				if rule.Allow {
+					allowRoot = true
+				} else {
+					disallowRoot = true
+				}
+			}
+		}
+		if disallowRoot && !allowRoot {
+			return true
+		}
+	}
+	return false
+}
+
+// visibleText returns the body text without scripts, styles and templates
+func visibleText(doc *goquery.Document) string {
+	body := doc.Find("body").Clone()
+	body.Find("script, style, noscript, template").Remove()
+	return body.Text()
+}
+
+// excerpt returns the match with a little surrounding context
+func excerpt(text, match string) string {
+	idx := strings.Index(text, match)
+	start := max(idx-40, 0)
+	end := min(idx+len(match)+40, len(text))
+	return truncate(strings.Join(strings.Fields(strings.ToValidUTF8(text[start:end], "")), " "), 120)
+}
@@ -0,0 +1,62 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestCheckProductionReadiness(t *testing.T) {
+	html := `<html><head>
+		<title>React App</title>
+		<meta name="robots" content="noindex, nofollow">
+		<script>// TODO: remove before launch</script>
+	</head><body>
+		<p>Lorem ipsum dolor sit amet.</p>
+		<p>Pricing: TODO</p>
+		<pre>Traceback (most recent call last):
+  File "app.py", line 10, in handler</pre>
+	</body></html>`
+
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+	robots := parseRobots("User-agent: *\nDisallow: /\n")
+
+	report := CheckProductionReadiness(doc, robots)
+
+	counts := make(map[string]int)
+	for _, issue := range report.Issues {
+		counts[issue.Rule]++
+	}
+
+	expected := map[string]int{
+		RuleMetaNoindex:     1,
+		RuleRobotsDisallow:  1,
+		RuleDefaultTitle:    1,
+		RulePlaceholderText: 2,
+		RuleVisibleTrace:    1,
+	}
+	for rule, want := range expected {
+		if counts[rule] != want {
+			t.Errorf("Expected %d %s issues, got %d (%+v)", want, rule, counts[rule], report.Issues)
+		}
+	}
+
+	for _, issue := range report.Issues {
+		if issue.Rule == RulePlaceholderText && strings.Contains(issue.Evidence, "remove before launch") {
+			t.Errorf("Script content should not count as visible text: %q", issue.Evidence)
+		}
+	}
+}
+
+func TestCheckProductionReadinessClean(t *testing.T) {
+	html := `<html><head><title>Acme Widgets</title><meta name="robots" content="index, follow"></head>
+		<body><p>Our todo list app helps teams ship.</p></body></html>`
+
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+	robots := parseRobots("User-agent: *\nDisallow: /admin\n")
+
+	if report := CheckProductionReadiness(doc, robots); len(report.Issues) != 0 {
+		t.Errorf("Expected no issues, got %+v", report.Issues)
+	}
+}
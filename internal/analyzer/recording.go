@@ -0,0 +1,85 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"website-analyzer/internal/models"
+)
+
+// recordingMaxRedirects caps how many recorded redirects a replayed page
+// follows
+const recordingMaxRedirects = 10
+
+// recordedResponse is a recorded response to a GET
+type recordedResponse struct {
+	status   int
+	proto    string
+	header   http.Header
+	body     []byte
+	redirect string
+	timing   *models.ResponseTiming
+}
+
+// recording maps normalized URLs to their recorded responses, for the
+// fetchers that replay HAR and WARC files
+type recording map[string]recordedResponse
+
+// fetch replays the response recorded for pageURL, following recorded
+// redirects
+func (r recording) fetch(ctx context.Context, pageURL string) (*FetchedPage, error) {
+	var hops []models.RedirectHop
+	for range recordingMaxRedirects + 1 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		resp, ok := r[normalizeURL(pageURL)]
+		if !ok {
+			return nil, fmt.Errorf("failed to fetch URL: %s is not in the recording", pageURL)
+		}
+		redirect := resp.status >= 300 && resp.status < 400 && resp.redirect != ""
+		if redirect || hops != nil {
+			hop := models.RedirectHop{URL: pageURL, StatusCode: resp.status}
+			if resp.timing != nil {
+				hop.LatencyMs = resp.timing.TotalMs
+			}
+			hops = append(hops, hop)
+		}
+		if redirect {
+			base, err := url.Parse(pageURL)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch URL: %w", err)
+			}
+			next, err := base.Parse(resp.redirect)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch URL: %w", err)
+			}
+			pageURL = next.String()
+			if err := redirectLoop(hops, pageURL); err != nil {
+				return nil, fmt.Errorf("failed to fetch URL: %w", err)
+			}
+			continue
+		}
+		if resp.status != http.StatusOK {
+			return nil, fmt.Errorf("HTTP %d: %s", resp.status, http.StatusText(resp.status))
+		}
+		page := &FetchedPage{
+			Body:      resp.body,
+			Size:      int64(len(resp.body)),
+			Header:    resp.header.Clone(),
+			Proto:     resp.proto,
+			TLS:       strings.HasPrefix(pageURL, "https:"),
+			Redirects: hops,
+		}
+		// The recorded timing is that of the final response
+		if resp.timing != nil {
+			timing := *resp.timing
+			page.Timing = &timing
+		}
+		return page, nil
+	}
+	return nil, fmt.Errorf("failed to fetch URL: %w", errTooManyRedirects)
+}
@@ -0,0 +1,47 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"website-analyzer/internal/models"
+)
+
+// defaultRedirectChainMax is how many redirects a page's chain may have
+// before it is flagged, when the configuration sets no limit
+const defaultRedirectChainMax = 3
+
+// errRedirectLoop stops a fetch whose redirects lead back to a URL already
+// visited
+var errRedirectLoop = errors.New("redirect loop")
+
+// redirectLoop returns an error naming the chain when next was already
+// visited on it
+func redirectLoop(hops []models.RedirectHop, next string) error {
+	for _, hop := range hops {
+		if hop.URL != next {
+			continue
+		}
+		chain := make([]string, 0, len(hops)+1)
+		for _, hop := range hops {
+			chain = append(chain, hop.URL)
+		}
+		return fmt.Errorf("%w: %s → %s", errRedirectLoop, strings.Join(chain, " → "), next)
+	}
+	return nil
+}
+
+// RedirectReport describes the redirect chain of a fetched page, flagging
+// chains of more than limit redirects; zero flags more than three. It is
+// nil when the page wasn't redirected.
+func RedirectReport(hops []models.RedirectHop, limit int) *models.RedirectReport {
+	if len(hops) == 0 {
+		return nil
+	}
+	if limit <= 0 {
+		limit = defaultRedirectChainMax
+	}
+	// The last hop is the page itself
+	return &models.RedirectReport{Hops: hops, TooLong: len(hops)-1 > limit, Limit: limit}
+}
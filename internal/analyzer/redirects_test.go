@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRedirectChain(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/moved", http.StatusMovedPermanently)
+		case "/moved":
+			http.Redirect(w, r, "/new", http.StatusFound)
+		case "/new", "/direct":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><head><title>New</title></head><body></body></html>`))
+		case "/loop-a":
+			http.Redirect(w, r, "/loop-b", http.StatusFound)
+		case "/loop-b":
+			http.Redirect(w, r, "/loop-a", http.StatusFound)
+		}
+	}))
+	defer ts.Close()
+
+	a := NewAnalyzer(&Config{
+		RequestTimeout:   2 * time.Second,
+		LinkTimeout:      time.Second,
+		MaxWorkers:       2,
+		MaxResponseSize:  1024 * 1024,
+		MaxURLLength:     2048,
+		MaxRedirects:     5,
+		RedirectChainMax: 1,
+	})
+
+	result, err := a.Analyze(context.Background(), ts.URL+"/old")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	report := result.Redirects
+	if report == nil || len(report.Hops) != 3 {
+		t.Fatalf("Expected three hops, got %+v", report)
+	}
+	for i, want := range []struct {
+		path   string
+		status int
+	}{{"/old", 301}, {"/moved", 302}, {"/new", 200}} {
+		if hop := report.Hops[i]; hop.URL != ts.URL+want.path || hop.StatusCode != want.status {
+			t.Errorf("Expected hop %d to be %s %d, got %+v", i, want.path, want.status, hop)
+		}
+	}
+	if !report.TooLong || report.Limit != 1 {
+		t.Errorf("Expected two redirects over the limit of one to be flagged, got %+v", report)
+	}
+
+	direct, err := a.Analyze(context.Background(), ts.URL+"/direct")
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if direct.Redirects != nil {
+		t.Errorf("Expected no redirect chain, got %+v", direct.Redirects)
+	}
+
+	if _, err := a.Analyze(context.Background(), ts.URL+"/loop-a"); !errors.Is(err, errRedirectLoop) {
+		t.Errorf("Expected a redirect loop error, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package analyzer
+
+import (
+	"cmp"
+	"context"
+
+	"website-analyzer/internal/models"
+)
+
+// defaultRegion names the analyzing server's location when Config.Region
+// is empty
+const defaultRegion = "local"
+
+// RegionChecker checks URLs from other network locations, such as remote
+// agents registered with the server
+type RegionChecker interface {
+	// CheckRegions checks urls from every region it knows, returning
+	// nothing when there are none
+	CheckRegions(ctx context.Context, urls []string) []RegionCheck
+}
+
+// RegionCheck is the outcome of checking URLs from one region; Err is set
+// when the region couldn't check them at all
+type RegionCheck struct {
+	Region   string
+	Statuses []models.LinkStatus
+	Err      error
+}
+
+// CheckURLs checks urls from here the way a page's links are checked,
+// with the configured timeouts, retries and per-host limits. Remote
+// agents answer the server with it.
+func (a *Analyzer) CheckURLs(ctx context.Context, urls []string) []models.LinkStatus {
+	links := make([]models.Link, 0, len(urls))
+	for _, u := range urls {
+		links = append(links, models.Link{URL: u, Type: models.LinkTypeExternal})
+	}
+	limiter := newHostLimiter(a.config.LinkHostRate, a.config.LinkHostInFlight)
+	return CheckAllLinks(ctx, links, a.checkLinksConfig(Profile{}, a.config.MaxWorkers, limiter))
+}
+
+// checkRegions checks the page and the external links checked here from
+// the other regions and compares the outcomes
+func (a *Analyzer) checkRegions(ctx context.Context, pageURL string, statuses []models.LinkStatus) *models.RegionReport {
+	urls := []string{pageURL}
+	for _, status := range statuses {
+		if status.Type == models.LinkTypeExternal && !status.Blocked {
+			urls = append(urls, status.URL)
+		}
+	}
+	checks := a.config.Regions.CheckRegions(ctx, urls)
+	if len(checks) == 0 {
+		return nil
+	}
+	return CompareRegions(cmp.Or(a.config.Region, defaultRegion), pageURL, statuses, checks)
+}
+
+// CompareRegions reports the reachability of the page and its external
+// links from the local region, where statuses were checked and the page
+// fetched, and from the regions of checks. Links skipped by a circuit
+// breaker count in no region.
+func CompareRegions(local, pageURL string, statuses []models.LinkStatus, checks []RegionCheck) *models.RegionReport {
+	type outcome struct {
+		region string
+		status models.LinkStatus
+	}
+	outcomes := make(map[string][]outcome)
+	var order []string
+	add := func(region string, status models.LinkStatus) {
+		if _, ok := outcomes[status.URL]; !ok {
+			order = append(order, status.URL)
+		}
+		outcomes[status.URL] = append(outcomes[status.URL], outcome{region, status})
+	}
+
+	report := &models.RegionReport{}
+	add(local, models.LinkStatus{URL: pageURL})
+	here := models.RegionReach{Region: local, PageReachable: true}
+	for _, status := range statuses {
+		if status.Type != models.LinkTypeExternal || status.Blocked {
+			continue
+		}
+		here.Checked++
+		if status.Error != "" {
+			here.Broken++
+		}
+		add(local, status)
+	}
+	report.Regions = append(report.Regions, here)
+
+	for _, check := range checks {
+		reach := models.RegionReach{Region: check.Region}
+		if check.Err != nil {
+			reach.Error = check.Err.Error()
+			report.Regions = append(report.Regions, reach)
+			continue
+		}
+		for _, status := range check.Statuses {
+			if status.Blocked {
+				continue
+			}
+			if status.URL == pageURL {
+				reach.PageReachable = status.Error == ""
+			} else {
+				reach.Checked++
+				if status.Error != "" {
+					reach.Broken++
+				}
+			}
+			add(check.Region, status)
+		}
+		report.Regions = append(report.Regions, reach)
+	}
+
+	for _, url := range order {
+		link := models.RegionalLink{URL: url}
+		for _, o := range outcomes[url] {
+			if o.status.Error == "" {
+				link.Reachable = append(link.Reachable, o.region)
+				continue
+			}
+			link.Failures = append(link.Failures, models.RegionFailure{
+				Region:     o.region,
+				StatusCode: o.status.StatusCode,
+				Error:      o.status.Error,
+			})
+		}
+		if len(link.Reachable) > 0 && len(link.Failures) > 0 {
+			report.Links = append(report.Links, link)
+		}
+	}
+	return report
+}
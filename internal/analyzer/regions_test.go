@@ -0,0 +1,140 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+func TestCompareRegions(t *testing.T) {
+	const page = "https://example.com/"
+	statuses := []models.LinkStatus{
+		{URL: "https://example.com/about", Type: models.LinkTypeInternal},
+		{URL: "https://news.example/", Type: models.LinkTypeExternal},
+		{URL: "https://shop.example/", Type: models.LinkTypeExternal},
+		{URL: "https://gone.example/", Type: models.LinkTypeExternal, StatusCode: 404, Error: "HTTP 404"},
+		{URL: "https://flaky.example/", Type: models.LinkTypeExternal, Blocked: true, Error: "skipped"},
+	}
+	checks := []RegionCheck{
+		{Region: "eu-west", Statuses: []models.LinkStatus{
+			{URL: page},
+			{URL: "https://news.example/"},
+			{URL: "https://shop.example/", StatusCode: 451, Error: "HTTP 451"},
+			{URL: "https://gone.example/", StatusCode: 404, Error: "HTTP 404"},
+		}},
+		{Region: "ap-south", Statuses: []models.LinkStatus{
+			{URL: page, Error: "connection refused"},
+			{URL: "https://news.example/"},
+			{URL: "https://shop.example/"},
+			{URL: "https://gone.example/", StatusCode: 404, Error: "HTTP 404"},
+		}},
+		{Region: "us-east", Err: errors.New("agent unreachable")},
+	}
+
+	report := CompareRegions("local", page, statuses, checks)
+	want := []models.RegionReach{
+		{Region: "local", PageReachable: true, Checked: 3, Broken: 1},
+		{Region: "eu-west", PageReachable: true, Checked: 3, Broken: 2},
+		{Region: "ap-south", PageReachable: false, Checked: 3, Broken: 1},
+		{Region: "us-east", Error: "agent unreachable"},
+	}
+	if !slices.Equal(report.Regions, want) {
+		t.Errorf("Expected regions %+v, got %+v", want, report.Regions)
+	}
+
+	// Links broken everywhere aren't regional
+	if len(report.Links) != 2 {
+		t.Fatalf("Expected the page and one link to differ between regions, got %+v", report.Links)
+	}
+	if link := report.Links[0]; link.URL != page || !slices.Equal(link.Reachable, []string{"local", "eu-west"}) || link.Failures[0].Region != "ap-south" {
+		t.Errorf("Unexpected page reachability %+v", link)
+	}
+	if link := report.Links[1]; link.URL != "https://shop.example/" || len(link.Failures) != 1 || link.Failures[0] != (models.RegionFailure{Region: "eu-west", StatusCode: 451, Error: "HTTP 451"}) {
+		t.Errorf("Expected shop.example to fail in eu-west only, got %+v", link)
+	}
+}
+
+// regionsFunc adapts a function to RegionChecker
+type regionsFunc func(ctx context.Context, urls []string) []RegionCheck
+
+func (f regionsFunc) CheckRegions(ctx context.Context, urls []string) []RegionCheck {
+	return f(ctx, urls)
+}
+
+func TestAnalyzeRegions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><body><a href="/about">About</a><a href="https://external.invalid/">External</a></body></html>`))
+	}))
+	defer server.Close()
+
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	var sent []string
+	a := NewAnalyzer(&Config{
+		RequestTimeout:  5 * time.Second,
+		LinkTimeout:     2 * time.Second,
+		MaxWorkers:      2,
+		MaxResponseSize: 1024 * 1024,
+		MaxURLLength:    2048,
+		Region:          "eu-central",
+		Regions: regionsFunc(func(ctx context.Context, urls []string) []RegionCheck {
+			sent = urls
+			return []RegionCheck{{Region: "us-east", Statuses: reachable(urls)}}
+		}),
+	})
+	result, err := a.Analyze(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if !slices.Equal(sent, []string{server.URL, "https://external.invalid/"}) {
+		t.Errorf("Expected the page and its external link to be sent, got %v", sent)
+	}
+	if result.Regions == nil || len(result.Regions.Regions) != 2 || result.Regions.Regions[0].Region != "eu-central" {
+		t.Fatalf("Expected local and remote regions, got %+v", result.Regions)
+	}
+	// The link doesn't resolve here but does from us-east
+	if len(result.Regions.Links) != 1 || result.Regions.Links[0].URL != "https://external.invalid/" {
+		t.Errorf("Expected the external link to differ between regions, got %+v", result.Regions.Links)
+	}
+}
+
+// reachable answers every URL as reachable
+func reachable(urls []string) []models.LinkStatus {
+	statuses := make([]models.LinkStatus, 0, len(urls))
+	for _, u := range urls {
+		statuses = append(statuses, models.LinkStatus{URL: u, StatusCode: 200})
+	}
+	return statuses
+}
+
+func TestCheckURLs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	a := NewAnalyzer(&Config{RequestTimeout: 5 * time.Second, LinkTimeout: 2 * time.Second, MaxWorkers: 2})
+	statuses := a.CheckURLs(context.Background(), []string{server.URL + "/", server.URL + "/missing"})
+	if len(statuses) != 2 {
+		t.Fatalf("Expected two statuses, got %+v", statuses)
+	}
+	for _, status := range statuses {
+		if broken := status.Error != ""; broken != (status.URL == server.URL+"/missing") {
+			t.Errorf("Unexpected outcome %+v", status)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package analyzer
+
+import (
+	"net/url"
+	"strings"
+
+	"website-analyzer/internal/models"
+)
+
+// affiliateHosts are redirect domains of common affiliate networks
+var affiliateHosts = []string{
+	"amzn.to",
+	"shareasale.com",
+	"awin1.com",
+	"linksynergy.com",
+	"anrdoezrs.net",
+	"jdoqocy.com",
+	"tkqlhce.com",
+	"dpbolvw.net",
+	"kqzyfj.com",
+	"skimresources.com",
+	"viglink.com",
+	"prf.hn",
+	"sjv.io",
+	"clickbank.net",
+	"rstyle.me",
+	"pntrs.com",
+	"avantlink.com",
+	"impactradius.com",
+}
+
+// affiliateParams are query parameters carrying affiliate/partner IDs
+var affiliateParams = []string{"affid", "aff_id", "affiliate_id", "affiliate", "aff", "partner_id", "clickref"}
+
+// affiliatePathPrefixes are typical cloaked affiliate redirects on the site
+var affiliatePathPrefixes = []string{"/go/", "/recommends/", "/refer/", "/out/"}
+
+// AuditRelAttributes summarizes rel qualifiers of external links and flags
+// likely affiliate links that are not marked rel="sponsored"
+func AuditRelAttributes(links []models.Link) *models.RelReport {
+	report := &models.RelReport{}
+
+	for _, link := range links {
+		rel := toSet(link.Rel...)
+
+		if link.Type == models.LinkTypeExternal {
+			if rel["nofollow"] {
+				report.Nofollow++
+			}
+			if rel["sponsored"] {
+				report.Sponsored++
+			}
+			if rel["ugc"] {
+				report.UGC++
+			}
+			if !rel["nofollow"] && !rel["sponsored"] && !rel["ugc"] {
+				report.Follow++
+			}
+		}
+
+		if isAffiliateLink(link.URL) && !rel["sponsored"] {
+			report.UnmarkedAffiliates = append(report.UnmarkedAffiliates, link.URL)
+		}
+	}
+
+	return report
+}
+
+// isAffiliateLink matches known affiliate network hosts, Amazon associate
+// tags, affiliate query parameters and cloaked redirect paths
+func isAffiliateLink(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, affiliate := range affiliateHosts {
+		if host == affiliate || strings.HasSuffix(host, "."+affiliate) {
+			return true
+		}
+	}
+
+	query := u.Query()
+	if strings.Contains(host, "amazon.") && query.Get("tag") != "" {
+		return true
+	}
+	for _, param := range affiliateParams {
+		if query.Get(param) != "" {
+			return true
+		}
+	}
+	if strings.EqualFold(query.Get("utm_medium"), "affiliate") {
+		return true
+	}
+
+	path := strings.ToLower(u.Path)
+	for _, prefix := range affiliatePathPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,41 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestAuditRelAttributes(t *testing.T) {
+	html := `
+		<html><body>
+			<a href="https://news.example.org/story">Plain</a>
+			<a href="https://forum.example.net/post" rel="ugc nofollow">Comment</a>
+			<a href="https://www.amazon.com/dp/B000?tag=mysite-20">Amazon</a>
+			<a href="https://www.shareasale.com/r.cfm?b=1" rel="sponsored noopener">Marked</a>
+			<a href="/go/hosting-deal">Cloaked</a>
+		</body></html>
+	`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	links, err := ExtractLinks(doc, "https://mysite.com", LinkScope{})
+	if err != nil {
+		t.Fatalf("ExtractLinks failed: %v", err)
+	}
+
+	report := AuditRelAttributes(links)
+
+	if report.Follow != 2 || report.Nofollow != 1 || report.UGC != 1 || report.Sponsored != 1 {
+		t.Errorf("Unexpected rel counts: %+v", report)
+	}
+
+	expected := []string{"https://www.amazon.com/dp/B000?tag=mysite-20", "https://mysite.com/go/hosting-deal"}
+	if strings.Join(report.UnmarkedAffiliates, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected unmarked affiliates %v, got %v", expected, report.UnmarkedAffiliates)
+	}
+}
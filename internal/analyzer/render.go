@@ -0,0 +1,153 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"website-analyzer/internal/validator"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// Render modes select how the analyzed page's HTML is obtained
+const (
+	RenderHTTP    = "http"
+	RenderBrowser = "browser"
+)
+
+const (
+	// renderIdle is how long the network must stay quiet after the load
+	// event before the rendered DOM is captured
+	renderIdle = 500 * time.Millisecond
+	// renderMaxWait caps the wait for network idle on pages that poll or
+	// stream; the DOM is captured as it is then
+	renderMaxWait = 10 * time.Second
+)
+
+// renderBrowser loads pageURL in headless Chrome, waits for the network to
+// go idle and returns the rendered DOM. The browser's own requests go
+// through the same SSRF checks as the page itself.
+func (a *Analyzer) renderBrowser(ctx context.Context, pageURL string) (string, error) {
+	opts := append(chromedp.DefaultExecAllocatorOptions[:], chromedp.UserAgent(a.UserAgent()))
+	if a.config.BrowserPath != "" {
+		opts = append(opts, chromedp.ExecPath(a.config.BrowserPath))
+	}
+	// Chrome refuses to start its sandbox as root, as in most containers
+	if os.Geteuid() == 0 {
+		opts = append(opts, chromedp.NoSandbox)
+	}
+
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(ctx, opts...)
+	defer cancelAlloc()
+	browserCtx, cancel := chromedp.NewContext(allocCtx)
+	defer cancel()
+
+	idle := &networkIdle{inFlight: make(map[network.RequestID]bool), last: time.Now()}
+	chromedp.ListenTarget(browserCtx, func(ev any) {
+		switch ev := ev.(type) {
+		case *fetch.EventRequestPaused:
+			// Commands can't be sent from the listener itself
+			go filterBrowserRequest(browserCtx, ev, a.optOut)
+		case *network.EventRequestWillBeSent:
+			idle.start(ev.RequestID)
+		case *network.EventLoadingFinished:
+			idle.finish(ev.RequestID)
+		case *network.EventLoadingFailed:
+			idle.finish(ev.RequestID)
+		}
+	})
+
+	var html string
+	err := chromedp.Run(browserCtx,
+		network.Enable(),
+		fetch.Enable(),
+		chromedp.Navigate(pageURL),
+		idle.wait(renderIdle, renderMaxWait),
+		chromedp.OuterHTML("html", &html, chromedp.ByQuery),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to render page: %w", err)
+	}
+	return html, nil
+}
+
+// filterBrowserRequest lets a request paused by the browser continue
+// unless it leaves http(s), targets a private address or an opted-out
+// domain
+func filterBrowserRequest(ctx context.Context, ev *fetch.EventRequestPaused, optOut domainList) {
+	ctx = cdp.WithExecutor(ctx, chromedp.FromContext(ctx).Target)
+
+	u, err := url.Parse(ev.Request.URL)
+	if err == nil && u.Scheme != "http" && u.Scheme != "https" {
+		err = fmt.Errorf("scheme %q is not allowed", u.Scheme)
+	}
+	if err == nil && optOut.contains(u.Hostname()) {
+		err = ErrOptedOut
+	}
+	if err == nil {
+		err = validator.CheckTarget(u.Hostname())
+	}
+
+	if err != nil {
+		_ = fetch.FailRequest(ev.RequestID, network.ErrorReasonBlockedByClient).Do(ctx)
+		return
+	}
+	_ = fetch.ContinueRequest(ev.RequestID).Do(ctx)
+}
+
+// networkIdle tracks the browser's requests in flight
+type networkIdle struct {
+	mu       sync.Mutex
+	inFlight map[network.RequestID]bool
+	// last is when the most recent request started or ended
+	last time.Time
+}
+
+func (n *networkIdle) start(id network.RequestID) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.inFlight[id] = true
+	n.last = time.Now()
+}
+
+func (n *networkIdle) finish(id network.RequestID) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.inFlight, id)
+	n.last = time.Now()
+}
+
+// quietFor reports how long no request has been in flight
+func (n *networkIdle) quietFor() time.Duration {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(n.inFlight) > 0 {
+		return 0
+	}
+	return time.Since(n.last)
+}
+
+// wait blocks until the network has been quiet for quiet, or limit passed
+func (n *networkIdle) wait(quiet, limit time.Duration) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		deadline := time.Now().Add(limit)
+		ticker := time.NewTicker(50 * time.Millisecond)
+		defer ticker.Stop()
+
+		for n.quietFor() < quiet && time.Now().Before(deadline) {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+			}
+		}
+		return nil
+	}
+}
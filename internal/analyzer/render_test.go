@@ -0,0 +1,89 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+)
+
+func TestNetworkIdle(t *testing.T) {
+	idle := &networkIdle{inFlight: make(map[network.RequestID]bool), last: time.Now()}
+
+	idle.start("1")
+	idle.start("2")
+	idle.finish("1")
+	if idle.quietFor() != 0 {
+		t.Error("Expected the network to be busy while a request is in flight")
+	}
+
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		idle.finish("2")
+	}()
+
+	start := time.Now()
+	if err := idle.wait(20*time.Millisecond, time.Second)(context.Background()); err != nil {
+		t.Fatalf("wait failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected to wait for the last request plus the quiet period, waited %v", elapsed)
+	}
+
+	// Requests that never finish give up at the limit rather than failing
+	idle.start("3")
+	start = time.Now()
+	if err := idle.wait(20*time.Millisecond, 60*time.Millisecond)(context.Background()); err != nil {
+		t.Fatalf("wait failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected the wait to stop at its limit, waited %v", elapsed)
+	}
+}
+
+func TestAnalyzer_RenderBrowser(t *testing.T) {
+	found := false
+	for _, name := range []string{"chromium", "chromium-browser", "google-chrome", "headless-shell"} {
+		if _, err := exec.LookPath(name); err == nil {
+			found = true
+		}
+	}
+	if !found {
+		t.Skip("Chrome is not installed")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>App</title></head><body><div id="root"></div>
+			<script>document.getElementById("root").innerHTML = '<h1>Rendered</h1><a href="/about">About</a>';</script>
+			</body></html>`))
+	}))
+	defer server.Close()
+
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	a := NewAnalyzer(&Config{
+		RequestTimeout:  30 * time.Second,
+		MaxResponseSize: 1024 * 1024,
+		RenderMode:      RenderBrowser,
+	})
+
+	fetcher, err := a.Fetcher("", a.config.Flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, _, err := a.fetchHTML(context.Background(), server.URL, fetcher, &stageClock{})
+	if err != nil {
+		t.Fatalf("fetchHTML failed: %v", err)
+	}
+	if got := strings.TrimSpace(doc.Find("#root h1").Text()); got != "Rendered" {
+		t.Errorf("Expected the script-rendered heading, got %q", got)
+	}
+}
@@ -0,0 +1,115 @@
+package analyzer
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+// ErrNoSnapshot is returned when replaying an analysis stored without the
+// HTML it ran on
+var ErrNoSnapshot = errors.New("no snapshot stored for this analysis")
+
+// snapshotFetcher serves a stored snapshot for any URL
+type snapshotFetcher struct {
+	snapshot *models.Snapshot
+}
+
+func (f snapshotFetcher) Fetch(ctx context.Context, pageURL string) (*FetchedPage, error) {
+	return &FetchedPage{
+		Body:   []byte(f.snapshot.Body),
+		Size:   f.snapshot.Size,
+		Header: http.Header(f.snapshot.Header),
+	}, nil
+}
+
+// Offline reports that snapshots are replayed from storage
+func (f snapshotFetcher) Offline() bool {
+	return true
+}
+
+// snapshotOf captures the analyzed page
+func snapshotOf(targetURL string, page *FetchedPage) *models.Snapshot {
+	return &models.Snapshot{
+		URL:        targetURL,
+		Body:       string(page.Body),
+		Size:       page.Size,
+		Header:     page.Header,
+		CapturedAt: time.Now().UTC(),
+	}
+}
+
+// Replay analyzes a stored snapshot again with the current checks, without
+// network access. Findings that need outbound requests (link, document,
+// feed, resource and asset caching checks, social images and site files)
+// can't be
+// repeated and are carried over from stored, the result the snapshot was
+// taken with. An empty opts.Profile uses the stored result's profile.
+func (a *Analyzer) Replay(ctx context.Context, snapshot *models.Snapshot, stored *models.AnalysisResult, opts AnalyzeOptions) (*models.AnalysisResult, error) {
+	if snapshot == nil {
+		return nil, ErrNoSnapshot
+	}
+	profile, err := a.profile(cmp.Or(opts.Profile, stored.Profile))
+	if err != nil {
+		return nil, err
+	}
+	flags, err := a.flags(opts.Flags)
+	if err != nil {
+		return nil, err
+	}
+
+	pc := &pageContext{opts: opts, profile: profile, flags: flags, fetcher: snapshotFetcher{snapshot}, replay: stored}
+	result, _, err := a.analyzePage(ctx, snapshot.URL, pc)
+	if err != nil {
+		return nil, a.redactor.Error(err)
+	}
+	a.redactor.Walk(result)
+	return result, nil
+}
+
+// carryOver copies the findings of stored that needed the network into
+// result, before it is scored
+func carryOver(result, stored *models.AnalysisResult) {
+	result.ResponseTimeMs = stored.ResponseTimeMs
+	result.Timing = stored.Timing
+	result.Protocol = stored.Protocol
+	result.InaccessibleLinks = stored.InaccessibleLinks
+	result.Restricted = stored.Restricted
+	result.RobotsSkipped = stored.RobotsSkipped
+	result.OptedOut = stored.OptedOut
+	result.OutOfScope = stored.OutOfScope
+	result.ExternalDomains = stored.ExternalDomains
+	result.LinkLatency = stored.LinkLatency
+	result.Regions = stored.Regions
+	result.Documents = stored.Documents
+	result.InsecureLinks = stored.InsecureLinks
+	result.Feeds = stored.Feeds
+	result.Site = stored.Site
+	result.Social = stored.Social
+	result.ImageFormats = stored.ImageFormats
+	if stored.Resources != nil && slices.ContainsFunc(stored.Resources.Resources, func(r models.Resource) bool { return r.Checked }) {
+		result.Resources = stored.Resources
+	}
+	if stored.Caching != nil && stored.Caching.AssetsChecked {
+		result.Caching = stored.Caching
+	}
+
+	// Link details keep the stored check outcomes of links still present
+	checked := make(map[string]models.LinkDetail, len(stored.LinkDetails))
+	for _, detail := range stored.LinkDetails {
+		checked[detail.URL] = detail
+	}
+	for i, detail := range result.LinkDetails {
+		if old, ok := checked[detail.URL]; ok && old.Checked {
+			result.LinkDetails[i].Checked = true
+			result.LinkDetails[i].StatusCode = old.StatusCode
+			result.LinkDetails[i].Error = old.Error
+			result.LinkDetails[i].LatencyMs = old.LatencyMs
+		}
+	}
+}
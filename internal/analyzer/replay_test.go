@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReplaySnapshot(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>Snapshot</title></head><body><h1>Hi</h1><a href="/gone">Gone</a></body></html>`))
+	}))
+
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	a := NewAnalyzer(&Config{
+		RequestTimeout:  5 * time.Second,
+		LinkTimeout:     2 * time.Second,
+		MaxWorkers:      2,
+		MaxResponseSize: 1024 * 1024,
+		MaxURLLength:    2048,
+		KeepSnapshots:   true,
+	})
+	stored, err := a.AnalyzeWithOptions(context.Background(), server.URL, AnalyzeOptions{IncludeLinks: true})
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	snapshot := stored.Snapshot
+	if snapshot == nil || !strings.Contains(snapshot.Body, "<title>Snapshot</title>") || snapshot.URL != server.URL {
+		t.Fatalf("Expected a snapshot of the page, got %+v", snapshot)
+	}
+	if len(stored.InaccessibleLinks) != 1 {
+		t.Fatalf("Expected the broken link to be found, got %+v", stored.InaccessibleLinks)
+	}
+
+	// Nothing is fetched again: the server is gone
+	server.Close()
+	result, err := a.Replay(context.Background(), snapshot, stored, AnalyzeOptions{IncludeLinks: true})
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if result.Title != "Snapshot" || result.Headings["h1"] != 1 || result.Snapshot != nil {
+		t.Errorf("Expected the snapshot to be analyzed again, got %+v", result)
+	}
+	if len(result.InaccessibleLinks) != 1 || !strings.HasSuffix(result.InaccessibleLinks[0].URL, "/gone") {
+		t.Errorf("Expected the broken link to be carried over, got %+v", result.InaccessibleLinks)
+	}
+	if len(result.LinkDetails) != 1 || !result.LinkDetails[0].Checked || result.LinkDetails[0].StatusCode != http.StatusNotFound {
+		t.Errorf("Expected stored link outcomes, got %+v", result.LinkDetails)
+	}
+	if result.Scores == nil || result.Scores.Links != stored.Scores.Links {
+		t.Errorf("Expected the same link score, got %+v and %+v", result.Scores, stored.Scores)
+	}
+
+	if _, err := a.Replay(context.Background(), nil, stored, AnalyzeOptions{}); !errors.Is(err, ErrNoSnapshot) {
+		t.Errorf("Expected ErrNoSnapshot, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package analyzer
+
+import (
+	"context"
+	"net/url"
+
+	"website-analyzer/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// resourceSelectors map the elements loading a resource to its kind and
+// the attribute holding its URL
+var resourceSelectors = []struct {
+	selector string
+	kind     string
+	attr     string
+}{
+	{`link[rel~="stylesheet" i][href]`, models.ResourceStylesheet, "href"},
+	{"script[src]", models.ResourceScript, "src"},
+	{`link[rel~="preload" i][href], link[rel~="modulepreload" i][href]`, models.ResourcePreload, "href"},
+	{`link[rel~="prefetch" i][href]`, models.ResourcePrefetch, "href"},
+	{"iframe[src]", models.ResourceIframe, "src"},
+}
+
+// ExtractResources finds the stylesheets, scripts, preloaded and
+// prefetched files and frames the page loads, resolved against the
+// document's base URL and classified as internal or external to baseURL by
+// scope. A URL loaded several ways is listed once, under its first kind.
+func ExtractResources(doc *goquery.Document, baseURL string, scope LinkScope) []models.Resource {
+	page, err := url.Parse(baseURL)
+	if err != nil {
+		return nil
+	}
+	base := documentBase(doc, page)
+
+	var resources []models.Resource
+	seen := make(map[string]bool)
+	for _, rs := range resourceSelectors {
+		doc.Find(rs.selector).Each(func(i int, s *goquery.Selection) {
+			resolved, err := resolveURL(base, s.AttrOr(rs.attr, ""))
+			if err != nil || resolved == "" || seen[resolved] {
+				return
+			}
+			seen[resolved] = true
+			resources = append(resources, models.Resource{
+				URL:  resolved,
+				Kind: rs.kind,
+				Type: classifyLink(resolved, page, scope),
+			})
+		})
+	}
+	return resources
+}
+
+// auditResources reports the page's resources. With config set they are
+// checked like links, except those the page may not contact; a nil config
+// only lists them.
+func (a *Analyzer) auditResources(ctx context.Context, pc *pageContext, resources []models.Resource, config *CheckLinksConfig) *models.ResourceReport {
+	if len(resources) == 0 {
+		return nil
+	}
+
+	report := &models.ResourceReport{ByKind: make(map[string]int)}
+	var statuses map[string]models.LinkStatus
+	if config != nil {
+		var links []models.Link
+		for _, resource := range resources {
+			if a.optOut.containsURL(resource.URL) || outOfScope(resource.URL) {
+				continue
+			}
+			links = append(links, models.Link{URL: resource.URL, Type: resource.Type})
+		}
+		statuses = make(map[string]models.LinkStatus, len(links))
+		for _, status := range pc.checked.check(ctx, links, *config) {
+			statuses[status.URL] = status
+		}
+	}
+
+	for _, resource := range resources {
+		report.ByKind[resource.Kind]++
+		if status, ok := statuses[resource.URL]; ok && !status.Blocked {
+			resource.Checked = true
+			resource.StatusCode = status.StatusCode
+			resource.Error = status.Error
+			if resource.Error != "" {
+				report.Broken++
+			}
+		}
+		report.Resources = append(report.Resources, resource)
+	}
+	return report
+}
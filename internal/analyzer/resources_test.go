@@ -0,0 +1,105 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"website-analyzer/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestExtractResources(t *testing.T) {
+	html := `<html><head>
+		<link rel="StyleSheet" href="/css/site.css">
+		<link rel="preload" href="/fonts/body.woff2" as="font">
+		<link rel="modulepreload" href="/js/app.mjs">
+		<link rel="prefetch" href="https://cdn.example.net/next.js">
+		<link rel="icon" href="/favicon.ico">
+		<script src="/js/app.js"></script>
+		<script src="/css/site.css"></script>
+		<script>inline()</script>
+	</head><body><iframe src="https://video.example.org/embed/1"></iframe><iframe src="javascript:void(0)"></iframe></body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resources := ExtractResources(doc, "https://example.com/", LinkScope{})
+	want := []models.Resource{
+		{URL: "https://example.com/css/site.css", Kind: models.ResourceStylesheet, Type: models.LinkTypeInternal},
+		{URL: "https://example.com/js/app.js", Kind: models.ResourceScript, Type: models.LinkTypeInternal},
+		{URL: "https://example.com/fonts/body.woff2", Kind: models.ResourcePreload, Type: models.LinkTypeInternal},
+		{URL: "https://example.com/js/app.mjs", Kind: models.ResourcePreload, Type: models.LinkTypeInternal},
+		{URL: "https://cdn.example.net/next.js", Kind: models.ResourcePrefetch, Type: models.LinkTypeExternal},
+		{URL: "https://video.example.org/embed/1", Kind: models.ResourceIframe, Type: models.LinkTypeExternal},
+	}
+	if len(resources) != len(want) {
+		t.Fatalf("Expected %d resources, got %+v", len(want), resources)
+	}
+	for i := range want {
+		if resources[i] != want[i] {
+			t.Errorf("Resource %d: expected %+v, got %+v", i, want[i], resources[i])
+		}
+	}
+}
+
+func TestAnalyzeResources(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<html><head><link rel="stylesheet" href="/site.css"><script src="/missing.js"></script></head><body></body></html>`))
+		case "/site.css":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	a := NewAnalyzer(&Config{
+		RequestTimeout:  2 * time.Second,
+		LinkTimeout:     time.Second,
+		LinkMaxAttempts: 1,
+		MaxWorkers:      2,
+		MaxResponseSize: 1024 * 1024,
+		MaxURLLength:    2048,
+		MaxRedirects:    5,
+		Profiles: map[string]Profile{
+			"list":  {Name: "list", Checks: []Check{ResourcesCheck}},
+			"check": {Name: "check", Checks: []Check{ResourcesCheck}, DeepAnalysis: true},
+		},
+	})
+
+	listed, err := a.AnalyzeWithOptions(context.Background(), ts.URL, AnalyzeOptions{Profile: "list"})
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if r := listed.Resources; r == nil || len(r.Resources) != 2 || r.Resources[0].Checked || r.Broken != 0 {
+		t.Errorf("Expected resources to be listed unchecked, got %+v", r)
+	}
+
+	checked, err := a.AnalyzeWithOptions(context.Background(), ts.URL, AnalyzeOptions{Profile: "check"})
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	r := checked.Resources
+	if r == nil || r.Broken != 1 || r.ByKind[models.ResourceStylesheet] != 1 || r.ByKind[models.ResourceScript] != 1 {
+		t.Fatalf("Expected a stylesheet and a broken script, got %+v", r)
+	}
+	if css := r.Resources[0]; !css.Checked || css.Error != "" {
+		t.Errorf("Expected the stylesheet to load, got %+v", css)
+	}
+	if js := r.Resources[1]; !js.Checked || js.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected the script to be missing, got %+v", js)
+	}
+}
@@ -0,0 +1,144 @@
+package analyzer
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	"website-analyzer/internal/models"
+)
+
+// SplitRestricted groups internal links by their first path segment and
+// pulls out sections where every link answered 401 or 403. It returns those
+// sections and the statuses that remain for the broken link report.
+func SplitRestricted(statuses []models.LinkStatus) ([]models.RestrictedSection, []models.LinkStatus) {
+	type group struct {
+		restricted []models.LinkStatus
+		other      int
+	}
+	groups := make(map[string]*group)
+
+	for _, status := range statuses {
+		if status.Type != models.LinkTypeInternal || status.Blocked {
+			continue
+		}
+		key := sectionPath(status.URL)
+		g, ok := groups[key]
+		if !ok {
+			g = &group{}
+			groups[key] = g
+		}
+		if isAuthStatus(status.StatusCode) {
+			g.restricted = append(g.restricted, status)
+		} else {
+			g.other++
+		}
+	}
+
+	var sections []models.RestrictedSection
+	restrictedURLs := make(map[string]bool)
+	for path, g := range groups {
+		if len(g.restricted) == 0 || g.other > 0 {
+			continue
+		}
+
+		section := models.RestrictedSection{Path: path}
+		for _, status := range g.restricted {
+			section.URLs = append(section.URLs, status.URL)
+			restrictedURLs[status.URL] = true
+			if !slices.Contains(section.StatusCodes, status.StatusCode) {
+				section.StatusCodes = append(section.StatusCodes, status.StatusCode)
+			}
+			schemes, realms := parseAuthenticate(status.Authenticate)
+			for _, scheme := range schemes {
+				if !slices.Contains(section.Schemes, scheme) {
+					section.Schemes = append(section.Schemes, scheme)
+				}
+			}
+			for _, realm := range realms {
+				if !slices.Contains(section.Realms, realm) {
+					section.Realms = append(section.Realms, realm)
+				}
+			}
+		}
+		sort.Ints(section.StatusCodes)
+		sort.Strings(section.URLs)
+		sections = append(sections, section)
+	}
+
+	sort.Slice(sections, func(i, j int) bool {
+		return sections[i].Path < sections[j].Path
+	})
+
+	var remaining []models.LinkStatus
+	for _, status := range statuses {
+		if !restrictedURLs[status.URL] {
+			remaining = append(remaining, status)
+		}
+	}
+
+	return sections, remaining
+}
+
+func isAuthStatus(code int) bool {
+	return code == http.StatusUnauthorized || code == http.StatusForbidden
+}
+
+// sectionPath returns the first path segment of a URL, e.g. "/admin"
+func sectionPath(linkURL string) string {
+	u, err := url.Parse(linkURL)
+	if err != nil {
+		return "/"
+	}
+	first, _, _ := strings.Cut(strings.Trim(u.Path, "/"), "/")
+	return "/" + first
+}
+
+// parseAuthenticate extracts auth schemes and realms from a
+// WWW-Authenticate header such as `Basic realm="Admin", Bearer`
+func parseAuthenticate(header string) ([]string, []string) {
+	var schemes, realms []string
+
+	for _, part := range splitOutsideQuotes(header, ',') {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		// A part starting with a bare token (no '=') opens a new challenge
+		first, rest, _ := strings.Cut(part, " ")
+		if !strings.Contains(first, "=") {
+			schemes = append(schemes, first)
+			part = strings.TrimSpace(rest)
+		}
+
+		key, value, found := strings.Cut(part, "=")
+		if found && strings.EqualFold(strings.TrimSpace(key), "realm") {
+			realms = append(realms, strings.Trim(strings.TrimSpace(value), `"`))
+		}
+	}
+
+	return schemes, realms
+}
+
+func splitOutsideQuotes(s string, sep rune) []string {
+	var parts []string
+	var current strings.Builder
+	quoted := false
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			current.WriteRune(r)
+		case r == sep && !quoted:
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	return append(parts, current.String())
+}
@@ -0,0 +1,45 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+
+	"website-analyzer/internal/models"
+)
+
+func TestSplitRestricted(t *testing.T) {
+	statuses := []models.LinkStatus{
+		{URL: "https://example.com/admin/users", Type: models.LinkTypeInternal, StatusCode: 401, Authenticate: `Basic realm="Admin Area", charset="UTF-8"`, Error: "HTTP 401"},
+		{URL: "https://example.com/admin/settings", Type: models.LinkTypeInternal, StatusCode: 403, Error: "HTTP 403"},
+		{URL: "https://example.com/api/v1", Type: models.LinkTypeInternal, StatusCode: 401, Authenticate: `Bearer realm="api", error="invalid_token", Digest realm="legacy, v1", nonce="abc"`, Error: "HTTP 401"},
+		{URL: "https://example.com/docs/a", Type: models.LinkTypeInternal, StatusCode: 403, Error: "HTTP 403"},
+		{URL: "https://example.com/docs/b", Type: models.LinkTypeInternal, StatusCode: 200},
+		{URL: "https://example.com/gone", Type: models.LinkTypeInternal, StatusCode: 404, Error: "HTTP 404"},
+		{URL: "https://other.com/private", Type: models.LinkTypeExternal, StatusCode: 401, Error: "HTTP 401"},
+	}
+
+	sections, remaining := SplitRestricted(statuses)
+
+	if len(sections) != 2 {
+		t.Fatalf("Expected 2 restricted sections, got %+v", sections)
+	}
+
+	admin := sections[0]
+	if admin.Path != "/admin" || !reflect.DeepEqual(admin.StatusCodes, []int{401, 403}) || len(admin.URLs) != 2 {
+		t.Errorf("Unexpected admin section: %+v", admin)
+	}
+	if !reflect.DeepEqual(admin.Schemes, []string{"Basic"}) || !reflect.DeepEqual(admin.Realms, []string{"Admin Area"}) {
+		t.Errorf("Unexpected admin auth: %v %v", admin.Schemes, admin.Realms)
+	}
+
+	api := sections[1]
+	if !reflect.DeepEqual(api.Schemes, []string{"Bearer", "Digest"}) || !reflect.DeepEqual(api.Realms, []string{"api", "legacy, v1"}) {
+		t.Errorf("Unexpected api auth: %v %v", api.Schemes, api.Realms)
+	}
+
+	// Mixed sections, other errors and external links stay in the broken list
+	errors := InaccessibleLinks(remaining)
+	if len(errors) != 3 {
+		t.Errorf("Expected 3 remaining inaccessible links, got %+v", errors)
+	}
+}
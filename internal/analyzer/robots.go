@@ -0,0 +1,172 @@
+package analyzer
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxRobotsSize follows Google's 500KiB robots.txt limit
+const maxRobotsSize = 500 * 1024
+
+// robotsTxt is a parsed robots.txt file
+type robotsTxt struct {
+	Raw      string
+	Groups   []robotsGroup
+	Sitemaps []string
+}
+
+// robotsGroup is a set of rules shared by one or more user agents
+type robotsGroup struct {
+	Agents []string
+	Rules  []robotsRule
+}
+
+// robotsRule is a single Allow or Disallow line
+type robotsRule struct {
+	Allow bool
+	Path  string
+}
+
+// fetchRobots downloads and parses /robots.txt for the target's origin
+func fetchRobots(ctx context.Context, client *http.Client, targetURL string) (*robotsTxt, error) {
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return nil, err
+	}
+
+	robotsURL := u.Scheme + "://" + u.Host + "/robots.txt"
+	body, _, err := fetchBody(ctx, client, robotsURL, maxRobotsSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseRobots(string(body)), nil
+}
+
+// parseRobots parses robots.txt content. Consecutive user-agent lines start
+// a shared group; rules before any user-agent are ignored.
+func parseRobots(content string) *robotsTxt {
+	robots := &robotsTxt{Raw: content}
+	var current *robotsGroup
+	lastWasAgent := false
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if current == nil || !lastWasAgent {
+				robots.Groups = append(robots.Groups, robotsGroup{})
+				current = &robots.Groups[len(robots.Groups)-1]
+			}
+			current.Agents = append(current.Agents, strings.ToLower(value))
+			lastWasAgent = true
+			continue
+		case "allow", "disallow":
+			if current != nil {
+				current.Rules = append(current.Rules, robotsRule{Allow: key == "allow", Path: value})
+			}
+		case "sitemap":
+			if value != "" {
+				robots.Sitemaps = append(robots.Sitemaps, value)
+			}
+		}
+		lastWasAgent = false
+	}
+
+	return robots
+}
+
+// robotsAgent is the product token our requests identify as
+const robotsAgent = "webpageanalyzer"
+
+// allowed reports whether robots.txt lets agent fetch rawURL. Rules come
+// from the groups naming agent, or the * groups when none do; the longest
+// matching path wins and Allow wins ties, as in RFC 9309.
+func (r *robotsTxt) allowed(agent, rawURL string) bool {
+	if r == nil {
+		return true
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return true
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+
+	rules := r.rulesFor(agent)
+	allow, longest := true, -1
+	for _, rule := range rules {
+		if rule.Path == "" || !matchRobotsPath(rule.Path, path) {
+			continue
+		}
+		if n := len(rule.Path); n > longest || (n == longest && rule.Allow) {
+			allow, longest = rule.Allow, n
+		}
+	}
+	return allow
+}
+
+// rulesFor merges the rules of every group that applies to agent
+func (r *robotsTxt) rulesFor(agent string) []robotsRule {
+	var specific, wildcard []robotsRule
+	for _, group := range r.Groups {
+		for _, name := range group.Agents {
+			if name == agent {
+				specific = append(specific, group.Rules...)
+				break
+			}
+			if name == "*" {
+				wildcard = append(wildcard, group.Rules...)
+				break
+			}
+		}
+	}
+	if specific != nil {
+		return specific
+	}
+	return wildcard
+}
+
+// matchRobotsPath matches path against a robots.txt pattern where * matches
+// any run of characters and a trailing $ anchors the end
+func matchRobotsPath(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	for i, part := range parts[1:] {
+		if anchored && i == len(parts)-2 {
+			return strings.HasSuffix(rest, part)
+		}
+		idx := strings.Index(rest, part)
+		if idx < 0 {
+			return false
+		}
+		rest = rest[idx+len(part):]
+	}
+	return !anchored || rest == ""
+}
@@ -0,0 +1,131 @@
+package analyzer
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// knownRobotsDirectives are the directives understood by major crawlers
+var knownRobotsDirectives = toSet("user-agent", "allow", "disallow", "sitemap", "crawl-delay", "host", "clean-param")
+
+// nonProductionHostHints mark hosts where blocking all crawlers is expected
+var nonProductionHostHints = []string{"staging", "stage.", "dev.", "test.", "preview", "localhost", "127.0.0.1", "uat."}
+
+// lintRobots checks robots.txt for syntax problems, rules crawlers resolve
+// differently than authors expect, and risky production settings
+func lintRobots(content string, host string) []string {
+	var warnings []string
+	seenAgent := false
+	lineNo := 0
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			warnings = append(warnings, fmt.Sprintf("line %d: missing ':' separator in %q", lineNo, line))
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch {
+		case key == "noindex" || key == "nofollow":
+			warnings = append(warnings, fmt.Sprintf("line %d: %q is not supported in robots.txt and is ignored", lineNo, key))
+		case !knownRobotsDirectives[key]:
+			warnings = append(warnings, fmt.Sprintf("line %d: unknown directive %q", lineNo, key))
+		case key == "user-agent":
+			seenAgent = true
+			if value == "" {
+				warnings = append(warnings, fmt.Sprintf("line %d: empty user-agent", lineNo))
+			}
+		case (key == "allow" || key == "disallow") && !seenAgent:
+			warnings = append(warnings, fmt.Sprintf("line %d: %s rule appears before any user-agent and is ignored", lineNo, key))
+		case (key == "allow" || key == "disallow") && value != "" && !strings.HasPrefix(value, "/") && !strings.HasPrefix(value, "*"):
+			warnings = append(warnings, fmt.Sprintf("line %d: %s path %q should start with '/'", lineNo, key, value))
+		case key == "sitemap":
+			if u, err := url.Parse(value); err != nil || !u.IsAbs() {
+				warnings = append(warnings, fmt.Sprintf("line %d: sitemap URL %q must be absolute", lineNo, value))
+			}
+		}
+	}
+
+	robots := parseRobots(content)
+	warnings = append(warnings, lintRobotsGroups(robots, host)...)
+
+	if len(robots.Sitemaps) == 0 {
+		warnings = append(warnings, "no Sitemap declaration found")
+	}
+
+	return warnings
+}
+
+// lintRobotsGroups flags duplicate groups, conflicting rules and a blanket
+// Disallow on production hosts
+func lintRobotsGroups(robots *robotsTxt, host string) []string {
+	var warnings []string
+	agentGroups := make(map[string]int)
+
+	for _, group := range robots.Groups {
+		for _, agent := range group.Agents {
+			agentGroups[agent]++
+			if agentGroups[agent] == 2 {
+				warnings = append(warnings, fmt.Sprintf("user-agent %q has multiple groups; crawlers merge or pick one inconsistently", agent))
+			}
+		}
+
+		allowed := make(map[string]bool)
+		disallowed := make(map[string]bool)
+		for _, rule := range group.Rules {
+			if rule.Path == "" {
+				continue
+			}
+			if rule.Allow {
+				allowed[rule.Path] = true
+			} else {
+				disallowed[rule.Path] = true
+			}
+		}
+		for path := range allowed {
+			if disallowed[path] {
+				warnings = append(warnings, fmt.Sprintf("path %q is both allowed and disallowed for %s; Allow wins for equal-length rules", path, strings.Join(group.Agents, ", ")))
+			}
+		}
+	}
+
+	if blocksAllCrawlers(robots) && isProductionHost(host) {
+		warnings = append(warnings, "Disallow: / blocks all crawlers from the entire site on a production host")
+	}
+
+	return warnings
+}
+
+func isWildcardGroup(group robotsGroup) bool {
+	for _, agent := range group.Agents {
+		if agent == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func isProductionHost(host string) bool {
+	host = strings.ToLower(host)
+	for _, hint := range nonProductionHostHints {
+		if strings.Contains(host, hint) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLintRobots(t *testing.T) {
+	content := `Disallow: /early
+User-agent: *
+Disallow: /
+Disallow: /admin
+Allow: /admin
+Noindex: /private
+Crawl-delay: 5
+Foo: bar
+Allow: images
+Sitemap: /sitemap.xml
+
+User-agent: *
+Disallow: /tmp
+`
+
+	warnings := lintRobots(content, "www.example.com")
+	joined := strings.Join(warnings, "\n")
+
+	expected := []string{
+		"line 1: disallow rule appears before any user-agent",
+		"line 6: \"noindex\" is not supported",
+		"line 8: unknown directive \"foo\"",
+		"line 9: allow path \"images\" should start with '/'",
+		"line 10: sitemap URL \"/sitemap.xml\" must be absolute",
+		"user-agent \"*\" has multiple groups",
+		"path \"/admin\" is both allowed and disallowed",
+		"Disallow: / blocks all crawlers",
+	}
+
+	for _, want := range expected {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Missing warning %q in:\n%s", want, joined)
+		}
+	}
+
+	if strings.Contains(joined, "crawl-delay") {
+		t.Errorf("Crawl-delay is a known directive, got:\n%s", joined)
+	}
+}
+
+func TestLintRobotsStagingAndSitemap(t *testing.T) {
+	warnings := lintRobots("User-agent: *\nDisallow: /\n", "staging.example.com")
+
+	if len(warnings) != 1 || warnings[0] != "no Sitemap declaration found" {
+		t.Errorf("Expected only the missing sitemap warning on staging, got %v", warnings)
+	}
+}
@@ -0,0 +1,82 @@
+package analyzer
+
+import "testing"
+
+func TestParseRobots(t *testing.T) {
+	content := `# comment
+Disallow: /ignored
+User-agent: Googlebot
+User-agent: Bingbot
+Disallow: /private # trailing comment
+Allow: /private/public
+
+User-agent: *
+Disallow: /
+
+Sitemap: https://example.com/sitemap.xml
+`
+
+	robots := parseRobots(content)
+
+	if len(robots.Groups) != 2 {
+		t.Fatalf("Expected 2 groups, got %d", len(robots.Groups))
+	}
+
+	first := robots.Groups[0]
+	if len(first.Agents) != 2 || first.Agents[1] != "bingbot" {
+		t.Errorf("Expected shared group for googlebot and bingbot, got %v", first.Agents)
+	}
+	if len(first.Rules) != 2 || first.Rules[0].Path != "/private" || !first.Rules[1].Allow {
+		t.Errorf("Unexpected rules: %+v", first.Rules)
+	}
+
+	if len(robots.Sitemaps) != 1 || robots.Sitemaps[0] != "https://example.com/sitemap.xml" {
+		t.Errorf("Unexpected sitemaps: %v", robots.Sitemaps)
+	}
+}
+
+func TestRobotsAllowed(t *testing.T) {
+	robots := parseRobots(`User-agent: *
+Disallow: /private
+Allow: /private/public
+Disallow: /*.pdf$
+Disallow: /search?
+
+User-agent: OtherBot
+Disallow: /
+`)
+
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://example.com/", true},
+		{"https://example.com/about", true},
+		{"https://example.com/private", false},
+		{"https://example.com/private/page", false},
+		{"https://example.com/private/public/page", true},
+		{"https://example.com/files/report.pdf", false},
+		{"https://example.com/files/report.pdf?v=2", true},
+		{"https://example.com/search?q=go", false},
+		{"https://example.com/search", true},
+	}
+	for _, tt := range tests {
+		if got := robots.allowed(robotsAgent, tt.url); got != tt.want {
+			t.Errorf("allowed(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+
+	if robots.allowed("otherbot", "https://example.com/") {
+		t.Error("Expected the specific group to apply to otherbot")
+	}
+
+	specific := parseRobots("User-agent: *\nDisallow: /\n\nUser-agent: WebPageAnalyzer\nDisallow: /admin\n")
+	if !specific.allowed(robotsAgent, "https://example.com/page") || specific.allowed(robotsAgent, "https://example.com/admin") {
+		t.Error("Expected our own group to replace the * group")
+	}
+
+	var missing *robotsTxt
+	if !missing.allowed(robotsAgent, "https://example.com/private") {
+		t.Error("Expected a missing robots.txt to allow everything")
+	}
+}
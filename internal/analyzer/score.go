@@ -0,0 +1,100 @@
+package analyzer
+
+import (
+	"slices"
+
+	"website-analyzer/internal/models"
+)
+
+// Score deductions. Each category starts at 100 and is clamped at 0.
+const (
+	penaltyMissingTitle       = 20
+	penaltyTitleLength        = 5
+	penaltyMissingDescription = 15
+	penaltyMissingH1          = 15
+	penaltyMultipleH1         = 5
+	penaltyMissingCanonical   = 5
+	penaltyNoindex            = 30
+	penaltyReadinessIssue     = 10
+	penaltyInvalidEntity      = 5
+	penaltyAccessibilityIssue = 5
+	penaltyContrastFailure    = 2
+)
+
+// ScoreResult condenses an analysis into 0-100 scores per category plus an
+// overall average, so pages can be compared and tracked over time
+func ScoreResult(result *models.AnalysisResult) *models.Scores {
+	scores := &models.Scores{
+		SEO:           scoreSEO(result),
+		Accessibility: scoreAccessibility(result),
+		Links:         scoreLinks(result),
+	}
+	scores.Overall = (scores.SEO + scores.Accessibility + scores.Links) / 3
+	return scores
+}
+
+func scoreSEO(result *models.AnalysisResult) int {
+	score := 100
+
+	if result.Title == "" || result.Title == "No title" {
+		score -= penaltyMissingTitle
+	} else if n := len([]rune(result.Title)); n < 10 || n > serpTitleLimit {
+		score -= penaltyTitleLength
+	}
+
+	switch h1 := result.Headings["h1"]; {
+	case h1 == 0:
+		score -= penaltyMissingH1
+	case h1 > 1:
+		score -= penaltyMultipleH1
+	}
+
+	if seo := result.SEO; seo != nil {
+		if seo.Description == "" {
+			score -= penaltyMissingDescription
+		}
+		if seo.Canonical == "" {
+			score -= penaltyMissingCanonical
+		}
+		if slices.Contains(seo.Robots, "noindex") || slices.Contains(seo.Robots, "none") {
+			score -= penaltyNoindex
+		}
+	}
+
+	if result.Readiness != nil {
+		score -= penaltyReadinessIssue * len(result.Readiness.Issues)
+	}
+
+	if result.StructuredData != nil {
+		for _, entity := range result.StructuredData.Entities {
+			if len(entity.MissingRequired) > 0 {
+				score -= penaltyInvalidEntity
+			}
+		}
+	}
+
+	return max(score, 0)
+}
+
+func scoreAccessibility(result *models.AnalysisResult) int {
+	report := result.Accessibility
+	if report == nil {
+		return 100
+	}
+
+	score := 100 - penaltyAccessibilityIssue*len(report.Issues)
+	if report.Contrast != nil {
+		score -= penaltyContrastFailure * report.Contrast.Failures
+	}
+	return max(score, 0)
+}
+
+// scoreLinks is the share of links that responded successfully
+func scoreLinks(result *models.AnalysisResult) int {
+	total := result.InternalLinks + result.ExternalLinks
+	if total == 0 {
+		return 100
+	}
+	broken := min(len(result.InaccessibleLinks), total)
+	return 100 * (total - broken) / total
+}
@@ -0,0 +1,56 @@
+package analyzer
+
+import (
+	"testing"
+
+	"website-analyzer/internal/models"
+)
+
+func TestScoreResult(t *testing.T) {
+	result := &models.AnalysisResult{
+		Title:             "Acme Widgets and Gadgets",
+		Headings:          map[string]int{"h1": 2},
+		InternalLinks:     8,
+		ExternalLinks:     2,
+		InaccessibleLinks: []models.LinkError{{URL: "https://example.com/gone"}},
+		SEO:               &models.SEOReport{Robots: []string{"noindex"}},
+		Readiness:         &models.ReadinessReport{Issues: []models.ReadinessIssue{{Rule: RuleMetaNoindex}}},
+		StructuredData: &models.StructuredDataReport{Entities: []models.StructuredDataEntity{
+			{Type: "Product", MissingRequired: []string{"name"}},
+			{Type: "Organization"},
+		}},
+		Accessibility: &models.AccessibilityReport{
+			Issues:   make([]models.AccessibilityIssue, 3),
+			Contrast: &models.ContrastReport{Failures: 5},
+		},
+	}
+
+	scores := ScoreResult(result)
+
+	// 100 - multiple h1 (5) - description (15) - canonical (5) - noindex (30) - readiness (10) - entity (5)
+	if scores.SEO != 30 {
+		t.Errorf("Expected SEO score 30, got %d", scores.SEO)
+	}
+	// 100 - 3*5 - 5*2
+	if scores.Accessibility != 75 {
+		t.Errorf("Expected accessibility score 75, got %d", scores.Accessibility)
+	}
+	if scores.Links != 90 {
+		t.Errorf("Expected links score 90, got %d", scores.Links)
+	}
+	if scores.Overall != 65 {
+		t.Errorf("Expected overall score 65, got %d", scores.Overall)
+	}
+}
+
+func TestScoreResultClamps(t *testing.T) {
+	result := &models.AnalysisResult{
+		Title:         "No title",
+		Accessibility: &models.AccessibilityReport{Issues: make([]models.AccessibilityIssue, 40)},
+	}
+
+	scores := ScoreResult(result)
+	if scores.Accessibility != 0 || scores.Links != 100 {
+		t.Errorf("Unexpected scores: %+v", scores)
+	}
+}
@@ -0,0 +1,167 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"website-analyzer/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Text alternative, form label, language and heading rule identifiers
+const (
+	RuleMissingAlt       = "image-alt-missing"
+	RuleUnlabeledControl = "form-control-unlabeled"
+	RuleMissingLang      = "html-lang-missing"
+	RuleInvalidLang      = "html-lang-invalid"
+	RuleNoHeadings       = "heading-none"
+	RuleEmptyHeading     = "heading-empty"
+	RuleSkippedHeading   = "heading-level-skipped"
+)
+
+// langPattern loosely matches a BCP 47 language tag such as "en" or "pt-BR"
+var langPattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{1,8})*$`)
+
+// unlabeledInputTypes are input types that are labeled by their value or
+// aren't shown at all
+var unlabeledInputTypes = toSet("hidden", "submit", "reset", "button", "image")
+
+// checkSemantics reports images without text alternatives, form controls
+// without labels, a missing or malformed page language and a poor heading
+// outline. Links without text are covered by the accessible name check.
+func checkSemantics(doc *goquery.Document) (string, []models.AccessibilityIssue) {
+	var issues []models.AccessibilityIssue
+
+	lang, issue := checkLang(doc)
+	if issue != nil {
+		issues = append(issues, *issue)
+	}
+	issues = append(issues, checkAltText(doc)...)
+	issues = append(issues, checkFormLabels(doc)...)
+	issues = append(issues, checkHeadingOutline(doc)...)
+
+	return lang, issues
+}
+
+// checkLang returns the page language declared on <html>
+func checkLang(doc *goquery.Document) (string, *models.AccessibilityIssue) {
+	html := doc.Find("html").First()
+	lang := strings.TrimSpace(html.AttrOr("lang", html.AttrOr("xml:lang", "")))
+
+	switch {
+	case lang == "":
+		return "", &models.AccessibilityIssue{
+			Rule:    RuleMissingLang,
+			Element: "<html>",
+			Message: "page has no lang attribute, so screen readers may use the wrong pronunciation",
+		}
+	case !langPattern.MatchString(lang):
+		return lang, &models.AccessibilityIssue{
+			Rule:    RuleInvalidLang,
+			Element: "<html>",
+			Message: fmt.Sprintf("lang %q is not a valid language tag", lang),
+		}
+	}
+	return lang, nil
+}
+
+// checkAltText flags images without an alt attribute. An empty alt marks
+// an image as decorative and is accepted.
+func checkAltText(doc *goquery.Document) []models.AccessibilityIssue {
+	var issues []models.AccessibilityIssue
+
+	doc.Find(`img, input[type="image"], area[href]`).Each(func(i int, s *goquery.Selection) {
+		if _, ok := s.Attr("alt"); ok || isHiddenFromAT(s) || isPresentational(s) {
+			return
+		}
+		// An ARIA label gives the image a name just as alt would
+		if strings.TrimSpace(s.AttrOr("aria-label", "")) != "" || s.AttrOr("aria-labelledby", "") != "" {
+			return
+		}
+		issues = append(issues, newIssue(RuleMissingAlt, s, "image has no alt attribute; use alt=\"\" if it is decorative"))
+	})
+
+	return issues
+}
+
+// checkFormLabels flags form controls with no label, aria-label,
+// aria-labelledby or title. A placeholder is not a label.
+func checkFormLabels(doc *goquery.Document) []models.AccessibilityIssue {
+	var issues []models.AccessibilityIssue
+
+	doc.Find("input, select, textarea").Each(func(i int, s *goquery.Selection) {
+		if unlabeledInputTypes[strings.ToLower(s.AttrOr("type", ""))] || isHiddenFromAT(s) {
+			return
+		}
+		if controlLabel(doc, s) == "" {
+			issues = append(issues, newIssue(RuleUnlabeledControl, s, "form control has no associated label"))
+		}
+	})
+
+	return issues
+}
+
+// controlLabel returns the label text of a form control
+func controlLabel(doc *goquery.Document, s *goquery.Selection) string {
+	if labelledBy, ok := s.Attr("aria-labelledby"); ok {
+		for _, ref := range strings.Fields(labelledBy) {
+			if text := strings.TrimSpace(doc.Find("#" + escapeID(ref)).Text()); text != "" {
+				return text
+			}
+		}
+	}
+	if label := strings.TrimSpace(s.AttrOr("aria-label", "")); label != "" {
+		return label
+	}
+	if id := s.AttrOr("id", ""); id != "" {
+		if text := strings.TrimSpace(doc.Find(`label[for="` + id + `"]`).Text()); text != "" {
+			return text
+		}
+	}
+	if text := strings.TrimSpace(s.Closest("label").Text()); text != "" {
+		return text
+	}
+	return strings.TrimSpace(s.AttrOr("title", ""))
+}
+
+// checkHeadingOutline flags pages without headings, empty headings and
+// headings that skip a level on the way down (h2 followed by h4)
+func checkHeadingOutline(doc *goquery.Document) []models.AccessibilityIssue {
+	var issues []models.AccessibilityIssue
+
+	headings := doc.Find("h1, h2, h3, h4, h5, h6").FilterFunction(func(i int, s *goquery.Selection) bool {
+		return !isHiddenFromAT(s)
+	})
+	if headings.Length() == 0 {
+		return []models.AccessibilityIssue{{
+			Rule:    RuleNoHeadings,
+			Element: "<body>",
+			Message: "page has no headings to navigate by",
+		}}
+	}
+
+	previous := 0
+	headings.Each(func(i int, s *goquery.Selection) {
+		level := int(goquery.NodeName(s)[1] - '0')
+
+		if accessibleName(doc, s) == "" {
+			issues = append(issues, newIssue(RuleEmptyHeading, s, "heading has no text"))
+		}
+		if previous > 0 && level > previous+1 {
+			issues = append(issues, newIssue(RuleSkippedHeading, s,
+				fmt.Sprintf("h%d follows h%d, skipping a level", level, previous)))
+		}
+		previous = level
+	})
+
+	return issues
+}
+
+// isPresentational reports whether the element's role removes it from the
+// accessibility tree
+func isPresentational(s *goquery.Selection) bool {
+	role := strings.ToLower(firstField(s.AttrOr("role", "")))
+	return role == "presentation" || role == "none"
+}
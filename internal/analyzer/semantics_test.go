@@ -0,0 +1,90 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestCheckSemantics(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		rule     string
+		expected int
+	}{
+		{
+			name:     "Images without alt",
+			html:     `<img src="a.png"><img src="b.png" alt=""><img src="c.png" aria-label="Chart"><img src="d.png" role="presentation"><input type="image" src="go.png">`,
+			rule:     RuleMissingAlt,
+			expected: 2,
+		},
+		{
+			name: "Unlabeled form controls",
+			html: `<label for="email">Email</label><input id="email" type="email">
+				<label>Name <input type="text"></label>
+				<input type="search" aria-label="Search">
+				<input type="text" placeholder="Phone">
+				<select></select>
+				<input type="hidden" name="csrf"><input type="submit" value="Send">`,
+			rule:     RuleUnlabeledControl,
+			expected: 2,
+		},
+		{
+			name:     "Missing lang",
+			html:     `<html><body><h1>Hi</h1></body></html>`,
+			rule:     RuleMissingLang,
+			expected: 1,
+		},
+		{
+			name:     "Invalid lang",
+			html:     `<html lang="english"><body><h1>Hi</h1></body></html>`,
+			rule:     RuleInvalidLang,
+			expected: 1,
+		},
+		{
+			name:     "Valid lang",
+			html:     `<html lang="pt-BR"><body><h1>Oi</h1></body></html>`,
+			rule:     RuleInvalidLang,
+			expected: 0,
+		},
+		{
+			name:     "No headings",
+			html:     `<p>Just text</p>`,
+			rule:     RuleNoHeadings,
+			expected: 1,
+		},
+		{
+			name:     "Empty heading",
+			html:     `<h1>Title</h1><h2> </h2><h2><img src="logo.png" alt="Logo"></h2>`,
+			rule:     RuleEmptyHeading,
+			expected: 1,
+		},
+		{
+			name:     "Skipped heading levels",
+			html:     `<h1>Title</h1><h3>Too deep</h3><h2>Section</h2><h3>Fine</h3><h2>Back up</h2><h5>Too deep</h5>`,
+			rule:     RuleSkippedHeading,
+			expected: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countRule(t, tt.html, tt.rule); got != tt.expected {
+				t.Errorf("Expected %d %s issues, got %d", tt.expected, tt.rule, got)
+			}
+		})
+	}
+}
+
+func TestAnalyzeAccessibilityLang(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<html lang="de"><body><h1>Hallo</h1></body></html>`))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	if report := AnalyzeAccessibility(doc); report.Lang != "de" {
+		t.Errorf("Expected page language de, got %q", report.Lang)
+	}
+}
@@ -0,0 +1,79 @@
+package analyzer
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"website-analyzer/internal/models"
+)
+
+// ExtractSEO collects the page's search and social metadata: description,
+// keywords, canonical URL, robots directives, Open Graph and Twitter Cards
+func ExtractSEO(doc *goquery.Document, baseURL string) *models.SEOReport {
+	report := &models.SEOReport{
+		Canonical:   canonicalURL(doc, baseURL),
+		OpenGraph:   make(map[string]string),
+		TwitterCard: make(map[string]string),
+	}
+
+	doc.Find("meta[content]").Each(func(i int, s *goquery.Selection) {
+		name := strings.ToLower(strings.TrimSpace(s.AttrOr("name", "")))
+		property := strings.ToLower(strings.TrimSpace(s.AttrOr("property", "")))
+		content := strings.TrimSpace(s.AttrOr("content", ""))
+
+		switch {
+		case name == "description" && report.Description == "":
+			report.Description = content
+		case name == "keywords" && report.Keywords == nil:
+			for _, keyword := range strings.Split(content, ",") {
+				if keyword = strings.TrimSpace(keyword); keyword != "" {
+					report.Keywords = append(report.Keywords, keyword)
+				}
+			}
+		case name == "robots":
+			for _, directive := range strings.Split(content, ",") {
+				if directive = strings.ToLower(strings.TrimSpace(directive)); directive != "" {
+					report.Robots = append(report.Robots, directive)
+				}
+			}
+		case strings.HasPrefix(property, "og:"):
+			setFirst(report.OpenGraph, strings.TrimPrefix(property, "og:"), content)
+		case strings.HasPrefix(name, "twitter:"):
+			setFirst(report.TwitterCard, strings.TrimPrefix(name, "twitter:"), content)
+		case strings.HasPrefix(property, "twitter:"):
+			// Some sites use property= for Twitter tags; crawlers accept both
+			setFirst(report.TwitterCard, strings.TrimPrefix(property, "twitter:"), content)
+		}
+	})
+
+	return report
+}
+
+// setFirst keeps the first value seen for a key, matching crawler behavior
+// for repeated tags such as og:image
+func setFirst(m map[string]string, key, value string) {
+	if _, ok := m[key]; !ok && key != "" {
+		m[key] = value
+	}
+}
+
+// canonicalURL returns the page's absolute rel="canonical" URL, if declared
+func canonicalURL(doc *goquery.Document, baseURL string) string {
+	href, ok := doc.Find(`link[rel~="canonical"][href]`).First().Attr("href")
+	if !ok {
+		return ""
+	}
+
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return ""
+	}
+	base = documentBase(doc, base)
+	resolved, err := resolveURL(base, href)
+	if err != nil {
+		return ""
+	}
+	return resolved
+}
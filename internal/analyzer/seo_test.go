@@ -0,0 +1,49 @@
+package analyzer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestExtractSEO(t *testing.T) {
+	html := `<html><head>
+		<meta name="Description" content=" A page about widgets. ">
+		<meta name="description" content="Second description is ignored">
+		<meta name="keywords" content="widgets, gadgets, ,tools">
+		<meta name="robots" content="NOINDEX, follow">
+		<link rel="canonical" href="/widgets">
+		<meta property="og:title" content="Widgets">
+		<meta property="og:image" content="https://example.com/a.png">
+		<meta property="og:image" content="https://example.com/b.png">
+		<meta name="twitter:card" content="summary_large_image">
+		<meta property="twitter:site" content="@example">
+	</head><body></body></html>`
+
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+	report := ExtractSEO(doc, "https://example.com/widgets?ref=nav")
+
+	if report.Description != "A page about widgets." {
+		t.Errorf("Unexpected description %q", report.Description)
+	}
+	if !reflect.DeepEqual(report.Keywords, []string{"widgets", "gadgets", "tools"}) {
+		t.Errorf("Unexpected keywords %v", report.Keywords)
+	}
+	if report.Canonical != "https://example.com/widgets" {
+		t.Errorf("Unexpected canonical %q", report.Canonical)
+	}
+	if !reflect.DeepEqual(report.Robots, []string{"noindex", "follow"}) {
+		t.Errorf("Unexpected robots %v", report.Robots)
+	}
+
+	expectedOG := map[string]string{"title": "Widgets", "image": "https://example.com/a.png"}
+	if !reflect.DeepEqual(report.OpenGraph, expectedOG) {
+		t.Errorf("Unexpected Open Graph %v", report.OpenGraph)
+	}
+	expectedTwitter := map[string]string{"card": "summary_large_image", "site": "@example"}
+	if !reflect.DeepEqual(report.TwitterCard, expectedTwitter) {
+		t.Errorf("Unexpected Twitter Card %v", report.TwitterCard)
+	}
+}
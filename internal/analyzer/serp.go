@@ -0,0 +1,66 @@
+package analyzer
+
+import (
+	"net/url"
+	"strings"
+
+	"website-analyzer/internal/models"
+)
+
+// Approximate Google snippet limits. Google truncates by pixel width
+// (~600px for titles); character counts are a close, font-free stand-in.
+const (
+	serpTitleLimit       = 60
+	serpDescriptionLimit = 160
+	serpBreadcrumbParts  = 3
+)
+
+// BuildSERPPreview renders how the page would likely appear as a search
+// result: truncated title and description plus a breadcrumb-style URL
+func BuildSERPPreview(title, description, pageURL string) *models.SERPPreview {
+	preview := &models.SERPPreview{Breadcrumb: serpBreadcrumb(pageURL)}
+	preview.Title, preview.TitleTruncated = truncateWords(title, serpTitleLimit)
+	preview.Description, preview.DescriptionTruncated = truncateWords(description, serpDescriptionLimit)
+	return preview
+}
+
+// truncateWords shortens s to at most limit characters at a word boundary,
+// appending an ellipsis the way search engines do
+func truncateWords(s string, limit int) (string, bool) {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s, false
+	}
+
+	cut := string(runes[:limit])
+	if idx := strings.LastIndex(cut, " "); idx > limit/2 {
+		cut = cut[:idx]
+	}
+	return strings.TrimRight(cut, " ,.;:-") + " ...", true
+}
+
+// serpBreadcrumb formats a URL as "example.com › blog › post"
+func serpBreadcrumb(pageURL string) string {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return pageURL
+	}
+
+	parts := []string{u.Scheme + "://" + u.Host}
+	for _, segment := range strings.Split(strings.Trim(u.Path, "/"), "/") {
+		if segment == "" {
+			continue
+		}
+		if len(parts) > serpBreadcrumbParts {
+			parts = append(parts, "...")
+			break
+		}
+		if decoded, err := url.PathUnescape(segment); err == nil {
+			segment = decoded
+		}
+		parts = append(parts, segment)
+	}
+
+	return strings.Join(parts, " › ")
+}
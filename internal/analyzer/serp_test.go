@@ -0,0 +1,36 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildSERPPreview(t *testing.T) {
+	title := "The Complete Guide to Choosing Industrial Widgets for Small Manufacturing Businesses"
+	description := "Short description."
+
+	preview := BuildSERPPreview(title, description, "https://example.com/guides/widgets/choosing%20widgets/part-2/extra?ref=1")
+
+	if !preview.TitleTruncated || !strings.HasSuffix(preview.Title, " ...") {
+		t.Errorf("Expected truncated title, got %q", preview.Title)
+	}
+	if len([]rune(preview.Title)) > serpTitleLimit+4 {
+		t.Errorf("Title too long: %q", preview.Title)
+	}
+	if strings.Contains(preview.Title, "Manufacturing") {
+		t.Errorf("Expected cut before the limit, got %q", preview.Title)
+	}
+
+	if preview.DescriptionTruncated || preview.Description != description {
+		t.Errorf("Expected untouched description, got %q", preview.Description)
+	}
+
+	expected := "https://example.com › guides › widgets › choosing widgets › ..."
+	if preview.Breadcrumb != expected {
+		t.Errorf("Expected breadcrumb %q, got %q", expected, preview.Breadcrumb)
+	}
+
+	if got := BuildSERPPreview("Home", "", "https://example.com/").Breadcrumb; got != "https://example.com" {
+		t.Errorf("Expected bare host breadcrumb, got %q", got)
+	}
+}
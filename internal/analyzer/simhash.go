@@ -0,0 +1,44 @@
+package analyzer
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// simhashShingle is how many consecutive words make up each feature
+const simhashShingle = 3
+
+// SimHash fingerprints text so near-duplicate pages get fingerprints that
+// differ in few bits: every run of three words votes on each of 64 bits
+// with its hash. It returns the fingerprint as 16 hex digits, or "" for
+// text without words.
+func SimHash(text string) string {
+	words := strings.Fields(strings.ToLower(text))
+	if len(words) == 0 {
+		return ""
+	}
+
+	// Text shorter than a shingle is a single feature
+	var votes [64]int
+	for i := 0; i == 0 || i+simhashShingle <= len(words); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:min(i+simhashShingle, len(words))], " ")))
+		sum := h.Sum64()
+		for bit := range votes {
+			if sum&(1<<bit) != 0 {
+				votes[bit]++
+			} else {
+				votes[bit]--
+			}
+		}
+	}
+
+	var fingerprint uint64
+	for bit, vote := range votes {
+		if vote > 0 {
+			fingerprint |= 1 << bit
+		}
+	}
+	return fmt.Sprintf("%016x", fingerprint)
+}
@@ -0,0 +1,36 @@
+package analyzer
+
+import (
+	"math/bits"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSimHash(t *testing.T) {
+	distance := func(a, b string) int {
+		x, _ := strconv.ParseUint(a, 16, 64)
+		y, _ := strconv.ParseUint(b, 16, 64)
+		return bits.OnesCount64(x ^ y)
+	}
+
+	article := strings.Repeat("The quick brown fox jumps over the lazy dog near the quiet river bank. ", 20)
+	edited := strings.Replace(article, "lazy dog", "sleepy cat", 1)
+	other := strings.Repeat("Quarterly revenue grew as new customers signed up for the premium plan. ", 20)
+
+	if SimHash(article) != SimHash(strings.ToUpper(article)) {
+		t.Error("Expected case to be ignored")
+	}
+	if d := distance(SimHash(article), SimHash(edited)); d > 8 {
+		t.Errorf("Expected a small edit to change few bits, %d changed", d)
+	}
+	if d := distance(SimHash(article), SimHash(other)); d < 16 {
+		t.Errorf("Expected unrelated text to differ in many bits, %d differ", d)
+	}
+	if got := SimHash("Hi"); len(got) != 16 {
+		t.Errorf("Expected a short text to be fingerprinted, got %q", got)
+	}
+	if got := SimHash("  "); got != "" {
+		t.Errorf("Expected no fingerprint without words, got %q", got)
+	}
+}
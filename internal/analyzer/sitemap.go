@@ -0,0 +1,169 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// maxSitemapSize is the protocol's uncompressed size limit (50MiB)
+	maxSitemapSize = 50 * 1024 * 1024
+	// maxChildSitemaps bounds how many sitemaps of an index are read
+	maxChildSitemaps = 10
+)
+
+// sitemapFile is a fetched and parsed sitemap or sitemap index
+type sitemapFile struct {
+	URL      string
+	Size     int
+	IsIndex  bool
+	URLs     []sitemapURL
+	Children []string
+}
+
+type sitemapURL struct {
+	Loc        string        `xml:"loc"`
+	LastMod    string        `xml:"lastmod"`
+	Alternates []sitemapLink `xml:"http://www.w3.org/1999/xhtml link"`
+}
+
+type sitemapLink struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
+
+type sitemapURLSet struct {
+	URLs []sitemapURL `xml:"url"`
+}
+
+type sitemapIndexFile struct {
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// discoverSitemaps returns sitemap URLs declared in robots.txt, falling back
+// to /sitemap.xml at the origin
+func discoverSitemaps(robots *robotsTxt, targetURL string) []string {
+	if robots != nil && len(robots.Sitemaps) > 0 {
+		return robots.Sitemaps
+	}
+
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return nil
+	}
+	return []string{u.Scheme + "://" + u.Host + "/sitemap.xml"}
+}
+
+// fetchSitemap downloads and parses a sitemap or sitemap index
+func fetchSitemap(ctx context.Context, client *http.Client, sitemapURL string) (*sitemapFile, error) {
+	body, _, err := fetchBody(ctx, client, sitemapURL, maxSitemapSize+1)
+	if err != nil {
+		return nil, err
+	}
+	return parseSitemap(sitemapURL, body)
+}
+
+func parseSitemap(sitemapURL string, body []byte) (*sitemapFile, error) {
+	file := &sitemapFile{URL: sitemapURL, Size: len(body)}
+
+	root, err := rootElement(body)
+	if err != nil {
+		return file, err
+	}
+
+	switch root {
+	case "sitemapindex":
+		file.IsIndex = true
+		var index sitemapIndexFile
+		if err := xml.Unmarshal(body, &index); err != nil {
+			return file, fmt.Errorf("invalid sitemap index XML: %w", err)
+		}
+		for _, s := range index.Sitemaps {
+			file.Children = append(file.Children, strings.TrimSpace(s.Loc))
+		}
+	case "urlset":
+		var set sitemapURLSet
+		if err := xml.Unmarshal(body, &set); err != nil {
+			return file, fmt.Errorf("invalid sitemap XML: %w", err)
+		}
+		for _, u := range set.URLs {
+			u.Loc = strings.TrimSpace(u.Loc)
+			file.URLs = append(file.URLs, u)
+		}
+	default:
+		return file, fmt.Errorf("unexpected sitemap root element <%s>", root)
+	}
+
+	return file, nil
+}
+
+// loadSitemaps fetches the given sitemaps, expanding indexes one level deep
+func loadSitemaps(ctx context.Context, client *http.Client, sitemapURLs []string) ([]*sitemapFile, []error) {
+	var files []*sitemapFile
+	var errs []error
+
+	for _, sitemapURL := range sitemapURLs {
+		file, err := fetchSitemap(ctx, client, sitemapURL)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sitemapURL, err))
+			if file == nil {
+				continue
+			}
+		}
+		files = append(files, file)
+
+		for i, child := range file.Children {
+			if i >= maxChildSitemaps {
+				break
+			}
+			childFile, err := fetchSitemap(ctx, client, child)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", child, err))
+				if childFile == nil {
+					continue
+				}
+			}
+			files = append(files, childFile)
+		}
+	}
+
+	return files, errs
+}
+
+// rootElement returns the local name of the document's root element
+func rootElement(body []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", fmt.Errorf("invalid XML: %w", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+// sameURL compares URLs ignoring host case and a trailing slash
+func sameURL(a, b string) bool {
+	return normalizeURL(a) == normalizeURL(b)
+}
+
+func normalizeURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return raw
+	}
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	return u.String()
+}
@@ -0,0 +1,109 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+// maxSitemapEntries is the protocol's limit on URLs (or child sitemaps) per file
+const maxSitemapEntries = 50000
+
+// w3cDatetimeLayouts are the lastmod formats allowed by the sitemap protocol
+var w3cDatetimeLayouts = []string{
+	"2006",
+	"2006-01",
+	"2006-01-02",
+	"2006-01-02T15:04Z07:00",
+	time.RFC3339,
+	time.RFC3339Nano,
+}
+
+// validateSitemaps checks fetched sitemaps against the sitemaps.org protocol
+// and flags the analyzed page when it is listed under a non-canonical URL
+func validateSitemaps(files []*sitemapFile, pageURL, canonical string) ([]models.SitemapInfo, []string) {
+	var infos []models.SitemapInfo
+	var issues []string
+	now := time.Now()
+
+	for _, file := range files {
+		info := models.SitemapInfo{URL: file.URL, IsIndex: file.IsIndex, Size: file.Size}
+		if file.IsIndex {
+			info.Entries = len(file.Children)
+		} else {
+			info.Entries = len(file.URLs)
+		}
+		infos = append(infos, info)
+
+		report := func(format string, args ...any) {
+			issues = append(issues, file.URL+": "+fmt.Sprintf(format, args...))
+		}
+
+		if file.Size > maxSitemapSize {
+			report("exceeds the 50MiB uncompressed size limit")
+		}
+		if info.Entries > maxSitemapEntries {
+			report("lists %d entries, more than the %d allowed", info.Entries, maxSitemapEntries)
+		}
+
+		base, err := url.Parse(file.URL)
+		if err != nil {
+			continue
+		}
+
+		seen := make(map[string]bool)
+		for _, entry := range file.URLs {
+			if entry.Loc == "" {
+				report("<url> entry without <loc>")
+				continue
+			}
+
+			loc, err := url.Parse(entry.Loc)
+			if err != nil || !loc.IsAbs() {
+				report("%s is not an absolute URL", entry.Loc)
+				continue
+			}
+			if !strings.EqualFold(loc.Host, base.Host) || loc.Scheme != base.Scheme {
+				report("%s is outside the sitemap's origin", entry.Loc)
+			}
+			if loc.Fragment != "" {
+				report("%s contains a fragment", entry.Loc)
+			}
+
+			key := normalizeURL(entry.Loc)
+			if seen[key] {
+				report("%s is listed more than once", entry.Loc)
+			}
+			seen[key] = true
+
+			if entry.LastMod != "" {
+				lastMod, ok := parseW3CDatetime(entry.LastMod)
+				switch {
+				case !ok:
+					report("%s has invalid lastmod %q (expected W3C Datetime)", entry.Loc, entry.LastMod)
+				case lastMod.After(now.Add(24 * time.Hour)):
+					report("%s has lastmod %q in the future", entry.Loc, entry.LastMod)
+				}
+			}
+
+			if canonical != "" && sameURL(entry.Loc, pageURL) && !sameURL(canonical, pageURL) {
+				report("%s is listed but its canonical URL is %s", entry.Loc, canonical)
+			}
+		}
+	}
+
+	return infos, issues
+}
+
+func parseW3CDatetime(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range w3cDatetimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,71 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestValidateSitemaps(t *testing.T) {
+	body := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/</loc><lastmod>2024-05-01</lastmod></url>
+  <url><loc>https://example.com/a</loc><lastmod>2024-05-01T10:00:00+02:00</lastmod></url>
+  <url><loc>https://example.com/a/</loc></url>
+  <url><loc>https://example.com/b</loc><lastmod>01/05/2024</lastmod></url>
+  <url><loc>https://example.com/c</loc><lastmod>2999-01-01</lastmod></url>
+  <url><loc>https://other.com/d</loc></url>
+  <url><loc>/relative</loc></url>
+  <url><loc>https://example.com/e#top</loc></url>
+  <url><loc>https://example.com/print</loc></url>
+</urlset>`)
+
+	file, err := parseSitemap("https://example.com/sitemap.xml", body)
+	if err != nil {
+		t.Fatalf("parseSitemap failed: %v", err)
+	}
+
+	infos, issues := validateSitemaps([]*sitemapFile{file}, "https://example.com/print", "https://example.com/article")
+	if len(infos) != 1 || infos[0].Entries != 9 {
+		t.Errorf("Expected one sitemap with 9 entries, got %+v", infos)
+	}
+
+	joined := strings.Join(issues, "\n")
+	expected := []string{
+		"https://example.com/a/ is listed more than once",
+		`https://example.com/b has invalid lastmod "01/05/2024"`,
+		"https://example.com/c has lastmod \"2999-01-01\" in the future",
+		"https://other.com/d is outside the sitemap's origin",
+		"/relative is not an absolute URL",
+		"https://example.com/e#top contains a fragment",
+		"https://example.com/print is listed but its canonical URL is https://example.com/article",
+	}
+	for _, want := range expected {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Missing issue %q in:\n%s", want, joined)
+		}
+	}
+	if len(issues) != len(expected) {
+		t.Errorf("Expected %d issues, got %d:\n%s", len(expected), len(issues), joined)
+	}
+}
+
+func TestValidateSitemapsLimits(t *testing.T) {
+	file := &sitemapFile{
+		URL:  "https://example.com/sitemap.xml",
+		Size: maxSitemapSize + 1,
+		URLs: make([]sitemapURL, maxSitemapEntries+1),
+	}
+	for i := range file.URLs {
+		file.URLs[i].Loc = fmt.Sprintf("https://example.com/p%d", i)
+	}
+
+	_, issues := validateSitemaps([]*sitemapFile{file}, "https://example.com/", "")
+	joined := strings.Join(issues, "\n")
+
+	for _, want := range []string{"exceeds the 50MiB", "lists 50001 entries"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Missing issue %q", want)
+		}
+	}
+}
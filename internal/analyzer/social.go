@@ -0,0 +1,162 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"net/url"
+
+	"website-analyzer/internal/models"
+)
+
+// maxSocialImageSize caps how much of og:image is downloaded (Facebook's
+// own limit is 8MB)
+const maxSocialImageSize = 8 * 1024 * 1024
+
+// Minimum image sizes the platforms require before showing a large card
+const (
+	minLargeImageWidth  = 600
+	minLargeImageHeight = 315
+	minImageDimension   = 200
+)
+
+// BuildSocialPreviews assembles Facebook, Twitter and LinkedIn share cards
+// from the page metadata, applying each platform's fallbacks, and fetches
+// the share image to check it is reachable and large enough
+func BuildSocialPreviews(ctx context.Context, seo *models.SEOReport, title, pageURL string, client *http.Client) *models.SocialReport {
+	og, tw := seo.OpenGraph, seo.TwitterCard
+	domain := getDomain(pageURL)
+
+	report := &models.SocialReport{}
+
+	facebook := models.SocialPreview{
+		Platform:    "Facebook",
+		Title:       firstNonEmpty(og["title"], title),
+		Description: firstNonEmpty(og["description"], seo.Description),
+		Image:       og["image"],
+		Domain:      domain,
+	}
+	facebook.Missing = missingKeys(og, "og:", "title", "description", "image", "url")
+
+	card := firstNonEmpty(tw["card"], "summary")
+	twitter := models.SocialPreview{
+		Platform:    "Twitter",
+		Title:       firstNonEmpty(tw["title"], og["title"], title),
+		Description: firstNonEmpty(tw["description"], og["description"], seo.Description),
+		Image:       firstNonEmpty(tw["image"], og["image"]),
+		Domain:      domain,
+		CardType:    card,
+	}
+	twitter.Missing = missingKeys(tw, "twitter:", "card")
+
+	linkedin := models.SocialPreview{
+		Platform: "LinkedIn",
+		Title:    firstNonEmpty(og["title"], title),
+		Image:    og["image"],
+		Domain:   domain,
+	}
+	linkedin.Missing = missingKeys(og, "og:", "title", "image")
+
+	report.Previews = []models.SocialPreview{facebook, twitter, linkedin}
+
+	// Cards display the resolved image so the preview matches the crawlers
+	if base, err := url.Parse(pageURL); err == nil {
+		for i := range report.Previews {
+			if resolved, err := resolveURL(base, report.Previews[i].Image); err == nil {
+				report.Previews[i].Image = resolved
+			}
+		}
+	}
+
+	if imageURL := firstNonEmpty(og["image"], tw["image"]); imageURL != "" {
+		report.Image = fetchSocialImage(ctx, client, pageURL, imageURL)
+		report.Warnings = socialImageWarnings(report.Image, card)
+	}
+
+	return report
+}
+
+// fetchSocialImage downloads the share image and decodes its dimensions
+func fetchSocialImage(ctx context.Context, client *http.Client, pageURL, imageURL string) *models.SocialImage {
+	info := &models.SocialImage{URL: imageURL}
+
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	ref, err := url.Parse(imageURL)
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	info.Relative = !ref.IsAbs()
+	info.URL = base.ResolveReference(ref).String()
+
+	body, status, err := fetchBody(ctx, client, info.URL, maxSocialImageSize)
+	info.StatusCode = status
+	if err != nil {
+		info.Error = err.Error()
+		return info
+	}
+	info.Size = int64(len(body))
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(body))
+	if err != nil {
+		info.Error = fmt.Sprintf("could not read image dimensions: %v", err)
+		return info
+	}
+	info.Format = format
+	info.Width = config.Width
+	info.Height = config.Height
+
+	return info
+}
+
+func socialImageWarnings(img *models.SocialImage, card string) []string {
+	var warnings []string
+
+	if img.Relative {
+		warnings = append(warnings, "Share image URL is relative; some crawlers require an absolute URL")
+	}
+	if img.Error != "" {
+		return append(warnings, "Share image could not be loaded: "+img.Error)
+	}
+
+	if img.Width < minImageDimension || img.Height < minImageDimension {
+		warnings = append(warnings, fmt.Sprintf("Share image is %dx%d; platforms ignore images smaller than %dx%d", img.Width, img.Height, minImageDimension, minImageDimension))
+	} else if img.Width < minLargeImageWidth || img.Height < minLargeImageHeight {
+		warnings = append(warnings, fmt.Sprintf("Share image is %dx%d; large cards need at least %dx%d (1200x630 recommended)", img.Width, img.Height, minLargeImageWidth, minLargeImageHeight))
+	}
+
+	if card == "summary_large_image" && img.Width > 0 && img.Width < 2*img.Height {
+		warnings = append(warnings, "Twitter large image cards are cropped to 2:1; the share image is closer to square")
+	}
+
+	return warnings
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// missingKeys lists required tags that are absent, with their prefix
+func missingKeys(tags map[string]string, prefix string, keys ...string) []string {
+	var missing []string
+	for _, key := range keys {
+		if tags[key] == "" {
+			missing = append(missing, prefix+key)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,79 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+func TestBuildSocialPreviews(t *testing.T) {
+	var thumbnail bytes.Buffer
+	_ = png.Encode(&thumbnail, image.NewRGBA(image.Rect(0, 0, 400, 300)))
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/share.png" {
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(thumbnail.Bytes())
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer ts.Close()
+
+	seo := &models.SEOReport{
+		Description: "Meta description",
+		OpenGraph:   map[string]string{"title": "OG Title", "image": "/share.png"},
+		TwitterCard: map[string]string{"card": "summary_large_image"},
+	}
+
+	report := BuildSocialPreviews(context.Background(), seo, "Page Title", ts.URL+"/post", &http.Client{Timeout: time.Second})
+
+	if len(report.Previews) != 3 {
+		t.Fatalf("Expected 3 previews, got %d", len(report.Previews))
+	}
+
+	facebook, twitter := report.Previews[0], report.Previews[1]
+	if facebook.Title != "OG Title" || facebook.Description != "Meta description" || facebook.Image != ts.URL+"/share.png" {
+		t.Errorf("Unexpected Facebook preview: %+v", facebook)
+	}
+	if !reflect.DeepEqual(facebook.Missing, []string{"og:description", "og:url"}) {
+		t.Errorf("Unexpected Facebook missing tags: %v", facebook.Missing)
+	}
+	if twitter.Title != "OG Title" || twitter.CardType != "summary_large_image" || len(twitter.Missing) != 0 {
+		t.Errorf("Unexpected Twitter preview: %+v", twitter)
+	}
+
+	img := report.Image
+	if img == nil || img.Width != 400 || img.Height != 300 || img.Format != "png" || !img.Relative {
+		t.Fatalf("Unexpected share image: %+v", img)
+	}
+
+	joined := strings.Join(report.Warnings, "\n")
+	for _, want := range []string{"relative", "large cards need at least", "cropped to 2:1"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Missing warning %q in:\n%s", want, joined)
+		}
+	}
+}
+
+func TestBuildSocialPreviewsWithoutMetadata(t *testing.T) {
+	seo := &models.SEOReport{OpenGraph: map[string]string{}, TwitterCard: map[string]string{}}
+
+	report := BuildSocialPreviews(context.Background(), seo, "Page Title", "https://example.com/", http.DefaultClient)
+
+	if report.Image != nil {
+		t.Errorf("Expected no image fetch without og:image, got %+v", report.Image)
+	}
+	if twitter := report.Previews[1]; twitter.Title != "Page Title" || twitter.CardType != "summary" {
+		t.Errorf("Expected title fallback and default card, got %+v", twitter)
+	}
+}
@@ -0,0 +1,46 @@
+package analyzer
+
+import (
+	"log/slog"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+// Stages of a page analysis besides the checks, which are timed under
+// their check names
+const (
+	StageFetch        = "fetch"
+	StageParse        = "parse"
+	StageExtractLinks = "extract_links"
+	StageRegions      = "regions"
+	StageSitemaps     = "sitemaps"
+	StageKeyword      = "keyword"
+	StageSimHash      = "simhash"
+	StageScore        = "score"
+)
+
+// stageClock records how long each stage of a page analysis takes, in the
+// order they ran
+type stageClock struct {
+	stages []models.StageTiming
+}
+
+// start begins timing stage; calling the returned function ends it
+func (c *stageClock) start(stage string) func() {
+	began := time.Now()
+	return func() {
+		ms := float64(time.Since(began).Microseconds()) / 1000
+		c.stages = append(c.stages, models.StageTiming{Stage: stage, DurationMs: ms})
+	}
+}
+
+// StagesAttr groups the stage timings of a result for logging, in
+// milliseconds by stage
+func StagesAttr(stages []models.StageTiming) slog.Attr {
+	attrs := make([]any, 0, len(stages))
+	for _, stage := range stages {
+		attrs = append(attrs, slog.Float64(stage.Stage, stage.DurationMs))
+	}
+	return slog.Group("stages_ms", attrs...)
+}
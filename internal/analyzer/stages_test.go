@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+func TestAnalyzeRecordsStages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html lang="en"><head><title>Stages</title></head><body><a href="/about">About</a></body></html>`))
+	}))
+	defer server.Close()
+
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	a := NewAnalyzer(&Config{
+		RequestTimeout:  5 * time.Second,
+		LinkTimeout:     2 * time.Second,
+		MaxWorkers:      2,
+		MaxResponseSize: 1024 * 1024,
+		MaxURLLength:    2048,
+	})
+	result, err := a.AnalyzeWithOptions(context.Background(), server.URL, AnalyzeOptions{Profile: "quick"})
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	var names []string
+	for _, stage := range result.Stages {
+		if stage.DurationMs < 0 {
+			t.Errorf("Negative duration for %+v", stage)
+		}
+		names = append(names, stage.Stage)
+	}
+	// The quick profile leaves out most checks, and so their stages
+	want := "fetch parse extract_links links seo accessibility readiness score"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("Expected stages %q, got %q", want, got)
+	}
+}
+
+func TestStagesAttr(t *testing.T) {
+	attr := StagesAttr([]models.StageTiming{{Stage: StageFetch, DurationMs: 12.5}, {Stage: "links", DurationMs: 40}})
+	if attr.Key != "stages_ms" || attr.Value.Kind() != slog.KindGroup {
+		t.Fatalf("Expected a stages_ms group, got %v", attr)
+	}
+	if group := attr.Value.Group(); len(group) != 2 || group[0].Key != "fetch" || group[0].Value.Float64() != 12.5 {
+		t.Errorf("Unexpected group %v", group)
+	}
+}
@@ -0,0 +1,288 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"website-analyzer/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Structured data formats
+const (
+	FormatJSONLD    = "json-ld"
+	FormatMicrodata = "microdata"
+)
+
+// schemaSpec lists required and recommended properties of a schema.org type.
+// A path like "offers.price" looks into nested objects (every element of an
+// array must have it); "a|b" is satisfied by either property.
+type schemaSpec struct {
+	required    []string
+	recommended []string
+	// conditional requirements apply only when the keyed property is present
+	conditional map[string][]string
+}
+
+var articleSpec = schemaSpec{
+	required:    []string{"headline"},
+	recommended: []string{"image", "author", "datePublished", "dateModified", "publisher"},
+}
+
+// schemaSpecs follows the rich results requirements for common types
+var schemaSpecs = map[string]schemaSpec{
+	"Article":     articleSpec,
+	"NewsArticle": articleSpec,
+	"BlogPosting": articleSpec,
+	"Product": {
+		required:    []string{"name", "offers|review|aggregateRating"},
+		recommended: []string{"image", "description", "brand", "sku"},
+		conditional: map[string][]string{
+			"offers": {"offers.price|offers.lowPrice", "offers.priceCurrency"},
+		},
+	},
+	"FAQPage": {
+		required: []string{"mainEntity", "mainEntity.name", "mainEntity.acceptedAnswer", "mainEntity.acceptedAnswer.text"},
+	},
+	"BreadcrumbList": {
+		required: []string{"itemListElement", "itemListElement.position", "itemListElement.name|itemListElement.item.name"},
+	},
+	"Organization": {
+		recommended: []string{"name", "url", "logo"},
+	},
+	"LocalBusiness": {
+		required:    []string{"name", "address"},
+		recommended: []string{"telephone", "openingHoursSpecification", "geo", "url"},
+	},
+	"Event": {
+		required:    []string{"name", "startDate", "location"},
+		recommended: []string{"endDate", "description", "image", "offers", "organizer"},
+	},
+	"Recipe": {
+		required:    []string{"name", "image"},
+		recommended: []string{"author", "recipeIngredient", "recipeInstructions", "totalTime", "nutrition"},
+	},
+}
+
+// AnalyzeStructuredData parses JSON-LD blocks and microdata items, validates
+// the entities against schema.org rich result requirements and lists the
+// types present
+func AnalyzeStructuredData(doc *goquery.Document) *models.StructuredDataReport {
+	report := &models.StructuredDataReport{}
+
+	doc.Find(`script[type="application/ld+json"]`).Each(func(i int, s *goquery.Selection) {
+		var data any
+		if err := json.Unmarshal([]byte(s.Text()), &data); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("JSON-LD block %d: %v", i+1, err))
+			return
+		}
+
+		for _, entity := range jsonLDEntities(data) {
+			report.Entities = append(report.Entities, validateEntity(entity, FormatJSONLD))
+			report.Types = appendTypes(report.Types, entity)
+		}
+	})
+
+	items, errors := microdataItems(doc)
+	for _, item := range items {
+		report.Entities = append(report.Entities, validateEntity(item, FormatMicrodata))
+		report.Types = appendTypes(report.Types, item)
+	}
+	report.Errors = append(report.Errors, errors...)
+	slices.Sort(report.Types)
+
+	return report
+}
+
+// appendTypes adds the entity's schema.org types that aren't in types yet
+func appendTypes(types []string, entity map[string]any) []string {
+	for _, t := range schemaTypes(entity["@type"]) {
+		if !slices.Contains(types, t) {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// jsonLDEntities flattens top-level arrays and @graph containers
+func jsonLDEntities(data any) []map[string]any {
+	var entities []map[string]any
+
+	switch v := data.(type) {
+	case []any:
+		for _, item := range v {
+			entities = append(entities, jsonLDEntities(item)...)
+		}
+	case map[string]any:
+		if graph, ok := v["@graph"]; ok {
+			entities = append(entities, jsonLDEntities(graph)...)
+		} else {
+			entities = append(entities, v)
+		}
+	}
+
+	return entities
+}
+
+// validateEntity checks an entity against the spec for its type
+func validateEntity(entity map[string]any, format string) models.StructuredDataEntity {
+	types := schemaTypes(entity["@type"])
+	result := models.StructuredDataEntity{
+		Type:   strings.Join(types, ", "),
+		Format: format,
+	}
+	if result.Type == "" {
+		result.Type = "unknown"
+	}
+
+	for _, t := range types {
+		spec, ok := schemaSpecs[t]
+		if !ok {
+			continue
+		}
+		result.Validated = true
+
+		for _, path := range spec.required {
+			if !hasAnyPath(entity, path) {
+				result.MissingRequired = append(result.MissingRequired, path)
+			}
+		}
+		for property, paths := range spec.conditional {
+			if _, present := entity[property]; !present {
+				continue
+			}
+			for _, path := range paths {
+				if !hasAnyPath(entity, path) {
+					result.MissingRequired = append(result.MissingRequired, path)
+				}
+			}
+		}
+		for _, path := range spec.recommended {
+			if !hasAnyPath(entity, path) {
+				result.MissingRecommended = append(result.MissingRecommended, path)
+			}
+		}
+	}
+
+	return result
+}
+
+// schemaTypes normalizes @type values such as "schema:Product" or
+// "https://schema.org/Product" to bare type names
+func schemaTypes(value any) []string {
+	var raw []string
+	switch v := value.(type) {
+	case string:
+		raw = []string{v}
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				raw = append(raw, s)
+			}
+		}
+	}
+
+	types := make([]string, 0, len(raw))
+	for _, t := range raw {
+		if idx := strings.LastIndexAny(t, "/:#"); idx >= 0 {
+			t = t[idx+1:]
+		}
+		if t != "" {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// hasAnyPath checks "a.b|a.c.d" style alternatives. The shared prefix ("a")
+// is walked first so that every array element may satisfy a different
+// alternative.
+func hasAnyPath(entity map[string]any, alternatives string) bool {
+	var paths [][]string
+	for _, path := range strings.Split(alternatives, "|") {
+		paths = append(paths, strings.Split(path, "."))
+	}
+
+	prefix := 0
+	for prefix < len(paths[0])-1 {
+		segment := paths[0][prefix]
+		shared := true
+		for _, path := range paths[1:] {
+			if prefix >= len(path)-1 || path[prefix] != segment {
+				shared = false
+			}
+		}
+		if !shared {
+			break
+		}
+		prefix++
+	}
+
+	suffixes := make([][]string, len(paths))
+	for i, path := range paths {
+		suffixes[i] = path[prefix:]
+	}
+
+	return hasAlternatives(entity, paths[0][:prefix], suffixes)
+}
+
+func hasAlternatives(value any, prefix []string, suffixes [][]string) bool {
+	switch v := value.(type) {
+	case []any:
+		if len(v) == 0 {
+			return false
+		}
+		for _, item := range v {
+			if !hasAlternatives(item, prefix, suffixes) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		if len(prefix) > 0 {
+			child, ok := v[prefix[0]]
+			return ok && hasAlternatives(child, prefix[1:], suffixes)
+		}
+		for _, suffix := range suffixes {
+			if hasPath(v, suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasPath reports whether a non-empty value exists at the path. Arrays must
+// have the path on every element.
+func hasPath(value any, path []string) bool {
+	switch v := value.(type) {
+	case []any:
+		if len(v) == 0 {
+			return false
+		}
+		for _, item := range v {
+			if !hasPath(item, path) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		if len(path) == 0 {
+			return true
+		}
+		child, ok := v[path[0]]
+		if !ok {
+			return false
+		}
+		return hasPath(child, path[1:])
+	case string:
+		return len(path) == 0 && strings.TrimSpace(v) != ""
+	case nil:
+		return false
+	default:
+		return len(path) == 0
+	}
+}
@@ -0,0 +1,64 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestAnalyzeStructuredData(t *testing.T) {
+	html := `
+		<html><head>
+			<script type="application/ld+json">
+			{
+				"@context": "https://schema.org",
+				"@graph": [
+					{"@type": "Product", "name": "Widget", "offers": [{"@type": "Offer", "priceCurrency": "USD"}]},
+					{"@type": "BreadcrumbList", "itemListElement": [
+						{"@type": "ListItem", "position": 1, "name": "Home", "item": "https://example.com/"},
+						{"@type": "ListItem", "position": 2, "item": {"@id": "https://example.com/widgets", "name": "Widgets"}}
+					]}
+				]
+			}
+			</script>
+			<script type="application/ld+json">
+			{"@context": "https://schema.org", "@type": "FAQPage", "mainEntity": [
+				{"@type": "Question", "name": "Why?", "acceptedAnswer": {"@type": "Answer", "text": "Because."}},
+				{"@type": "Question", "name": "How?"}
+			]}
+			</script>
+			<script type="application/ld+json">{ "broken": </script>
+		</head></html>
+	`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	report := AnalyzeStructuredData(doc)
+
+	if len(report.Errors) != 1 {
+		t.Errorf("Expected 1 parse error, got %v", report.Errors)
+	}
+
+	if len(report.Entities) != 3 {
+		t.Fatalf("Expected 3 entities, got %d", len(report.Entities))
+	}
+
+	product := report.Entities[0]
+	if product.Type != "Product" || strings.Join(product.MissingRequired, ",") != "offers.price|offers.lowPrice" {
+		t.Errorf("Expected product to miss price, got %+v", product)
+	}
+
+	breadcrumb := report.Entities[1]
+	if len(breadcrumb.MissingRequired) != 0 {
+		t.Errorf("Expected valid breadcrumb, got %+v", breadcrumb)
+	}
+
+	faq := report.Entities[2]
+	if strings.Join(faq.MissingRequired, ",") != "mainEntity.acceptedAnswer,mainEntity.acceptedAnswer.text" {
+		t.Errorf("Expected FAQ to miss an answer, got %+v", faq)
+	}
+}
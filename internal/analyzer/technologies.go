@@ -0,0 +1,241 @@
+package analyzer
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"website-analyzer/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// defaultTechRules is the built-in rule set, see DefaultTechRules
+//
+//go:embed technologies.json
+var defaultTechRules []byte
+
+// defaultTrackerRules is the built-in rule set, see DefaultTrackerRules
+//
+//go:embed trackers.json
+var defaultTrackerRules []byte
+
+// TechRule recognizes one technology by patterns matched against the page.
+// Patterns are regular expressions, matched case-insensitively; the first
+// capture group of a match, if any, is taken as the version. An empty
+// header pattern matches any value, so the header's presence suffices.
+type TechRule struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	// Generator is matched against <meta name="generator"> content
+	Generator string `json:"generator,omitempty"`
+	// Scripts are matched against the resolved URLs of <script src>
+	Scripts []string `json:"scripts,omitempty"`
+	// Headers are matched against the values of response headers
+	Headers map[string]string `json:"headers,omitempty"`
+	// Cookies are matched against the names of cookies the page sets
+	Cookies []string `json:"cookies,omitempty"`
+	// Inline is matched against the content of inline scripts and
+	// <noscript> elements, where tracking snippets and pixels sit
+	Inline []string `json:"inline,omitempty"`
+
+	generator *regexp.Regexp
+	scripts   []*regexp.Regexp
+	inline    []*regexp.Regexp
+	headers   map[string]*regexp.Regexp
+	cookies   []*regexp.Regexp
+}
+
+// compile prepares the rule's patterns
+func (r *TechRule) compile() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("rule without a name")
+	}
+	compile := func(pattern string) (*regexp.Regexp, error) {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+		return re, nil
+	}
+
+	var err error
+	if r.Generator != "" {
+		if r.generator, err = compile(r.Generator); err != nil {
+			return err
+		}
+	}
+	r.scripts, r.cookies, r.inline = nil, nil, nil
+	for _, pattern := range r.Scripts {
+		re, err := compile(pattern)
+		if err != nil {
+			return err
+		}
+		r.scripts = append(r.scripts, re)
+	}
+	for _, pattern := range r.Inline {
+		re, err := compile(pattern)
+		if err != nil {
+			return err
+		}
+		r.inline = append(r.inline, re)
+	}
+	for _, pattern := range r.Cookies {
+		re, err := compile(pattern)
+		if err != nil {
+			return err
+		}
+		r.cookies = append(r.cookies, re)
+	}
+	r.headers = make(map[string]*regexp.Regexp, len(r.Headers))
+	for name, pattern := range r.Headers {
+		re, err := compile(pattern)
+		if err != nil {
+			return err
+		}
+		r.headers[http.CanonicalHeaderKey(name)] = re
+	}
+	return nil
+}
+
+// DefaultTechRules returns the built-in rules for common CMSs, frameworks,
+// analytics tools, JavaScript libraries, CDNs and servers
+func DefaultTechRules() []TechRule {
+	rules, err := parseTechRules(defaultTechRules)
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in technology rules: %v", err))
+	}
+	return rules
+}
+
+// DefaultTrackerRules returns the built-in rules for common analytics,
+// advertising, session recording and tag manager scripts, for
+// DetectTechnologies to list the trackers a page loads
+func DefaultTrackerRules() []TechRule {
+	rules, err := parseTechRules(defaultTrackerRules)
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in tracker rules: %v", err))
+	}
+	return rules
+}
+
+// LoadTechRules reads rules from the JSON file at path and merges them
+// into base: a rule replaces the base rule of the same name, others are
+// added
+func LoadTechRules(path string, base []TechRule) ([]TechRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read technology rules: %w", err)
+	}
+	loaded, err := parseTechRules(data)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := slices.Clone(base)
+	for _, rule := range loaded {
+		i := slices.IndexFunc(merged, func(r TechRule) bool { return strings.EqualFold(r.Name, rule.Name) })
+		if i >= 0 {
+			merged[i] = rule
+		} else {
+			merged = append(merged, rule)
+		}
+	}
+	return merged, nil
+}
+
+// parseTechRules decodes and compiles a JSON list of rules
+func parseTechRules(data []byte) ([]TechRule, error) {
+	var rules []TechRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("invalid technology rules: %w", err)
+	}
+	for i := range rules {
+		if err := rules[i].compile(); err != nil {
+			return nil, fmt.Errorf("invalid technology rules: %w", err)
+		}
+	}
+	return rules, nil
+}
+
+// DetectTechnologies lists the technologies whose rules match the page's
+// generator meta tags, script URLs, inline scripts, response headers or
+// cookies, sorted by category and name
+func DetectTechnologies(doc *goquery.Document, baseURL string, header http.Header, rules []TechRule) []models.Technology {
+	var generators, cookies, inline []string
+	doc.Find(`meta[name]`).Each(func(_ int, s *goquery.Selection) {
+		if name, _ := s.Attr("name"); strings.EqualFold(name, "generator") {
+			if content := strings.TrimSpace(s.AttrOr("content", "")); content != "" {
+				generators = append(generators, content)
+			}
+		}
+	})
+	scripts := weightResources(doc, baseURL)["scripts"]
+	doc.Find("script:not([src]), noscript").Each(func(_ int, s *goquery.Selection) {
+		if text := strings.TrimSpace(s.Text()); text != "" {
+			inline = append(inline, text)
+		}
+	})
+	for _, cookie := range (&http.Response{Header: header}).Cookies() {
+		cookies = append(cookies, cookie.Name)
+	}
+
+	var found []models.Technology
+	for i := range rules {
+		rule := &rules[i]
+		tech := models.Technology{Name: rule.Name, Category: rule.Category}
+		if rule.generator != nil {
+			for _, generator := range generators {
+				matchTechnology(&tech, rule.generator, generator, "meta generator")
+			}
+		}
+		for _, re := range rule.scripts {
+			for _, script := range scripts {
+				matchTechnology(&tech, re, script, "script "+script)
+			}
+		}
+		for _, re := range rule.inline {
+			for _, text := range inline {
+				matchTechnology(&tech, re, text, "inline snippet")
+			}
+		}
+		for name, re := range rule.headers {
+			for _, value := range header.Values(name) {
+				matchTechnology(&tech, re, value, "header "+name)
+			}
+		}
+		for _, re := range rule.cookies {
+			for _, cookie := range cookies {
+				matchTechnology(&tech, re, cookie, "cookie "+cookie)
+			}
+		}
+		if len(tech.Evidence) > 0 {
+			found = append(found, tech)
+		}
+	}
+	slices.SortFunc(found, func(a, b models.Technology) int {
+		if c := strings.Compare(a.Category, b.Category); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return found
+}
+
+// matchTechnology records evidence for tech when re matches value, taking
+// the first version a match captures
+func matchTechnology(tech *models.Technology, re *regexp.Regexp, value, evidence string) {
+	match := re.FindStringSubmatch(value)
+	if match == nil || slices.Contains(tech.Evidence, evidence) {
+		return
+	}
+	tech.Evidence = append(tech.Evidence, evidence)
+	if tech.Version == "" && len(match) > 1 {
+		tech.Version = match[1]
+	}
+}
@@ -0,0 +1,126 @@
+package analyzer
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"website-analyzer/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestDetectTechnologies(t *testing.T) {
+	html := `<html><head>
+		<meta name="Generator" content="WordPress 6.4.2">
+		<script src="/wp-includes/js/jquery/jquery.min.js?ver=3.7.1"></script>
+		<script src="https://code.jquery.com/jquery-3.7.1.min.js"></script>
+		<script async src="https://www.googletagmanager.com/gtag/js?id=G-XYZ"></script>
+		<script src="/app.js"></script>
+	</head><body></body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := http.Header{}
+	header.Set("Server", "nginx/1.25.3")
+	header.Set("CF-Ray", "8a1b2c3d4e5f-FRA")
+	header.Add("Set-Cookie", "_ga=GA1.1.123; Path=/")
+	header.Add("Set-Cookie", "PHPSESSID=abc; HttpOnly")
+
+	found := DetectTechnologies(doc, "https://example.com/", header, DefaultTechRules())
+	byName := make(map[string]models.Technology)
+	for _, tech := range found {
+		byName[tech.Name] = tech
+	}
+
+	for name, version := range map[string]string{
+		"WordPress":        "6.4.2",
+		"jQuery":           "3.7.1",
+		"Google Analytics": "",
+		"Nginx":            "1.25.3",
+		"Cloudflare":       "",
+		"PHP":              "",
+	} {
+		tech, ok := byName[name]
+		if !ok {
+			t.Errorf("Expected %s to be detected, got %+v", name, found)
+			continue
+		}
+		if tech.Version != version {
+			t.Errorf("Expected %s version %q, got %q", name, version, tech.Version)
+		}
+	}
+	if len(found) != 6 {
+		t.Errorf("Expected 6 technologies, got %+v", found)
+	}
+	if ga := byName["Google Analytics"]; !slices.Contains(ga.Evidence, "cookie _ga") || len(ga.Evidence) != 2 {
+		t.Errorf("Expected the gtag script and _ga cookie as evidence, got %v", ga.Evidence)
+	}
+	if !slices.IsSortedFunc(found, func(a, b models.Technology) int { return strings.Compare(a.Category, b.Category) }) {
+		t.Errorf("Expected technologies sorted by category, got %+v", found)
+	}
+}
+
+func TestLoadTechRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	os.WriteFile(path, []byte(`[
+		{"name": "nginx", "category": "Web server", "headers": {"Server": "^openresty"}},
+		{"name": "Acme CMS", "category": "CMS", "generator": "^Acme v(\\d+)", "scripts": ["/acme-static/"]}
+	]`), 0o644)
+
+	base := DefaultTechRules()
+	rules, err := LoadTechRules(path, base)
+	if err != nil {
+		t.Fatalf("LoadTechRules failed: %v", err)
+	}
+	if len(rules) != len(base)+1 {
+		t.Errorf("Expected one rule added and one replaced, got %d rules from %d", len(rules), len(base))
+	}
+
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(`<meta name="generator" content="Acme v7">`))
+	header := http.Header{"Server": {"nginx/1.25"}}
+	found := DetectTechnologies(doc, "https://example.com/", header, rules)
+	if len(found) != 1 || found[0].Name != "Acme CMS" || found[0].Version != "7" {
+		t.Errorf("Expected the added rule to match and the replaced one not to, got %+v", found)
+	}
+
+	for _, invalid := range []string{`{}`, `[{"category": "CMS"}]`, `[{"name": "Bad", "scripts": ["("]}]`} {
+		os.WriteFile(path, []byte(invalid), 0o644)
+		if _, err := LoadTechRules(path, base); err == nil {
+			t.Errorf("Expected %s to be rejected", invalid)
+		}
+	}
+}
+
+func TestDetectTrackers(t *testing.T) {
+	html := `<html><head>
+		<script async src="https://www.googletagmanager.com/gtag/js?id=G-XYZ"></script>
+		<script>window.dataLayer = window.dataLayer || []; gtag('config', 'G-XYZ');</script>
+		<script>!function(f,b,e,v,n,t,s){}(window, document,'script',
+			'https://connect.facebook.net/en_US/fbevents.js'); fbq('init', '1234');</script>
+		<noscript><img height="1" width="1" src="https://www.facebook.com/tr?id=1234&ev=PageView&noscript=1"></noscript>
+		<script>(function(h,o,t,j,a,r){h._hjSettings={hjid:1};})(window,document);</script>
+		<script src="/app.js"></script>
+	</head><body></body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := DetectTechnologies(doc, "https://example.com/", http.Header{}, DefaultTrackerRules())
+	var names []string
+	for _, tracker := range found {
+		names = append(names, tracker.Category+"/"+tracker.Name)
+	}
+	want := []string{"Advertising/Meta Pixel", "Analytics/Google Analytics", "Session recording/Hotjar"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("Expected trackers %v, got %v", want, names)
+	}
+	if ga := found[1]; !slices.Equal(ga.Evidence, []string{"script https://www.googletagmanager.com/gtag/js?id=G-XYZ", "inline snippet"}) {
+		t.Errorf("Expected the gtag script and snippet as evidence, got %v", ga.Evidence)
+	}
+}
@@ -0,0 +1,95 @@
+package analyzer
+
+import (
+	"cmp"
+	"net/url"
+	"slices"
+	"strings"
+
+	"website-analyzer/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// InventoryThirdParties groups every external URL the page references, from
+// links, scripts, stylesheets, images, fonts and iframes, by registrable
+// domain. Whether a URL is external follows scope, as for links; hosts
+// without a registrable domain, such as addresses, stand for themselves.
+func InventoryThirdParties(doc *goquery.Document, baseURL string, links []models.Link, scope LinkScope) *models.ThirdPartyReport {
+	page, err := url.Parse(baseURL)
+	if err != nil {
+		return nil
+	}
+
+	byKind := weightResources(doc, baseURL)
+	base := documentBase(doc, page)
+	doc.Find("iframe[src]").Each(func(i int, s *goquery.Selection) {
+		if resolved, err := resolveURL(base, s.AttrOr("src", "")); err == nil && resolved != "" {
+			byKind["iframes"] = append(byKind["iframes"], resolved)
+		}
+	})
+	for _, link := range links {
+		if link.Type == models.LinkTypeExternal {
+			byKind["links"] = append(byKind["links"], link.URL)
+		}
+	}
+
+	report := &models.ThirdPartyReport{}
+	domains := make(map[string]*models.ThirdParty)
+	hosts := make(map[string]map[string]bool)
+	seen := make(map[[2]string]bool)
+	for kind, urls := range byKind {
+		for _, raw := range urls {
+			u, err := url.Parse(raw)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || scope.internal(u, page) {
+				continue
+			}
+			if seen[[2]string{kind, raw}] {
+				continue
+			}
+			seen[[2]string{kind, raw}] = true
+
+			host := strings.ToLower(u.Hostname())
+			domain, ok := registrableDomain(host)
+			if !ok {
+				domain = host
+			}
+			party, ok := domains[domain]
+			if !ok {
+				party = &models.ThirdParty{Domain: domain}
+				domains[domain] = party
+				hosts[domain] = make(map[string]bool)
+			}
+			if !hosts[domain][host] {
+				hosts[domain][host] = true
+				party.Hosts = append(party.Hosts, host)
+			}
+			party.Total++
+			report.Resources++
+			switch kind {
+			case "links":
+				party.Links++
+			case "scripts":
+				party.Scripts++
+			case "stylesheets":
+				party.Stylesheets++
+			case "images":
+				party.Images++
+			case "fonts":
+				party.Fonts++
+			case "iframes":
+				party.Iframes++
+			}
+		}
+	}
+
+	report.Domains = make([]models.ThirdParty, 0, len(domains))
+	for _, party := range domains {
+		slices.Sort(party.Hosts)
+		report.Domains = append(report.Domains, *party)
+	}
+	slices.SortFunc(report.Domains, func(a, b models.ThirdParty) int {
+		return cmp.Or(cmp.Compare(b.Total, a.Total), strings.Compare(a.Domain, b.Domain))
+	})
+	return report
+}
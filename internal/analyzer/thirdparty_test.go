@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"website-analyzer/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestInventoryThirdParties(t *testing.T) {
+	html := `<html><head>
+		<script src="https://www.googletagmanager.com/gtag/js?id=G-XYZ"></script>
+		<script src="https://cdn.example.net/app.js"></script>
+		<link rel="stylesheet" href="https://fonts.googleapis.com/css2?family=Inter">
+		<link rel="stylesheet" href="/site.css">
+		<script src="/local.js"></script>
+	</head><body>
+		<img src="https://img.example.net/a.png">
+		<img src="https://img.example.net/a.png">
+		<img src="data:image/png;base64,AAAA">
+		<iframe src="https://www.youtube.com/embed/xyz"></iframe>
+		<a href="https://twitter.com/example">Twitter</a>
+		<a href="https://cdn.example.net/app.js">Source</a>
+		<a href="/about">About</a>
+	</body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	links, err := ExtractLinks(doc, "https://www.example.com/", LinkScope{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := InventoryThirdParties(doc, "https://www.example.com/", links, LinkScope{})
+	if report.Resources != 7 {
+		t.Errorf("Expected 7 third-party resources, got %d", report.Resources)
+	}
+	var domains []string
+	for _, party := range report.Domains {
+		domains = append(domains, party.Domain)
+	}
+	want := []string{"example.net", "fonts.googleapis.com", "googletagmanager.com", "twitter.com", "youtube.com"}
+	if !slices.Equal(domains, want) {
+		t.Fatalf("Expected domains %v, got %v", want, domains)
+	}
+
+	// The same script linked to counts once as each kind
+	got := report.Domains[0]
+	expected := models.ThirdParty{Domain: "example.net", Hosts: []string{"cdn.example.net", "img.example.net"}, Total: 3, Links: 1, Scripts: 1, Images: 1}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+	if yt := report.Domains[4]; yt.Iframes != 1 || yt.Hosts[0] != "www.youtube.com" {
+		t.Errorf("Expected the embed counted for youtube.com, got %+v", yt)
+	}
+
+	// Under the registrable domain scope, the page's other hosts aren't third parties
+	scoped := InventoryThirdParties(doc, "https://www.example.net/", nil, LinkScope{policy: ScopeRegistrableDomain})
+	for _, party := range scoped.Domains {
+		if party.Domain == "example.net" {
+			t.Errorf("Expected example.net to be first-party, got %+v", party)
+		}
+	}
+}
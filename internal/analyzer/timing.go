@@ -0,0 +1,102 @@
+package analyzer
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+// fetchTrace records the phases of a page fetch through httptrace. Phases
+// repeated for redirects add up; time to first byte counts from the first
+// request, so redirects are part of it.
+type fetchTrace struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	tlsStart     time.Time
+	connectStart map[string]time.Time
+	firstByte    time.Time
+	dns          time.Duration
+	connect      time.Duration
+	tls          time.Duration
+	reused       bool
+}
+
+// withFetchTrace returns ctx instrumented to record into a new trace
+func withFetchTrace(ctx context.Context) (context.Context, *fetchTrace) {
+	t := &fetchTrace{start: time.Now(), connectStart: make(map[string]time.Time)}
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if !t.dnsStart.IsZero() {
+				t.dns += time.Since(t.dnsStart)
+			}
+		},
+		// Dual-stack dials race several addresses; only the one that
+		// connected counts
+		ConnectStart: func(network, addr string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.connectStart[network+addr] = time.Now()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if started, ok := t.connectStart[network+addr]; ok && err == nil {
+				t.connect += time.Since(started)
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if !t.tlsStart.IsZero() {
+				t.tls += time.Since(t.tlsStart)
+			}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.reused = info.Reused
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.firstByte = time.Now()
+		},
+	}
+	return httptrace.WithClientTrace(ctx, trace), t
+}
+
+// timing summarizes the trace once the body has been read
+func (t *fetchTrace) timing() *models.ResponseTiming {
+	end := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	timing := &models.ResponseTiming{
+		DNSMs:     t.dns.Milliseconds(),
+		ConnectMs: t.connect.Milliseconds(),
+		TLSMs:     t.tls.Milliseconds(),
+		TotalMs:   end.Sub(t.start).Milliseconds(),
+		Reused:    t.reused,
+	}
+	if !t.firstByte.IsZero() {
+		timing.TTFBMs = t.firstByte.Sub(t.start).Milliseconds()
+		timing.DownloadMs = end.Sub(t.firstByte).Milliseconds()
+	}
+	return timing
+}
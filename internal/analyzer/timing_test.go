@@ -0,0 +1,47 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPFetcherTiming(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("<html></html>"))
+	}))
+	defer server.Close()
+
+	f := httpFetcher{client: server.Client(), maxSize: 1024}
+	page, err := f.Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	timing := page.Timing
+	if timing == nil {
+		t.Fatal("Expected timing for a network fetch")
+	}
+	if timing.Reused {
+		t.Error("Expected a new connection for the first fetch")
+	}
+	if timing.TTFBMs < 20 || timing.DownloadMs < 20 {
+		t.Errorf("Expected first byte and download of at least 20 ms, got %+v", timing)
+	}
+	if timing.TotalMs < timing.TTFBMs+timing.DownloadMs-1 {
+		t.Errorf("Total shorter than its phases: %+v", timing)
+	}
+
+	page, err = f.Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !page.Timing.Reused || page.Timing.TLSMs != 0 || page.Timing.ConnectMs != 0 {
+		t.Errorf("Expected the second fetch to reuse the connection, got %+v", page.Timing)
+	}
+}
@@ -0,0 +1,105 @@
+package analyzer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+// usageMeter accumulates the resources used by one analysis run. It travels
+// in the context so every client and worker pool of the run reports to it.
+type usageMeter struct {
+	start    time.Time
+	requests atomic.Int64
+	bytes    atomic.Int64
+	active   atomic.Int64
+	peak     atomic.Int64
+}
+
+type usageKey struct{}
+
+// withUsage starts metering a run; the calling goroutine counts as the first
+func withUsage(ctx context.Context) (context.Context, *usageMeter) {
+	m := &usageMeter{start: time.Now()}
+	m.enter()
+	return context.WithValue(ctx, usageKey{}, m), m
+}
+
+// meterFrom returns the run's meter, or nil outside a metered run
+func meterFrom(ctx context.Context) *usageMeter {
+	m, _ := ctx.Value(usageKey{}).(*usageMeter)
+	return m
+}
+
+// enter records a goroutine starting work for the run
+func (m *usageMeter) enter() {
+	if m == nil {
+		return
+	}
+	active := m.active.Add(1)
+	for {
+		peak := m.peak.Load()
+		if active <= peak || m.peak.CompareAndSwap(peak, active) {
+			return
+		}
+	}
+}
+
+// leave records a goroutine finishing work for the run
+func (m *usageMeter) leave() {
+	if m != nil {
+		m.active.Add(-1)
+	}
+}
+
+// usage reports the run so far, which analyzed the given number of pages
+func (m *usageMeter) usage(pages int) *models.ResourceUsage {
+	return &models.ResourceUsage{
+		WallTimeMs:      time.Since(m.start).Milliseconds(),
+		Pages:           int64(pages),
+		Requests:        m.requests.Load(),
+		BytesDownloaded: m.bytes.Load(),
+		PeakGoroutines:  m.peak.Load(),
+	}
+}
+
+// meteredTransport counts requests and downloaded body bytes against the
+// meter in the request context
+type meteredTransport struct {
+	base http.RoundTripper
+}
+
+func (t meteredTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	m := meterFrom(req.Context())
+	if m == nil {
+		return base.RoundTrip(req)
+	}
+
+	m.requests.Add(1)
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &meteredBody{ReadCloser: resp.Body, meter: m}
+	return resp, nil
+}
+
+type meteredBody struct {
+	io.ReadCloser
+	meter *usageMeter
+}
+
+func (b *meteredBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.meter.bytes.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"runtime/debug"
+	"slices"
+
+	"website-analyzer/internal/models"
+)
+
+// Version identifies the analyzer release. Builds set it with
+// -ldflags "-X website-analyzer/internal/analyzer.Version=v1.2.3"; other
+// builds report "dev" and rely on the VCS revision.
+var Version = "dev"
+
+// buildInfo is the version, VCS revision and Go version of this binary
+var buildInfo = readBuildInfo()
+
+func readBuildInfo() models.AnalyzerInfo {
+	info := models.AnalyzerInfo{Version: Version}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.GoVersion = build.GoVersion
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Revision = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
+
+// Info describes the analyzer build and the features the server enables
+// by default
+func (a *Analyzer) Info() models.AnalyzerInfo {
+	return a.info(Profile{DeepAnalysis: a.config.DeepAnalysis, SitemapAnalysis: a.config.SitemapAnalysis}, a.config.Flags)
+}
+
+// info describes the analyzer build and the features and feature flags
+// enabled for analyses with profile p, so stored results can be read
+// against the rules that produced them
+func (a *Analyzer) info(p Profile, flags Flags) models.AnalyzerInfo {
+	info := buildInfo
+	for feature, enabled := range map[string]bool{
+		"deep_analysis":       p.DeepAnalysis,
+		"sitemap_analysis":    p.SitemapAnalysis,
+		"respect_robots":      a.config.RespectRobots,
+		"link_check_get_only": a.config.LinkCheckGetOnly,
+	} {
+		if enabled {
+			info.Features = append(info.Features, feature)
+		}
+	}
+	info.Features = append(info.Features, flags.names()...)
+	slices.Sort(info.Features)
+	return info
+}
@@ -0,0 +1,23 @@
+package analyzer
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAnalyzerInfo(t *testing.T) {
+	a := NewAnalyzer(&Config{RespectRobots: true, RenderMode: RenderBrowser, DeepAnalysis: true})
+
+	info := a.Info()
+	if info.Version != Version {
+		t.Errorf("Expected version %q, got %q", Version, info.Version)
+	}
+	if want := []string{"deep_analysis", "deep_resources", "rendered", "respect_robots"}; !slices.Equal(info.Features, want) {
+		t.Errorf("Expected the server features %v, got %v", want, info.Features)
+	}
+
+	// Results record the features of their profile and flags
+	if features := a.info(Profile{SitemapAnalysis: true}, Flags{FlagSimHash: true}).Features; !slices.Equal(features, []string{"respect_robots", "simhash", "sitemap_analysis"}) {
+		t.Errorf("Expected the profile's features, got %v", features)
+	}
+}
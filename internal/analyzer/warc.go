@@ -0,0 +1,248 @@
+package analyzer
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+// WARCFetcher replays pages archived in a WARC file, such as one written
+// by Heritrix, wget --warc-file or a WARC export, without contacting their
+// hosts. Response records are used, the first of each URL; recorded
+// redirects are followed. Bodies compressed with anything but gzip can't
+// be read and their records are skipped.
+type WARCFetcher struct {
+	responses recording
+}
+
+// LoadWARC reads the WARC file at path, which may be gzipped
+func LoadWARC(path string) (*WARCFetcher, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseWARC(f)
+}
+
+// ParseWARC reads WARC records, compressed as a whole or per record with
+// gzip, or uncompressed
+func ParseWARC(r io.Reader) (*WARCFetcher, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("invalid WARC: %w", err)
+		}
+		defer zr.Close()
+		br = bufio.NewReader(zr)
+	}
+
+	responses := make(recording)
+	tp := textproto.NewReader(br)
+	for {
+		line, err := tp.ReadLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid WARC: %w", err)
+		}
+		if line == "" {
+			// Records are separated by blank lines
+			continue
+		}
+		if !strings.HasPrefix(line, "WARC/") {
+			return nil, fmt.Errorf("invalid WARC: expected a record, got %q", line[:min(len(line), 40)])
+		}
+		header, err := tp.ReadMIMEHeader()
+		if err != nil {
+			return nil, fmt.Errorf("invalid WARC: %w", err)
+		}
+		length, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+		if err != nil || length < 0 {
+			return nil, fmt.Errorf("invalid WARC: record without a valid Content-Length")
+		}
+		block := make([]byte, length)
+		if _, err := io.ReadFull(br, block); err != nil {
+			return nil, fmt.Errorf("invalid WARC: truncated record: %w", err)
+		}
+
+		if header.Get("WARC-Type") != "response" || !strings.HasPrefix(header.Get("Content-Type"), "application/http") {
+			continue
+		}
+		// WARC 1.0 wrapped the URI in angle brackets
+		target := strings.Trim(header.Get("WARC-Target-URI"), "<>")
+		key := normalizeURL(target)
+		if _, ok := responses[key]; ok {
+			continue
+		}
+		resp, ok, err := readWARCResponse(block)
+		if err != nil {
+			return nil, fmt.Errorf("invalid WARC: response for %s: %w", target, err)
+		}
+		if ok {
+			responses[key] = resp
+		}
+	}
+	return &WARCFetcher{responses: responses}, nil
+}
+
+// readWARCResponse parses the HTTP response a record holds; ok is false
+// when its body is compressed in a way that can't be read
+func readWARCResponse(block []byte) (resp recordedResponse, ok bool, err error) {
+	r, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(block)), &http.Request{Method: http.MethodGet})
+	if err != nil {
+		return recordedResponse{}, false, err
+	}
+	defer r.Body.Close()
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return recordedResponse{}, false, err
+	}
+
+	switch contentEncoding(r.Header) {
+	case "":
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return recordedResponse{}, false, err
+		}
+		if body, err = io.ReadAll(zr); err != nil {
+			return recordedResponse{}, false, err
+		}
+	default:
+		return recordedResponse{}, false, nil
+	}
+
+	return recordedResponse{
+		status:   r.StatusCode,
+		proto:    r.Proto,
+		header:   r.Header,
+		body:     body,
+		redirect: r.Header.Get("Location"),
+	}, true, nil
+}
+
+// Offline reports that archived pages never contact their hosts
+func (f *WARCFetcher) Offline() bool {
+	return true
+}
+
+func (f *WARCFetcher) Fetch(ctx context.Context, pageURL string) (*FetchedPage, error) {
+	return f.responses.fetch(ctx, pageURL)
+}
+
+// WriteWARC writes the snapshot of an analysis as a WARC 1.1 file: a
+// warcinfo record, the page as a response record and, when result lists
+// the page's resources, a metadata record naming each as an outlink with
+// its deep mode check outcome. Resources are only checked, never
+// downloaded, so their content isn't archived.
+func WriteWARC(w io.Writer, snapshot *models.Snapshot, result *models.AnalysisResult) error {
+	software := "website-analyzer"
+	if info := result.Analyzer; info != nil && info.Version != "" {
+		software += "/" + info.Version
+	}
+	date := snapshot.CapturedAt.UTC().Format(time.RFC3339)
+
+	info := fmt.Sprintf("software: %s\r\nformat: WARC File Format 1.1\r\n", software)
+	if _, err := writeWARCRecord(w, "warcinfo", "", date, "", "application/warc-fields", []byte(info)); err != nil {
+		return err
+	}
+
+	// The body is stored decoded, so the headers must not claim otherwise
+	header := http.Header(snapshot.Header).Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Del("Content-Encoding")
+	header.Del("Transfer-Encoding")
+	header.Set("Content-Length", strconv.Itoa(len(snapshot.Body)))
+	var response bytes.Buffer
+	response.WriteString("HTTP/1.1 200 OK\r\n")
+	if err := header.Write(&response); err != nil {
+		return err
+	}
+	response.WriteString("\r\n")
+	response.WriteString(snapshot.Body)
+	pageID, err := writeWARCRecord(w, "response", snapshot.URL, date, "", "application/http;msgtype=response", response.Bytes())
+	if err != nil {
+		return err
+	}
+
+	if result.Resources == nil || len(result.Resources.Resources) == 0 {
+		return nil
+	}
+	var metadata bytes.Buffer
+	for _, resource := range result.Resources.Resources {
+		fmt.Fprintf(&metadata, "outlink: %s %s\r\n", resource.URL, resource.Kind)
+		if !resource.Checked {
+			continue
+		}
+		outcome := strconv.Itoa(resource.StatusCode)
+		if resource.Error != "" {
+			outcome = strings.Join(strings.Fields(resource.Error), " ")
+		}
+		fmt.Fprintf(&metadata, "outlink-status: %s %s\r\n", resource.URL, outcome)
+	}
+	_, err = writeWARCRecord(w, "metadata", snapshot.URL, date, pageID, "application/warc-fields", metadata.Bytes())
+	return err
+}
+
+// writeWARCRecord writes one record, returning its ID
+func writeWARCRecord(w io.Writer, recordType, targetURI, date, concurrentTo, contentType string, block []byte) (string, error) {
+	id, err := warcRecordID()
+	if err != nil {
+		return "", err
+	}
+	digest := sha1.Sum(block)
+
+	var head strings.Builder
+	head.WriteString("WARC/1.1\r\n")
+	fmt.Fprintf(&head, "WARC-Type: %s\r\n", recordType)
+	fmt.Fprintf(&head, "WARC-Record-ID: %s\r\n", id)
+	fmt.Fprintf(&head, "WARC-Date: %s\r\n", date)
+	if targetURI != "" {
+		fmt.Fprintf(&head, "WARC-Target-URI: %s\r\n", targetURI)
+	}
+	if concurrentTo != "" {
+		fmt.Fprintf(&head, "WARC-Concurrent-To: %s\r\n", concurrentTo)
+	}
+	fmt.Fprintf(&head, "WARC-Block-Digest: sha1:%s\r\n", base32.StdEncoding.EncodeToString(digest[:]))
+	fmt.Fprintf(&head, "Content-Type: %s\r\n", contentType)
+	fmt.Fprintf(&head, "Content-Length: %d\r\n\r\n", len(block))
+
+	if _, err := io.WriteString(w, head.String()); err != nil {
+		return "", err
+	}
+	if _, err := w.Write(block); err != nil {
+		return "", err
+	}
+	_, err = io.WriteString(w, "\r\n\r\n")
+	return id, err
+}
+
+// warcRecordID returns a random URN for a record
+func warcRecordID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
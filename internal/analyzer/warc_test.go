@@ -0,0 +1,118 @@
+package analyzer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+// warcRecord formats a WARC record holding block
+func warcRecord(version, recordType, targetURI, contentType, block string) string {
+	return fmt.Sprintf("%s\r\nWARC-Type: %s\r\nWARC-Target-URI: %s\r\nContent-Type: %s\r\nContent-Length: %d\r\n\r\n%s\r\n\r\n",
+		version, recordType, targetURI, contentType, len(block), block)
+}
+
+func TestWARCFetcher(t *testing.T) {
+	var zipped bytes.Buffer
+	zw := gzip.NewWriter(&zipped)
+	zw.Write([]byte("<html><title>Home</title></html>"))
+	zw.Close()
+	chunked := "HTTP/1.1 200 OK\r\nContent-Type: text/html\r\nContent-Encoding: gzip\r\nTransfer-Encoding: chunked\r\n\r\n" +
+		fmt.Sprintf("%x\r\n%s\r\n0\r\n\r\n", zipped.Len(), zipped.String())
+
+	// A redirect, a request record, a gzipped and chunked page and a
+	// brotli page that can't be read
+	archive := warcRecord("WARC/1.0", "response", "<http://example.com/>", "application/http; msgtype=response",
+		"HTTP/1.1 301 Moved Permanently\r\nLocation: /home\r\n\r\n") +
+		warcRecord("WARC/1.1", "request", "http://example.com/home", "application/http; msgtype=request",
+			"GET /home HTTP/1.1\r\nHost: example.com\r\n\r\n") +
+		warcRecord("WARC/1.1", "response", "http://example.com/home", "application/http; msgtype=response", chunked) +
+		warcRecord("WARC/1.1", "response", "http://example.com/br", "application/http; msgtype=response",
+			"HTTP/1.1 200 OK\r\nContent-Encoding: br\r\n\r\n\x0b\x02\x80ab\x03")
+
+	// The same archive, plain and gzipped
+	var gzipped bytes.Buffer
+	zw = gzip.NewWriter(&gzipped)
+	zw.Write([]byte(archive))
+	zw.Close()
+	for name, data := range map[string][]byte{"plain": []byte(archive), "gzipped": gzipped.Bytes()} {
+		warc, err := ParseWARC(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: ParseWARC failed: %v", name, err)
+		}
+		if !offline(warc) {
+			t.Errorf("%s: expected WARC replay to be offline", name)
+		}
+		page, err := warc.Fetch(context.Background(), "http://example.com")
+		if err != nil {
+			t.Fatalf("%s: Fetch failed: %v", name, err)
+		}
+		if string(page.Body) != "<html><title>Home</title></html>" || page.Proto != "HTTP/1.1" || page.Timing != nil {
+			t.Errorf("%s: unexpected page %q %+v", name, page.Body, page)
+		}
+		if page.Header.Get("Content-Type") != "text/html" || page.Header.Get("Content-Encoding") != "gzip" {
+			t.Errorf("%s: expected the archived headers, got %v", name, page.Header)
+		}
+		if _, err := warc.Fetch(context.Background(), "http://example.com/br"); err == nil {
+			t.Errorf("%s: expected the brotli page to be skipped", name)
+		}
+	}
+
+	if _, err := ParseWARC(strings.NewReader("not a warc")); err == nil {
+		t.Error("Expected invalid WARC to be rejected")
+	}
+}
+
+func TestWriteWARC(t *testing.T) {
+	snapshot := &models.Snapshot{
+		URL:        "https://example.com/page",
+		Body:       "<html><title>Kept</title></html>",
+		Header:     map[string][]string{"Content-Type": {"text/html"}, "Content-Encoding": {"gzip"}},
+		CapturedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	result := &models.AnalysisResult{
+		Analyzer: &models.AnalyzerInfo{Version: "v1.2.3"},
+		Resources: &models.ResourceReport{Resources: []models.Resource{
+			{URL: "https://example.com/site.css", Kind: "stylesheet", Checked: true, StatusCode: 200},
+			{URL: "https://example.com/app.js", Kind: "script"},
+		}},
+	}
+
+	var out bytes.Buffer
+	if err := WriteWARC(&out, snapshot, result); err != nil {
+		t.Fatal(err)
+	}
+	archive := out.String()
+	for _, want := range []string{
+		"WARC-Type: warcinfo", "software: website-analyzer/v1.2.3",
+		"WARC-Date: 2026-01-02T03:04:05Z", "WARC-Concurrent-To: <urn:uuid:",
+		"outlink: https://example.com/site.css stylesheet", "outlink-status: https://example.com/site.css 200",
+		"outlink: https://example.com/app.js script",
+	} {
+		if !strings.Contains(archive, want) {
+			t.Errorf("Expected %q in the archive:\n%s", want, archive)
+		}
+	}
+	if strings.Contains(archive, "outlink-status: https://example.com/app.js") {
+		t.Error("Expected no outcome for an unchecked resource")
+	}
+
+	// The export reads back as the page it archived
+	warc, err := ParseWARC(&out)
+	if err != nil {
+		t.Fatalf("ParseWARC failed: %v", err)
+	}
+	page, err := warc.Fetch(context.Background(), snapshot.URL)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if string(page.Body) != snapshot.Body || page.Header.Get("Content-Encoding") != "" || !page.TLS {
+		t.Errorf("Expected the snapshot back, got %q %v", page.Body, page.Header)
+	}
+}
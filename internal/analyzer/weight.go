@@ -0,0 +1,165 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"website-analyzer/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Budget caps a page's weight; zero fields are unlimited
+type Budget struct {
+	HTML        int64 // bytes of the HTML document
+	Total       int64 // bytes of the document and its measured resources
+	Scripts     int
+	Stylesheets int
+	Images      int
+	Fonts       int
+}
+
+// ParseBudget reads a budget such as
+// "html=102400,total=2097152,scripts=25,stylesheets=10,images=50,fonts=6";
+// omitted fields are unlimited
+func ParseBudget(spec string) (Budget, error) {
+	var b Budget
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(item, "=")
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil || n < 0 {
+			return Budget{}, fmt.Errorf("invalid budget %q", item)
+		}
+		switch strings.TrimSpace(name) {
+		case "html":
+			b.HTML = n
+		case "total":
+			b.Total = n
+		case "scripts":
+			b.Scripts = int(n)
+		case "stylesheets":
+			b.Stylesheets = int(n)
+		case "images":
+			b.Images = int(n)
+		case "fonts":
+			b.Fonts = int(n)
+		default:
+			return Budget{}, fmt.Errorf("unknown budget %q", name)
+		}
+	}
+	return b, nil
+}
+
+// fontFacePattern matches the first source of each inline @font-face rule
+var fontFacePattern = regexp.MustCompile(`(?is)@font-face\s*\{[^}]*?url\(\s*['"]?([^'")]+)`)
+
+// weightResources returns the distinct scripts, stylesheets, images and
+// fonts the page references, by kind. Fonts loaded by external stylesheets
+// can't be seen without fetching them and are not counted.
+func weightResources(doc *goquery.Document, baseURL string) map[string][]string {
+	page, err := url.Parse(baseURL)
+	if err != nil {
+		return nil
+	}
+	base := documentBase(doc, page)
+
+	byKind := make(map[string][]string)
+	seen := make(map[string]bool)
+	add := func(kind, href string) {
+		if strings.HasPrefix(strings.TrimSpace(href), "data:") {
+			return
+		}
+		resolved, err := resolveURL(base, href)
+		if err != nil || resolved == "" || seen[resolved] {
+			return
+		}
+		seen[resolved] = true
+		byKind[kind] = append(byKind[kind], resolved)
+	}
+
+	doc.Find("script[src]").Each(func(i int, s *goquery.Selection) {
+		add("scripts", s.AttrOr("src", ""))
+	})
+	doc.Find(`link[rel~="stylesheet" i][href]`).Each(func(i int, s *goquery.Selection) {
+		add("stylesheets", s.AttrOr("href", ""))
+	})
+	doc.Find("img").Each(func(i int, s *goquery.Selection) {
+		if src := imageSource(s); src != "" {
+			add("images", src)
+		}
+	})
+	doc.Find(`link[rel~="preload" i][as="font" i][href]`).Each(func(i int, s *goquery.Selection) {
+		add("fonts", s.AttrOr("href", ""))
+	})
+	doc.Find("style").Each(func(i int, s *goquery.Selection) {
+		for _, match := range fontFacePattern.FindAllStringSubmatch(s.Text(), -1) {
+			add("fonts", match[1])
+		}
+	})
+	return byKind
+}
+
+// MeasurePageWeight counts the resources the page references and, with a
+// client, adds up their sizes from HEAD requests. Pages over budget have
+// each exceeded limit listed.
+func MeasurePageWeight(ctx context.Context, doc *goquery.Document, baseURL string, htmlSize int64, budget Budget, client *http.Client, maxWorkers int) *models.PageWeightReport {
+	byKind := weightResources(doc, baseURL)
+	report := &models.PageWeightReport{
+		HTMLSize:    htmlSize,
+		Scripts:     len(byKind["scripts"]),
+		Stylesheets: len(byKind["stylesheets"]),
+		Images:      len(byKind["images"]),
+		Fonts:       len(byKind["fonts"]),
+		TotalSize:   htmlSize,
+	}
+
+	if client != nil {
+		var urls []string
+		for _, kind := range []string{"scripts", "stylesheets", "images", "fonts"} {
+			urls = append(urls, byKind[kind]...)
+		}
+		var size, unmeasured atomic.Int64
+		runLimited(ctx, len(urls), maxWorkers, func(i int) {
+			resp, err := headResource(ctx, client, urls[i])
+			if err != nil {
+				unmeasured.Add(1)
+				return
+			}
+			resp.Body.Close()
+			if resp.StatusCode >= 400 || resp.ContentLength < 0 {
+				unmeasured.Add(1)
+				return
+			}
+			size.Add(resp.ContentLength)
+		})
+		report.Measured = true
+		report.ResourceSize = size.Load()
+		report.Unmeasured = int(unmeasured.Load())
+		report.TotalSize += report.ResourceSize
+	}
+
+	over := func(metric string, value, limit int64) {
+		if limit > 0 && value > limit {
+			report.OverBudget = append(report.OverBudget, models.BudgetViolation{Metric: metric, Value: value, Budget: limit})
+		}
+	}
+	over("html", report.HTMLSize, budget.HTML)
+	if report.Measured {
+		over("total", report.TotalSize, budget.Total)
+	}
+	over("scripts", int64(report.Scripts), int64(budget.Scripts))
+	over("stylesheets", int64(report.Stylesheets), int64(budget.Stylesheets))
+	over("images", int64(report.Images), int64(budget.Images))
+	over("fonts", int64(report.Fonts), int64(budget.Fonts))
+	return report
+}
@@ -0,0 +1,91 @@
+package analyzer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestParseBudget(t *testing.T) {
+	b, err := ParseBudget("html=1000, total=5000,scripts=2,stylesheets=1,images=3,fonts=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Budget{HTML: 1000, Total: 5000, Scripts: 2, Stylesheets: 1, Images: 3, Fonts: 1}
+	if b != want {
+		t.Errorf("ParseBudget = %+v, want %+v", b, want)
+	}
+
+	if b, err := ParseBudget(""); err != nil || b != (Budget{}) {
+		t.Errorf("empty budget = %+v, %v", b, err)
+	}
+	for _, spec := range []string{"html=big", "scripts=-1", "videos=2"} {
+		if _, err := ParseBudget(spec); err == nil {
+			t.Errorf("ParseBudget(%q) should fail", spec)
+		}
+	}
+}
+
+func TestMeasurePageWeight(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("unexpected %s request", r.Method)
+		}
+		switch r.URL.Path {
+		case "/app.js":
+			w.Header().Set("Content-Length", "3000")
+		case "/site.css":
+			w.Header().Set("Content-Length", "1000")
+		case "/logo.png":
+			w.Header().Set("Content-Length", "500")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	html := `<html><head>
+		<link rel="stylesheet" href="/site.css">
+		<link rel="preload" href="/body.woff2" as="font">
+		<script src="/app.js"></script>
+		<script src="/app.js"></script>
+		<style>@font-face { font-family: X; src: url("/body.woff2") }</style>
+	</head><body><img src="/logo.png"><img src="data:image/png;base64,AAAA"></body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	budget := Budget{HTML: 100, Total: 4000, Scripts: 1, Fonts: 1}
+	report := MeasurePageWeight(context.Background(), doc, server.URL+"/", int64(len(html)), budget, nil, 2)
+	if report.Scripts != 1 || report.Stylesheets != 1 || report.Images != 1 || report.Fonts != 1 {
+		t.Errorf("counts = %d scripts, %d stylesheets, %d images, %d fonts", report.Scripts, report.Stylesheets, report.Images, report.Fonts)
+	}
+	if report.Measured || report.TotalSize != int64(len(html)) {
+		t.Errorf("without a client nothing should be measured: %+v", report)
+	}
+	if len(report.OverBudget) != 1 || report.OverBudget[0].Metric != "html" {
+		t.Errorf("OverBudget = %+v, want only html", report.OverBudget)
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	report = MeasurePageWeight(context.Background(), doc, server.URL+"/", 200, budget, client, 2)
+	if !report.Measured || report.ResourceSize != 4500 || report.Unmeasured != 1 {
+		t.Errorf("measured %d bytes with %d unmeasured, want 4500 and 1", report.ResourceSize, report.Unmeasured)
+	}
+	if report.TotalSize != 4700 {
+		t.Errorf("TotalSize = %d, want 4700", report.TotalSize)
+	}
+	var metrics []string
+	for _, v := range report.OverBudget {
+		metrics = append(metrics, v.Metric)
+	}
+	if got := strings.Join(metrics, ","); got != "html,total" {
+		t.Errorf("OverBudget metrics = %s, want html,total", got)
+	}
+}
@@ -0,0 +1,289 @@
+// Package bundle exports the service's configuration, such as monitors,
+// project webhooks and the denylist, as a YAML document that can be
+// imported on another instance
+package bundle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"website-analyzer/internal/analyzer"
+	"website-analyzer/internal/monitor"
+	"website-analyzer/internal/storage"
+	"website-analyzer/internal/webhook"
+)
+
+// Version is the bundle format written by Export and read by Parse
+const Version = 1
+
+// Bundle is the exported configuration. Monitors carry their schedule but
+// not their run history.
+type Bundle struct {
+	Version    int                `yaml:"version"`
+	ExportedAt time.Time          `yaml:"exported_at"`
+	Projects   []Project          `yaml:"projects,omitempty"`
+	Monitors   []Monitor          `yaml:"monitors,omitempty"`
+	Denylist   []DenyEntry        `yaml:"denylist,omitempty"`
+	Profiles   []analyzer.Profile `yaml:"profiles,omitempty"`
+}
+
+// Project is a project with its notification settings and portfolio
+// report
+type Project struct {
+	Name           string   `yaml:"name"`
+	Description    string   `yaml:"description,omitempty"`
+	NotifyWebhook  string   `yaml:"notify_webhook,omitempty"`
+	NotifyFormat   string   `yaml:"notify_format,omitempty"`
+	NotifyEmail    string   `yaml:"notify_email,omitempty"`
+	KeyURLs        []string `yaml:"key_urls,omitempty"`
+	ReportSchedule string   `yaml:"report_schedule,omitempty"`
+}
+
+// Monitor is a scheduled page
+type Monitor struct {
+	URL      string `yaml:"url"`
+	Schedule string `yaml:"schedule"`
+	Project  string `yaml:"project,omitempty"`
+	Profile  string `yaml:"profile,omitempty"`
+	Webhook  string `yaml:"webhook,omitempty"`
+}
+
+// DenyEntry is a do-not-analyze domain
+type DenyEntry struct {
+	Domain string `yaml:"domain"`
+	Reason string `yaml:"reason,omitempty"`
+}
+
+// Result counts what Import changed. Existing projects and denylist entries
+// are updated; monitors identical to an existing one are skipped.
+type Result struct {
+	Projects        int `json:"projects"`
+	Monitors        int `json:"monitors"`
+	MonitorsSkipped int `json:"monitors_skipped"`
+	Denylist        int `json:"denylist"`
+	// ProfilesNotApplied names bundled profiles that are missing here or
+	// differ; profiles are read from PROFILES_FILE at startup
+	ProfilesNotApplied []string `json:"profiles_not_applied,omitempty"`
+}
+
+// Export collects the configuration stored in store and the analyzer's
+// profiles
+func Export(store storage.Store, profiles []analyzer.Profile) (*Bundle, error) {
+	b := &Bundle{Version: Version, ExportedAt: time.Now().UTC().Truncate(time.Second), Profiles: profiles}
+
+	projects, err := store.Projects()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range projects {
+		b.Projects = append(b.Projects, Project{
+			Name:           p.Name,
+			Description:    p.Description,
+			NotifyWebhook:  p.NotifyWebhook,
+			NotifyFormat:   p.NotifyFormat,
+			NotifyEmail:    p.NotifyEmail,
+			KeyURLs:        p.KeyURLs,
+			ReportSchedule: p.ReportSchedule,
+		})
+	}
+
+	monitors, err := store.Monitors()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range monitors {
+		b.Monitors = append(b.Monitors, Monitor{URL: m.URL, Schedule: m.Schedule, Project: m.Project, Profile: m.Profile, Webhook: m.Webhook})
+	}
+
+	denylist, err := store.Denylist()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range denylist {
+		b.Denylist = append(b.Denylist, DenyEntry{Domain: entry.Domain, Reason: entry.Reason})
+	}
+
+	return b, nil
+}
+
+// Marshal writes b as YAML
+func (b *Bundle) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(b); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Parse reads a YAML bundle, rejecting unknown fields and other versions
+func Parse(data []byte) (*Bundle, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var b Bundle
+	if err := decoder.Decode(&b); err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	if b.Version != Version {
+		return nil, fmt.Errorf("unsupported bundle version %d, expected %d", b.Version, Version)
+	}
+	return &b, nil
+}
+
+// Validate checks b against this instance before anything is imported:
+// monitors may only use profiles configured here and projects that are
+// bundled or already stored
+func (b *Bundle) Validate(store storage.Store, profiles []analyzer.Profile) error {
+	projects := make(map[string]bool, len(b.Projects))
+	for _, p := range b.Projects {
+		if !storage.ValidProjectName(p.Name) {
+			return fmt.Errorf("invalid project name %q", p.Name)
+		}
+		if p.NotifyWebhook != "" && !httpURL(p.NotifyWebhook) {
+			return fmt.Errorf("project %s: notification webhook must be an http or https URL", p.Name)
+		}
+		if !webhook.ValidFormat(p.NotifyFormat) {
+			return fmt.Errorf("project %s: unknown webhook payload format %q", p.Name, p.NotifyFormat)
+		}
+		for _, key := range p.KeyURLs {
+			if !httpURL(key) {
+				return fmt.Errorf("project %s: key page %q must be an http or https URL", p.Name, key)
+			}
+		}
+		if p.ReportSchedule != "" {
+			if _, err := monitor.ParseSchedule(p.ReportSchedule); err != nil {
+				return fmt.Errorf("project %s: report %w", p.Name, err)
+			}
+		}
+		projects[p.Name] = true
+	}
+
+	for _, m := range b.Monitors {
+		if !httpURL(m.URL) {
+			return fmt.Errorf("monitored URL %q must be an http or https URL", m.URL)
+		}
+		if m.Webhook != "" && !httpURL(m.Webhook) {
+			return fmt.Errorf("monitor for %s: webhook must be an http or https URL", m.URL)
+		}
+		if _, err := monitor.ParseSchedule(m.Schedule); err != nil {
+			return fmt.Errorf("monitor for %s: %w", m.URL, err)
+		}
+		if m.Profile != "" && profileNamed(profiles, m.Profile) == nil {
+			return fmt.Errorf("monitor for %s uses profile %q, which is not configured on this instance", m.URL, m.Profile)
+		}
+		if m.Project != "" && !projects[m.Project] {
+			if _, err := store.Project(m.Project); errors.Is(err, storage.ErrNotFound) {
+				return fmt.Errorf("monitor for %s uses project %q, which is neither bundled nor stored here", m.URL, m.Project)
+			} else if err != nil {
+				return err
+			}
+			projects[m.Project] = true
+		}
+	}
+
+	for _, entry := range b.Denylist {
+		if strings.TrimSpace(entry.Domain) == "" || strings.ContainsAny(entry.Domain, "/: ") {
+			return fmt.Errorf("denylist domain %q must be a host name such as example.com", entry.Domain)
+		}
+	}
+	return nil
+}
+
+// Import validates b and applies it to store. Projects come first since
+// monitors refer to them.
+func Import(store storage.Store, b *Bundle, profiles []analyzer.Profile) (Result, error) {
+	var result Result
+	if err := b.Validate(store, profiles); err != nil {
+		return result, err
+	}
+
+	now := time.Now()
+	for _, p := range b.Projects {
+		project := &storage.Project{
+			Name:           p.Name,
+			Description:    p.Description,
+			NotifyWebhook:  p.NotifyWebhook,
+			NotifyFormat:   p.NotifyFormat,
+			NotifyEmail:    p.NotifyEmail,
+			KeyURLs:        p.KeyURLs,
+			ReportSchedule: p.ReportSchedule,
+		}
+		if p.ReportSchedule != "" {
+			schedule, _ := monitor.ParseSchedule(p.ReportSchedule)
+			project.ReportNext = schedule.Next(now)
+		}
+		if err := store.SaveProject(project); err != nil {
+			return result, err
+		}
+		result.Projects++
+	}
+
+	for _, entry := range b.Denylist {
+		if err := store.Deny(&storage.DenyEntry{Domain: entry.Domain, Reason: entry.Reason}); err != nil {
+			return result, err
+		}
+		result.Denylist++
+	}
+
+	existing, err := store.Monitors()
+	if err != nil {
+		return result, err
+	}
+	known := make(map[Monitor]bool, len(existing))
+	for _, m := range existing {
+		known[Monitor{URL: m.URL, Schedule: m.Schedule, Project: m.Project, Profile: m.Profile, Webhook: m.Webhook}] = true
+	}
+	for _, m := range b.Monitors {
+		if known[m] {
+			result.MonitorsSkipped++
+			continue
+		}
+		schedule, _ := monitor.ParseSchedule(m.Schedule)
+		created := &storage.Monitor{
+			URL:      m.URL,
+			Schedule: m.Schedule,
+			Project:  m.Project,
+			Profile:  m.Profile,
+			Webhook:  m.Webhook,
+			NextRun:  schedule.Next(now),
+		}
+		if err := store.CreateMonitor(created); err != nil {
+			return result, err
+		}
+		known[m] = true
+		result.Monitors++
+	}
+
+	for _, p := range b.Profiles {
+		if current := profileNamed(profiles, p.Name); current == nil || !reflect.DeepEqual(*current, p) {
+			result.ProfilesNotApplied = append(result.ProfilesNotApplied, p.Name)
+		}
+	}
+	return result, nil
+}
+
+func profileNamed(profiles []analyzer.Profile, name string) *analyzer.Profile {
+	for i := range profiles {
+		if profiles[i].Name == name {
+			return &profiles[i]
+		}
+	}
+	return nil
+}
+
+func httpURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,155 @@
+package bundle
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"website-analyzer/internal/analyzer"
+	"website-analyzer/internal/storage"
+)
+
+func openStore(t *testing.T) *storage.SQLiteStore {
+	t.Helper()
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	profiles := []analyzer.Profile{
+		{Name: "standard"},
+		{Name: "quick", Checks: []analyzer.Check{analyzer.LinksCheck}, MaxLinks: 25, LinkTimeout: analyzer.Duration(3 * time.Second)},
+	}
+
+	source := openStore(t)
+	if err := source.SaveProject(&storage.Project{
+		Name: "docs", NotifyWebhook: "https://hooks.example.com/docs", NotifyFormat: "flat",
+		KeyURLs: []string{"https://example.com", "https://example.com/docs"}, ReportSchedule: "@weekly",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := source.CreateMonitor(&storage.Monitor{URL: "https://example.com", Schedule: "@daily", Project: "docs", Profile: "quick", Webhook: "https://hooks.example.com/incident"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := source.Deny(&storage.DenyEntry{Domain: "blocked.example", Reason: "Legal request"}); err != nil {
+		t.Fatal(err)
+	}
+
+	exported, err := Export(source, profiles)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	data, err := exported.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	for _, snippet := range []string{"version: 1", "notify_format: flat", "schedule: '@daily'", "link_timeout: 3s", "domain: blocked.example"} {
+		if !strings.Contains(string(data), snippet) {
+			t.Errorf("Expected %q in the bundle:\n%s", snippet, data)
+		}
+	}
+
+	b, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	// The target instance configures quick differently
+	targetProfiles := []analyzer.Profile{profiles[0], {Name: "quick", MaxLinks: 10}}
+	target := openStore(t)
+	result, err := Import(target, b, targetProfiles)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if result.Projects != 1 || result.Monitors != 1 || result.Denylist != 1 {
+		t.Errorf("Unexpected import result %+v", result)
+	}
+	if len(result.ProfilesNotApplied) != 1 || result.ProfilesNotApplied[0] != "quick" {
+		t.Errorf("Expected the differing profile to be reported, got %v", result.ProfilesNotApplied)
+	}
+
+	project, err := target.Project("docs")
+	if err != nil || project.NotifyWebhook != "https://hooks.example.com/docs" || project.NotifyFormat != "flat" {
+		t.Errorf("Expected the project webhook to be imported, got %+v (%v)", project, err)
+	}
+	if len(project.KeyURLs) != 2 || project.ReportSchedule != "@weekly" || project.ReportNext.IsZero() {
+		t.Errorf("Expected the portfolio report to be imported and scheduled, got %+v", project)
+	}
+	monitors, err := target.Monitors()
+	if err != nil || len(monitors) != 1 || monitors[0].Schedule != "@daily" || monitors[0].Webhook == "" || monitors[0].NextRun.IsZero() {
+		t.Errorf("Expected the monitor to be scheduled, got %+v (%v)", monitors, err)
+	}
+	if entry, err := target.DeniedHost("blocked.example"); err != nil || entry.Reason != "Legal request" {
+		t.Errorf("Expected the denylist entry, got %+v (%v)", entry, err)
+	}
+
+	// Importing again keeps monitors unique
+	result, err = Import(target, b, profiles)
+	if err != nil || result.Monitors != 0 || result.MonitorsSkipped != 1 || len(result.ProfilesNotApplied) != 0 {
+		t.Errorf("Expected a repeated import to skip the monitor, got %+v (%v)", result, err)
+	}
+}
+
+func TestParseRejectsInvalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"syntax":        "version: [1",
+		"version":       "version: 2\n",
+		"unknown field": "version: 1\nschedules: []\n",
+		"duration":      "version: 1\nprofiles:\n  - name: quick\n    link_timeout: soon\n",
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Expected the %s error to be rejected", name)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	store := openStore(t)
+	if err := store.SaveProject(&storage.Project{Name: "stored"}); err != nil {
+		t.Fatal(err)
+	}
+	profiles := []analyzer.Profile{{Name: "standard"}}
+
+	valid := &Bundle{
+		Version:  Version,
+		Projects: []Project{{Name: "docs"}},
+		Monitors: []Monitor{
+			{URL: "https://example.com", Schedule: "@hourly", Project: "docs", Profile: "standard"},
+			{URL: "https://example.com/about", Schedule: "0 6 * * 1-5", Project: "stored"},
+		},
+	}
+	if err := valid.Validate(store, profiles); err != nil {
+		t.Errorf("Expected a valid bundle, got %v", err)
+	}
+
+	for name, b := range map[string]*Bundle{
+		"project name":   {Projects: []Project{{Name: "Not A Slug"}}},
+		"webhook":        {Projects: []Project{{Name: "docs", NotifyWebhook: "ftp://hooks.example.com"}}},
+		"webhook format": {Projects: []Project{{Name: "docs", NotifyFormat: "xml"}}},
+		"key page":       {Projects: []Project{{Name: "docs", KeyURLs: []string{"example.com"}}}},
+		"report":         {Projects: []Project{{Name: "docs", ReportSchedule: "weekly"}}},
+		"monitor URL":    {Monitors: []Monitor{{URL: "example.com", Schedule: "@daily"}}},
+		"monitor hook":   {Monitors: []Monitor{{URL: "https://example.com", Schedule: "@daily", Webhook: "hooks"}}},
+		"schedule":       {Monitors: []Monitor{{URL: "https://example.com", Schedule: "daily"}}},
+		"profile":        {Monitors: []Monitor{{URL: "https://example.com", Schedule: "@daily", Profile: "missing"}}},
+		"project":        {Monitors: []Monitor{{URL: "https://example.com", Schedule: "@daily", Project: "missing"}}},
+		"domain":         {Denylist: []DenyEntry{{Domain: "https://example.com/"}}},
+	} {
+		if err := b.Validate(store, profiles); err == nil {
+			t.Errorf("Expected an invalid %s to be rejected", name)
+		}
+	}
+
+	// Nothing is imported from an invalid bundle
+	invalid := &Bundle{Projects: []Project{{Name: "new"}}, Monitors: []Monitor{{URL: "https://example.com", Schedule: "never"}}}
+	if _, err := Import(store, invalid, profiles); err == nil {
+		t.Fatal("Expected Import to reject the bundle")
+	}
+	if _, err := store.Project("new"); err == nil {
+		t.Error("Expected no project to be imported from an invalid bundle")
+	}
+}
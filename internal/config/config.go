@@ -3,31 +3,162 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type Config struct {
-	Port            string
-	Env             string
-	RequestTimeout  time.Duration
-	LinkTimeout     time.Duration
-	MaxWorkers      int
-	MaxResponseSize int64
-	MaxURLLength    int
-	MaxRedirects    int
+	Port              string
+	Env               string
+	RequestTimeout    time.Duration
+	LinkTimeout       time.Duration
+	LinkMaxAttempts   int
+	LinkRetryBackoff  time.Duration
+	LinkRetryJitter   float64
+	LinkCheckGetOnly  bool
+	MaxWorkers        int
+	MaxResponseSize   int64
+	MaxURLLength      int
+	MaxRedirects      int
+	RedirectChainMax  int
+	DeepAnalysis      bool
+	LargeDocumentSize int64
+	SitemapAnalysis   bool
+	CrawlMaxDepth     int
+	CrawlMaxPages     int
+	HistoryDBPath     string
+	ProfilesFile      string
+	DefaultProfile    string
+	GateTolerance     int
+	RespectRobots     bool
+	RedactParams      []string
+	LinkHostRate      float64
+	LinkHostInFlight  int
+	QuotaDaily        string
+	QuotaMonthly      string
+	CircuitBreakerTTL time.Duration
+	AdminToken        string
+	ListenSocket      string
+	AdminAddr         string
+	StaticMaxAge      time.Duration
+	RenderMode        string
+	BrowserPath       string
+	BatchMaxURLs      int
+	BotInfoURL        string
+	BotContact        string
+	OptOutDomains     []string
+	CacheTTL          time.Duration
+	RedisURL          string
+	SheetsID          string
+	SheetsName        string
+	SheetsCredentials string
+	MonitorInterval   time.Duration
+	WebhookURL        string
+	WebhookFormat     string
+	WebhookSecret     string
+	JobWorkers        int
+	BlockedHosts      []string
+	BlockedCIDRs      []string
+	AllowedHosts      []string
+	DeniedHosts       []string
+	LinkScope         string
+	RespectNoArchive  bool
+	JobTimeout        time.Duration
+	FeatureFlags      string
+	WeightBudget      string
+	Fetcher           string
+	SlowestLinks      int
+	KeepSnapshots     bool
+	WARCFile          string
+	AgentToken        string
+	AgentRegion       string
+	AgentServer       string
+	AgentURL          string
+	TechRulesFile     string
+	CrawlMemoryURLs   int
+	CrawlSpillDir     string
+	TrackerRulesFile  string
+	CrawlBloomFPRate  float64
+	HARFile           string
+	RequestFlags      []string
 }
 
 func LoadConfig() *Config {
 	// Default values are defined in docs/specs/REQUIREMENTS.md
 	return &Config{
-		Port:            getEnv("PORT", "8080"),
-		Env:             getEnv("ENV", "production"),
-		RequestTimeout:  getEnvDuration("REQUEST_TIMEOUT", 30*time.Second),
-		LinkTimeout:     getEnvDuration("LINK_CHECK_TIMEOUT", 5*time.Second),
-		MaxWorkers:      getEnvInt("MAX_WORKERS", 10),
-		MaxResponseSize: getEnvInt64("MAX_RESPONSE_SIZE", 10*1024*1024), // 10MB
-		MaxURLLength:    getEnvInt("MAX_URL_LENGTH", 2048),
-		MaxRedirects:    getEnvInt("MAX_REDIRECTS", 10),
+		Port:              getEnv("PORT", "8080"),
+		Env:               getEnv("ENV", "production"),
+		RequestTimeout:    getEnvDuration("REQUEST_TIMEOUT", 30*time.Second),
+		LinkTimeout:       getEnvDuration("LINK_CHECK_TIMEOUT", 5*time.Second),
+		LinkMaxAttempts:   getEnvInt("LINK_CHECK_ATTEMPTS", 2),
+		LinkRetryBackoff:  getEnvDuration("LINK_CHECK_BACKOFF", 250*time.Millisecond),
+		LinkRetryJitter:   getEnvFloat("LINK_CHECK_JITTER", 0.2),
+		LinkCheckGetOnly:  getEnvBool("LINK_CHECK_GET_ONLY", false),
+		MaxWorkers:        getEnvInt("MAX_WORKERS", 10),
+		MaxResponseSize:   getEnvInt64("MAX_RESPONSE_SIZE", 10*1024*1024), // 10MB
+		MaxURLLength:      getEnvInt("MAX_URL_LENGTH", 2048),
+		MaxRedirects:      getEnvInt("MAX_REDIRECTS", 10),
+		RedirectChainMax:  getEnvInt("REDIRECT_CHAIN_MAX", 3),
+		DeepAnalysis:      getEnvBool("DEEP_ANALYSIS", false),
+		LargeDocumentSize: getEnvInt64("LARGE_DOCUMENT_SIZE", 5*1024*1024), // 5MB
+		SitemapAnalysis:   getEnvBool("SITEMAP_ANALYSIS", false),
+		CrawlMaxDepth:     getEnvInt("CRAWL_MAX_DEPTH", 2),
+		CrawlMaxPages:     getEnvInt("CRAWL_MAX_PAGES", 50),
+		HistoryDBPath:     getEnv("HISTORY_DB_PATH", "data/history.db"),
+		ProfilesFile:      getEnv("PROFILES_FILE", ""),
+		DefaultProfile:    getEnv("DEFAULT_PROFILE", "standard"),
+		GateTolerance:     getEnvInt("GATE_SCORE_TOLERANCE", 5),
+		RespectRobots:     getEnvBool("RESPECT_ROBOTS", false),
+		LinkHostRate:      getEnvFloat("LINK_CHECK_HOST_RATE", 0),
+		LinkHostInFlight:  getEnvInt("LINK_CHECK_HOST_CONCURRENCY", 4),
+		QuotaDaily:        getEnv("API_QUOTA_DAILY", ""),
+		QuotaMonthly:      getEnv("API_QUOTA_MONTHLY", ""),
+		CircuitBreakerTTL: getEnvDuration("CIRCUIT_BREAKER_TTL", 10*time.Minute),
+		AdminToken:        getEnv("ADMIN_TOKEN", ""),
+		ListenSocket:      getEnv("LISTEN_SOCKET", ""),
+		AdminAddr:         getEnv("ADMIN_ADDR", ""),
+		StaticMaxAge:      getEnvDuration("STATIC_CACHE_MAX_AGE", 365*24*time.Hour),
+		RenderMode:        getEnv("RENDER_MODE", "http"),
+		BrowserPath:       getEnv("CHROME_PATH", ""),
+		BatchMaxURLs:      getEnvInt("BATCH_MAX_URLS", 200),
+		BotInfoURL:        getEnv("BOT_INFO_URL", ""),
+		BotContact:        getEnv("BOT_CONTACT", ""),
+		OptOutDomains:     getEnvList("OPT_OUT_DOMAINS", nil),
+		CacheTTL:          getEnvDuration("CACHE_TTL", 0),
+		RedisURL:          getEnv("REDIS_URL", ""),
+		SheetsID:          getEnv("GOOGLE_SHEETS_ID", ""),
+		SheetsName:        getEnv("GOOGLE_SHEETS_SHEET", "Sheet1"),
+		SheetsCredentials: getEnv("GOOGLE_APPLICATION_CREDENTIALS", ""),
+		MonitorInterval:   getEnvDuration("MONITOR_INTERVAL", time.Minute),
+		WebhookURL:        getEnv("WEBHOOK_URL", ""),
+		WebhookFormat:     getEnv("WEBHOOK_FORMAT", "summary"),
+		WebhookSecret:     getEnv("WEBHOOK_SECRET", ""),
+		JobWorkers:        getEnvInt("JOB_WORKERS", 2),
+		BlockedHosts:      getEnvList("BLOCKED_HOSTS", nil),
+		BlockedCIDRs:      getEnvList("BLOCKED_CIDRS", nil),
+		AllowedHosts:      getEnvList("ALLOWED_HOSTS", nil),
+		DeniedHosts:       getEnvList("DENIED_HOSTS", nil),
+		LinkScope:         getEnv("LINK_SCOPE", "exact-host"),
+		RespectNoArchive:  getEnvBool("RESPECT_NOARCHIVE", false),
+		JobTimeout:        getEnvDuration("JOB_TIMEOUT", 0),
+		FeatureFlags:      getEnv("FEATURE_FLAGS", ""),
+		Fetcher:           getEnv("FETCHER", ""),
+		SlowestLinks:      getEnvInt("SLOWEST_LINKS", 10),
+		KeepSnapshots:     getEnvBool("STORE_SNAPSHOTS", false),
+		WARCFile:          getEnv("WARC_FILE", ""),
+		AgentToken:        getEnv("AGENT_TOKEN", ""),
+		AgentRegion:       getEnv("AGENT_REGION", ""),
+		AgentServer:       getEnv("AGENT_SERVER", ""),
+		AgentURL:          getEnv("AGENT_URL", ""),
+		TechRulesFile:     getEnv("TECH_RULES_FILE", ""),
+		CrawlMemoryURLs:   getEnvInt("CRAWL_MEMORY_URLS", 100000),
+		CrawlSpillDir:     getEnv("CRAWL_SPILL_DIR", ""),
+		TrackerRulesFile:  getEnv("TRACKER_RULES_FILE", ""),
+		CrawlBloomFPRate:  getEnvFloat("CRAWL_BLOOM_FP_RATE", 0),
+		HARFile:           getEnv("HAR_FILE", ""),
+		WeightBudget:      getEnv("PAGE_WEIGHT_BUDGET", "html=102400,total=2097152,scripts=25,stylesheets=10,images=50,fonts=6"),
+		RequestFlags:      getEnvList("REQUEST_FEATURE_FLAGS", nil),
+		RedactParams:      getEnvList("REDACT_QUERY_PARAMS", []string{"token", "key", "session", "password", "secret"}),
 	}
 }
 
@@ -56,6 +187,15 @@ func getEnvInt64(key string, fallback int64) int64 {
 	return fallback
 }
 
+func getEnvBool(key string, fallback bool) bool {
+	if value, ok := os.LookupEnv(key); ok {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return fallback
+}
+
 func getEnvDuration(key string, fallback time.Duration) time.Duration {
 	if value, ok := os.LookupEnv(key); ok {
 		if d, err := time.ParseDuration(value); err == nil {
@@ -64,3 +204,28 @@ func getEnvDuration(key string, fallback time.Duration) time.Duration {
 	}
 	return fallback
 }
+
+func getEnvFloat(key string, fallback float64) float64 {
+	if value, ok := os.LookupEnv(key); ok {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return fallback
+}
+
+// getEnvList splits a comma-separated variable; set but empty yields an
+// empty list
+func getEnvList(key string, fallback []string) []string {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
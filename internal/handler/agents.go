@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"website-analyzer/internal/agent"
+)
+
+// SetAgents accepts remote agents into registry; without it the agent
+// endpoints answer 404
+func (h *Handler) SetAgents(registry *agent.Registry) {
+	h.agents = registry
+}
+
+// RegisterAgentHandler records the heartbeat of a remote agent bearing the
+// agent token, see agent.Registration
+func (h *Handler) RegisterAgentHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.agents == nil {
+		writeJSONError(w, "Remote agents are disabled", http.StatusNotFound)
+		return
+	}
+	secret, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || !h.agents.Authorized(strings.TrimSpace(secret)) {
+		writeJSONError(w, "A valid agent token is required", http.StatusUnauthorized)
+		return
+	}
+
+	var registration agent.Registration
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&registration); err != nil {
+		writeJSONError(w, "Invalid registration", http.StatusBadRequest)
+		return
+	}
+	added, err := h.agents.Register(registration)
+	if err != nil {
+		writeJSONError(w, "A region and an http(s) URL are required", http.StatusBadRequest)
+		return
+	}
+	if added {
+		slog.Info("agent registered", "region", registration.Region, "url", registration.URL)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AgentsHandler lists the live remote agents as JSON
+func (h *Handler) AgentsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.adminAuthorized(w, r) {
+		return
+	}
+	if h.agents == nil {
+		writeJSONError(w, "Remote agents are disabled", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, h.agents.Agents())
+}
@@ -0,0 +1,261 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"website-analyzer/internal/analyzer"
+	"website-analyzer/internal/models"
+	"website-analyzer/internal/storage"
+)
+
+// gateResponse is the body returned by the gate endpoint
+type gateResponse struct {
+	URL        string              `json:"url"`
+	ResultID   string              `json:"result_id"`
+	BaselineID string              `json:"baseline_id"`
+	Verdict    *models.GateVerdict `json:"verdict"`
+}
+
+// BaselineHandler marks an analysis as the baseline for its URL. It takes
+// either the id of a stored analysis or a url to analyze now.
+func (h *Handler) BaselineHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.store == nil {
+		writeJSONError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeJSONError(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	labels, actor, ok := h.apiLabels(w, r)
+	if !ok {
+		return
+	}
+
+	id := r.FormValue("id")
+	if id == "" {
+		if r.FormValue("url") == "" {
+			writeJSONError(w, "Either id or url is required", http.StatusBadRequest)
+			return
+		}
+		record, ok := h.analyzeAndSave(w, r, labels, actor, r.FormValue("force") == "true")
+		if !ok {
+			return
+		}
+		id = record.ID
+	}
+
+	if err := h.store.SetBaseline(id); errors.Is(err, storage.ErrNotFound) {
+		writeJSONError(w, "Analysis not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		slog.Error("failed to set baseline", "id", id, "error", err)
+		writeJSONError(w, "Failed to set baseline", http.StatusInternalServerError)
+		return
+	}
+
+	record, err := h.store.Get(id)
+	if err != nil {
+		slog.Error("failed to load analysis", "id", id, "error", err)
+		writeJSONError(w, "Failed to load analysis", http.StatusInternalServerError)
+		return
+	}
+
+	slog.Info("baseline set", "url", record.URL, "id", id)
+	h.audit(actor, storage.AuditBaselineSet, record.URL, "id="+id)
+	writeJSON(w, http.StatusOK, struct {
+		URL        string `json:"url"`
+		BaselineID string `json:"baseline_id"`
+	}{URL: record.URL, BaselineID: id})
+}
+
+// GateHandler analyzes a URL and checks it against the URL's baseline. It
+// answers 200 when the gate passes and 409 when it fails, so CI can gate a
+// deployment on the status code alone.
+func (h *Handler) GateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.store == nil {
+		writeJSONError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeJSONError(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	labels, actor, ok := h.apiLabels(w, r)
+	if !ok {
+		return
+	}
+
+	tolerance := h.analyzer.GateTolerance()
+	if value := r.FormValue("tolerance"); value != "" {
+		t, err := strconv.Atoi(value)
+		if err != nil || t < 0 {
+			writeJSONError(w, "tolerance must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		tolerance = t
+	}
+
+	// Stored URLs are redacted, so look the baseline up the same way
+	targetURL := h.analyzer.Redactor().Text(r.FormValue("url"))
+	baseline, err := h.store.Baseline(targetURL)
+	if errors.Is(err, storage.ErrNotFound) {
+		writeJSONError(w, "No baseline set for this URL", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to load baseline", "url", targetURL, "error", err)
+		writeJSONError(w, "Failed to load baseline", http.StatusInternalServerError)
+		return
+	}
+
+	// A cached result could predate the deploy being gated
+	record, ok := h.analyzeAndSave(w, r, labels, actor, true)
+	if !ok {
+		return
+	}
+
+	// Acknowledged findings never fail the gate
+	h.applyAcknowledgements(baseline.Result)
+	h.applyAcknowledgements(record.Result)
+	verdict := analyzer.EvaluateGate(baseline.Result, record.Result, tolerance)
+
+	slog.Info("gate evaluated", "url", targetURL, "pass", verdict.Pass, "reasons", verdict.Reasons)
+
+	status := http.StatusOK
+	if !verdict.Pass {
+		status = http.StatusConflict
+	}
+	writeJSON(w, status, gateResponse{
+		URL:        targetURL,
+		ResultID:   record.ID,
+		BaselineID: baseline.ID,
+		Verdict:    verdict,
+	})
+}
+
+// apiLabels reads the labels and audit actor for an API request. A bearer
+// API key files the analysis under the key's project and identifies the
+// caller; an invalid key is rejected.
+func (h *Handler) apiLabels(w http.ResponseWriter, r *http.Request) (storage.Labels, string, bool) {
+	labels := labelsFromForm(r)
+
+	key, ok := h.apiKey(w, r)
+	if !ok {
+		return labels, "", false
+	}
+	if key == nil {
+		if labels.Project != "" && !h.projectExists(labels.Project) {
+			writeJSONError(w, "Unknown project", http.StatusBadRequest)
+			return labels, "", false
+		}
+		return labels, webActor(r), true
+	}
+
+	labels.Project = key.Project
+	labels.APIKey = key.ID
+	return labels, "api-key:" + key.Prefix, true
+}
+
+// apiKey returns the bearer API key of the request, or nil when it has no
+// Authorization header. An invalid key writes a JSON error.
+func (h *Handler) apiKey(w http.ResponseWriter, r *http.Request) (*storage.APIKey, bool) {
+	auth := r.Header.Get("Authorization")
+	if auth == "" {
+		return nil, true
+	}
+	secret, found := strings.CutPrefix(auth, "Bearer ")
+	if !found {
+		writeJSONError(w, "Authorization must be a bearer API key", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	key, err := h.store.LookupAPIKey(strings.TrimSpace(secret))
+	if errors.Is(err, storage.ErrNotFound) {
+		writeJSONError(w, "Invalid API key", http.StatusUnauthorized)
+		return nil, false
+	}
+	if err != nil {
+		slog.Error("failed to look up API key", "error", err)
+		writeJSONError(w, "Failed to check API key", http.StatusInternalServerError)
+		return nil, false
+	}
+	return key, true
+}
+
+// analyzeAndSave analyzes the url form value on behalf of actor and stores
+// the raw result, writing a JSON error on failure. force bypasses the
+// result cache.
+func (h *Handler) analyzeAndSave(w http.ResponseWriter, r *http.Request, labels storage.Labels, actor string, force bool) (*storage.Record, bool) {
+	if !h.enforceQuota(w, labels.APIKey, 1) {
+		return nil, false
+	}
+
+	targetURL := r.FormValue("url")
+	var result *models.AnalysisResult
+	err := h.checkDenylist(targetURL)
+	if err == nil {
+		result, err = h.analyzer.AnalyzeWithOptions(r.Context(), targetURL, analyzer.AnalyzeOptions{
+			Profile:      r.FormValue("profile"),
+			Force:        force,
+			IncludeLinks: r.FormValue("include_links") == "true",
+			Flags:        r.FormValue("flags"),
+			Fetcher:      r.FormValue("fetcher"),
+		})
+	}
+	if err != nil {
+		h.audit(actor, storage.AuditAnalysisRun, targetURL, "failed: "+err.Error())
+		if r.Context().Err() == nil {
+			writeJSONError(w, err.Error(), analysisErrorStatus(err))
+		}
+		return nil, false
+	}
+
+	record, err := h.store.Save(result.URL, result, labels)
+	if errors.Is(err, storage.ErrUnknownProject) {
+		writeJSONError(w, "Unknown project", http.StatusBadRequest)
+		return nil, false
+	}
+	if err != nil {
+		slog.Error("failed to save analysis", "url", targetURL, "error", err)
+		writeJSONError(w, "Failed to save analysis", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	h.audit(actor, storage.AuditAnalysisRun, targetURL, "id="+record.ID)
+	h.RunSavedHooks(record.ID)
+	return record, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("failed to encode response", "error", err)
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, errMsg string, status int) {
+	writeJSON(w, status, struct {
+		Error string `json:"error"`
+	}{Error: errMsg})
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"encoding/csv"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"website-analyzer/internal/storage"
+)
+
+// auditPageLimit caps the number of entries shown on the audit page;
+// exports include every matching entry
+const auditPageLimit = 200
+
+// webActor identifies a browser request by its client address
+func webActor(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "web:" + host
+}
+
+// audit appends an entry to the audit log with secrets in target and detail
+// redacted. Failures are logged and never fail the audited request.
+func (h *Handler) audit(actor, action, target, detail string) {
+	if h.store == nil {
+		return
+	}
+	redactor := h.analyzer.Redactor()
+	target, detail = redactor.Text(target), redactor.Text(detail)
+	entry := &storage.AuditEntry{Actor: actor, Action: action, Target: target, Detail: detail}
+	if err := h.store.RecordAudit(entry); err != nil {
+		slog.Error("failed to record audit entry", "action", action, "target", target, "error", err)
+	}
+}
+
+func auditFilter(r *http.Request) storage.AuditFilter {
+	return storage.AuditFilter{
+		Action: r.URL.Query().Get("action"),
+		Actor:  r.URL.Query().Get("actor"),
+	}
+}
+
+func (h *Handler) AuditHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.store == nil {
+		h.renderError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	filter := auditFilter(r)
+	filter.Limit = auditPageLimit
+	entries, err := h.store.AuditLog(filter)
+	if err != nil {
+		slog.Error("failed to list audit log", "error", err)
+		h.renderError(w, "Failed to load audit log", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Entries []storage.AuditEntry
+		Filter  storage.AuditFilter
+		Actions []string
+	}{
+		Entries: entries,
+		Filter:  filter,
+		Actions: []string{
+			storage.AuditAnalysisRun, storage.AuditCrawlRun, storage.AuditCompareRun,
+			storage.AuditBaselineSet, storage.AuditAcknowledge, storage.AuditUnacknowledge,
+			storage.AuditProjectSave, storage.AuditAPIKeyIssue, storage.AuditAPIKeyRevoke,
+			storage.AuditDataErase, storage.AuditDenylistAdd, storage.AuditDenylistRemove,
+			storage.AuditMonitorCreate, storage.AuditMonitorDelete, storage.AuditMonitorPause, storage.AuditMonitorResume,
+			storage.AuditWindowAdd, storage.AuditWindowRemove,
+			storage.AuditConfigExport, storage.AuditConfigImport,
+		},
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "audit.html", data); err != nil {
+		slog.Error("template error", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// AuditExportHandler downloads the filtered audit log as CSV (default) or
+// JSON with ?format=json
+func (h *Handler) AuditExportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.store == nil {
+		http.Error(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	entries, err := h.store.AuditLog(auditFilter(r))
+	if err != nil {
+		slog.Error("failed to export audit log", "error", err)
+		http.Error(w, "Failed to load audit log", http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Disposition", `attachment; filename="audit-log.json"`)
+		writeJSON(w, http.StatusOK, entries)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="audit-log.csv"`)
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"id", "time", "actor", "action", "target", "detail"})
+	for _, entry := range entries {
+		_ = cw.Write([]string{
+			strconv.FormatInt(entry.ID, 10),
+			entry.Time.Format(time.RFC3339Nano),
+			entry.Actor,
+			entry.Action,
+			entry.Target,
+			entry.Detail,
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		slog.Error("failed to write audit export", "error", err)
+	}
+}
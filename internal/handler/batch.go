@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"website-analyzer/internal/analyzer"
+	"website-analyzer/internal/models"
+	"website-analyzer/internal/storage"
+)
+
+const (
+	// defaultBatchLimit caps the URLs of one batch unless SetBatchLimit
+	// says otherwise
+	defaultBatchLimit = 200
+	// batchWorkers is how many pages of a batch are analyzed at once
+	batchWorkers = 4
+)
+
+// batchItem is the outcome for one URL of a batch
+type batchItem struct {
+	URL      string                 `json:"url"`
+	ResultID string                 `json:"result_id,omitempty"`
+	Result   *models.AnalysisResult `json:"result,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+}
+
+// batchResponse is the JSON body of the batch API, in request order
+type batchResponse struct {
+	Succeeded int         `json:"succeeded"`
+	Failed    int         `json:"failed"`
+	Results   []batchItem `json:"results"`
+}
+
+// SetBatchLimit sets the most URLs one batch request may carry
+func (h *Handler) SetBatchLimit(limit int) {
+	h.batchLimit = limit
+}
+
+// BatchAnalyzeHandler analyzes and stores up to the batch limit of URLs,
+// given as repeated url values and/or newline-separated in urls. One URL
+// failing doesn't fail the batch; each item carries its result or error.
+// With dry_run=true it returns the batch's scope plan instead, fetching
+// nothing and charging no quota.
+func (h *Handler) BatchAnalyzeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	urls, labels, actor, ok := h.batchRequest(w, r)
+	if !ok {
+		return
+	}
+
+	opts := batchOptions(r)
+	if r.FormValue("dry_run") == "true" {
+		plan, err := h.analyzer.PlanBatch(r.Context(), urls, opts, batchWorkers, h.planOptions())
+		if err != nil {
+			writeJSONError(w, err.Error(), analysisErrorStatus(err))
+			return
+		}
+		writeJSON(w, http.StatusOK, plan)
+		return
+	}
+
+	if !h.enforceQuota(w, labels.APIKey, len(urls)) {
+		return
+	}
+
+	response := h.runBatch(r.Context(), urls, opts, labels, actor, nil)
+	if r.Context().Err() != nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// batchOptions reads the analysis options of a batch from its form
+func batchOptions(r *http.Request) analyzer.AnalyzeOptions {
+	return analyzer.AnalyzeOptions{
+		Profile:      r.FormValue("profile"),
+		Force:        r.FormValue("force") == "true",
+		IncludeLinks: r.FormValue("include_links") == "true",
+		Flags:        r.FormValue("flags"),
+		Fetcher:      r.FormValue("fetcher"),
+	}
+}
+
+// runBatch analyzes urls batchWorkers at a time, reporting each finished
+// page to progress unless it is nil
+func (h *Handler) runBatch(ctx context.Context, urls []string, opts analyzer.AnalyzeOptions, labels storage.Labels, actor string, progress analyzer.ProgressReporter) batchResponse {
+	if progress != nil {
+		progress.AddPages(len(urls))
+	}
+
+	items := make([]batchItem, len(urls))
+	sem := make(chan struct{}, batchWorkers)
+	var wg sync.WaitGroup
+	for i, targetURL := range urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			start := time.Now()
+			items[i] = h.analyzeBatchItem(ctx, targetURL, opts, labels, actor)
+			if progress != nil {
+				progress.PageDone(time.Since(start))
+			}
+		}()
+	}
+	wg.Wait()
+
+	response := batchResponse{Results: items}
+	for _, item := range items {
+		if item.Error != "" {
+			response.Failed++
+		} else {
+			response.Succeeded++
+		}
+	}
+	slog.Info("batch analyzed", "urls", len(urls), "succeeded", response.Succeeded, "failed", response.Failed)
+	return response
+}
+
+// batchRequest parses and checks the form of a batch, writing a JSON error
+// if it can't run
+func (h *Handler) batchRequest(w http.ResponseWriter, r *http.Request) ([]string, storage.Labels, string, bool) {
+	if h.store == nil {
+		writeJSONError(w, "Analysis history is disabled", http.StatusNotFound)
+		return nil, storage.Labels{}, "", false
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeJSONError(w, "Invalid form data", http.StatusBadRequest)
+		return nil, storage.Labels{}, "", false
+	}
+
+	labels, actor, ok := h.apiLabels(w, r)
+	if !ok {
+		return nil, labels, "", false
+	}
+
+	urls := batchURLs(r)
+	limit := h.batchLimit
+	if limit <= 0 {
+		limit = defaultBatchLimit
+	}
+	if len(urls) == 0 {
+		writeJSONError(w, "At least one url is required", http.StatusBadRequest)
+		return nil, labels, "", false
+	}
+	if len(urls) > limit {
+		writeJSONError(w, fmt.Sprintf("A batch may contain at most %d URLs", limit), http.StatusRequestEntityTooLarge)
+		return nil, labels, "", false
+	}
+	return urls, labels, actor, true
+}
+
+// batchURLs collects the batch's URLs, skipping blank lines
+func batchURLs(r *http.Request) []string {
+	var urls []string
+	for _, value := range r.Form["url"] {
+		if value = strings.TrimSpace(value); value != "" {
+			urls = append(urls, value)
+		}
+	}
+	for _, line := range strings.Split(r.FormValue("urls"), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			urls = append(urls, line)
+		}
+	}
+	return urls
+}
+
+// analyzeBatchItem analyzes and stores one URL of a batch
+func (h *Handler) analyzeBatchItem(ctx context.Context, targetURL string, opts analyzer.AnalyzeOptions, labels storage.Labels, actor string) batchItem {
+	item := batchItem{URL: h.analyzer.Redactor().Text(targetURL)}
+
+	// Usage is recorded as each page is saved, so pages already in flight
+	// may run slightly past a quota
+	if left, err := h.quotaLeft(labels.APIKey); err != nil {
+		slog.Error("failed to check quota", "error", err)
+		item.Error = "Failed to check quota"
+		return item
+	} else if !left {
+		item.Error = "Quota exceeded"
+		return item
+	}
+
+	var result *models.AnalysisResult
+	err := h.checkDenylist(targetURL)
+	if err == nil {
+		result, err = h.analyzer.AnalyzeWithOptions(ctx, targetURL, opts)
+	}
+	if err != nil {
+		h.audit(actor, storage.AuditAnalysisRun, targetURL, "failed: "+err.Error())
+		item.Error = err.Error()
+		return item
+	}
+
+	record, err := h.store.Save(result.URL, result, labels)
+	if errors.Is(err, storage.ErrUnknownProject) {
+		item.Error = "Unknown project"
+		return item
+	}
+	if err != nil {
+		slog.Error("failed to save analysis", "url", targetURL, "error", err)
+		item.Error = "Failed to save analysis"
+		return item
+	}
+
+	h.audit(actor, storage.AuditAnalysisRun, targetURL, "id="+record.ID)
+	h.RunSavedHooks(record.ID)
+	item.ResultID = record.ID
+	item.Result = result
+	return item
+}
+
+// quotaLeft reports whether the API key may start another analysis
+func (h *Handler) quotaLeft(keyID string) (bool, error) {
+	if h.quotas == (Quotas{}) {
+		return true, nil
+	}
+	if keyID == "" {
+		return false, nil
+	}
+	windows, err := h.quotaWindows(keyID, time.Now())
+	if err != nil {
+		return false, err
+	}
+	for _, window := range windows {
+		if !window.fits(1) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// planOptions gives dry runs the stored history and the denylist
+func (h *Handler) planOptions() analyzer.PlanOptions {
+	return analyzer.PlanOptions{
+		Previous: func(url string) *models.AnalysisResult {
+			if h.store == nil {
+				return nil
+			}
+			record, err := h.store.Latest(h.analyzer.Redactor().Text(url))
+			if err != nil {
+				if !errors.Is(err, storage.ErrNotFound) {
+					slog.Error("failed to load previous analysis", "error", err)
+				}
+				return nil
+			}
+			return record.Result
+		},
+		Denied: func(url string) string {
+			if err := h.checkDenylist(url); err != nil {
+				return err.Error()
+			}
+			return ""
+		},
+	}
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"website-analyzer/internal/analyzer"
+)
+
+// BotInfoPath is where the page describing the analyzer's requests is
+// served; BOT_INFO_URL should point here
+const BotInfoPath = "/.well-known/bot"
+
+// SetBotContact sets the address site owners can write to about the bot;
+// empty leaves it off the bot info page
+func (h *Handler) SetBotContact(contact string) {
+	h.botContact = contact
+}
+
+// BotInfoHandler describes the requests the analyzer makes for site owners
+// who find its User-Agent in their logs
+func (h *Handler) BotInfoHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	data := struct {
+		UserAgent     string
+		RobotsToken   string
+		RespectRobots bool
+		Contact       string
+	}{
+		UserAgent:     h.analyzer.UserAgent(),
+		RobotsToken:   analyzer.RobotsToken(),
+		RespectRobots: h.analyzer.RespectsRobots(),
+		Contact:       h.botContact,
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "bot.html", data); err != nil {
+		slog.Error("template error", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"website-analyzer/internal/bundle"
+	"website-analyzer/internal/storage"
+)
+
+// maxBundleSize bounds an imported configuration bundle
+const maxBundleSize = 4 << 20
+
+// ConfigExportHandler downloads the monitors, projects with their
+// webhooks, denylist and profiles as a YAML bundle
+func (h *Handler) ConfigExportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.adminAuthorized(w, r) {
+		return
+	}
+	if h.store == nil {
+		writeJSONError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	b, err := bundle.Export(h.store, h.analyzer.Profiles())
+	var data []byte
+	if err == nil {
+		data, err = b.Marshal()
+	}
+	if err != nil {
+		slog.Error("failed to export configuration", "error", err)
+		writeJSONError(w, "Failed to export configuration", http.StatusInternalServerError)
+		return
+	}
+	h.audit(adminActor, storage.AuditConfigExport, "bundle",
+		fmt.Sprintf("projects=%d monitors=%d denylist=%d", len(b.Projects), len(b.Monitors), len(b.Denylist)))
+
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Header().Set("Content-Disposition", `attachment; filename="website-analyzer-config.yaml"`)
+	_, _ = w.Write(data)
+}
+
+// ConfigImportHandler applies a YAML bundle from the request body. Nothing
+// is imported when the bundle is invalid for this instance.
+func (h *Handler) ConfigImportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.adminAuthorized(w, r) {
+		return
+	}
+	if h.store == nil {
+		writeJSONError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBundleSize))
+	if err != nil {
+		writeJSONError(w, "Bundle too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	b, err := bundle.Parse(data)
+	if err == nil {
+		err = b.Validate(h.store, h.analyzer.Profiles())
+	}
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := bundle.Import(h.store, b, h.analyzer.Profiles())
+	if err != nil {
+		slog.Error("failed to import configuration", "error", err)
+		writeJSONError(w, "Failed to import configuration", http.StatusInternalServerError)
+		return
+	}
+	slog.Info("configuration imported", "projects", result.Projects, "monitors", result.Monitors, "denylist", result.Denylist)
+	h.audit(adminActor, storage.AuditConfigImport, "bundle",
+		fmt.Sprintf("projects=%d monitors=%d monitors_skipped=%d denylist=%d",
+			result.Projects, result.Monitors, result.MonitorsSkipped, result.Denylist))
+
+	writeJSON(w, http.StatusOK, result)
+}
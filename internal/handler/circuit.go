@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"net/http"
+
+	"website-analyzer/internal/models"
+)
+
+// CircuitBreakerHandler reports the link checker's per-domain circuit
+// breaker, which is shared across analyses
+func (h *Handler) CircuitBreakerHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	states := h.analyzer.CircuitBreakerState()
+	if states == nil {
+		states = []models.CircuitState{}
+	}
+	writeJSON(w, http.StatusOK, states)
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"website-analyzer/internal/storage"
+)
+
+// errDenylistUnavailable fails submissions when the denylist can't be read,
+// since letting them through could contact a listed domain
+var errDenylistUnavailable = errors.New("the do-not-analyze list could not be checked; try again later")
+
+// deniedError rejects a submission whose host is on the denylist
+type deniedError struct {
+	host  string
+	entry storage.DenyEntry
+}
+
+func (e *deniedError) Error() string {
+	msg := e.host + " is on this service's do-not-analyze list"
+	if e.entry.Reason != "" {
+		msg += ": " + e.entry.Reason
+	}
+	return msg
+}
+
+// checkDenylist rejects the submission if any of urls is on a denied
+// domain. URLs that don't parse are left for the analyzer to reject.
+func (h *Handler) checkDenylist(urls ...string) error {
+	if h.store == nil {
+		return nil
+	}
+	for _, rawURL := range urls {
+		u, err := url.Parse(strings.TrimSpace(rawURL))
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		entry, err := h.store.DeniedHost(u.Hostname())
+		if errors.Is(err, storage.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			slog.Error("failed to check denylist", "error", err)
+			return errDenylistUnavailable
+		}
+		return &deniedError{host: u.Hostname(), entry: *entry}
+	}
+	return nil
+}
+
+// DenylistHandler lists the do-not-analyze domains, or adds the domain
+// form value with an optional reason shown to rejected users
+func (h *Handler) DenylistHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.adminAuthorized(w, r) {
+		return
+	}
+	if h.store == nil {
+		writeJSONError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		entries, err := h.store.Denylist()
+		if err != nil {
+			slog.Error("failed to list denylist", "error", err)
+			writeJSONError(w, "Failed to load denylist", http.StatusInternalServerError)
+			return
+		}
+		if entries == nil {
+			entries = []storage.DenyEntry{}
+		}
+		writeJSON(w, http.StatusOK, entries)
+		return
+	}
+
+	domain := strings.Trim(strings.ToLower(strings.TrimSpace(r.FormValue("domain"))), ".")
+	if domain == "" || strings.ContainsAny(domain, "/: ") {
+		writeJSONError(w, "domain must be a host name such as example.com", http.StatusBadRequest)
+		return
+	}
+
+	entry := &storage.DenyEntry{Domain: domain, Reason: strings.TrimSpace(r.FormValue("reason"))}
+	if err := h.store.Deny(entry); err != nil {
+		slog.Error("failed to save denylist entry", "error", err)
+		writeJSONError(w, "Failed to save denylist entry", http.StatusInternalServerError)
+		return
+	}
+	h.audit(adminActor, storage.AuditDenylistAdd, entry.Domain, entry.Reason)
+
+	writeJSON(w, http.StatusOK, entry)
+}
+
+// RemoveDenylistHandler takes the path's domain off the denylist
+func (h *Handler) RemoveDenylistHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.adminAuthorized(w, r) {
+		return
+	}
+	if h.store == nil {
+		writeJSONError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	domain := r.PathValue("domain")
+	err := h.store.RemoveDenied(domain)
+	if errors.Is(err, storage.ErrNotFound) {
+		writeJSONError(w, "Domain is not on the denylist", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to remove denylist entry", "error", err)
+		writeJSONError(w, "Failed to remove denylist entry", http.StatusInternalServerError)
+		return
+	}
+	h.audit(adminActor, storage.AuditDenylistRemove, domain, "")
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"website-analyzer/internal/analyzer"
+	"website-analyzer/internal/models"
+	"website-analyzer/internal/storage"
+)
+
+// diffFailure is a failed diff lookup with the status to answer with
+type diffFailure struct {
+	msg    string
+	status int
+}
+
+// DiffHandler compares two stored analyses. The from and to query values
+// are each an analysis ID or a URL, which stands for its latest stored
+// analysis.
+func (h *Handler) DiffHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.store == nil {
+		writeJSONError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	diff, err := h.diff(r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	if err != nil {
+		writeJSONError(w, err.msg, err.status)
+		return
+	}
+	writeJSON(w, http.StatusOK, diff)
+}
+
+// DiffPageHandler renders the comparison of two stored analyses, or a form
+// asking for them
+func (h *Handler) DiffPageHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.store == nil {
+		h.renderError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	data := struct {
+		From string
+		To   string
+		Diff *models.AnalysisDiff
+	}{
+		From: r.URL.Query().Get("from"),
+		To:   r.URL.Query().Get("to"),
+	}
+	if data.From != "" || data.To != "" {
+		diff, err := h.diff(data.From, data.To)
+		if err != nil {
+			h.renderError(w, err.msg, err.status)
+			return
+		}
+		data.Diff = diff
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "diff.html", data); err != nil {
+		slog.Error("template error", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// diff loads both analyses and compares them. Acknowledged findings are
+// left out, as on the results page.
+func (h *Handler) diff(from, to string) (*models.AnalysisDiff, *diffFailure) {
+	if from == "" || to == "" {
+		return nil, &diffFailure{"from and to are required", http.StatusBadRequest}
+	}
+
+	fromRecord, err := h.diffRecord(from)
+	if err != nil {
+		return nil, err
+	}
+	toRecord, err := h.diffRecord(to)
+	if err != nil {
+		return nil, err
+	}
+
+	h.applyAcknowledgements(fromRecord.Result)
+	h.applyAcknowledgements(toRecord.Result)
+	diff := analyzer.DiffResults(fromRecord.Result, toRecord.Result)
+	diff.From = models.DiffSide{ID: fromRecord.ID, URL: fromRecord.URL, AnalyzedAt: fromRecord.CreatedAt}
+	diff.To = models.DiffSide{ID: toRecord.ID, URL: toRecord.URL, AnalyzedAt: toRecord.CreatedAt}
+	return diff, nil
+}
+
+// diffRecord loads the analysis with the ID ref, or the latest analysis of
+// the URL ref
+func (h *Handler) diffRecord(ref string) (*storage.Record, *diffFailure) {
+	var (
+		record *storage.Record
+		err    error
+	)
+	if strings.Contains(ref, "://") {
+		// Stored URLs are redacted, so look them up the same way
+		ref = h.analyzer.Redactor().Text(ref)
+		record, err = loadStored(h, func() (*storage.Record, error) { return h.store.Latest(ref) })
+	} else {
+		record, err = loadStored(h, func() (*storage.Record, error) { return h.store.Get(ref) })
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, &diffFailure{fmt.Sprintf("No stored analysis found for %s", ref), http.StatusNotFound}
+	}
+	if err != nil {
+		slog.Error("failed to load analysis", "ref", ref, "error", err)
+		return nil, &diffFailure{"Failed to load analysis", http.StatusInternalServerError}
+	}
+	return record, nil
+}
@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"website-analyzer/internal/analyzer"
+	"website-analyzer/internal/storage"
+)
+
+// erasureTTL is how long an erasure confirmation token stays valid
+const erasureTTL = 10 * time.Minute
+
+// adminActor is the audit actor of requests made with the admin token
+const adminActor = "admin"
+
+// pendingErasure is an erasure awaiting confirmation
+type pendingErasure struct {
+	erasure storage.Erasure
+	expires time.Time
+}
+
+// erasures holds the erasures awaiting confirmation by token
+type erasures struct {
+	mu      sync.Mutex
+	pending map[string]pendingErasure
+}
+
+// erasureResponse is the JSON body of the erasure API
+type erasureResponse struct {
+	Erasure   storage.Erasure       `json:"erasure"`
+	Counts    storage.ErasureCounts `json:"counts"`
+	Token     string                `json:"confirmation_token,omitempty"`
+	ExpiresAt time.Time             `json:"expires_at,omitzero"`
+	Erased    bool                  `json:"erased"`
+}
+
+// SetAdminToken enables the admin API for bearers of token
+func (h *Handler) SetAdminToken(token string) {
+	h.adminToken = token
+}
+
+// adminAuthorized checks the request's bearer admin token, writing a JSON
+// error when it is missing or wrong. A project API key is recognized but
+// forbidden, since it only grants access to its own project.
+func (h *Handler) adminAuthorized(w http.ResponseWriter, r *http.Request) bool {
+	if h.adminToken == "" {
+		writeJSONError(w, "The admin API is disabled", http.StatusNotFound)
+		return false
+	}
+	secret, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	secret = strings.TrimSpace(secret)
+	if found && subtle.ConstantTimeCompare([]byte(secret), []byte(h.adminToken)) == 1 {
+		return true
+	}
+	if found && h.store != nil {
+		if _, err := h.store.LookupAPIKey(secret); err == nil {
+			writeJSONError(w, "API keys can't use the admin API", http.StatusForbidden)
+			return false
+		}
+	}
+	writeJSONError(w, "A valid admin token is required", http.StatusUnauthorized)
+	return false
+}
+
+// ErasureHandler previews the permanent removal of everything stored about
+// the url or domain form value and issues a token to confirm it with
+func (h *Handler) ErasureHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.adminAuthorized(w, r) {
+		return
+	}
+	if h.store == nil {
+		writeJSONError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	erasure, msg := parseErasure(r.FormValue("url"), r.FormValue("domain"))
+	if msg != "" {
+		writeJSONError(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	counts, err := h.store.Erase(erasure, true)
+	if err != nil {
+		slog.Error("failed to preview erasure", "error", err)
+		writeJSONError(w, "Failed to find stored data", http.StatusInternalServerError)
+		return
+	}
+
+	token, err := newErasureToken()
+	if err != nil {
+		slog.Error("failed to generate erasure token", "error", err)
+		writeJSONError(w, "Failed to issue confirmation token", http.StatusInternalServerError)
+		return
+	}
+	expires := time.Now().Add(erasureTTL).UTC()
+	h.erasures.add(token, pendingErasure{erasure: erasure, expires: expires})
+
+	writeJSON(w, http.StatusOK, erasureResponse{Erasure: erasure, Counts: counts, Token: token, ExpiresAt: expires})
+}
+
+// ConfirmErasureHandler carries out the erasure the path's token was issued for
+func (h *Handler) ConfirmErasureHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.adminAuthorized(w, r) {
+		return
+	}
+	if h.store == nil {
+		writeJSONError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	pending, ok := h.erasures.take(r.PathValue("token"), time.Now())
+	if !ok {
+		writeJSONError(w, "Unknown or expired confirmation token", http.StatusNotFound)
+		return
+	}
+
+	counts, err := h.store.Erase(pending.erasure, false)
+	if err != nil {
+		slog.Error("failed to erase data", "error", err)
+		writeJSONError(w, "Failed to erase stored data", http.StatusInternalServerError)
+		return
+	}
+
+	// Cached results and pages would otherwise serve, and save again,
+	// what was just erased
+	cached := pending.erasure
+	if cached.URL != "" {
+		cached.URL = analyzer.NormalizeURL(cached.URL)
+	}
+	h.analyzer.PurgeCache(r.Context(), cached.Matches)
+
+	// The log names a digest so the request can be proven without keeping
+	// the erased URL or domain
+	digest := sha256.Sum256([]byte(pending.erasure.URL + pending.erasure.Domain))
+	slog.Info("data erased", "analyses", counts.Analyses, "audit_entries", counts.AuditEntries)
+	h.audit(adminActor, storage.AuditDataErase, "sha256:"+hex.EncodeToString(digest[:]),
+		fmt.Sprintf("analyses=%d baselines=%d acknowledgements=%d monitors=%d maintenance_windows=%d audit_entries=%d",
+			counts.Analyses, counts.Baselines, counts.Acknowledgements, counts.Monitors, counts.MaintenanceWindows, counts.AuditEntries))
+
+	writeJSON(w, http.StatusOK, erasureResponse{Erasure: pending.erasure, Counts: counts, Erased: true})
+}
+
+// parseErasure validates an erasure request, returning an error message
+// when it is invalid
+func parseErasure(rawURL, domain string) (storage.Erasure, string) {
+	rawURL = strings.TrimSpace(rawURL)
+	domain = strings.Trim(strings.ToLower(strings.TrimSpace(domain)), ".")
+
+	switch {
+	case rawURL != "" && domain != "":
+		return storage.Erasure{}, "Give either a url or a domain, not both"
+	case rawURL != "":
+		if u, err := url.Parse(rawURL); err != nil || u.Host == "" {
+			return storage.Erasure{}, "url must be an absolute URL"
+		}
+		return storage.Erasure{URL: rawURL}, ""
+	case domain != "":
+		if strings.ContainsAny(domain, "/: ") {
+			return storage.Erasure{}, "domain must be a host name such as example.com"
+		}
+		return storage.Erasure{Domain: domain}, ""
+	default:
+		return storage.Erasure{}, "A url or domain is required"
+	}
+}
+
+func newErasureToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (e *erasures) add(token string, pending pendingErasure) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.pending == nil {
+		e.pending = make(map[string]pendingErasure)
+	}
+	e.pending[token] = pending
+}
+
+// take removes and returns the pending erasure for token, dropping expired
+// ones along the way
+func (e *erasures) take(token string, now time.Time) (pendingErasure, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for t, p := range e.pending {
+		if now.After(p.expires) {
+			delete(e.pending, t)
+		}
+	}
+	pending, ok := e.pending[token]
+	delete(e.pending, token)
+	return pending, ok
+}
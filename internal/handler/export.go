@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"website-analyzer/internal/analyzer"
+	"website-analyzer/internal/models"
+	"website-analyzer/internal/storage"
+)
+
+// ExportHandler downloads a stored analysis as csv (its broken links), json
+// (the full result), md (a readable report) or warc (the page's snapshot
+// for web-archiving tools). Acknowledged findings are left out, as on the
+// results page.
+func (h *Handler) ExportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.store == nil {
+		http.Error(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "csv" && format != "json" && format != "md" && format != "warc" {
+		http.Error(w, "format must be csv, json, md or warc", http.StatusBadRequest)
+		return
+	}
+
+	record, err := h.store.Get(r.PathValue("id"))
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Analysis not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to load analysis", "error", err)
+		http.Error(w, "Failed to load analysis", http.StatusInternalServerError)
+		return
+	}
+	h.applyAcknowledgements(record.Result)
+
+	var snapshot *models.Snapshot
+	if format == "warc" {
+		snapshot, err = h.store.Snapshot(record.ID)
+		if errors.Is(err, storage.ErrNotFound) {
+			http.Error(w, analyzer.ErrNoSnapshot.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			slog.Error("failed to load snapshot", "id", record.ID, "error", err)
+			http.Error(w, "Failed to load snapshot", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="analysis-%s.%s"`, record.ID, format))
+	switch format {
+	case "json":
+		writeJSON(w, http.StatusOK, record)
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"url", "status_code", "error_type", "error", "attempts"})
+		for _, link := range record.Result.InaccessibleLinks {
+			statusCode := ""
+			if link.StatusCode != 0 {
+				statusCode = strconv.Itoa(link.StatusCode)
+			}
+			_ = cw.Write([]string{link.URL, statusCode, link.ErrorType.String(), link.Error, strconv.Itoa(link.Attempts)})
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			slog.Error("failed to write analysis export", "error", err)
+		}
+	case "md":
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		_, _ = w.Write(markdownReport(record))
+	case "warc":
+		w.Header().Set("Content-Type", "application/warc")
+		if err := analyzer.WriteWARC(w, snapshot, record.Result); err != nil {
+			slog.Error("failed to write analysis export", "error", err)
+		}
+	}
+}
+
+// markdownReport summarizes a stored analysis for readers without access
+// to the analyzer
+func markdownReport(record *storage.Record) []byte {
+	result := record.Result
+	var b bytes.Buffer
+
+	title := result.Title
+	if title == "" {
+		title = result.URL
+	}
+	fmt.Fprintf(&b, "# %s\n\n", mdEscape(title))
+	fmt.Fprintf(&b, "- **URL:** %s\n", result.URL)
+	fmt.Fprintf(&b, "- **Analyzed:** %s\n", record.CreatedAt.Format("2006-01-02 15:04:05 UTC"))
+	if record.Project != "" {
+		fmt.Fprintf(&b, "- **Project:** %s\n", mdEscape(record.Project))
+	}
+	if result.Profile != "" {
+		fmt.Fprintf(&b, "- **Profile:** %s\n", mdEscape(result.Profile))
+	}
+	fmt.Fprintf(&b, "- **HTML version:** %s\n", mdEscape(result.HTMLVersion))
+	fmt.Fprintf(&b, "- **Login form:** %s\n", yesNo(result.HasLoginForm))
+
+	if s := result.Scores; s != nil {
+		b.WriteString("\n## Scores\n\n| Overall | SEO | Accessibility | Links |\n| --- | --- | --- | --- |\n")
+		fmt.Fprintf(&b, "| %d | %d | %d | %d |\n", s.Overall, s.SEO, s.Accessibility, s.Links)
+	}
+
+	b.WriteString("\n## Structure\n\n| Metric | Value |\n| --- | --- |\n")
+	levels := make([]string, 0, len(result.Headings))
+	for level := range result.Headings {
+		levels = append(levels, level)
+	}
+	sort.Strings(levels)
+	for _, level := range levels {
+		fmt.Fprintf(&b, "| %s headings | %d |\n", strings.ToUpper(level), result.Headings[level])
+	}
+	fmt.Fprintf(&b, "| Internal links | %d |\n", result.InternalLinks)
+	fmt.Fprintf(&b, "| External links | %d |\n", result.ExternalLinks)
+	fmt.Fprintf(&b, "| Broken links | %d |\n", len(result.InaccessibleLinks))
+	fmt.Fprintf(&b, "| Words | %d |\n", result.WordCount)
+
+	if len(result.InaccessibleLinks) > 0 {
+		b.WriteString("\n## Broken Links\n\n| URL | Status | Problem |\n| --- | --- | --- |\n")
+		for _, link := range result.InaccessibleLinks {
+			status := "-"
+			if link.StatusCode != 0 {
+				status = strconv.Itoa(link.StatusCode)
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", mdEscape(link.URL), status, mdEscape(link.Error))
+		}
+	}
+
+	if r := result.Readiness; r != nil && len(r.Issues) > 0 {
+		b.WriteString("\n## Production Readiness\n\n")
+		for _, issue := range r.Issues {
+			fmt.Fprintf(&b, "- %s\n", mdEscape(issue.Message))
+		}
+	}
+
+	if a := result.Accessibility; a != nil && len(a.Issues) > 0 {
+		b.WriteString("\n## Accessibility\n\n")
+		for _, issue := range a.Issues {
+			fmt.Fprintf(&b, "- %s\n", mdEscape(issue.Message))
+		}
+	}
+
+	if info := result.Analyzer; info != nil {
+		fmt.Fprintf(&b, "\n---\n\nAnalyzer %s", mdEscape(versionLabel(info)))
+		if len(info.Features) > 0 {
+			fmt.Fprintf(&b, " with %s", strings.Join(info.Features, ", "))
+		}
+		b.WriteString("\n")
+	}
+
+	return b.Bytes()
+}
+
+// versionLabel is the version of an analyzer build with its short VCS
+// revision, e.g. "v1.2.3 (4f2c1ab)"
+func versionLabel(info *models.AnalyzerInfo) string {
+	if info.Revision == "" {
+		return info.Version
+	}
+	revision := info.Revision[:min(7, len(info.Revision))]
+	if info.Modified {
+		revision += "+dirty"
+	}
+	return info.Version + " (" + revision + ")"
+}
+
+// mdEscape keeps page-supplied text from breaking out of a Markdown table
+// cell or list item
+func mdEscape(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.NewReplacer(`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`, "`", "\\`", "<", "&lt;", "[", `\[`).Replace(s)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"website-analyzer/internal/storage"
+)
+
+// maxGrafanaRequestSize caps the JSON body of datasource queries
+const maxGrafanaRequestSize = 1 << 20
+
+// grafanaMetricLabels names storage.MetricNames in Grafana's metric picker
+var grafanaMetricLabels = map[string]string{
+	"broken_links":        "Broken links",
+	"score_overall":       "Overall score",
+	"score_seo":           "SEO score",
+	"score_accessibility": "Accessibility score",
+	"score_links":         "Links score",
+	"duration_ms":         "Analysis duration (ms)",
+	"requests":            "Outbound requests",
+	"bytes_downloaded":    "Bytes downloaded",
+	"html_size":           "HTML size (bytes)",
+	"word_count":          "Word count",
+	"response_time_ms":    "Page response time (ms)",
+	"link_count":          "Links on the page",
+}
+
+// grafanaPayloadOptions are the per-query filters offered in Grafana's
+// query editor
+var grafanaPayloadOptions = []grafanaPayload{
+	{Label: "URL", Name: "url", Type: "input", Placeholder: "All pages"},
+	{Label: "Project", Name: "project", Type: "input", Placeholder: "All projects"},
+}
+
+type grafanaPayload struct {
+	Label       string `json:"label"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Placeholder string `json:"placeholder,omitempty"`
+}
+
+type grafanaMetric struct {
+	Label    string           `json:"label"`
+	Value    string           `json:"value"`
+	Payloads []grafanaPayload `json:"payloads"`
+}
+
+// grafanaQuery is the body of a datasource query. Older plugin versions
+// send the filters as data rather than payload.
+type grafanaQuery struct {
+	Range struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"range"`
+	MaxDataPoints int `json:"maxDataPoints"`
+	Targets       []struct {
+		Target  string         `json:"target"`
+		Hide    bool           `json:"hide"`
+		Payload grafanaFilters `json:"payload"`
+		Data    grafanaFilters `json:"data"`
+	} `json:"targets"`
+}
+
+type grafanaFilters struct {
+	URL     string `json:"url"`
+	Project string `json:"project"`
+}
+
+// grafanaSeries is a time series in the datasource's response; each data
+// point is [value, unix milliseconds]
+type grafanaSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+// GrafanaTestHandler answers the datasource's connection test
+func (h *Handler) GrafanaTestHandler(w http.ResponseWriter, r *http.Request) {
+	if h.store == nil {
+		writeJSONError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// GrafanaSearchHandler lists the metric names for older datasource
+// plugin versions
+func (h *Handler) GrafanaSearchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, storage.MetricNames)
+}
+
+// GrafanaMetricsHandler lists the metrics with their labels and filters
+func (h *Handler) GrafanaMetricsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	metrics := make([]grafanaMetric, len(storage.MetricNames))
+	for i, name := range storage.MetricNames {
+		metrics[i] = grafanaMetric{Label: grafanaMetricLabels[name], Value: name, Payloads: grafanaPayloadOptions}
+	}
+	writeJSON(w, http.StatusOK, metrics)
+}
+
+// GrafanaQueryHandler returns stored analysis metrics as time series. A
+// target filtered to one URL is a single series; otherwise there is one
+// series per page, named "<metric> <url>".
+func (h *Handler) GrafanaQueryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.store == nil {
+		writeJSONError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	var query grafanaQuery
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxGrafanaRequestSize)).Decode(&query); err != nil {
+		writeJSONError(w, "Invalid query", http.StatusBadRequest)
+		return
+	}
+
+	series := []grafanaSeries{}
+	for _, target := range query.Targets {
+		if target.Hide || target.Target == "" {
+			continue
+		}
+		if !slices.Contains(storage.MetricNames, target.Target) {
+			writeJSONError(w, "Unknown metric "+target.Target, http.StatusBadRequest)
+			return
+		}
+		filters := target.Payload
+		if filters == (grafanaFilters{}) {
+			filters = target.Data
+		}
+
+		points, err := h.store.MetricSeries(storage.MetricFilter{
+			Metric:  target.Target,
+			URL:     filters.URL,
+			Project: filters.Project,
+			From:    query.Range.From,
+			To:      query.Range.To,
+			Limit:   query.MaxDataPoints,
+		})
+		if err != nil {
+			slog.Error("failed to load metric", "metric", target.Target, "error", err)
+			writeJSONError(w, "Failed to load metric", http.StatusInternalServerError)
+			return
+		}
+		series = append(series, groupSeries(target.Target, filters.URL != "", points)...)
+	}
+
+	writeJSON(w, http.StatusOK, series)
+}
+
+// groupSeries splits points into one series per URL in order of first
+// appearance, or keeps them as one series named after the metric
+func groupSeries(metric string, single bool, points []storage.MetricPoint) []grafanaSeries {
+	if single {
+		s := grafanaSeries{Target: metric, Datapoints: [][2]float64{}}
+		for _, p := range points {
+			s.Datapoints = append(s.Datapoints, [2]float64{p.Value, float64(p.Time.UnixMilli())})
+		}
+		return []grafanaSeries{s}
+	}
+
+	var series []grafanaSeries
+	index := make(map[string]int)
+	for _, p := range points {
+		i, ok := index[p.URL]
+		if !ok {
+			i = len(series)
+			index[p.URL] = i
+			series = append(series, grafanaSeries{Target: metric + " " + p.URL})
+		}
+		series[i].Datapoints = append(series[i].Datapoints, [2]float64{p.Value, float64(p.Time.UnixMilli())})
+	}
+	return series
+}
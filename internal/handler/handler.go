@@ -1,30 +1,67 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"html/template"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
+	"website-analyzer/internal/agent"
 	"website-analyzer/internal/analyzer"
+	"website-analyzer/internal/jobs"
 	"website-analyzer/internal/models"
+	"website-analyzer/internal/storage"
+	"website-analyzer/internal/validator"
 )
 
+// historyLimit caps the number of analyses shown on the history page
+const historyLimit = 100
+
 type Handler struct {
 	analyzer  *analyzer.Analyzer
+	store     storage.Store
 	templates *template.Template
+	quotas    Quotas
+	// adminToken authorizes the admin API; empty disables it
+	adminToken string
+	erasures   erasures
+	started    time.Time
+	assets     *StaticAssets
+	batchLimit int
+	botContact string
+	savedHooks []namedHook
+	jobs       *jobs.Manager
+	agents     *agent.Registry
+	// scheduledHooks run after monitor runs only
+	scheduledHooks []namedHook
 }
 
-func NewHandler(analyzer *analyzer.Analyzer, templatesPath string) (*Handler, error) {
-	tmpl, err := template.ParseGlob(templatesPath + "/*.html")
+// NewHandler creates a handler; store may be nil to disable history
+func NewHandler(analyzer *analyzer.Analyzer, store storage.Store, templatesPath string) (*Handler, error) {
+	h := &Handler{
+		analyzer: analyzer,
+		store:    store,
+		started:  time.Now(),
+	}
+
+	// asset resolves at render time so SetAssets may come after parsing
+	funcs := template.FuncMap{"asset": func(name string) string { return h.assets.Path(name) }}
+	tmpl, err := template.New("").Funcs(funcs).ParseGlob(templatesPath + "/*.html")
 	if err != nil {
 		return nil, err
 	}
+	h.templates = tmpl
 
-	return &Handler{
-		analyzer:  analyzer,
-		templates: tmpl,
-	}, nil
+	return h, nil
+}
+
+// SetAssets serves templates' asset links from fingerprinted static files
+func (h *Handler) SetAssets(assets *StaticAssets) {
+	h.assets = assets
 }
 
 func (h *Handler) IndexHandler(w http.ResponseWriter, r *http.Request) {
@@ -34,8 +71,15 @@ func (h *Handler) IndexHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	data := struct {
-		Error string
-	}{}
+		Error          string
+		Profiles       []analyzer.Profile
+		DefaultProfile string
+		Projects       []storage.Project
+	}{
+		Profiles:       h.analyzer.Profiles(),
+		DefaultProfile: h.analyzer.DefaultProfile(),
+		Projects:       h.projects(),
+	}
 
 	if err := h.templates.ExecuteTemplate(w, "index.html", data); err != nil {
 		slog.Error("template error", "error", err)
@@ -56,31 +100,447 @@ func (h *Handler) AnalyzeHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	targetURL := r.FormValue("url")
+	opts := analyzer.AnalyzeOptions{
+		Keyword:      r.FormValue("keyword"),
+		Profile:      r.FormValue("profile"),
+		Force:        r.FormValue("force") == "true",
+		IncludeLinks: r.FormValue("include_links") == "true",
+		Flags:        r.FormValue("flags"),
+		Fetcher:      r.FormValue("fetcher"),
+	}
+	labels := labelsFromForm(r)
+	if labels.Project != "" && !h.projectExists(labels.Project) {
+		h.renderError(w, "Unknown project", http.StatusBadRequest)
+		return
+	}
 
 	// Analyze
 	start := time.Now()
-	result, err := h.analyzer.Analyze(targetURL)
+	var result *models.AnalysisResult
+	err := h.checkDenylist(targetURL)
+	if err == nil {
+		result, err = h.analyzer.AnalyzeWithOptions(r.Context(), targetURL, opts)
+	}
 	duration := time.Since(start)
 
-	slog.Info("analysis completed",
+	attrs := []any{"url", targetURL, "duration", duration, "error", err}
+	if result != nil {
+		attrs = append(attrs, analyzer.StagesAttr(result.Stages))
+	}
+	slog.Info("analysis completed", attrs...)
+	if err != nil {
+		h.audit(webActor(r), storage.AuditAnalysisRun, targetURL, "failed: "+err.Error())
+	}
+
+	// Nobody is waiting for a response once the client has gone away
+	if r.Context().Err() != nil {
+		return
+	}
+
+	if err != nil {
+		h.renderError(w, err.Error(), analysisErrorStatus(err))
+		return
+	}
+
+	// Persist the full result so acknowledgements can later be undone
+	var record *storage.Record
+	if h.store != nil {
+		record, err = h.store.Save(result.URL, result, labels)
+		if err != nil {
+			slog.Error("failed to save analysis", "url", targetURL, "error", err)
+			h.audit(webActor(r), storage.AuditAnalysisRun, targetURL, "not saved")
+		} else {
+			h.audit(webActor(r), storage.AuditAnalysisRun, targetURL, "id="+record.ID)
+			h.RunSavedHooks(record.ID)
+		}
+		h.applyAcknowledgements(result)
+	}
+
+	// Render results
+	h.renderResults(w, result, record)
+}
+
+func (h *Handler) HistoryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.store == nil {
+		h.renderError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	filter := storage.Filter{
+		Project: r.URL.Query().Get("project"),
+		Tag:     strings.ToLower(strings.TrimSpace(r.URL.Query().Get("tag"))),
+		Limit:   historyLimit,
+	}
+	summaries, err := h.store.List(filter)
+	if err != nil {
+		slog.Error("failed to list analyses", "error", err)
+		h.renderError(w, "Failed to load history", http.StatusInternalServerError)
+		return
+	}
+	h.analyzer.Redactor().Walk(summaries)
+
+	data := struct {
+		Analyses []storage.Summary
+		Filter   storage.Filter
+		Projects []storage.Project
+	}{
+		Analyses: summaries,
+		Filter:   filter,
+		Projects: h.projects(),
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "history.html", data); err != nil {
+		slog.Error("template error", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) HistoryResultHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.store == nil {
+		h.renderError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	record, ok := h.loadRecord(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	h.applyAcknowledgements(record.Result)
+	h.renderResults(w, record.Result, record)
+}
+
+func (h *Handler) AcknowledgeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.store == nil {
+		h.renderError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		h.renderError(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	// The host comes from the stored analysis rather than the form
+	record, ok := h.loadRecord(w, r.FormValue("record"))
+	if !ok {
+		return
+	}
+
+	ack := &models.Acknowledgement{
+		Host:   hostOf(record.URL),
+		Kind:   r.FormValue("kind"),
+		Target: r.FormValue("target"),
+		Note:   strings.TrimSpace(r.FormValue("note")),
+	}
+	switch ack.Kind {
+	case models.FindingLink, models.FindingReadiness, models.FindingAccessibility:
+	default:
+		h.renderError(w, "Unknown finding kind", http.StatusBadRequest)
+		return
+	}
+	if ack.Target == "" {
+		h.renderError(w, "Missing finding", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.store.Acknowledge(ack); err != nil {
+		slog.Error("failed to save acknowledgement", "host", ack.Host, "error", err)
+		h.renderError(w, "Failed to save acknowledgement", http.StatusInternalServerError)
+		return
+	}
+
+	slog.Info("finding acknowledged", "host", ack.Host, "kind", ack.Kind, "target", ack.Target)
+	h.audit(webActor(r), storage.AuditAcknowledge, ack.Target, fmt.Sprintf("host=%s kind=%s note=%q", ack.Host, ack.Kind, ack.Note))
+	http.Redirect(w, r, "/history/"+record.ID, http.StatusSeeOther)
+}
+
+func (h *Handler) UnacknowledgeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.store == nil {
+		h.renderError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		h.renderError(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	err := h.store.DeleteAcknowledgement(r.PathValue("id"))
+	if errors.Is(err, storage.ErrNotFound) {
+		h.renderError(w, "Acknowledgement not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to delete acknowledgement", "id", r.PathValue("id"), "error", err)
+		h.renderError(w, "Failed to delete acknowledgement", http.StatusInternalServerError)
+		return
+	}
+	h.audit(webActor(r), storage.AuditUnacknowledge, r.PathValue("id"), "")
+
+	redirect := "/history"
+	if id := r.FormValue("record"); id != "" {
+		redirect += "/" + url.PathEscape(id)
+	}
+	http.Redirect(w, r, redirect, http.StatusSeeOther)
+}
+
+// loadStored returns what load reads from the analysis history, redacted:
+// results saved before redaction was configured may still hold secrets
+func loadStored[T any](h *Handler, load func() (T, error)) (T, error) {
+	v, err := load()
+	if err != nil {
+		return v, err
+	}
+	h.analyzer.Redactor().Walk(v)
+	return v, nil
+}
+
+// loadRecord fetches a stored analysis, rendering an error page on failure
+func (h *Handler) loadRecord(w http.ResponseWriter, id string) (*storage.Record, bool) {
+	record, err := loadStored(h, func() (*storage.Record, error) { return h.store.Get(id) })
+	if errors.Is(err, storage.ErrNotFound) {
+		h.renderError(w, "Analysis not found", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		slog.Error("failed to load analysis", "id", id, "error", err)
+		h.renderError(w, "Failed to load analysis", http.StatusInternalServerError)
+		return nil, false
+	}
+	return record, true
+}
+
+// applyAcknowledgements suppresses findings acknowledged for the result's host
+func (h *Handler) applyAcknowledgements(result *models.AnalysisResult) {
+	acks, err := h.store.Acknowledgements(hostOf(result.URL))
+	if err != nil {
+		slog.Error("failed to load acknowledgements", "url", result.URL, "error", err)
+		return
+	}
+	analyzer.ApplyAcknowledgements(result, acks)
+}
+
+// labelsFromForm reads the project and tags fields of an analysis form
+func labelsFromForm(r *http.Request) storage.Labels {
+	return storage.Labels{
+		Project: r.FormValue("project"),
+		Tags:    storage.ParseTags(r.FormValue("tags")),
+	}
+}
+
+// projects lists stored projects for form menus; errors leave the menu empty
+func (h *Handler) projects() []storage.Project {
+	if h.store == nil {
+		return nil
+	}
+	projects, err := h.store.Projects()
+	if err != nil {
+		slog.Error("failed to list projects", "error", err)
+	}
+	return projects
+}
+
+// projectExists reports whether name is a stored project
+func (h *Handler) projectExists(name string) bool {
+	if h.store == nil {
+		return false
+	}
+	_, err := h.store.Project(name)
+	return err == nil
+}
+
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+func (h *Handler) CrawlHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		h.renderError(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	targetURL := r.FormValue("url")
+	opts := analyzer.CrawlOptions{Profile: r.FormValue("profile")}
+
+	if r.FormValue("background") == "true" {
+		if h.jobs == nil {
+			h.renderError(w, "Background jobs are disabled", http.StatusNotFound)
+			return
+		}
+		job, err := h.startCrawl(targetURL, opts, webActor(r), jobs.PriorityInteractive)
+		if err != nil {
+			h.renderError(w, err.Error(), jobErrorStatus(err))
+			return
+		}
+		http.Redirect(w, r, "/jobs/"+job.ID, http.StatusSeeOther)
+		return
+	}
+
+	if r.FormValue("dry_run") == "true" {
+		plan, err := h.analyzer.PlanCrawl(targetURL, opts, h.planOptions())
+		if err != nil {
+			h.renderError(w, err.Error(), analysisErrorStatus(err))
+			return
+		}
+		data := struct {
+			URL  string
+			Plan *models.ScopePlan
+		}{
+			URL:  targetURL,
+			Plan: plan,
+		}
+		if err := h.templates.ExecuteTemplate(w, "plan.html", data); err != nil {
+			slog.Error("template error", "error", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// Crawl
+	start := time.Now()
+	var result *models.CrawlResult
+	err := h.checkDenylist(targetURL)
+	if err == nil {
+		result, err = h.analyzer.Crawl(r.Context(), targetURL, opts)
+	}
+	duration := time.Since(start)
+
+	h.logCrawl(webActor(r), targetURL, duration, result, err)
+
+	if r.Context().Err() != nil {
+		return
+	}
+
+	if err != nil {
+		h.renderError(w, err.Error(), analysisErrorStatus(err))
+		return
+	}
+
+	data := struct {
+		Crawl *models.CrawlResult
+	}{
+		Crawl: result,
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "crawl.html", data); err != nil {
+		slog.Error("template error", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// logCrawl logs and audits a finished crawl
+func (h *Handler) logCrawl(actor, targetURL string, duration time.Duration, result *models.CrawlResult, err error) {
+	slog.Info("crawl completed",
+		"url", targetURL,
+		"duration", duration,
+		"error", err)
+	if err != nil {
+		h.audit(actor, storage.AuditCrawlRun, targetURL, "failed: "+err.Error())
+	} else {
+		h.audit(actor, storage.AuditCrawlRun, targetURL, fmt.Sprintf("pages=%d", len(result.Pages)))
+	}
+}
+
+func (h *Handler) CompareHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		h.renderError(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	targetURL := r.FormValue("url")
+	competitors := strings.Fields(r.FormValue("competitors"))
+
+	// Compare
+	start := time.Now()
+	var report *models.ComparisonReport
+	err := h.checkDenylist(append([]string{targetURL}, competitors...)...)
+	if err == nil {
+		report, err = h.analyzer.Compare(r.Context(), targetURL, competitors)
+	}
+	duration := time.Since(start)
+
+	slog.Info("comparison completed",
 		"url", targetURL,
+		"competitors", len(competitors),
 		"duration", duration,
 		"error", err)
+	if err != nil {
+		h.audit(webActor(r), storage.AuditCompareRun, targetURL, "failed: "+err.Error())
+	} else {
+		h.audit(webActor(r), storage.AuditCompareRun, targetURL, "competitors="+strings.Join(competitors, " "))
+	}
+
+	if r.Context().Err() != nil {
+		return
+	}
 
 	if err != nil {
-		h.renderError(w, err.Error(), http.StatusBadGateway)
+		h.renderError(w, err.Error(), analysisErrorStatus(err))
 		return
 	}
 
-	// Render results
-	h.renderResults(w, result)
+	data := struct {
+		Compare *models.ComparisonReport
+	}{
+		Compare: report,
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "compare.html", data); err != nil {
+		slog.Error("template error", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
 }
 
-func (h *Handler) renderResults(w http.ResponseWriter, result *models.AnalysisResult) {
+func (h *Handler) renderResults(w http.ResponseWriter, result *models.AnalysisResult, record *storage.Record) {
 	data := struct {
-		Result *models.AnalysisResult
+		Result     *models.AnalysisResult
+		Record     *storage.Record
+		PreviousID string
 	}{
 		Result: result,
+		Record: record,
+	}
+	if record != nil {
+		if previous, err := h.store.Previous(record.ID); err == nil {
+			data.PreviousID = previous.ID
+		} else if !errors.Is(err, storage.ErrNotFound) {
+			slog.Error("failed to load previous analysis", "id", record.ID, "error", err)
+		}
 	}
 
 	if err := h.templates.ExecuteTemplate(w, "results.html", data); err != nil {
@@ -89,6 +549,22 @@ func (h *Handler) renderResults(w http.ResponseWriter, result *models.AnalysisRe
 	}
 }
 
+// analysisErrorStatus maps a failed analysis to a response status: the
+// operator's opt-out list, denylist or access lists forbid it, anything
+// else is the target's fault
+func analysisErrorStatus(err error) int {
+	var denied *deniedError
+	switch {
+	case errors.Is(err, analyzer.ErrOptedOut), errors.As(err, &denied), errors.Is(err, validator.ErrOutOfScope):
+		return http.StatusForbidden
+	case errors.Is(err, errDenylistUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, analyzer.ErrInvalidFlags), errors.Is(err, analyzer.ErrInvalidFetcher):
+		return http.StatusBadRequest
+	}
+	return http.StatusBadGateway
+}
+
 func (h *Handler) renderError(w http.ResponseWriter, errMsg string, statusCode int) {
 	data := struct {
 		Error      string
@@ -1,19 +1,43 @@
 package handler
 
 import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"regexp"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+	"website-analyzer/internal/agent"
 	"website-analyzer/internal/analyzer"
+	"website-analyzer/internal/bundle"
+	"website-analyzer/internal/jobs"
+	"website-analyzer/internal/linkrot"
+	"website-analyzer/internal/models"
+	"website-analyzer/internal/portfolio"
+	"website-analyzer/internal/storage"
 )
 
 func TestE2E_FullFlow(t *testing.T) {
-	// 1. Setup mock target server (the site being analyzed)
+	// 1. Setup mock target server (the site being analyzed); regressed adds
+	// a broken link to simulate a bad deployment
+	var regressed atomic.Bool
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone" {
+			http.NotFound(w, r)
+			return
+		}
+		extra := ""
+		if regressed.Load() {
+			extra = `<a href="/gone">Gone</a>`
+		}
 		w.Header().Set("Content-Type", "text/html")
 		_, _ = w.Write([]byte(`
 			<!DOCTYPE html>
@@ -22,7 +46,7 @@ func TestE2E_FullFlow(t *testing.T) {
 			<body>
 				<h1>Welcome</h1>
 				<a href="/about">Internal Link</a>
-				<a href="https://google.com">External Link</a>
+				<a href="https://google.com">External Link</a>` + extra + `
 				<form action="/login" method="POST">
 					<input type="password" name="pwd">
 				</form>
@@ -44,12 +68,19 @@ func TestE2E_FullFlow(t *testing.T) {
 		MaxResponseSize: 1024 * 1024,
 		MaxURLLength:    2048,
 		MaxRedirects:    5,
+		KeepSnapshots:   true,
 	}
 	a := analyzer.NewAnalyzer(analyzerCfg)
 
 	// 4. Setup Handler
 	// Note: Path is relative to the test file location (internal/handler)
-	h, err := NewHandler(a, "../../web/templates")
+	store, err := storage.NewSQLiteStore(t.TempDir() + "/history.db")
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	h, err := NewHandler(a, store, "../../web/templates")
 	if err != nil {
 		t.Fatalf("Failed to create handler: %v", err)
 	}
@@ -74,6 +105,7 @@ func TestE2E_FullFlow(t *testing.T) {
 	t.Run("AnalyzeFlow", func(t *testing.T) {
 		form := url.Values{}
 		form.Add("url", ts.URL)
+		form.Add("keyword", "welcome")
 
 		req := httptest.NewRequest("POST", "/analyze", strings.NewReader(form.Encode()))
 		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -91,6 +123,7 @@ func TestE2E_FullFlow(t *testing.T) {
 			"Internal Links",
 			"External Links",
 			"Yes", // Login Form: Yes
+			"Keyword Audit",
 		}
 
 		for _, snippet := range expectedSnippets {
@@ -100,23 +133,1791 @@ func TestE2E_FullFlow(t *testing.T) {
 		}
 	})
 
-	// 7. Test Error Handling (Invalid URL)
-	t.Run("InvalidURL", func(t *testing.T) {
+	// 7. Test Crawl (POST /crawl)
+	t.Run("CrawlFlow", func(t *testing.T) {
 		form := url.Values{}
-		form.Add("url", "not-a-url")
+		form.Add("url", ts.URL)
 
-		req := httptest.NewRequest("POST", "/analyze", strings.NewReader(form.Encode()))
+		req := httptest.NewRequest("POST", "/crawl", strings.NewReader(form.Encode()))
 		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 		rr := httptest.NewRecorder()
-		h.AnalyzeHandler(rr, req)
+		h.CrawlHandler(rr, req)
 
-		if rr.Code != http.StatusBadGateway {
-			t.Errorf("Expected status Bad Gateway, got %v", rr.Code)
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected status OK, got %v. Body: %s", rr.Code, rr.Body.String())
 		}
 
 		body := rr.Body.String()
-		if !strings.Contains(body, "URL scheme must be http or https") {
-			t.Errorf("Error page missing expected error message. Got: %s", body)
+		for _, snippet := range []string{"Crawl Results", "Site Summary", "E2E Test Site"} {
+			if !strings.Contains(body, snippet) {
+				t.Errorf("Crawl page missing expected snippet: %s", snippet)
+			}
+		}
+	})
+
+	// 8. Test Compare (POST /compare)
+	t.Run("CompareFlow", func(t *testing.T) {
+		form := url.Values{}
+		form.Add("url", ts.URL)
+		form.Add("competitors", ts.URL+"/about\n"+ts.URL+"/other")
+
+		req := httptest.NewRequest("POST", "/compare", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		h.CompareHandler(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected status OK, got %v. Body: %s", rr.Code, rr.Body.String())
+		}
+
+		body := rr.Body.String()
+		for _, snippet := range []string{"Competitor Comparison", "Your page", ts.URL + "/other"} {
+			if !strings.Contains(body, snippet) {
+				t.Errorf("Comparison page missing expected snippet: %s", snippet)
+			}
+		}
+	})
+
+	// 9. Test History (GET /history, GET /history/{id})
+	t.Run("HistoryFlow", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/history", nil)
+		rr := httptest.NewRecorder()
+		h.HistoryHandler(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status OK, got %v", rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "E2E Test Site") {
+			t.Error("History page doesn't list the previous analysis")
+		}
+
+		summaries, err := store.List(storage.Filter{Limit: 1})
+		if err != nil || len(summaries) != 1 {
+			t.Fatalf("Expected a stored analysis, got %v (%v)", summaries, err)
+		}
+
+		req = httptest.NewRequest("GET", "/history/"+summaries[0].ID, nil)
+		req.SetPathValue("id", summaries[0].ID)
+		rr = httptest.NewRecorder()
+		h.HistoryResultHandler(rr, req)
+
+		if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "E2E Test Site") {
+			t.Errorf("Expected stored result page, got %v", rr.Code)
+		}
+
+		req = httptest.NewRequest("GET", "/history/missing", nil)
+		req.SetPathValue("id", "missing")
+		rr = httptest.NewRecorder()
+		h.HistoryResultHandler(rr, req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status Not Found, got %v", rr.Code)
+		}
+	})
+
+	t.Run("AcknowledgeFlow", func(t *testing.T) {
+		summaries, err := store.List(storage.Filter{Limit: 1})
+		if err != nil || len(summaries) != 1 {
+			t.Fatalf("Expected a stored analysis, got %v (%v)", summaries, err)
+		}
+		record, err := store.Get(summaries[0].ID)
+		if err != nil {
+			t.Fatalf("Failed to load analysis: %v", err)
+		}
+		if record.Result.Accessibility == nil || len(record.Result.Accessibility.Issues) == 0 {
+			t.Fatal("Expected the stored analysis to have accessibility issues")
+		}
+		issue := record.Result.Accessibility.Issues[0]
+
+		form := url.Values{}
+		form.Add("record", record.ID)
+		form.Add("kind", "accessibility")
+		form.Add("target", analyzer.AccessibilityTarget(issue))
+		form.Add("note", "tracked in design backlog")
+
+		req := httptest.NewRequest("POST", "/acknowledge", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		h.AcknowledgeHandler(rr, req)
+
+		if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/history/"+record.ID {
+			t.Fatalf("Expected redirect to the analysis, got %v %q", rr.Code, rr.Header().Get("Location"))
+		}
+
+		req = httptest.NewRequest("GET", "/history/"+record.ID, nil)
+		req.SetPathValue("id", record.ID)
+		rr = httptest.NewRecorder()
+		h.HistoryResultHandler(rr, req)
+
+		body := rr.Body.String()
+		if !strings.Contains(body, "Acknowledged (1)") || !strings.Contains(body, "tracked in design backlog") {
+			t.Error("Result page doesn't show the acknowledged finding")
+		}
+
+		acks, err := store.Acknowledgements(strings.TrimPrefix(ts.URL, "http://"))
+		if err != nil || len(acks) != 1 {
+			t.Fatalf("Expected one acknowledgement, got %v (%v)", acks, err)
+		}
+
+		form = url.Values{}
+		form.Add("record", record.ID)
+		req = httptest.NewRequest("POST", "/acknowledge/"+acks[0].ID+"/delete", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetPathValue("id", acks[0].ID)
+		rr = httptest.NewRecorder()
+		h.UnacknowledgeHandler(rr, req)
+
+		if rr.Code != http.StatusSeeOther {
+			t.Errorf("Expected redirect after undo, got %v", rr.Code)
+		}
+		if acks, _ := store.Acknowledgements(strings.TrimPrefix(ts.URL, "http://")); len(acks) != 0 {
+			t.Errorf("Expected acknowledgement to be removed, got %v", acks)
+		}
+
+		// Unknown kinds are rejected
+		form.Set("kind", "bogus")
+		form.Set("target", "x")
+		req = httptest.NewRequest("POST", "/acknowledge", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr = httptest.NewRecorder()
+		h.AcknowledgeHandler(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected status Bad Request, got %v", rr.Code)
+		}
+	})
+
+	t.Run("GateFlow", func(t *testing.T) {
+		post := func(handler http.HandlerFunc, form url.Values) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", "/api", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+			handler(rr, req)
+			return rr
+		}
+
+		rr := post(h.GateHandler, url.Values{"url": {ts.URL}})
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status Not Found without a baseline, got %v", rr.Code)
+		}
+
+		rr = post(h.BaselineHandler, url.Values{"url": {ts.URL}})
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status OK setting baseline, got %v: %s", rr.Code, rr.Body.String())
+		}
+
+		rr = post(h.GateHandler, url.Values{"url": {ts.URL}})
+		if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"pass":true`) {
+			t.Errorf("Expected gate to pass, got %v: %s", rr.Code, rr.Body.String())
+		}
+
+		regressed.Store(true)
+		defer regressed.Store(false)
+
+		rr = post(h.GateHandler, url.Values{"url": {ts.URL}, "tolerance": {"100"}})
+		if rr.Code != http.StatusConflict || !strings.Contains(rr.Body.String(), "/gone") {
+			t.Errorf("Expected gate to fail on the new broken link, got %v: %s", rr.Code, rr.Body.String())
+		}
+
+		rr = post(h.GateHandler, url.Values{"url": {ts.URL}, "tolerance": {"-1"}})
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected status Bad Request for negative tolerance, got %v", rr.Code)
+		}
+	})
+
+	t.Run("ProjectsFlow", func(t *testing.T) {
+		form := url.Values{"name": {"docs"}, "description": {"Documentation"}}
+		req := httptest.NewRequest("POST", "/projects", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		h.ProjectsHandler(rr, req)
+
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("Expected redirect after creating project, got %v", rr.Code)
+		}
+
+		form = url.Values{"name": {"docs"}, "notify_webhook": {"https://hooks.example.com/docs"}, "notify_format": {"xml"}}
+		req = httptest.NewRequest("POST", "/projects", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr = httptest.NewRecorder()
+		h.ProjectsHandler(rr, req)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected an unknown webhook payload format to be rejected, got %v", rr.Code)
+		}
+
+		h.SetAdminToken("s3cret")
+		defer h.SetAdminToken("")
+		req = httptest.NewRequest("POST", "/admin/projects/docs/keys", nil)
+		req.SetPathValue("name", "docs")
+		req.Header.Set("Authorization", "Bearer s3cret")
+		rr = httptest.NewRecorder()
+		h.ProjectKeyHandler(rr, req)
+
+		var issued issuedKey
+		if err := json.Unmarshal(rr.Body.Bytes(), &issued); err != nil || rr.Code != http.StatusCreated {
+			t.Fatalf("Expected the new API key, got %v: %s", rr.Code, rr.Body.String())
+		}
+		secret := issued.Secret
+		if !regexp.MustCompile(`^wa_[0-9a-f]{32}$`).MatchString(secret) || issued.Key.Project != "docs" {
+			t.Fatalf("Unexpected API key %+v", issued)
+		}
+
+		// The key files API analyses under its project
+		form = url.Values{"url": {ts.URL}, "tags": {"Release, ci"}}
+		req = httptest.NewRequest("POST", "/api/baseline", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Authorization", "Bearer "+secret)
+		rr = httptest.NewRecorder()
+		h.BaselineHandler(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status OK, got %v: %s", rr.Code, rr.Body.String())
+		}
+
+		req = httptest.NewRequest("POST", "/api/baseline", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Authorization", "Bearer wa_invalid")
+		rr = httptest.NewRecorder()
+		h.BaselineHandler(rr, req)
+
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status Unauthorized for a bad key, got %v", rr.Code)
+		}
+
+		req = httptest.NewRequest("GET", "/history?project=docs&tag=ci", nil)
+		rr = httptest.NewRecorder()
+		h.HistoryHandler(rr, req)
+
+		summaries, _ := store.List(storage.Filter{Project: "docs", Tag: "release", Limit: 10})
+		if len(summaries) != 1 || !strings.Contains(rr.Body.String(), summaries[0].ID) {
+			t.Errorf("Expected filtered history to list the project analysis, got %v", summaries)
+		}
+
+		// The key can read back what its analyses used
+		req = httptest.NewRequest("GET", "/api/usage", nil)
+		req.Header.Set("Authorization", "Bearer "+secret)
+		rr = httptest.NewRecorder()
+		h.UsageHandler(rr, req)
+
+		var usage usageResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &usage); err != nil || rr.Code != http.StatusOK {
+			t.Fatalf("Expected usage JSON, got %v: %s", rr.Code, rr.Body.String())
+		}
+		if usage.Project != "docs" || usage.Usage.Analyses != 1 || usage.Usage.Requests == 0 {
+			t.Errorf("Unexpected usage: %+v", usage)
+		}
+
+		req = httptest.NewRequest("GET", "/api/usage", nil)
+		rr = httptest.NewRecorder()
+		h.UsageHandler(rr, req)
+
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("Expected usage to require an API key, got %v", rr.Code)
+		}
+
+		form = url.Values{"url": {ts.URL}, "project": {"missing"}}
+		req = httptest.NewRequest("POST", "/analyze", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr = httptest.NewRecorder()
+		h.AnalyzeHandler(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected status Bad Request for an unknown project, got %v", rr.Code)
 		}
 	})
+
+	t.Run("ProjectKeysAdmin", func(t *testing.T) {
+		if err := store.SaveProject(&storage.Project{Name: "keys"}); err != nil {
+			t.Fatal(err)
+		}
+		secret, key, err := store.CreateAPIKey("keys")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		issue := func(token string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", "/admin/projects/keys/keys", nil)
+			req.SetPathValue("name", "keys")
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			rr := httptest.NewRecorder()
+			h.ProjectKeyHandler(rr, req)
+			return rr
+		}
+		revoke := func(token string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", "/admin/projects/keys/keys/"+key.ID+"/revoke", nil)
+			req.SetPathValue("name", "keys")
+			req.SetPathValue("id", key.ID)
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			rr := httptest.NewRecorder()
+			h.RevokeKeyHandler(rr, req)
+			return rr
+		}
+
+		if rr := issue("s3cret"); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 while the admin API is disabled, got %v", rr.Code)
+		}
+		h.SetAdminToken("s3cret")
+		defer h.SetAdminToken("")
+
+		for _, tt := range []struct {
+			name  string
+			token string
+			want  int
+		}{
+			{"no token", "", http.StatusUnauthorized},
+			{"wrong token", "wrong", http.StatusUnauthorized},
+			{"project API key", secret, http.StatusForbidden},
+		} {
+			if rr := issue(tt.token); rr.Code != tt.want {
+				t.Errorf("Issuing with %s: expected %v, got %v", tt.name, tt.want, rr.Code)
+			}
+			if rr := revoke(tt.token); rr.Code != tt.want {
+				t.Errorf("Revoking with %s: expected %v, got %v", tt.name, tt.want, rr.Code)
+			}
+		}
+		if keys, _ := store.APIKeys("keys"); len(keys) != 1 {
+			t.Fatalf("Expected refused requests to leave one key, got %v", keys)
+		}
+
+		if rr := revoke("s3cret"); rr.Code != http.StatusNoContent {
+			t.Fatalf("Expected the key to be revoked, got %v: %s", rr.Code, rr.Body.String())
+		}
+		if _, err := store.LookupAPIKey(secret); err == nil {
+			t.Error("Expected the revoked key to stop working")
+		}
+	})
+
+	t.Run("PortfolioReport", func(t *testing.T) {
+		saveProject := func(form url.Values) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", "/projects", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+			h.ProjectsHandler(rr, req)
+			return rr
+		}
+
+		for _, form := range []url.Values{
+			{"name": {"portfolio"}, "key_urls": {"ftp://example.com/"}},
+			{"name": {"portfolio"}, "key_urls": {ts.URL}, "report_schedule": {"every tuesday"}},
+			{"name": {"portfolio"}, "report_schedule": {"@weekly"}},
+		} {
+			if rr := saveProject(form); rr.Code != http.StatusBadRequest {
+				t.Errorf("Expected %v to be rejected, got %v", form, rr.Code)
+			}
+		}
+
+		rr := saveProject(url.Values{"name": {"portfolio"}, "key_urls": {ts.URL + "\r\n\r\n" + ts.URL + "/never\r\n" + ts.URL}, "report_schedule": {"@weekly"}})
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("Expected redirect after saving key pages, got %v: %s", rr.Code, rr.Body.String())
+		}
+		project, err := store.Project("portfolio")
+		if err != nil || !slices.Equal(project.KeyURLs, []string{ts.URL, ts.URL + "/never"}) || project.ReportNext.IsZero() {
+			t.Fatalf("Expected key pages and a scheduled report, got %+v (%v)", project, err)
+		}
+
+		report := func(format string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("GET", "/projects/portfolio/report?format="+format, nil)
+			req.SetPathValue("name", "portfolio")
+			rr := httptest.NewRecorder()
+			h.ProjectReportHandler(rr, req)
+			return rr
+		}
+
+		rr = report("")
+		if body := rr.Body.String(); rr.Code != http.StatusOK || !strings.Contains(body, "E2E Test Site") || !strings.Contains(body, ts.URL+"/never") {
+			t.Errorf("Expected the report page to list analyzed and unanalyzed pages, got %v: %s", rr.Code, body)
+		}
+
+		var rollup portfolio.Report
+		if rr = report("json"); json.Unmarshal(rr.Body.Bytes(), &rollup) != nil || len(rollup.Pages) != 1 || rollup.Pages[0].Title != "E2E Test Site" {
+			t.Errorf("Expected a JSON report with one analyzed page, got %v: %s", rr.Code, rr.Body.String())
+		}
+		if rr = report("md"); !strings.HasPrefix(rr.Body.String(), "# Portfolio Report: portfolio") || !strings.Contains(rr.Body.String(), "## Not Yet Analyzed") {
+			t.Errorf("Expected a Markdown report, got %s", rr.Body.String())
+		}
+		if rr = report("pdf"); rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected an unknown format to be rejected, got %v", rr.Code)
+		}
+
+		req := httptest.NewRequest("GET", "/projects/missing/report", nil)
+		req.SetPathValue("name", "missing")
+		rr = httptest.NewRecorder()
+		h.ProjectReportHandler(rr, req)
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("Expected an unknown project to be not found, got %v", rr.Code)
+		}
+	})
+
+	t.Run("LinkRot", func(t *testing.T) {
+		linkRot := func(query string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("GET", "/linkrot?"+query, nil)
+			rr := httptest.NewRecorder()
+			h.LinkRotHandler(rr, req)
+			return rr
+		}
+
+		for query, want := range map[string]int{
+			"":                             http.StatusBadRequest,
+			"url=a&project=b":              http.StatusBadRequest,
+			"project=missing":              http.StatusNotFound,
+			"project=portfolio&months=0":   http.StatusBadRequest,
+			"project=portfolio&format=csv": http.StatusBadRequest,
+			"url=" + url.QueryEscape(ts.URL) + "&months=abc": http.StatusBadRequest,
+		} {
+			if rr := linkRot(query); rr.Code != want {
+				t.Errorf("Expected %v for %q, got %v", want, query, rr.Code)
+			}
+		}
+
+		rr := linkRot("url=" + url.QueryEscape(ts.URL) + "&months=6&format=json")
+		var report linkrot.Report
+		if err := json.Unmarshal(rr.Body.Bytes(), &report); err != nil || rr.Code != http.StatusOK {
+			t.Fatalf("Expected a JSON report, got %v: %s", rr.Code, rr.Body.String())
+		}
+		if report.URL != ts.URL || report.Months != 6 || len(report.Trend) != 7 {
+			t.Errorf("Unexpected report %+v", report)
+		}
+		if rr := linkRot("project=portfolio"); rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "Link Rot") {
+			t.Errorf("Expected the report page, got %v: %s", rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("QuotaFlow", func(t *testing.T) {
+		if err := store.SaveProject(&storage.Project{Name: "quota"}); err != nil {
+			t.Fatalf("SaveProject failed: %v", err)
+		}
+		secret, _, err := store.CreateAPIKey("quota")
+		if err != nil {
+			t.Fatalf("CreateAPIKey failed: %v", err)
+		}
+		h.SetQuotas(Quotas{Daily: storage.Quota{Analyses: 1}, Monthly: storage.Quota{Analyses: 10}})
+		defer h.SetQuotas(Quotas{})
+
+		baseline := func() *httptest.ResponseRecorder {
+			form := url.Values{"url": {ts.URL}}
+			req := httptest.NewRequest("POST", "/api/baseline", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set("Authorization", "Bearer "+secret)
+			rr := httptest.NewRecorder()
+			h.BaselineHandler(rr, req)
+			return rr
+		}
+
+		rr := baseline()
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected the first analysis to be allowed, got %v: %s", rr.Code, rr.Body.String())
+		}
+		if rr.Header().Get("X-RateLimit-Limit") != "1" || rr.Header().Get("X-RateLimit-Remaining") != "1" || rr.Header().Get("X-RateLimit-Reset") == "" {
+			t.Errorf("Expected the daily window in the rate limit headers, got %v", rr.Header())
+		}
+
+		rr = baseline()
+		if rr.Code != http.StatusTooManyRequests || rr.Header().Get("Retry-After") == "" {
+			t.Fatalf("Expected 429 with Retry-After once the quota is used, got %v %v", rr.Code, rr.Header())
+		}
+		if rr.Header().Get("X-RateLimit-Remaining") != "0" {
+			t.Errorf("Expected no analyses remaining, got %q", rr.Header().Get("X-RateLimit-Remaining"))
+		}
+
+		req := httptest.NewRequest("GET", "/api/quota", nil)
+		req.Header.Set("Authorization", "Bearer "+secret)
+		rr = httptest.NewRecorder()
+		h.QuotaHandler(rr, req)
+
+		var quota struct {
+			Windows []quotaWindow `json:"windows"`
+		}
+		if err := json.Unmarshal(rr.Body.Bytes(), &quota); err != nil || rr.Code != http.StatusOK {
+			t.Fatalf("Expected quota JSON, got %v: %s", rr.Code, rr.Body.String())
+		}
+		if len(quota.Windows) != 2 || quota.Windows[0].Quotas["analyses"].Remaining != 0 || quota.Windows[1].Quotas["analyses"].Remaining != 9 {
+			t.Errorf("Unexpected quota windows: %+v", quota.Windows)
+		}
+
+		// Leaving the key out doesn't escape the quota
+		form := url.Values{"url": {ts.URL}}
+		req = httptest.NewRequest("POST", "/api/baseline", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr = httptest.NewRecorder()
+		h.BaselineHandler(rr, req)
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 without a key while quotas are enforced, got %v", rr.Code)
+		}
+
+		// A batch counts every URL against the allowance left
+		h.SetQuotas(Quotas{Daily: storage.Quota{Analyses: 3}})
+		batch := func(urls ...string) *httptest.ResponseRecorder {
+			form := url.Values{"url": urls}
+			req := httptest.NewRequest("POST", "/api/v1/analyze/batch", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set("Authorization", "Bearer "+secret)
+			rr := httptest.NewRecorder()
+			h.BatchAnalyzeHandler(rr, req)
+			return rr
+		}
+		rr = batch(ts.URL, ts.URL+"/a", ts.URL+"/b")
+		if rr.Code != http.StatusTooManyRequests || !strings.Contains(rr.Body.String(), "batch of 3 URLs") {
+			t.Fatalf("Expected a batch larger than the allowance left to be refused, got %v: %s", rr.Code, rr.Body.String())
+		}
+		if rr.Header().Get("X-RateLimit-Remaining") != "2" {
+			t.Errorf("Expected two analyses left, got %q", rr.Header().Get("X-RateLimit-Remaining"))
+		}
+		rr = batch(ts.URL, ts.URL+"/a")
+		var response batchResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil || rr.Code != http.StatusOK || response.Succeeded != 2 {
+			t.Errorf("Expected a batch within the allowance to run, got %v: %s", rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("BatchFlow", func(t *testing.T) {
+		batch := func(form url.Values) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", "/api/v1/analyze/batch", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+			h.BatchAnalyzeHandler(rr, req)
+			return rr
+		}
+
+		if rr := batch(url.Values{}); rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected an empty batch to be rejected, got %v", rr.Code)
+		}
+
+		h.SetBatchLimit(2)
+		if rr := batch(url.Values{"urls": {ts.URL + "\n" + ts.URL + "/a\n" + ts.URL + "/b"}}); rr.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected an oversized batch to be rejected, got %v", rr.Code)
+		}
+		h.SetBatchLimit(0)
+
+		rr := batch(url.Values{"url": {ts.URL, "not-a-url"}, "urls": {"\n" + ts.URL + "/about\n"}, "tags": {"batch"}})
+		var response batchResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil || rr.Code != http.StatusOK {
+			t.Fatalf("Expected batch JSON, got %v: %s", rr.Code, rr.Body.String())
+		}
+		if len(response.Results) != 3 || response.Succeeded != 2 || response.Failed != 1 {
+			t.Fatalf("Expected two successes and one failure, got %+v", response)
+		}
+		if item := response.Results[1]; item.URL != "not-a-url" || item.Error == "" || item.Result != nil {
+			t.Errorf("Expected the invalid URL to fail in place, got %+v", item)
+		}
+		for _, i := range []int{0, 2} {
+			item := response.Results[i]
+			if item.ResultID == "" || item.Result == nil {
+				t.Errorf("Expected result %d to be stored, got %+v", i, item)
+				continue
+			}
+			if record, err := store.Get(item.ResultID); err != nil || !slices.Contains(record.Tags, "batch") {
+				t.Errorf("Expected result %d to be stored with its tags, got %+v (%v)", i, record, err)
+			}
+			if len(item.Result.LinkDetails) != 0 {
+				t.Errorf("Expected no link details without include_links, got %+v", item.Result.LinkDetails)
+			}
+		}
+
+		rr = batch(url.Values{"url": {ts.URL}, "include_links": {"true"}})
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil || len(response.Results) != 1 || response.Results[0].Result == nil {
+			t.Fatalf("Expected a batch result with links, got %v: %s", rr.Code, rr.Body.String())
+		}
+		details := response.Results[0].Result.LinkDetails
+		if len(details) != 2 || details[0].Text != "Internal Link" || !details[0].Checked {
+			t.Errorf("Expected checked link details with anchor text, got %+v", details)
+		}
+	})
+
+	t.Run("DryRun", func(t *testing.T) {
+		var hits atomic.Int64
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+		}))
+		defer target.Close()
+
+		form := url.Values{"urls": {ts.URL + "\n" + target.URL}, "dry_run": {"true"}}
+		req := httptest.NewRequest("POST", "/api/v1/analyze/batch", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		h.BatchAnalyzeHandler(rr, req)
+
+		var plan models.ScopePlan
+		if err := json.Unmarshal(rr.Body.Bytes(), &plan); err != nil || rr.Code != http.StatusOK {
+			t.Fatalf("Expected a plan, got %v: %s", rr.Code, rr.Body.String())
+		}
+		if len(plan.Pages) != 2 || plan.FetchPages+countCached(plan) != 2 || plan.Concurrency != batchWorkers {
+			t.Errorf("Unexpected plan %+v", plan)
+		}
+		if !plan.Pages[0].FromHistory {
+			t.Errorf("Expected the analyzed page to be estimated from history, got %+v", plan.Pages[0])
+		}
+
+		form = url.Values{"url": {target.URL}, "dry_run": {"true"}}
+		req = httptest.NewRequest("POST", "/crawl", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr = httptest.NewRecorder()
+		h.CrawlHandler(rr, req)
+		if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "Crawl Dry Run") {
+			t.Errorf("Expected the crawl plan page, got %v", rr.Code)
+		}
+
+		if hits.Load() != 0 {
+			t.Errorf("Expected dry runs to make no requests, target saw %d", hits.Load())
+		}
+	})
+
+	t.Run("Jobs", func(t *testing.T) {
+		h.SetJobs(jobs.NewManager(context.Background(), 2))
+		defer h.SetJobs(nil)
+
+		start := func(form url.Values) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", "/api/v1/jobs", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+			h.JobsHandler(rr, req)
+			return rr
+		}
+		get := func(handler http.HandlerFunc, path, id string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("GET", path, nil)
+			req.SetPathValue("id", id)
+			rr := httptest.NewRecorder()
+			handler(rr, req)
+			return rr
+		}
+		wait := func(id string) models.Job {
+			t.Helper()
+			deadline := time.Now().Add(10 * time.Second)
+			for time.Now().Before(deadline) {
+				var job models.Job
+				rr := get(h.JobHandler, "/api/v1/jobs/"+id, id)
+				if err := json.Unmarshal(rr.Body.Bytes(), &job); err != nil || rr.Code != http.StatusOK {
+					t.Fatalf("Expected the job, got %v: %s", rr.Code, rr.Body.String())
+				}
+				if job.State == jobs.StateCompleted || job.State == jobs.StateFailed {
+					return job
+				}
+				time.Sleep(20 * time.Millisecond)
+			}
+			t.Fatalf("Job %s did not finish", id)
+			return models.Job{}
+		}
+
+		if rr := start(url.Values{"kind": {"bogus"}}); rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected an unknown kind to be rejected, got %v", rr.Code)
+		}
+
+		rr := start(url.Values{"kind": {"crawl"}, "url": {ts.URL}})
+		var job models.Job
+		if err := json.Unmarshal(rr.Body.Bytes(), &job); err != nil || rr.Code != http.StatusAccepted {
+			t.Fatalf("Expected a started job, got %v: %s", rr.Code, rr.Body.String())
+		}
+		if rr.Header().Get("Location") != "/api/v1/jobs/"+job.ID || job.Kind != "crawl" || job.Priority != "api" {
+			t.Errorf("Unexpected job %+v at %q", job, rr.Header().Get("Location"))
+		}
+
+		job = wait(job.ID)
+		if job.State != jobs.StateCompleted || job.Result == nil || job.Progress.Percent != 100 || job.Progress.PagesDone == 0 || job.Progress.PagesDone != job.Progress.PagesTotal {
+			t.Errorf("Expected a completed crawl, got %+v", job)
+		}
+
+		rr = get(h.JobEventsHandler, "/api/v1/jobs/"+job.ID+"/events", job.ID)
+		if rr.Header().Get("Content-Type") != "text/event-stream" || !strings.Contains(rr.Body.String(), "event: completed\ndata: ") {
+			t.Errorf("Expected a completed event, got %q", rr.Body.String())
+		}
+		if strings.Contains(rr.Body.String(), `"result"`) {
+			t.Error("Expected events to leave out the result")
+		}
+
+		if rr := get(h.JobPageHandler, "/jobs/"+job.ID, job.ID); rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "Crawl Results") {
+			t.Errorf("Expected the finished crawl's results page, got %v", rr.Code)
+		}
+
+		rr = start(url.Values{"kind": {"batch"}, "urls": {ts.URL + "\nnot-a-url"}})
+		if err := json.Unmarshal(rr.Body.Bytes(), &job); err != nil || rr.Code != http.StatusAccepted {
+			t.Fatalf("Expected a started batch, got %v: %s", rr.Code, rr.Body.String())
+		}
+		job = wait(job.ID)
+		result, _ := job.Result.(map[string]any)
+		if job.State != jobs.StateCompleted || result["succeeded"] != 1.0 || result["failed"] != 1.0 || job.Progress.PagesDone != 2 {
+			t.Errorf("Expected a completed batch, got %+v", job)
+		}
+		if rr := get(h.JobPageHandler, "/jobs/"+job.ID, job.ID); rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "/api/v1/jobs/"+job.ID) {
+			t.Errorf("Expected the batch's progress page, got %v", rr.Code)
+		}
+
+		form := url.Values{"url": {ts.URL}, "background": {"true"}}
+		req := httptest.NewRequest("POST", "/crawl", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr = httptest.NewRecorder()
+		h.CrawlHandler(rr, req)
+		if location := rr.Header().Get("Location"); rr.Code != http.StatusSeeOther || !strings.HasPrefix(location, "/jobs/") {
+			t.Fatalf("Expected a redirect to the job, got %v to %q", rr.Code, location)
+		} else {
+			wait(strings.TrimPrefix(location, "/jobs/"))
+		}
+
+		if rr := get(h.JobHandler, "/api/v1/jobs/missing", "missing"); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected an unknown job to be 404, got %v", rr.Code)
+		}
+		if rr := get(h.JobEventsHandler, "/api/v1/jobs/missing/events", "missing"); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected an unknown job's events to be 404, got %v", rr.Code)
+		}
+	})
+
+	t.Run("CircuitBreaker", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/admin/circuit-breaker", nil)
+		rr := httptest.NewRecorder()
+		h.CircuitBreakerHandler(rr, req)
+
+		var states []models.CircuitState
+		if err := json.Unmarshal(rr.Body.Bytes(), &states); err != nil || rr.Code != http.StatusOK {
+			t.Fatalf("Expected circuit breaker JSON, got %v: %s", rr.Code, rr.Body.String())
+		}
+		if states == nil {
+			t.Error("Expected an empty list rather than null")
+		}
+	})
+
+	t.Run("Metrics", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/metrics", nil)
+		rr := httptest.NewRecorder()
+		h.MetricsHandler(rr, req)
+
+		body := rr.Body.String()
+		if rr.Code != http.StatusOK || !strings.HasPrefix(rr.Header().Get("Content-Type"), "text/plain") {
+			t.Fatalf("Expected text metrics, got %v %q", rr.Code, rr.Header().Get("Content-Type"))
+		}
+		for _, want := range []string{"# TYPE go_goroutines gauge", "website_analyzer_uptime_seconds ", `website_analyzer_circuit_breaker_domains{state="open"} `} {
+			if !strings.Contains(body, want) {
+				t.Errorf("Metrics missing %q:\n%s", want, body)
+			}
+		}
+	})
+
+	t.Run("BotInfo", func(t *testing.T) {
+		req := httptest.NewRequest("GET", BotInfoPath, nil)
+		rr := httptest.NewRecorder()
+		h.BotInfoHandler(rr, req)
+
+		body := rr.Body.String()
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status OK, got %v", rr.Code)
+		}
+		if !strings.Contains(body, analyzer.UserAgent("")) || !strings.Contains(body, "Opting Out") {
+			t.Errorf("Bot page should describe the User-Agent and opt-out:\n%s", body)
+		}
+	})
+
+	t.Run("Version", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		h.VersionHandler(rr, httptest.NewRequest("GET", "/version", nil))
+
+		var info models.AnalyzerInfo
+		if err := json.Unmarshal(rr.Body.Bytes(), &info); err != nil || rr.Code != http.StatusOK || info.Version != analyzer.Version {
+			t.Errorf("Expected the analyzer version, got %v: %s", rr.Code, rr.Body.String())
+		}
+
+		rr = httptest.NewRecorder()
+		h.VersionHandler(rr, httptest.NewRequest("POST", "/version", nil))
+		if rr.Code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected POST to be rejected, got %v", rr.Code)
+		}
+	})
+
+	t.Run("AuditFlow", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/admin/audit", nil)
+		rr := httptest.NewRecorder()
+		h.AuditHandler(rr, req)
+
+		body := rr.Body.String()
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status OK, got %v", rr.Code)
+		}
+		for _, want := range []string{"analysis.run", "finding.acknowledge", "apikey.issue", "api-key:wa_", "web:192.0.2.1"} {
+			if !strings.Contains(body, want) {
+				t.Errorf("Audit page missing %q", want)
+			}
+		}
+
+		req = httptest.NewRequest("GET", "/admin/audit/export?action=baseline.set", nil)
+		rr = httptest.NewRecorder()
+		h.AuditExportHandler(rr, req)
+
+		rows, err := csv.NewReader(rr.Body).ReadAll()
+		if err != nil {
+			t.Fatalf("Failed to parse CSV export: %v", err)
+		}
+		if len(rows) < 2 || rows[0][0] != "id" {
+			t.Fatalf("Expected header and entries, got %v", rows)
+		}
+		for _, row := range rows[1:] {
+			if row[3] != "baseline.set" {
+				t.Errorf("Expected only baseline.set entries, got %v", row)
+			}
+		}
+
+		req = httptest.NewRequest("GET", "/admin/audit/export?format=json", nil)
+		rr = httptest.NewRecorder()
+		h.AuditExportHandler(rr, req)
+
+		var entries []storage.AuditEntry
+		if err := json.NewDecoder(rr.Body).Decode(&entries); err != nil || len(entries) == 0 {
+			t.Errorf("Expected JSON export, got %v (%v)", entries, err)
+		}
+	})
+
+	// 10. Test Error Handling (Invalid URL)
+	t.Run("InvalidURL", func(t *testing.T) {
+		form := url.Values{}
+		form.Add("url", "not-a-url")
+
+		req := httptest.NewRequest("POST", "/analyze", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		h.AnalyzeHandler(rr, req)
+
+		if rr.Code != http.StatusBadGateway {
+			t.Errorf("Expected status Bad Gateway, got %v", rr.Code)
+		}
+
+		body := rr.Body.String()
+		if !strings.Contains(body, "URL scheme must be http or https") {
+			t.Errorf("Error page missing expected error message. Got: %s", body)
+		}
+
+		form.Set("url", "https://example.com/")
+		form.Set("flags", "telepathy")
+		req = httptest.NewRequest("POST", "/analyze", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr = httptest.NewRecorder()
+		h.AnalyzeHandler(rr, req)
+		if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "telepathy") {
+			t.Errorf("Expected an unknown feature flag to be rejected, got %v", rr.Code)
+		}
+
+		form.Del("flags")
+		form.Set("fetcher", "carrier-pigeon")
+		req = httptest.NewRequest("POST", "/analyze", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr = httptest.NewRecorder()
+		h.AnalyzeHandler(rr, req)
+		if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "carrier-pigeon") {
+			t.Errorf("Expected an unknown fetcher to be rejected, got %v", rr.Code)
+		}
+	})
+
+	t.Run("Export", func(t *testing.T) {
+		var id string
+		for _, summary := range mustList(t, store) {
+			if record, err := store.Get(summary.ID); err == nil && len(record.Result.InaccessibleLinks) > 0 {
+				id = record.ID
+				break
+			}
+		}
+		if id == "" {
+			t.Fatal("Expected a stored analysis with broken links")
+		}
+		export := func(id, format string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("GET", "/api/v1/analyses/"+id+"/export?format="+format, nil)
+			req.SetPathValue("id", id)
+			rr := httptest.NewRecorder()
+			h.ExportHandler(rr, req)
+			return rr
+		}
+
+		rr := export(id, "csv")
+		rows, err := csv.NewReader(rr.Body).ReadAll()
+		if err != nil || rr.Code != http.StatusOK || len(rows) < 2 || rows[0][0] != "url" || !strings.HasSuffix(rows[1][0], "/gone") {
+			t.Errorf("Expected broken links as CSV rows, got %v %v: %v", rr.Code, err, rows)
+		}
+		if !strings.Contains(rr.Header().Get("Content-Disposition"), "analysis-"+id+".csv") {
+			t.Errorf("Expected a download, got %q", rr.Header().Get("Content-Disposition"))
+		}
+
+		rr = export(id, "json")
+		var record storage.Record
+		if err := json.Unmarshal(rr.Body.Bytes(), &record); err != nil || record.ID != id || record.Result == nil {
+			t.Errorf("Expected the stored record as JSON, got %v: %s", rr.Code, rr.Body.String())
+		}
+
+		rr = export(id, "md")
+		body := rr.Body.String()
+		if rr.Code != http.StatusOK || !strings.HasPrefix(body, "# ") || !strings.Contains(body, "## Broken Links") || !strings.Contains(body, "/gone") {
+			t.Errorf("Expected a Markdown report, got %v:\n%s", rr.Code, body)
+		}
+		if !strings.Contains(body, "\n---\n\nAnalyzer "+analyzer.Version) {
+			t.Errorf("Expected the analyzer version in the report footer:\n%s", body)
+		}
+
+		rr = export(id, "warc")
+		if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != "application/warc" {
+			t.Fatalf("Expected a WARC file, got %v: %s", rr.Code, rr.Body.String())
+		}
+		warc, err := analyzer.ParseWARC(rr.Body)
+		if err != nil {
+			t.Fatalf("Expected a readable WARC file: %v", err)
+		}
+		if page, err := warc.Fetch(context.Background(), record.URL); err != nil || !strings.Contains(string(page.Body), "E2E Test Site") {
+			t.Errorf("Expected the archived page, got %v", err)
+		}
+		plain, err := store.Save("https://plain.example/", &models.AnalysisResult{URL: "https://plain.example/"}, storage.Labels{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rr := export(plain.ID, "warc"); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 without a snapshot, got %v", rr.Code)
+		}
+
+		if rr := export(id, "pdf"); rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected an unknown format to be rejected, got %v", rr.Code)
+		}
+		if rr := export("missing", "csv"); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected a missing analysis to 404, got %v", rr.Code)
+		}
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		summaries := mustList(t, store)
+		if len(summaries) == 0 {
+			t.Fatal("Expected stored analyses")
+		}
+		id := summaries[len(summaries)-1].ID
+
+		replay := func(id, form string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", "/api/v1/analyses/"+id+"/replay", strings.NewReader(form))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.SetPathValue("id", id)
+			rr := httptest.NewRecorder()
+			h.ReplayHandler(rr, req)
+			return rr
+		}
+
+		rr := replay(id, "")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status OK, got %v: %s", rr.Code, rr.Body.String())
+		}
+		var result models.AnalysisResult
+		if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+			t.Fatal(err)
+		}
+		if result.ReplayOf != id || result.Title != "E2E Test Site" || !result.HasLoginForm {
+			t.Errorf("Unexpected replay: %+v", result)
+		}
+
+		before := len(mustList(t, store))
+		rr = replay(id, "store=true")
+		var record storage.Record
+		if err := json.NewDecoder(rr.Body).Decode(&record); err != nil || record.ID == "" || record.ID == id {
+			t.Fatalf("Expected the replay to be saved as a new analysis, got %v: %v", rr.Code, err)
+		}
+		if len(mustList(t, store)) != before+1 {
+			t.Error("Expected one more stored analysis")
+		}
+		if _, err := store.Snapshot(record.ID); err != nil {
+			t.Errorf("Expected the saved replay to keep the snapshot: %v", err)
+		}
+
+		if rr := replay("missing", ""); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 for an unknown analysis, got %v", rr.Code)
+		}
+	})
+
+	t.Run("Rescore", func(t *testing.T) {
+		rescore := func(token string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", "/admin/rescore", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+			h.RescoreHandler(rr, req)
+			return rr
+		}
+		if rr := rescore("s3cret"); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 while the admin API is disabled, got %v", rr.Code)
+		}
+		h.SetAdminToken("s3cret")
+		defer h.SetAdminToken("")
+		if rr := rescore("wrong"); rr.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 for a wrong token, got %v", rr.Code)
+		}
+
+		ids, err := store.SnapshotIDs()
+		if err != nil || len(ids) == 0 {
+			t.Fatalf("Expected stored snapshots, got %v: %v", ids, err)
+		}
+		original, err := store.Get(ids[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := rescore("s3cret")
+		var summary struct {
+			Snapshots, Rescored, Failed int
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&summary); err != nil || rr.Code != http.StatusOK {
+			t.Fatalf("Expected a rescore summary, got %v: %v", rr.Code, err)
+		}
+		if summary.Snapshots != len(ids) || summary.Rescored != len(ids) || summary.Failed != 0 {
+			t.Errorf("Unexpected summary %+v for %d snapshots", summary, len(ids))
+		}
+
+		record, err := store.Get(ids[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if record.Rescored == nil || record.Rescored.ReplayOf != ids[0] || record.RescoredAt.IsZero() {
+			t.Errorf("Expected the analysis to be rescored, got %+v", record.Rescored)
+		}
+		if record.Result.Title != original.Result.Title || record.Result.Scores == nil ||
+			*record.Result.Scores != *original.Result.Scores {
+			t.Error("Expected the original findings to be kept")
+		}
+
+		req := httptest.NewRequest("GET", "/history/"+ids[0], nil)
+		req.SetPathValue("id", ids[0])
+		rr = httptest.NewRecorder()
+		h.HistoryResultHandler(rr, req)
+		if !strings.Contains(rr.Body.String(), "Re-scored") {
+			t.Error("Expected the history page to compare original and rescored scores")
+		}
+	})
+
+	t.Run("Agents", func(t *testing.T) {
+		register := func(token, body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", "/api/v1/agents", strings.NewReader(body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+			h.RegisterAgentHandler(rr, req)
+			return rr
+		}
+		const registration = `{"region": "eu-west", "url": "http://agent.example:8080"}`
+		if rr := register("agent-secret", registration); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 while agents are disabled, got %v", rr.Code)
+		}
+		h.SetAgents(agent.NewRegistry("agent-secret", time.Second))
+		defer h.SetAgents(nil)
+
+		if rr := register("wrong", registration); rr.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 for a wrong token, got %v", rr.Code)
+		}
+		if rr := register("agent-secret", `{"region": "eu-west"}`); rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for a registration without a URL, got %v", rr.Code)
+		}
+		if rr := register("agent-secret", registration); rr.Code != http.StatusNoContent {
+			t.Fatalf("Expected the agent to be registered, got %v: %s", rr.Code, rr.Body.String())
+		}
+
+		list := func(token string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("GET", "/admin/agents", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+			h.AgentsHandler(rr, req)
+			return rr
+		}
+		h.SetAdminToken("s3cret")
+		defer h.SetAdminToken("")
+		if rr := list("agent-secret"); rr.Code != http.StatusUnauthorized {
+			t.Errorf("Expected the agent token not to list agents, got %v", rr.Code)
+		}
+		var agents []agent.Agent
+		rr := list("s3cret")
+		if err := json.Unmarshal(rr.Body.Bytes(), &agents); err != nil || len(agents) != 1 || agents[0].Region != "eu-west" {
+			t.Errorf("Expected the registered agent, got %v: %s", rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("Diff", func(t *testing.T) {
+		const page = "https://release.example/"
+		before, err := store.Save(page, &models.AnalysisResult{URL: page, Title: "Before", Links: []string{page + "old"}, InternalLinks: 1}, storage.Labels{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		after, err := store.Save(page, &models.AnalysisResult{URL: page, Title: "After", Links: []string{page + "new"}, InternalLinks: 1}, storage.Labels{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		diff := func(handler http.HandlerFunc, from, to string) *httptest.ResponseRecorder {
+			query := url.Values{"from": {from}, "to": {to}}
+			rr := httptest.NewRecorder()
+			handler(rr, httptest.NewRequest("GET", "/api/v1/diff?"+query.Encode(), nil))
+			return rr
+		}
+
+		rr := diff(h.DiffHandler, before.ID, after.ID)
+		var got models.AnalysisDiff
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil || rr.Code != http.StatusOK {
+			t.Fatalf("Expected a diff, got %v: %s", rr.Code, rr.Body.String())
+		}
+		if got.From.ID != before.ID || got.To.ID != after.ID || len(got.LinksAdded) != 1 || len(got.LinksRemoved) != 1 ||
+			len(got.Fields) != 1 || got.Fields[0].Field != "title" {
+			t.Errorf("Unexpected diff %s", rr.Body.String())
+		}
+
+		// A URL stands for its latest stored analysis
+		rr = diff(h.DiffHandler, before.ID, page)
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil || got.To.ID != after.ID {
+			t.Errorf("Expected the latest analysis of the URL, got %v: %s", rr.Code, rr.Body.String())
+		}
+
+		if rr := diff(h.DiffHandler, before.ID, ""); rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected a missing side to be rejected, got %v", rr.Code)
+		}
+		if rr := diff(h.DiffHandler, before.ID, "https://unknown.example/"); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected an unanalyzed URL to 404, got %v", rr.Code)
+		}
+
+		rr = diff(h.DiffPageHandler, before.ID, after.ID)
+		if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), page+"new") {
+			t.Errorf("Expected the diff page, got %v", rr.Code)
+		}
+
+		req := httptest.NewRequest("GET", "/history/"+after.ID, nil)
+		req.SetPathValue("id", after.ID)
+		rr = httptest.NewRecorder()
+		h.HistoryResultHandler(rr, req)
+		if !strings.Contains(rr.Body.String(), "/diff?from="+before.ID+"&amp;to="+after.ID) {
+			t.Errorf("Expected a link to the changes since the previous run")
+		}
+	})
+
+	t.Run("SavedHooks", func(t *testing.T) {
+		saved := make(chan *storage.Record, 1)
+		h.AddSavedHook("test", func(ctx context.Context, record *storage.Record) error {
+			select {
+			case saved <- record:
+			default:
+			}
+			return nil
+		})
+		scheduled := make(chan *storage.Record, 1)
+		h.AddScheduledHook("test", func(ctx context.Context, record *storage.Record) error {
+			select {
+			case scheduled <- record:
+			default:
+			}
+			return nil
+		})
+
+		req := httptest.NewRequest("POST", "/analyze", strings.NewReader(url.Values{"url": {ts.URL}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		h.AnalyzeHandler(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected the analysis to succeed, got %v", rr.Code)
+		}
+
+		select {
+		case record := <-saved:
+			if record.URL != ts.URL || record.Result == nil {
+				t.Errorf("Expected the stored analysis, got %+v", record)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected the hook to run after the analysis was stored")
+		}
+
+		// Scheduled hooks only see monitor runs
+		select {
+		case record := <-scheduled:
+			t.Errorf("Expected the scheduled hook to skip an analysis, got %s", record.ID)
+		case <-time.After(100 * time.Millisecond):
+		}
+		summaries, err := store.List(storage.Filter{Limit: 1})
+		if err != nil || len(summaries) == 0 {
+			t.Fatalf("Expected a stored analysis, got %v: %v", summaries, err)
+		}
+		h.RunScheduledHooks(summaries[0].ID)
+		for name, hook := range map[string]chan *storage.Record{"saved": saved, "scheduled": scheduled} {
+			select {
+			case record := <-hook:
+				if record.ID != summaries[0].ID {
+					t.Errorf("Expected the %s hook to get the run, got %s", name, record.ID)
+				}
+			case <-time.After(5 * time.Second):
+				t.Errorf("Expected the %s hook to run after a monitor run", name)
+			}
+		}
+	})
+
+	t.Run("Monitors", func(t *testing.T) {
+		post := func(target string, form url.Values) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", target, strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+			h.MonitorsHandler(rr, req)
+			return rr
+		}
+
+		for _, form := range []url.Values{
+			{"url": {"ftp://example.com"}, "schedule": {"@daily"}},
+			{"url": {ts.URL}, "schedule": {"every day"}},
+			{"url": {ts.URL}, "schedule": {"@daily"}, "profile": {"missing"}},
+			{"url": {ts.URL}, "schedule": {"@daily"}, "project": {"missing"}},
+			{"url": {ts.URL}, "schedule": {"@daily"}, "webhook": {"hooks.example.com"}},
+		} {
+			if rr := post("/monitors", form); rr.Code != http.StatusBadRequest {
+				t.Errorf("Expected %v to be rejected, got %v", form, rr.Code)
+			}
+		}
+
+		rr := post("/monitors", url.Values{"url": {ts.URL}, "schedule": {"*/30 * * * *"}, "webhook": {"https://hooks.example.com/incident"}})
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("Expected a redirect, got %v: %s", rr.Code, rr.Body.String())
+		}
+		monitors, err := store.Monitors()
+		if err != nil || len(monitors) != 1 || monitors[0].URL != ts.URL || monitors[0].Webhook == "" || monitors[0].NextRun.IsZero() {
+			t.Fatalf("Expected the monitor to be scheduled, got %+v (%v)", monitors, err)
+		}
+
+		rr = httptest.NewRecorder()
+		h.MonitorsHandler(rr, httptest.NewRequest("GET", "/monitors", nil))
+		if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "*/30 * * * *") {
+			t.Errorf("Expected the monitor to be listed, got %v", rr.Code)
+		}
+
+		pause := func(until string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", "/monitors/"+monitors[0].ID+"/pause", strings.NewReader(url.Values{"until": {until}}.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.SetPathValue("id", monitors[0].ID)
+			rr := httptest.NewRecorder()
+			h.PauseMonitorHandler(rr, req)
+			return rr
+		}
+		for _, until := range []string{"tomorrow", "2001-01-01T00:00"} {
+			if rr := pause(until); rr.Code != http.StatusBadRequest {
+				t.Errorf("Expected resume time %q to be rejected, got %v", until, rr.Code)
+			}
+		}
+		resume := time.Now().UTC().Add(48 * time.Hour).Truncate(time.Minute)
+		if rr := pause(resume.Format("2006-01-02T15:04")); rr.Code != http.StatusSeeOther {
+			t.Fatalf("Expected a redirect, got %v: %s", rr.Code, rr.Body.String())
+		}
+		if m, _ := store.Monitor(monitors[0].ID); !m.Paused || !m.PausedUntil.Equal(resume) {
+			t.Errorf("Expected the monitor to be paused until %v, got %+v", resume, m)
+		}
+		rr = httptest.NewRecorder()
+		h.MonitorsHandler(rr, httptest.NewRequest("GET", "/monitors", nil))
+		if !strings.Contains(rr.Body.String(), "Paused</strong> until") || !strings.Contains(rr.Body.String(), "/resume") {
+			t.Error("Expected the monitor to be listed as paused")
+		}
+		req := httptest.NewRequest("POST", "/monitors/"+monitors[0].ID+"/resume", nil)
+		req.SetPathValue("id", monitors[0].ID)
+		rr = httptest.NewRecorder()
+		h.ResumeMonitorHandler(rr, req)
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("Expected a redirect, got %v", rr.Code)
+		}
+		if m, _ := store.Monitor(monitors[0].ID); m.Paused {
+			t.Errorf("Expected the monitor to be resumed, got %+v", m)
+		}
+
+		req = httptest.NewRequest("POST", "/monitors/"+monitors[0].ID+"/delete", nil)
+		req.SetPathValue("id", monitors[0].ID)
+		rr = httptest.NewRecorder()
+		h.DeleteMonitorHandler(rr, req)
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("Expected a redirect, got %v", rr.Code)
+		}
+		if monitors, _ := store.Monitors(); len(monitors) != 0 {
+			t.Errorf("Expected the monitor to be deleted, got %+v", monitors)
+		}
+		rr = httptest.NewRecorder()
+		h.DeleteMonitorHandler(rr, req)
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 deleting twice, got %v", rr.Code)
+		}
+	})
+
+	t.Run("MaintenanceWindows", func(t *testing.T) {
+		post := func(form url.Values) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", "/maintenance", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rr := httptest.NewRecorder()
+			h.MaintenanceHandler(rr, req)
+			return rr
+		}
+
+		for _, form := range []url.Values{
+			{"schedule": {"@daily"}, "duration": {"1h"}},
+			{"url": {ts.URL}, "project": {"docs"}, "schedule": {"@daily"}, "duration": {"1h"}},
+			{"url": {"example.com"}, "schedule": {"@daily"}, "duration": {"1h"}},
+			{"url": {ts.URL}, "schedule": {"daily"}, "duration": {"1h"}},
+			{"url": {ts.URL}, "schedule": {"@daily"}, "duration": {"forever"}},
+			{"url": {ts.URL}, "schedule": {"@daily"}, "duration": {"400h"}},
+			{"project": {"missing"}, "schedule": {"@daily"}, "duration": {"1h"}},
+		} {
+			if rr := post(form); rr.Code != http.StatusBadRequest {
+				t.Errorf("Expected %v to be rejected, got %v", form, rr.Code)
+			}
+		}
+
+		rr := post(url.Values{"url": {ts.URL}, "schedule": {"* * * * *"}, "duration": {"90m"}, "description": {"Deploy slot"}})
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("Expected a redirect, got %v: %s", rr.Code, rr.Body.String())
+		}
+		windows, err := store.MaintenanceWindows()
+		if err != nil || len(windows) != 1 || windows[0].URL != ts.URL || windows[0].Duration != 90*time.Minute {
+			t.Fatalf("Expected the window to be stored, got %+v (%v)", windows, err)
+		}
+
+		rr = httptest.NewRecorder()
+		h.MonitorsHandler(rr, httptest.NewRequest("GET", "/monitors", nil))
+		if body := rr.Body.String(); !strings.Contains(body, "Deploy slot") || !strings.Contains(body, "<strong>Open</strong>") {
+			t.Errorf("Expected the open window to be listed, got %v", rr.Code)
+		}
+
+		req := httptest.NewRequest("POST", "/maintenance/"+windows[0].ID+"/delete", nil)
+		req.SetPathValue("id", windows[0].ID)
+		rr = httptest.NewRecorder()
+		h.DeleteMaintenanceHandler(rr, req)
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("Expected a redirect, got %v", rr.Code)
+		}
+		rr = httptest.NewRecorder()
+		h.DeleteMaintenanceHandler(rr, req)
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 deleting twice, got %v", rr.Code)
+		}
+	})
+
+	t.Run("GrafanaDatasource", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		h.GrafanaTestHandler(rr, httptest.NewRequest("GET", "/api/grafana/", nil))
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected the connection test to pass, got %v", rr.Code)
+		}
+
+		rr = httptest.NewRecorder()
+		h.GrafanaMetricsHandler(rr, httptest.NewRequest("POST", "/api/grafana/metrics", strings.NewReader("{}")))
+		var metrics []grafanaMetric
+		if err := json.Unmarshal(rr.Body.Bytes(), &metrics); err != nil || len(metrics) != len(storage.MetricNames) || metrics[0].Label == "" {
+			t.Fatalf("Expected labelled metrics, got %v: %s", rr.Code, rr.Body.String())
+		}
+
+		query := func(body string) *httptest.ResponseRecorder {
+			rr := httptest.NewRecorder()
+			h.GrafanaQueryHandler(rr, httptest.NewRequest("POST", "/api/grafana/query", strings.NewReader(body)))
+			return rr
+		}
+		rr = query(`{"range":{"from":"2000-01-01T00:00:00Z","to":"2100-01-01T00:00:00Z"},"maxDataPoints":100,
+			"targets":[{"refId":"A","target":"broken_links","payload":{"url":"` + ts.URL + `"}},{"refId":"B","target":"score_overall","hide":true}]}`)
+		var series []grafanaSeries
+		if err := json.Unmarshal(rr.Body.Bytes(), &series); err != nil || rr.Code != http.StatusOK {
+			t.Fatalf("Expected series, got %v: %s", rr.Code, rr.Body.String())
+		}
+		if len(series) != 1 || series[0].Target != "broken_links" || len(series[0].Datapoints) == 0 {
+			t.Fatalf("Expected one broken link series for %s, got %+v", ts.URL, series)
+		}
+		for i, point := range series[0].Datapoints[1:] {
+			if point[1] < series[0].Datapoints[i][1] {
+				t.Errorf("Expected data points oldest first, got %v", series[0].Datapoints)
+			}
+		}
+
+		rr = query(`{"targets":[{"target":"score_overall"}]}`)
+		if err := json.Unmarshal(rr.Body.Bytes(), &series); err != nil || len(series) == 0 || !strings.HasPrefix(series[0].Target, "score_overall http") {
+			t.Errorf("Expected one series per page without a URL filter, got %s", rr.Body.String())
+		}
+
+		if rr := query(`{"targets":[{"target":"bogus"}]}`); rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected an unknown metric to be rejected, got %v", rr.Code)
+		}
+	})
+
+	t.Run("ErasureFlow", func(t *testing.T) {
+		erase := func(path, token string, form url.Values) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", path, strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			rr := httptest.NewRecorder()
+			if confirm, found := strings.CutPrefix(path, "/admin/erasure/"); found {
+				req.SetPathValue("token", strings.TrimSuffix(confirm, "/confirm"))
+				h.ConfirmErasureHandler(rr, req)
+			} else {
+				h.ErasureHandler(rr, req)
+			}
+			return rr
+		}
+		target := url.Values{"url": {ts.URL}}
+
+		if rr := erase("/admin/erasure", "", target); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected erasure to be disabled without an admin token, got %v", rr.Code)
+		}
+
+		h.SetAdminToken("s3cret")
+		defer h.SetAdminToken("")
+
+		if rr := erase("/admin/erasure", "wrong", target); rr.Code != http.StatusUnauthorized {
+			t.Errorf("Expected a wrong admin token to be rejected, got %v", rr.Code)
+		}
+		if rr := erase("/admin/erasure", "s3cret", url.Values{}); rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected a missing target to be rejected, got %v", rr.Code)
+		}
+
+		rr := erase("/admin/erasure", "s3cret", target)
+		var preview erasureResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &preview); err != nil || rr.Code != http.StatusOK {
+			t.Fatalf("Expected erasure preview, got %v: %s", rr.Code, rr.Body.String())
+		}
+		if preview.Token == "" || preview.Counts.Analyses == 0 || preview.Erased {
+			t.Fatalf("Expected a token and stored analyses to erase, got %+v", preview)
+		}
+		if len(mustList(t, store)) == 0 {
+			t.Fatal("Expected the preview to keep stored analyses")
+		}
+
+		rr = erase("/admin/erasure/"+preview.Token+"/confirm", "s3cret", nil)
+		var done erasureResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &done); err != nil || rr.Code != http.StatusOK || !done.Erased {
+			t.Fatalf("Expected erasure to be confirmed, got %v: %s", rr.Code, rr.Body.String())
+		}
+		if done.Counts.Analyses != preview.Counts.Analyses {
+			t.Errorf("Expected %d analyses erased, got %d", preview.Counts.Analyses, done.Counts.Analyses)
+		}
+		for _, summary := range mustList(t, store) {
+			if summary.URL == ts.URL || summary.URL == ts.URL+"/" {
+				t.Errorf("Expected %s to be erased, found %s", ts.URL, summary.ID)
+			}
+		}
+
+		if rr := erase("/admin/erasure/"+preview.Token+"/confirm", "s3cret", nil); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected a confirmation token to work once, got %v", rr.Code)
+		}
+
+		entries, _ := store.AuditLog(storage.AuditFilter{Action: storage.AuditDataErase})
+		if len(entries) != 1 || entries[0].Actor != adminActor || strings.Contains(entries[0].Target, ts.URL) {
+			t.Errorf("Expected the erasure to be audited without its URL, got %+v", entries)
+		}
+	})
+
+	t.Run("DenylistFlow", func(t *testing.T) {
+		h.SetAdminToken("s3cret")
+		defer h.SetAdminToken("")
+		admin := func(method, path string, form url.Values) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set("Authorization", "Bearer s3cret")
+			rr := httptest.NewRecorder()
+			if domain, found := strings.CutPrefix(path, "/admin/denylist/"); found {
+				req.SetPathValue("domain", strings.TrimSuffix(domain, "/delete"))
+				h.RemoveDenylistHandler(rr, req)
+			} else {
+				h.DenylistHandler(rr, req)
+			}
+			return rr
+		}
+
+		if rr := admin("POST", "/admin/denylist", url.Values{"domain": {"https://example.com/"}}); rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected a URL to be rejected as a domain, got %v", rr.Code)
+		}
+		host := strings.TrimPrefix(ts.URL, "http://")
+		host = host[:strings.LastIndex(host, ":")]
+		if rr := admin("POST", "/admin/denylist", url.Values{"domain": {host}, "reason": {"Legal request"}}); rr.Code != http.StatusOK {
+			t.Fatalf("Expected the domain to be listed, got %v: %s", rr.Code, rr.Body.String())
+		}
+
+		req := httptest.NewRequest("POST", "/analyze", strings.NewReader(url.Values{"url": {ts.URL}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		h.AnalyzeHandler(rr, req)
+		if rr.Code != http.StatusForbidden || !strings.Contains(rr.Body.String(), "Legal request") {
+			t.Errorf("Expected a denied submission to be explained, got %v: %s", rr.Code, rr.Body.String())
+		}
+
+		rr = admin("GET", "/admin/denylist", nil)
+		var entries []storage.DenyEntry
+		if err := json.Unmarshal(rr.Body.Bytes(), &entries); err != nil || len(entries) != 1 || entries[0].Domain != host {
+			t.Fatalf("Expected the listed domain, got %v: %s", rr.Code, rr.Body.String())
+		}
+
+		if rr := admin("POST", "/admin/denylist/"+host+"/delete", nil); rr.Code != http.StatusNoContent {
+			t.Errorf("Expected the domain to be removed, got %v", rr.Code)
+		}
+		if rr := admin("POST", "/admin/denylist/"+host+"/delete", nil); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected removing twice to fail, got %v", rr.Code)
+		}
+		if err := h.checkDenylist(ts.URL); err != nil {
+			t.Errorf("Expected the removed domain to be allowed, got %v", err)
+		}
+
+		audited, _ := store.AuditLog(storage.AuditFilter{Action: storage.AuditDenylistAdd})
+		if len(audited) != 1 || audited[0].Actor != adminActor || audited[0].Detail != "Legal request" {
+			t.Errorf("Expected the listing to be audited, got %+v", audited)
+		}
+	})
+
+	t.Run("ConfigBundle", func(t *testing.T) {
+		h.SetAdminToken("s3cret")
+		defer h.SetAdminToken("")
+		admin := func(method, path, body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(method, path, strings.NewReader(body))
+			req.Header.Set("Authorization", "Bearer s3cret")
+			rr := httptest.NewRecorder()
+			if method == http.MethodGet {
+				h.ConfigExportHandler(rr, req)
+			} else {
+				h.ConfigImportHandler(rr, req)
+			}
+			return rr
+		}
+
+		if err := store.Deny(&storage.DenyEntry{Domain: "blocked.example", Reason: "Legal request"}); err != nil {
+			t.Fatal(err)
+		}
+		rr := admin("GET", "/admin/config/export", "")
+		if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != "application/yaml" {
+			t.Fatalf("Expected a YAML bundle, got %v: %s", rr.Code, rr.Body.String())
+		}
+		exported := rr.Body.String()
+		if !strings.Contains(exported, "domain: blocked.example") || !strings.Contains(exported, "profiles:") {
+			t.Errorf("Expected the denylist and profiles in the bundle:\n%s", exported)
+		}
+
+		// Re-importing the export changes no monitors
+		rr = admin("POST", "/admin/config/import", exported)
+		var result bundle.Result
+		if err := json.Unmarshal(rr.Body.Bytes(), &result); err != nil || rr.Code != http.StatusOK {
+			t.Fatalf("Expected the bundle to be imported, got %v: %s", rr.Code, rr.Body.String())
+		}
+		if result.Monitors != 0 || result.Denylist == 0 || len(result.ProfilesNotApplied) != 0 {
+			t.Errorf("Unexpected import result %+v", result)
+		}
+
+		invalid := "version: 1\nmonitors:\n  - url: https://example.com\n    schedule: sometimes\n"
+		if rr := admin("POST", "/admin/config/import", invalid); rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected an invalid bundle to be rejected, got %v", rr.Code)
+		}
+		if rr := admin("POST", "/admin/config/import", "version: 7\n"); rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected an unsupported version to be rejected, got %v", rr.Code)
+		}
+
+		audited, _ := store.AuditLog(storage.AuditFilter{Action: storage.AuditConfigImport})
+		if len(audited) != 1 || audited[0].Actor != adminActor {
+			t.Errorf("Expected one audited import, got %+v", audited)
+		}
+		if err := store.RemoveDenied("blocked.example"); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// TestErasurePurgesCache erases a page whose analysis is cached, then
+// analyzes it again
+func TestErasurePurgesCache(t *testing.T) {
+	var fetches atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		n := fetches.Add(1)
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, "<html><head><title>Version %d</title></head><body></body></html>", n)
+	}))
+	defer ts.Close()
+
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	store, err := storage.NewSQLiteStore(t.TempDir() + "/history.db")
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	a := analyzer.NewAnalyzer(&analyzer.Config{
+		RequestTimeout:  5 * time.Second,
+		LinkTimeout:     2 * time.Second,
+		MaxWorkers:      5,
+		MaxResponseSize: 1024 * 1024,
+		MaxURLLength:    2048,
+		MaxRedirects:    5,
+		CacheTTL:        time.Hour,
+	})
+	h, err := NewHandler(a, store, "../../web/templates")
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+	h.SetAdminToken("s3cret")
+
+	post := func(handler http.HandlerFunc, path string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Authorization", "Bearer s3cret")
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+	// Both the result cache and, with the cached fetcher, the page cache
+	// hold the page
+	analyze := func() {
+		t.Helper()
+		for _, fetcher := range []string{"", analyzer.FetcherCached} {
+			if rr := post(h.AnalyzeHandler, "/analyze", url.Values{"url": {ts.URL}, "fetcher": {fetcher}}); rr.Code != http.StatusOK {
+				t.Fatalf("Expected the analysis to succeed, got %v: %s", rr.Code, rr.Body.String())
+			}
+		}
+	}
+
+	analyze()
+	analyze()
+	if n := fetches.Load(); n != 2 {
+		t.Fatalf("Expected repeated analyses to be cached, the page was fetched %d times", n)
+	}
+
+	var preview erasureResponse
+	rr := post(h.ErasureHandler, "/admin/erasure", url.Values{"url": {ts.URL + "/"}})
+	if err := json.Unmarshal(rr.Body.Bytes(), &preview); err != nil || preview.Token == "" {
+		t.Fatalf("Expected an erasure token, got %v: %s", rr.Code, rr.Body.String())
+	}
+	req := httptest.NewRequest("POST", "/admin/erasure/"+preview.Token+"/confirm", nil)
+	req.SetPathValue("token", preview.Token)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rr = httptest.NewRecorder()
+	h.ConfirmErasureHandler(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected the erasure to be confirmed, got %v: %s", rr.Code, rr.Body.String())
+	}
+
+	analyze()
+	if n := fetches.Load(); n != 4 {
+		t.Errorf("Expected both caches to be purged, the page was fetched %d times", n)
+	}
+	for _, summary := range mustList(t, store) {
+		if record, err := store.Get(summary.ID); err == nil && record.Result.Title != "Version 3" && record.Result.Title != "Version 4" {
+			t.Errorf("Expected only fresh analyses after the erasure, found %q", record.Result.Title)
+		}
+	}
+}
+
+// TestStoredResultsRedacted reads back results saved before redaction was
+// configured through every view of the history
+func TestStoredResultsRedacted(t *testing.T) {
+	store, err := storage.NewSQLiteStore(t.TempDir() + "/history.db")
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	const page = "https://example.com/?token=s3cret"
+	var ids []string
+	for _, title := range []string{"Before", "After"} {
+		record, err := store.Save(page, &models.AnalysisResult{URL: page, Title: title, Links: []string{page + "&next=1"}}, storage.Labels{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, record.ID)
+	}
+	if err := store.SaveProject(&storage.Project{Name: "docs", KeyURLs: []string{page}}); err != nil {
+		t.Fatal(err)
+	}
+
+	a := analyzer.NewAnalyzer(&analyzer.Config{RequestTimeout: time.Second, RedactParams: []string{"token"}})
+	h, err := NewHandler(a, store, "../../web/templates")
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+
+	get := func(handler http.HandlerFunc, target string, values map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", target, nil)
+		for name, value := range values {
+			req.SetPathValue(name, value)
+		}
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+	for name, rr := range map[string]*httptest.ResponseRecorder{
+		"history":   get(h.HistoryResultHandler, "/history/"+ids[0], map[string]string{"id": ids[0]}),
+		"diff":      get(h.DiffHandler, "/api/v1/diff?"+url.Values{"from": {ids[0]}, "to": {ids[1]}}.Encode(), nil),
+		"link rot":  get(h.LinkRotHandler, "/linkrot?format=json&"+url.Values{"url": {page}}.Encode(), nil),
+		"portfolio": get(h.ProjectReportHandler, "/projects/docs/report?format=json", map[string]string{"name": "docs"}),
+	} {
+		if rr.Code != http.StatusOK {
+			t.Errorf("%s: expected status OK, got %v: %s", name, rr.Code, rr.Body.String())
+			continue
+		}
+		if body := rr.Body.String(); strings.Contains(body, "s3cret") {
+			t.Errorf("%s: expected the token to be redacted:\n%s", name, body)
+		}
+	}
+}
+
+func mustList(t *testing.T, store storage.Store) []storage.Summary {
+	t.Helper()
+	summaries, err := store.List(storage.Filter{Limit: 100})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	return summaries
+}
+
+func TestStaticAssets(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(dir+"/img", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir+"/style.css", []byte("body { color: red; }"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir+"/img/logo.svg", []byte("<svg/>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	assets, err := NewStaticAssets(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("NewStaticAssets failed: %v", err)
+	}
+
+	hashed := assets.Path("style.css")
+	if !strings.HasPrefix(hashed, "/static/style.") || !strings.HasSuffix(hashed, ".css") || hashed == "/static/style.css" {
+		t.Fatalf("Expected a fingerprinted path, got %q", hashed)
+	}
+	if logo := assets.Path("img/logo.svg"); !strings.HasPrefix(logo, "/static/img/logo.") {
+		t.Errorf("Expected nested assets to keep their directory, got %q", logo)
+	}
+	if missing := assets.Path("missing.js"); missing != "/static/missing.js" {
+		t.Errorf("Expected unknown assets to keep their name, got %q", missing)
+	}
+
+	get := func(path string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		rr := httptest.NewRecorder()
+		assets.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get(hashed)
+	if rr.Code != http.StatusOK || rr.Body.String() != "body { color: red; }" {
+		t.Fatalf("Expected the stylesheet, got %v %q", rr.Code, rr.Body.String())
+	}
+	if cc := rr.Header().Get("Cache-Control"); cc != "public, max-age=3600, immutable" {
+		t.Errorf("Expected long-lived caching, got %q", cc)
+	}
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/css") {
+		t.Errorf("Expected a CSS content type, got %q", ct)
+	}
+
+	rr = get("/static/style.css")
+	if rr.Code != http.StatusOK || rr.Header().Get("Cache-Control") != "no-cache" {
+		t.Errorf("Expected the plain name to be revalidated, got %v %q", rr.Code, rr.Header().Get("Cache-Control"))
+	}
+	if rr := get("/static/style.css", "If-None-Match", rr.Header().Get("ETag")); rr.Code != http.StatusNotModified {
+		t.Errorf("Expected a matching ETag to return 304, got %v", rr.Code)
+	}
+	if rr := get("/static/style.0000000000.css"); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected a stale fingerprint to be missing, got %v", rr.Code)
+	}
+}
+
+// countCached counts the plan's pages served from the result cache
+func countCached(plan models.ScopePlan) int {
+	n := 0
+	for _, page := range plan.Pages {
+		if page.Cached {
+			n++
+		}
+	}
+	return n
 }
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"website-analyzer/internal/storage"
+)
+
+// savedHookTimeout bounds each hook run
+const savedHookTimeout = 30 * time.Second
+
+// SavedHook publishes a stored analysis elsewhere, e.g. to a spreadsheet
+type SavedHook func(ctx context.Context, record *storage.Record) error
+
+type namedHook struct {
+	name string
+	hook SavedHook
+}
+
+// AddSavedHook runs hook in the background after every analysis the
+// handler stores; failures are logged under name
+func (h *Handler) AddSavedHook(name string, hook SavedHook) {
+	h.savedHooks = append(h.savedHooks, namedHook{name: name, hook: hook})
+}
+
+// AddScheduledHook runs hook in the background after every scheduled
+// monitor run, and not after other analyses
+func (h *Handler) AddScheduledHook(name string, hook SavedHook) {
+	h.scheduledHooks = append(h.scheduledHooks, namedHook{name: name, hook: hook})
+}
+
+// RunSavedHooks runs the saved hooks for the stored analysis id, including
+// analyses stored outside the handler. Each hook gets its own copy, so the
+// caller may go on modifying the result it saved.
+func (h *Handler) RunSavedHooks(id string) {
+	h.runHooks(id, h.savedHooks)
+}
+
+// RunScheduledHooks runs the saved and scheduled hooks for the stored
+// result id of a monitor run
+func (h *Handler) RunScheduledHooks(id string) {
+	h.runHooks(id, h.savedHooks)
+	h.runHooks(id, h.scheduledHooks)
+}
+
+func (h *Handler) runHooks(id string, hooks []namedHook) {
+	for _, hook := range hooks {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), savedHookTimeout)
+			defer cancel()
+
+			record, err := h.store.Get(id)
+			if err == nil {
+				err = hook.hook(ctx, record)
+			}
+			if err != nil {
+				slog.Error("saved hook failed", "hook", hook.name, "id", id, "error", err)
+			}
+		}()
+	}
+}
@@ -0,0 +1,304 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"website-analyzer/internal/analyzer"
+	"website-analyzer/internal/jobs"
+	"website-analyzer/internal/models"
+	"website-analyzer/internal/storage"
+)
+
+// jobEventInterval is the least time between two events of a job's stream
+const jobEventInterval = 500 * time.Millisecond
+
+var errJobStart = errors.New("Failed to start job")
+
+// crawlJob is what a background crawl needs to run on any replica
+type crawlJob struct {
+	URL     string
+	Options analyzer.CrawlOptions
+	Actor   string
+}
+
+// batchJob is what a background batch needs to run on any replica
+type batchJob struct {
+	URLs    []string
+	Options analyzer.AnalyzeOptions
+	Labels  storage.Labels
+	Actor   string
+}
+
+// SetJobs runs background crawls and batches on m; without it the jobs
+// endpoints answer 404
+func (h *Handler) SetJobs(m *jobs.Manager) {
+	h.jobs = m
+	if m == nil {
+		return
+	}
+	m.Handle(jobs.KindCrawl, h.runCrawlJob)
+	m.Handle(jobs.KindBatch, h.runBatchJob)
+}
+
+// JobsHandler starts a background job chosen by the kind form value:
+// crawl with url and profile, or batch with the batch API's values. It
+// answers 202 with the job, whose URL is in the Location header.
+func (h *Handler) JobsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.jobs == nil {
+		writeJSONError(w, "Background jobs are disabled", http.StatusNotFound)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeJSONError(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	var job models.Job
+	var err error
+	switch r.FormValue("kind") {
+	case jobs.KindCrawl:
+		job, err = h.startCrawl(r.FormValue("url"), analyzer.CrawlOptions{Profile: r.FormValue("profile")}, webActor(r), jobs.PriorityAPI)
+		if err != nil {
+			writeJSONError(w, err.Error(), jobErrorStatus(err))
+			return
+		}
+	case jobs.KindBatch:
+		urls, labels, actor, ok := h.batchRequest(w, r)
+		if !ok || !h.enforceQuota(w, labels.APIKey, len(urls)) {
+			return
+		}
+		payload := batchJob{URLs: urls, Options: batchOptions(r), Labels: labels, Actor: actor}
+		job, err = h.jobs.Submit(jobs.KindBatch, fmt.Sprintf("%d URLs", len(urls)), jobs.PriorityAPI, payload)
+		if err != nil {
+			slog.Error("failed to start job", "error", err)
+			writeJSONError(w, errJobStart.Error(), http.StatusInternalServerError)
+			return
+		}
+	default:
+		writeJSONError(w, fmt.Sprintf("kind must be %q or %q", jobs.KindCrawl, jobs.KindBatch), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/jobs/"+job.ID)
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// startCrawl queues a crawl of targetURL as a background job after the
+// checks a synchronous crawl makes up front
+func (h *Handler) startCrawl(targetURL string, opts analyzer.CrawlOptions, actor string, priority jobs.Priority) (models.Job, error) {
+	if err := h.checkDenylist(targetURL); err != nil {
+		return models.Job{}, err
+	}
+
+	payload := crawlJob{URL: targetURL, Options: opts, Actor: actor}
+	job, err := h.jobs.Submit(jobs.KindCrawl, h.analyzer.Redactor().Text(targetURL), priority, payload)
+	if err != nil {
+		slog.Error("failed to start job", "error", err)
+		return models.Job{}, errJobStart
+	}
+	return job, nil
+}
+
+// runCrawlJob runs a crawl job queued by startCrawl
+func (h *Handler) runCrawlJob(ctx context.Context, payload json.RawMessage, progress *jobs.Tracker) (any, error) {
+	var job crawlJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	result, err := h.analyzer.Crawl(analyzer.WithProgress(ctx, progress), job.URL, job.Options)
+	h.logCrawl(job.Actor, job.URL, time.Since(start), result, err)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// runBatchJob runs a batch job queued by JobsHandler
+func (h *Handler) runBatchJob(ctx context.Context, payload json.RawMessage, progress *jobs.Tracker) (any, error) {
+	var job batchJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return nil, err
+	}
+	return h.runBatch(analyzer.WithProgress(ctx, progress), job.URLs, job.Options, job.Labels, job.Actor, progress), nil
+}
+
+// jobErrorStatus is the status of a job that could not start
+func jobErrorStatus(err error) int {
+	if errors.Is(err, errJobStart) {
+		return http.StatusInternalServerError
+	}
+	return analysisErrorStatus(err)
+}
+
+// JobHandler returns a job with its progress, and its result once done
+func (h *Handler) JobHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	job, ok := h.job(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// JobEventsHandler streams a job's progress as server-sent events: a
+// progress event whenever it changes, at most every jobEventInterval,
+// then one completed or failed event before the stream ends. Events carry
+// the job without its result.
+func (h *Handler) JobEventsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.jobs == nil {
+		writeJSONError(w, "Background jobs are disabled", http.StatusNotFound)
+		return
+	}
+
+	id := r.PathValue("id")
+	updates, unsubscribe, err := h.jobs.Subscribe(id)
+	if errors.Is(err, jobs.ErrNotFound) {
+		writeJSONError(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	defer unsubscribe()
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSONError(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	for {
+		job, err := h.jobs.Get(id)
+		if err != nil {
+			return
+		}
+		event := "progress"
+		if job.State == jobs.StateCompleted || job.State == jobs.StateFailed {
+			event = job.State
+		}
+		job.Result = nil
+		data, err := json.Marshal(job)
+		if err != nil {
+			slog.Error("failed to encode job", "error", err)
+			return
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+			return
+		}
+		flusher.Flush()
+		if event != "progress" {
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-updates:
+		}
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(jobEventInterval):
+		}
+	}
+}
+
+// JobPageHandler shows a queued or running job's progress bar. A completed crawl
+// shows its results.
+func (h *Handler) JobPageHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.jobs == nil {
+		h.renderError(w, "Background jobs are disabled", http.StatusNotFound)
+		return
+	}
+
+	job, err := h.jobs.Get(r.PathValue("id"))
+	if err != nil {
+		h.renderError(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	if crawl, ok := crawlResult(job); ok {
+		data := struct {
+			Crawl *models.CrawlResult
+		}{
+			Crawl: crawl,
+		}
+		if err := h.templates.ExecuteTemplate(w, "crawl.html", data); err != nil {
+			slog.Error("template error", "error", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	data := struct {
+		Job models.Job
+	}{
+		Job: job,
+	}
+	if err := h.templates.ExecuteTemplate(w, "job.html", data); err != nil {
+		slog.Error("template error", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// crawlResult is the result of a completed crawl job, decoded when another
+// replica ran it
+func crawlResult(job models.Job) (*models.CrawlResult, bool) {
+	switch result := job.Result.(type) {
+	case *models.CrawlResult:
+		return result, true
+	case json.RawMessage:
+		if job.Kind != jobs.KindCrawl {
+			return nil, false
+		}
+		var crawl models.CrawlResult
+		if err := json.Unmarshal(result, &crawl); err != nil {
+			return nil, false
+		}
+		return &crawl, true
+	}
+	return nil, false
+}
+
+// job looks up the job named by the id path value, writing a JSON error
+// if there is none
+func (h *Handler) job(w http.ResponseWriter, r *http.Request) (models.Job, bool) {
+	if h.jobs == nil {
+		writeJSONError(w, "Background jobs are disabled", http.StatusNotFound)
+		return models.Job{}, false
+	}
+	job, err := h.jobs.Get(r.PathValue("id"))
+	if err != nil {
+		writeJSONError(w, "Job not found", http.StatusNotFound)
+		return models.Job{}, false
+	}
+	return job, true
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"website-analyzer/internal/linkrot"
+	"website-analyzer/internal/storage"
+)
+
+// LinkRotHandler reports how the external links of a page (url) or a
+// project's pages (project) decayed over the past months (12 by default):
+// a printable page, or with format=json the report itself
+func (h *Handler) LinkRotHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.store == nil {
+		h.renderError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format != "" && format != "json" {
+		http.Error(w, "format must be json", http.StatusBadRequest)
+		return
+	}
+	filter := storage.HistoryFilter{URL: query.Get("url"), Project: query.Get("project")}
+	if (filter.URL == "") == (filter.Project == "") {
+		h.renderError(w, "Choose either a url or a project", http.StatusBadRequest)
+		return
+	}
+	if filter.Project != "" {
+		if _, err := h.store.Project(filter.Project); errors.Is(err, storage.ErrNotFound) {
+			h.renderError(w, "Project not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			slog.Error("failed to load project", "project", filter.Project, "error", err)
+			h.renderError(w, "Failed to load project", http.StatusInternalServerError)
+			return
+		}
+	}
+	months := linkrot.DefaultMonths
+	if value := query.Get("months"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > linkrot.MaxMonths {
+			h.renderError(w, "months must be between 1 and "+strconv.Itoa(linkrot.MaxMonths), http.StatusBadRequest)
+			return
+		}
+		months = n
+	}
+
+	report, err := loadStored(h, func() (*linkrot.Report, error) { return linkrot.Build(h.store, filter, months, time.Now()) })
+	if err != nil {
+		slog.Error("failed to build link rot report", "url", filter.URL, "project", filter.Project, "error", err)
+		h.renderError(w, "Failed to build report", http.StatusInternalServerError)
+		return
+	}
+
+	if format == "json" {
+		writeJSON(w, http.StatusOK, report)
+		return
+	}
+	if err := h.templates.ExecuteTemplate(w, "linkrot.html", report); err != nil {
+		slog.Error("template error", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
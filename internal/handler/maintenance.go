@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"website-analyzer/internal/monitor"
+	"website-analyzer/internal/storage"
+)
+
+// maxMaintenanceDuration bounds a maintenance window, so a typo can't
+// silence a page for good
+const maxMaintenanceDuration = 7 * 24 * time.Hour
+
+// maintenanceWindow is a window as listed on the monitors page
+type maintenanceWindow struct {
+	storage.MaintenanceWindow
+	Open bool
+}
+
+// MaintenanceHandler adds a recurring maintenance window for the url or
+// project form value, opening on the schedule value for duration, during
+// which notifications are suppressed
+func (h *Handler) MaintenanceHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.store == nil {
+		h.renderError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		h.renderError(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	window := &storage.MaintenanceWindow{
+		URL:         strings.TrimSpace(r.FormValue("url")),
+		Project:     r.FormValue("project"),
+		Schedule:    strings.TrimSpace(r.FormValue("schedule")),
+		Description: strings.TrimSpace(r.FormValue("description")),
+	}
+	if (window.URL == "") == (window.Project == "") {
+		h.renderError(w, "A maintenance window covers either a URL or a project", http.StatusBadRequest)
+		return
+	}
+	if window.URL != "" {
+		if u, err := url.Parse(window.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			h.renderError(w, "URL must be an http or https URL", http.StatusBadRequest)
+			return
+		}
+	}
+	if _, err := monitor.ParseSchedule(window.Schedule); err != nil {
+		h.renderError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	duration, err := time.ParseDuration(strings.TrimSpace(r.FormValue("duration")))
+	if err != nil || duration <= 0 || duration > maxMaintenanceDuration {
+		h.renderError(w, "Duration must be between 1m and 168h, e.g. 90m", http.StatusBadRequest)
+		return
+	}
+	window.Duration = duration
+
+	err = h.store.CreateMaintenanceWindow(window)
+	if errors.Is(err, storage.ErrUnknownProject) {
+		h.renderError(w, "Unknown project", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to create maintenance window", "error", err)
+		h.renderError(w, "Failed to create maintenance window", http.StatusInternalServerError)
+		return
+	}
+
+	target := window.URL
+	if target == "" {
+		target = "project:" + window.Project
+	}
+	slog.Info("maintenance window created", "id", window.ID, "target", target, "schedule", window.Schedule, "duration", window.Duration)
+	h.audit(webActor(r), storage.AuditWindowAdd, target, "id="+window.ID+" schedule="+window.Schedule+" duration="+window.Duration.String())
+	http.Redirect(w, r, "/monitors", http.StatusSeeOther)
+}
+
+func (h *Handler) DeleteMaintenanceHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.store == nil {
+		h.renderError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	id := r.PathValue("id")
+	err := h.store.DeleteMaintenanceWindow(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		h.renderError(w, "Maintenance window not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to delete maintenance window", "id", id, "error", err)
+		h.renderError(w, "Failed to delete maintenance window", http.StatusInternalServerError)
+		return
+	}
+
+	slog.Info("maintenance window deleted", "id", id)
+	h.audit(webActor(r), storage.AuditWindowRemove, id, "id="+id)
+	http.Redirect(w, r, "/monitors", http.StatusSeeOther)
+}
+
+// maintenanceWindows lists the windows for the monitors page, marking
+// those open at now; a failed lookup is logged and lists none
+func (h *Handler) maintenanceWindows(now time.Time) []maintenanceWindow {
+	windows, err := h.store.MaintenanceWindows()
+	if err != nil {
+		slog.Error("failed to list maintenance windows", "error", err)
+		return nil
+	}
+	h.analyzer.Redactor().Walk(windows)
+
+	listed := make([]maintenanceWindow, len(windows))
+	for i := range windows {
+		listed[i] = maintenanceWindow{MaintenanceWindow: windows[i], Open: monitor.WindowOpen(&windows[i], now)}
+	}
+	return listed
+}
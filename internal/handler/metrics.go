@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+// MetricsHandler exposes process and link checker gauges in the Prometheus
+// text format
+func (h *Handler) MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	states := map[string]int{models.CircuitClosed: 0, models.CircuitOpen: 0, models.CircuitHalfOpen: 0}
+	for _, state := range h.analyzer.CircuitBreakerState() {
+		states[state.State]++
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	gauge(w, "website_analyzer_uptime_seconds", "Seconds since the server started.", time.Since(h.started).Seconds())
+	gauge(w, "go_goroutines", "Number of goroutines that currently exist.", float64(runtime.NumGoroutine()))
+	gauge(w, "go_memstats_heap_alloc_bytes", "Number of heap bytes allocated and still in use.", float64(mem.HeapAlloc))
+
+	fmt.Fprintln(w, "# HELP website_analyzer_circuit_breaker_domains Domains with recent link check failures by breaker state.")
+	fmt.Fprintln(w, "# TYPE website_analyzer_circuit_breaker_domains gauge")
+	for _, state := range []string{models.CircuitClosed, models.CircuitOpen, models.CircuitHalfOpen} {
+		fmt.Fprintf(w, "website_analyzer_circuit_breaker_domains{state=%q} %d\n", state, states[state])
+	}
+}
+
+func gauge(w http.ResponseWriter, name, help string, value float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n", name, help, name, name, value)
+}
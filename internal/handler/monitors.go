@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"website-analyzer/internal/analyzer"
+	"website-analyzer/internal/monitor"
+	"website-analyzer/internal/storage"
+)
+
+// MonitorsHandler lists the pages re-analyzed on a schedule, or registers
+// one from the url, schedule, project, profile and webhook form values
+func (h *Handler) MonitorsHandler(w http.ResponseWriter, r *http.Request) {
+	if h.store == nil {
+		h.renderError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.renderMonitors(w)
+	case http.MethodPost:
+		h.createMonitor(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handler) createMonitor(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		h.renderError(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	m := &storage.Monitor{
+		URL:      strings.TrimSpace(r.FormValue("url")),
+		Schedule: strings.TrimSpace(r.FormValue("schedule")),
+		Project:  r.FormValue("project"),
+		Profile:  r.FormValue("profile"),
+		Webhook:  strings.TrimSpace(r.FormValue("webhook")),
+	}
+	if u, err := url.Parse(m.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		h.renderError(w, "Monitored URL must be an http or https URL", http.StatusBadRequest)
+		return
+	}
+	if m.Webhook != "" {
+		if u, err := url.Parse(m.Webhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			h.renderError(w, "Webhook must be an http or https URL", http.StatusBadRequest)
+			return
+		}
+	}
+	schedule, err := monitor.ParseSchedule(m.Schedule)
+	if err != nil {
+		h.renderError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if m.Profile != "" && !h.profileExists(m.Profile) {
+		h.renderError(w, "Unknown profile", http.StatusBadRequest)
+		return
+	}
+	if err := h.checkDenylist(m.URL); err != nil {
+		h.renderError(w, err.Error(), analysisErrorStatus(err))
+		return
+	}
+	m.NextRun = schedule.Next(time.Now())
+
+	err = h.store.CreateMonitor(m)
+	if errors.Is(err, storage.ErrUnknownProject) {
+		h.renderError(w, "Unknown project", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to create monitor", "url", m.URL, "error", err)
+		h.renderError(w, "Failed to create monitor", http.StatusInternalServerError)
+		return
+	}
+
+	slog.Info("monitor created", "id", m.ID, "url", m.URL, "schedule", m.Schedule)
+	h.audit(webActor(r), storage.AuditMonitorCreate, m.URL, "id="+m.ID+" schedule="+m.Schedule)
+	http.Redirect(w, r, "/monitors", http.StatusSeeOther)
+}
+
+func (h *Handler) DeleteMonitorHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.store == nil {
+		h.renderError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	id := r.PathValue("id")
+	m, err := h.store.Monitor(id)
+	if err == nil {
+		err = h.store.DeleteMonitor(id)
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		h.renderError(w, "Monitor not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to delete monitor", "id", id, "error", err)
+		h.renderError(w, "Failed to delete monitor", http.StatusInternalServerError)
+		return
+	}
+
+	slog.Info("monitor deleted", "id", id)
+	h.audit(webActor(r), storage.AuditMonitorDelete, m.URL, "id="+id)
+	http.Redirect(w, r, "/monitors", http.StatusSeeOther)
+}
+
+// PauseMonitorHandler stops a monitor's runs and alerts, e.g. during planned
+// maintenance of the site, until it is resumed or until the optional until
+// form value, a UTC time
+func (h *Handler) PauseMonitorHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.store == nil {
+		h.renderError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	var until time.Time
+	if value := strings.TrimSpace(r.FormValue("until")); value != "" {
+		var err error
+		until, err = parseResumeTime(value)
+		if err != nil {
+			h.renderError(w, "Resume time must look like 2006-01-02T15:04 (UTC)", http.StatusBadRequest)
+			return
+		}
+		if !until.After(time.Now()) {
+			h.renderError(w, "Resume time must be in the future", http.StatusBadRequest)
+			return
+		}
+	}
+
+	id := r.PathValue("id")
+	m, err := h.store.Monitor(id)
+	if err == nil {
+		err = h.store.PauseMonitor(id, until)
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		h.renderError(w, "Monitor not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to pause monitor", "id", id, "error", err)
+		h.renderError(w, "Failed to pause monitor", http.StatusInternalServerError)
+		return
+	}
+
+	detail := "id=" + id
+	if !until.IsZero() {
+		detail += " until=" + until.Format(time.RFC3339)
+	}
+	slog.Info("monitor paused", "id", id, "until", until)
+	h.audit(webActor(r), storage.AuditMonitorPause, m.URL, detail)
+	http.Redirect(w, r, "/monitors", http.StatusSeeOther)
+}
+
+// ResumeMonitorHandler lets a paused monitor run again from its schedule
+func (h *Handler) ResumeMonitorHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.store == nil {
+		h.renderError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	id := r.PathValue("id")
+	m, err := h.store.Monitor(id)
+	if err == nil {
+		err = h.store.ResumeMonitor(id)
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		h.renderError(w, "Monitor not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to resume monitor", "id", id, "error", err)
+		h.renderError(w, "Failed to resume monitor", http.StatusInternalServerError)
+		return
+	}
+
+	slog.Info("monitor resumed", "id", id)
+	h.audit(webActor(r), storage.AuditMonitorResume, m.URL, "id="+id)
+	http.Redirect(w, r, "/monitors", http.StatusSeeOther)
+}
+
+// parseResumeTime reads a datetime-local form value in UTC, or RFC 3339
+func parseResumeTime(value string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02T15:04", value, time.UTC); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func (h *Handler) renderMonitors(w http.ResponseWriter) {
+	monitors, err := h.store.Monitors()
+	if err != nil {
+		slog.Error("failed to list monitors", "error", err)
+		h.renderError(w, "Failed to load monitors", http.StatusInternalServerError)
+		return
+	}
+	h.analyzer.Redactor().Walk(monitors)
+
+	now := time.Now()
+	data := struct {
+		Monitors       []storage.Monitor
+		Profiles       []analyzer.Profile
+		DefaultProfile string
+		Projects       []storage.Project
+		Windows        []maintenanceWindow
+		Now            time.Time
+	}{
+		Monitors:       monitors,
+		Profiles:       h.analyzer.Profiles(),
+		DefaultProfile: h.analyzer.DefaultProfile(),
+		Projects:       h.projects(),
+		Windows:        h.maintenanceWindows(now),
+		Now:            now,
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "monitors.html", data); err != nil {
+		slog.Error("template error", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// profileExists reports whether name is a configured analysis profile
+func (h *Handler) profileExists(name string) bool {
+	for _, p := range h.analyzer.Profiles() {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"website-analyzer/internal/portfolio"
+	"website-analyzer/internal/storage"
+)
+
+// ProjectReportHandler rolls up the latest analyses of a project's key
+// pages into one report: a printable page, or with format=json or md a
+// download
+func (h *Handler) ProjectReportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.store == nil {
+		h.renderError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "md" {
+		http.Error(w, "format must be json or md", http.StatusBadRequest)
+		return
+	}
+
+	project, err := h.store.Project(r.PathValue("name"))
+	if errors.Is(err, storage.ErrNotFound) {
+		h.renderError(w, "Project not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to load project", "project", r.PathValue("name"), "error", err)
+		h.renderError(w, "Failed to load project", http.StatusInternalServerError)
+		return
+	}
+
+	report, err := loadStored(h, func() (*portfolio.Report, error) { return portfolio.Build(h.store, project, time.Now()) })
+	if err != nil {
+		slog.Error("failed to build portfolio report", "project", project.Name, "error", err)
+		h.renderError(w, "Failed to build report", http.StatusInternalServerError)
+		return
+	}
+
+	switch format {
+	case "json":
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="portfolio-%s.json"`, project.Name))
+		writeJSON(w, http.StatusOK, report)
+	case "md":
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="portfolio-%s.md"`, project.Name))
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		_, _ = w.Write(portfolioMarkdown(report))
+	default:
+		data := struct {
+			Report    *portfolio.Report
+			Project   *storage.Project
+			Regressed []portfolio.Page
+		}{
+			Report:    report,
+			Project:   project,
+			Regressed: report.RegressedPages(),
+		}
+		if err := h.templates.ExecuteTemplate(w, "portfolio.html", data); err != nil {
+			slog.Error("template error", "error", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+	}
+}
+
+// portfolioMarkdown renders a portfolio report for readers without access
+// to the analyzer
+func portfolioMarkdown(report *portfolio.Report) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "# Portfolio Report: %s\n\n", mdEscape(report.Project))
+	fmt.Fprintf(&b, "- **Generated:** %s\n", report.GeneratedAt.Format("2006-01-02 15:04:05 UTC"))
+	fmt.Fprintf(&b, "- **Pages:** %d\n", len(report.Pages))
+	fmt.Fprintf(&b, "- **Broken links:** %d\n", report.BrokenLinks)
+	fmt.Fprintf(&b, "- **Pages with regressions:** %d\n", report.Regressed)
+
+	if len(report.Pages) > 0 {
+		b.WriteString("\n## Pages, Worst First\n\n| Page | Overall | SEO | Accessibility | Links | Broken Links | Analyzed |\n| --- | --- | --- | --- | --- | --- | --- |\n")
+		for _, page := range report.Pages {
+			overall, seo, accessibility, links := "-", "-", "-", "-"
+			if s := page.Scores; s != nil {
+				overall, seo, accessibility, links = strconv.Itoa(s.Overall), strconv.Itoa(s.SEO), strconv.Itoa(s.Accessibility), strconv.Itoa(s.Links)
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %d | %s |\n", mdEscape(page.URL), overall, seo, accessibility, links,
+				page.BrokenLinks, page.AnalyzedAt.Format("2006-01-02 15:04"))
+		}
+	}
+
+	if regressed := report.RegressedPages(); len(regressed) > 0 {
+		b.WriteString("\n## Regressions\n\n")
+		for _, page := range regressed {
+			for _, regression := range page.Regressions {
+				fmt.Fprintf(&b, "- %s: %s %s\n", mdEscape(page.URL), regression.Kind, mdEscape(regression.Detail))
+			}
+		}
+	}
+
+	if len(report.NotAnalyzed) > 0 {
+		b.WriteString("\n## Not Yet Analyzed\n\n")
+		for _, url := range report.NotAnalyzed {
+			fmt.Fprintf(&b, "- %s\n", mdEscape(url))
+		}
+	}
+
+	return b.Bytes()
+}
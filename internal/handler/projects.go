@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"website-analyzer/internal/monitor"
+	"website-analyzer/internal/portfolio"
+	"website-analyzer/internal/storage"
+	"website-analyzer/internal/webhook"
+)
+
+// projectView is a project with its API keys for the projects page
+type projectView struct {
+	storage.Project
+	Keys []keyView
+}
+
+// keyView is an API key with the resources its analyses have used
+type keyView struct {
+	storage.APIKey
+	Usage storage.UsageTotal
+}
+
+func (h *Handler) ProjectsHandler(w http.ResponseWriter, r *http.Request) {
+	if h.store == nil {
+		h.renderError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.renderProjects(w)
+	case http.MethodPost:
+		h.saveProject(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handler) saveProject(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		h.renderError(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	project := &storage.Project{
+		Name:          strings.TrimSpace(r.FormValue("name")),
+		Description:   strings.TrimSpace(r.FormValue("description")),
+		NotifyWebhook: strings.TrimSpace(r.FormValue("notify_webhook")),
+		NotifyFormat:  r.FormValue("notify_format"),
+		NotifyEmail:   strings.TrimSpace(r.FormValue("notify_email")),
+		// Stored URLs are redacted, so key pages are kept the same way
+		KeyURLs:        h.keyURLs(r.FormValue("key_urls")),
+		ReportSchedule: strings.TrimSpace(r.FormValue("report_schedule")),
+	}
+	if !storage.ValidProjectName(project.Name) {
+		h.renderError(w, "Project names use lowercase letters, digits and dashes", http.StatusBadRequest)
+		return
+	}
+	if project.NotifyWebhook != "" {
+		if u, err := url.Parse(project.NotifyWebhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			h.renderError(w, "Notification webhook must be an http or https URL", http.StatusBadRequest)
+			return
+		}
+	}
+	if !webhook.ValidFormat(project.NotifyFormat) {
+		h.renderError(w, "Unknown webhook payload format", http.StatusBadRequest)
+		return
+	}
+	if project.NotifyEmail != "" {
+		if _, err := mail.ParseAddress(project.NotifyEmail); err != nil {
+			h.renderError(w, "Invalid notification email address", http.StatusBadRequest)
+			return
+		}
+	}
+
+	for _, key := range project.KeyURLs {
+		if u, err := url.Parse(key); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			h.renderError(w, "Key pages must be http or https URLs", http.StatusBadRequest)
+			return
+		}
+	}
+	if len(project.KeyURLs) > portfolio.MaxKeyURLs {
+		h.renderError(w, fmt.Sprintf("A project has at most %d key pages", portfolio.MaxKeyURLs), http.StatusBadRequest)
+		return
+	}
+	if project.ReportSchedule != "" {
+		schedule, err := monitor.ParseSchedule(project.ReportSchedule)
+		if err != nil {
+			h.renderError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(project.KeyURLs) == 0 {
+			h.renderError(w, "A scheduled report needs key pages", http.StatusBadRequest)
+			return
+		}
+		project.ReportNext = schedule.Next(time.Now())
+	}
+
+	if err := h.store.SaveProject(project); err != nil {
+		slog.Error("failed to save project", "project", project.Name, "error", err)
+		h.renderError(w, "Failed to save project", http.StatusInternalServerError)
+		return
+	}
+
+	slog.Info("project saved", "project", project.Name)
+	h.audit(webActor(r), storage.AuditProjectSave, project.Name, "")
+	http.Redirect(w, r, "/projects", http.StatusSeeOther)
+}
+
+// issuedKey is a newly issued API key with its secret, which is returned
+// once and cannot be recovered later
+type issuedKey struct {
+	Secret string          `json:"secret"`
+	Key    *storage.APIKey `json:"key"`
+}
+
+// ProjectKeyHandler issues an API key for a project. Keys file analyses
+// under their project and count against its quotas, so issuing one takes
+// the admin token.
+func (h *Handler) ProjectKeyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.adminAuthorized(w, r) {
+		return
+	}
+	if h.store == nil {
+		writeJSONError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	secret, key, err := h.store.CreateAPIKey(r.PathValue("name"))
+	if errors.Is(err, storage.ErrUnknownProject) {
+		writeJSONError(w, "Project not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to create API key", "project", r.PathValue("name"), "error", err)
+		writeJSONError(w, "Failed to create API key", http.StatusInternalServerError)
+		return
+	}
+
+	slog.Info("API key issued", "project", key.Project, "prefix", key.Prefix)
+	h.audit(adminActor, storage.AuditAPIKeyIssue, key.Project, "prefix="+key.Prefix)
+	writeJSON(w, http.StatusCreated, issuedKey{Secret: secret, Key: key})
+}
+
+// RevokeKeyHandler revokes the path's API key, which takes the admin token
+func (h *Handler) RevokeKeyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.adminAuthorized(w, r) {
+		return
+	}
+	if h.store == nil {
+		writeJSONError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	err := h.store.RevokeAPIKey(r.PathValue("id"))
+	if errors.Is(err, storage.ErrNotFound) {
+		writeJSONError(w, "API key not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to revoke API key", "id", r.PathValue("id"), "error", err)
+		writeJSONError(w, "Failed to revoke API key", http.StatusInternalServerError)
+		return
+	}
+
+	slog.Info("API key revoked", "id", r.PathValue("id"))
+	h.audit(adminActor, storage.AuditAPIKeyRevoke, r.PathValue("name"), "id="+r.PathValue("id"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// keyURLs reads one key page per line, skipping blanks and duplicates
+func (h *Handler) keyURLs(value string) []string {
+	var urls []string
+	for _, line := range strings.Split(value, "\n") {
+		line = h.analyzer.Redactor().Text(strings.TrimSpace(line))
+		if line != "" && !slices.Contains(urls, line) {
+			urls = append(urls, line)
+		}
+	}
+	return urls
+}
+
+func (h *Handler) renderProjects(w http.ResponseWriter) {
+	projects, err := h.store.Projects()
+	if err != nil {
+		slog.Error("failed to list projects", "error", err)
+		h.renderError(w, "Failed to load projects", http.StatusInternalServerError)
+		return
+	}
+
+	totals, err := h.store.Usage(storage.UsageFilter{})
+	if err != nil {
+		slog.Error("failed to load usage", "error", err)
+		h.renderError(w, "Failed to load projects", http.StatusInternalServerError)
+		return
+	}
+	usage := make(map[string]storage.UsageTotal, len(totals))
+	for _, total := range totals {
+		usage[total.APIKey] = total
+	}
+
+	views := make([]projectView, 0, len(projects))
+	for _, project := range projects {
+		keys, err := h.store.APIKeys(project.Name)
+		if err != nil {
+			slog.Error("failed to list API keys", "project", project.Name, "error", err)
+			h.renderError(w, "Failed to load projects", http.StatusInternalServerError)
+			return
+		}
+		view := projectView{Project: project}
+		for _, key := range keys {
+			view.Keys = append(view.Keys, keyView{APIKey: key, Usage: usage[key.ID]})
+		}
+		views = append(views, view)
+	}
+
+	data := struct {
+		Projects []projectView
+	}{
+		Projects: views,
+	}
+
+	if err := h.templates.ExecuteTemplate(w, "projects.html", data); err != nil {
+		slog.Error("template error", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
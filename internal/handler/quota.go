@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"website-analyzer/internal/storage"
+)
+
+// Quotas are the allowances of each API key per UTC calendar day and month
+type Quotas struct {
+	Daily   storage.Quota
+	Monthly storage.Quota
+}
+
+// SetQuotas enables quota enforcement for requests made with API keys
+func (h *Handler) SetQuotas(quotas Quotas) {
+	h.quotas = quotas
+}
+
+// quotaMetric is one limited metric of a quota window
+type quotaMetric struct {
+	Limit     int64 `json:"limit"`
+	Used      int64 `json:"used"`
+	Remaining int64 `json:"remaining"`
+}
+
+// quotaWindow is a quota period of an API key with its limited metrics
+type quotaWindow struct {
+	Period   string                 `json:"period"`
+	ResetsAt time.Time              `json:"resets_at"`
+	Quotas   map[string]quotaMetric `json:"quotas"`
+}
+
+// fits reports whether the window has room for n more analyses, counting
+// a page each, with some bytes left to download
+func (w quotaWindow) fits(n int64) bool {
+	for name, metric := range w.Quotas {
+		need := n
+		if name == "bytes" {
+			need = 1
+		}
+		if metric.Remaining < need {
+			return false
+		}
+	}
+	return true
+}
+
+// quotaWindows reports the key's usage against every configured window
+func (h *Handler) quotaWindows(keyID string, now time.Time) ([]quotaWindow, error) {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	periods := []struct {
+		name       string
+		limit      storage.Quota
+		start, end time.Time
+	}{
+		{"day", h.quotas.Daily, day, day.AddDate(0, 0, 1)},
+		{"month", h.quotas.Monthly, month, month.AddDate(0, 1, 0)},
+	}
+
+	var windows []quotaWindow
+	for _, period := range periods {
+		if period.limit.Unlimited() {
+			continue
+		}
+		totals, err := h.store.Usage(storage.UsageFilter{APIKey: keyID, Since: period.start})
+		if err != nil {
+			return nil, err
+		}
+		var used storage.UsageTotal
+		if len(totals) > 0 {
+			used = totals[0]
+		}
+
+		window := quotaWindow{Period: period.name, ResetsAt: period.end, Quotas: make(map[string]quotaMetric)}
+		for name, pair := range map[string][2]int64{
+			"analyses": {period.limit.Analyses, used.Analyses},
+			"pages":    {period.limit.Pages, used.Pages},
+			"bytes":    {period.limit.Bytes, used.BytesDownloaded},
+		} {
+			if limit := pair[0]; limit > 0 {
+				window.Quotas[name] = quotaMetric{Limit: limit, Used: pair[1], Remaining: max(limit-pair[1], 0)}
+			}
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+
+// enforceQuota sets the quota headers for an API key request and rejects it
+// with 429 unless every window has room for the request's analyses. While
+// quotas are configured requests need a key, so they can't go unmetered.
+func (h *Handler) enforceQuota(w http.ResponseWriter, keyID string, analyses int) bool {
+	if h.quotas == (Quotas{}) {
+		return true
+	}
+	if keyID == "" {
+		writeJSONError(w, "A bearer API key is required while quotas are enforced", http.StatusUnauthorized)
+		return false
+	}
+
+	windows, err := h.quotaWindows(keyID, time.Now())
+	if err != nil {
+		slog.Error("failed to check quota", "error", err)
+		writeJSONError(w, "Failed to check quota", http.StatusInternalServerError)
+		return false
+	}
+	setQuotaHeaders(w, windows)
+
+	var exceeded *quotaWindow
+	for i := range windows {
+		if !windows[i].fits(int64(analyses)) && (exceeded == nil || windows[i].ResetsAt.After(exceeded.ResetsAt)) {
+			exceeded = &windows[i]
+		}
+	}
+	if exceeded == nil {
+		return true
+	}
+
+	retry := int(time.Until(exceeded.ResetsAt).Seconds()) + 1
+	w.Header().Set("Retry-After", strconv.Itoa(retry))
+	msg := fmt.Sprintf("Quota exceeded for this %s", exceeded.Period)
+	if analyses > 1 && exceeded.fits(1) {
+		msg = fmt.Sprintf("A batch of %d URLs exceeds the quota left for this %s", analyses, exceeded.Period)
+	}
+	writeJSONError(w, msg, http.StatusTooManyRequests)
+	return false
+}
+
+// setQuotaHeaders reports the tightest analyses window in the
+// X-RateLimit-* headers and the smallest page and byte allowances in
+// X-Quota-* headers. Values describe the state before the current request.
+func setQuotaHeaders(w http.ResponseWriter, windows []quotaWindow) {
+	tightest := func(name string) (quotaMetric, time.Time, bool) {
+		var (
+			best  quotaMetric
+			reset time.Time
+			found bool
+		)
+		for _, window := range windows {
+			metric, ok := window.Quotas[name]
+			if ok && (!found || metric.Remaining < best.Remaining) {
+				best, reset, found = metric, window.ResetsAt, true
+			}
+		}
+		return best, reset, found
+	}
+
+	if metric, reset, ok := tightest("analyses"); ok {
+		w.Header().Set("X-RateLimit-Limit", strconv.FormatInt(metric.Limit, 10))
+		w.Header().Set("X-RateLimit-Remaining", strconv.FormatInt(metric.Remaining, 10))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	}
+	if metric, _, ok := tightest("pages"); ok {
+		w.Header().Set("X-Quota-Pages-Remaining", strconv.FormatInt(metric.Remaining, 10))
+	}
+	if metric, _, ok := tightest("bytes"); ok {
+		w.Header().Set("X-Quota-Bytes-Remaining", strconv.FormatInt(metric.Remaining, 10))
+	}
+}
+
+// QuotaHandler reports the bearer API key's remaining allowance per window
+func (h *Handler) QuotaHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.store == nil {
+		writeJSONError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	key, ok := h.apiKey(w, r)
+	if !ok {
+		return
+	}
+	if key == nil {
+		writeJSONError(w, "A bearer API key is required", http.StatusUnauthorized)
+		return
+	}
+
+	windows, err := h.quotaWindows(key.ID, time.Now())
+	if err != nil {
+		slog.Error("failed to check quota", "key", key.Prefix, "error", err)
+		writeJSONError(w, "Failed to check quota", http.StatusInternalServerError)
+		return
+	}
+	setQuotaHeaders(w, windows)
+
+	writeJSON(w, http.StatusOK, struct {
+		Project string        `json:"project"`
+		Key     string        `json:"key"`
+		Windows []quotaWindow `json:"windows"`
+	}{Project: key.Project, Key: key.Prefix, Windows: windows})
+}
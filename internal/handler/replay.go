@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"website-analyzer/internal/analyzer"
+	"website-analyzer/internal/storage"
+)
+
+// ReplayHandler re-analyzes the snapshot kept with a stored analysis using
+// the current checks, without fetching the page. The replay is returned;
+// with store=true it is also saved as a new analysis of the page, in the
+// original's project and tags.
+func (h *Handler) ReplayHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.store == nil {
+		writeJSONError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeJSONError(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	labels, actor, ok := h.apiLabels(w, r)
+	if !ok {
+		return
+	}
+
+	id := r.PathValue("id")
+	record, err := h.store.Get(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		writeJSONError(w, "Analysis not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to load analysis", "id", id, "error", err)
+		writeJSONError(w, "Failed to load analysis", http.StatusInternalServerError)
+		return
+	}
+	snapshot, err := h.store.Snapshot(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		writeJSONError(w, analyzer.ErrNoSnapshot.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to load snapshot", "id", id, "error", err)
+		writeJSONError(w, "Failed to load snapshot", http.StatusInternalServerError)
+		return
+	}
+
+	result, err := h.analyzer.Replay(r.Context(), snapshot, record.Result, analyzer.AnalyzeOptions{
+		Profile:      r.FormValue("profile"),
+		IncludeLinks: r.FormValue("include_links") == "true",
+		Flags:        r.FormValue("flags"),
+	})
+	if err != nil {
+		h.audit(actor, storage.AuditAnalysisReplay, record.URL, "id="+id+" failed: "+err.Error())
+		if r.Context().Err() == nil {
+			writeJSONError(w, err.Error(), analysisErrorStatus(err))
+		}
+		return
+	}
+	result.ReplayOf = id
+
+	if r.FormValue("store") != "true" {
+		h.audit(actor, storage.AuditAnalysisReplay, record.URL, "id="+id)
+		writeJSON(w, http.StatusOK, result)
+		return
+	}
+
+	// The saved replay keeps the snapshot so it can be replayed in turn
+	result.Snapshot = snapshot
+	saved, err := h.store.Save(record.URL, result, storage.Labels{Project: record.Project, Tags: record.Tags, APIKey: labels.APIKey})
+	if err != nil {
+		slog.Error("failed to save replay", "id", id, "error", err)
+		writeJSONError(w, "Failed to save analysis", http.StatusInternalServerError)
+		return
+	}
+	h.audit(actor, storage.AuditAnalysisReplay, record.URL, "id="+id+" saved="+saved.ID)
+	h.RunSavedHooks(saved.ID)
+	writeJSON(w, http.StatusOK, saved)
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"website-analyzer/internal/rescore"
+	"website-analyzer/internal/storage"
+)
+
+// RescoreHandler re-scores every stored snapshot with the current checks
+// and reports which overall scores moved. Original findings are kept; the
+// re-scoring is returned with each analysis as "rescored".
+func (h *Handler) RescoreHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.adminAuthorized(w, r) {
+		return
+	}
+	if h.store == nil {
+		writeJSONError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	summary, err := rescore.Run(r.Context(), h.store, h.analyzer)
+	if summary != nil {
+		h.audit(adminActor, storage.AuditRescore, "snapshots",
+			fmt.Sprintf("snapshots=%d rescored=%d changed=%d failed=%d",
+				summary.Snapshots, summary.Rescored, len(summary.Changes), summary.Failed))
+	}
+	if err != nil {
+		if r.Context().Err() == nil {
+			slog.Error("failed to rescore analyses", "error", err)
+			writeJSONError(w, "Failed to rescore analyses", http.StatusInternalServerError)
+		}
+		return
+	}
+	slog.Info("analyses rescored", "snapshots", summary.Snapshots, "rescored", summary.Rescored, "failed", summary.Failed)
+	writeJSON(w, http.StatusOK, summary)
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// staticAsset is a static file loaded at startup
+type staticAsset struct {
+	name    string
+	hash    string
+	content []byte
+	modTime time.Time
+}
+
+// StaticAssets serves the files of a directory under content-hashed names
+// such as style.3f2a1b9c0d.css, which are cached for maxAge and never
+// revalidated. The plain names keep working but must be revalidated.
+type StaticAssets struct {
+	maxAge time.Duration
+	// byName and byHashed index the same assets by plain and hashed name
+	byName   map[string]*staticAsset
+	byHashed map[string]*staticAsset
+}
+
+// NewStaticAssets loads and fingerprints every file under dir
+func NewStaticAssets(dir string, maxAge time.Duration) (*StaticAssets, error) {
+	assets := &StaticAssets{
+		maxAge:   maxAge,
+		byName:   make(map[string]*staticAsset),
+		byHashed: make(map[string]*staticAsset),
+	}
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		sum := sha256.Sum256(content)
+		asset := &staticAsset{
+			name:    filepath.ToSlash(rel),
+			hash:    hex.EncodeToString(sum[:5]),
+			content: content,
+			modTime: info.ModTime(),
+		}
+		assets.byName[asset.name] = asset
+		assets.byHashed[hashedName(asset.name, asset.hash)] = asset
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load static assets: %w", err)
+	}
+	return assets, nil
+}
+
+// hashedName inserts hash before the file extension
+func hashedName(name, hash string) string {
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hash + ext
+}
+
+// Path returns the URL of the named asset, fingerprinted when it exists
+func (a *StaticAssets) Path(name string) string {
+	if a != nil {
+		if asset, ok := a.byName[name]; ok {
+			return "/static/" + hashedName(asset.name, asset.hash)
+		}
+	}
+	return "/static/" + name
+}
+
+func (a *StaticAssets) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/static/")
+	if asset, ok := a.byHashed[name]; ok {
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d, immutable", int(a.maxAge.Seconds())))
+		a.serve(w, r, asset)
+		return
+	}
+	if asset, ok := a.byName[name]; ok {
+		w.Header().Set("Cache-Control", "no-cache")
+		a.serve(w, r, asset)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+func (a *StaticAssets) serve(w http.ResponseWriter, r *http.Request, asset *staticAsset) {
+	w.Header().Set("ETag", `"`+asset.hash+`"`)
+	http.ServeContent(w, r, asset.name, asset.modTime, bytes.NewReader(asset.content))
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"website-analyzer/internal/storage"
+)
+
+// usageResponse is the JSON body of the usage API
+type usageResponse struct {
+	Project string             `json:"project"`
+	Key     string             `json:"key"`
+	Since   string             `json:"since,omitempty"`
+	Usage   storage.UsageTotal `json:"usage"`
+}
+
+// UsageHandler reports the resources used by analyses run with the bearer
+// API key, optionally since a YYYY-MM-DD date
+func (h *Handler) UsageHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.store == nil {
+		writeJSONError(w, "Analysis history is disabled", http.StatusNotFound)
+		return
+	}
+
+	key, ok := h.apiKey(w, r)
+	if !ok {
+		return
+	}
+	if key == nil {
+		writeJSONError(w, "A bearer API key is required", http.StatusUnauthorized)
+		return
+	}
+
+	filter := storage.UsageFilter{APIKey: key.ID}
+	since := r.URL.Query().Get("since")
+	if since != "" {
+		t, err := time.Parse(time.DateOnly, since)
+		if err != nil {
+			writeJSONError(w, "since must be a YYYY-MM-DD date", http.StatusBadRequest)
+			return
+		}
+		filter.Since = t
+	}
+
+	totals, err := h.store.Usage(filter)
+	if err != nil {
+		slog.Error("failed to load usage", "key", key.Prefix, "error", err)
+		writeJSONError(w, "Failed to load usage", http.StatusInternalServerError)
+		return
+	}
+
+	resp := usageResponse{Project: key.Project, Key: key.Prefix, Since: since, Usage: storage.UsageTotal{APIKey: key.ID}}
+	if len(totals) > 0 {
+		resp.Usage = totals[0]
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,13 @@
+package handler
+
+import "net/http"
+
+// VersionHandler reports the analyzer build and the features the server
+// enables by default
+func (h *Handler) VersionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, h.analyzer.Info())
+}
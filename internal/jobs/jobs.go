@@ -0,0 +1,261 @@
+// Package jobs runs crawls and batches in the background on a fixed number
+// of workers, queued by priority, and tracks their progress for the jobs
+// API, its event stream and the progress page. Jobs queued with Submit
+// can be shared between replicas through a Queue.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+// Job kinds
+const (
+	KindCrawl = "crawl"
+	KindBatch = "batch"
+)
+
+// Job states
+const (
+	StateQueued    = "queued"
+	StateRunning   = "running"
+	StateCompleted = "completed"
+	StateFailed    = "failed"
+)
+
+// Retention is how long a finished job stays available
+const Retention = time.Hour
+
+// ErrNotFound is returned for unknown or expired jobs
+var ErrNotFound = errors.New("job not found")
+
+// Func is the work of a job. It reports progress to progress, usually by
+// passing it to analyzer.WithProgress, and returns the job's result.
+type Func func(ctx context.Context, progress *Tracker) (any, error)
+
+// Manager runs jobs on its workers and keeps them until Retention after
+// they finish
+type Manager struct {
+	ctx     context.Context
+	now     func() time.Time
+	workers int
+	// timeout bounds how long a job may run once started; zero is none
+	timeout time.Duration
+	// mu guards the jobs, the queue and the waiters of queued jobs
+	mu      sync.Mutex
+	jobs    map[string]*job
+	running int
+	queue   waitQueue
+	seq     uint64
+	// handlers run the kinds of jobs queued with Submit, and shared, when
+	// set, holds those jobs instead of this process
+	handlers map[string]TaskFunc
+	shared   Queue
+}
+
+// job is a queued, running or finished job and whoever is watching it
+type job struct {
+	mu          sync.Mutex
+	info        models.Job
+	tracker     *Tracker
+	subscribers map[chan struct{}]struct{}
+	waiter      *waiter
+}
+
+// NewManager returns a manager running up to workers jobs at once, at
+// least one, whose jobs are cancelled with ctx
+func NewManager(ctx context.Context, workers int) *Manager {
+	return &Manager{ctx: ctx, now: time.Now, workers: max(1, workers), jobs: make(map[string]*job), handlers: make(map[string]TaskFunc)}
+}
+
+// SetTimeout bounds how long each job may run once a worker picks it up;
+// its context is cancelled after timeout. Zero, the default, lets jobs run
+// until they finish.
+func (m *Manager) SetTimeout(timeout time.Duration) {
+	m.timeout = timeout
+}
+
+// Start queues fn as a job of kind on target at priority and returns the
+// job as queued. It runs in the background once a worker is free.
+func (m *Manager) Start(kind, target string, priority Priority, fn Func) (models.Job, error) {
+	id, err := newID()
+	if err != nil {
+		return models.Job{}, err
+	}
+
+	now := m.now()
+	j := &job{
+		info: models.Job{
+			ID:        id,
+			Kind:      kind,
+			Target:    target,
+			Priority:  priority.String(),
+			State:     StateQueued,
+			CreatedAt: now,
+		},
+		subscribers: make(map[chan struct{}]struct{}),
+	}
+	j.tracker = newTracker(m.now, j.notify)
+
+	m.mu.Lock()
+	m.prune()
+	m.jobs[id] = j
+	m.mu.Unlock()
+
+	go func() {
+		release, err := m.acquire(m.ctx, priority, j)
+		if err != nil {
+			m.finish(j, nil, err)
+			return
+		}
+		defer release()
+
+		started := m.now()
+		j.mu.Lock()
+		j.info.State = StateRunning
+		j.info.StartedAt = &started
+		j.mu.Unlock()
+		j.notify()
+
+		result, err := m.run(fn, j.tracker)
+		m.finish(j, result, err)
+	}()
+
+	return m.snapshot(j), nil
+}
+
+// Get returns the job with id
+func (m *Manager) Get(id string) (models.Job, error) {
+	m.mu.Lock()
+	m.prune()
+	j, ok := m.jobs[id]
+	m.mu.Unlock()
+	if !ok && m.shared != nil {
+		return m.getShared(id)
+	}
+	if !ok {
+		return models.Job{}, ErrNotFound
+	}
+	return m.snapshot(j), nil
+}
+
+// Subscribe returns a channel that receives a value whenever the job with
+// id changes, and a function to stop. Updates are coalesced; read the job
+// with Get after each.
+func (m *Manager) Subscribe(id string) (<-chan struct{}, func(), error) {
+	m.mu.Lock()
+	j, ok := m.jobs[id]
+	m.mu.Unlock()
+	if !ok && m.shared != nil {
+		return m.subscribeShared(id)
+	}
+	if !ok {
+		return nil, nil, ErrNotFound
+	}
+
+	ch := make(chan struct{}, 1)
+	j.mu.Lock()
+	j.subscribers[ch] = struct{}{}
+	j.mu.Unlock()
+
+	unsubscribe := func() {
+		j.mu.Lock()
+		delete(j.subscribers, ch)
+		j.mu.Unlock()
+	}
+	return ch, unsubscribe, nil
+}
+
+// run runs fn within the manager's timeout, explaining a job cut short
+// by it
+func (m *Manager) run(fn Func, tracker *Tracker) (any, error) {
+	if m.timeout <= 0 {
+		return fn(m.ctx, tracker)
+	}
+	ctx, cancel := context.WithTimeout(m.ctx, m.timeout)
+	defer cancel()
+
+	result, err := fn(ctx, tracker)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) && m.ctx.Err() == nil {
+		err = fmt.Errorf("job exceeded its %s deadline: %w", m.timeout, err)
+	}
+	return result, err
+}
+
+func (m *Manager) finish(j *job, result any, err error) {
+	now := m.now()
+	j.mu.Lock()
+	j.info.FinishedAt = &now
+	if err != nil {
+		j.info.State = StateFailed
+		j.info.Error = err.Error()
+	} else {
+		j.info.State = StateCompleted
+		j.info.Result = result
+	}
+	j.mu.Unlock()
+	j.notify()
+}
+
+// prune drops jobs that finished more than Retention ago; m.mu is held
+func (m *Manager) prune() {
+	cutoff := m.now().Add(-Retention)
+	for id, j := range m.jobs {
+		j.mu.Lock()
+		expired := j.info.FinishedAt != nil && j.info.FinishedAt.Before(cutoff)
+		j.mu.Unlock()
+		if expired {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// snapshot copies the job with its current progress and queue position
+func (m *Manager) snapshot(j *job) models.Job {
+	progress := j.tracker.Progress()
+	m.mu.Lock()
+	position := m.position(j)
+	m.mu.Unlock()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	info := j.info
+	info.Progress = progress
+	info.QueuePosition = position
+	if info.State == StateCompleted {
+		info.Progress.Percent = 100
+	}
+	if info.State == StateCompleted || info.State == StateFailed {
+		zero := 0.0
+		info.Progress.ETASeconds = &zero
+	}
+	return info
+}
+
+// notify wakes every subscriber without waiting on slow ones
+func (j *job) notify() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for ch := range j.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// newID returns a random 16-byte hex identifier
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
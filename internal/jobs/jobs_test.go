@@ -0,0 +1,256 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitFinished polls until the job has completed or failed
+func waitFinished(t *testing.T, m *Manager, id string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := m.Get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.State == StateCompleted || job.State == StateFailed {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Job %s did not finish", id)
+}
+
+func TestManagerRunsJobs(t *testing.T) {
+	m := NewManager(context.Background(), 2)
+
+	release := make(chan struct{})
+	job, err := m.Start(KindCrawl, "https://example.com/", PriorityAPI, func(ctx context.Context, progress *Tracker) (any, error) {
+		progress.AddPages(2)
+		progress.PageDone(time.Millisecond)
+		<-release
+		progress.PageDone(time.Millisecond)
+		return "done", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.ID == "" || job.State != StateQueued || job.Kind != KindCrawl || job.Priority != "api" {
+		t.Fatalf("Unexpected started job %+v", job)
+	}
+
+	updates, unsubscribe, err := m.Subscribe(job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unsubscribe()
+
+	close(release)
+	waitFinished(t, m, job.ID)
+	select {
+	case <-updates:
+	default:
+		t.Error("Expected subscribers to hear about the job finishing")
+	}
+
+	job, _ = m.Get(job.ID)
+	if job.State != StateCompleted || job.Result != "done" || job.StartedAt == nil || job.FinishedAt == nil {
+		t.Errorf("Unexpected finished job %+v", job)
+	}
+	if job.Progress.Percent != 100 || job.Progress.ETASeconds == nil || *job.Progress.ETASeconds != 0 || job.Progress.PagesDone != 2 {
+		t.Errorf("Expected finished progress, got %+v", job.Progress)
+	}
+
+	failed, _ := m.Start(KindBatch, "2 URLs", PriorityAPI, func(ctx context.Context, progress *Tracker) (any, error) {
+		return nil, errors.New("boom")
+	})
+	waitFinished(t, m, failed.ID)
+	if failed, _ = m.Get(failed.ID); failed.State != StateFailed || failed.Error != "boom" || failed.Result != nil {
+		t.Errorf("Unexpected failed job %+v", failed)
+	}
+
+	if _, err := m.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if _, _, err := m.Subscribe("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestManagerExpiresFinishedJobs(t *testing.T) {
+	m := NewManager(context.Background(), 2)
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	job, _ := m.Start(KindCrawl, "https://example.com/", PriorityAPI, func(ctx context.Context, progress *Tracker) (any, error) {
+		return nil, nil
+	})
+	waitFinished(t, m, job.ID)
+
+	now = now.Add(Retention - time.Minute)
+	if _, err := m.Get(job.ID); err != nil {
+		t.Errorf("Expected the job to be kept within retention, got %v", err)
+	}
+	now = now.Add(2 * time.Minute)
+	if _, err := m.Get(job.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected the job to expire, got %v", err)
+	}
+}
+
+func TestManagerCancelsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := NewManager(ctx, 2)
+
+	job, _ := m.Start(KindCrawl, "https://example.com/", PriorityAPI, func(ctx context.Context, progress *Tracker) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	cancel()
+	waitFinished(t, m, job.ID)
+	if job, _ = m.Get(job.ID); job.State != StateFailed {
+		t.Errorf("Expected a cancelled job to fail, got %+v", job)
+	}
+}
+
+func TestManagerQueuesByPriority(t *testing.T) {
+	m := NewManager(context.Background(), 1)
+
+	// A scheduled run holds the only worker
+	release, err := m.Acquire(context.Background(), PriorityScheduled)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var order []string
+	run := func(name string) Func {
+		return func(ctx context.Context, progress *Tracker) (any, error) {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return nil, nil
+		}
+	}
+
+	scheduled := make(chan struct{})
+	go func() {
+		release, err := m.Acquire(context.Background(), PriorityScheduled)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		mu.Lock()
+		order = append(order, "scheduled")
+		mu.Unlock()
+		release()
+		close(scheduled)
+	}()
+	waitQueued(t, m, 1)
+
+	batch, _ := m.Start(KindBatch, "2 URLs", PriorityAPI, run("batch"))
+	waitQueued(t, m, 2)
+	crawl, _ := m.Start(KindCrawl, "https://example.com/", PriorityInteractive, run("crawl"))
+	waitQueued(t, m, 3)
+
+	if job, _ := m.Get(crawl.ID); job.State != StateQueued || job.QueuePosition != 1 {
+		t.Errorf("Expected the interactive job first in the queue, got %+v", job)
+	}
+	if job, _ := m.Get(batch.ID); job.QueuePosition != 2 {
+		t.Errorf("Expected the API job second in the queue, got %+v", job)
+	}
+
+	release()
+	release() // releasing twice frees the worker once
+	waitFinished(t, m, crawl.ID)
+	waitFinished(t, m, batch.ID)
+	<-scheduled
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"crawl", "batch", "scheduled"}; !slices.Equal(order, want) {
+		t.Errorf("Ran %v, want %v", order, want)
+	}
+	if m.running != 0 {
+		t.Errorf("Expected every worker to be free, %d are running", m.running)
+	}
+}
+
+func TestManagerTimeout(t *testing.T) {
+	m := NewManager(context.Background(), 1)
+	m.SetTimeout(20 * time.Millisecond)
+
+	job, _ := m.Start(KindCrawl, "https://example.com/", PriorityAPI, func(ctx context.Context, progress *Tracker) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	waitFinished(t, m, job.ID)
+	if job, _ = m.Get(job.ID); job.State != StateFailed || !strings.Contains(job.Error, "exceeded its 20ms deadline") {
+		t.Errorf("Expected the job to fail with its deadline, got %+v", job)
+	}
+}
+
+func TestManagerSubmit(t *testing.T) {
+	m := NewManager(context.Background(), 1)
+	m.Handle(KindBatch, func(ctx context.Context, payload json.RawMessage, progress *Tracker) (any, error) {
+		var urls []string
+		if err := json.Unmarshal(payload, &urls); err != nil {
+			return nil, err
+		}
+		return len(urls), nil
+	})
+
+	job, err := m.Submit(KindBatch, "2 URLs", PriorityAPI, []string{"https://a.example/", "https://b.example/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFinished(t, m, job.ID)
+	if job, _ = m.Get(job.ID); job.State != StateCompleted || job.Result != 2 {
+		t.Errorf("Expected the handler's result, got %+v", job)
+	}
+
+	job, _ = m.Submit(KindCrawl, "https://example.com/", PriorityAPI, "https://example.com/")
+	waitFinished(t, m, job.ID)
+	if job, _ = m.Get(job.ID); job.State != StateFailed || !strings.Contains(job.Error, "no handler for crawl jobs") {
+		t.Errorf("Expected a kind without a handler to fail, got %+v", job)
+	}
+}
+
+func TestManagerAcquireCancelled(t *testing.T) {
+	m := NewManager(context.Background(), 1)
+	release, _ := m.Acquire(context.Background(), PriorityAPI)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.Acquire(ctx, PriorityInteractive); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the wait to end with ctx, got %v", err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.queue.Len() != 0 {
+		t.Errorf("Expected the cancelled waiter to leave the queue, %d remain", m.queue.Len())
+	}
+}
+
+// waitQueued polls until n waiters are queued
+func waitQueued(t *testing.T, m *Manager, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		m.mu.Lock()
+		queued := m.queue.Len()
+		m.mu.Unlock()
+		if queued == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("Expected %d queued waiters", n)
+}
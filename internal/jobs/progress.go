@@ -0,0 +1,118 @@
+package jobs
+
+import (
+	"sync"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+// latencyWindow is how many recently finished pages and links the rolling
+// averages cover
+const latencyWindow = 50
+
+// Tracker counts a job's pages and links as the analyzer reports them; it
+// is an analyzer.ProgressReporter
+type Tracker struct {
+	mu         sync.Mutex
+	pagesDone  int
+	pagesTotal int
+	linksDone  int
+	linksTotal int
+	// latencies and finished cover the last latencyWindow pages and links
+	latencies []time.Duration
+	finished  []time.Time
+	now       func() time.Time
+	changed   func()
+}
+
+func newTracker(now func() time.Time, changed func()) *Tracker {
+	return &Tracker{now: now, changed: changed}
+}
+
+// AddPages announces n more pages
+func (t *Tracker) AddPages(n int) {
+	t.update(func() { t.pagesTotal += n })
+}
+
+// PageDone records a finished page
+func (t *Tracker) PageDone(elapsed time.Duration) {
+	t.update(func() {
+		t.pagesDone++
+		t.record(elapsed)
+	})
+}
+
+// AddLinks announces n more links
+func (t *Tracker) AddLinks(n int) {
+	t.update(func() { t.linksTotal += n })
+}
+
+// LinkChecked records a checked link
+func (t *Tracker) LinkChecked(elapsed time.Duration) {
+	t.update(func() {
+		t.linksDone++
+		t.record(elapsed)
+	})
+}
+
+func (t *Tracker) update(fn func()) {
+	t.mu.Lock()
+	fn()
+	t.mu.Unlock()
+	if t.changed != nil {
+		t.changed()
+	}
+}
+
+// record adds a finished page or link to the rolling window; t.mu is held
+func (t *Tracker) record(elapsed time.Duration) {
+	t.latencies = append(t.latencies, elapsed)
+	t.finished = append(t.finished, t.now())
+	if len(t.latencies) > latencyWindow {
+		t.latencies = t.latencies[1:]
+		t.finished = t.finished[1:]
+	}
+}
+
+// Progress reports the job so far. Links of pages not analyzed yet are
+// estimated from the links per finished page, and the ETA is the remaining
+// work at the rolling average time between finished pages and links, so
+// it accounts for work running concurrently.
+func (t *Tracker) Progress() models.JobProgress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p := models.JobProgress{
+		PagesDone:  t.pagesDone,
+		PagesTotal: t.pagesTotal,
+		LinksDone:  t.linksDone,
+		LinksTotal: t.linksTotal,
+	}
+
+	var unseenLinks int
+	if t.pagesDone > 0 {
+		unseenLinks = (t.pagesTotal - t.pagesDone) * t.linksTotal / t.pagesDone
+	}
+	done := t.pagesDone + t.linksDone
+	total := t.pagesTotal + t.linksTotal + unseenLinks
+	if total > 0 {
+		p.Percent = min(100, 100*float64(done)/float64(total))
+	}
+
+	if len(t.latencies) > 0 {
+		var sum time.Duration
+		for _, latency := range t.latencies {
+			sum += latency
+		}
+		p.AvgLatencyMs = (sum / time.Duration(len(t.latencies))).Milliseconds()
+	}
+
+	// The window runs up to now so a stalled job's ETA grows
+	if n := len(t.finished); n >= 2 {
+		perUnit := t.now().Sub(t.finished[0]) / time.Duration(n-1)
+		eta := (time.Duration(max(0, total-done)) * perUnit).Seconds()
+		p.ETASeconds = &eta
+	}
+	return p
+}
@@ -0,0 +1,73 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrackerProgress(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	changes := 0
+	tracker := newTracker(func() time.Time { return now }, func() { changes++ })
+
+	p := tracker.Progress()
+	if p.Percent != 0 || p.ETASeconds != nil {
+		t.Errorf("Expected no progress or ETA before any work, got %+v", p)
+	}
+
+	tracker.AddPages(4)
+	tracker.AddLinks(10)
+	for range 10 {
+		now = now.Add(100 * time.Millisecond)
+		tracker.LinkChecked(200 * time.Millisecond)
+	}
+	now = now.Add(100 * time.Millisecond)
+	tracker.PageDone(time.Second)
+
+	p = tracker.Progress()
+	if p.PagesDone != 1 || p.PagesTotal != 4 || p.LinksDone != 10 || p.LinksTotal != 10 {
+		t.Errorf("Unexpected counts %+v", p)
+	}
+	// 11 of 4 pages + 10 links + 30 links estimated for the other pages
+	if want := 100 * 11.0 / 44.0; p.Percent != want {
+		t.Errorf("Percent = %v, want %v", p.Percent, want)
+	}
+	// 33 units left at 100ms each
+	if p.ETASeconds == nil || *p.ETASeconds < 3.29 || *p.ETASeconds > 3.31 {
+		t.Errorf("Expected an ETA of 3.3s, got %v", p.ETASeconds)
+	}
+	if want := (10*200 + 1000) / 11; p.AvgLatencyMs != int64(want) {
+		t.Errorf("AvgLatencyMs = %d, want %d", p.AvgLatencyMs, want)
+	}
+	if changes != 13 {
+		t.Errorf("Expected every report to signal a change, got %d", changes)
+	}
+
+	// A stall stretches the estimate
+	now = now.Add(time.Second)
+	if stalled := tracker.Progress(); *stalled.ETASeconds <= *p.ETASeconds {
+		t.Errorf("Expected a stalled job's ETA to grow, got %v after %v", *stalled.ETASeconds, *p.ETASeconds)
+	}
+}
+
+func TestTrackerWindow(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := newTracker(func() time.Time { return now }, nil)
+	tracker.AddLinks(200)
+	for range 100 {
+		now = now.Add(time.Second)
+		tracker.LinkChecked(time.Second)
+	}
+	for range 50 {
+		now = now.Add(10 * time.Millisecond)
+		tracker.LinkChecked(10 * time.Millisecond)
+	}
+
+	p := tracker.Progress()
+	if p.AvgLatencyMs != 10 {
+		t.Errorf("Expected only recent links in the average, got %dms", p.AvgLatencyMs)
+	}
+	if *p.ETASeconds > 1 {
+		t.Errorf("Expected the ETA to follow the recent rate, got %vs", *p.ETASeconds)
+	}
+}
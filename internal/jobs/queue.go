@@ -0,0 +1,167 @@
+package jobs
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+)
+
+// Priority orders work waiting for a worker; lower values run first
+type Priority int
+
+const (
+	// PriorityInteractive is work someone is watching in the web UI
+	PriorityInteractive Priority = iota
+	// PriorityAPI is work submitted through the API
+	PriorityAPI
+	// PriorityScheduled is work nobody is waiting on, such as monitor runs
+	PriorityScheduled
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityInteractive:
+		return "interactive"
+	case PriorityAPI:
+		return "api"
+	case PriorityScheduled:
+		return "scheduled"
+	}
+	return "unknown"
+}
+
+// waiter is work queued for a worker. index is its place in the heap, or
+// -1 once it has been granted one.
+type waiter struct {
+	priority Priority
+	seq      uint64
+	index    int
+	ready    chan struct{}
+	job      *job
+}
+
+// before reports whether w runs before other: higher priority first, then
+// in submission order
+func (w *waiter) before(other *waiter) bool {
+	if w.priority != other.priority {
+		return w.priority < other.priority
+	}
+	return w.seq < other.seq
+}
+
+// waitQueue is a container/heap of waiters
+type waitQueue []*waiter
+
+func (q waitQueue) Len() int           { return len(q) }
+func (q waitQueue) Less(i, j int) bool { return q[i].before(q[j]) }
+
+func (q waitQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waitQueue) Push(x any) {
+	w := x.(*waiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *waitQueue) Pop() any {
+	old := *q
+	w := old[len(old)-1]
+	old[len(old)-1] = nil
+	w.index = -1
+	*q = old[:len(old)-1]
+	return w
+}
+
+// Acquire waits for one of the manager's workers, letting work of higher
+// priority go first, and returns the function that frees it again. It
+// fails only when ctx is done first.
+func (m *Manager) Acquire(ctx context.Context, priority Priority) (func(), error) {
+	return m.acquire(ctx, priority, nil)
+}
+
+// acquire is Acquire for j, whose queue position follows its waiter
+func (m *Manager) acquire(ctx context.Context, priority Priority, j *job) (func(), error) {
+	m.mu.Lock()
+	if m.running < m.workers && m.queue.Len() == 0 {
+		m.running++
+		m.mu.Unlock()
+		return m.releaser(), nil
+	}
+	w := &waiter{priority: priority, seq: m.seq, ready: make(chan struct{}), job: j}
+	m.seq++
+	heap.Push(&m.queue, w)
+	if j != nil {
+		j.waiter = w
+	}
+	m.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return m.releaser(), nil
+	case <-ctx.Done():
+		m.mu.Lock()
+		granted := w.index < 0
+		if !granted {
+			heap.Remove(&m.queue, w.index)
+		}
+		m.mu.Unlock()
+		if granted {
+			m.releaser()()
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// releaser returns a function freeing a worker once, however often it is
+// called
+func (m *Manager) releaser() func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.mu.Lock()
+			m.running--
+			m.dispatch()
+			m.mu.Unlock()
+		})
+	}
+}
+
+// dispatch hands free workers to the first waiters and tells the jobs
+// still queued that they moved up; m.mu is held
+func (m *Manager) dispatch() {
+	moved := false
+	for m.running < m.workers && m.queue.Len() > 0 {
+		w := heap.Pop(&m.queue).(*waiter)
+		m.running++
+		close(w.ready)
+		moved = true
+	}
+	if !moved {
+		return
+	}
+	for _, w := range m.queue {
+		if w.job != nil {
+			w.job.notify()
+		}
+	}
+}
+
+// position is the 1-based place of j in the queue, or 0 when it isn't
+// waiting; m.mu is held
+func (m *Manager) position(j *job) int {
+	w := j.waiter
+	if w == nil || w.index < 0 {
+		return 0
+	}
+	position := 1
+	for _, other := range m.queue {
+		if other.before(w) {
+			position++
+		}
+	}
+	return position
+}
@@ -0,0 +1,248 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+// sharedSaveInterval is the least time between two saves of a running
+// job's progress to a shared queue
+const sharedSaveInterval = time.Second
+
+// sharedPollInterval is how often subscribers to a job in a shared queue
+// look for changes, and how long workers back off after a queue error
+const sharedPollInterval = time.Second
+
+// sharedActiveTTL is how long a queued or running job stays in a shared
+// queue, so the jobs of a replica that went away expire eventually
+const sharedActiveTTL = 24 * time.Hour
+
+// Task is a job waiting in a shared queue: Kind names the TaskFunc that
+// runs it and Payload is that function's JSON input
+type Task struct {
+	ID       string          `json:"id"`
+	Kind     string          `json:"kind"`
+	Priority Priority        `json:"priority"`
+	Payload  json.RawMessage `json:"payload"`
+}
+
+// Queue shares jobs between replicas: the tasks waiting for a worker of
+// any of them, and the state of every job so each replica can report on
+// jobs another one runs
+type Queue interface {
+	// Push queues task behind the tasks of the same or higher priority
+	Push(ctx context.Context, task Task) error
+	// Pop takes the first task, waiting until there is one or ctx is done
+	Pop(ctx context.Context) (Task, error)
+	// Position is the 1-based place of the task with id in the queue, or
+	// 0 when it isn't waiting
+	Position(ctx context.Context, id string) (int, error)
+	// Save stores job until ttl from now
+	Save(ctx context.Context, job models.Job, ttl time.Duration) error
+	// Load returns the job with id, or ErrNotFound. Its Result is the
+	// result's JSON encoding as a json.RawMessage.
+	Load(ctx context.Context, id string) (models.Job, error)
+}
+
+// TaskFunc is the work of a kind of job queued with Submit; payload is the
+// JSON encoding of the value it was submitted with
+type TaskFunc func(ctx context.Context, payload json.RawMessage, progress *Tracker) (any, error)
+
+// Handle runs the jobs of kind queued with Submit with fn
+func (m *Manager) Handle(kind string, fn TaskFunc) {
+	m.mu.Lock()
+	m.handlers[kind] = fn
+	m.mu.Unlock()
+}
+
+// SetQueue shares the jobs queued with Submit through queue: the manager's
+// workers take them in turn with the other replicas', and Get and Subscribe
+// report them whichever replica runs them. Jobs queued with Start stay in
+// this process. Call it once, after registering every kind with Handle.
+func (m *Manager) SetQueue(queue Queue) {
+	m.shared = queue
+	for range m.workers {
+		go m.work()
+	}
+}
+
+// Submit queues a job of kind on target at priority, run by the kind's
+// TaskFunc with payload, and returns the job as queued. With a shared
+// queue any replica may run it; otherwise it runs here like Start.
+func (m *Manager) Submit(kind, target string, priority Priority, payload any) (models.Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return models.Job{}, fmt.Errorf("failed to encode job: %w", err)
+	}
+	if m.shared == nil {
+		return m.Start(kind, target, priority, func(ctx context.Context, progress *Tracker) (any, error) {
+			return m.runTask(ctx, kind, data, progress)
+		})
+	}
+
+	id, err := newID()
+	if err != nil {
+		return models.Job{}, err
+	}
+	job := models.Job{
+		ID:        id,
+		Kind:      kind,
+		Target:    target,
+		Priority:  priority.String(),
+		State:     StateQueued,
+		CreatedAt: m.now(),
+	}
+	if err := m.shared.Save(m.ctx, job, sharedActiveTTL); err != nil {
+		return models.Job{}, fmt.Errorf("failed to save job: %w", err)
+	}
+	if err := m.shared.Push(m.ctx, Task{ID: id, Kind: kind, Priority: priority, Payload: data}); err != nil {
+		return models.Job{}, fmt.Errorf("failed to queue job: %w", err)
+	}
+	if position, err := m.shared.Position(m.ctx, id); err == nil {
+		job.QueuePosition = position
+	}
+	return job, nil
+}
+
+// runTask runs payload with the TaskFunc registered for kind
+func (m *Manager) runTask(ctx context.Context, kind string, payload json.RawMessage, progress *Tracker) (any, error) {
+	m.mu.Lock()
+	fn, ok := m.handlers[kind]
+	m.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("no handler for %s jobs", kind)
+	}
+	return fn(ctx, payload, progress)
+}
+
+// work runs tasks from the shared queue until the manager's context ends
+func (m *Manager) work() {
+	for {
+		task, err := m.shared.Pop(m.ctx)
+		if m.ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			slog.Warn("failed to take a job from the queue", "error", err)
+			select {
+			case <-m.ctx.Done():
+				return
+			case <-time.After(sharedPollInterval):
+			}
+			continue
+		}
+		m.runShared(task)
+	}
+}
+
+// runShared runs task on one of the manager's workers, saving its job to
+// the shared queue as it starts, at most every sharedSaveInterval as it
+// progresses, and when it finishes
+func (m *Manager) runShared(task Task) {
+	ctx := context.WithoutCancel(m.ctx)
+	info, err := m.shared.Load(ctx, task.ID)
+	if err != nil {
+		slog.Warn("dropped a queued job without state", "job", task.ID, "error", err)
+		return
+	}
+
+	j := &job{info: info, subscribers: make(map[chan struct{}]struct{})}
+	var mu sync.Mutex
+	var saved time.Time
+	finished := false
+	save := func(ttl time.Duration) {
+		if err := m.shared.Save(ctx, m.snapshot(j), ttl); err != nil {
+			slog.Warn("failed to save job", "job", task.ID, "error", err)
+		}
+		saved = m.now()
+	}
+	j.tracker = newTracker(m.now, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if !finished && m.now().Sub(saved) >= sharedSaveInterval {
+			save(sharedActiveTTL)
+		}
+	})
+	done := func(result any, err error) {
+		m.finish(j, result, err)
+		mu.Lock()
+		defer mu.Unlock()
+		finished = true
+		save(Retention)
+	}
+
+	release, err := m.acquire(m.ctx, task.Priority, nil)
+	if err != nil {
+		done(nil, err)
+		return
+	}
+	defer release()
+
+	started := m.now()
+	j.mu.Lock()
+	j.info.State = StateRunning
+	j.info.StartedAt = &started
+	j.mu.Unlock()
+	mu.Lock()
+	save(sharedActiveTTL)
+	mu.Unlock()
+
+	done(m.run(func(ctx context.Context, progress *Tracker) (any, error) {
+		return m.runTask(ctx, task.Kind, task.Payload, progress)
+	}, j.tracker))
+}
+
+// getShared returns the job with id from the shared queue with its place
+// in it
+func (m *Manager) getShared(id string) (models.Job, error) {
+	job, err := m.shared.Load(m.ctx, id)
+	if err != nil {
+		return models.Job{}, err
+	}
+	if job.State == StateQueued {
+		if position, err := m.shared.Position(m.ctx, id); err == nil {
+			job.QueuePosition = position
+		}
+	}
+	return job, nil
+}
+
+// subscribeShared is Subscribe for a job in the shared queue, which is
+// polled every sharedPollInterval for changes
+func (m *Manager) subscribeShared(id string) (<-chan struct{}, func(), error) {
+	last, err := m.getShared(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch := make(chan struct{}, 1)
+	ctx, cancel := context.WithCancel(m.ctx)
+	go func() {
+		ticker := time.NewTicker(sharedPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			job, err := m.getShared(id)
+			if err != nil || reflect.DeepEqual(job, last) {
+				continue
+			}
+			last = job
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return ch, cancel, nil
+}
@@ -0,0 +1,216 @@
+// Package linkrot follows the external links of stored analyses over time
+// and reports which ones died, which domains decay fastest and how long
+// links last before breaking
+package linkrot
+
+import (
+	"cmp"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"website-analyzer/internal/models"
+	"website-analyzer/internal/storage"
+)
+
+// DefaultMonths is the period a report covers when none is given
+const DefaultMonths = 12
+
+// MaxMonths bounds the period a report covers
+const MaxMonths = 120
+
+// maxDeadLinks bounds the dead links a report lists, most recent first
+const maxDeadLinks = 100
+
+// Report is the link rot of a page or project over a period. A link died
+// when an analysis found it broken after an earlier one found it working;
+// links broken from their first check are counted separately, since when
+// they broke is unknown.
+type Report struct {
+	URL         string    `json:"url,omitempty"`
+	Project     string    `json:"project,omitempty"`
+	Months      int       `json:"months"`
+	From        time.Time `json:"from"`
+	GeneratedAt time.Time `json:"generated_at"`
+	// Analyses counts the stored analyses whose external links were
+	// checked
+	Analyses int `json:"analyses"`
+	Pages    int `json:"pages"`
+	// Links counts the distinct external links of each page
+	Links           int `json:"links"`
+	Died            int `json:"died"`
+	BrokenWhenFound int `json:"broken_when_found"`
+	// AverageDaysToBreakage is the mean time from a dead link's first
+	// working check to the analysis that found it broken
+	AverageDaysToBreakage float64     `json:"average_days_to_breakage"`
+	Trend                 []Month     `json:"trend"`
+	Domains               []DomainRot `json:"domains,omitempty"`
+	Dead                  []DeadLink  `json:"dead,omitempty"`
+}
+
+// Month counts the links found dead in a calendar month
+type Month struct {
+	Month string `json:"month"`
+	Died  int    `json:"died"`
+}
+
+// DomainRot is the decay of the links to one domain. Domains with dead
+// links are listed, fastest decaying (highest share of links dead) first.
+type DomainRot struct {
+	Domain                string  `json:"domain"`
+	Links                 int     `json:"links"`
+	Died                  int     `json:"died"`
+	RotRate               float64 `json:"rot_rate"`
+	AverageDaysToBreakage float64 `json:"average_days_to_breakage"`
+}
+
+// DeadLink is an external link that stopped working
+type DeadLink struct {
+	Page       string    `json:"page"`
+	URL        string    `json:"url"`
+	FirstSeen  time.Time `json:"first_seen"`
+	DiedAt     time.Time `json:"died_at"`
+	AnalysisID string    `json:"analysis_id"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// link is what is known of one external link of one page
+type link struct {
+	domain    string
+	firstSeen time.Time // first working check, zero when never working
+	dead      bool
+}
+
+// Build reports the link rot of the analyses matching filter over the
+// months before now. filter.From is overwritten.
+func Build(store storage.Store, filter storage.HistoryFilter, months int, now time.Time) (*Report, error) {
+	now = now.UTC()
+	if months <= 0 {
+		months = DefaultMonths
+	}
+	months = min(months, MaxMonths)
+	filter.From = now.AddDate(0, -months, 0)
+	records, err := store.History(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{URL: filter.URL, Project: filter.Project, Months: months, From: filter.From, GeneratedAt: now}
+	died := make(map[string]int)
+	first := time.Date(filter.From.Year(), filter.From.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for month := first; !month.After(now); month = month.AddDate(0, 1, 0) {
+		died[month.Format("2006-01")] = 0
+	}
+
+	links := make(map[[2]string]*link)
+	pages := make(map[string]bool)
+	domains := make(map[string]*DomainRot)
+	var totalDays float64
+	for _, record := range records {
+		result := record.Result
+		// Without external link outcomes the analysis didn't check them
+		if result == nil || len(result.ExternalDomains) == 0 {
+			continue
+		}
+		page, err := url.Parse(record.URL)
+		if err != nil {
+			continue
+		}
+		report.Analyses++
+		pages[record.URL] = true
+
+		broken := make(map[string]models.LinkError)
+		for _, status := range result.InaccessibleLinks {
+			broken[status.URL] = status
+		}
+		for _, target := range result.Links {
+			domain := externalDomain(page, target)
+			if domain == "" {
+				continue
+			}
+			key := [2]string{record.URL, target}
+			l, seen := links[key]
+			if !seen {
+				l = &link{domain: domain}
+				links[key] = l
+				d, ok := domains[domain]
+				if !ok {
+					d = &DomainRot{Domain: domain}
+					domains[domain] = d
+				}
+				d.Links++
+			}
+
+			status, isBroken := broken[target]
+			switch {
+			case !isBroken:
+				if l.firstSeen.IsZero() {
+					l.firstSeen = record.CreatedAt
+				}
+			case !seen:
+				l.dead = true
+				report.BrokenWhenFound++
+			case !l.dead && !l.firstSeen.IsZero():
+				l.dead = true
+				days := record.CreatedAt.Sub(l.firstSeen).Hours() / 24
+				totalDays += days
+				report.Died++
+				died[record.CreatedAt.Format("2006-01")]++
+				d := domains[domain]
+				d.Died++
+				d.AverageDaysToBreakage += days
+				report.Dead = append(report.Dead, DeadLink{
+					Page:       record.URL,
+					URL:        target,
+					FirstSeen:  l.firstSeen,
+					DiedAt:     record.CreatedAt,
+					AnalysisID: record.ID,
+					StatusCode: status.StatusCode,
+					Error:      status.Error,
+				})
+			}
+		}
+	}
+
+	report.Pages = len(pages)
+	report.Links = len(links)
+	if report.Died > 0 {
+		report.AverageDaysToBreakage = totalDays / float64(report.Died)
+	}
+	for month, count := range died {
+		report.Trend = append(report.Trend, Month{Month: month, Died: count})
+	}
+	slices.SortFunc(report.Trend, func(a, b Month) int { return strings.Compare(a.Month, b.Month) })
+	for _, d := range domains {
+		if d.Died == 0 {
+			continue
+		}
+		d.RotRate = float64(d.Died) / float64(d.Links)
+		d.AverageDaysToBreakage /= float64(d.Died)
+		report.Domains = append(report.Domains, *d)
+	}
+	slices.SortFunc(report.Domains, func(a, b DomainRot) int {
+		return cmp.Or(cmp.Compare(b.RotRate, a.RotRate), cmp.Compare(b.Died, a.Died), strings.Compare(a.Domain, b.Domain))
+	})
+	slices.Reverse(report.Dead)
+	if len(report.Dead) > maxDeadLinks {
+		report.Dead = report.Dead[:maxDeadLinks]
+	}
+	return report, nil
+}
+
+// externalDomain returns the domain of target when it is an external link
+// of page, without a leading "www."
+func externalDomain(page *url.URL, target string) string {
+	u, err := page.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "" || host == strings.ToLower(page.Hostname()) {
+		return ""
+	}
+	return strings.TrimPrefix(host, "www.")
+}
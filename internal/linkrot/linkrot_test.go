@@ -0,0 +1,98 @@
+package linkrot
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"website-analyzer/internal/models"
+	"website-analyzer/internal/storage"
+)
+
+func TestBuild(t *testing.T) {
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+	for _, name := range []string{"site", "other"} {
+		if err := store.SaveProject(&storage.Project{Name: name}); err != nil {
+			t.Fatalf("SaveProject failed: %v", err)
+		}
+	}
+
+	const page = "https://example.com/"
+	links := []string{
+		"https://www.dead.example/a",
+		"https://dead.example/b",
+		"https://alive.example/",
+		"https://gone.example/",
+		"/internal",
+		"https://example.com/about",
+	}
+	save := func(project string, broken ...string) *storage.Record {
+		result := &models.AnalysisResult{
+			URL:             page,
+			Links:           links,
+			ExternalDomains: []models.DomainHealth{{Domain: "dead.example", Total: 2}},
+		}
+		for _, link := range broken {
+			result.InaccessibleLinks = append(result.InaccessibleLinks, models.LinkError{URL: link, StatusCode: 404, Error: "Not Found"})
+		}
+		record, err := store.Save(page, result, storage.Labels{Project: project})
+		if err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		return record
+	}
+
+	first := save("site", "https://gone.example/")
+	// Analyses that didn't check external links say nothing about them
+	if _, err := store.Save(page, &models.AnalysisResult{URL: page, Links: links}, storage.Labels{Project: "site"}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	save("site", "https://gone.example/", "https://www.dead.example/a")
+	last := save("other", "https://gone.example/", "https://www.dead.example/a", "https://dead.example/b")
+
+	report, err := Build(store, storage.HistoryFilter{URL: page}, 0, time.Now())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if report.Months != DefaultMonths || report.Analyses != 3 || report.Pages != 1 || report.Links != 4 {
+		t.Errorf("Unexpected totals %+v", report)
+	}
+	if report.Died != 2 || report.BrokenWhenFound != 1 {
+		t.Errorf("Expected 2 links died and 1 broken when found, got %d and %d", report.Died, report.BrokenWhenFound)
+	}
+	if len(report.Trend) != DefaultMonths+1 {
+		t.Errorf("Expected a trend entry per month, got %d", len(report.Trend))
+	}
+	if month := report.Trend[len(report.Trend)-1]; month.Month != time.Now().UTC().Format("2006-01") || month.Died != 2 {
+		t.Errorf("Expected both deaths this month, got %+v", month)
+	}
+	if len(report.Domains) != 1 || report.Domains[0].Domain != "dead.example" || report.Domains[0].RotRate != 1 {
+		t.Errorf("Expected dead.example to have fully decayed, got %+v", report.Domains)
+	}
+	if len(report.Dead) != 2 || report.Dead[0].URL != "https://dead.example/b" || report.Dead[0].AnalysisID != last.ID {
+		t.Fatalf("Expected the most recent death first, got %+v", report.Dead)
+	}
+	if !report.Dead[0].FirstSeen.Equal(first.CreatedAt) || report.Dead[0].StatusCode != 404 {
+		t.Errorf("Unexpected dead link %+v", report.Dead[0])
+	}
+
+	report, err = Build(store, storage.HistoryFilter{Project: "site"}, 1, time.Now())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if report.Analyses != 2 || report.Died != 1 || len(report.Trend) != 2 {
+		t.Errorf("Expected the project's own analyses only, got %+v", report)
+	}
+
+	report, err = Build(store, storage.HistoryFilter{URL: page}, 1, time.Now().AddDate(1, 0, 0))
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if report.Analyses != 0 || report.Died != 0 {
+		t.Errorf("Expected analyses before the period to be left out, got %+v", report)
+	}
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // LinkType represents the category of a link
 type LinkType int
 
@@ -24,23 +26,987 @@ func (lt LinkType) String() string {
 type Link struct {
 	URL  string   `json:"url"`
 	Type LinkType `json:"type"`
+	Rel  []string `json:"rel,omitempty"`
+	// Text is the anchor text, or the label of a link without any
+	Text   string `json:"text,omitempty"`
+	Target string `json:"target,omitempty"`
+}
+
+// ResponseTiming breaks down how long fetching the analyzed page took.
+// DNS, connect and TLS are zero on a reused connection; TTFB counts from
+// the first request, including any redirects.
+type ResponseTiming struct {
+	DNSMs      int64 `json:"dns_ms"`
+	ConnectMs  int64 `json:"connect_ms"`
+	TLSMs      int64 `json:"tls_ms"`
+	TTFBMs     int64 `json:"ttfb_ms"`
+	DownloadMs int64 `json:"download_ms"`
+	TotalMs    int64 `json:"total_ms"`
+	Reused     bool  `json:"reused,omitempty"`
+}
+
+// StageTiming is how long one stage of the page's analysis took: fetching,
+// parsing, extracting links, one check or scoring. Stages that didn't run
+// are left out.
+type StageTiming struct {
+	Stage      string  `json:"stage"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+// ProtocolReport describes the HTTP versions of the analyzed page's server.
+// HTTP2 is only meaningful when HTTP2Known, i.e. the page was fetched over
+// TLS; HTTP3 is set when the server advertises it in Alt-Svc.
+type ProtocolReport struct {
+	// Negotiated is the protocol of the response, e.g. "HTTP/2.0"
+	Negotiated string `json:"negotiated"`
+	HTTP2      bool   `json:"http2"`
+	HTTP2Known bool   `json:"http2_known"`
+	HTTP3      bool   `json:"http3"`
+	AltSvc     string `json:"alt_svc,omitempty"`
+}
+
+// Technology is a framework, CMS, analytics tool, library or server the
+// page was found to use, with what gave it away
+type Technology struct {
+	Name     string   `json:"name"`
+	Category string   `json:"category"`
+	Version  string   `json:"version,omitempty"`
+	Evidence []string `json:"evidence"`
+}
+
+// ThirdPartyReport lists the external domains the page references through
+// links, scripts, stylesheets, images, fonts and iframes, most referenced
+// first
+type ThirdPartyReport struct {
+	// Resources counts the distinct third-party URLs across all domains
+	Resources int          `json:"resources"`
+	Domains   []ThirdParty `json:"domains"`
+}
+
+// ThirdParty counts the distinct URLs of each kind the page references on
+// one registrable domain
+type ThirdParty struct {
+	Domain      string   `json:"domain"`
+	Hosts       []string `json:"hosts"`
+	Total       int      `json:"total"`
+	Links       int      `json:"links,omitempty"`
+	Scripts     int      `json:"scripts,omitempty"`
+	Stylesheets int      `json:"stylesheets,omitempty"`
+	Images      int      `json:"images,omitempty"`
+	Fonts       int      `json:"fonts,omitempty"`
+	Iframes     int      `json:"iframes,omitempty"`
+}
+
+// AnalyzerInfo identifies an analyzer build and the optional features it
+// ran with
+type AnalyzerInfo struct {
+	Version   string   `json:"version"`
+	Revision  string   `json:"revision,omitempty"`
+	Modified  bool     `json:"modified,omitempty"`
+	GoVersion string   `json:"go_version,omitempty"`
+	Features  []string `json:"features,omitempty"`
+}
+
+// InlineLink is an anchor whose target is embedded in the page (data:) or
+// created by its scripts (blob:) rather than fetched from a server. URL is
+// the blob: URL, or for data: URLs only the scheme and media type, with
+// the payload size in Size.
+type InlineLink struct {
+	Scheme string `json:"scheme"`
+	URL    string `json:"url"`
+	Size   int    `json:"size,omitempty"`
+	Text   string `json:"text,omitempty"`
+}
+
+// LinkDetail is a link found on the page with its attributes and, when it
+// was checked, the outcome
+type LinkDetail struct {
+	Link
+	Checked    bool   `json:"checked"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+	LatencyMs  int64  `json:"latency_ms,omitempty"`
+}
+
+// RedirectHop is one response on the way to the analyzed page
+type RedirectHop struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+	LatencyMs  int64  `json:"latency_ms"`
+}
+
+// RedirectReport is the chain of redirects the requested URL went through.
+// The last hop is the final response.
+type RedirectReport struct {
+	Hops []RedirectHop `json:"hops"`
+	// TooLong is set when the chain has more redirects than Limit
+	TooLong bool `json:"too_long"`
+	Limit   int  `json:"limit"`
 }
 
 // AnalysisResult contains all analysis data for a webpage
 type AnalysisResult struct {
-	URL               string         `json:"url"`
-	HTMLVersion       string         `json:"html_version"`
-	Title             string         `json:"title"`
-	Headings          map[string]int `json:"headings"`
-	InternalLinks     int            `json:"internal_links"`
-	ExternalLinks     int            `json:"external_links"`
-	InaccessibleLinks []LinkError    `json:"inaccessible_links"`
-	HasLoginForm      bool           `json:"has_login_form"`
+	URL               string                `json:"url"`
+	Profile           string                `json:"profile,omitempty"`
+	HTMLVersion       string                `json:"html_version"`
+	Title             string                `json:"title"`
+	HTMLSize          int64                 `json:"html_size"`
+	ResponseTimeMs    int64                 `json:"response_time_ms,omitempty"`
+	Timing            *ResponseTiming       `json:"timing,omitempty"`
+	Protocol          *ProtocolReport       `json:"protocol,omitempty"`
+	Redirects         *RedirectReport       `json:"redirects,omitempty"`
+	Stages            []StageTiming         `json:"stages,omitempty"`
+	WordCount         int                   `json:"word_count"`
+	Headings          map[string]int        `json:"headings"`
+	InternalLinks     int                   `json:"internal_links"`
+	ExternalLinks     int                   `json:"external_links"`
+	Links             []string              `json:"links,omitempty"`
+	LinkDetails       []LinkDetail          `json:"link_details,omitempty"`
+	InaccessibleLinks []LinkError           `json:"inaccessible_links"`
+	HasLoginForm      bool                  `json:"has_login_form"`
+	LazyLoading       *LazyLoadReport       `json:"lazy_loading,omitempty"`
+	ImageFormats      *ImageFormatReport    `json:"image_formats,omitempty"`
+	DataURIs          *DataURIReport        `json:"data_uris,omitempty"`
+	Accessibility     *AccessibilityReport  `json:"accessibility,omitempty"`
+	Documents         *DocumentReport       `json:"documents,omitempty"`
+	Resources         *ResourceReport       `json:"resources,omitempty"`
+	Fragments         *FragmentReport       `json:"fragments,omitempty"`
+	Weight            *PageWeightReport     `json:"weight,omitempty"`
+	Caching           *CachingReport        `json:"caching,omitempty"`
+	Technologies      []Technology          `json:"technologies,omitempty"`
+	ThirdParties      *ThirdPartyReport     `json:"third_parties,omitempty"`
+	Trackers          []Technology          `json:"trackers,omitempty"`
+	Icons             *IconReport           `json:"icons,omitempty"`
+	ExternalDomains   []DomainHealth        `json:"external_domains,omitempty"`
+	LinkLatency       *LinkLatencyReport    `json:"link_latency,omitempty"`
+	Regions           *RegionReport         `json:"regions,omitempty"`
+	RelCompliance     *RelReport            `json:"rel_compliance,omitempty"`
+	InsecureLinks     *InsecureLinkReport   `json:"insecure_links,omitempty"`
+	Hreflang          *HreflangReport       `json:"hreflang,omitempty"`
+	StructuredData    *StructuredDataReport `json:"structured_data,omitempty"`
+	Feeds             *FeedReport           `json:"feeds,omitempty"`
+	Site              *SiteReport           `json:"site,omitempty"`
+	Readiness         *ReadinessReport      `json:"readiness,omitempty"`
+	Restricted        []RestrictedSection   `json:"restricted_sections,omitempty"`
+	RobotsSkipped     []string              `json:"robots_skipped,omitempty"`
+	OptedOut          []string              `json:"opted_out,omitempty"`
+	OutOfScope        []string              `json:"out_of_scope,omitempty"`
+	// InlineLinks are anchors to data: and blob: URLs, which are neither
+	// counted nor checked
+	InlineLinks []InlineLink `json:"inline_links,omitempty"`
+	// SimHash fingerprints the page text, with the simhash feature flag;
+	// near-duplicate pages differ in few bits
+	SimHash string `json:"simhash,omitempty"`
+	// Analyzer is the build and features that produced the result
+	Analyzer *AnalyzerInfo `json:"analyzer,omitempty"`
+	// ArchiveDirectives are the noarchive and nosnippet directives the
+	// page declares; ContentWithheld is set when its text was therefore
+	// left out of the stored result
+	ArchiveDirectives []string              `json:"archive_directives,omitempty"`
+	ContentWithheld   bool                  `json:"content_withheld,omitempty"`
+	SEO               *SEOReport            `json:"seo,omitempty"`
+	Social            *SocialReport         `json:"social,omitempty"`
+	Keyword           *KeywordReport        `json:"keyword,omitempty"`
+	Scores            *Scores               `json:"scores,omitempty"`
+	Acknowledged      []AcknowledgedFinding `json:"acknowledged,omitempty"`
+	Usage             *ResourceUsage        `json:"usage,omitempty"`
+	CachedAt          time.Time             `json:"cached_at,omitzero"`
+	Regressions       []Regression          `json:"regressions,omitempty"`
+	// ReplayOf is the stored analysis whose snapshot was re-analyzed
+	ReplayOf string `json:"replay_of,omitempty"`
+	// Snapshot is the page as analyzed, when snapshots are kept; it is
+	// stored beside the result rather than in it
+	Snapshot *Snapshot `json:"-"`
+}
+
+// Snapshot is the HTML an analysis ran on, kept so the page can be
+// analyzed again with later rules without fetching it
+type Snapshot struct {
+	URL string `json:"url"`
+	// Body is the analyzed HTML, the rendered DOM for rendered pages
+	Body string `json:"body"`
+	// Size is the number of bytes downloaded
+	Size       int64               `json:"size"`
+	Header     map[string][]string `json:"header,omitempty"`
+	CapturedAt time.Time           `json:"captured_at"`
+}
+
+// ResourceUsage is what running an analysis cost
+type ResourceUsage struct {
+	WallTimeMs      int64 `json:"wall_time_ms"`
+	Pages           int64 `json:"pages"`
+	Requests        int64 `json:"requests"`
+	BytesDownloaded int64 `json:"bytes_downloaded"`
+	PeakGoroutines  int64 `json:"peak_goroutines"`
+}
+
+// LinkErrorType classifies why a link check failed
+type LinkErrorType int
+
+const (
+	LinkErrorNone LinkErrorType = iota
+	LinkErrorDNS
+	LinkErrorConnectionRefused
+	LinkErrorTimeout
+	LinkErrorTLS
+	LinkErrorTooManyRedirects
+	LinkErrorHTTP4xx
+	LinkErrorHTTP5xx
+	LinkErrorOther
+)
+
+var linkErrorTypeNames = [...]string{
+	LinkErrorNone:              "",
+	LinkErrorDNS:               "dns",
+	LinkErrorConnectionRefused: "connection_refused",
+	LinkErrorTimeout:           "timeout",
+	LinkErrorTLS:               "tls",
+	LinkErrorTooManyRedirects:  "too_many_redirects",
+	LinkErrorHTTP4xx:           "http_4xx",
+	LinkErrorHTTP5xx:           "http_5xx",
+	LinkErrorOther:             "other",
+}
+
+func (t LinkErrorType) String() string {
+	if t < 0 || int(t) >= len(linkErrorTypeNames) {
+		return "other"
+	}
+	return linkErrorTypeNames[t]
+}
+
+// MarshalText encodes the type by name so stored results stay readable
+func (t LinkErrorType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *LinkErrorType) UnmarshalText(text []byte) error {
+	for i, name := range linkErrorTypeNames {
+		if name == string(text) {
+			*t = LinkErrorType(i)
+			return nil
+		}
+	}
+	*t = LinkErrorOther
+	return nil
 }
 
 // LinkError represents a link that could not be accessed
 type LinkError struct {
-	URL        string `json:"url"`
+	URL        string        `json:"url"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Error      string        `json:"error"`
+	ErrorType  LinkErrorType `json:"error_type,omitempty"`
+	Attempts   int           `json:"attempts,omitempty"`
+}
+
+// LoadingStats counts elements by their loading attribute
+type LoadingStats struct {
+	Total int `json:"total"`
+	Lazy  int `json:"lazy"`
+	Eager int `json:"eager"`
+}
+
+// LazyLoadReport summarizes native lazy-loading usage on the page
+type LazyLoadReport struct {
+	Images         LoadingStats `json:"images"`
+	Iframes        LoadingStats `json:"iframes"`
+	EagerBelowFold []string     `json:"eager_below_fold,omitempty"`
+	LazyAboveFold  []string     `json:"lazy_above_fold,omitempty"`
+}
+
+// ImageInfo describes a raster image referenced by the page
+type ImageInfo struct {
+	URL                  string `json:"url"`
+	Format               string `json:"format"`
+	Size                 int64  `json:"size,omitempty"`
+	HasModernAlternative bool   `json:"has_modern_alternative"`
+	Flagged              bool   `json:"flagged"`
+	EstimatedSavings     int64  `json:"estimated_savings,omitempty"`
+}
+
+// ImageFormatReport lists raster images and WebP/AVIF conversion candidates
+type ImageFormatReport struct {
+	Images                []ImageInfo `json:"images"`
+	TotalEstimatedSavings int64       `json:"total_estimated_savings"`
+}
+
+// DataURI describes an inline data: URI embedded in the document
+type DataURI struct {
+	Location    string `json:"location"`
+	MediaType   string `json:"media_type"`
+	EncodedSize int    `json:"encoded_size"`
+	DecodedSize int    `json:"decoded_size"`
+	Flagged     bool   `json:"flagged"`
+}
+
+// DataURIReport summarizes inline data URIs and flags oversized ones
+type DataURIReport struct {
+	URIs             []DataURI `json:"uris,omitempty"`
+	TotalDecodedSize int       `json:"total_decoded_size"`
+	Flagged          int       `json:"flagged"`
+}
+
+// AccessibilityIssue is a single accessibility finding
+type AccessibilityIssue struct {
+	Rule    string `json:"rule"`
+	Element string `json:"element"`
+	Message string `json:"message"`
+}
+
+// AccessibilityReport aggregates accessibility findings for the page
+type AccessibilityReport struct {
+	Issues           []AccessibilityIssue `json:"issues"`
+	Landmarks        map[string]int       `json:"landmarks"`
+	PositiveTabIndex int                  `json:"positive_tabindex"`
+	HasSkipLink      bool                 `json:"has_skip_link"`
+	Lang             string               `json:"lang,omitempty"`
+	Contrast         *ContrastReport      `json:"contrast,omitempty"`
+}
+
+// ContrastSample is a text element failing WCAG AA contrast
+type ContrastSample struct {
+	Element    string  `json:"element"`
+	Text       string  `json:"text"`
+	Foreground string  `json:"foreground"`
+	Background string  `json:"background"`
+	Ratio      float64 `json:"ratio"`
+	Required   float64 `json:"required"`
+}
+
+// ContrastReport summarizes estimated text contrast (deep mode)
+type ContrastReport struct {
+	Checked  int              `json:"checked"`
+	Failures int              `json:"failures"`
+	Samples  []ContrastSample `json:"samples,omitempty"`
+}
+
+// DocumentLink is a link to a downloadable document such as a PDF
+type DocumentLink struct {
+	URL             string `json:"url"`
+	Type            string `json:"type"`
+	Text            string `json:"text"`
+	Size            int64  `json:"size,omitempty"`
+	StatusCode      int    `json:"status_code,omitempty"`
+	Error           string `json:"error,omitempty"`
+	MissingSizeHint bool   `json:"missing_size_hint"`
+}
+
+// DocumentReport inventories document links on the page
+type DocumentReport struct {
+	Documents []DocumentLink `json:"documents,omitempty"`
+	ByType    map[string]int `json:"by_type"`
+	TotalSize int64          `json:"total_size"`
+	Flagged   int            `json:"flagged"`
+}
+
+// Resource kinds
+const (
+	ResourceStylesheet = "stylesheet"
+	ResourceScript     = "script"
+	ResourcePreload    = "preload"
+	ResourcePrefetch   = "prefetch"
+	ResourceIframe     = "iframe"
+)
+
+// Resource is a stylesheet, script, preloaded or prefetched file or frame
+// the page loads and, when it was checked, the outcome
+type Resource struct {
+	URL        string   `json:"url"`
+	Kind       string   `json:"kind"`
+	Type       LinkType `json:"type"`
+	Checked    bool     `json:"checked"`
+	StatusCode int      `json:"status_code,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// ResourceReport inventories the resources the page loads besides images
+type ResourceReport struct {
+	Resources []Resource     `json:"resources,omitempty"`
+	ByKind    map[string]int `json:"by_kind"`
+	Broken    int            `json:"broken"`
+}
+
+// PageWeightReport counts what a page loads and, when resources were
+// measured, their total size. ResourceSize sums the Content-Length of the
+// resources that answered a HEAD request; Unmeasured counts the others.
+type PageWeightReport struct {
+	HTMLSize     int64             `json:"html_size"`
+	Scripts      int               `json:"scripts"`
+	Stylesheets  int               `json:"stylesheets"`
+	Images       int               `json:"images"`
+	Fonts        int               `json:"fonts"`
+	Measured     bool              `json:"measured"`
+	ResourceSize int64             `json:"resource_size,omitempty"`
+	Unmeasured   int               `json:"unmeasured,omitempty"`
+	TotalSize    int64             `json:"total_size"`
+	OverBudget   []BudgetViolation `json:"over_budget,omitempty"`
+}
+
+// CachingReport describes how the page is compressed and cached, and, when
+// assets were checked, which of its static assets lack a long-lived cache
+// lifetime. Unchecked counts assets that didn't answer a HEAD request.
+type CachingReport struct {
+	// Compression is the page's Content-Encoding, such as "gzip" or "br";
+	// empty when it is served uncompressed
+	Compression   string       `json:"compression,omitempty"`
+	CacheControl  string       `json:"cache_control,omitempty"`
+	ETag          bool         `json:"etag"`
+	LastModified  bool         `json:"last_modified"`
+	Assets        int          `json:"assets"`
+	AssetsChecked bool         `json:"assets_checked"`
+	LongLived     int          `json:"long_lived,omitempty"`
+	ShortLived    []AssetCache `json:"short_lived,omitempty"`
+	Unchecked     int          `json:"unchecked,omitempty"`
+	Issues        []string     `json:"issues,omitempty"`
+}
+
+// AssetCache is a static asset whose cache lifetime is missing or short
+type AssetCache struct {
+	URL          string `json:"url"`
+	Kind         string `json:"kind"`
+	CacheControl string `json:"cache_control,omitempty"`
+	// MaxAge is the asset's freshness lifetime in seconds
+	MaxAge int64 `json:"max_age"`
+}
+
+// BudgetViolation is a page weight metric over its configured budget
+type BudgetViolation struct {
+	Metric string `json:"metric"`
+	Value  int64  `json:"value"`
+	Budget int64  `json:"budget"`
+}
+
+// BrokenFragment is an in-page anchor whose target the page lacks
+type BrokenFragment struct {
+	Fragment string `json:"fragment"`
+	Text     string `json:"text,omitempty"`
+}
+
+// FragmentReport counts in-page anchors and lists those leading nowhere
+type FragmentReport struct {
+	Total  int              `json:"total"`
+	Broken []BrokenFragment `json:"broken,omitempty"`
+}
+
+// LinkStatus is the outcome of checking a single link
+type LinkStatus struct {
+	URL          string        `json:"url"`
+	Type         LinkType      `json:"type"`
+	StatusCode   int           `json:"status_code,omitempty"`
+	Authenticate string        `json:"authenticate,omitempty"`
+	Error        string        `json:"error,omitempty"`
+	ErrorType    LinkErrorType `json:"error_type,omitempty"`
+	LatencyMs    int64         `json:"latency_ms"`
+	Blocked      bool          `json:"blocked,omitempty"`
+	Attempts     int           `json:"attempts,omitempty"`
+}
+
+// RegionReport compares how the page and its external links are reached
+// from the analyzing server and from remote agents in other regions, to
+// catch geo-blocked links and links failing in some regions only
+type RegionReport struct {
+	Regions []RegionReach `json:"regions"`
+	// Links lists the URLs reachable from some regions but not others
+	Links []RegionalLink `json:"links,omitempty"`
+}
+
+// RegionReach summarizes the checks made from one region
+type RegionReach struct {
+	Region        string `json:"region"`
+	PageReachable bool   `json:"page_reachable"`
+	Checked       int    `json:"checked"`
+	Broken        int    `json:"broken"`
+	// Error is set when the region's agent could not check the links
+	Error string `json:"error,omitempty"`
+}
+
+// RegionalLink is a URL whose outcome differs between regions
+type RegionalLink struct {
+	URL       string          `json:"url"`
+	Reachable []string        `json:"reachable"`
+	Failures  []RegionFailure `json:"failures"`
+}
+
+// RegionFailure is how a URL failed from one region
+type RegionFailure struct {
+	Region     string `json:"region"`
 	StatusCode int    `json:"status_code,omitempty"`
 	Error      string `json:"error"`
 }
+
+// DomainHealth aggregates link check outcomes for one destination domain
+type DomainHealth struct {
+	Domain       string `json:"domain"`
+	Total        int    `json:"total"`
+	Broken       int    `json:"broken"`
+	Blocked      int    `json:"blocked"`
+	AvgLatencyMs int64  `json:"avg_latency_ms"`
+}
+
+// LinkLatencyReport summarizes how quickly checked links answered
+type LinkLatencyReport struct {
+	Measured int        `json:"measured"`
+	P50Ms    int64      `json:"p50_ms"`
+	P95Ms    int64      `json:"p95_ms"`
+	MaxMs    int64      `json:"max_ms"`
+	Slowest  []SlowLink `json:"slowest,omitempty"`
+}
+
+// SlowLink is one of the slowest links to answer a check
+type SlowLink struct {
+	URL        string   `json:"url"`
+	Type       LinkType `json:"type"`
+	StatusCode int      `json:"status_code,omitempty"`
+	LatencyMs  int64    `json:"latency_ms"`
+}
+
+// RelReport summarizes rel qualifiers on external links
+type RelReport struct {
+	Follow             int      `json:"follow"`
+	Nofollow           int      `json:"nofollow"`
+	Sponsored          int      `json:"sponsored"`
+	UGC                int      `json:"ugc"`
+	UnmarkedAffiliates []string `json:"unmarked_affiliates,omitempty"`
+}
+
+// InsecureLink is a link to an http:// destination
+type InsecureLink struct {
+	URL          string   `json:"url"`
+	Type         LinkType `json:"type"`
+	HTTPSURL     string   `json:"https_url"`
+	Upgradeable  bool     `json:"upgradeable"`
+	UpgradeError string   `json:"upgrade_error,omitempty"`
+}
+
+// InsecureLinkReport lists http:// links and whether HTTPS works for them
+type InsecureLinkReport struct {
+	Links       []InsecureLink `json:"links,omitempty"`
+	Upgradeable int            `json:"upgradeable"`
+}
+
+// HreflangAlternate is a language alternate of the page
+type HreflangAlternate struct {
+	Lang    string   `json:"lang"`
+	URL     string   `json:"url"`
+	Sources []string `json:"sources"`
+}
+
+// HreflangReport merges hreflang declarations from link tags and the sitemap
+type HreflangReport struct {
+	Alternates []HreflangAlternate `json:"alternates,omitempty"`
+	Conflicts  []string            `json:"conflicts,omitempty"`
+}
+
+// StructuredDataEntity is a schema.org entity found on the page
+type StructuredDataEntity struct {
+	Type               string   `json:"type"`
+	Format             string   `json:"format"`
+	Validated          bool     `json:"validated"`
+	MissingRequired    []string `json:"missing_required,omitempty"`
+	MissingRecommended []string `json:"missing_recommended,omitempty"`
+}
+
+// StructuredDataReport lists structured data entities and validation
+// results, plus the distinct schema.org types found in any format
+type StructuredDataReport struct {
+	Entities []StructuredDataEntity `json:"entities,omitempty"`
+	Types    []string               `json:"types,omitempty"`
+	Errors   []string               `json:"errors,omitempty"`
+}
+
+// Feed is an RSS/Atom/JSON feed or OpenSearch description advertised by the page
+type Feed struct {
+	URL           string     `json:"url"`
+	Type          string     `json:"type"`
+	Title         string     `json:"title,omitempty"`
+	Valid         bool       `json:"valid"`
+	Error         string     `json:"error,omitempty"`
+	EntryCount    int        `json:"entry_count"`
+	LastPublished *time.Time `json:"last_published,omitempty"`
+	Stale         bool       `json:"stale"`
+}
+
+// Icon sources
+const (
+	IconSourceLink     = "icon"
+	IconSourceTouch    = "apple-touch-icon"
+	IconSourceManifest = "manifest"
+	IconSourceFallback = "fallback"
+)
+
+// Icon is a favicon, touch icon or web app manifest icon the page
+// declares, or the /favicon.ico browsers fall back to. Available is set
+// when it was checked and answered 200 with an image content type.
+type Icon struct {
+	URL         string `json:"url"`
+	Source      string `json:"source"`
+	Sizes       string `json:"sizes,omitempty"`
+	Checked     bool   `json:"checked"`
+	Available   bool   `json:"available"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// IconReport lists the page's icons and what is missing or broken
+type IconReport struct {
+	Manifest      string   `json:"manifest,omitempty"`
+	ManifestError string   `json:"manifest_error,omitempty"`
+	Icons         []Icon   `json:"icons"`
+	Issues        []string `json:"issues,omitempty"`
+}
+
+// FeedReport lists the feeds advertised by the page
+type FeedReport struct {
+	Feeds []Feed `json:"feeds"`
+}
+
+// CrawlPage is the analysis of one page reached during a crawl
+type CrawlPage struct {
+	URL    string          `json:"url"`
+	Depth  int             `json:"depth"`
+	Result *AnalysisResult `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// CrawlSummary aggregates results across all crawled pages
+type CrawlSummary struct {
+	PagesCrawled       int `json:"pages_crawled"`
+	PagesFailed        int `json:"pages_failed"`
+	InternalLinks      int `json:"internal_links"`
+	ExternalLinks      int `json:"external_links"`
+	UniqueBrokenLinks  int `json:"unique_broken_links"`
+	PagesWithLoginForm int `json:"pages_with_login_form"`
+	PagesMissingTitle  int `json:"pages_missing_title"`
+	PagesMissingH1     int `json:"pages_missing_h1"`
+	DuplicateTitles    int `json:"duplicate_titles"`
+}
+
+// CrawlResult contains per-page results and the site summary of a crawl
+type CrawlResult struct {
+	StartURL string       `json:"start_url"`
+	Pages    []CrawlPage  `json:"pages"`
+	Summary  CrawlSummary `json:"summary"`
+	// RobotsSkipped lists pages not crawled because robots.txt disallows them
+	RobotsSkipped []string       `json:"robots_skipped,omitempty"`
+	Usage         *ResourceUsage `json:"usage,omitempty"`
+}
+
+// ScopePlan is a dry run of a batch or crawl: the pages it would fetch and
+// what that would cost, worked out without contacting any analyzed host.
+// Estimates come from each page's last stored analysis where there is one.
+type ScopePlan struct {
+	Pages []PlannedPage `json:"pages"`
+	// UnknownPages counts crawl pages that can only be named by fetching
+	// their parents; they are estimated up to the page limit
+	UnknownPages int      `json:"unknown_pages,omitempty"`
+	FetchPages   int      `json:"fetch_pages"`
+	Requests     int64    `json:"estimated_requests"`
+	DurationMs   int64    `json:"estimated_duration_ms"`
+	Concurrency  int      `json:"concurrency"`
+	Notes        []string `json:"notes,omitempty"`
+}
+
+// PlannedPage is one page of a ScopePlan. Skipped explains why it would
+// not be fetched; a Cached page is served from the result cache.
+type PlannedPage struct {
+	URL         string `json:"url"`
+	Depth       int    `json:"depth,omitempty"`
+	Skipped     string `json:"skipped,omitempty"`
+	Cached      bool   `json:"cached,omitempty"`
+	Requests    int64  `json:"estimated_requests"`
+	DurationMs  int64  `json:"estimated_duration_ms"`
+	FromHistory bool   `json:"from_history"`
+}
+
+// Job is a crawl or batch running in the background. QueuePosition is set
+// while it waits for a worker, Result once it has completed.
+type Job struct {
+	ID            string      `json:"id"`
+	Kind          string      `json:"kind"`
+	Target        string      `json:"target"`
+	Priority      string      `json:"priority"`
+	State         string      `json:"state"`
+	QueuePosition int         `json:"queue_position,omitempty"`
+	CreatedAt     time.Time   `json:"created_at"`
+	StartedAt     *time.Time  `json:"started_at,omitempty"`
+	FinishedAt    *time.Time  `json:"finished_at,omitempty"`
+	Progress      JobProgress `json:"progress"`
+	Error         string      `json:"error,omitempty"`
+	Result        any         `json:"result,omitempty"`
+}
+
+// JobProgress is how far a job has come. Totals grow as a crawl discovers
+// pages; ETASeconds is nil until enough work has finished to estimate it.
+type JobProgress struct {
+	PagesDone    int      `json:"pages_done"`
+	PagesTotal   int      `json:"pages_total"`
+	LinksDone    int      `json:"links_done"`
+	LinksTotal   int      `json:"links_total"`
+	Percent      float64  `json:"percent"`
+	ETASeconds   *float64 `json:"eta_seconds"`
+	AvgLatencyMs int64    `json:"avg_latency_ms"`
+}
+
+// SiteReport collects origin-level hygiene findings (robots.txt, sitemaps)
+type SiteReport struct {
+	RobotsFound    bool          `json:"robots_found"`
+	RobotsWarnings []string      `json:"robots_warnings,omitempty"`
+	Sitemaps       []SitemapInfo `json:"sitemaps,omitempty"`
+	SitemapIssues  []string      `json:"sitemap_issues,omitempty"`
+}
+
+// SitemapInfo describes a fetched sitemap or sitemap index
+type SitemapInfo struct {
+	URL     string `json:"url"`
+	IsIndex bool   `json:"is_index"`
+	Entries int    `json:"entries"`
+	Size    int    `json:"size"`
+}
+
+// ReadinessIssue is a launch leftover such as noindex or placeholder text
+type ReadinessIssue struct {
+	Rule     string `json:"rule"`
+	Evidence string `json:"evidence"`
+	Message  string `json:"message"`
+}
+
+// ReadinessReport lists production readiness problems on the page
+type ReadinessReport struct {
+	Issues []ReadinessIssue `json:"issues,omitempty"`
+}
+
+// RestrictedSection is a part of the site whose internal links all
+// answered 401 or 403
+type RestrictedSection struct {
+	Path        string   `json:"path"`
+	StatusCodes []int    `json:"status_codes"`
+	Schemes     []string `json:"schemes,omitempty"`
+	Realms      []string `json:"realms,omitempty"`
+	URLs        []string `json:"urls"`
+}
+
+// SEOReport holds search and social metadata declared by the page
+type SEOReport struct {
+	Description string            `json:"description,omitempty"`
+	Keywords    []string          `json:"keywords,omitempty"`
+	Canonical   string            `json:"canonical,omitempty"`
+	Robots      []string          `json:"robots,omitempty"`
+	OpenGraph   map[string]string `json:"open_graph,omitempty"`
+	TwitterCard map[string]string `json:"twitter_card,omitempty"`
+	Preview     *SERPPreview      `json:"serp_preview,omitempty"`
+}
+
+// SERPPreview approximates the page's search result snippet
+type SERPPreview struct {
+	Title                string `json:"title"`
+	TitleTruncated       bool   `json:"title_truncated"`
+	Description          string `json:"description"`
+	DescriptionTruncated bool   `json:"description_truncated"`
+	Breadcrumb           string `json:"breadcrumb"`
+}
+
+// SocialPreview is a share card as one platform would render it
+type SocialPreview struct {
+	Platform    string   `json:"platform"`
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Image       string   `json:"image,omitempty"`
+	Domain      string   `json:"domain"`
+	CardType    string   `json:"card_type,omitempty"`
+	Missing     []string `json:"missing,omitempty"`
+}
+
+// SocialImage describes the fetched share image
+type SocialImage struct {
+	URL        string `json:"url"`
+	Relative   bool   `json:"relative,omitempty"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Format     string `json:"format,omitempty"`
+	Width      int    `json:"width,omitempty"`
+	Height     int    `json:"height,omitempty"`
+	Size       int64  `json:"size,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// SocialReport holds share previews and share image findings
+type SocialReport struct {
+	Previews []SocialPreview `json:"previews"`
+	Image    *SocialImage    `json:"image,omitempty"`
+	Warnings []string        `json:"warnings,omitempty"`
+}
+
+// KeywordCheck is one checklist item of the keyword audit. Position is the
+// 1-based word (or image) index of the first match.
+type KeywordCheck struct {
+	Location string `json:"location"`
+	Found    bool   `json:"found"`
+	Position int    `json:"position,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// KeywordReport shows where a target phrase appears on the page
+type KeywordReport struct {
+	Keyword string         `json:"keyword"`
+	Checks  []KeywordCheck `json:"checks"`
+	Passed  int            `json:"passed"`
+	Total   int            `json:"total"`
+}
+
+// Scores rates a page from 0 to 100 per category
+type Scores struct {
+	Overall       int `json:"overall"`
+	SEO           int `json:"seo"`
+	Accessibility int `json:"accessibility"`
+	Links         int `json:"links"`
+}
+
+// ComparisonPage is one column of a competitor comparison
+type ComparisonPage struct {
+	URL             string   `json:"url"`
+	Primary         bool     `json:"primary"`
+	Error           string   `json:"error,omitempty"`
+	Title           string   `json:"title,omitempty"`
+	WordCount       int      `json:"word_count"`
+	Headings        int      `json:"headings"`
+	H1              int      `json:"h1"`
+	PageWeight      int64    `json:"page_weight"`
+	Scores          *Scores  `json:"scores,omitempty"`
+	StructuredTypes []string `json:"structured_types,omitempty"`
+}
+
+// ComparisonLag is a metric where the primary page trails a competitor
+type ComparisonLag struct {
+	Metric  string `json:"metric"`
+	Primary int64  `json:"primary"`
+	Best    int64  `json:"best"`
+	BestURL string `json:"best_url"`
+}
+
+// ComparisonReport compares a primary page against competitor pages
+type ComparisonReport struct {
+	Pages   []ComparisonPage `json:"pages"`
+	Lags    []ComparisonLag  `json:"lags,omitempty"`
+	Lagging map[string]bool  `json:"-"`
+}
+
+// Finding kinds that can be acknowledged
+const (
+	FindingLink          = "link"
+	FindingReadiness     = "readiness"
+	FindingAccessibility = "accessibility"
+)
+
+// Acknowledgement suppresses a known finding on a host. Target is the link
+// URL, the readiness rule, or "rule|element" for accessibility issues.
+type Acknowledgement struct {
+	ID        string    `json:"id"`
+	Host      string    `json:"host"`
+	Kind      string    `json:"kind"`
+	Target    string    `json:"target"`
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AcknowledgedFinding is a finding moved out of the report by an
+// acknowledgement
+type AcknowledgedFinding struct {
+	Acknowledgement
+	Detail string `json:"detail,omitempty"`
+}
+
+// ScoreDrop is a score that fell further below the baseline than allowed
+type ScoreDrop struct {
+	Metric   string `json:"metric"`
+	Baseline int    `json:"baseline"`
+	Current  int    `json:"current"`
+}
+
+// Regression kinds flagged by monitors
+const (
+	RegressionBrokenLink   = "new_broken_link"
+	RegressionTitleChanged = "title_changed"
+	RegressionLoginForm    = "login_form_removed"
+	// Anomalies are flagged against the page's own recent history
+	RegressionResponseTime = "response_time_anomaly"
+	RegressionLinkCollapse = "link_count_collapse"
+)
+
+// Regression is a change for the worse since a monitor's previous run
+type Regression struct {
+	Kind   string `json:"kind"`
+	Detail string `json:"detail"`
+}
+
+// GateVerdict is the outcome of checking a result against its baseline
+type GateVerdict struct {
+	Pass           bool        `json:"pass"`
+	Tolerance      int         `json:"tolerance"`
+	NewBrokenLinks []LinkError `json:"new_broken_links,omitempty"`
+	ScoreDrops     []ScoreDrop `json:"score_drops,omitempty"`
+	Reasons        []string    `json:"reasons,omitempty"`
+}
+
+// AnalysisDiff is what changed between two analyses, usually of one page
+// before and after a release. Lists are empty rather than null.
+type AnalysisDiff struct {
+	From DiffSide `json:"from"`
+	To   DiffSide `json:"to"`
+	// LinksAdded and LinksRemoved compare the pages' link URLs;
+	// LinksUnavailable is set when a result predates stored link lists
+	LinksAdded       []string      `json:"links_added"`
+	LinksRemoved     []string      `json:"links_removed"`
+	LinksUnavailable bool          `json:"links_unavailable,omitempty"`
+	NewlyBroken      []LinkError   `json:"newly_broken_links"`
+	Fixed            []string      `json:"fixed_links"`
+	Headings         []CountChange `json:"heading_changes"`
+	Counts           []CountChange `json:"count_changes"`
+	Fields           []FieldChange `json:"field_changes"`
+}
+
+// DiffSide identifies one of the analyses in a diff
+type DiffSide struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	AnalyzedAt time.Time `json:"analyzed_at"`
+}
+
+// CountChange is a number that differs between two analyses
+type CountChange struct {
+	Name string `json:"name"`
+	From int64  `json:"from"`
+	To   int64  `json:"to"`
+}
+
+// FieldChange is a text field, such as the title or a meta tag, that
+// differs between two analyses; an empty side means the field was absent
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// Empty reports whether the diff found no changes
+func (d *AnalysisDiff) Empty() bool {
+	return len(d.LinksAdded) == 0 && len(d.LinksRemoved) == 0 && len(d.NewlyBroken) == 0 &&
+		len(d.Fixed) == 0 && len(d.Headings) == 0 && len(d.Counts) == 0 && len(d.Fields) == 0
+}
+
+// Circuit breaker states
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half-open"
+)
+
+// CircuitState is the link checker's view of a domain that has recently
+// failed. Closed domains are still checked; open ones are skipped until a
+// half-open probe succeeds.
+type CircuitState struct {
+	Domain      string    `json:"domain"`
+	State       string    `json:"state"`
+	Failures    int       `json:"failures"`
+	Successes   int       `json:"successes,omitempty"`
+	LastFailure time.Time `json:"last_failure"`
+	ExpiresAt   time.Time `json:"expires_at,omitzero"`
+}
@@ -0,0 +1,86 @@
+package monitor
+
+import (
+	"fmt"
+	"math"
+
+	"website-analyzer/internal/models"
+)
+
+const (
+	// anomalyMinHistory is how many earlier runs a page needs before its
+	// metrics are judged against them
+	anomalyMinHistory = 5
+	// anomalyWindow is how many of the latest runs the rolling mean covers
+	anomalyWindow = 20
+	// anomalySigmas is how far above the rolling mean a response time has
+	// to be to be unusual
+	anomalySigmas = 3
+	// anomalyMinSpread floors the standard deviation at this share of the
+	// mean, so a perfectly steady history doesn't flag a few milliseconds
+	anomalyMinSpread = 0.05
+	// linkCollapseRatio is the share of its usual links a page must drop
+	// below, on top of being unusual, to count as a collapse
+	linkCollapseRatio = 0.5
+)
+
+// History is the values a page's metrics had in its earlier runs, oldest
+// first
+type History struct {
+	ResponseTimeMs []float64
+	LinkCount      []float64
+}
+
+// Anomalies flags current's metrics that are statistically unusual for the
+// page: a response time more than three standard deviations above the
+// rolling mean, and a link count that fell as far below it and to under
+// half of it, which usually means a broken template. Pages with too
+// little history are never flagged.
+func Anomalies(history History, current *models.AnalysisResult) []models.Regression {
+	var anomalies []models.Regression
+
+	if current.ResponseTimeMs > 0 {
+		if mean, sd, ok := rollingStats(history.ResponseTimeMs); ok {
+			value := float64(current.ResponseTimeMs)
+			if value > mean+anomalySigmas*sd {
+				anomalies = append(anomalies, models.Regression{
+					Kind:   models.RegressionResponseTime,
+					Detail: fmt.Sprintf("responded in %d ms, %.1fσ above the usual %.0f ms", current.ResponseTimeMs, (value-mean)/sd, mean),
+				})
+			}
+		}
+	}
+
+	if mean, sd, ok := rollingStats(history.LinkCount); ok {
+		value := float64(len(current.Links))
+		if value < mean-anomalySigmas*sd && value < mean*linkCollapseRatio {
+			anomalies = append(anomalies, models.Regression{
+				Kind:   models.RegressionLinkCollapse,
+				Detail: fmt.Sprintf("%d links instead of the usual %.0f", len(current.Links), mean),
+			})
+		}
+	}
+
+	return anomalies
+}
+
+// rollingStats returns the mean and floored standard deviation of the
+// latest anomalyWindow values, or false with too few of them
+func rollingStats(values []float64) (mean, sd float64, ok bool) {
+	if len(values) < anomalyMinHistory {
+		return 0, 0, false
+	}
+	if len(values) > anomalyWindow {
+		values = values[len(values)-anomalyWindow:]
+	}
+
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	for _, v := range values {
+		sd += (v - mean) * (v - mean)
+	}
+	sd = math.Sqrt(sd / float64(len(values)))
+	return mean, max(sd, mean*anomalyMinSpread), mean > 0
+}
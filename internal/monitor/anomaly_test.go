@@ -0,0 +1,60 @@
+package monitor
+
+import (
+	"testing"
+
+	"website-analyzer/internal/models"
+)
+
+func TestAnomalies(t *testing.T) {
+	links := func(n int) []string {
+		return make([]string, n)
+	}
+	steady := History{
+		ResponseTimeMs: []float64{200, 210, 190, 205, 195, 200},
+		LinkCount:      []float64{40, 42, 41, 40, 39, 40},
+	}
+
+	tests := []struct {
+		name    string
+		history History
+		current *models.AnalysisResult
+		want    []string
+	}{
+		{"usual", steady, &models.AnalysisResult{ResponseTimeMs: 215, Links: links(38)}, nil},
+		{"slow", steady, &models.AnalysisResult{ResponseTimeMs: 900, Links: links(40)}, []string{models.RegressionResponseTime}},
+		{"collapse", steady, &models.AnalysisResult{ResponseTimeMs: 200, Links: links(3)}, []string{models.RegressionLinkCollapse}},
+		// Fewer links, but not a collapse
+		{"trimmed", steady, &models.AnalysisResult{ResponseTimeMs: 200, Links: links(30)}, nil},
+		{"short history", History{ResponseTimeMs: []float64{200, 200}, LinkCount: []float64{40, 40}},
+			&models.AnalysisResult{ResponseTimeMs: 900, Links: links(0)}, nil},
+		// A flat history still tolerates small changes
+		{"flat", History{ResponseTimeMs: []float64{100, 100, 100, 100, 100}}, &models.AnalysisResult{ResponseTimeMs: 110}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Anomalies(tt.history, tt.current)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Anomalies() = %+v, want %v", got, tt.want)
+			}
+			for i, kind := range tt.want {
+				if got[i].Kind != kind || got[i].Detail == "" {
+					t.Errorf("Anomalies()[%d] = %+v, want %s", i, got[i], kind)
+				}
+			}
+		})
+	}
+
+	// Only the rolling window counts: a page that used to be slow isn't
+	// excused by it
+	history := History{ResponseTimeMs: make([]float64, 0, 40)}
+	for range 20 {
+		history.ResponseTimeMs = append(history.ResponseTimeMs, 5000)
+	}
+	for range 20 {
+		history.ResponseTimeMs = append(history.ResponseTimeMs, 200)
+	}
+	if got := Anomalies(history, &models.AnalysisResult{ResponseTimeMs: 1000}); len(got) != 1 {
+		t.Errorf("Expected the slow run to stand out from the latest runs, got %+v", got)
+	}
+}
@@ -0,0 +1,32 @@
+package monitor
+
+import (
+	"time"
+
+	"website-analyzer/internal/storage"
+)
+
+// WindowOpen reports whether w is open at t, i.e. its schedule was due
+// less than its duration before t. A window whose schedule doesn't parse
+// is never open.
+func WindowOpen(w *storage.MaintenanceWindow, t time.Time) bool {
+	schedule, err := ParseSchedule(w.Schedule)
+	if err != nil || w.Duration <= 0 {
+		return false
+	}
+	start := schedule.Next(t.Add(-w.Duration))
+	return !start.IsZero() && !start.After(t)
+}
+
+// InMaintenance returns the window open at t for url, or for project when
+// it isn't empty, or nil
+func InMaintenance(windows []storage.MaintenanceWindow, url, project string, t time.Time) *storage.MaintenanceWindow {
+	for i := range windows {
+		w := &windows[i]
+		covers := (w.URL != "" && w.URL == url) || (w.Project != "" && w.Project == project)
+		if covers && WindowOpen(w, t) {
+			return w
+		}
+	}
+	return nil
+}
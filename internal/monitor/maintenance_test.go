@@ -0,0 +1,56 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"website-analyzer/internal/storage"
+)
+
+func TestWindowOpen(t *testing.T) {
+	// Tuesdays 22:00-23:30 UTC
+	window := &storage.MaintenanceWindow{Schedule: "0 22 * * 2", Duration: 90 * time.Minute}
+	tuesday := time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		at   time.Time
+		open bool
+	}{
+		{tuesday.Add(21*time.Hour + 59*time.Minute), false},
+		{tuesday.Add(22 * time.Hour), true},
+		{tuesday.Add(22*time.Hour + 30*time.Second), true},
+		{tuesday.Add(23*time.Hour + 29*time.Minute), true},
+		{tuesday.Add(23*time.Hour + 30*time.Minute), false},
+		{tuesday.Add(46 * time.Hour), false},
+	}
+	for _, tt := range tests {
+		if got := WindowOpen(window, tt.at); got != tt.open {
+			t.Errorf("WindowOpen at %v = %v, want %v", tt.at, got, tt.open)
+		}
+	}
+
+	if WindowOpen(&storage.MaintenanceWindow{Schedule: "daily", Duration: time.Hour}, tuesday) {
+		t.Error("Expected a window with an invalid schedule to stay closed")
+	}
+}
+
+func TestInMaintenance(t *testing.T) {
+	midnight := time.Date(2026, 3, 3, 0, 10, 0, 0, time.UTC)
+	windows := []storage.MaintenanceWindow{
+		{ID: "page", URL: "https://example.com/", Schedule: "@daily", Duration: time.Hour},
+		{ID: "project", Project: "docs", Schedule: "@daily", Duration: 30 * time.Minute},
+	}
+
+	if w := InMaintenance(windows, "https://example.com/", "", midnight); w == nil || w.ID != "page" {
+		t.Errorf("Expected the page's window, got %+v", w)
+	}
+	if w := InMaintenance(windows, "https://docs.example.com/", "docs", midnight); w == nil || w.ID != "project" {
+		t.Errorf("Expected the project's window, got %+v", w)
+	}
+	if w := InMaintenance(windows, "https://other.example.com/", "", midnight); w != nil {
+		t.Errorf("Expected no window for another page, got %+v", w)
+	}
+	if w := InMaintenance(windows, "https://docs.example.com/", "docs", midnight.Add(time.Hour)); w != nil {
+		t.Errorf("Expected the window to have closed, got %+v", w)
+	}
+}
@@ -0,0 +1,253 @@
+// Package monitor re-analyzes registered pages on cron-like schedules and
+// flags regressions between consecutive runs
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"website-analyzer/internal/analyzer"
+	"website-analyzer/internal/jobs"
+	"website-analyzer/internal/models"
+	"website-analyzer/internal/storage"
+)
+
+// invalidScheduleRetry is how long a monitor whose schedule no longer
+// parses waits before it is tried again
+const invalidScheduleRetry = 24 * time.Hour
+
+// Notifier tells webhooks about finished runs
+type Notifier interface {
+	// NotifyRun is called with the stored result of a run
+	NotifyRun(ctx context.Context, m *storage.Monitor, record *storage.Record) error
+	// NotifyFailure is called when a run could not analyze the page
+	NotifyFailure(ctx context.Context, m *storage.Monitor, runErr error) error
+}
+
+// Queue hands out the workers shared with background jobs
+type Queue interface {
+	// Acquire waits for a worker and returns the function freeing it
+	Acquire(ctx context.Context, priority jobs.Priority) (func(), error)
+}
+
+// Runner runs due monitors one at a time, so scheduled work never competes
+// with itself for the analyzer's workers
+type Runner struct {
+	store    storage.Store
+	analyzer *analyzer.Analyzer
+	interval time.Duration
+	onSaved  func(id string)
+	notifier Notifier
+	queue    Queue
+}
+
+// NewRunner returns a runner that checks store for due monitors every
+// interval
+func NewRunner(store storage.Store, a *analyzer.Analyzer, interval time.Duration) *Runner {
+	return &Runner{store: store, analyzer: a, interval: interval}
+}
+
+// OnSaved registers fn to be called with the ID of every stored result,
+// except those of monitors paused by the time it is stored
+func (r *Runner) OnSaved(fn func(id string)) {
+	r.onSaved = fn
+}
+
+// SetNotifier reports every finished run to notifier
+func (r *Runner) SetNotifier(notifier Notifier) {
+	r.notifier = notifier
+}
+
+// SetQueue makes every run wait for a worker of queue at the scheduled
+// priority, behind jobs someone is waiting on
+func (r *Runner) SetQueue(queue Queue) {
+	r.queue = queue
+}
+
+// Run runs due monitors every interval until ctx is cancelled
+func (r *Runner) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.RunDue(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue runs each monitor due at now and returns how many ran
+func (r *Runner) RunDue(ctx context.Context, now time.Time) int {
+	due, err := r.store.DueMonitors(now)
+	if err != nil {
+		slog.Error("failed to list due monitors", "error", err)
+		return 0
+	}
+
+	ran := 0
+	for i := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		r.run(ctx, &due[i], now)
+		ran++
+	}
+	return ran
+}
+
+// run analyzes the monitor's page, stores the result flagged with its
+// regressions and schedules the next run
+func (r *Runner) run(ctx context.Context, m *storage.Monitor, now time.Time) {
+	schedule, err := ParseSchedule(m.Schedule)
+	if err != nil {
+		slog.Error("invalid monitor schedule", "monitor", m.ID, "error", err)
+		m.NextRun = now.Add(invalidScheduleRetry)
+		m.LastError = err.Error()
+		r.update(m)
+		return
+	}
+
+	if m.Paused {
+		// Due while paused only once the pause has expired
+		if err := r.store.ResumeMonitor(m.ID); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			slog.Error("failed to resume monitor", "monitor", m.ID, "error", err)
+		}
+		slog.Info("monitor resumed", "monitor", m.ID, "url", m.URL)
+	}
+
+	actor := "monitor:" + m.ID
+	start := time.Now()
+	result, err := r.analyze(ctx, m)
+	// Runs cut short by shutdown are retried on the next start
+	if ctx.Err() != nil {
+		return
+	}
+
+	m.LastRun = now
+	m.NextRun = schedule.Next(now)
+	m.LastError = ""
+	m.Regressions = 0
+	slog.Info("monitor run completed", "monitor", m.ID, "url", m.URL, "duration", time.Since(start), "error", err)
+	if err != nil {
+		m.LastError = err.Error()
+		r.audit(actor, m.URL, "failed: "+err.Error())
+		r.update(m)
+		if r.notifier != nil && !r.paused(m.ID) {
+			if err := r.notifier.NotifyFailure(ctx, m, err); err != nil {
+				slog.Error("failed to notify monitor failure", "monitor", m.ID, "error", err)
+			}
+		}
+		return
+	}
+
+	if m.LastAnalysis != "" {
+		previous, err := r.store.Get(m.LastAnalysis)
+		if err == nil {
+			result.Regressions = Regressions(previous.Result, result)
+		} else if !errors.Is(err, storage.ErrNotFound) {
+			slog.Error("failed to load previous monitor run", "monitor", m.ID, "error", err)
+		}
+	}
+	result.Regressions = append(result.Regressions, r.anomalies(m, result)...)
+
+	record, err := r.store.Save(result.URL, result, storage.Labels{Project: m.Project})
+	if err != nil {
+		slog.Error("failed to save monitor run", "monitor", m.ID, "error", err)
+		m.LastError = "failed to save the analysis"
+		r.audit(actor, m.URL, "not saved")
+		r.update(m)
+		return
+	}
+	r.audit(actor, m.URL, "id="+record.ID)
+
+	m.LastAnalysis = record.ID
+	m.Regressions = len(result.Regressions)
+	if m.Regressions > 0 {
+		slog.Warn("monitor found regressions", "monitor", m.ID, "url", m.URL, "regressions", m.Regressions, "id", record.ID)
+	}
+	r.update(m)
+
+	// A monitor paused during the run keeps its result without hooks or
+	// alerts
+	if r.paused(m.ID) {
+		return
+	}
+	if r.onSaved != nil {
+		r.onSaved(record.ID)
+	}
+	if r.notifier != nil {
+		if err := r.notifier.NotifyRun(ctx, m, record); err != nil {
+			slog.Error("failed to notify monitor run", "monitor", m.ID, "error", err)
+		}
+	}
+}
+
+// analyze checks the do-not-analyze list, which may have grown since the
+// monitor was registered, and analyzes the page afresh
+func (r *Runner) analyze(ctx context.Context, m *storage.Monitor) (*models.AnalysisResult, error) {
+	if u, err := url.Parse(m.URL); err == nil && u.Hostname() != "" {
+		_, err := r.store.DeniedHost(u.Hostname())
+		if err == nil {
+			return nil, fmt.Errorf("%s is on this service's do-not-analyze list", u.Hostname())
+		}
+		if !errors.Is(err, storage.ErrNotFound) {
+			return nil, fmt.Errorf("failed to check the do-not-analyze list: %w", err)
+		}
+	}
+	if r.queue != nil {
+		release, err := r.queue.Acquire(ctx, jobs.PriorityScheduled)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+	return r.analyzer.AnalyzeWithOptions(ctx, m.URL, analyzer.AnalyzeOptions{Profile: m.Profile, Force: true})
+}
+
+// anomalies judges result against the page's stored runs; a failed lookup
+// is logged and flags nothing
+func (r *Runner) anomalies(m *storage.Monitor, result *models.AnalysisResult) []models.Regression {
+	var history History
+	for metric, values := range map[string]*[]float64{
+		"response_time_ms": &history.ResponseTimeMs,
+		"link_count":       &history.LinkCount,
+	} {
+		points, err := r.store.MetricSeries(storage.MetricFilter{Metric: metric, URL: result.URL, Limit: anomalyWindow})
+		if err != nil {
+			slog.Error("failed to load monitor history", "monitor", m.ID, "metric", metric, "error", err)
+			return nil
+		}
+		for _, point := range points {
+			*values = append(*values, point.Value)
+		}
+	}
+	return Anomalies(history, result)
+}
+
+// paused reports whether the monitor was paused during its run, which
+// keeps the result but suppresses its alerts
+func (r *Runner) paused(id string) bool {
+	m, err := r.store.Monitor(id)
+	return err == nil && m.PausedAt(time.Now())
+}
+
+func (r *Runner) update(m *storage.Monitor) {
+	// A monitor deleted during its run stays deleted
+	if err := r.store.UpdateMonitorRun(m); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		slog.Error("failed to update monitor", "monitor", m.ID, "error", err)
+	}
+}
+
+func (r *Runner) audit(actor, target, detail string) {
+	entry := &storage.AuditEntry{Actor: actor, Action: storage.AuditAnalysisRun, Target: target, Detail: detail}
+	if err := r.store.RecordAudit(entry); err != nil {
+		slog.Error("failed to record audit entry", "action", entry.Action, "error", err)
+	}
+}
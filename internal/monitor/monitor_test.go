@@ -0,0 +1,315 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"website-analyzer/internal/analyzer"
+	"website-analyzer/internal/jobs"
+	"website-analyzer/internal/models"
+	"website-analyzer/internal/storage"
+)
+
+// recordingNotifier remembers the runs it was told about
+type recordingNotifier struct {
+	runs     []string
+	failures []string
+}
+
+func (n *recordingNotifier) NotifyRun(_ context.Context, m *storage.Monitor, record *storage.Record) error {
+	n.runs = append(n.runs, record.ID)
+	return nil
+}
+
+func (n *recordingNotifier) NotifyFailure(_ context.Context, m *storage.Monitor, runErr error) error {
+	n.failures = append(n.failures, runErr.Error())
+	return nil
+}
+
+// countingQueue hands out workers immediately, counting them
+type countingQueue struct {
+	acquired, released int
+	priorities         []jobs.Priority
+}
+
+func (q *countingQueue) Acquire(_ context.Context, priority jobs.Priority) (func(), error) {
+	q.acquired++
+	q.priorities = append(q.priorities, priority)
+	return func() { q.released++ }, nil
+}
+
+func TestRunnerRunDue(t *testing.T) {
+	// broken swaps the login page for an error page with a dead link
+	var broken atomic.Bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		if broken.Load() {
+			w.Write([]byte(`<html><head><title>Error</title></head><body><a href="/gone">Home</a></body></html>`))
+			return
+		}
+		w.Write([]byte(`<html><head><title>Sign in</title></head><body>
+			<form method="POST"><input type="password" name="pwd"></form></body></html>`))
+	}))
+	defer ts.Close()
+
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	a := analyzer.NewAnalyzer(&analyzer.Config{
+		RequestTimeout:  5 * time.Second,
+		LinkTimeout:     2 * time.Second,
+		MaxWorkers:      2,
+		MaxResponseSize: 1024 * 1024,
+		MaxURLLength:    2048,
+		MaxRedirects:    5,
+	})
+	runner := NewRunner(store, a, time.Minute)
+	var saved []string
+	runner.OnSaved(func(id string) { saved = append(saved, id) })
+	notifier := &recordingNotifier{}
+	runner.SetNotifier(notifier)
+	queue := &countingQueue{}
+	runner.SetQueue(queue)
+
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+	monitor := &storage.Monitor{URL: ts.URL, Schedule: "@hourly", NextRun: now}
+	if err := store.CreateMonitor(monitor); err != nil {
+		t.Fatalf("CreateMonitor failed: %v", err)
+	}
+
+	if ran := runner.RunDue(context.Background(), now.Add(-time.Minute)); ran != 0 {
+		t.Errorf("Expected nothing due before the next run, ran %d", ran)
+	}
+	if ran := runner.RunDue(context.Background(), now); ran != 1 {
+		t.Fatalf("Expected the monitor to run, ran %d", ran)
+	}
+	first, err := store.Monitor(monitor.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.LastAnalysis == "" || first.LastError != "" || first.Regressions != 0 {
+		t.Errorf("Expected a clean first run, got %+v", first)
+	}
+	if !first.NextRun.Equal(now.Add(time.Hour)) || !first.LastRun.Equal(now) {
+		t.Errorf("Expected the next run an hour later, got %+v", first)
+	}
+	if len(saved) != 1 || saved[0] != first.LastAnalysis {
+		t.Errorf("Expected OnSaved with the stored result, got %v", saved)
+	}
+	if len(notifier.runs) != 1 || notifier.runs[0] != first.LastAnalysis {
+		t.Errorf("Expected the run to be notified, got %v", notifier.runs)
+	}
+
+	broken.Store(true)
+	later := now.Add(time.Hour)
+	if ran := runner.RunDue(context.Background(), later); ran != 1 {
+		t.Fatalf("Expected the monitor to run again, ran %d", ran)
+	}
+	second, err := store.Monitor(monitor.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.LastAnalysis == first.LastAnalysis || second.Regressions != 3 {
+		t.Fatalf("Expected three regressions in a new analysis, got %+v", second)
+	}
+	record, err := store.Get(second.LastAnalysis)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := make(map[string]bool)
+	for _, regression := range record.Result.Regressions {
+		kinds[regression.Kind] = true
+	}
+	for _, kind := range []string{models.RegressionBrokenLink, models.RegressionTitleChanged, models.RegressionLoginForm} {
+		if !kinds[kind] {
+			t.Errorf("Expected a %s regression in the stored result, got %+v", kind, record.Result.Regressions)
+		}
+	}
+
+	entries, err := store.AuditLog(storage.AuditFilter{Actor: "monitor:" + monitor.ID})
+	if err != nil || len(entries) != 2 {
+		t.Errorf("Expected both runs to be audited, got %+v (%v)", entries, err)
+	}
+
+	// Domains denied after registration are no longer contacted
+	host := strings.TrimPrefix(ts.URL, "http://")
+	host = host[:strings.LastIndex(host, ":")]
+	if err := store.Deny(&storage.DenyEntry{Domain: host}); err != nil {
+		t.Fatal(err)
+	}
+	runner.RunDue(context.Background(), later.Add(time.Hour))
+	third, err := store.Monitor(monitor.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(third.LastError, "do-not-analyze") || third.LastAnalysis != second.LastAnalysis {
+		t.Errorf("Expected the denied run to fail, got %+v", third)
+	}
+	if len(notifier.failures) != 1 || len(notifier.runs) != 2 {
+		t.Errorf("Expected two runs and one failure notified, got %v and %v", notifier.runs, notifier.failures)
+	}
+	// The denied run never needed a worker
+	if queue.acquired != 2 || queue.released != 2 || queue.priorities[0] != jobs.PriorityScheduled {
+		t.Errorf("Expected both analyses to hold a scheduled worker, got %+v", queue)
+	}
+}
+
+// pausingQueue pauses a monitor while its run waits for a worker
+type pausingQueue struct {
+	store storage.Store
+	id    string
+}
+
+func (q *pausingQueue) Acquire(_ context.Context, _ jobs.Priority) (func(), error) {
+	return func() {}, q.store.PauseMonitor(q.id, time.Time{})
+}
+
+func TestRunnerPaused(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>Status</title></head><body></body></html>`))
+	}))
+	defer ts.Close()
+
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	a := analyzer.NewAnalyzer(&analyzer.Config{
+		RequestTimeout:  5 * time.Second,
+		LinkTimeout:     2 * time.Second,
+		MaxWorkers:      2,
+		MaxResponseSize: 1024 * 1024,
+		MaxURLLength:    2048,
+		MaxRedirects:    5,
+	})
+	runner := NewRunner(store, a, time.Minute)
+	notifier := &recordingNotifier{}
+	runner.SetNotifier(notifier)
+	var saved []string
+	runner.OnSaved(func(id string) { saved = append(saved, id) })
+
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+	monitor := &storage.Monitor{URL: ts.URL, Schedule: "@hourly", NextRun: now}
+	if err := store.CreateMonitor(monitor); err != nil {
+		t.Fatalf("CreateMonitor failed: %v", err)
+	}
+
+	resume := now.Add(3 * time.Hour)
+	if err := store.PauseMonitor(monitor.ID, resume); err != nil {
+		t.Fatal(err)
+	}
+	if ran := runner.RunDue(context.Background(), now.Add(time.Hour)); ran != 0 {
+		t.Errorf("Expected a paused monitor not to run, ran %d", ran)
+	}
+	if ran := runner.RunDue(context.Background(), resume); ran != 1 {
+		t.Fatalf("Expected the monitor to run at its resume time, ran %d", ran)
+	}
+	resumed, err := store.Monitor(monitor.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumed.Paused || !resumed.PausedUntil.IsZero() || !resumed.NextRun.Equal(resume.Add(time.Hour)) {
+		t.Errorf("Expected the pause to be cleared and the schedule to continue, got %+v", resumed)
+	}
+	if len(notifier.runs) != 1 || len(saved) != 1 {
+		t.Errorf("Expected the resumed run to be notified and hooked, got %v and %v", notifier.runs, saved)
+	}
+
+	// Pausing during a run keeps the result without alerting
+	runner.SetQueue(&pausingQueue{store: store, id: monitor.ID})
+	if ran := runner.RunDue(context.Background(), resume.Add(time.Hour)); ran != 1 {
+		t.Fatalf("Expected the monitor to run, ran %d", ran)
+	}
+	paused, err := store.Monitor(monitor.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !paused.Paused || paused.LastAnalysis == resumed.LastAnalysis {
+		t.Errorf("Expected a stored run on a paused monitor, got %+v", paused)
+	}
+	if len(notifier.runs) != 1 || len(saved) != 1 {
+		t.Errorf("Expected no notification or hook for a run paused midway, got %v and %v", notifier.runs, saved)
+	}
+}
+
+func TestRunnerAnomalies(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>Shop</title></head><body><a href="/">Home</a></body></html>`))
+	}))
+	defer ts.Close()
+
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	// The page used to have forty links; its template now renders one
+	links := make([]string, 40)
+	for i := range links {
+		links[i] = fmt.Sprintf("%s/product/%d", ts.URL, i)
+	}
+	for range anomalyMinHistory {
+		if _, err := store.Save(ts.URL, &models.AnalysisResult{URL: ts.URL, Title: "Shop", Links: links}, storage.Labels{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := analyzer.NewAnalyzer(&analyzer.Config{
+		RequestTimeout:  5 * time.Second,
+		LinkTimeout:     2 * time.Second,
+		MaxWorkers:      2,
+		MaxResponseSize: 1024 * 1024,
+		MaxURLLength:    2048,
+		MaxRedirects:    5,
+	})
+	runner := NewRunner(store, a, time.Minute)
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+	monitor := &storage.Monitor{URL: ts.URL, Schedule: "@hourly", NextRun: now}
+	if err := store.CreateMonitor(monitor); err != nil {
+		t.Fatalf("CreateMonitor failed: %v", err)
+	}
+
+	if ran := runner.RunDue(context.Background(), now); ran != 1 {
+		t.Fatalf("Expected the monitor to run, ran %d", ran)
+	}
+	got, err := store.Monitor(monitor.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, err := store.Get(got.LastAnalysis)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Regressions != 1 || record.Result.Regressions[0].Kind != models.RegressionLinkCollapse {
+		t.Errorf("Expected a link count collapse, got %+v", record.Result.Regressions)
+	}
+}
@@ -0,0 +1,40 @@
+package monitor
+
+import (
+	"fmt"
+
+	"website-analyzer/internal/models"
+)
+
+// Regressions lists what got worse from previous to current: links that
+// broke since, a changed title and a login form that disappeared, which
+// often means a sign-in page was replaced by an error page
+func Regressions(previous, current *models.AnalysisResult) []models.Regression {
+	var regressions []models.Regression
+
+	known := make(map[string]bool, len(previous.InaccessibleLinks))
+	for _, link := range previous.InaccessibleLinks {
+		known[link.URL] = true
+	}
+	for _, link := range current.InaccessibleLinks {
+		if !known[link.URL] {
+			regressions = append(regressions, models.Regression{Kind: models.RegressionBrokenLink, Detail: link.URL})
+		}
+	}
+
+	if previous.Title != current.Title {
+		regressions = append(regressions, models.Regression{
+			Kind:   models.RegressionTitleChanged,
+			Detail: fmt.Sprintf("%q changed to %q", previous.Title, current.Title),
+		})
+	}
+
+	if previous.HasLoginForm && !current.HasLoginForm {
+		regressions = append(regressions, models.Regression{
+			Kind:   models.RegressionLoginForm,
+			Detail: "the page no longer has a login form",
+		})
+	}
+
+	return regressions
+}
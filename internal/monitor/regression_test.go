@@ -0,0 +1,38 @@
+package monitor
+
+import (
+	"reflect"
+	"testing"
+
+	"website-analyzer/internal/models"
+)
+
+func TestRegressions(t *testing.T) {
+	previous := &models.AnalysisResult{
+		Title:             "Sign in",
+		HasLoginForm:      true,
+		InaccessibleLinks: []models.LinkError{{URL: "https://example.com/old"}},
+	}
+
+	if got := Regressions(previous, previous); len(got) != 0 {
+		t.Errorf("Expected an unchanged page to have no regressions, got %+v", got)
+	}
+
+	current := &models.AnalysisResult{
+		Title:             "Service Unavailable",
+		InaccessibleLinks: []models.LinkError{{URL: "https://example.com/old"}, {URL: "https://example.com/new"}},
+	}
+	want := []models.Regression{
+		{Kind: models.RegressionBrokenLink, Detail: "https://example.com/new"},
+		{Kind: models.RegressionTitleChanged, Detail: `"Sign in" changed to "Service Unavailable"`},
+		{Kind: models.RegressionLoginForm, Detail: "the page no longer has a login form"},
+	}
+	if got := Regressions(previous, current); !reflect.DeepEqual(got, want) {
+		t.Errorf("Regressions = %+v, want %+v", got, want)
+	}
+
+	// Fixed links and a new login form are improvements
+	if got := Regressions(current, previous); len(got) != 1 || got[0].Kind != models.RegressionTitleChanged {
+		t.Errorf("Expected only the title change, got %+v", got)
+	}
+}
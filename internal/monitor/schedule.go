@@ -0,0 +1,168 @@
+package monitor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scheduleMacros are the shorthand schedules accepted besides five-field
+// expressions
+var scheduleMacros = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// scheduleFields are the bounds of the five fields of a schedule
+var scheduleFields = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// set is a bitmask of the values a schedule field matches
+type set uint64
+
+func (s set) has(v int) bool {
+	return s&(1<<v) != 0
+}
+
+// Schedule is a cron expression: minute, hour, day of month, month and day
+// of week, evaluated in UTC. Fields accept "*", values, ranges ("1-5"),
+// steps ("*/15", "0-30/10") and comma-separated lists of them; Sunday is 0
+// or 7. As in cron, when both day fields are restricted a day matching
+// either is due.
+type Schedule struct {
+	minute, hour, dom, month, dow set
+	// domAny and dowAny record day fields starting with "*"
+	domAny, dowAny bool
+}
+
+// ParseSchedule reads a five-field cron expression or one of @hourly,
+// @daily, @weekly and @monthly
+func ParseSchedule(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if expr, ok := scheduleMacros[spec]; ok {
+		spec = expr
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != len(scheduleFields) {
+		return nil, fmt.Errorf("schedule %q must have five fields: minute hour day-of-month month day-of-week", spec)
+	}
+
+	sets := make([]set, len(fields))
+	for i, field := range fields {
+		s, err := parseField(field, scheduleFields[i].min, scheduleFields[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in schedule %q: %w", scheduleFields[i].name, spec, err)
+		}
+		sets[i] = s
+	}
+
+	// Sunday may be written as 7
+	dow := sets[4]
+	if dow.has(7) {
+		dow = dow&^(1<<7) | 1
+	}
+	schedule := &Schedule{
+		minute: sets[0],
+		hour:   sets[1],
+		dom:    sets[2],
+		month:  sets[3],
+		dow:    dow,
+		domAny: strings.HasPrefix(fields[2], "*"),
+		dowAny: strings.HasPrefix(fields[4], "*"),
+	}
+	if schedule.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("schedule %q is never due", spec)
+	}
+	return schedule, nil
+}
+
+// parseField reads one comma-separated field with values in [min, max]
+func parseField(field string, min, max int) (set, error) {
+	var s set
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("bad step %q", part)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		if rangePart != "*" {
+			from, to, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = strconv.Atoi(from); err != nil {
+				return 0, fmt.Errorf("bad value %q", part)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(to); err != nil {
+					return 0, fmt.Errorf("bad range %q", part)
+				}
+			} else if hasStep {
+				// "5/15" runs from 5 to the end of the field
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q is outside %d-%d", part, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			s |= 1 << v
+		}
+	}
+	return s, nil
+}
+
+// Next returns the first time after t that the schedule is due, or the zero
+// time when it never is, e.g. "0 0 30 2 *"
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	// Every valid schedule is due within a leap year cycle
+	limit := t.AddDate(8, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case !s.month.has(int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case !s.hour.has(t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, time.UTC)
+		case !s.minute.has(t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom, dow := s.dom.has(t.Day()), s.dow.has(int(t.Weekday()))
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dow
+	case s.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
@@ -0,0 +1,93 @@
+// Package portfolio rolls the latest analyses of a project's key pages up
+// into a single report and delivers it on the project's schedule
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"website-analyzer/internal/models"
+	"website-analyzer/internal/storage"
+)
+
+// MaxKeyURLs bounds the pages a project's report rolls up
+const MaxKeyURLs = 100
+
+// Page is a key page as of its latest stored analysis
+type Page struct {
+	URL         string              `json:"url"`
+	AnalysisID  string              `json:"analysis_id"`
+	Title       string              `json:"title"`
+	AnalyzedAt  time.Time           `json:"analyzed_at"`
+	Scores      *models.Scores      `json:"scores,omitempty"`
+	BrokenLinks int                 `json:"broken_links"`
+	Regressions []models.Regression `json:"regressions,omitempty"`
+}
+
+// Report is the roll-up of a project's key pages. Pages are ordered worst
+// overall score first, with unscored pages last.
+type Report struct {
+	Project     string    `json:"project"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Pages       []Page    `json:"pages"`
+	// NotAnalyzed lists key pages without a stored analysis
+	NotAnalyzed []string `json:"not_analyzed,omitempty"`
+	BrokenLinks int      `json:"broken_links"`
+	// Regressed counts pages whose latest analysis flagged regressions
+	Regressed int `json:"regressed"`
+}
+
+// Build reports on the latest stored analysis of each of the project's
+// key pages
+func Build(store storage.Store, project *storage.Project, now time.Time) (*Report, error) {
+	report := &Report{Project: project.Name, GeneratedAt: now.UTC(), Pages: []Page{}}
+	for _, url := range project.KeyURLs {
+		record, err := store.Latest(url)
+		if errors.Is(err, storage.ErrNotFound) {
+			report.NotAnalyzed = append(report.NotAnalyzed, url)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the latest analysis of %s: %w", url, err)
+		}
+
+		result := record.Result
+		page := Page{
+			URL:         url,
+			AnalysisID:  record.ID,
+			Title:       result.Title,
+			AnalyzedAt:  record.CreatedAt,
+			Scores:      result.Scores,
+			BrokenLinks: len(result.InaccessibleLinks),
+			Regressions: result.Regressions,
+		}
+		report.BrokenLinks += page.BrokenLinks
+		if len(page.Regressions) > 0 {
+			report.Regressed++
+		}
+		report.Pages = append(report.Pages, page)
+	}
+
+	sort.SliceStable(report.Pages, func(i, j int) bool {
+		a, b := report.Pages[i].Scores, report.Pages[j].Scores
+		if a == nil || b == nil {
+			return b == nil && a != nil
+		}
+		return a.Overall < b.Overall
+	})
+	return report, nil
+}
+
+// RegressedPages returns the pages whose latest analysis flagged
+// regressions, in report order
+func (r *Report) RegressedPages() []Page {
+	var pages []Page
+	for _, page := range r.Pages {
+		if len(page.Regressions) > 0 {
+			pages = append(pages, page)
+		}
+	}
+	return pages
+}
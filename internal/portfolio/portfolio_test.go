@@ -0,0 +1,128 @@
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"website-analyzer/internal/models"
+	"website-analyzer/internal/storage"
+)
+
+// recordingNotifier remembers the reports it was handed
+type recordingNotifier struct {
+	reports []*Report
+	err     error
+}
+
+func (n *recordingNotifier) NotifyReport(_ context.Context, _ *storage.Project, report *Report) error {
+	n.reports = append(n.reports, report)
+	return n.err
+}
+
+func newStore(t *testing.T) *storage.SQLiteStore {
+	t.Helper()
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestBuild(t *testing.T) {
+	store := newStore(t)
+	project := &storage.Project{Name: "shop", KeyURLs: []string{
+		"https://shop.example/", "https://shop.example/cart", "https://shop.example/about", "https://shop.example/new",
+	}}
+	if err := store.SaveProject(project); err != nil {
+		t.Fatalf("SaveProject failed: %v", err)
+	}
+
+	broken := []models.LinkError{{URL: "https://shop.example/a"}, {URL: "https://shop.example/b"}}
+	for _, saved := range []struct {
+		url    string
+		result *models.AnalysisResult
+	}{
+		// Only the latest analysis of a page counts
+		{"https://shop.example/", &models.AnalysisResult{Title: "Old", Scores: &models.Scores{Overall: 10}}},
+		{"https://shop.example/", &models.AnalysisResult{Title: "Home", Scores: &models.Scores{Overall: 90}, InaccessibleLinks: broken[:1]}},
+		{"https://shop.example/cart", &models.AnalysisResult{
+			Title: "Cart", Scores: &models.Scores{Overall: 40}, InaccessibleLinks: broken,
+			Regressions: []models.Regression{{Kind: models.RegressionBrokenLink, Detail: "https://shop.example/b"}},
+		}},
+		{"https://shop.example/about", &models.AnalysisResult{Title: "About"}},
+		{"https://other.example/", &models.AnalysisResult{Title: "Elsewhere", Scores: &models.Scores{Overall: 1}}},
+	} {
+		if _, err := store.Save(saved.url, saved.result, storage.Labels{}); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	report, err := Build(store, project, time.Now())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	var titles []string
+	for _, page := range report.Pages {
+		titles = append(titles, page.Title)
+	}
+	if !slices.Equal(titles, []string{"Cart", "Home", "About"}) {
+		t.Errorf("Expected pages worst score first and unscored last, got %v", titles)
+	}
+	if !slices.Equal(report.NotAnalyzed, []string{"https://shop.example/new"}) {
+		t.Errorf("Expected the unanalyzed page to be listed, got %v", report.NotAnalyzed)
+	}
+	if report.BrokenLinks != 3 || report.Regressed != 1 {
+		t.Errorf("Expected 3 broken links and 1 regressed page, got %d and %d", report.BrokenLinks, report.Regressed)
+	}
+	if regressed := report.RegressedPages(); len(regressed) != 1 || regressed[0].URL != "https://shop.example/cart" {
+		t.Errorf("Expected the cart to have regressed, got %+v", regressed)
+	}
+}
+
+func TestRunnerRunDue(t *testing.T) {
+	store := newStore(t)
+	now := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	for _, project := range []*storage.Project{
+		{Name: "due", KeyURLs: []string{"https://example.com/"}, ReportSchedule: "0 9 * * 1", ReportNext: now},
+		{Name: "broken", KeyURLs: []string{"https://example.com/"}, ReportSchedule: "not a schedule", ReportNext: now},
+		{Name: "later", KeyURLs: []string{"https://example.com/"}, ReportSchedule: "@daily", ReportNext: now.Add(time.Hour)},
+	} {
+		if err := store.SaveProject(project); err != nil {
+			t.Fatalf("SaveProject failed: %v", err)
+		}
+	}
+
+	notifier := &recordingNotifier{}
+	runner := NewRunner(store, notifier, time.Minute)
+	if sent := runner.RunDue(context.Background(), now); sent != 1 {
+		t.Fatalf("Expected one report to be sent, got %d", sent)
+	}
+	if len(notifier.reports) != 1 || notifier.reports[0].Project != "due" || !slices.Equal(notifier.reports[0].NotAnalyzed, []string{"https://example.com/"}) {
+		t.Errorf("Unexpected reports: %+v", notifier.reports)
+	}
+
+	due, _ := store.Project("due")
+	if want := now.Add(7 * 24 * time.Hour); !due.ReportNext.Equal(want) {
+		t.Errorf("Expected the next report at %v, got %v", want, due.ReportNext)
+	}
+	broken, _ := store.Project("broken")
+	if want := now.Add(invalidScheduleRetry); !broken.ReportNext.Equal(want) {
+		t.Errorf("Expected an invalid schedule to be retried at %v, got %v", want, broken.ReportNext)
+	}
+
+	// A failed delivery waits for the next scheduled report
+	notifier.err = errors.New("webhook down")
+	if sent := runner.RunDue(context.Background(), now.Add(7*24*time.Hour)); sent != 0 {
+		t.Errorf("Expected a failed delivery not to count, got %d", sent)
+	}
+	due, _ = store.Project("due")
+	if want := now.Add(14 * 24 * time.Hour); !due.ReportNext.Equal(want) {
+		t.Errorf("Expected a failed report to be rescheduled at %v, got %v", want, due.ReportNext)
+	}
+}
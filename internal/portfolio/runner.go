@@ -0,0 +1,99 @@
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"website-analyzer/internal/monitor"
+	"website-analyzer/internal/storage"
+)
+
+// invalidScheduleRetry is how long a project whose report schedule no
+// longer parses waits before it is tried again
+const invalidScheduleRetry = 24 * time.Hour
+
+// Notifier delivers finished reports
+type Notifier interface {
+	NotifyReport(ctx context.Context, project *storage.Project, report *Report) error
+}
+
+// Runner builds and delivers the reports of projects as they fall due
+type Runner struct {
+	store    storage.Store
+	notifier Notifier
+	interval time.Duration
+}
+
+// NewRunner returns a runner that checks store for due reports every
+// interval and hands them to notifier
+func NewRunner(store storage.Store, notifier Notifier, interval time.Duration) *Runner {
+	return &Runner{store: store, notifier: notifier, interval: interval}
+}
+
+// Run delivers due reports every interval until ctx is cancelled
+func (r *Runner) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.RunDue(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue delivers each report due at now and returns how many were sent
+func (r *Runner) RunDue(ctx context.Context, now time.Time) int {
+	due, err := r.store.DueReports(now)
+	if err != nil {
+		slog.Error("failed to list due reports", "error", err)
+		return 0
+	}
+
+	sent := 0
+	for i := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		if r.run(ctx, &due[i], now) {
+			sent++
+		}
+	}
+	return sent
+}
+
+// run delivers the project's report and schedules the next one. A failed
+// delivery is logged and not retried before the next scheduled report.
+func (r *Runner) run(ctx context.Context, project *storage.Project, now time.Time) bool {
+	schedule, err := monitor.ParseSchedule(project.ReportSchedule)
+	if err != nil {
+		slog.Error("invalid report schedule", "project", project.Name, "error", err)
+		r.reschedule(project, now.Add(invalidScheduleRetry))
+		return false
+	}
+	defer r.reschedule(project, schedule.Next(now))
+
+	report, err := Build(r.store, project, now)
+	if err != nil {
+		slog.Error("failed to build portfolio report", "project", project.Name, "error", err)
+		return false
+	}
+	if err := r.notifier.NotifyReport(ctx, project, report); err != nil {
+		slog.Error("failed to deliver portfolio report", "project", project.Name, "error", err)
+		return false
+	}
+	slog.Info("portfolio report delivered", "project", project.Name, "pages", len(report.Pages), "regressed", report.Regressed)
+	return true
+}
+
+func (r *Runner) reschedule(project *storage.Project, next time.Time) {
+	// A project deleted meanwhile stays deleted
+	if err := r.store.SetReportNext(project.Name, next); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		slog.Error("failed to schedule portfolio report", "project", project.Name, "error", err)
+	}
+}
@@ -0,0 +1,61 @@
+package redact
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogHandler wraps h so log messages and string or error attributes are
+// redacted before they are written. A nil Redactor returns h unchanged.
+func (r *Redactor) LogHandler(h slog.Handler) slog.Handler {
+	if r == nil {
+		return h
+	}
+	return &logHandler{Handler: h, redactor: r}
+}
+
+type logHandler struct {
+	slog.Handler
+	redactor *Redactor
+}
+
+func (h *logHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, h.redactor.Text(record.Message), record.PC)
+	record.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(h.redactor.attr(a))
+		return true
+	})
+	return h.Handler.Handle(ctx, redacted)
+}
+
+func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.redactor.attr(a)
+	}
+	return &logHandler{Handler: h.Handler.WithAttrs(redacted), redactor: h.redactor}
+}
+
+func (h *logHandler) WithGroup(name string) slog.Handler {
+	return &logHandler{Handler: h.Handler.WithGroup(name), redactor: h.redactor}
+}
+
+func (r *Redactor) attr(a slog.Attr) slog.Attr {
+	value := a.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, r.Text(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		attrs := make([]any, len(group))
+		for i, g := range group {
+			attrs[i] = r.attr(g)
+		}
+		return slog.Group(a.Key, attrs...)
+	case slog.KindAny:
+		if err, ok := value.Any().(error); ok {
+			return slog.String(a.Key, r.Text(err.Error()))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: value}
+}
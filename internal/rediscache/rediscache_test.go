@@ -0,0 +1,60 @@
+package rediscache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestCache(t *testing.T) {
+	server := miniredis.RunT(t)
+	ctx := context.Background()
+
+	if _, err := New(ctx, "redis://"+server.Addr(), 0); err == nil {
+		t.Error("Expected a zero TTL to be rejected")
+	}
+	if _, err := New(ctx, "http://"+server.Addr(), time.Minute); err == nil {
+		t.Error("Expected a non-Redis URL to be rejected")
+	}
+
+	cache, err := New(ctx, "redis://"+server.Addr(), time.Minute)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer cache.Close()
+
+	if _, ok := cache.Get(ctx, "page"); ok {
+		t.Error("Expected a miss on an empty cache")
+	}
+	cache.Set(ctx, "page", []byte(`{"title":"Home"}`))
+	if value, ok := cache.Get(ctx, "page"); !ok || string(value) != `{"title":"Home"}` {
+		t.Errorf("Get = %q, %v", value, ok)
+	}
+	if ttl := server.TTL(keyPrefix + "page"); ttl != time.Minute {
+		t.Errorf("Expected the entry to expire in a minute, got %v", ttl)
+	}
+
+	cache.Set(ctx, "other", []byte("{}"))
+	cache.Delete(ctx, func(key string) bool { return key == "page" })
+	if _, ok := cache.Get(ctx, "page"); ok {
+		t.Error("Expected the matching entry to be deleted")
+	}
+	if _, ok := cache.Get(ctx, "other"); !ok {
+		t.Error("Expected other entries to be kept")
+	}
+	cache.Set(ctx, "page", []byte(`{"title":"Home"}`))
+
+	server.FastForward(time.Minute)
+	if _, ok := cache.Get(ctx, "page"); ok {
+		t.Error("Expected the entry to expire")
+	}
+
+	// An unreachable server is a miss, not an error
+	server.Close()
+	cache.Set(ctx, "page", []byte("x"))
+	if _, ok := cache.Get(ctx, "page"); ok {
+		t.Error("Expected a miss while Redis is down")
+	}
+}
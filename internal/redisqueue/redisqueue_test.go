@@ -0,0 +1,157 @@
+package redisqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	"website-analyzer/internal/jobs"
+	"website-analyzer/internal/models"
+)
+
+func TestQueue(t *testing.T) {
+	server := miniredis.RunT(t)
+	ctx := context.Background()
+
+	if _, err := New(ctx, "http://"+server.Addr()); err == nil {
+		t.Error("Expected a non-Redis URL to be rejected")
+	}
+
+	q, err := New(ctx, "redis://"+server.Addr())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer q.Close()
+
+	for _, task := range []jobs.Task{
+		{ID: "monitor", Kind: jobs.KindCrawl, Priority: jobs.PriorityScheduled},
+		{ID: "api", Kind: jobs.KindBatch, Priority: jobs.PriorityAPI, Payload: json.RawMessage(`["https://example.com/"]`)},
+		{ID: "web", Kind: jobs.KindCrawl, Priority: jobs.PriorityInteractive},
+	} {
+		if err := q.Push(ctx, task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if position, _ := q.Position(ctx, "monitor"); position != 3 {
+		t.Errorf("Expected the scheduled task last, at 3, got %d", position)
+	}
+
+	for _, want := range []string{"web", "api", "monitor"} {
+		task, err := q.Pop(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if task.ID != want {
+			t.Errorf("Popped %q, want %q", task.ID, want)
+		}
+		if want == "api" && string(task.Payload) != `["https://example.com/"]` {
+			t.Errorf("Expected the payload to survive, got %s", task.Payload)
+		}
+	}
+	if position, _ := q.Position(ctx, "monitor"); position != 0 {
+		t.Errorf("Expected a popped task to leave the queue, at %d", position)
+	}
+
+	job := models.Job{ID: "api", Kind: jobs.KindBatch, State: jobs.StateCompleted, Result: map[string]int{"succeeded": 1}}
+	if err := q.Save(ctx, job, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := server.TTL(jobKey("api")); ttl != time.Hour {
+		t.Errorf("Expected the job to expire in an hour, got %v", ttl)
+	}
+	loaded, err := q.Load(ctx, "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw, ok := loaded.Result.(json.RawMessage); loaded.State != jobs.StateCompleted || !ok || string(raw) != `{"succeeded":1}` {
+		t.Errorf("Unexpected loaded job %+v", loaded)
+	}
+	if _, err := q.Load(ctx, "missing"); !errors.Is(err, jobs.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := q.Pop(cancelled); err == nil {
+		t.Error("Expected Pop to end with its context")
+	}
+}
+
+// TestReplicasShareJobs runs two managers of one worker each on one queue:
+// two jobs submitted to the first run at once, one on each, and the
+// second reports their results
+func TestReplicasShareJobs(t *testing.T) {
+	server := miniredis.RunT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan string, 2)
+	release := make(chan struct{})
+	replica := func(name string) *jobs.Manager {
+		q, err := New(ctx, "redis://"+server.Addr())
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		t.Cleanup(func() { q.Close() })
+
+		m := jobs.NewManager(ctx, 1)
+		m.Handle(jobs.KindCrawl, func(ctx context.Context, payload json.RawMessage, progress *jobs.Tracker) (any, error) {
+			started <- name
+			<-release
+			return name, nil
+		})
+		m.SetQueue(q)
+		return m
+	}
+	a, b := replica("a"), replica("b")
+
+	var submitted []models.Job
+	for range 2 {
+		job, err := a.Submit(jobs.KindCrawl, "https://example.com/", jobs.PriorityAPI, "https://example.com/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		submitted = append(submitted, job)
+	}
+
+	ran := map[string]bool{}
+	for range 2 {
+		select {
+		case name := <-started:
+			ran[name] = true
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected both jobs to start at once, started on %v", ran)
+		}
+	}
+	if !ran["a"] || !ran["b"] {
+		t.Errorf("Expected each replica to run a job, ran on %v", ran)
+	}
+	close(release)
+
+	for _, job := range submitted {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			got, err := b.Get(job.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.State == jobs.StateCompleted {
+				if _, ok := got.Result.(json.RawMessage); !ok || got.StartedAt == nil || got.Progress.Percent != 100 {
+					t.Errorf("Unexpected completed job %+v", got)
+				}
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Job %s did not complete, %+v", job.ID, got)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	if _, err := b.Get("missing"); !errors.Is(err, jobs.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+// Package rescore re-runs the checks and scoring over every stored
+// snapshot after check logic changes, without fetching any page. The
+// original findings are kept next to the new ones for comparison.
+package rescore
+
+import (
+	"context"
+	"log/slog"
+
+	"website-analyzer/internal/analyzer"
+	"website-analyzer/internal/models"
+	"website-analyzer/internal/storage"
+)
+
+// Summary reports the outcome of a re-scoring run
+type Summary struct {
+	Snapshots int      `json:"snapshots"`
+	Rescored  int      `json:"rescored"`
+	Failed    int      `json:"failed"`
+	Changes   []Change `json:"changes,omitempty"`
+}
+
+// Change is an analysis whose overall score moved when re-scored
+type Change struct {
+	ID     string `json:"id"`
+	URL    string `json:"url"`
+	Before int    `json:"before"`
+	After  int    `json:"after"`
+}
+
+// Run replays every stored snapshot with a's current checks, in each
+// analysis' original profile, and saves the result as its re-scoring.
+// Analyses that fail are logged and counted; cancelling ctx stops the run
+// and returns the summary so far with ctx's error.
+func Run(ctx context.Context, store storage.Store, a *analyzer.Analyzer) (*Summary, error) {
+	ids, err := store.SnapshotIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &Summary{Snapshots: len(ids)}
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+		change, err := rescore(ctx, store, a, id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return summary, ctx.Err()
+			}
+			slog.Warn("failed to rescore analysis", "id", id, "error", err)
+			summary.Failed++
+			continue
+		}
+		summary.Rescored++
+		if change != nil {
+			summary.Changes = append(summary.Changes, *change)
+		}
+	}
+	return summary, nil
+}
+
+// rescore re-scores one analysis, returning its score change if any
+func rescore(ctx context.Context, store storage.Store, a *analyzer.Analyzer, id string) (*Change, error) {
+	record, err := store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := store.Snapshot(id)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := a.Replay(ctx, snapshot, record.Result, analyzer.AnalyzeOptions{
+		IncludeLinks: len(record.Result.LinkDetails) > 0,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.ReplayOf = id
+	if err := store.SaveRescore(id, result); err != nil {
+		return nil, err
+	}
+
+	before, after := overall(record.Result), overall(result)
+	if before == after {
+		return nil, nil
+	}
+	return &Change{ID: id, URL: record.URL, Before: before, After: after}, nil
+}
+
+func overall(result *models.AnalysisResult) int {
+	if result.Scores == nil {
+		return 0
+	}
+	return result.Scores.Overall
+}
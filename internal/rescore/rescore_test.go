@@ -0,0 +1,81 @@
+package rescore
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"website-analyzer/internal/analyzer"
+	"website-analyzer/internal/models"
+	"website-analyzer/internal/storage"
+)
+
+func TestRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html lang="en"><head><title>Rescore</title></head><body><h1>Hi</h1></body></html>`))
+	}))
+	defer server.Close()
+
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	a := analyzer.NewAnalyzer(&analyzer.Config{
+		RequestTimeout:  5 * time.Second,
+		LinkTimeout:     2 * time.Second,
+		MaxWorkers:      2,
+		MaxResponseSize: 1024 * 1024,
+		MaxURLLength:    2048,
+		KeepSnapshots:   true,
+	})
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	result, err := a.Analyze(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	want := result.Scores.Overall
+	// Pretend the page was scored by older checks
+	result.Scores.Overall = want - 10
+	saved, err := store.Save(server.URL, result, storage.Labels{})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err := store.Save("https://example.com/", &models.AnalysisResult{Title: "No snapshot"}, storage.Labels{}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	server.Close()
+	summary, err := Run(context.Background(), store, a)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if summary.Snapshots != 1 || summary.Rescored != 1 || summary.Failed != 0 {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+	if len(summary.Changes) != 1 || summary.Changes[0] != (Change{ID: saved.ID, URL: server.URL, Before: want - 10, After: want}) {
+		t.Errorf("Unexpected changes %+v", summary.Changes)
+	}
+
+	record, err := store.Get(saved.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if record.Result.Scores.Overall != want-10 || record.Rescored == nil || record.Rescored.Scores.Overall != want {
+		t.Errorf("Expected original and rescored results side by side, got %+v", record)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Run(ctx, store, a); err == nil {
+		t.Error("Expected a cancelled run to fail")
+	}
+}
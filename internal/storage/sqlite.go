@@ -0,0 +1,484 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"website-analyzer/internal/models"
+
+	_ "modernc.org/sqlite"
+)
+
+// auditNoUpdateTrigger keeps audit entries from being changed; Erase lifts
+// it briefly to scrub erased targets
+const auditNoUpdateTrigger = `CREATE TRIGGER IF NOT EXISTS audit_log_no_update BEFORE UPDATE ON audit_log
+BEGIN SELECT RAISE(ABORT, 'audit log is append-only'); END;`
+
+const schema = `
+CREATE TABLE IF NOT EXISTS analyses (
+	id         TEXT PRIMARY KEY,
+	url        TEXT NOT NULL,
+	title      TEXT NOT NULL,
+	created_at INTEGER NOT NULL,
+	result     TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS analyses_created_at ON analyses (created_at);
+CREATE TABLE IF NOT EXISTS projects (
+	name           TEXT PRIMARY KEY,
+	description    TEXT NOT NULL,
+	notify_webhook TEXT NOT NULL,
+	notify_email   TEXT NOT NULL,
+	created_at     INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS audit_log (
+	id     INTEGER PRIMARY KEY AUTOINCREMENT,
+	at     INTEGER NOT NULL,
+	actor  TEXT NOT NULL,
+	action TEXT NOT NULL,
+	target TEXT NOT NULL,
+	detail TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS audit_log_action ON audit_log (action, at);
+` + auditNoUpdateTrigger + `
+CREATE TRIGGER IF NOT EXISTS audit_log_no_delete BEFORE DELETE ON audit_log
+BEGIN SELECT RAISE(ABORT, 'audit log is append-only'); END;
+CREATE TABLE IF NOT EXISTS api_keys (
+	id         TEXT PRIMARY KEY,
+	project    TEXT NOT NULL REFERENCES projects (name),
+	prefix     TEXT NOT NULL,
+	key_hash   TEXT NOT NULL UNIQUE,
+	created_at INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS acknowledgements (
+	id         TEXT PRIMARY KEY,
+	host       TEXT NOT NULL,
+	kind       TEXT NOT NULL,
+	target     TEXT NOT NULL,
+	note       TEXT NOT NULL,
+	created_at INTEGER NOT NULL,
+	UNIQUE (host, kind, target)
+);
+CREATE TABLE IF NOT EXISTS baselines (
+	url        TEXT PRIMARY KEY,
+	analysis   TEXT NOT NULL REFERENCES analyses (id),
+	updated_at INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS denylist (
+	domain     TEXT PRIMARY KEY,
+	reason     TEXT NOT NULL,
+	created_at INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS monitors (
+	id            TEXT PRIMARY KEY,
+	url           TEXT NOT NULL,
+	schedule      TEXT NOT NULL,
+	project       TEXT NOT NULL,
+	profile       TEXT NOT NULL,
+	next_run      INTEGER NOT NULL,
+	last_run      INTEGER NOT NULL,
+	last_analysis TEXT NOT NULL,
+	last_error    TEXT NOT NULL,
+	regressions   INTEGER NOT NULL,
+	created_at    INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS monitors_next_run ON monitors (next_run);
+CREATE TABLE IF NOT EXISTS maintenance_windows (
+	id          TEXT PRIMARY KEY,
+	url         TEXT NOT NULL,
+	project     TEXT NOT NULL,
+	schedule    TEXT NOT NULL,
+	duration    INTEGER NOT NULL,
+	description TEXT NOT NULL,
+	created_at  INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS snapshots (
+	analysis    TEXT PRIMARY KEY REFERENCES analyses (id),
+	url         TEXT NOT NULL,
+	body        BLOB NOT NULL,
+	size        INTEGER NOT NULL,
+	header      TEXT NOT NULL,
+	captured_at INTEGER NOT NULL
+);
+`
+
+// SQLiteStore stores analyses in a single SQLite database file
+type SQLiteStore struct {
+	db               *sql.DB
+	respectNoArchive bool
+}
+
+// NewSQLiteStore opens (creating if needed) the database at path
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create storage directory: %w", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// SQLite allows a single writer; serialize access through one connection
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate schema: %w", err)
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+// columnMigrations are columns added to tables after their first release.
+// Tags are stored comma-joined; ParseTags guarantees they contain no commas.
+var columnMigrations = []struct {
+	table, column, definition string
+}{
+	{"analyses", "project", "TEXT NOT NULL DEFAULT ''"},
+	{"analyses", "tags", "TEXT NOT NULL DEFAULT ''"},
+	{"analyses", "api_key", "TEXT NOT NULL DEFAULT ''"},
+	{"analyses", "wall_time_ms", "INTEGER NOT NULL DEFAULT 0"},
+	{"analyses", "pages", "INTEGER NOT NULL DEFAULT 0"},
+	{"analyses", "requests", "INTEGER NOT NULL DEFAULT 0"},
+	{"analyses", "bytes_downloaded", "INTEGER NOT NULL DEFAULT 0"},
+	{"analyses", "peak_goroutines", "INTEGER NOT NULL DEFAULT 0"},
+	{"projects", "notify_format", "TEXT NOT NULL DEFAULT ''"},
+	{"monitors", "webhook", "TEXT NOT NULL DEFAULT ''"},
+	{"monitors", "paused", "INTEGER NOT NULL DEFAULT 0"},
+	{"monitors", "paused_until", "INTEGER NOT NULL DEFAULT 0"},
+	{"projects", "key_urls", "TEXT NOT NULL DEFAULT ''"},
+	{"projects", "report_schedule", "TEXT NOT NULL DEFAULT ''"},
+	{"projects", "report_next", "INTEGER NOT NULL DEFAULT 0"},
+	{"analyses", "rescored", "TEXT NOT NULL DEFAULT ''"},
+	{"analyses", "rescored_at", "INTEGER NOT NULL DEFAULT 0"},
+}
+
+// migrate adds missing columns to databases created by older versions
+func migrate(db *sql.DB) error {
+	for _, m := range columnMigrations {
+		var exists bool
+		err := db.QueryRow(
+			`SELECT COUNT(*) > 0 FROM pragma_table_info(?) WHERE name = ?`, m.table, m.column,
+		).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if _, err := db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, m.table, m.column, m.definition)); err != nil {
+			return err
+		}
+	}
+
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS analyses_project ON analyses (project, created_at)`); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS analyses_api_key ON analyses (api_key, created_at)`); err != nil {
+		return err
+	}
+	_, err := db.Exec(`CREATE INDEX IF NOT EXISTS analyses_url ON analyses (url, created_at)`)
+	return err
+}
+
+func (s *SQLiteStore) Save(url string, result *models.AnalysisResult, labels Labels) (*Record, error) {
+	if labels.Project != "" {
+		if _, err := s.Project(labels.Project); errors.Is(err, ErrNotFound) {
+			return nil, ErrUnknownProject
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ID: %w", err)
+	}
+
+	// Withheld copies drop the snapshot along with the page text
+	snapshot := result.Snapshot
+	if s.respectNoArchive && len(result.ArchiveDirectives) > 0 {
+		if result, err = withholdContent(result); err != nil {
+			return nil, err
+		}
+		snapshot = nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+
+	record := &Record{
+		ID:        id,
+		URL:       url,
+		Project:   labels.Project,
+		Tags:      labels.Tags,
+		CreatedAt: time.Now().UTC(),
+		Result:    result,
+	}
+
+	var usage models.ResourceUsage
+	if result.Usage != nil {
+		usage = *result.Usage
+	}
+
+	// The analysis and its snapshot are saved together or not at all
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start saving analysis: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		`INSERT INTO analyses (id, url, title, project, tags, created_at, result, api_key, wall_time_ms, pages, requests, bytes_downloaded, peak_goroutines)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		record.ID, record.URL, result.Title, record.Project, strings.Join(record.Tags, ","), record.CreatedAt.UnixNano(), string(data),
+		labels.APIKey, usage.WallTimeMs, usage.Pages, usage.Requests, usage.BytesDownloaded, usage.PeakGoroutines,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save analysis: %w", err)
+	}
+	if snapshot != nil {
+		if err := saveSnapshot(tx, record.ID, snapshot); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to save analysis: %w", err)
+	}
+
+	return record, nil
+}
+
+func (s *SQLiteStore) Get(id string) (*Record, error) {
+	var (
+		record     Record
+		tags       string
+		createdAt  int64
+		data       string
+		rescored   string
+		rescoredAt int64
+	)
+
+	err := s.db.QueryRow(
+		`SELECT id, url, project, tags, created_at, result, rescored, rescored_at FROM analyses WHERE id = ?`, id,
+	).Scan(&record.ID, &record.URL, &record.Project, &tags, &createdAt, &data, &rescored, &rescoredAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load analysis: %w", err)
+	}
+
+	record.Tags = splitTags(tags)
+	record.CreatedAt = time.Unix(0, createdAt).UTC()
+	if err := json.Unmarshal([]byte(data), &record.Result); err != nil {
+		return nil, fmt.Errorf("failed to decode result: %w", err)
+	}
+	if rescored != "" {
+		if err := json.Unmarshal([]byte(rescored), &record.Rescored); err != nil {
+			return nil, fmt.Errorf("failed to decode rescored result: %w", err)
+		}
+		record.RescoredAt = time.Unix(0, rescoredAt).UTC()
+	}
+
+	return &record, nil
+}
+
+func (s *SQLiteStore) List(filter Filter) ([]Summary, error) {
+	rows, err := s.db.Query(
+		`SELECT id, url, title, project, tags, created_at FROM analyses
+		 WHERE (? = '' OR project = ?) AND (? = '' OR instr(',' || tags || ',', ',' || ? || ',') > 0)
+		 ORDER BY created_at DESC LIMIT ?`,
+		filter.Project, filter.Project, filter.Tag, filter.Tag, filter.Limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list analyses: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []Summary
+	for rows.Next() {
+		var (
+			summary   Summary
+			tags      string
+			createdAt int64
+		)
+		if err := rows.Scan(&summary.ID, &summary.URL, &summary.Title, &summary.Project, &tags, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to read analysis: %w", err)
+		}
+		summary.Tags = splitTags(tags)
+		summary.CreatedAt = time.Unix(0, createdAt).UTC()
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, rows.Err()
+}
+
+func (s *SQLiteStore) Acknowledge(ack *models.Acknowledgement) error {
+	id, err := newID()
+	if err != nil {
+		return fmt.Errorf("failed to generate ID: %w", err)
+	}
+	ack.ID = id
+	ack.CreatedAt = time.Now().UTC()
+
+	// Acknowledging the same finding again replaces the note
+	_, err = s.db.Exec(
+		`INSERT INTO acknowledgements (id, host, kind, target, note, created_at) VALUES (?, ?, ?, ?, ?, ?)
+		 ON CONFLICT (host, kind, target) DO UPDATE SET id = excluded.id, note = excluded.note, created_at = excluded.created_at`,
+		ack.ID, ack.Host, ack.Kind, ack.Target, ack.Note, ack.CreatedAt.UnixNano(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save acknowledgement: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Acknowledgements(host string) ([]models.Acknowledgement, error) {
+	rows, err := s.db.Query(
+		`SELECT id, host, kind, target, note, created_at FROM acknowledgements WHERE host = ? ORDER BY created_at`, host,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list acknowledgements: %w", err)
+	}
+	defer rows.Close()
+
+	var acks []models.Acknowledgement
+	for rows.Next() {
+		var (
+			ack       models.Acknowledgement
+			createdAt int64
+		)
+		if err := rows.Scan(&ack.ID, &ack.Host, &ack.Kind, &ack.Target, &ack.Note, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to read acknowledgement: %w", err)
+		}
+		ack.CreatedAt = time.Unix(0, createdAt).UTC()
+		acks = append(acks, ack)
+	}
+
+	return acks, rows.Err()
+}
+
+func (s *SQLiteStore) DeleteAcknowledgement(id string) error {
+	res, err := s.db.Exec(`DELETE FROM acknowledgements WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete acknowledgement: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStore) SetBaseline(id string) error {
+	var url string
+	err := s.db.QueryRow(`SELECT url FROM analyses WHERE id = ?`, id).Scan(&url)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load analysis: %w", err)
+	}
+
+	_, err = s.db.Exec(
+		`INSERT INTO baselines (url, analysis, updated_at) VALUES (?, ?, ?)
+		 ON CONFLICT (url) DO UPDATE SET analysis = excluded.analysis, updated_at = excluded.updated_at`,
+		url, id, time.Now().UTC().UnixNano(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save baseline: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Baseline(url string) (*Record, error) {
+	var id string
+	err := s.db.QueryRow(`SELECT analysis FROM baselines WHERE url = ?`, url).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load baseline: %w", err)
+	}
+	return s.Get(id)
+}
+
+func (s *SQLiteStore) Previous(id string) (*Record, error) {
+	var previous string
+	err := s.db.QueryRow(
+		`SELECT p.id FROM analyses a JOIN analyses p ON p.url = a.url AND p.created_at < a.created_at
+		 WHERE a.id = ? ORDER BY p.created_at DESC LIMIT 1`, id,
+	).Scan(&previous)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load previous analysis: %w", err)
+	}
+	return s.Get(previous)
+}
+
+func (s *SQLiteStore) Latest(url string) (*Record, error) {
+	var id string
+	err := s.db.QueryRow(`SELECT id FROM analyses WHERE url = ? ORDER BY created_at DESC LIMIT 1`, url).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load latest analysis: %w", err)
+	}
+	return s.Get(id)
+}
+
+func (s *SQLiteStore) History(filter HistoryFilter) ([]Record, error) {
+	var from int64
+	if !filter.From.IsZero() {
+		from = filter.From.UnixNano()
+	}
+	rows, err := s.db.Query(
+		`SELECT id, url, project, tags, created_at, result FROM analyses
+		 WHERE (? = '' OR url = ?) AND (? = '' OR project = ?) AND created_at >= ?
+		 ORDER BY created_at`,
+		filter.URL, filter.URL, filter.Project, filter.Project, from,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load history: %w", err)
+	}
+	defer rows.Close()
+
+	var records []Record
+	for rows.Next() {
+		var (
+			record    Record
+			tags      string
+			createdAt int64
+			data      string
+		)
+		if err := rows.Scan(&record.ID, &record.URL, &record.Project, &tags, &createdAt, &data); err != nil {
+			return nil, fmt.Errorf("failed to scan analysis: %w", err)
+		}
+		record.Tags = splitTags(tags)
+		record.CreatedAt = time.Unix(0, createdAt).UTC()
+		if err := json.Unmarshal([]byte(data), &record.Result); err != nil {
+			return nil, fmt.Errorf("failed to decode result: %w", err)
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
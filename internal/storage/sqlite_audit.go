@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+func (s *SQLiteStore) RecordAudit(entry *AuditEntry) error {
+	entry.Time = time.Now().UTC()
+
+	res, err := s.db.Exec(
+		`INSERT INTO audit_log (at, actor, action, target, detail) VALUES (?, ?, ?, ?, ?)`,
+		entry.Time.UnixNano(), entry.Actor, entry.Action, entry.Target, entry.Detail,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+
+	entry.ID, err = res.LastInsertId()
+	return err
+}
+
+func (s *SQLiteStore) AuditLog(filter AuditFilter) ([]AuditEntry, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1 // SQLite: no limit
+	}
+
+	rows, err := s.db.Query(
+		`SELECT id, at, actor, action, target, detail FROM audit_log
+		 WHERE (? = '' OR action = ?) AND (? = '' OR actor = ?)
+		 ORDER BY id DESC LIMIT ?`,
+		filter.Action, filter.Action, filter.Actor, filter.Actor, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []AuditEntry
+	for rows.Next() {
+		var (
+			entry AuditEntry
+			at    int64
+		)
+		if err := rows.Scan(&entry.ID, &at, &entry.Actor, &entry.Action, &entry.Target, &entry.Detail); err != nil {
+			return nil, fmt.Errorf("failed to read audit entry: %w", err)
+		}
+		entry.Time = time.Unix(0, at).UTC()
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
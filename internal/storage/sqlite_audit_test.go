@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLiteStoreAuditLog(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	entries := []*AuditEntry{
+		{Actor: "web:10.0.0.1", Action: AuditAnalysisRun, Target: "https://example.com/"},
+		{Actor: "api-key:wa_abc123", Action: AuditBaselineSet, Target: "https://example.com/", Detail: "id=1"},
+		{Actor: "web:10.0.0.1", Action: AuditAPIKeyIssue, Target: "docs"},
+	}
+	for _, entry := range entries {
+		if err := store.RecordAudit(entry); err != nil {
+			t.Fatalf("RecordAudit failed: %v", err)
+		}
+		if entry.ID == 0 || entry.Time.IsZero() {
+			t.Errorf("Expected ID and time to be assigned, got %+v", entry)
+		}
+	}
+
+	all, err := store.AuditLog(AuditFilter{})
+	if err != nil || len(all) != 3 || all[0].Action != AuditAPIKeyIssue {
+		t.Fatalf("Expected all entries newest first, got %+v (%v)", all, err)
+	}
+	if all[1].Detail != "id=1" {
+		t.Errorf("Expected detail to round-trip, got %+v", all[1])
+	}
+
+	byActor, _ := store.AuditLog(AuditFilter{Actor: "web:10.0.0.1"})
+	byAction, _ := store.AuditLog(AuditFilter{Action: AuditBaselineSet})
+	limited, _ := store.AuditLog(AuditFilter{Limit: 1})
+	if len(byActor) != 2 || len(byAction) != 1 || len(limited) != 1 {
+		t.Errorf("Unexpected filtered counts: actor=%d action=%d limit=%d", len(byActor), len(byAction), len(limited))
+	}
+
+	// The table rejects edits and deletions
+	if _, err := store.db.Exec(`UPDATE audit_log SET actor = 'someone-else'`); err == nil {
+		t.Error("Expected update to be rejected")
+	}
+	if _, err := store.db.Exec(`DELETE FROM audit_log`); err == nil {
+		t.Error("Expected delete to be rejected")
+	}
+	if all, _ := store.AuditLog(AuditFilter{}); len(all) != 3 {
+		t.Errorf("Expected audit log to be unchanged, got %d entries", len(all))
+	}
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+func (s *SQLiteStore) Deny(entry *DenyEntry) error {
+	entry.Domain = strings.Trim(strings.ToLower(strings.TrimSpace(entry.Domain)), ".")
+	if entry.Domain == "" {
+		return errors.New("denylist domain is required")
+	}
+
+	// Updating the reason keeps the original listing time
+	var createdAt int64
+	err := s.db.QueryRow(
+		`INSERT INTO denylist (domain, reason, created_at) VALUES (?, ?, ?)
+		 ON CONFLICT (domain) DO UPDATE SET reason = excluded.reason
+		 RETURNING created_at`,
+		entry.Domain, entry.Reason, time.Now().UTC().UnixNano(),
+	).Scan(&createdAt)
+	if err != nil {
+		return fmt.Errorf("failed to save denylist entry: %w", err)
+	}
+	entry.CreatedAt = time.Unix(0, createdAt).UTC()
+	return nil
+}
+
+func (s *SQLiteStore) Denylist() ([]DenyEntry, error) {
+	rows, err := s.db.Query(`SELECT domain, reason, created_at FROM denylist ORDER BY domain`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list denylist: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []DenyEntry
+	for rows.Next() {
+		var (
+			entry     DenyEntry
+			createdAt int64
+		)
+		if err := rows.Scan(&entry.Domain, &entry.Reason, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to read denylist entry: %w", err)
+		}
+		entry.CreatedAt = time.Unix(0, createdAt).UTC()
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// DeniedHost looks up host and each of its parent domains, preferring the
+// most specific entry
+func (s *SQLiteStore) DeniedHost(host string) (*DenyEntry, error) {
+	host = strings.Trim(strings.ToLower(host), ".")
+	for domain := host; domain != ""; {
+		var (
+			entry     = DenyEntry{Domain: domain}
+			createdAt int64
+		)
+		err := s.db.QueryRow(
+			`SELECT reason, created_at FROM denylist WHERE domain = ?`, domain,
+		).Scan(&entry.Reason, &createdAt)
+		if err == nil {
+			entry.CreatedAt = time.Unix(0, createdAt).UTC()
+			return &entry, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to check denylist: %w", err)
+		}
+
+		_, parent, ok := strings.Cut(domain, ".")
+		if !ok {
+			break
+		}
+		domain = parent
+	}
+	return nil, ErrNotFound
+}
+
+func (s *SQLiteStore) RemoveDenied(domain string) error {
+	domain = strings.Trim(strings.ToLower(strings.TrimSpace(domain)), ".")
+	res, err := s.db.Exec(`DELETE FROM denylist WHERE domain = ?`, domain)
+	if err != nil {
+		return fmt.Errorf("failed to remove denylist entry: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLiteStoreDenylist(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	entry := &DenyEntry{Domain: " Example.com. ", Reason: "Legal request"}
+	if err := store.Deny(entry); err != nil {
+		t.Fatalf("Deny failed: %v", err)
+	}
+	if entry.Domain != "example.com" || entry.CreatedAt.IsZero() {
+		t.Errorf("Expected a normalized, timestamped entry, got %+v", entry)
+	}
+	if err := store.Deny(&DenyEntry{Domain: "shop.example.com", Reason: "Abuse report"}); err != nil {
+		t.Fatalf("Deny failed: %v", err)
+	}
+
+	// Updating the reason keeps the listing time
+	update := &DenyEntry{Domain: "example.com", Reason: "Court order"}
+	if err := store.Deny(update); err != nil {
+		t.Fatalf("Deny failed: %v", err)
+	}
+	if !update.CreatedAt.Equal(entry.CreatedAt) {
+		t.Errorf("Expected created_at %v to be kept, got %v", entry.CreatedAt, update.CreatedAt)
+	}
+
+	entries, err := store.Denylist()
+	if err != nil || len(entries) != 2 || entries[0].Reason != "Court order" {
+		t.Fatalf("Denylist = %+v, %v", entries, err)
+	}
+
+	tests := []struct {
+		host   string
+		domain string
+	}{
+		{"example.com", "example.com"},
+		{"WWW.example.com", "example.com"},
+		{"cart.shop.example.com", "shop.example.com"},
+		{"notexample.com", ""},
+		{"example.org", ""},
+	}
+	for _, tt := range tests {
+		denied, err := store.DeniedHost(tt.host)
+		if tt.domain == "" {
+			if !errors.Is(err, ErrNotFound) {
+				t.Errorf("DeniedHost(%q) = %+v, %v; want ErrNotFound", tt.host, denied, err)
+			}
+			continue
+		}
+		if err != nil || denied.Domain != tt.domain {
+			t.Errorf("DeniedHost(%q) = %+v, %v; want %s", tt.host, denied, err, tt.domain)
+		}
+	}
+
+	if err := store.RemoveDenied("example.com"); err != nil {
+		t.Fatalf("RemoveDenied failed: %v", err)
+	}
+	if _, err := store.DeniedHost("www.example.com"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected removed domain to be allowed, got %v", err)
+	}
+	if err := store.RemoveDenied("example.com"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound removing twice, got %v", err)
+	}
+	if err := store.Deny(&DenyEntry{Domain: "  "}); err == nil {
+		t.Error("Expected an error for an empty domain")
+	}
+}
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// erasedTarget replaces the target of audit entries about erased data
+const erasedTarget = "[erased]"
+
+func (s *SQLiteStore) Erase(erasure Erasure, dryRun bool) (ErasureCounts, error) {
+	var counts ErasureCounts
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return counts, fmt.Errorf("failed to start erasure: %w", err)
+	}
+	defer tx.Rollback()
+
+	analyses, err := matchingRows(tx, `SELECT id, url, '' FROM analyses`, erasure)
+	if err != nil {
+		return counts, fmt.Errorf("failed to find analyses: %w", err)
+	}
+	baselines, err := matchingRows(tx, `SELECT url, url, '' FROM baselines`, erasure)
+	if err != nil {
+		return counts, fmt.Errorf("failed to find baselines: %w", err)
+	}
+	acks, err := matchingRows(tx, `SELECT id, target, host FROM acknowledgements`, erasure)
+	if err != nil {
+		return counts, fmt.Errorf("failed to find acknowledgements: %w", err)
+	}
+	monitors, err := matchingRows(tx, `SELECT id, url, '' FROM monitors`, erasure)
+	if err != nil {
+		return counts, fmt.Errorf("failed to find monitors: %w", err)
+	}
+	windows, err := matchingRows(tx, `SELECT id, url, '' FROM maintenance_windows`, erasure)
+	if err != nil {
+		return counts, fmt.Errorf("failed to find maintenance windows: %w", err)
+	}
+	audits, err := matchingRows(tx, `SELECT id, target, '' FROM audit_log`, erasure)
+	if err != nil {
+		return counts, fmt.Errorf("failed to find audit entries: %w", err)
+	}
+
+	counts = ErasureCounts{
+		Analyses:           int64(len(analyses)),
+		Baselines:          int64(len(baselines)),
+		Acknowledgements:   int64(len(acks)),
+		Monitors:           int64(len(monitors)),
+		MaintenanceWindows: int64(len(windows)),
+		AuditEntries:       int64(len(audits)),
+	}
+	if dryRun || counts == (ErasureCounts{}) {
+		return counts, nil
+	}
+
+	deletes := []struct {
+		query string
+		keys  []string
+	}{
+		{`DELETE FROM baselines WHERE url = ?`, baselines},
+		{`DELETE FROM snapshots WHERE analysis = ?`, analyses},
+		{`DELETE FROM analyses WHERE id = ?`, analyses},
+		{`DELETE FROM acknowledgements WHERE id = ?`, acks},
+		{`DELETE FROM monitors WHERE id = ?`, monitors},
+		{`DELETE FROM maintenance_windows WHERE id = ?`, windows},
+	}
+	for _, d := range deletes {
+		for _, key := range d.keys {
+			if _, err := tx.Exec(d.query, key); err != nil {
+				return counts, fmt.Errorf("failed to erase data: %w", err)
+			}
+		}
+	}
+
+	// Audit entries stay so the log still shows who did what and when,
+	// but no longer name the erased page
+	if len(audits) > 0 {
+		if _, err := tx.Exec(`DROP TRIGGER audit_log_no_update`); err != nil {
+			return counts, fmt.Errorf("failed to scrub audit log: %w", err)
+		}
+		for _, id := range audits {
+			if _, err := tx.Exec(`UPDATE audit_log SET target = ?, detail = '' WHERE id = ?`, erasedTarget, id); err != nil {
+				return counts, fmt.Errorf("failed to scrub audit log: %w", err)
+			}
+		}
+		if _, err := tx.Exec(auditNoUpdateTrigger); err != nil {
+			return counts, fmt.Errorf("failed to scrub audit log: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return counts, fmt.Errorf("failed to erase data: %w", err)
+	}
+
+	// Deleted rows linger in free pages until the file is rebuilt
+	if _, err := s.db.Exec(`VACUUM`); err != nil {
+		return counts, fmt.Errorf("failed to compact database: %w", err)
+	}
+	return counts, nil
+}
+
+// matchingRows returns the keys of the rows selected by query, which yields
+// key, URL and host columns, whose URL or host falls within erasure
+func matchingRows(tx *sql.Tx, query string, erasure Erasure) ([]string, error) {
+	rows, err := tx.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key, rawURL, host string
+		if err := rows.Scan(&key, &rawURL, &host); err != nil {
+			return nil, err
+		}
+		if erasure.Matches(rawURL) || erasure.matchesHost(host) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, rows.Err()
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"website-analyzer/internal/models"
+)
+
+func TestSQLiteStoreErase(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	var ids []string
+	for _, u := range []string{"https://example.com/", "https://blog.example.com/post", "https://other.com/"} {
+		record, err := store.Save(u, &models.AnalysisResult{URL: u, Title: "Page"}, Labels{})
+		if err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		ids = append(ids, record.ID)
+		if err := store.RecordAudit(&AuditEntry{Actor: "web:10.0.0.1", Action: AuditAnalysisRun, Target: u, Detail: "id=" + record.ID}); err != nil {
+			t.Fatalf("RecordAudit failed: %v", err)
+		}
+	}
+	if err := store.SetBaseline(ids[0]); err != nil {
+		t.Fatalf("SetBaseline failed: %v", err)
+	}
+	if err := store.Acknowledge(&models.Acknowledgement{Host: "example.com", Kind: models.FindingLink, Target: "https://cdn.net/a.js"}); err != nil {
+		t.Fatalf("Acknowledge failed: %v", err)
+	}
+
+	if err := store.CreateMonitor(&Monitor{URL: "https://blog.example.com/post", Schedule: "@daily"}); err != nil {
+		t.Fatalf("CreateMonitor failed: %v", err)
+	}
+
+	erasure := Erasure{Domain: "example.com"}
+	want := ErasureCounts{Analyses: 2, Baselines: 1, Acknowledgements: 1, Monitors: 1, AuditEntries: 2}
+
+	preview, err := store.Erase(erasure, true)
+	if err != nil || preview != want {
+		t.Fatalf("Expected dry run to count %+v, got %+v (%v)", want, preview, err)
+	}
+	if _, err := store.Get(ids[0]); err != nil {
+		t.Fatalf("Expected dry run to keep data, got %v", err)
+	}
+
+	counts, err := store.Erase(erasure, false)
+	if err != nil || counts != want {
+		t.Fatalf("Expected erase to remove %+v, got %+v (%v)", want, counts, err)
+	}
+
+	for _, id := range ids[:2] {
+		if _, err := store.Get(id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected analysis %s to be erased, got %v", id, err)
+		}
+	}
+	if _, err := store.Get(ids[2]); err != nil {
+		t.Errorf("Expected other sites to be kept, got %v", err)
+	}
+	if _, err := store.Baseline("https://example.com/"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected baseline to be erased, got %v", err)
+	}
+	if acks, _ := store.Acknowledgements("example.com"); len(acks) != 0 {
+		t.Errorf("Expected acknowledgements to be erased, got %+v", acks)
+	}
+
+	entries, _ := store.AuditLog(AuditFilter{})
+	if len(entries) != 3 {
+		t.Fatalf("Expected audit entries to be kept, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		scrubbed := entry.Target == erasedTarget && entry.Detail == ""
+		if scrubbed != (entry.Target != "https://other.com/") {
+			t.Errorf("Unexpected audit entry after erasure: %+v", entry)
+		}
+	}
+
+	// The audit log is append-only again afterwards
+	if _, err := store.db.Exec(`UPDATE audit_log SET actor = 'someone-else'`); err == nil {
+		t.Error("Expected update to be rejected")
+	}
+}
+
+func TestErasureMatches(t *testing.T) {
+	tests := []struct {
+		erasure Erasure
+		url     string
+		want    bool
+	}{
+		{Erasure{URL: "https://example.com/page"}, "https://example.com/page/", true},
+		{Erasure{URL: "https://example.com/page"}, "https://example.com/other", false},
+		{Erasure{Domain: "example.com"}, "https://EXAMPLE.com:8443/x", true},
+		{Erasure{Domain: "example.com"}, "https://www.example.com/", true},
+		{Erasure{Domain: "example.com"}, "https://notexample.com/", false},
+		{Erasure{Domain: "example.com"}, "docs", false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.erasure.Matches(tt.url); got != tt.want {
+			t.Errorf("%+v.Matches(%q) = %v, want %v", tt.erasure, tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+func (s *SQLiteStore) CreateMaintenanceWindow(window *MaintenanceWindow) error {
+	if window.Project != "" {
+		if _, err := s.Project(window.Project); errors.Is(err, ErrNotFound) {
+			return ErrUnknownProject
+		} else if err != nil {
+			return err
+		}
+	}
+
+	id, err := newID()
+	if err != nil {
+		return fmt.Errorf("failed to generate ID: %w", err)
+	}
+	window.ID = id
+	window.CreatedAt = time.Now().UTC()
+
+	_, err = s.db.Exec(
+		`INSERT INTO maintenance_windows (id, url, project, schedule, duration, description, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		window.ID, window.URL, window.Project, window.Schedule, int64(window.Duration), window.Description, window.CreatedAt.UnixNano(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save maintenance window: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) MaintenanceWindows() ([]MaintenanceWindow, error) {
+	rows, err := s.db.Query(
+		`SELECT id, url, project, schedule, duration, description, created_at FROM maintenance_windows ORDER BY project, url, created_at`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maintenance windows: %w", err)
+	}
+	defer rows.Close()
+
+	var windows []MaintenanceWindow
+	for rows.Next() {
+		var (
+			window              MaintenanceWindow
+			duration, createdAt int64
+		)
+		err := rows.Scan(&window.ID, &window.URL, &window.Project, &window.Schedule, &duration, &window.Description, &createdAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read maintenance window: %w", err)
+		}
+		window.Duration = time.Duration(duration)
+		window.CreatedAt = time.Unix(0, createdAt).UTC()
+		windows = append(windows, window)
+	}
+
+	return windows, rows.Err()
+}
+
+func (s *SQLiteStore) DeleteMaintenanceWindow(id string) error {
+	res, err := s.db.Exec(`DELETE FROM maintenance_windows WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete maintenance window: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLiteStoreMaintenanceWindows(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.CreateMaintenanceWindow(&MaintenanceWindow{Project: "missing", Schedule: "@daily", Duration: time.Hour}); !errors.Is(err, ErrUnknownProject) {
+		t.Errorf("Expected ErrUnknownProject, got %v", err)
+	}
+	if err := store.SaveProject(&Project{Name: "docs"}); err != nil {
+		t.Fatal(err)
+	}
+
+	window := &MaintenanceWindow{Project: "docs", Schedule: "0 22 * * 2", Duration: 90 * time.Minute, Description: "Tuesday deploys"}
+	if err := store.CreateMaintenanceWindow(window); err != nil {
+		t.Fatalf("CreateMaintenanceWindow failed: %v", err)
+	}
+	if window.ID == "" || window.CreatedAt.IsZero() {
+		t.Errorf("Expected an ID and timestamp, got %+v", window)
+	}
+
+	windows, err := store.MaintenanceWindows()
+	if err != nil || len(windows) != 1 {
+		t.Fatalf("Expected one maintenance window, got %+v (%v)", windows, err)
+	}
+	if got := windows[0]; got.Project != "docs" || got.Schedule != window.Schedule || got.Duration != window.Duration || got.Description != window.Description {
+		t.Errorf("Expected the window to round-trip, got %+v", got)
+	}
+
+	if err := store.DeleteMaintenanceWindow(window.ID); err != nil {
+		t.Fatalf("DeleteMaintenanceWindow failed: %v", err)
+	}
+	if err := store.DeleteMaintenanceWindow(window.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting twice, got %v", err)
+	}
+
+	// Erasing a site removes the windows of its pages, not of projects
+	page := &MaintenanceWindow{URL: "https://example.com/shop", Schedule: "@daily", Duration: time.Hour}
+	if err := store.CreateMaintenanceWindow(page); err != nil {
+		t.Fatalf("CreateMaintenanceWindow failed: %v", err)
+	}
+	if err := store.CreateMaintenanceWindow(window); err != nil {
+		t.Fatalf("CreateMaintenanceWindow failed: %v", err)
+	}
+	counts, err := store.Erase(Erasure{Domain: "example.com"}, false)
+	if err != nil || counts.MaintenanceWindows != 1 {
+		t.Fatalf("Expected one maintenance window erased, got %+v (%v)", counts, err)
+	}
+	if windows, _ := store.MaintenanceWindows(); len(windows) != 1 || windows[0].Project != "docs" {
+		t.Errorf("Expected only the project's window to be kept, got %+v", windows)
+	}
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// metricExpressions computes each of MetricNames from an analyses row;
+// NULL means the analysis didn't record the metric
+var metricExpressions = map[string]string{
+	"broken_links":        `COALESCE(json_array_length(result, '$.inaccessible_links'), 0)`,
+	"score_overall":       `json_extract(result, '$.scores.overall')`,
+	"score_seo":           `json_extract(result, '$.scores.seo')`,
+	"score_accessibility": `json_extract(result, '$.scores.accessibility')`,
+	"score_links":         `json_extract(result, '$.scores.links')`,
+	"duration_ms":         `NULLIF(wall_time_ms, 0)`,
+	"requests":            `NULLIF(requests, 0)`,
+	"bytes_downloaded":    `NULLIF(bytes_downloaded, 0)`,
+	"html_size":           `json_extract(result, '$.html_size')`,
+	"word_count":          `json_extract(result, '$.word_count')`,
+	"response_time_ms":    `NULLIF(json_extract(result, '$.response_time_ms'), 0)`,
+	"link_count":          `json_array_length(result, '$.links')`,
+}
+
+func (s *SQLiteStore) MetricSeries(filter MetricFilter) ([]MetricPoint, error) {
+	expr, ok := metricExpressions[filter.Metric]
+	if !ok {
+		return nil, fmt.Errorf("unknown metric %q", filter.Metric)
+	}
+
+	var from int64
+	if !filter.From.IsZero() {
+		from = filter.From.UnixNano()
+	}
+	to := int64(math.MaxInt64)
+	if !filter.To.IsZero() {
+		to = filter.To.UnixNano()
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+
+	// The newest points are kept when the limit cuts the series short
+	rows, err := s.db.Query(
+		`SELECT created_at, url, value FROM (
+		   SELECT created_at, url, `+expr+` AS value FROM analyses
+		   WHERE (? = '' OR url = ?) AND (? = '' OR project = ?) AND created_at >= ? AND created_at <= ?
+		     AND `+expr+` IS NOT NULL
+		   ORDER BY created_at DESC LIMIT ?
+		 ) ORDER BY created_at`,
+		filter.URL, filter.URL, filter.Project, filter.Project, from, to, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load metric: %w", err)
+	}
+	defer rows.Close()
+
+	var points []MetricPoint
+	for rows.Next() {
+		var (
+			point     MetricPoint
+			createdAt int64
+		)
+		if err := rows.Scan(&createdAt, &point.URL, &point.Value); err != nil {
+			return nil, fmt.Errorf("failed to read metric: %w", err)
+		}
+		point.Time = time.Unix(0, createdAt).UTC()
+		points = append(points, point)
+	}
+
+	return points, rows.Err()
+}
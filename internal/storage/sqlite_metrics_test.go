@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+func TestSQLiteStoreMetricSeries(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.SaveProject(&Project{Name: "shop"}); err != nil {
+		t.Fatalf("SaveProject failed: %v", err)
+	}
+	results := []struct {
+		url     string
+		project string
+		result  *models.AnalysisResult
+	}{
+		{"https://example.com/", "", &models.AnalysisResult{
+			InaccessibleLinks: []models.LinkError{{URL: "https://example.com/a"}, {URL: "https://example.com/b"}},
+			Scores:            &models.Scores{Overall: 70},
+			Usage:             &models.ResourceUsage{WallTimeMs: 1200},
+			ResponseTimeMs:    340,
+			Links:             []string{"https://example.com/a", "https://example.com/b", "https://example.com/c"},
+		}},
+		// Stored before scoring existed
+		{"https://example.com/", "", &models.AnalysisResult{}},
+		{"https://shop.example.com/", "shop", &models.AnalysisResult{Scores: &models.Scores{Overall: 90}}},
+	}
+	start := time.Now()
+	for _, r := range results {
+		r.result.URL = r.url
+		if _, err := store.Save(r.url, r.result, Labels{Project: r.project}); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	broken, err := store.MetricSeries(MetricFilter{Metric: "broken_links", URL: "https://example.com/"})
+	if err != nil {
+		t.Fatalf("MetricSeries failed: %v", err)
+	}
+	if len(broken) != 2 || broken[0].Value != 2 || broken[1].Value != 0 || !broken[0].Time.Before(broken[1].Time) {
+		t.Errorf("Expected broken links oldest first, got %+v", broken)
+	}
+
+	scores, err := store.MetricSeries(MetricFilter{Metric: "score_overall"})
+	if err != nil || len(scores) != 2 || scores[0].Value != 70 || scores[1].URL != "https://shop.example.com/" {
+		t.Errorf("Expected analyses without scores to be skipped, got %+v, %v", scores, err)
+	}
+
+	shop, err := store.MetricSeries(MetricFilter{Metric: "score_overall", Project: "shop"})
+	if err != nil || len(shop) != 1 || shop[0].Value != 90 {
+		t.Errorf("Expected the project's score only, got %+v, %v", shop, err)
+	}
+
+	latest, err := store.MetricSeries(MetricFilter{Metric: "score_overall", Limit: 1})
+	if err != nil || len(latest) != 1 || latest[0].Value != 90 {
+		t.Errorf("Expected the limit to keep the newest point, got %+v, %v", latest, err)
+	}
+
+	duration, err := store.MetricSeries(MetricFilter{Metric: "duration_ms", From: start})
+	if err != nil || len(duration) != 1 || duration[0].Value != 1200 {
+		t.Errorf("Expected the recorded duration only, got %+v, %v", duration, err)
+	}
+	responseTime, err := store.MetricSeries(MetricFilter{Metric: "response_time_ms", URL: "https://example.com/"})
+	if err != nil || len(responseTime) != 1 || responseTime[0].Value != 340 {
+		t.Errorf("Expected the recorded response time only, got %+v, %v", responseTime, err)
+	}
+	links, err := store.MetricSeries(MetricFilter{Metric: "link_count", URL: "https://example.com/"})
+	if err != nil || len(links) != 1 || links[0].Value != 3 {
+		t.Errorf("Expected the link count, got %+v, %v", links, err)
+	}
+
+	if none, err := store.MetricSeries(MetricFilter{Metric: "score_overall", To: start}); err != nil || len(none) != 0 {
+		t.Errorf("Expected nothing before the analyses, got %+v, %v", none, err)
+	}
+
+	if _, err := store.MetricSeries(MetricFilter{Metric: "bogus"}); err == nil {
+		t.Error("Expected an unknown metric to fail")
+	}
+	for _, name := range MetricNames {
+		if _, err := store.MetricSeries(MetricFilter{Metric: name}); err != nil {
+			t.Errorf("Metric %s failed: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const monitorColumns = `id, url, schedule, project, profile, next_run, last_run, last_analysis, last_error, regressions, created_at, webhook, paused, paused_until`
+
+func (s *SQLiteStore) CreateMonitor(monitor *Monitor) error {
+	if monitor.Project != "" {
+		if _, err := s.Project(monitor.Project); errors.Is(err, ErrNotFound) {
+			return ErrUnknownProject
+		} else if err != nil {
+			return err
+		}
+	}
+
+	id, err := newID()
+	if err != nil {
+		return fmt.Errorf("failed to generate ID: %w", err)
+	}
+	monitor.ID = id
+	monitor.CreatedAt = time.Now().UTC()
+
+	_, err = s.db.Exec(
+		`INSERT INTO monitors (`+monitorColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		monitor.ID, monitor.URL, monitor.Schedule, monitor.Project, monitor.Profile,
+		unixNano(monitor.NextRun), unixNano(monitor.LastRun), monitor.LastAnalysis, monitor.LastError,
+		monitor.Regressions, monitor.CreatedAt.UnixNano(), monitor.Webhook, monitor.Paused, unixNano(monitor.PausedUntil),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save monitor: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Monitor(id string) (*Monitor, error) {
+	monitor, err := scanMonitor(s.db.QueryRow(`SELECT `+monitorColumns+` FROM monitors WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load monitor: %w", err)
+	}
+	return monitor, nil
+}
+
+func (s *SQLiteStore) Monitors() ([]Monitor, error) {
+	return s.queryMonitors(`SELECT ` + monitorColumns + ` FROM monitors ORDER BY url, created_at`)
+}
+
+func (s *SQLiteStore) DueMonitors(now time.Time) ([]Monitor, error) {
+	return s.queryMonitors(
+		`SELECT `+monitorColumns+` FROM monitors
+		WHERE next_run <= ? AND (paused = 0 OR (paused_until != 0 AND paused_until <= ?))
+		ORDER BY next_run`,
+		now.UnixNano(), now.UnixNano(),
+	)
+}
+
+func (s *SQLiteStore) UpdateMonitorRun(monitor *Monitor) error {
+	res, err := s.db.Exec(
+		`UPDATE monitors SET next_run = ?, last_run = ?, last_analysis = ?, last_error = ?, regressions = ? WHERE id = ?`,
+		unixNano(monitor.NextRun), unixNano(monitor.LastRun), monitor.LastAnalysis, monitor.LastError,
+		monitor.Regressions, monitor.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update monitor: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStore) PauseMonitor(id string, until time.Time) error {
+	return s.setMonitorPaused(id, true, until)
+}
+
+func (s *SQLiteStore) ResumeMonitor(id string) error {
+	return s.setMonitorPaused(id, false, time.Time{})
+}
+
+func (s *SQLiteStore) setMonitorPaused(id string, paused bool, until time.Time) error {
+	res, err := s.db.Exec(`UPDATE monitors SET paused = ?, paused_until = ? WHERE id = ?`, paused, unixNano(until), id)
+	if err != nil {
+		return fmt.Errorf("failed to update monitor: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStore) DeleteMonitor(id string) error {
+	res, err := s.db.Exec(`DELETE FROM monitors WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete monitor: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStore) queryMonitors(query string, args ...any) ([]Monitor, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list monitors: %w", err)
+	}
+	defer rows.Close()
+
+	var monitors []Monitor
+	for rows.Next() {
+		monitor, err := scanMonitor(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read monitor: %w", err)
+		}
+		monitors = append(monitors, *monitor)
+	}
+
+	return monitors, rows.Err()
+}
+
+// scanMonitor reads a row of monitorColumns
+func scanMonitor(row interface{ Scan(...any) error }) (*Monitor, error) {
+	var (
+		monitor                     Monitor
+		nextRun, lastRun, createdAt int64
+		pausedUntil                 int64
+	)
+	err := row.Scan(&monitor.ID, &monitor.URL, &monitor.Schedule, &monitor.Project, &monitor.Profile,
+		&nextRun, &lastRun, &monitor.LastAnalysis, &monitor.LastError, &monitor.Regressions, &createdAt, &monitor.Webhook,
+		&monitor.Paused, &pausedUntil)
+	if err != nil {
+		return nil, err
+	}
+	monitor.NextRun = fromUnixNano(nextRun)
+	monitor.LastRun = fromUnixNano(lastRun)
+	monitor.PausedUntil = fromUnixNano(pausedUntil)
+	monitor.CreatedAt = time.Unix(0, createdAt).UTC()
+	return &monitor, nil
+}
+
+// unixNano stores a zero time as 0 so it reads back as zero
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromUnixNano(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n).UTC()
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLiteStoreMonitors(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Now().UTC().Truncate(time.Minute)
+	if err := store.CreateMonitor(&Monitor{URL: "https://example.com", Schedule: "@hourly", Project: "missing"}); !errors.Is(err, ErrUnknownProject) {
+		t.Errorf("Expected ErrUnknownProject, got %v", err)
+	}
+
+	hourly := &Monitor{URL: "https://example.com", Schedule: "@hourly", NextRun: now.Add(-time.Minute)}
+	daily := &Monitor{URL: "https://example.com/about", Schedule: "@daily", Webhook: "https://hooks.example.com/incident", NextRun: now.Add(time.Hour)}
+	for _, monitor := range []*Monitor{hourly, daily} {
+		if err := store.CreateMonitor(monitor); err != nil {
+			t.Fatalf("CreateMonitor failed: %v", err)
+		}
+	}
+	if hourly.ID == "" || hourly.CreatedAt.IsZero() {
+		t.Errorf("Expected an ID and timestamp, got %+v", hourly)
+	}
+
+	monitors, err := store.Monitors()
+	if err != nil || len(monitors) != 2 || monitors[0].ID != hourly.ID {
+		t.Fatalf("Expected both monitors ordered by URL, got %+v (%v)", monitors, err)
+	}
+	if monitors[1].Webhook != daily.Webhook {
+		t.Errorf("Expected the monitor webhook to be saved, got %q", monitors[1].Webhook)
+	}
+	if !monitors[0].LastRun.IsZero() || !monitors[0].NextRun.Equal(hourly.NextRun) {
+		t.Errorf("Expected an unrun monitor to keep its next run, got %+v", monitors[0])
+	}
+
+	due, err := store.DueMonitors(now)
+	if err != nil || len(due) != 1 || due[0].ID != hourly.ID {
+		t.Fatalf("Expected only the hourly monitor to be due, got %+v (%v)", due, err)
+	}
+
+	hourly.LastRun = now
+	hourly.NextRun = now.Add(time.Hour)
+	hourly.LastAnalysis = "abc"
+	hourly.LastError = ""
+	hourly.Regressions = 2
+	if err := store.UpdateMonitorRun(hourly); err != nil {
+		t.Fatalf("UpdateMonitorRun failed: %v", err)
+	}
+	got, err := store.Monitor(hourly.ID)
+	if err != nil {
+		t.Fatalf("Monitor failed: %v", err)
+	}
+	if !got.LastRun.Equal(now) || got.LastAnalysis != "abc" || got.Regressions != 2 {
+		t.Errorf("Expected the run to be saved, got %+v", got)
+	}
+	if due, _ := store.DueMonitors(now); len(due) != 0 {
+		t.Errorf("Expected no due monitors after the run, got %+v", due)
+	}
+
+	// Paused until resumed, then until a time
+	later := now.Add(2 * time.Hour)
+	if err := store.PauseMonitor(hourly.ID, time.Time{}); err != nil {
+		t.Fatalf("PauseMonitor failed: %v", err)
+	}
+	if due, _ := store.DueMonitors(later); len(due) != 1 || due[0].ID != daily.ID {
+		t.Errorf("Expected the paused monitor not to be due, got %+v", due)
+	}
+	if err := store.PauseMonitor(hourly.ID, later); err != nil {
+		t.Fatalf("PauseMonitor failed: %v", err)
+	}
+	got, _ = store.Monitor(hourly.ID)
+	if !got.Paused || !got.PausedUntil.Equal(later) || !got.PausedAt(now) || got.PausedAt(later) {
+		t.Errorf("Expected a pause until %v, got %+v", later, got)
+	}
+	if due, _ := store.DueMonitors(later.Add(-time.Minute)); len(due) != 1 {
+		t.Errorf("Expected the monitor to stay paused before its resume time, got %+v", due)
+	}
+	if due, _ := store.DueMonitors(later); len(due) != 2 {
+		t.Errorf("Expected the monitor to resume at its resume time, got %+v", due)
+	}
+	if err := store.ResumeMonitor(hourly.ID); err != nil {
+		t.Fatalf("ResumeMonitor failed: %v", err)
+	}
+	if got, _ := store.Monitor(hourly.ID); got.Paused || !got.PausedUntil.IsZero() {
+		t.Errorf("Expected the monitor to be resumed, got %+v", got)
+	}
+
+	if err := store.DeleteMonitor(hourly.ID); err != nil {
+		t.Fatalf("DeleteMonitor failed: %v", err)
+	}
+	if _, err := store.Monitor(hourly.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+	if err := store.DeleteMonitor(hourly.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting twice, got %v", err)
+	}
+	if err := store.UpdateMonitorRun(hourly); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound updating a deleted monitor, got %v", err)
+	}
+	if err := store.PauseMonitor(hourly.ID, time.Time{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound pausing a deleted monitor, got %v", err)
+	}
+}
@@ -0,0 +1,223 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// apiKeyPrefix marks secrets issued by this service
+const apiKeyPrefix = "wa_"
+
+const projectColumns = `name, description, notify_webhook, notify_format, notify_email, created_at, key_urls, report_schedule, report_next`
+
+func (s *SQLiteStore) SaveProject(project *Project) error {
+	if !ValidProjectName(project.Name) {
+		return fmt.Errorf("invalid project name %q", project.Name)
+	}
+
+	if existing, err := s.Project(project.Name); err == nil {
+		project.CreatedAt = existing.CreatedAt
+	} else if errors.Is(err, ErrNotFound) {
+		project.CreatedAt = time.Now().UTC()
+	} else {
+		return err
+	}
+
+	_, err := s.db.Exec(
+		`INSERT INTO projects (`+projectColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT (name) DO UPDATE SET description = excluded.description,
+		 notify_webhook = excluded.notify_webhook, notify_format = excluded.notify_format, notify_email = excluded.notify_email,
+		 key_urls = excluded.key_urls, report_schedule = excluded.report_schedule, report_next = excluded.report_next`,
+		project.Name, project.Description, project.NotifyWebhook, project.NotifyFormat, project.NotifyEmail, project.CreatedAt.UnixNano(),
+		strings.Join(project.KeyURLs, "\n"), project.ReportSchedule, unixNano(project.ReportNext),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save project: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Project(name string) (*Project, error) {
+	project, err := scanProject(s.db.QueryRow(`SELECT `+projectColumns+` FROM projects WHERE name = ?`, name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project: %w", err)
+	}
+	return project, nil
+}
+
+func (s *SQLiteStore) Projects() ([]Project, error) {
+	return s.queryProjects(`SELECT ` + projectColumns + ` FROM projects ORDER BY name`)
+}
+
+func (s *SQLiteStore) DueReports(now time.Time) ([]Project, error) {
+	return s.queryProjects(
+		`SELECT `+projectColumns+` FROM projects WHERE report_schedule != '' AND report_next <= ? ORDER BY report_next`,
+		now.UnixNano(),
+	)
+}
+
+func (s *SQLiteStore) SetReportNext(name string, next time.Time) error {
+	res, err := s.db.Exec(`UPDATE projects SET report_next = ? WHERE name = ?`, unixNano(next), name)
+	if err != nil {
+		return fmt.Errorf("failed to update project: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *SQLiteStore) queryProjects(query string, args ...any) ([]Project, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	defer rows.Close()
+
+	var projects []Project
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read project: %w", err)
+		}
+		projects = append(projects, *project)
+	}
+
+	return projects, rows.Err()
+}
+
+// scanProject reads a row of projectColumns
+func scanProject(row interface{ Scan(...any) error }) (*Project, error) {
+	var (
+		project               Project
+		createdAt, reportNext int64
+		keyURLs               string
+	)
+	err := row.Scan(&project.Name, &project.Description, &project.NotifyWebhook, &project.NotifyFormat, &project.NotifyEmail,
+		&createdAt, &keyURLs, &project.ReportSchedule, &reportNext)
+	if err != nil {
+		return nil, err
+	}
+	project.CreatedAt = time.Unix(0, createdAt).UTC()
+	if keyURLs != "" {
+		project.KeyURLs = strings.Split(keyURLs, "\n")
+	}
+	project.ReportNext = fromUnixNano(reportNext)
+	return &project, nil
+}
+
+func (s *SQLiteStore) CreateAPIKey(project string) (string, *APIKey, error) {
+	if _, err := s.Project(project); errors.Is(err, ErrNotFound) {
+		return "", nil, ErrUnknownProject
+	} else if err != nil {
+		return "", nil, err
+	}
+
+	id, err := newID()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate ID: %w", err)
+	}
+	random, err := newID()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	secret := apiKeyPrefix + random
+
+	key := &APIKey{
+		ID:        id,
+		Project:   project,
+		Prefix:    secret[:len(apiKeyPrefix)+6],
+		CreatedAt: time.Now().UTC(),
+	}
+	_, err = s.db.Exec(
+		`INSERT INTO api_keys (id, project, prefix, key_hash, created_at) VALUES (?, ?, ?, ?, ?)`,
+		key.ID, key.Project, key.Prefix, hashAPIKey(secret), key.CreatedAt.UnixNano(),
+	)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to save API key: %w", err)
+	}
+
+	return secret, key, nil
+}
+
+func (s *SQLiteStore) LookupAPIKey(secret string) (*APIKey, error) {
+	if !strings.HasPrefix(secret, apiKeyPrefix) {
+		return nil, ErrNotFound
+	}
+
+	var (
+		key       APIKey
+		createdAt int64
+	)
+	err := s.db.QueryRow(
+		`SELECT id, project, prefix, created_at FROM api_keys WHERE key_hash = ?`, hashAPIKey(secret),
+	).Scan(&key.ID, &key.Project, &key.Prefix, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load API key: %w", err)
+	}
+
+	key.CreatedAt = time.Unix(0, createdAt).UTC()
+	return &key, nil
+}
+
+func (s *SQLiteStore) APIKeys(project string) ([]APIKey, error) {
+	rows, err := s.db.Query(
+		`SELECT id, project, prefix, created_at FROM api_keys WHERE project = ? ORDER BY created_at`, project,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []APIKey
+	for rows.Next() {
+		var (
+			key       APIKey
+			createdAt int64
+		)
+		if err := rows.Scan(&key.ID, &key.Project, &key.Prefix, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to read API key: %w", err)
+		}
+		key.CreatedAt = time.Unix(0, createdAt).UTC()
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (s *SQLiteStore) RevokeAPIKey(id string) error {
+	res, err := s.db.Exec(`DELETE FROM api_keys WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// hashAPIKey returns the stored form of a key; keys carry 128 random bits
+// so a fast unsalted hash is sufficient
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// splitTags reverses the comma-joined tag column
+func splitTags(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
@@ -0,0 +1,230 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+func TestSQLiteStoreProjects(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.SaveProject(&Project{Name: "Not A Slug"}); err == nil {
+		t.Error("Expected invalid project name to be rejected")
+	}
+	if _, err := store.Save("https://example.com/", &models.AnalysisResult{}, Labels{Project: "docs"}); !errors.Is(err, ErrUnknownProject) {
+		t.Errorf("Expected ErrUnknownProject, got %v", err)
+	}
+
+	docs := &Project{Name: "docs", Description: "Documentation"}
+	if err := store.SaveProject(docs); err != nil {
+		t.Fatalf("SaveProject failed: %v", err)
+	}
+	if err := store.SaveProject(&Project{Name: "marketing-site"}); err != nil {
+		t.Fatalf("SaveProject failed: %v", err)
+	}
+
+	// Updating keeps the creation time
+	update := &Project{Name: "docs", Description: "Docs site", NotifyWebhook: "https://hooks.example.com/docs", NotifyFormat: "flat"}
+	if err := store.SaveProject(update); err != nil {
+		t.Fatalf("SaveProject failed: %v", err)
+	}
+	project, err := store.Project("docs")
+	if err != nil {
+		t.Fatalf("Project failed: %v", err)
+	}
+	if project.Description != "Docs site" || project.NotifyWebhook != "https://hooks.example.com/docs" || project.NotifyFormat != "flat" || !project.CreatedAt.Equal(docs.CreatedAt) {
+		t.Errorf("Unexpected project after update: %+v", project)
+	}
+
+	projects, err := store.Projects()
+	if err != nil || len(projects) != 2 || projects[0].Name != "docs" {
+		t.Errorf("Expected 2 projects sorted by name, got %+v (%v)", projects, err)
+	}
+
+	saved, err := store.Save("https://docs.example.com/", &models.AnalysisResult{Title: "Docs"}, Labels{Project: "docs", Tags: []string{"release", "nightly"}})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err := store.Save("https://example.com/", &models.AnalysisResult{Title: "Home"}, Labels{Project: "marketing-site", Tags: []string{"release"}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err := store.Save("https://example.com/x", &models.AnalysisResult{Title: "Untagged"}, Labels{}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	record, err := store.Get(saved.ID)
+	if err != nil || record.Project != "docs" || strings.Join(record.Tags, ",") != "release,nightly" {
+		t.Errorf("Expected labels to round-trip, got %+v (%v)", record, err)
+	}
+
+	tests := []struct {
+		filter Filter
+		titles string
+	}{
+		{Filter{Limit: 10}, "Untagged,Home,Docs"},
+		{Filter{Project: "docs", Limit: 10}, "Docs"},
+		{Filter{Tag: "release", Limit: 10}, "Home,Docs"},
+		{Filter{Project: "marketing-site", Tag: "nightly", Limit: 10}, ""},
+		// Tags match whole entries only
+		{Filter{Tag: "night", Limit: 10}, ""},
+	}
+	for _, tt := range tests {
+		summaries, err := store.List(tt.filter)
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		var titles []string
+		for _, summary := range summaries {
+			titles = append(titles, summary.Title)
+		}
+		if got := strings.Join(titles, ","); got != tt.titles {
+			t.Errorf("List(%+v) = %q, want %q", tt.filter, got, tt.titles)
+		}
+	}
+}
+
+func TestSQLiteStoreDueReports(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	keyURLs := []string{"https://example.com/", "https://example.com/pricing?a=1,2"}
+	for _, project := range []*Project{
+		{Name: "weekly", KeyURLs: keyURLs, ReportSchedule: "@weekly", ReportNext: now.Add(-time.Minute)},
+		{Name: "later", KeyURLs: keyURLs, ReportSchedule: "@daily", ReportNext: now.Add(time.Hour)},
+		{Name: "unscheduled", KeyURLs: keyURLs},
+	} {
+		if err := store.SaveProject(project); err != nil {
+			t.Fatalf("SaveProject failed: %v", err)
+		}
+	}
+
+	due, err := store.DueReports(now)
+	if err != nil || len(due) != 1 || due[0].Name != "weekly" {
+		t.Fatalf("Expected only the weekly report to be due, got %+v (%v)", due, err)
+	}
+	if !slices.Equal(due[0].KeyURLs, keyURLs) || !due[0].ReportNext.Equal(now.Add(-time.Minute)) {
+		t.Errorf("Expected key URLs and next report to round-trip, got %+v", due[0])
+	}
+
+	if err := store.SetReportNext("weekly", now.Add(7*24*time.Hour)); err != nil {
+		t.Fatalf("SetReportNext failed: %v", err)
+	}
+	if due, err := store.DueReports(now); err != nil || len(due) != 0 {
+		t.Errorf("Expected no reports due after rescheduling, got %+v (%v)", due, err)
+	}
+	if err := store.SetReportNext("missing", now); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown project, got %v", err)
+	}
+}
+
+func TestSQLiteStoreAPIKeys(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	if _, _, err := store.CreateAPIKey("docs"); !errors.Is(err, ErrUnknownProject) {
+		t.Errorf("Expected ErrUnknownProject, got %v", err)
+	}
+	if err := store.SaveProject(&Project{Name: "docs"}); err != nil {
+		t.Fatalf("SaveProject failed: %v", err)
+	}
+
+	secret, key, err := store.CreateAPIKey("docs")
+	if err != nil {
+		t.Fatalf("CreateAPIKey failed: %v", err)
+	}
+	if !strings.HasPrefix(secret, key.Prefix) || len(secret) <= len(key.Prefix) {
+		t.Errorf("Expected prefix %q to identify secret %q", key.Prefix, secret)
+	}
+
+	found, err := store.LookupAPIKey(secret)
+	if err != nil || found.ID != key.ID || found.Project != "docs" {
+		t.Errorf("Expected to find key, got %+v (%v)", found, err)
+	}
+	for _, wrong := range []string{"", "wa_nope", secret + "x"} {
+		if _, err := store.LookupAPIKey(wrong); !errors.Is(err, ErrNotFound) {
+			t.Errorf("LookupAPIKey(%q) = %v, want ErrNotFound", wrong, err)
+		}
+	}
+
+	keys, err := store.APIKeys("docs")
+	if err != nil || len(keys) != 1 || keys[0].Prefix != key.Prefix {
+		t.Errorf("Expected one listed key, got %+v (%v)", keys, err)
+	}
+
+	if err := store.RevokeAPIKey(key.ID); err != nil {
+		t.Fatalf("RevokeAPIKey failed: %v", err)
+	}
+	if _, err := store.LookupAPIKey(secret); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected revoked key to be rejected, got %v", err)
+	}
+}
+
+func TestSQLiteStoreMigratesOldSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.db")
+
+	// Database as created before projects and tags existed
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	_, err = db.Exec(`CREATE TABLE analyses (id TEXT PRIMARY KEY, url TEXT NOT NULL, title TEXT NOT NULL, created_at INTEGER NOT NULL, result TEXT NOT NULL);
+		INSERT INTO analyses VALUES ('old', 'https://example.com/', 'Old', 1, '{"title":"Old"}');`)
+	db.Close()
+	if err != nil {
+		t.Fatalf("Failed to create old schema: %v", err)
+	}
+
+	store, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	record, err := store.Get("old")
+	if err != nil || record.Project != "" || record.Tags != nil || record.Result.Title != "Old" {
+		t.Errorf("Expected old analysis to load, got %+v (%v)", record, err)
+	}
+}
+
+func TestParseQuota(t *testing.T) {
+	q, err := ParseQuota(" analyses=100, bytes=2048 ")
+	if err != nil || q != (Quota{Analyses: 100, Bytes: 2048}) {
+		t.Errorf("Unexpected quota %+v (%v)", q, err)
+	}
+	if q, err := ParseQuota(""); err != nil || !q.Unlimited() {
+		t.Errorf("Expected an empty spec to be unlimited, got %+v (%v)", q, err)
+	}
+	for _, spec := range []string{"analyses", "pages=-1", "requests=5"} {
+		if _, err := ParseQuota(spec); err == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	got := ParseTags(" Release, nightly,,release , ")
+	if strings.Join(got, "|") != "release|nightly" {
+		t.Errorf("Unexpected tags: %q", got)
+	}
+	if ParseTags("") != nil {
+		t.Error("Expected no tags for an empty value")
+	}
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+// saveSnapshot stores the HTML analysis id ran on, gzipped
+func saveSnapshot(tx *sql.Tx, id string, snapshot *models.Snapshot) error {
+	var body bytes.Buffer
+	zw := gzip.NewWriter(&body)
+	if _, err := io.WriteString(zw, snapshot.Body); err != nil {
+		return fmt.Errorf("failed to compress snapshot: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to compress snapshot: %w", err)
+	}
+	header, err := json.Marshal(snapshot.Header)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot headers: %w", err)
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO snapshots (analysis, url, body, size, header, captured_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		id, snapshot.URL, body.Bytes(), snapshot.Size, string(header), snapshot.CapturedAt.UnixNano(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Snapshot(id string) (*models.Snapshot, error) {
+	var (
+		snapshot   models.Snapshot
+		body       []byte
+		header     string
+		capturedAt int64
+	)
+	err := s.db.QueryRow(
+		`SELECT url, body, size, header, captured_at FROM snapshots WHERE analysis = ?`, id,
+	).Scan(&snapshot.URL, &body, &snapshot.Size, &header, &capturedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress snapshot: %w", err)
+	}
+	html, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress snapshot: %w", err)
+	}
+	snapshot.Body = string(html)
+	if err := json.Unmarshal([]byte(header), &snapshot.Header); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot headers: %w", err)
+	}
+	snapshot.CapturedAt = time.Unix(0, capturedAt).UTC()
+	return &snapshot, nil
+}
+
+func (s *SQLiteStore) SnapshotIDs() ([]string, error) {
+	rows, err := s.db.Query(
+		`SELECT s.analysis FROM snapshots s JOIN analyses a ON a.id = s.analysis ORDER BY a.created_at`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan snapshot: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (s *SQLiteStore) SaveRescore(id string, result *models.AnalysisResult) error {
+	var err error
+	if s.respectNoArchive && len(result.ArchiveDirectives) > 0 {
+		if result, err = withholdContent(result); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+
+	res, err := s.db.Exec(
+		`UPDATE analyses SET rescored = ?, rescored_at = ? WHERE id = ?`,
+		string(data), time.Now().UTC().UnixNano(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save rescored result: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
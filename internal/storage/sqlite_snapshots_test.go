@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+func TestSQLiteStoreSnapshots(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	snapshot := &models.Snapshot{
+		URL:        "https://example.com/",
+		Body:       "<html><title>Kept</title></html>",
+		Size:       31,
+		Header:     map[string][]string{"Content-Type": {"text/html"}},
+		CapturedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	saved, err := store.Save("https://example.com/", &models.AnalysisResult{Title: "Kept", Snapshot: snapshot}, Labels{})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	got, err := store.Snapshot(saved.ID)
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	if got.URL != snapshot.URL || got.Body != snapshot.Body || got.Size != 31 ||
+		got.Header["Content-Type"][0] != "text/html" || !got.CapturedAt.Equal(snapshot.CapturedAt) {
+		t.Errorf("Snapshot = %+v, want %+v", got, snapshot)
+	}
+
+	plain, err := store.Save("https://example.com/plain", &models.AnalysisResult{Title: "Plain"}, Labels{})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err := store.Snapshot(plain.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected no snapshot without one, got %v", err)
+	}
+
+	// Withheld pages keep no HTML either
+	store.SetRespectNoArchive(true)
+	withheld, err := store.Save("https://example.com/private", &models.AnalysisResult{
+		Title: "Private", ArchiveDirectives: []string{"noarchive"}, Snapshot: snapshot,
+	}, Labels{})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err := store.Snapshot(withheld.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected no snapshot for a noarchive page, got %v", err)
+	}
+
+	if _, err := store.Erase(Erasure{Domain: "example.com"}, false); err != nil {
+		t.Fatalf("Erase failed: %v", err)
+	}
+	if _, err := store.Snapshot(saved.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected erasure to remove the snapshot, got %v", err)
+	}
+}
+
+func TestSQLiteStoreSnapshotFailureRollsBack(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	if _, err := store.db.Exec(`DROP TABLE snapshots`); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := &models.Snapshot{URL: "https://example.com/", Body: "<html></html>", CapturedAt: time.Now()}
+	if _, err := store.Save("https://example.com/", &models.AnalysisResult{Title: "Lost", Snapshot: snapshot}, Labels{}); err == nil {
+		t.Fatal("Expected Save to fail without a snapshots table")
+	}
+
+	summaries, err := store.List(Filter{})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(summaries) != 0 {
+		t.Errorf("Expected the analysis to be rolled back with its snapshot, got %+v", summaries)
+	}
+}
+
+func TestSQLiteStoreRescore(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	snapshot := &models.Snapshot{URL: "https://example.com/", Body: "<html></html>", CapturedAt: time.Now()}
+	kept, err := store.Save("https://example.com/", &models.AnalysisResult{
+		Title: "Old", Scores: &models.Scores{Overall: 70}, Snapshot: snapshot,
+	}, Labels{})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err := store.Save("https://example.com/plain", &models.AnalysisResult{Title: "Plain"}, Labels{}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	ids, err := store.SnapshotIDs()
+	if err != nil {
+		t.Fatalf("SnapshotIDs failed: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != kept.ID {
+		t.Errorf("SnapshotIDs = %v, want [%s]", ids, kept.ID)
+	}
+
+	if err := store.SaveRescore(kept.ID, &models.AnalysisResult{Title: "Old", Scores: &models.Scores{Overall: 85}}); err != nil {
+		t.Fatalf("SaveRescore failed: %v", err)
+	}
+	record, err := store.Get(kept.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if record.Result.Scores.Overall != 70 {
+		t.Errorf("Expected the original score to be kept, got %d", record.Result.Scores.Overall)
+	}
+	if record.Rescored == nil || record.Rescored.Scores.Overall != 85 || record.RescoredAt.IsZero() {
+		t.Errorf("Expected the rescored result, got %+v at %v", record.Rescored, record.RescoredAt)
+	}
+
+	if err := store.SaveRescore("missing", &models.AnalysisResult{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown analysis, got %v", err)
+	}
+}
@@ -0,0 +1,277 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"website-analyzer/internal/models"
+)
+
+func TestSQLiteStore(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "history", "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	first, err := store.Save("https://example.com/a", &models.AnalysisResult{Title: "Page A", HTMLVersion: "HTML5"}, Labels{})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	second, err := store.Save("https://example.com/b", &models.AnalysisResult{Title: "Page B"}, Labels{})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if first.ID == second.ID {
+		t.Fatal("Expected unique IDs")
+	}
+
+	record, err := store.Get(first.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if record.URL != "https://example.com/a" || record.Result.Title != "Page A" || record.Result.HTMLVersion != "HTML5" {
+		t.Errorf("Unexpected record: %+v", record)
+	}
+
+	summaries, err := store.List(Filter{Limit: 10})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(summaries) != 2 || summaries[0].ID != second.ID || summaries[1].Title != "Page A" {
+		t.Errorf("Expected newest first, got %+v", summaries)
+	}
+
+	if _, err := store.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestSQLiteStoreAcknowledgements(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	ack := &models.Acknowledgement{Host: "example.com", Kind: models.FindingLink, Target: "https://partner.com/x", Note: "partner outage"}
+	if err := store.Acknowledge(ack); err != nil {
+		t.Fatalf("Acknowledge failed: %v", err)
+	}
+	if ack.ID == "" || ack.CreatedAt.IsZero() {
+		t.Errorf("Expected ID and timestamp to be assigned, got %+v", ack)
+	}
+
+	// Re-acknowledging the same finding replaces it
+	again := &models.Acknowledgement{Host: "example.com", Kind: models.FindingLink, Target: "https://partner.com/x", Note: "still down"}
+	if err := store.Acknowledge(again); err != nil {
+		t.Fatalf("Acknowledge failed: %v", err)
+	}
+	other := &models.Acknowledgement{Host: "other.com", Kind: models.FindingReadiness, Target: "meta-noindex"}
+	if err := store.Acknowledge(other); err != nil {
+		t.Fatalf("Acknowledge failed: %v", err)
+	}
+
+	acks, err := store.Acknowledgements("example.com")
+	if err != nil {
+		t.Fatalf("Acknowledgements failed: %v", err)
+	}
+	if len(acks) != 1 || acks[0].Note != "still down" || acks[0].ID != again.ID {
+		t.Errorf("Unexpected acknowledgements: %+v", acks)
+	}
+
+	if err := store.DeleteAcknowledgement(again.ID); err != nil {
+		t.Fatalf("DeleteAcknowledgement failed: %v", err)
+	}
+	if err := store.DeleteAcknowledgement(again.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if acks, _ := store.Acknowledgements("example.com"); len(acks) != 0 {
+		t.Errorf("Expected no acknowledgements, got %+v", acks)
+	}
+}
+
+func TestSQLiteStoreBaseline(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	const url = "https://example.com/"
+	if _, err := store.Baseline(url); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound without a baseline, got %v", err)
+	}
+	if err := store.SetBaseline("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for unknown analysis, got %v", err)
+	}
+
+	first, err := store.Save(url, &models.AnalysisResult{URL: url, Title: "First"}, Labels{})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	second, err := store.Save(url, &models.AnalysisResult{URL: url, Title: "Second"}, Labels{})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if err := store.SetBaseline(first.ID); err != nil {
+		t.Fatalf("SetBaseline failed: %v", err)
+	}
+	baseline, err := store.Baseline(url)
+	if err != nil || baseline.ID != first.ID {
+		t.Fatalf("Expected first analysis as baseline, got %v (%v)", baseline, err)
+	}
+
+	// A newer baseline replaces the previous one
+	if err := store.SetBaseline(second.ID); err != nil {
+		t.Fatalf("SetBaseline failed: %v", err)
+	}
+	baseline, err = store.Baseline(url)
+	if err != nil || baseline.Result.Title != "Second" {
+		t.Errorf("Expected second analysis as baseline, got %v (%v)", baseline, err)
+	}
+}
+
+func TestSQLiteStorePreviousAndLatest(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	const url = "https://example.com/"
+	first, err := store.Save(url, &models.AnalysisResult{URL: url, Title: "First"}, Labels{})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err := store.Save("https://other.com/", &models.AnalysisResult{Title: "Other"}, Labels{}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	second, err := store.Save(url, &models.AnalysisResult{URL: url, Title: "Second"}, Labels{})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	third, err := store.Save(url, &models.AnalysisResult{URL: url, Title: "Third"}, Labels{})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	previous, err := store.Previous(third.ID)
+	if err != nil || previous.ID != second.ID {
+		t.Errorf("Expected the second run before the third, got %v (%v)", previous, err)
+	}
+	previous, err = store.Previous(second.ID)
+	if err != nil || previous.ID != first.ID {
+		t.Errorf("Expected the first run before the second, got %v (%v)", previous, err)
+	}
+	if _, err := store.Previous(first.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for the first run, got %v", err)
+	}
+	if _, err := store.Previous("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown analysis, got %v", err)
+	}
+
+	latest, err := store.Latest(url)
+	if err != nil || latest.ID != third.ID {
+		t.Errorf("Expected the third run as the latest, got %v (%v)", latest, err)
+	}
+	if _, err := store.Latest("https://unknown.example/"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unanalyzed URL, got %v", err)
+	}
+}
+
+func TestSQLiteStoreRespectNoArchive(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	newResult := func(directives ...string) *models.AnalysisResult {
+		return &models.AnalysisResult{
+			Title:             "Private",
+			SEO:               &models.SEOReport{Description: "Secret summary"},
+			LinkDetails:       []models.LinkDetail{{Link: models.Link{URL: "https://example.com/x", Text: "Secret anchor"}}},
+			ArchiveDirectives: directives,
+			SimHash:           "0123456789abcdef",
+		}
+	}
+
+	// Disabled, content is kept even for noarchive pages
+	kept, err := store.Save("https://example.com/", newResult("noarchive"), Labels{})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if kept.Result.ContentWithheld || kept.Result.SEO.Description != "Secret summary" {
+		t.Errorf("Expected content to be kept, got %+v", kept.Result)
+	}
+
+	store.SetRespectNoArchive(true)
+	result := newResult("nosnippet")
+	saved, err := store.Save("https://example.com/", result, Labels{})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if result.ContentWithheld || result.SEO.Description == "" {
+		t.Error("Expected the caller's result to be left alone")
+	}
+	record, err := store.Get(saved.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	r := record.Result
+	if !r.ContentWithheld || r.SEO.Description != "" || r.LinkDetails[0].Text != "" || r.SimHash != "" {
+		t.Errorf("Expected page text to be withheld, got %+v", r)
+	}
+	if r.Title != "Private" || r.LinkDetails[0].URL != "https://example.com/x" {
+		t.Errorf("Expected the title and link URLs to be kept, got %+v", r)
+	}
+
+	// Pages without directives are stored whole
+	plain, err := store.Save("https://example.com/open", newResult(), Labels{})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if plain.Result.ContentWithheld {
+		t.Error("Expected a page without directives to be stored whole")
+	}
+}
+
+func TestSQLiteStoreHistory(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+	if err := store.SaveProject(&Project{Name: "site"}); err != nil {
+		t.Fatalf("SaveProject failed: %v", err)
+	}
+
+	const url = "https://example.com/"
+	first, err := store.Save(url, &models.AnalysisResult{Title: "First"}, Labels{Project: "site"})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err := store.Save("https://other.com/", &models.AnalysisResult{Title: "Other"}, Labels{Project: "site"}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	second, err := store.Save(url, &models.AnalysisResult{Title: "Second"}, Labels{})
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	records, err := store.History(HistoryFilter{URL: url})
+	if err != nil || len(records) != 2 || records[0].ID != first.ID || records[1].Result.Title != "Second" {
+		t.Errorf("Expected the URL's analyses oldest first, got %+v (%v)", records, err)
+	}
+	records, err = store.History(HistoryFilter{Project: "site"})
+	if err != nil || len(records) != 2 || records[1].URL != "https://other.com/" {
+		t.Errorf("Expected the project's analyses, got %+v (%v)", records, err)
+	}
+	records, err = store.History(HistoryFilter{URL: url, From: second.CreatedAt})
+	if err != nil || len(records) != 1 || records[0].ID != second.ID {
+		t.Errorf("Expected analyses from the given time only, got %+v (%v)", records, err)
+	}
+}
@@ -0,0 +1,33 @@
+package storage
+
+import "fmt"
+
+func (s *SQLiteStore) Usage(filter UsageFilter) ([]UsageTotal, error) {
+	var since int64
+	if !filter.Since.IsZero() {
+		since = filter.Since.UnixNano()
+	}
+
+	rows, err := s.db.Query(
+		`SELECT api_key, COUNT(*), SUM(wall_time_ms), SUM(pages), SUM(requests), SUM(bytes_downloaded), MAX(peak_goroutines)
+		 FROM analyses
+		 WHERE (? = '' OR api_key = ?) AND created_at >= ?
+		 GROUP BY api_key ORDER BY SUM(requests) DESC, api_key`,
+		filter.APIKey, filter.APIKey, since,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total usage: %w", err)
+	}
+	defer rows.Close()
+
+	var totals []UsageTotal
+	for rows.Next() {
+		var total UsageTotal
+		if err := rows.Scan(&total.APIKey, &total.Analyses, &total.WallTimeMs, &total.Pages, &total.Requests, &total.BytesDownloaded, &total.PeakGoroutines); err != nil {
+			return nil, fmt.Errorf("failed to read usage: %w", err)
+		}
+		totals = append(totals, total)
+	}
+
+	return totals, rows.Err()
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+func TestSQLiteStoreUsage(t *testing.T) {
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	runs := []struct {
+		key   string
+		usage *models.ResourceUsage
+	}{
+		{"key-a", &models.ResourceUsage{WallTimeMs: 100, Pages: 1, Requests: 10, BytesDownloaded: 1000, PeakGoroutines: 4}},
+		{"key-a", &models.ResourceUsage{WallTimeMs: 50, Pages: 1, Requests: 5, BytesDownloaded: 500, PeakGoroutines: 7}},
+		{"key-b", &models.ResourceUsage{WallTimeMs: 10, Requests: 1, BytesDownloaded: 10, PeakGoroutines: 1}},
+		// Web runs and results stored before accounting have no key or usage
+		{"", nil},
+	}
+	for _, run := range runs {
+		result := &models.AnalysisResult{URL: "https://example.com/", Usage: run.usage}
+		if _, err := store.Save(result.URL, result, Labels{APIKey: run.key}); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	totals, err := store.Usage(UsageFilter{})
+	if err != nil {
+		t.Fatalf("Usage failed: %v", err)
+	}
+	if len(totals) != 3 {
+		t.Fatalf("Expected totals for 3 keys, got %+v", totals)
+	}
+	want := UsageTotal{APIKey: "key-a", Analyses: 2, ResourceUsage: models.ResourceUsage{WallTimeMs: 150, Pages: 2, Requests: 15, BytesDownloaded: 1500, PeakGoroutines: 7}}
+	if totals[0] != want {
+		t.Errorf("Expected %+v first, got %+v", want, totals[0])
+	}
+
+	one, _ := store.Usage(UsageFilter{APIKey: "key-b"})
+	if len(one) != 1 || one[0].Requests != 1 {
+		t.Errorf("Expected only key-b, got %+v", one)
+	}
+
+	future, _ := store.Usage(UsageFilter{Since: time.Now().Add(time.Hour)})
+	if len(future) != 0 {
+		t.Errorf("Expected no usage after since, got %+v", future)
+	}
+}
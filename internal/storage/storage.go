@@ -0,0 +1,435 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"website-analyzer/internal/models"
+)
+
+// ErrNotFound is returned when no stored analysis matches the requested ID
+var ErrNotFound = errors.New("analysis not found")
+
+// ErrUnknownProject is returned when saving into a project that does not exist
+var ErrUnknownProject = errors.New("unknown project")
+
+// Record is a stored analysis result
+type Record struct {
+	ID        string                 `json:"id"`
+	URL       string                 `json:"url"`
+	Project   string                 `json:"project,omitempty"`
+	Tags      []string               `json:"tags,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+	Result    *models.AnalysisResult `json:"result"`
+	// Rescored is the latest re-scoring of the stored snapshot with
+	// updated checks; Result keeps the original findings for comparison
+	Rescored   *models.AnalysisResult `json:"rescored,omitempty"`
+	RescoredAt time.Time              `json:"rescored_at,omitzero"`
+}
+
+// Summary describes a stored analysis without its full result
+type Summary struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Title     string    `json:"title"`
+	Project   string    `json:"project,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Labels group a stored analysis into a project and free-form tags
+type Labels struct {
+	Project string
+	Tags    []string
+	// APIKey is the ID of the key that requested the analysis, if any
+	APIKey string
+}
+
+// Filter selects analyses for listing; empty fields match everything
+type Filter struct {
+	Project string
+	Tag     string
+	Limit   int
+}
+
+// HistoryFilter selects the analyses History returns; empty fields match
+// everything
+type HistoryFilter struct {
+	URL     string
+	Project string
+	From    time.Time
+}
+
+// Project groups analyses and carries the settings shared by them
+type Project struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Notification settings used by alerts for the project's analyses.
+	// NotifyFormat is the webhook payload format, see the webhook package.
+	NotifyWebhook string    `json:"notify_webhook,omitempty"`
+	NotifyFormat  string    `json:"notify_format,omitempty"`
+	NotifyEmail   string    `json:"notify_email,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	// KeyURLs are the pages rolled up in the project's portfolio report,
+	// delivered to NotifyWebhook on ReportSchedule when one is set
+	KeyURLs        []string  `json:"key_urls,omitempty"`
+	ReportSchedule string    `json:"report_schedule,omitempty"`
+	ReportNext     time.Time `json:"report_next,omitzero"`
+}
+
+// APIKey authenticates API clients and files their analyses under a
+// project. Only a hash of the key is stored; Prefix identifies it in lists.
+type APIKey struct {
+	ID        string    `json:"id"`
+	Project   string    `json:"project"`
+	Prefix    string    `json:"prefix"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Audit actions
+const (
+	AuditAnalysisRun    = "analysis.run"
+	AuditAnalysisReplay = "analysis.replay"
+	AuditRescore        = "analysis.rescore"
+	AuditCrawlRun       = "crawl.run"
+	AuditCompareRun     = "compare.run"
+	AuditBaselineSet    = "baseline.set"
+	AuditAcknowledge    = "finding.acknowledge"
+	AuditUnacknowledge  = "finding.unacknowledge"
+	AuditProjectSave    = "project.save"
+	AuditAPIKeyIssue    = "apikey.issue"
+	AuditAPIKeyRevoke   = "apikey.revoke"
+	AuditDataErase      = "data.erase"
+	AuditDenylistAdd    = "denylist.add"
+	AuditDenylistRemove = "denylist.remove"
+	AuditMonitorCreate  = "monitor.create"
+	AuditMonitorDelete  = "monitor.delete"
+	AuditMonitorPause   = "monitor.pause"
+	AuditMonitorResume  = "monitor.resume"
+	AuditWindowAdd      = "maintenance.add"
+	AuditWindowRemove   = "maintenance.remove"
+	AuditConfigExport   = "config.export"
+	AuditConfigImport   = "config.import"
+)
+
+// AuditEntry records who did what. Actor is "api-key:<prefix>" for API
+// clients, "web:<address>" for browser requests, "cli:<user>" for the
+// command line, "monitor:<id>" for scheduled runs and "admin" for
+// requests made with the admin token.
+type AuditEntry struct {
+	ID     int64     `json:"id"`
+	Time   time.Time `json:"time"`
+	Actor  string    `json:"actor"`
+	Action string    `json:"action"`
+	Target string    `json:"target"`
+	Detail string    `json:"detail,omitempty"`
+}
+
+// AuditFilter selects audit entries, newest first; empty fields match
+// everything and a zero Limit returns all entries
+type AuditFilter struct {
+	Action string
+	Actor  string
+	Limit  int
+}
+
+// UsageTotal sums the resource usage of the analyses run with one API key.
+// APIKey is empty for web and command line runs; PeakGoroutines is the
+// highest peak of any single analysis.
+type UsageTotal struct {
+	APIKey   string `json:"api_key"`
+	Analyses int64  `json:"analyses"`
+	models.ResourceUsage
+}
+
+// UsageFilter selects the analyses counted by Usage; a zero Since counts
+// all of them and an empty APIKey covers every key
+type UsageFilter struct {
+	APIKey string
+	Since  time.Time
+}
+
+// Quota limits what one API key may use in a period; zero fields are
+// unlimited
+type Quota struct {
+	Analyses int64 `json:"analyses,omitempty"`
+	Pages    int64 `json:"pages,omitempty"`
+	Bytes    int64 `json:"bytes,omitempty"`
+}
+
+// Unlimited reports whether the quota sets no limit at all
+func (q Quota) Unlimited() bool {
+	return q == Quota{}
+}
+
+// Erasure selects the stored data of one page or of a whole site for
+// permanent removal. Exactly one field is set; Domain covers subdomains.
+type Erasure struct {
+	URL    string `json:"url,omitempty"`
+	Domain string `json:"domain,omitempty"`
+}
+
+// Matches reports whether rawURL is the erased page or on the erased site
+func (e Erasure) Matches(rawURL string) bool {
+	if e.URL != "" {
+		return strings.TrimSuffix(rawURL, "/") == strings.TrimSuffix(e.URL, "/")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return e.matchesHost(u.Hostname())
+}
+
+func (e Erasure) matchesHost(host string) bool {
+	if e.Domain == "" {
+		return false
+	}
+	host = strings.ToLower(host)
+	return host == e.Domain || strings.HasSuffix(host, "."+e.Domain)
+}
+
+// Metrics that MetricSeries can chart over stored analyses
+var MetricNames = []string{
+	"broken_links", "score_overall", "score_seo", "score_accessibility", "score_links",
+	"duration_ms", "requests", "bytes_downloaded", "html_size", "word_count",
+	"response_time_ms", "link_count",
+}
+
+// MetricPoint is a metric's value in one stored analysis
+type MetricPoint struct {
+	Time  time.Time
+	URL   string
+	Value float64
+}
+
+// MetricFilter selects the analyses MetricSeries reads; empty fields match
+// everything and a zero To means now
+type MetricFilter struct {
+	Metric  string
+	URL     string
+	Project string
+	From    time.Time
+	To      time.Time
+	Limit   int
+}
+
+// DenyEntry puts a domain and its subdomains on the do-not-analyze list.
+// Reason is shown to users whose submissions it rejects.
+type DenyEntry struct {
+	Domain    string    `json:"domain"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Monitor re-analyzes URL whenever its cron-like Schedule is due. The Last
+// fields describe the most recent run; LastAnalysis is the ID of the last
+// stored result, which the next run is compared against. Webhook, if set,
+// is notified of every run in addition to the project's webhook. A paused
+// monitor doesn't run until it is resumed or, if set, until PausedUntil.
+type Monitor struct {
+	ID           string    `json:"id"`
+	URL          string    `json:"url"`
+	Schedule     string    `json:"schedule"`
+	Project      string    `json:"project,omitempty"`
+	Profile      string    `json:"profile,omitempty"`
+	Webhook      string    `json:"webhook,omitempty"`
+	NextRun      time.Time `json:"next_run"`
+	LastRun      time.Time `json:"last_run,omitzero"`
+	LastAnalysis string    `json:"last_analysis,omitempty"`
+	LastError    string    `json:"last_error,omitempty"`
+	Regressions  int       `json:"regressions"`
+	Paused       bool      `json:"paused,omitempty"`
+	PausedUntil  time.Time `json:"paused_until,omitzero"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// PausedAt reports whether the monitor is paused at t
+func (m Monitor) PausedAt(t time.Time) bool {
+	return m.Paused && (m.PausedUntil.IsZero() || t.Before(m.PausedUntil))
+}
+
+// MaintenanceWindow silences notifications about URL, or about every page
+// of Project, for Duration from each time its cron-like Schedule is due,
+// e.g. during a weekly deploy slot. Analyses still run and are stored.
+type MaintenanceWindow struct {
+	ID          string        `json:"id"`
+	URL         string        `json:"url,omitempty"`
+	Project     string        `json:"project,omitempty"`
+	Schedule    string        `json:"schedule"`
+	Duration    time.Duration `json:"duration"`
+	Description string        `json:"description,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// ErasureCounts reports how much stored data an erasure covers. Audit
+// entries are kept with their target and detail scrubbed.
+type ErasureCounts struct {
+	Analyses           int64 `json:"analyses"`
+	Baselines          int64 `json:"baselines"`
+	Acknowledgements   int64 `json:"acknowledgements"`
+	Monitors           int64 `json:"monitors"`
+	MaintenanceWindows int64 `json:"maintenance_windows"`
+	AuditEntries       int64 `json:"audit_entries"`
+}
+
+// Store persists analysis results
+type Store interface {
+	// Save stores result; a non-empty labels.Project must exist
+	Save(url string, result *models.AnalysisResult, labels Labels) (*Record, error)
+	Get(id string) (*Record, error)
+	List(filter Filter) ([]Summary, error)
+	// Previous returns the analysis of the same URL stored before id
+	Previous(id string) (*Record, error)
+	// Latest returns the most recent analysis of url
+	Latest(url string) (*Record, error)
+	// History returns the matching analyses with their results, oldest
+	// first
+	History(filter HistoryFilter) ([]Record, error)
+	// Snapshot returns the HTML the analysis id ran on, if it was kept
+	Snapshot(id string) (*models.Snapshot, error)
+	// SnapshotIDs lists the analyses with a snapshot, oldest first
+	SnapshotIDs() ([]string, error)
+	// SaveRescore records result as the re-scoring of analysis id,
+	// replacing any earlier one
+	SaveRescore(id string, result *models.AnalysisResult) error
+
+	// SaveProject creates or updates a project
+	SaveProject(project *Project) error
+	Project(name string) (*Project, error)
+	Projects() ([]Project, error)
+	// DueReports lists projects whose portfolio report is due at now
+	DueReports(now time.Time) ([]Project, error)
+	// SetReportNext schedules a project's next portfolio report
+	SetReportNext(name string, next time.Time) error
+
+	// CreateAPIKey issues a key for project, returning the secret once
+	CreateAPIKey(project string) (string, *APIKey, error)
+	// LookupAPIKey returns the key matching secret
+	LookupAPIKey(secret string) (*APIKey, error)
+	APIKeys(project string) ([]APIKey, error)
+	RevokeAPIKey(id string) error
+
+	// Usage totals resource usage per API key, most requests first
+	Usage(filter UsageFilter) ([]UsageTotal, error)
+
+	// RecordAudit appends entry to the audit log, assigning its ID and time
+	RecordAudit(entry *AuditEntry) error
+	AuditLog(filter AuditFilter) ([]AuditEntry, error)
+
+	// Acknowledge stores ack, assigning its ID and timestamp
+	Acknowledge(ack *models.Acknowledgement) error
+	Acknowledgements(host string) ([]models.Acknowledgement, error)
+	DeleteAcknowledgement(id string) error
+
+	// SetBaseline marks the stored analysis id as the baseline for its URL
+	SetBaseline(id string) error
+	// Baseline returns the baseline analysis for url
+	Baseline(url string) (*Record, error)
+
+	// Erase permanently removes the stored data matching erasure; with
+	// dryRun it only counts what would be removed
+	Erase(erasure Erasure, dryRun bool) (ErasureCounts, error)
+
+	// Deny adds entry to the do-not-analyze list or updates its reason,
+	// assigning its timestamp
+	Deny(entry *DenyEntry) error
+	Denylist() ([]DenyEntry, error)
+	// DeniedHost returns the entry covering host, if any
+	DeniedHost(host string) (*DenyEntry, error)
+	RemoveDenied(domain string) error
+
+	// CreateMonitor stores monitor, assigning its ID and timestamp; a
+	// non-empty Project must exist
+	CreateMonitor(monitor *Monitor) error
+	Monitor(id string) (*Monitor, error)
+	Monitors() ([]Monitor, error)
+	// DueMonitors returns the monitors whose next run is at or before now,
+	// leaving out those paused at now
+	DueMonitors(now time.Time) ([]Monitor, error)
+	// UpdateMonitorRun saves the run state of monitor
+	UpdateMonitorRun(monitor *Monitor) error
+	// PauseMonitor stops a monitor's runs until ResumeMonitor or, if not
+	// zero, until
+	PauseMonitor(id string, until time.Time) error
+	ResumeMonitor(id string) error
+	DeleteMonitor(id string) error
+
+	// CreateMaintenanceWindow stores window, assigning its ID and
+	// timestamp; a non-empty Project must exist
+	CreateMaintenanceWindow(window *MaintenanceWindow) error
+	MaintenanceWindows() ([]MaintenanceWindow, error)
+	DeleteMaintenanceWindow(id string) error
+
+	// MetricSeries returns the filter's metric for each matching analysis
+	// that has it, oldest first
+	MetricSeries(filter MetricFilter) ([]MetricPoint, error)
+
+	Close() error
+}
+
+// projectNamePattern restricts project names to URL-safe slugs
+var projectNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+// ValidProjectName reports whether name can be used as a project name
+func ValidProjectName(name string) bool {
+	return projectNamePattern.MatchString(name)
+}
+
+// ParseTags splits a comma-separated tag list, normalizing case and
+// dropping blanks and duplicates
+func ParseTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// ParseQuota reads a quota such as "analyses=100,pages=500,bytes=1048576";
+// omitted fields are unlimited
+func ParseQuota(spec string) (Quota, error) {
+	var q Quota
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(item, "=")
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil || n < 0 {
+			return Quota{}, fmt.Errorf("invalid quota limit %q", item)
+		}
+		switch strings.TrimSpace(name) {
+		case "analyses":
+			q.Analyses = n
+		case "pages":
+			q.Pages = n
+		case "bytes":
+			q.Bytes = n
+		default:
+			return Quota{}, fmt.Errorf("unknown quota %q", name)
+		}
+	}
+	return q, nil
+}
+
+// newID returns a random 16-byte hex identifier
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
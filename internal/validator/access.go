@@ -0,0 +1,147 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+)
+
+// ErrOutOfScope is returned for hosts outside the allowlist or on the
+// denylist of hosts this deployment may analyze
+var ErrOutOfScope = errors.New("host is outside the domains this service may analyze")
+
+// accessList matches hosts by exact name, "*." wildcard or address range
+type accessList struct {
+	hosts map[string]bool
+	// wildcards are the suffixes, with their leading dot, matching
+	// subdomains at any depth
+	wildcards []string
+	networks  []*net.IPNet
+}
+
+// accessLists are the allowlist, which is unrestricted when empty, and
+// the denylist, which wins over it
+type accessLists struct {
+	allowed, denied *accessList
+}
+
+var access atomic.Pointer[accessLists]
+
+func init() {
+	access.Store(&accessLists{allowed: &accessList{}, denied: &accessList{}})
+}
+
+// SetAccessLists restricts analyses, crawls and link checks to hosts
+// matching allowed, when it isn't empty, and never to hosts matching
+// denied. Patterns are exact hosts ("intranet.example.com" or an address
+// written in URLs), wildcards matching subdomains ("*.example.com") or
+// address ranges ("10.0.0.0/8"), which are also checked against the
+// address connected to.
+func SetAccessLists(allowed, denied []string) error {
+	allowList, err := parseAccessList(allowed)
+	if err != nil {
+		return fmt.Errorf("allowed hosts: %w", err)
+	}
+	denyList, err := parseAccessList(denied)
+	if err != nil {
+		return fmt.Errorf("denied hosts: %w", err)
+	}
+	access.Store(&accessLists{allowed: allowList, denied: denyList})
+	return nil
+}
+
+func parseAccessList(patterns []string) (*accessList, error) {
+	list := &accessList{hosts: make(map[string]bool)}
+	for _, pattern := range patterns {
+		pattern = normalizeHost(pattern)
+		switch {
+		case pattern == "":
+		case strings.Contains(pattern, "/"):
+			network, err := parseNetwork(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid range %q: %w", pattern, err)
+			}
+			list.networks = append(list.networks, network)
+		case strings.HasPrefix(pattern, "*."):
+			suffix := pattern[1:]
+			if strings.Contains(suffix, "*") {
+				return nil, fmt.Errorf("invalid wildcard %q: only a leading \"*.\" is supported", pattern)
+			}
+			list.wildcards = append(list.wildcards, suffix)
+		case strings.Contains(pattern, "*"):
+			return nil, fmt.Errorf("invalid wildcard %q: only a leading \"*.\" is supported", pattern)
+		case net.ParseIP(pattern) != nil:
+			list.hosts[net.ParseIP(pattern).String()] = true
+		default:
+			list.hosts[pattern] = true
+		}
+	}
+	return list, nil
+}
+
+func (l *accessList) empty() bool {
+	return len(l.hosts) == 0 && len(l.wildcards) == 0 && len(l.networks) == 0
+}
+
+// matchesName reports whether host matches by name; an address written
+// as the host also matches the ranges
+func (l *accessList) matchesName(host string) bool {
+	host = normalizeHost(host)
+	if ip := net.ParseIP(host); ip != nil {
+		return l.hosts[ip.String()] || l.matchesIP(ip)
+	}
+	if l.hosts[host] {
+		return true
+	}
+	for _, suffix := range l.wildcards {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *accessList) matchesIP(ip net.IP) bool {
+	for _, network := range l.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckAccess checks host against the access lists by name alone. A name
+// the allowlist doesn't match may still be allowed by the address it
+// resolves to, which connecting checks.
+func CheckAccess(host string) error {
+	_, err := checkAccessName(host)
+	return err
+}
+
+// checkAccessName checks host by name and reports whether its name
+// already allows it, or whether the address connected to must
+func checkAccessName(host string) (bool, error) {
+	lists := access.Load()
+	if lists.denied.matchesName(host) {
+		return false, fmt.Errorf("%w: %s", ErrOutOfScope, host)
+	}
+	if lists.allowed.empty() || lists.allowed.matchesName(host) {
+		return true, nil
+	}
+	if len(lists.allowed.networks) == 0 || net.ParseIP(host) != nil {
+		return false, fmt.Errorf("%w: %s", ErrOutOfScope, host)
+	}
+	return false, nil
+}
+
+// checkAccessAddress checks the address a host resolved to; nameAllowed
+// is whether checkAccessName already allowed the host
+func checkAccessAddress(ip net.IP, nameAllowed bool) error {
+	lists := access.Load()
+	if lists.denied.matchesIP(ip) || (!nameAllowed && !lists.allowed.matchesIP(ip)) {
+		return fmt.Errorf("%w: %s", ErrOutOfScope, ip)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package validator
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestAccessLists(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+	defer SetAccessLists(nil, nil)
+
+	if err := SetAccessLists(
+		[]string{"example.com", "*.corp.example", "10.0.0.0/8"},
+		[]string{"legacy.corp.example", "10.9.0.0/16", "*.example.com", "10.250.0.1"},
+	); err != nil {
+		t.Fatalf("SetAccessLists failed: %v", err)
+	}
+
+	tests := []struct {
+		host    string
+		allowed bool
+	}{
+		{"example.com", true},
+		{"EXAMPLE.COM.", true},
+		{"www.example.com", false},
+		{"intranet.corp.example", true},
+		{"a.b.corp.example", true},
+		{"legacy.corp.example", false},
+		{"10.1.2.3", true},
+		{"10.9.0.1", false},
+		{"10.250.0.1", false},
+		{"192.0.2.1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			err := ValidateTarget("http://"+tt.host+"/", 2048)
+			if (err == nil) != tt.allowed || (err != nil && !errors.Is(err, ErrOutOfScope)) {
+				t.Errorf("ValidateTarget(%q) = %v, want allowed %v", tt.host, err, tt.allowed)
+			}
+			// Only targets are restricted
+			if err := ValidateURL("http://"+tt.host+"/", 2048); err != nil {
+				t.Errorf("ValidateURL(%q) = %v, want the access lists ignored", tt.host, err)
+			}
+		})
+	}
+
+	// Names the allowlist doesn't match are left to the address connected
+	// to when it lists ranges
+	if err := CheckAccess("unknown.example.org"); err != nil {
+		t.Errorf("Expected the name to be left to its address, got %v", err)
+	}
+	if err := SetAccessLists([]string{"example.com"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckAccess("unknown.example.org"); !errors.Is(err, ErrOutOfScope) {
+		t.Errorf("Expected the name to be refused without ranges, got %v", err)
+	}
+}
+
+func TestSetAccessListsInvalid(t *testing.T) {
+	defer SetAccessLists(nil, nil)
+	for _, pattern := range []string{"10.0.0.0/33", "*example.com", "www.*.example.com"} {
+		if err := SetAccessLists([]string{pattern}, nil); err == nil {
+			t.Errorf("Expected %q to be rejected", pattern)
+		}
+	}
+}
+
+func TestTransportAccessLists(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+	defer SetAccessLists(nil, nil)
+
+	get := func(target string) error {
+		// A fresh transport, so no kept-alive connection skips the checks
+		client := &http.Client{Transport: NewTargetTransport()}
+		resp, err := client.Get(target)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	// localhost is only allowed by the address it resolves to
+	if err := SetAccessLists([]string{"127.0.0.0/8"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := get("http://localhost:" + ts.URL[len("http://127.0.0.1:"):]); err != nil {
+		t.Errorf("Expected the allowed range to be reachable, got %v", err)
+	}
+
+	if err := SetAccessLists([]string{"*.example.com"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := get(ts.URL); !errors.Is(err, ErrOutOfScope) || !Refused(err) {
+		t.Errorf("Expected a host outside the allowlist to be refused, got %v", err)
+	}
+	resp, err := (&http.Client{Transport: NewTransport()}).Get(ts.URL)
+	if err != nil {
+		t.Errorf("Expected the unscoped transport to ignore the access lists, got %v", err)
+	} else {
+		resp.Body.Close()
+	}
+
+	if err := SetAccessLists(nil, []string{"127.0.0.1/32"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := get("http://localhost:" + ts.URL[len("http://127.0.0.1:"):]); !errors.Is(err, ErrOutOfScope) {
+		t.Errorf("Expected the denied address to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+)
+
+// ErrBlocked is returned for requests to a blocked host or address. Unlike
+// the private-range checks, ALLOW_PRIVATE_IPS doesn't lift the blocklist.
+var ErrBlocked = errors.New("access to this host is blocked")
+
+// metadataHosts are the names of cloud instance metadata services
+var metadataHosts = []string{
+	"metadata.google.internal",
+	"metadata.goog",
+	"metadata.tencentyun.com",
+	"instance-data",
+}
+
+// metadataCIDRs are the addresses of cloud instance metadata services,
+// which answer on link-local or otherwise routable addresses
+var metadataCIDRs = []string{
+	"169.254.169.254/32", // AWS, GCP, Azure, DigitalOcean, Oracle
+	"169.254.170.2/32",   // AWS ECS task metadata
+	"fd00:ec2::254/128",  // AWS IPv6
+	"100.100.100.200/32", // Alibaba Cloud
+	"168.63.129.16/32",   // Azure WireServer
+	"192.0.0.192/32",     // Oracle Cloud Classic
+}
+
+// blocklist is the built-in metadata services plus configured entries
+type blocklist struct {
+	// hosts match themselves and their subdomains
+	hosts    []string
+	networks []*net.IPNet
+}
+
+var blocked atomic.Pointer[blocklist]
+
+func init() {
+	if err := SetBlocklist(nil, nil); err != nil {
+		panic(err)
+	}
+}
+
+// SetBlocklist blocks hosts, with their subdomains, and cidrs, which may
+// be bare addresses, on top of the built-in cloud metadata endpoints
+func SetBlocklist(hosts, cidrs []string) error {
+	list := &blocklist{}
+	for _, host := range append(metadataHosts, hosts...) {
+		if host = normalizeHost(host); host != "" {
+			list.hosts = append(list.hosts, host)
+		}
+	}
+	for _, cidr := range append(metadataCIDRs, cidrs...) {
+		network, err := parseNetwork(cidr)
+		if err != nil {
+			return fmt.Errorf("invalid blocked range %q: %w", cidr, err)
+		}
+		list.networks = append(list.networks, network)
+	}
+	blocked.Store(list)
+	return nil
+}
+
+// parseNetwork parses a CIDR, or a single address as a one-address range
+func parseNetwork(cidr string) (*net.IPNet, error) {
+	cidr = strings.TrimSpace(cidr)
+	if ip := net.ParseIP(cidr); ip != nil {
+		bits := 128
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 32
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+	_, network, err := net.ParseCIDR(cidr)
+	return network, err
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+// hostBlocked reports whether hostname or a parent domain is blocked
+func hostBlocked(hostname string) bool {
+	hostname = normalizeHost(hostname)
+	for _, host := range blocked.Load().hosts {
+		if hostname == host || strings.HasSuffix(hostname, "."+host) {
+			return true
+		}
+	}
+	return false
+}
+
+// ipBlocked reports whether ip is in a blocked range
+func ipBlocked(ip net.IP) bool {
+	for _, network := range blocked.Load().networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Refused reports whether err comes from a request the SSRF checks, the
+// blocklist or the access lists refused, which repeating won't change
+func Refused(err error) bool {
+	return errors.Is(err, ErrPrivateAddress) || errors.Is(err, ErrBlocked) || errors.Is(err, ErrOutOfScope)
+}
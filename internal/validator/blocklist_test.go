@@ -0,0 +1,88 @@
+package validator
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestBlocklist(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+	defer SetBlocklist(nil, nil)
+
+	if err := SetBlocklist([]string{"Internal.Example.com."}, []string{"203.0.113.0/24", "198.51.100.7"}); err != nil {
+		t.Fatalf("SetBlocklist failed: %v", err)
+	}
+
+	tests := []struct {
+		url     string
+		blocked bool
+	}{
+		{"http://169.254.169.254/latest/meta-data/", true},
+		{"http://[fd00:ec2::254]/", true},
+		{"http://metadata.google.internal/computeMetadata/v1/", true},
+		{"http://METADATA.GOOGLE.INTERNAL./", true},
+		{"http://api.internal.example.com/", true},
+		{"http://203.0.113.50/", true},
+		{"http://198.51.100.7/", true},
+		{"http://198.51.100.8/", false},
+		{"http://example.com/", false},
+		{"http://notinternal.example.com/", false},
+		{"http://127.0.0.1/", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := ValidateURL(tt.url, 2048)
+			if got := errors.Is(err, ErrBlocked); got != tt.blocked {
+				t.Errorf("ValidateURL(%q) = %v, want blocked %v", tt.url, err, tt.blocked)
+			}
+		})
+	}
+}
+
+func TestSetBlocklistInvalid(t *testing.T) {
+	defer SetBlocklist(nil, nil)
+	if err := SetBlocklist(nil, []string{"10.0.0.0/33"}); err == nil {
+		t.Error("Expected an invalid range to be rejected")
+	}
+	if !hostBlocked("metadata.google.internal") {
+		t.Error("Expected the built-in entries to survive a rejected update")
+	}
+}
+
+func TestTransportBlocklist(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+	defer SetBlocklist(nil, nil)
+	// The blocklist applies to the dialed address even when private
+	// addresses are allowed
+	if err := SetBlocklist([]string{"blocked.localhost"}, []string{"127.0.0.0/8"}); err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{Transport: NewTransport()}
+	resp, err := client.Get(ts.URL)
+	if err == nil {
+		resp.Body.Close()
+	}
+	if !errors.Is(err, ErrBlocked) {
+		t.Errorf("Expected the dialed address to be blocked, got %v", err)
+	}
+	if !Refused(err) {
+		t.Error("Expected a blocked request to count as refused")
+	}
+
+	resp, err = client.Get("http://blocked.localhost/")
+	if err == nil {
+		resp.Body.Close()
+	}
+	if !errors.Is(err, ErrBlocked) {
+		t.Errorf("Expected the host to be blocked before dialing, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// NewTransport returns an HTTP transport that refuses to connect to blocked
+// hosts and to blocked or private addresses. The address check runs on the
+// address actually dialed, after DNS resolution, so a host that resolves
+// to a public address when validated and a private one when fetched (DNS
+// rebinding) is still refused. It uses no proxy, since a proxy would
+// resolve hosts where they can't be checked.
+func NewTransport() *http.Transport {
+	return newTransport(false)
+}
+
+// NewTargetTransport returns a transport like NewTransport's that also
+// refuses hosts and addresses outside the access lists, for fetching the
+// pages analyzed, crawled and link-checked
+func NewTargetTransport() *http.Transport {
+	return newTransport(true)
+}
+
+func newTransport(scoped bool) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	// A custom dialer would otherwise turn HTTP/2 off; pages are fetched
+	// over it wherever the server offers it through ALPN
+	transport.ForceAttemptHTTP2 = true
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		if hostBlocked(host) {
+			return nil, fmt.Errorf("%w: %s", ErrBlocked, host)
+		}
+		nameAllowed := true
+		if scoped {
+			if nameAllowed, err = checkAccessName(host); err != nil {
+				return nil, err
+			}
+		}
+		dialer := &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			Control: func(network, address string, _ syscall.RawConn) error {
+				return dialControl(address, scoped, nameAllowed)
+			},
+		}
+		return dialer.DialContext(ctx, network, address)
+	}
+	return transport
+}
+
+// dialControl rejects a connection to a blocked or private address, or
+// when scoped to one outside the access lists, before it is made;
+// nameAllowed is whether the access lists already allowed the host by name
+func dialControl(address string, scoped, nameAllowed bool) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return ErrPrivateAddress
+	}
+	if ipBlocked(ip) {
+		return fmt.Errorf("%w: %s", ErrBlocked, host)
+	}
+	if scoped {
+		if err := checkAccessAddress(ip, nameAllowed); err != nil {
+			return err
+		}
+	}
+	if !privateAllowed() && isPrivateIP(ip) {
+		return ErrPrivateAddress
+	}
+	return nil
+}
+
+// ValidateRedirect checks a redirect hop of a page or link check like a
+// submitted URL: it must be http(s), must not lead to a private address
+// and must stay within the access lists
+func ValidateRedirect(req *http.Request) error {
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("redirect to %q blocked: URL scheme must be http or https", req.URL.Redacted())
+	}
+	if err := checkSSRF(req.URL.Hostname(), true); err != nil {
+		return fmt.Errorf("redirect to %s blocked: %w", req.URL.Hostname(), err)
+	}
+	return nil
+}
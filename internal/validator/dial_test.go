@@ -0,0 +1,84 @@
+package validator
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestNewTransport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: NewTransport()}
+	// localhost resolves to a private address only when dialed
+	for _, target := range []string{ts.URL, strings.Replace(ts.URL, "127.0.0.1", "localhost", 1)} {
+		resp, err := client.Get(target)
+		if err == nil {
+			resp.Body.Close()
+		}
+		if !errors.Is(err, ErrPrivateAddress) {
+			t.Errorf("Expected %s to be refused, got %v", target, err)
+		}
+	}
+
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+	resp, err := client.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("Expected ALLOW_PRIVATE_IPS to allow the request, got %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestValidateRedirect(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{"https://93.184.215.14/next", false},
+		{"http://127.0.0.1/admin", true},
+		{"http://169.254.169.254/latest/meta-data/", true},
+		{"http://[::1]:8080/", true},
+		{"ftp://93.184.215.14/", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			u, _ := url.Parse(tt.url)
+			err := ValidateRedirect(&http.Request{URL: u})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRedirect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewTransportHTTP2(t *testing.T) {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	defer ts.Close()
+
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	transport := NewTransport()
+	roots := x509.NewCertPool()
+	roots.AddCert(ts.Certificate())
+	transport.TLSClientConfig = &tls.Config{RootCAs: roots}
+	resp, err := (&http.Client{Transport: transport}).Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Errorf("Expected HTTP/2 to be negotiated, got %s", resp.Proto)
+	}
+}
@@ -1,13 +1,41 @@
 package validator
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"net/url"
 	"os"
 )
 
+// ErrPrivateAddress is returned for requests to private, loopback and
+// link-local addresses
+var ErrPrivateAddress = errors.New("access to private IP addresses is not allowed")
+
 func ValidateURL(rawURL string, maxURLLength int) error {
+	if err := ValidateSyntax(rawURL, maxURLLength); err != nil {
+		return err
+	}
+
+	// SSRF protection
+	parsed, _ := url.Parse(rawURL)
+	return checkSSRF(parsed.Hostname(), false)
+}
+
+// ValidateTarget applies ValidateURL's checks to a page to analyze, crawl
+// or link-check, which must also be within the access lists. Other
+// outbound requests, such as webhooks, aren't restricted by them.
+func ValidateTarget(rawURL string, maxURLLength int) error {
+	if err := ValidateSyntax(rawURL, maxURLLength); err != nil {
+		return err
+	}
+	parsed, _ := url.Parse(rawURL)
+	return checkSSRF(parsed.Hostname(), true)
+}
+
+// ValidateSyntax applies ValidateURL's checks except the SSRF lookup, so
+// it never resolves the host
+func ValidateSyntax(rawURL string, maxURLLength int) error {
 	if rawURL == "" {
 		return fmt.Errorf("URL is required")
 	}
@@ -31,16 +59,34 @@ func ValidateURL(rawURL string, maxURLLength int) error {
 		return fmt.Errorf("URL must have a host")
 	}
 
-	// SSRF protection
-	if err := checkSSRF(parsed.Hostname()); err != nil {
-		return err
-	}
-
 	return nil
 }
 
-func checkSSRF(hostname string) error {
-	if os.Getenv("ALLOW_PRIVATE_IPS") == "true" {
+// CheckTarget applies ValidateTarget's checks to a host name without
+// validating a whole URL, for requests made on a page's behalf
+func CheckTarget(hostname string) error {
+	return checkSSRF(hostname, true)
+}
+
+// checkSSRF refuses blocked and private hosts and, when scoped, hosts
+// outside the access lists
+func checkSSRF(hostname string, scoped bool) error {
+	if hostBlocked(hostname) {
+		return fmt.Errorf("%w: %s", ErrBlocked, hostname)
+	}
+	nameAllowed := true
+	if scoped {
+		var err error
+		if nameAllowed, err = checkAccessName(hostname); err != nil {
+			return err
+		}
+	}
+	if privateAllowed() {
+		// Names aren't resolved for local testing; dialing checks the
+		// address they resolve to against the blocklist and access lists
+		if ip := net.ParseIP(hostname); ip != nil && ipBlocked(ip) {
+			return fmt.Errorf("%w: %s", ErrBlocked, hostname)
+		}
 		return nil
 	}
 	// Resolve hostname
@@ -50,23 +96,40 @@ func checkSSRF(hostname string) error {
 	}
 
 	for _, ip := range ips {
+		if ipBlocked(ip) {
+			return fmt.Errorf("%w: %s", ErrBlocked, hostname)
+		}
+		if scoped {
+			if err := checkAccessAddress(ip, nameAllowed); err != nil {
+				return err
+			}
+		}
 		if isPrivateIP(ip) {
-			return fmt.Errorf("access to private IP addresses is not allowed")
+			return ErrPrivateAddress
 		}
 	}
 
 	return nil
 }
 
+// privateAllowed reports whether ALLOW_PRIVATE_IPS turns the private-range
+// checks off, e.g. for local testing
+func privateAllowed() bool {
+	return os.Getenv("ALLOW_PRIVATE_IPS") == "true"
+}
+
 func isPrivateIP(ip net.IP) bool {
 	// Check for private ranges
 	privateRanges := []string{
+		"0.0.0.0/8", // "this network", reaches the local host
 		"10.0.0.0/8",
 		"172.16.0.0/12",
 		"192.168.0.0/16",
 		"127.0.0.0/8",
 		"169.254.0.0/16", // link-local
+		"::/128",         // IPv6 unspecified
 		"::1/128",        // IPv6 localhost
+		"fc00::/7",       // IPv6 unique local
 		"fe80::/10",      // IPv6 link-local
 	}
 
@@ -31,6 +31,20 @@ func TestValidateURL(t *testing.T) {
 	}
 }
 
+func TestValidateSyntax(t *testing.T) {
+	// Private and unresolvable hosts pass, since nothing is looked up
+	for _, url := range []string{"http://127.0.0.1", "https://unresolvable.invalid/path"} {
+		if err := ValidateSyntax(url, 2048); err != nil {
+			t.Errorf("ValidateSyntax(%q) = %v, want nil", url, err)
+		}
+	}
+	for _, url := range []string{"", "ftp://example.com", "https://", "https://example.com/" + string(make([]byte, 2040))} {
+		if err := ValidateSyntax(url, 2048); err == nil {
+			t.Errorf("Expected ValidateSyntax(%q) to fail", url)
+		}
+	}
+}
+
 func TestIsPrivateIP(t *testing.T) {
 	tests := []struct {
 		ipStr string
@@ -45,6 +59,11 @@ func TestIsPrivateIP(t *testing.T) {
 		{"1.1.1.1", false},
 		{"::1", true},
 		{"fe80::1", true},
+		{"0.0.0.0", true},
+		{"::", true},
+		{"fd00::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"2606:4700::1111", false},
 	}
 
 	for _, tt := range tests {
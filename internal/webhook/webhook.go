@@ -0,0 +1,511 @@
+// Package webhook posts stored analyses to the notification webhooks of
+// their projects and monitors, and to a service-wide webhook
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"website-analyzer/internal/models"
+	"website-analyzer/internal/monitor"
+	"website-analyzer/internal/portfolio"
+	"website-analyzer/internal/redact"
+	"website-analyzer/internal/storage"
+	"website-analyzer/internal/validator"
+)
+
+// Payload formats a webhook can receive
+const (
+	// FormatFull sends the stored analysis with its nested result
+	FormatFull = "full"
+	// FormatFlat sends top-level scalar fields only, which no-code
+	// automation tools such as Zapier and IFTTT map without parsing
+	FormatFlat = "flat"
+	// FormatSummary sends the headline figures and the diff against the
+	// previous run, which is what incident tooling alerts on
+	FormatSummary = "summary"
+)
+
+// Formats lists the payload formats; an empty format means FormatFull
+var Formats = []string{FormatFull, FormatFlat, FormatSummary}
+
+// Events sent to webhooks
+const (
+	// EventAnalysisCompleted is sent when an analysis is stored
+	EventAnalysisCompleted = "analysis.completed"
+	// EventAnalysisFailed is sent when a scheduled run fails
+	EventAnalysisFailed = "analysis.failed"
+	// EventPortfolioReport is sent with a project's scheduled report
+	EventPortfolioReport = "portfolio.report"
+)
+
+// Signature headers, sent when a signing secret is configured. The
+// signature is "sha256=" followed by the hex HMAC-SHA256 of the timestamp,
+// a dot and the body; receivers should reject stale timestamps.
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+)
+
+const (
+	requestTimeout = 10 * time.Second
+	maxURLLength   = 2048
+)
+
+// ValidFormat reports whether format is empty or one of Formats
+func ValidFormat(format string) bool {
+	return format == "" || slices.Contains(Formats, format)
+}
+
+// Sign returns the signature header value for body sent at timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Diff compares an analysis with the previous run of the same URL
+type Diff struct {
+	PreviousID       string    `json:"previous_id"`
+	PreviousAt       time.Time `json:"previous_at"`
+	NewBrokenLinks   []string  `json:"new_broken_links"`
+	FixedBrokenLinks []string  `json:"fixed_broken_links"`
+	TitleChanged     bool      `json:"title_changed"`
+	PreviousTitle    string    `json:"previous_title,omitempty"`
+	// Changes are current minus previous values
+	InternalLinksChange int `json:"internal_links_change"`
+	ExternalLinksChange int `json:"external_links_change"`
+	WordCountChange     int `json:"word_count_change"`
+	// ScoreChanges is set when both runs were scored
+	ScoreChanges *models.Scores `json:"score_changes,omitempty"`
+}
+
+// NewDiff compares current with previous
+func NewDiff(previous, current *storage.Record) *Diff {
+	was, now := previous.Result, current.Result
+	diff := &Diff{
+		PreviousID:          previous.ID,
+		PreviousAt:          previous.CreatedAt,
+		NewBrokenLinks:      []string{},
+		FixedBrokenLinks:    []string{},
+		TitleChanged:        was.Title != now.Title,
+		InternalLinksChange: now.InternalLinks - was.InternalLinks,
+		ExternalLinksChange: now.ExternalLinks - was.ExternalLinks,
+		WordCountChange:     now.WordCount - was.WordCount,
+	}
+	if diff.TitleChanged {
+		diff.PreviousTitle = was.Title
+	}
+
+	broken := func(result *models.AnalysisResult) map[string]bool {
+		urls := make(map[string]bool, len(result.InaccessibleLinks))
+		for _, link := range result.InaccessibleLinks {
+			urls[link.URL] = true
+		}
+		return urls
+	}
+	wasBroken, nowBroken := broken(was), broken(now)
+	for _, link := range now.InaccessibleLinks {
+		if !wasBroken[link.URL] {
+			diff.NewBrokenLinks = append(diff.NewBrokenLinks, link.URL)
+		}
+	}
+	for _, link := range was.InaccessibleLinks {
+		if !nowBroken[link.URL] {
+			diff.FixedBrokenLinks = append(diff.FixedBrokenLinks, link.URL)
+		}
+	}
+
+	if was.Scores != nil && now.Scores != nil {
+		diff.ScoreChanges = &models.Scores{
+			Overall:       now.Scores.Overall - was.Scores.Overall,
+			SEO:           now.Scores.SEO - was.Scores.SEO,
+			Accessibility: now.Scores.Accessibility - was.Scores.Accessibility,
+			Links:         now.Scores.Links - was.Scores.Links,
+		}
+	}
+	return diff
+}
+
+// Payload is the full format: the stored analysis as the API returns it.
+// Diff is omitted on the first run of a URL.
+type Payload struct {
+	Event string `json:"event"`
+	*storage.Record
+	Diff *Diff `json:"diff,omitempty"`
+}
+
+// SummaryPayload is the summary format. Diff is null on the first run of a
+// URL; MonitorID is set for scheduled runs delivered to their monitor's
+// webhook.
+type SummaryPayload struct {
+	Event      string    `json:"event"`
+	AnalysisID string    `json:"analysis_id"`
+	MonitorID  string    `json:"monitor_id,omitempty"`
+	URL        string    `json:"url"`
+	Project    string    `json:"project,omitempty"`
+	AnalyzedAt time.Time `json:"analyzed_at"`
+	Summary    Summary   `json:"summary"`
+	Diff       *Diff     `json:"diff"`
+}
+
+// Summary holds the headline figures of an analysis
+type Summary struct {
+	Title         string              `json:"title"`
+	HTMLVersion   string              `json:"html_version"`
+	WordCount     int                 `json:"word_count"`
+	InternalLinks int                 `json:"internal_links"`
+	ExternalLinks int                 `json:"external_links"`
+	BrokenLinks   int                 `json:"broken_links"`
+	HasLoginForm  bool                `json:"has_login_form"`
+	Scores        *models.Scores      `json:"scores,omitempty"`
+	Regressions   []models.Regression `json:"regressions"`
+}
+
+// FailurePayload is sent when a scheduled run fails, in every format
+type FailurePayload struct {
+	Event     string    `json:"event"`
+	MonitorID string    `json:"monitor_id"`
+	URL       string    `json:"url"`
+	Project   string    `json:"project,omitempty"`
+	Error     string    `json:"error"`
+	FailedAt  time.Time `json:"failed_at"`
+}
+
+// ReportPayload is sent with a project's portfolio report, in every format
+type ReportPayload struct {
+	Event string `json:"event"`
+	*portfolio.Report
+}
+
+// FlatPayload is the flat format. Scores are null when the analysis
+// profile does not score pages; list fields are joined with newlines.
+type FlatPayload struct {
+	Event              string `json:"event"`
+	AnalysisID         string `json:"analysis_id"`
+	URL                string `json:"url"`
+	Project            string `json:"project"`
+	AnalyzedAt         string `json:"analyzed_at"`
+	Title              string `json:"title"`
+	HTMLVersion        string `json:"html_version"`
+	HTMLSize           int64  `json:"html_size"`
+	WordCount          int    `json:"word_count"`
+	InternalLinks      int    `json:"internal_links"`
+	ExternalLinks      int    `json:"external_links"`
+	BrokenLinks        int    `json:"broken_links"`
+	BrokenLinkURLs     string `json:"broken_link_urls"`
+	HasLoginForm       bool   `json:"has_login_form"`
+	ScoreOverall       *int   `json:"score_overall"`
+	ScoreSEO           *int   `json:"score_seo"`
+	ScoreAccessibility *int   `json:"score_accessibility"`
+	ScoreLinks         *int   `json:"score_links"`
+	Regressions        int    `json:"regressions"`
+	RegressionSummary  string `json:"regression_summary"`
+	// Diff fields are empty on the first run of a URL
+	PreviousID         string `json:"previous_id"`
+	NewBrokenLinks     int    `json:"new_broken_links"`
+	FixedBrokenLinks   int    `json:"fixed_broken_links"`
+	TitleChanged       bool   `json:"title_changed"`
+	ScoreOverallChange *int   `json:"score_overall_change"`
+}
+
+// NewPayload builds the payload for record in format; diff is nil on the
+// first run of a URL
+func NewPayload(format string, record *storage.Record, diff *Diff) any {
+	switch format {
+	case FormatFlat:
+		return newFlatPayload(record, diff)
+	case FormatSummary:
+		return newSummaryPayload(record, diff)
+	default:
+		return Payload{Event: EventAnalysisCompleted, Record: record, Diff: diff}
+	}
+}
+
+func newSummaryPayload(record *storage.Record, diff *Diff) SummaryPayload {
+	result := record.Result
+	regressions := result.Regressions
+	if regressions == nil {
+		regressions = []models.Regression{}
+	}
+	return SummaryPayload{
+		Event:      EventAnalysisCompleted,
+		AnalysisID: record.ID,
+		URL:        record.URL,
+		Project:    record.Project,
+		AnalyzedAt: record.CreatedAt.UTC(),
+		Summary: Summary{
+			Title:         result.Title,
+			HTMLVersion:   result.HTMLVersion,
+			WordCount:     result.WordCount,
+			InternalLinks: result.InternalLinks,
+			ExternalLinks: result.ExternalLinks,
+			BrokenLinks:   len(result.InaccessibleLinks),
+			HasLoginForm:  result.HasLoginForm,
+			Scores:        result.Scores,
+			Regressions:   regressions,
+		},
+		Diff: diff,
+	}
+}
+
+func newFlatPayload(record *storage.Record, diff *Diff) FlatPayload {
+	result := record.Result
+	payload := FlatPayload{
+		Event:         EventAnalysisCompleted,
+		AnalysisID:    record.ID,
+		URL:           record.URL,
+		Project:       record.Project,
+		AnalyzedAt:    record.CreatedAt.UTC().Format(time.RFC3339),
+		Title:         result.Title,
+		HTMLVersion:   result.HTMLVersion,
+		HTMLSize:      result.HTMLSize,
+		WordCount:     result.WordCount,
+		InternalLinks: result.InternalLinks,
+		ExternalLinks: result.ExternalLinks,
+		BrokenLinks:   len(result.InaccessibleLinks),
+		HasLoginForm:  result.HasLoginForm,
+		Regressions:   len(result.Regressions),
+	}
+
+	links := make([]string, 0, len(result.InaccessibleLinks))
+	for _, link := range result.InaccessibleLinks {
+		links = append(links, link.URL)
+	}
+	payload.BrokenLinkURLs = strings.Join(links, "\n")
+
+	regressions := make([]string, 0, len(result.Regressions))
+	for _, regression := range result.Regressions {
+		regressions = append(regressions, regression.Kind+": "+regression.Detail)
+	}
+	payload.RegressionSummary = strings.Join(regressions, "\n")
+
+	if scores := result.Scores; scores != nil {
+		payload.ScoreOverall = &scores.Overall
+		payload.ScoreSEO = &scores.SEO
+		payload.ScoreAccessibility = &scores.Accessibility
+		payload.ScoreLinks = &scores.Links
+	}
+
+	if diff != nil {
+		payload.PreviousID = diff.PreviousID
+		payload.NewBrokenLinks = len(diff.NewBrokenLinks)
+		payload.FixedBrokenLinks = len(diff.FixedBrokenLinks)
+		payload.TitleChanged = diff.TitleChanged
+		if diff.ScoreChanges != nil {
+			payload.ScoreOverallChange = &diff.ScoreChanges.Overall
+		}
+	}
+	return payload
+}
+
+// Notifier posts stored analyses to their project's webhook and the
+// service-wide webhook, and scheduled runs to their monitor's webhook
+type Notifier struct {
+	store        storage.Store
+	redactor     *redact.Redactor
+	client       *http.Client
+	secret       string
+	global       string
+	globalFormat string
+}
+
+// NewNotifier returns a notifier reading webhook settings from store.
+// Payloads are masked with redactor like everything else leaving the
+// service.
+func NewNotifier(store storage.Store, redactor *redact.Redactor) *Notifier {
+	return &Notifier{
+		store:    store,
+		redactor: redactor,
+		client: &http.Client{
+			Timeout: requestTimeout,
+			// The URL is checked when saved; the transport also refuses
+			// hosts that resolve to private addresses by delivery time
+			Transport: validator.NewTransport(),
+			// Redirects could lead to addresses the URL check refused
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// SetSecret signs every delivery with secret; empty disables signing
+func (n *Notifier) SetSecret(secret string) {
+	n.secret = secret
+}
+
+// SetGlobal sends every stored analysis and failed scheduled run to
+// webhookURL in format; an empty URL disables it
+func (n *Notifier) SetGlobal(webhookURL, format string) {
+	n.global = webhookURL
+	n.globalFormat = format
+}
+
+// Notify posts record to its project's webhook, if it has one, and to the
+// service-wide webhook, unless a maintenance window is open for it. The
+// record may be modified.
+func (n *Notifier) Notify(ctx context.Context, record *storage.Record) error {
+	if n.inMaintenance(record.URL, record.Project) {
+		return nil
+	}
+	type target struct{ url, format string }
+	var targets []target
+	if record.Project != "" {
+		project, err := n.store.Project(record.Project)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return err
+		}
+		if err == nil && project.NotifyWebhook != "" {
+			targets = append(targets, target{project.NotifyWebhook, project.NotifyFormat})
+		}
+	}
+	if n.global != "" {
+		targets = append(targets, target{n.global, n.globalFormat})
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	diff := n.diff(record)
+	n.redactor.Walk(record)
+	n.redactor.Walk(diff)
+	var errs []error
+	for _, t := range targets {
+		if err := n.post(ctx, t.url, NewPayload(t.format, record, diff)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", n.redactor.Text(t.url), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NotifyRun posts a scheduled run stored as record to the monitor's
+// webhook in the summary format. The record may be modified.
+func (n *Notifier) NotifyRun(ctx context.Context, m *storage.Monitor, record *storage.Record) error {
+	if m.Webhook == "" || n.inMaintenance(m.URL, m.Project) {
+		return nil
+	}
+	diff := n.diff(record)
+	n.redactor.Walk(record)
+	n.redactor.Walk(diff)
+	payload := newSummaryPayload(record, diff)
+	payload.MonitorID = m.ID
+	return n.post(ctx, m.Webhook, payload)
+}
+
+// NotifyFailure posts a failed scheduled run to the monitor's webhook and
+// the service-wide webhook
+func (n *Notifier) NotifyFailure(ctx context.Context, m *storage.Monitor, runErr error) error {
+	if n.inMaintenance(m.URL, m.Project) {
+		return nil
+	}
+	payload := &FailurePayload{
+		Event:     EventAnalysisFailed,
+		MonitorID: m.ID,
+		URL:       m.URL,
+		Project:   m.Project,
+		Error:     runErr.Error(),
+		FailedAt:  time.Now().UTC(),
+	}
+	n.redactor.Walk(payload)
+
+	var errs []error
+	for _, webhookURL := range []string{m.Webhook, n.global} {
+		if webhookURL == "" {
+			continue
+		}
+		if err := n.post(ctx, webhookURL, payload); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", n.redactor.Text(webhookURL), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NotifyReport posts a portfolio report to its project's webhook.
+// Maintenance windows do not hold it back: it is a digest, not an alert.
+func (n *Notifier) NotifyReport(ctx context.Context, project *storage.Project, report *portfolio.Report) error {
+	if project.NotifyWebhook == "" {
+		return nil
+	}
+	n.redactor.Walk(report)
+	return n.post(ctx, project.NotifyWebhook, ReportPayload{Event: EventPortfolioReport, Report: report})
+}
+
+// inMaintenance reports whether a maintenance window silences
+// notifications about url in project now. A failed lookup is logged and
+// lets the notification through.
+func (n *Notifier) inMaintenance(url, project string) bool {
+	windows, err := n.store.MaintenanceWindows()
+	if err != nil {
+		slog.Error("failed to load maintenance windows", "error", err)
+		return false
+	}
+	w := monitor.InMaintenance(windows, url, project, time.Now())
+	if w == nil {
+		return false
+	}
+	slog.Info("notification suppressed by maintenance window", "url", n.redactor.Text(url), "window", w.ID)
+	return true
+}
+
+// diff compares record with the previous run of its URL, or returns nil
+// on the first run. A failed lookup is logged rather than holding up the
+// notification.
+func (n *Notifier) diff(record *storage.Record) *Diff {
+	previous, err := n.store.Previous(record.ID)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			slog.Error("failed to load previous analysis", "id", record.ID, "error", err)
+		}
+		return nil
+	}
+	return NewDiff(previous, record)
+}
+
+func (n *Notifier) post(ctx context.Context, webhookURL string, payload any) error {
+	// Webhooks are user-supplied, so they get the same private-address
+	// checks as analyzed pages
+	if err := validator.ValidateURL(webhookURL, maxURLLength); err != nil {
+		return fmt.Errorf("refusing webhook URL: %w", err)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.secret != "" {
+		timestamp := time.Now().Unix()
+		req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(SignatureHeader, Sign(n.secret, timestamp, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("failed to deliver webhook: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,441 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"website-analyzer/internal/models"
+	"website-analyzer/internal/portfolio"
+	"website-analyzer/internal/redact"
+	"website-analyzer/internal/storage"
+	"website-analyzer/internal/validator"
+)
+
+func testRecord() *storage.Record {
+	return &storage.Record{
+		ID:        "abc123",
+		URL:       "https://example.com/?token=secret",
+		Project:   "docs",
+		CreatedAt: time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC),
+		Result: &models.AnalysisResult{
+			URL:               "https://example.com/?token=secret",
+			Title:             "Docs",
+			InternalLinks:     4,
+			InaccessibleLinks: []models.LinkError{{URL: "https://example.com/a"}, {URL: "https://example.com/b"}},
+			Scores:            &models.Scores{Overall: 81, SEO: 90},
+			Regressions:       []models.Regression{{Kind: models.RegressionTitleChanged, Detail: "changed"}},
+		},
+	}
+}
+
+func TestNewPayloadFlat(t *testing.T) {
+	data, err := json.Marshal(NewPayload(FormatFlat, testRecord(), nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range fields {
+		switch value.(type) {
+		case map[string]any, []any:
+			t.Errorf("Expected only scalar fields, %s is %T", name, value)
+		}
+	}
+	want := map[string]any{
+		"event":              EventAnalysisCompleted,
+		"analysis_id":        "abc123",
+		"analyzed_at":        "2026-03-04T05:06:07Z",
+		"broken_links":       float64(2),
+		"broken_link_urls":   "https://example.com/a\nhttps://example.com/b",
+		"score_overall":      float64(81),
+		"score_seo":          float64(90),
+		"regressions":        float64(1),
+		"regression_summary": "title_changed: changed",
+	}
+	for name, value := range want {
+		if fields[name] != value {
+			t.Errorf("%s = %v, want %v", name, fields[name], value)
+		}
+	}
+
+	record := testRecord()
+	record.Result.Scores = nil
+	data, _ = json.Marshal(NewPayload(FormatFlat, record, nil))
+	if !strings.Contains(string(data), `"score_overall":null`) {
+		t.Errorf("Expected null scores without scoring, got %s", data)
+	}
+}
+
+func TestNewPayloadFull(t *testing.T) {
+	for _, format := range []string{"", FormatFull} {
+		data, err := json.Marshal(NewPayload(format, testRecord(), nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var payload struct {
+			Event  string                 `json:"event"`
+			ID     string                 `json:"id"`
+			Result *models.AnalysisResult `json:"result"`
+		}
+		if err := json.Unmarshal(data, &payload); err != nil || payload.Event != EventAnalysisCompleted || payload.ID != "abc123" || payload.Result == nil || payload.Result.Title != "Docs" {
+			t.Errorf("Expected the nested record for format %q, got %s", format, data)
+		}
+	}
+}
+
+func TestNewPayloadSummary(t *testing.T) {
+	previous := testRecord()
+	previous.ID = "prev01"
+	previous.Result = &models.AnalysisResult{
+		Title:             "Old docs",
+		InternalLinks:     6,
+		InaccessibleLinks: []models.LinkError{{URL: "https://example.com/b"}, {URL: "https://example.com/c"}},
+		Scores:            &models.Scores{Overall: 85, SEO: 90},
+	}
+	diff := NewDiff(previous, testRecord())
+
+	data, err := json.Marshal(NewPayload(FormatSummary, testRecord(), diff))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload SummaryPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Event != EventAnalysisCompleted || payload.AnalysisID != "abc123" || payload.Summary.BrokenLinks != 2 || len(payload.Summary.Regressions) != 1 {
+		t.Errorf("Unexpected summary %s", data)
+	}
+	got := payload.Diff
+	if got == nil || got.PreviousID != "prev01" || !got.TitleChanged || got.PreviousTitle != "Old docs" {
+		t.Fatalf("Expected the diff against the previous run, got %s", data)
+	}
+	if len(got.NewBrokenLinks) != 1 || got.NewBrokenLinks[0] != "https://example.com/a" ||
+		len(got.FixedBrokenLinks) != 1 || got.FixedBrokenLinks[0] != "https://example.com/c" {
+		t.Errorf("Expected one new and one fixed broken link, got %+v", got)
+	}
+	if got.InternalLinksChange != -2 || got.ScoreChanges == nil || got.ScoreChanges.Overall != -4 {
+		t.Errorf("Expected link and score changes, got %+v", got)
+	}
+
+	data, _ = json.Marshal(NewPayload(FormatSummary, testRecord(), nil))
+	if !strings.Contains(string(data), `"diff":null`) {
+		t.Errorf("Expected a null diff on the first run, got %s", data)
+	}
+
+	flat, _ := json.Marshal(NewPayload(FormatFlat, testRecord(), diff))
+	if !strings.Contains(string(flat), `"new_broken_links":1`) || !strings.Contains(string(flat), `"score_overall_change":-4`) {
+		t.Errorf("Expected diff fields in the flat payload, got %s", flat)
+	}
+}
+
+func TestSign(t *testing.T) {
+	// Receivers recompute the HMAC over "<timestamp>.<body>"
+	body := []byte(`{"event":"analysis.completed"}`)
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte("1700000000." + string(body)))
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := Sign("s3cret", 1700000000, body); got != want {
+		t.Errorf("Sign() = %s, want %s", got, want)
+	}
+	if Sign("other", 1700000000, body) == want || Sign("s3cret", 1700000001, body) == want {
+		t.Error("Expected the signature to depend on the secret and timestamp")
+	}
+}
+
+func TestValidFormat(t *testing.T) {
+	for _, format := range []string{"", FormatFull, FormatFlat, FormatSummary} {
+		if !ValidFormat(format) {
+			t.Errorf("Expected %q to be valid", format)
+		}
+	}
+	if ValidFormat("xml") {
+		t.Error("Expected an unknown format to be rejected")
+	}
+}
+
+func TestNotifierNotify(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	var received []map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			http.Error(w, "down", http.StatusBadGateway)
+			return
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		received = append(received, body)
+	}))
+	defer ts.Close()
+
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+	if err := store.SaveProject(&storage.Project{Name: "docs", NotifyWebhook: ts.URL + "/hook", NotifyFormat: FormatFlat}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveProject(&storage.Project{Name: "quiet"}); err != nil {
+		t.Fatal(err)
+	}
+
+	notifier := NewNotifier(store, redact.New([]string{"token"}))
+	ctx := context.Background()
+
+	unfiled := testRecord()
+	unfiled.Project = ""
+	quiet := testRecord()
+	quiet.Project = "quiet"
+	for _, record := range []*storage.Record{unfiled, quiet} {
+		if err := notifier.Notify(ctx, record); err != nil {
+			t.Errorf("Expected records without a webhook to be skipped, got %v", err)
+		}
+	}
+	if len(received) != 0 {
+		t.Fatalf("Expected no deliveries, got %v", received)
+	}
+
+	if err := notifier.Notify(ctx, testRecord()); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if len(received) != 1 || received[0]["analysis_id"] != "abc123" {
+		t.Fatalf("Expected the flat payload, got %v", received)
+	}
+	if url, _ := received[0]["url"].(string); strings.Contains(url, "secret") {
+		t.Errorf("Expected secrets to be redacted, got %q", url)
+	}
+
+	if err := store.SaveProject(&storage.Project{Name: "docs", NotifyWebhook: ts.URL + "/fail"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := notifier.Notify(ctx, testRecord()); err == nil {
+		t.Error("Expected a failing webhook to return an error")
+	}
+}
+
+func TestNotifierOutsideAccessLists(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	var delivered int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered++
+	}))
+	defer ts.Close()
+
+	// The access lists restrict what is analyzed, not where results go
+	defer validator.SetAccessLists(nil, nil)
+	if err := validator.SetAccessLists([]string{"*.example.com"}, []string{"127.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+	if err := store.SaveProject(&storage.Project{Name: "docs", NotifyWebhook: ts.URL + "/hook"}); err != nil {
+		t.Fatal(err)
+	}
+
+	notifier := NewNotifier(store, redact.New(nil))
+	if err := notifier.Notify(context.Background(), testRecord()); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if delivered != 1 {
+		t.Errorf("Expected the webhook outside the access lists to be delivered, got %d deliveries", delivered)
+	}
+}
+
+func TestNotifierGlobalAndMonitor(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	type delivery struct {
+		path string
+		body map[string]any
+	}
+	var received []delivery
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+		if r.Header.Get(SignatureHeader) != Sign("s3cret", timestamp, data) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		var body map[string]any
+		json.Unmarshal(data, &body)
+		received = append(received, delivery{r.URL.Path, body})
+	}))
+	defer ts.Close()
+
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	notifier := NewNotifier(store, redact.New([]string{"token"}))
+	notifier.SetSecret("s3cret")
+	notifier.SetGlobal(ts.URL+"/global", FormatSummary)
+	ctx := context.Background()
+
+	const url = "https://example.com/"
+	first, err := store.Save(url, &models.AnalysisResult{Title: "Before"}, storage.Labels{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := notifier.Notify(ctx, first); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	second, err := store.Save(url, &models.AnalysisResult{Title: "After", InaccessibleLinks: []models.LinkError{{URL: url + "gone"}}}, storage.Labels{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &storage.Monitor{ID: "mon01", URL: url, Webhook: ts.URL + "/monitor"}
+	if err := notifier.NotifyRun(ctx, m, second); err != nil {
+		t.Fatalf("NotifyRun failed: %v", err)
+	}
+	if err := notifier.NotifyFailure(ctx, m, errors.New("timeout")); err != nil {
+		t.Fatalf("NotifyFailure failed: %v", err)
+	}
+
+	if len(received) != 4 {
+		t.Fatalf("Expected four signed deliveries, got %+v", received)
+	}
+	if received[0].path != "/global" || received[0].body["diff"] != nil {
+		t.Errorf("Expected the first run without a diff on the global webhook, got %+v", received[0])
+	}
+	diff, _ := received[1].body["diff"].(map[string]any)
+	if received[1].path != "/monitor" || received[1].body["monitor_id"] != "mon01" || diff["previous_id"] != first.ID || diff["title_changed"] != true {
+		t.Errorf("Expected the monitor run with a diff, got %+v", received[1])
+	}
+	for _, d := range received[2:] {
+		if d.body["event"] != EventAnalysisFailed || d.body["error"] != "timeout" {
+			t.Errorf("Expected the failure on both webhooks, got %+v", d)
+		}
+	}
+
+	// Unsigned deliveries are refused by this receiver
+	notifier.SetSecret("")
+	if err := notifier.Notify(ctx, second); err == nil {
+		t.Error("Expected the unsigned delivery to be refused")
+	}
+}
+
+func TestNotifierReport(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	var received []map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		received = append(received, body)
+	}))
+	defer ts.Close()
+
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+
+	notifier := NewNotifier(store, redact.New([]string{"token"}))
+	notifier.SetGlobal(ts.URL+"/global", FormatSummary)
+	report := &portfolio.Report{
+		Project:     "docs",
+		Pages:       []portfolio.Page{{URL: "https://example.com/?token=secret", BrokenLinks: 2}},
+		BrokenLinks: 2,
+	}
+
+	if err := notifier.NotifyReport(context.Background(), &storage.Project{Name: "docs"}, report); err != nil || len(received) != 0 {
+		t.Fatalf("Expected no delivery without a project webhook, got %v (%v)", received, err)
+	}
+	project := &storage.Project{Name: "docs", NotifyWebhook: ts.URL + "/project"}
+	if err := notifier.NotifyReport(context.Background(), project, report); err != nil {
+		t.Fatalf("NotifyReport failed: %v", err)
+	}
+	if len(received) != 1 || received[0]["event"] != EventPortfolioReport || received[0]["broken_links"] != 2.0 {
+		t.Fatalf("Expected the report on the project webhook only, got %v", received)
+	}
+	pages, _ := received[0]["pages"].([]any)
+	if len(pages) != 1 || strings.Contains(pages[0].(map[string]any)["url"].(string), "secret") {
+		t.Errorf("Expected the report's URLs to be redacted, got %v", pages)
+	}
+}
+
+func TestNotifierMaintenance(t *testing.T) {
+	os.Setenv("ALLOW_PRIVATE_IPS", "true")
+	defer os.Unsetenv("ALLOW_PRIVATE_IPS")
+
+	var received []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.URL.Path)
+	}))
+	defer ts.Close()
+
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer store.Close()
+	if err := store.SaveProject(&storage.Project{Name: "docs", NotifyWebhook: ts.URL + "/project"}); err != nil {
+		t.Fatal(err)
+	}
+	// Due every minute for an hour, so always open
+	for _, window := range []*storage.MaintenanceWindow{
+		{Project: "docs", Schedule: "* * * * *", Duration: time.Hour},
+		{URL: "https://example.com/deploying", Schedule: "* * * * *", Duration: time.Hour},
+	} {
+		if err := store.CreateMaintenanceWindow(window); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	notifier := NewNotifier(store, redact.New(nil))
+	notifier.SetGlobal(ts.URL+"/global", FormatSummary)
+	ctx := context.Background()
+
+	if err := notifier.Notify(ctx, testRecord()); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	m := &storage.Monitor{ID: "mon01", URL: "https://example.com/deploying", Webhook: ts.URL + "/monitor"}
+	record := testRecord()
+	record.URL, record.Project = m.URL, ""
+	if err := notifier.NotifyRun(ctx, m, record); err != nil {
+		t.Fatalf("NotifyRun failed: %v", err)
+	}
+	if err := notifier.NotifyFailure(ctx, m, errors.New("timeout")); err != nil {
+		t.Fatalf("NotifyFailure failed: %v", err)
+	}
+	if len(received) != 0 {
+		t.Fatalf("Expected no deliveries during maintenance, got %v", received)
+	}
+
+	other := testRecord()
+	other.Project, other.URL = "", "https://example.com/stable"
+	if err := notifier.Notify(ctx, other); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if len(received) != 1 || received[0] != "/global" {
+		t.Errorf("Expected pages outside the windows to notify, got %v", received)
+	}
+}